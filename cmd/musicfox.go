@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-musicfox/go-musicfox/internal/commands"
 	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/player"
 	"github.com/go-musicfox/go-musicfox/internal/runtime"
 	"github.com/go-musicfox/go-musicfox/internal/types"
 	mfoxapp "github.com/go-musicfox/go-musicfox/utils/app"
@@ -20,6 +21,10 @@ import (
 )
 
 func main() {
+	// beep 引擎原始采样率模式的输出子进程，只播放标准输入中的 PCM
+	if player.RunOutputProcess() {
+		return
+	}
 	// 以包装进程方式运行：崩溃（fatal error）时恢复终端并提示创建 issue。
 	// 子进程内可恢复的 panic 由 errorx.Recover 自行处理。
 	errorx.RunWrapped(func() {
@@ -82,6 +87,9 @@ func musicfox() {
 	app.Add(commands.NewConfigCommand())
 	app.Add(commands.NewUpgradeConfigCommand())
 	app.Add(commands.NewResetCommand())
+	app.Add(commands.NewDaemonCommand())
+	app.Add(commands.NewCtlCommand())
+	app.Add(commands.NewImportCommand())
 	app.DefaultCommand(playerCommand.Name)
 
 	app.Run()
@@ -105,6 +113,8 @@ func loadConfig() {
 
 	// 加载主题文件（内置 + 用户自定义）
 	configs.LoadThemeRegistry(mfoxapp.ConfigDir())
+	// 加载均衡器预设（内置 + 用户自定义）
+	configs.LoadEqualizerPresets(mfoxapp.ConfigDir())
 }
 
 // isFlagTrue checks whether a boolean flag is set to true in os.Args,
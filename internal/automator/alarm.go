@@ -0,0 +1,160 @@
+package automator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// alarmCatchUpWindow 应用重启后仍会补触发的时间窗口
+const alarmCatchUpWindow = 30 * time.Minute
+
+type scheduledAlarm struct {
+	key    string
+	hour   int
+	minute int
+	alarm  configs.AlarmConfig
+}
+
+// AlarmClock 按配置的时间触发闹钟，每个闹钟每天最多触发一次，触发记录持久化
+type AlarmClock struct {
+	alarms []scheduledAlarm
+	fire   func(configs.AlarmConfig) error
+	now    func() time.Time
+	load   func() storage.AlarmsFired
+	save   func(storage.AlarmsFired)
+
+	mu    sync.Mutex
+	fired storage.AlarmsFired
+}
+
+type AlarmClockOption func(*AlarmClock)
+
+// WithAlarmClockStore 替换默认的 BoltDB 持久化
+func WithAlarmClockStore(load func() storage.AlarmsFired, save func(storage.AlarmsFired)) AlarmClockOption {
+	return func(c *AlarmClock) {
+		c.load, c.save = load, save
+	}
+}
+
+func WithAlarmClockClock(now func() time.Time) AlarmClockOption {
+	return func(c *AlarmClock) {
+		c.now = now
+	}
+}
+
+// NewAlarmClock 创建闹钟，时间格式错误的闹钟会被忽略
+func NewAlarmClock(alarms []configs.AlarmConfig, fire func(configs.AlarmConfig) error, opts ...AlarmClockOption) *AlarmClock {
+	c := &AlarmClock{
+		fire: fire,
+		now:  time.Now,
+		load: loadAlarmsFired,
+		save: func(fired storage.AlarmsFired) { _ = storage.NewTable().SetByKVModel(fired, fired) },
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	for _, alarm := range alarms {
+		at, err := time.Parse("15:04", alarm.Time)
+		if err != nil {
+			slog.Warn("invalid alarm time", "time", alarm.Time, slogx.Error(err))
+			continue
+		}
+		c.alarms = append(c.alarms, scheduledAlarm{
+			key:    fmt.Sprintf("%s%v%s", alarm.Time, alarm.Weekdays, alarm.Playlist),
+			hour:   at.Hour(),
+			minute: at.Minute(),
+			alarm:  alarm,
+		})
+	}
+	c.fired = make(storage.AlarmsFired)
+	for key, day := range c.load() {
+		// 丢弃已从配置中删除的闹钟
+		if slices.ContainsFunc(c.alarms, func(a scheduledAlarm) bool { return a.key == key }) {
+			c.fired[key] = day
+		}
+	}
+	return c
+}
+
+func loadAlarmsFired() storage.AlarmsFired {
+	var fired storage.AlarmsFired
+	if data, err := storage.NewTable().GetByKVModel(fired); err == nil && len(data) > 0 {
+		_ = json.Unmarshal(data, &fired)
+	}
+	return fired
+}
+
+func (a scheduledAlarm) at(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), a.hour, a.minute, 0, 0, day.Location())
+}
+
+func (a scheduledAlarm) activeOn(day time.Time) bool {
+	if len(a.alarm.Weekdays) == 0 {
+		return true
+	}
+	weekday := int(day.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	return slices.Contains(a.alarm.Weekdays, weekday)
+}
+
+// Check 触发已到点且今天尚未触发的闹钟，应定期调用
+func (c *AlarmClock) Check() {
+	now := c.now()
+	today := now.Format(time.DateOnly)
+
+	c.mu.Lock()
+	var due []scheduledAlarm
+	for _, alarm := range c.alarms {
+		at := alarm.at(now)
+		if !alarm.activeOn(now) || now.Before(at) || now.Sub(at) > alarmCatchUpWindow || c.fired[alarm.key] == today {
+			continue
+		}
+		c.fired[alarm.key] = today
+		due = append(due, alarm)
+	}
+	if len(due) > 0 {
+		c.save(c.fired)
+	}
+	c.mu.Unlock()
+
+	for _, alarm := range due {
+		slog.Info("alarm fired", "time", alarm.alarm.Time, "playlist", alarm.alarm.Playlist)
+		if err := c.fire(alarm.alarm); err != nil {
+			slog.Error("alarm failed", "time", alarm.alarm.Time, slogx.Error(err))
+		}
+	}
+}
+
+// Next 下一次将触发的闹钟时间
+func (c *AlarmClock) Next() (time.Time, bool) {
+	now := c.now()
+	today := now.Format(time.DateOnly)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var next time.Time
+	for _, alarm := range c.alarms {
+		for days := range 8 {
+			day := now.AddDate(0, 0, days)
+			at := alarm.at(day)
+			if !alarm.activeOn(day) || (days == 0 && (now.After(at) || c.fired[alarm.key] == today)) {
+				continue
+			}
+			if next.IsZero() || at.Before(next) {
+				next = at
+			}
+			break
+		}
+	}
+	return next, !next.IsZero()
+}
@@ -0,0 +1,81 @@
+package automator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+)
+
+func newTestAlarmClock(alarms []configs.AlarmConfig, fired *storage.AlarmsFired, now *time.Time) (*AlarmClock, *[]string) {
+	var calls []string
+	clock := NewAlarmClock(alarms, func(alarm configs.AlarmConfig) error {
+		calls = append(calls, alarm.Time)
+		return nil
+	},
+		WithAlarmClockStore(func() storage.AlarmsFired { return *fired }, func(f storage.AlarmsFired) { *fired = f }),
+		WithAlarmClockClock(func() time.Time { return *now }))
+	return clock, &calls
+}
+
+func TestAlarmClockFiresOncePerDay(t *testing.T) {
+	// 2024-05-01 是星期三
+	now := time.Date(2024, 5, 1, 7, 29, 0, 0, time.Local)
+	fired := storage.AlarmsFired{}
+	clock, calls := newTestAlarmClock([]configs.AlarmConfig{{Time: "07:30"}}, &fired, &now)
+
+	clock.Check()
+	if len(*calls) != 0 {
+		t.Fatal("alarm fired early")
+	}
+	now = now.Add(time.Minute)
+	clock.Check()
+	now = now.Add(time.Minute)
+	clock.Check()
+	if len(*calls) != 1 {
+		t.Fatalf("alarm fired %d times, want 1", len(*calls))
+	}
+
+	// 重启后不会重复触发
+	clock, calls = newTestAlarmClock([]configs.AlarmConfig{{Time: "07:30"}}, &fired, &now)
+	clock.Check()
+	if len(*calls) != 0 {
+		t.Fatal("alarm fired again after restart")
+	}
+	if next, ok := clock.Next(); !ok || !next.Equal(time.Date(2024, 5, 2, 7, 30, 0, 0, time.Local)) {
+		t.Fatalf("next = %v, %v", next, ok)
+	}
+}
+
+func TestAlarmClockCatchUpWindow(t *testing.T) {
+	fired := storage.AlarmsFired{}
+	now := time.Date(2024, 5, 1, 7, 50, 0, 0, time.Local)
+	clock, calls := newTestAlarmClock([]configs.AlarmConfig{{Time: "07:30"}}, &fired, &now)
+	clock.Check()
+	if len(*calls) != 1 {
+		t.Fatal("missed alarm was not caught up after restart")
+	}
+
+	fired = storage.AlarmsFired{}
+	now = time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	clock, calls = newTestAlarmClock([]configs.AlarmConfig{{Time: "07:30"}}, &fired, &now)
+	clock.Check()
+	if len(*calls) != 0 {
+		t.Fatal("alarm outside the catch-up window fired")
+	}
+}
+
+func TestAlarmClockWeekdays(t *testing.T) {
+	fired := storage.AlarmsFired{}
+	now := time.Date(2024, 5, 1, 7, 30, 0, 0, time.Local) // 星期三
+	alarms := []configs.AlarmConfig{{Time: "07:30", Weekdays: []int{6, 7}}, {Time: "bad"}}
+	clock, calls := newTestAlarmClock(alarms, &fired, &now)
+	clock.Check()
+	if len(*calls) != 0 {
+		t.Fatal("weekend alarm fired on Wednesday")
+	}
+	if next, ok := clock.Next(); !ok || !next.Equal(time.Date(2024, 5, 4, 7, 30, 0, 0, time.Local)) {
+		t.Fatalf("next = %v, %v, want Saturday", next, ok)
+	}
+}
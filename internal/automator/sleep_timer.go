@@ -0,0 +1,264 @@
+package automator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+const (
+	SleepAfterDuration = "duration" // 到达截止时间后暂停
+	SleepAfterSongs    = "songs"    // 播放完指定数量的歌曲后停止
+)
+
+type SleepTimerBackend interface {
+	Volume() int
+	SetVolume(volume int)
+	Pause()
+	PassedTime() time.Duration
+	CurSong() structs.Song
+}
+
+// SleepTimer 睡眠定时，到期前按配置淡出音量，状态持久化以便重启后继续生效
+type SleepTimer struct {
+	backend SleepTimerBackend
+	fade    time.Duration
+	now     func() time.Time
+	load    func() (storage.SleepTimer, bool)
+	save    func(storage.SleepTimer)
+	stopped func(state storage.SleepTimer)
+
+	mu       sync.Mutex
+	state    storage.SleepTimer
+	fading   bool
+	fadeFrom int
+}
+
+type SleepTimerOption func(*SleepTimer)
+
+// WithSleepTimerFade 到期前淡出音量的时长
+func WithSleepTimerFade(fade time.Duration) SleepTimerOption {
+	return func(t *SleepTimer) {
+		t.fade = fade
+	}
+}
+
+// WithSleepTimerStore 替换默认的 BoltDB 持久化
+func WithSleepTimerStore(load func() (storage.SleepTimer, bool), save func(storage.SleepTimer)) SleepTimerOption {
+	return func(t *SleepTimer) {
+		t.load, t.save = load, save
+	}
+}
+
+func WithSleepTimerClock(now func() time.Time) SleepTimerOption {
+	return func(t *SleepTimer) {
+		t.now = now
+	}
+}
+
+// WithSleepTimerStopped 定时到期并停止播放后的回调
+func WithSleepTimerStopped(fn func(state storage.SleepTimer)) SleepTimerOption {
+	return func(t *SleepTimer) {
+		t.stopped = fn
+	}
+}
+
+func NewSleepTimer(backend SleepTimerBackend, opts ...SleepTimerOption) *SleepTimer {
+	t := &SleepTimer{
+		backend: backend,
+		now:     time.Now,
+		load:    loadSleepTimer,
+		save:    saveSleepTimer,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func loadSleepTimer() (storage.SleepTimer, bool) {
+	var state storage.SleepTimer
+	data, err := storage.NewTable().GetByKVModel(state)
+	if err != nil || len(data) == 0 {
+		return state, false
+	}
+	return state, json.Unmarshal(data, &state) == nil
+}
+
+func saveSleepTimer(state storage.SleepTimer) {
+	table := storage.NewTable()
+	if state.Mode == "" {
+		_ = table.DeleteByKVModel(state)
+		return
+	}
+	_ = table.SetByKVModel(state, state)
+}
+
+// Restore 恢复上次退出前设置的定时，已过期的定时直接丢弃
+func (t *SleepTimer) Restore() {
+	state, ok := t.load()
+	if !ok {
+		return
+	}
+	if (state.Mode == SleepAfterDuration && !state.Deadline.After(t.now())) ||
+		(state.Mode == SleepAfterSongs && state.Songs <= 0) ||
+		(state.Mode != SleepAfterDuration && state.Mode != SleepAfterSongs) {
+		t.save(storage.SleepTimer{})
+		if state.Volume > 0 {
+			t.backend.SetVolume(state.Volume)
+		}
+		return
+	}
+	t.mu.Lock()
+	t.state = state
+	if state.Volume > 0 {
+		// 上次退出时正在淡出，从淡出前的音量继续
+		t.fading, t.fadeFrom = true, state.Volume
+	}
+	t.mu.Unlock()
+}
+
+// After d 时长后暂停播放
+func (t *SleepTimer) After(d time.Duration) {
+	t.set(storage.SleepTimer{Mode: SleepAfterDuration, Deadline: t.now().Add(d)})
+}
+
+// AfterSongs 播放完 n 首歌（含当前歌曲）后停止，n 为 1 即播放完当前歌曲后停止
+func (t *SleepTimer) AfterSongs(n int) {
+	t.set(storage.SleepTimer{Mode: SleepAfterSongs, Songs: max(n, 1)})
+}
+
+// Cancel 取消定时，淡出中途取消时恢复音量
+func (t *SleepTimer) Cancel() {
+	t.set(storage.SleepTimer{})
+}
+
+func (t *SleepTimer) set(state storage.SleepTimer) {
+	t.mu.Lock()
+	restore, volume := t.fading, t.fadeFrom
+	t.state, t.fading = state, false
+	t.mu.Unlock()
+
+	if restore {
+		t.backend.SetVolume(volume)
+	}
+	t.save(state)
+}
+
+// Active 是否设置了定时
+func (t *SleepTimer) Active() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state.Mode != ""
+}
+
+// StopsAtSongEnd 当前歌曲是否为停止前的最后一首，此时不应预加载下一首
+func (t *SleepTimer) StopsAtSongEnd() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state.Mode == SleepAfterSongs && t.state.Songs == 1
+}
+
+// Status 状态栏显示的倒计时，未设置定时时返回空字符串
+func (t *SleepTimer) Status() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch t.state.Mode {
+	case SleepAfterDuration:
+		remaining := max(t.state.Deadline.Sub(t.now()), 0).Round(time.Second)
+		if remaining >= time.Hour {
+			return fmt.Sprintf("睡眠 %d:%02d:%02d", int(remaining.Hours()), int(remaining.Minutes())%60, int(remaining.Seconds())%60)
+		}
+		return fmt.Sprintf("睡眠 %02d:%02d", int(remaining.Minutes()), int(remaining.Seconds())%60)
+	case SleepAfterSongs:
+		if t.state.Songs == 1 {
+			return "睡眠 本曲后停止"
+		}
+		return fmt.Sprintf("睡眠 剩余%d首", t.state.Songs)
+	}
+	return ""
+}
+
+// Tick 每秒调用一次，负责淡出和按时长到期
+func (t *SleepTimer) Tick() {
+	t.mu.Lock()
+	var remaining time.Duration
+	switch {
+	case t.state.Mode == SleepAfterDuration:
+		remaining = t.state.Deadline.Sub(t.now())
+	case t.state.Mode == SleepAfterSongs && t.state.Songs == 1:
+		song := t.backend.CurSong()
+		if song.Id == 0 || song.Duration <= 0 {
+			t.mu.Unlock()
+			return
+		}
+		remaining = song.Duration - t.backend.PassedTime()
+	default:
+		t.mu.Unlock()
+		return
+	}
+
+	if t.state.Mode == SleepAfterDuration && remaining <= 0 {
+		t.expireLocked()
+		return
+	}
+	if t.fade <= 0 || remaining > t.fade {
+		t.mu.Unlock()
+		return
+	}
+	var persist *storage.SleepTimer
+	if !t.fading {
+		t.fading, t.fadeFrom = true, t.backend.Volume()
+		t.state.Volume = t.fadeFrom
+		state := t.state
+		persist = &state
+	}
+	volume := int(math.Ceil(float64(t.fadeFrom) * max(remaining, 0).Seconds() / t.fade.Seconds()))
+	t.mu.Unlock()
+	if persist != nil {
+		t.save(*persist)
+	}
+	t.backend.SetVolume(volume)
+}
+
+// SongEnded 歌曲自然播放结束时调用，返回 true 表示定时到期，不应再切换到下一首
+func (t *SleepTimer) SongEnded() bool {
+	t.mu.Lock()
+	if t.state.Mode != SleepAfterSongs {
+		t.mu.Unlock()
+		return false
+	}
+	t.state.Songs--
+	if t.state.Songs > 0 {
+		state := t.state
+		t.mu.Unlock()
+		t.save(state)
+		return false
+	}
+	t.expireLocked()
+	return true
+}
+
+// expireLocked 暂停播放并清除定时，调用时持有 t.mu，返回前释放。
+// 使用暂停而不是停止：停止状态会触发自动切换到下一首
+func (t *SleepTimer) expireLocked() {
+	state := t.state
+	restore, volume := t.fading, t.fadeFrom
+	t.state, t.fading = storage.SleepTimer{}, false
+	t.mu.Unlock()
+
+	t.backend.Pause()
+	if restore {
+		// 先暂停再恢复音量，下次播放时保持原来的音量
+		t.backend.SetVolume(volume)
+	}
+	t.save(storage.SleepTimer{})
+	if t.stopped != nil {
+		t.stopped(state)
+	}
+}
@@ -0,0 +1,129 @@
+package automator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+type fakeSleepBackend struct {
+	volume int
+	paused int
+	passed time.Duration
+	song   structs.Song
+}
+
+func (b *fakeSleepBackend) Volume() int               { return b.volume }
+func (b *fakeSleepBackend) SetVolume(volume int)      { b.volume = volume }
+func (b *fakeSleepBackend) Pause()                    { b.paused++ }
+func (b *fakeSleepBackend) PassedTime() time.Duration { return b.passed }
+func (b *fakeSleepBackend) CurSong() structs.Song     { return b.song }
+
+type memorySleepStore struct {
+	state storage.SleepTimer
+}
+
+func (s *memorySleepStore) option() SleepTimerOption {
+	return WithSleepTimerStore(
+		func() (storage.SleepTimer, bool) { return s.state, s.state.Mode != "" },
+		func(state storage.SleepTimer) { s.state = state },
+	)
+}
+
+func TestSleepTimerFadesAndPausesAtDeadline(t *testing.T) {
+	now := time.Date(2024, 5, 1, 23, 0, 0, 0, time.Local)
+	backend := &fakeSleepBackend{volume: 80}
+	store := &memorySleepStore{}
+	var stopped bool
+	timer := NewSleepTimer(backend, store.option(),
+		WithSleepTimerClock(func() time.Time { return now }),
+		WithSleepTimerFade(10*time.Second),
+		WithSleepTimerStopped(func(storage.SleepTimer) { stopped = true }))
+
+	timer.After(time.Minute)
+	if status := timer.Status(); status != "睡眠 01:00" {
+		t.Fatalf("status = %q", status)
+	}
+	now = now.Add(55 * time.Second)
+	timer.Tick()
+	if backend.volume != 40 {
+		t.Fatalf("volume halfway through fade = %d, want 40", backend.volume)
+	}
+	if store.state.Volume != 80 {
+		t.Fatalf("persisted volume = %d, want 80 so a restart can restore it", store.state.Volume)
+	}
+
+	now = now.Add(5 * time.Second)
+	timer.Tick()
+	if backend.paused != 1 || !stopped || timer.Active() {
+		t.Fatalf("paused=%d stopped=%v active=%v", backend.paused, stopped, timer.Active())
+	}
+	if backend.volume != 80 {
+		t.Fatalf("volume after expiry = %d, want restored 80", backend.volume)
+	}
+	if store.state.Mode != "" {
+		t.Fatalf("state not cleared: %+v", store.state)
+	}
+}
+
+func TestSleepTimerCountsSongs(t *testing.T) {
+	backend := &fakeSleepBackend{volume: 50}
+	timer := NewSleepTimer(backend, (&memorySleepStore{}).option())
+
+	timer.AfterSongs(2)
+	if timer.StopsAtSongEnd() || timer.SongEnded() {
+		t.Fatal("timer expired after the first song")
+	}
+	if !timer.StopsAtSongEnd() || timer.Status() != "睡眠 本曲后停止" {
+		t.Fatalf("status = %q", timer.Status())
+	}
+	if !timer.SongEnded() || backend.paused != 1 || timer.Active() {
+		t.Fatalf("timer did not expire after the last song, paused=%d", backend.paused)
+	}
+	if timer.SongEnded() {
+		t.Fatal("inactive timer stopped playback")
+	}
+}
+
+func TestSleepTimerCancelRestoresVolume(t *testing.T) {
+	now := time.Now()
+	backend := &fakeSleepBackend{volume: 60}
+	timer := NewSleepTimer(backend, (&memorySleepStore{}).option(),
+		WithSleepTimerClock(func() time.Time { return now }),
+		WithSleepTimerFade(30*time.Second))
+
+	timer.After(10 * time.Second)
+	timer.Tick()
+	if backend.volume >= 60 {
+		t.Fatalf("volume = %d, want fading", backend.volume)
+	}
+	timer.Cancel()
+	if backend.volume != 60 || backend.paused != 0 {
+		t.Fatalf("volume = %d paused = %d after cancel", backend.volume, backend.paused)
+	}
+}
+
+func TestSleepTimerRestore(t *testing.T) {
+	now := time.Date(2024, 5, 1, 23, 0, 0, 0, time.Local)
+	clock := WithSleepTimerClock(func() time.Time { return now })
+
+	store := &memorySleepStore{state: storage.SleepTimer{Mode: SleepAfterDuration, Deadline: now.Add(time.Minute)}}
+	timer := NewSleepTimer(&fakeSleepBackend{}, store.option(), clock)
+	timer.Restore()
+	if !timer.Active() {
+		t.Fatal("pending timer was not restored")
+	}
+
+	backend := &fakeSleepBackend{volume: 10}
+	store = &memorySleepStore{state: storage.SleepTimer{Mode: SleepAfterDuration, Deadline: now.Add(-time.Minute), Volume: 70}}
+	timer = NewSleepTimer(backend, store.option(), clock)
+	timer.Restore()
+	if timer.Active() || store.state.Mode != "" {
+		t.Fatal("expired timer was restored")
+	}
+	if backend.volume != 70 {
+		t.Fatalf("volume = %d, want the pre-fade volume 70", backend.volume)
+	}
+}
@@ -0,0 +1,168 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// aacSampleRates MPEG-4 采样率索引表
+var aacSampleRates = [...]int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+// aacFrameSamples 每个 AAC 帧的采样数（不含 SBR 倍频）
+const aacFrameSamples = 1024
+
+func isADTSHeader(b []byte) bool {
+	return len(b) >= 7 && b[0] == 0xFF && b[1]&0xF6 == 0xF0
+}
+
+type adtsHeader struct {
+	objectType   int
+	rateIndex    int
+	channels     int
+	headerLength int
+	frameLength  int
+}
+
+func parseADTSHeader(b []byte) (adtsHeader, error) {
+	if !isADTSHeader(b) {
+		return adtsHeader{}, errors.New("invalid ADTS sync word")
+	}
+	h := adtsHeader{
+		objectType:   int(b[2]>>6) + 1,
+		rateIndex:    int(b[2]>>2) & 0x0F,
+		channels:     int(b[2]&0x01)<<2 | int(b[3]>>6),
+		headerLength: 7,
+		frameLength:  int(b[3]&0x03)<<11 | int(b[4])<<3 | int(b[5]>>5),
+	}
+	if b[1]&0x01 == 0 {
+		h.headerLength = 9 // CRC
+	}
+	if h.rateIndex >= len(aacSampleRates) {
+		return h, fmt.Errorf("invalid ADTS sample rate index %d", h.rateIndex)
+	}
+	if h.frameLength <= h.headerLength {
+		return h, fmt.Errorf("invalid ADTS frame length %d", h.frameLength)
+	}
+	return h, nil
+}
+
+// audioSpecificConfig 由 ADTS 头生成解码器需要的 AudioSpecificConfig
+func (h adtsHeader) audioSpecificConfig() []byte {
+	v := h.objectType<<11 | h.rateIndex<<7 | h.channels<<3
+	return []byte{byte(v >> 8), byte(v)}
+}
+
+// ADTS AAC 裸流，边读取边建立帧索引，支持读取仍在下载中的文件
+type ADTS struct {
+	r       io.ReadSeeker
+	header  adtsHeader
+	offsets []int64 // 已索引的帧起始位置，最后一项为下一帧的位置
+	ended   bool
+}
+
+// OpenADTS 解析第一帧的头信息，文件开头的 ID3 标签会被跳过
+func OpenADTS(r io.ReadSeeker) (*ADTS, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	head := make([]byte, 10)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, err
+	}
+	var start int64
+	if string(head[:3]) == "ID3" {
+		size, _ := id3Size(head)
+		start = int64(size)
+	}
+	a := &ADTS{r: r, offsets: []int64{start}}
+	b, err := a.readAt(start, 9)
+	if err != nil {
+		return nil, err
+	}
+	if a.header, err = parseADTSHeader(b); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *ADTS) readAt(offset int64, n int) ([]byte, error) {
+	if _, err := a.r.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(a.r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// index 向后建立索引直到包含第 i 帧
+func (a *ADTS) index(i int) error {
+	for len(a.offsets)-1 <= i {
+		if a.ended {
+			return io.EOF
+		}
+		offset := a.offsets[len(a.offsets)-1]
+		b, err := a.readAt(offset, 7)
+		if err != nil {
+			return err
+		}
+		h, err := parseADTSHeader(b)
+		if err != nil {
+			// 末尾的 ID3v1 等非音频数据
+			a.ended = true
+			return io.EOF
+		}
+		a.offsets = append(a.offsets, offset+int64(h.frameLength))
+	}
+	return nil
+}
+
+func (a *ADTS) Config() []byte {
+	return a.header.audioSpecificConfig()
+}
+
+func (a *ADTS) Timescale() int {
+	return aacSampleRates[a.header.rateIndex]
+}
+
+// Duration 完整扫描文件得到的时长，扫描失败（如仍在下载）时返回 0
+func (a *ADTS) Duration() int64 {
+	for i := len(a.offsets) - 1; ; i++ {
+		if err := a.index(i); err != nil {
+			if a.ended {
+				return int64(len(a.offsets)-1) * aacFrameSamples
+			}
+			return 0
+		}
+	}
+}
+
+func (a *ADTS) Skip() int64 {
+	return 0
+}
+
+func (a *ADTS) Packet(i int) ([]byte, error) {
+	if err := a.index(i); err != nil {
+		return nil, err
+	}
+	offset, end := a.offsets[i], a.offsets[i+1]
+	frame, err := a.readAt(offset, int(end-offset))
+	if err != nil {
+		return nil, err
+	}
+	h, err := parseADTSHeader(frame)
+	if err != nil {
+		return nil, err
+	}
+	return frame[h.headerLength:], nil
+}
+
+func (a *ADTS) PacketStart(i int) int64 {
+	return int64(i) * aacFrameSamples
+}
+
+func (a *ADTS) PacketIndex(t int64) int {
+	return int(max(t, 0) / aacFrameSamples)
+}
@@ -0,0 +1,50 @@
+package codec
+
+import "errors"
+
+// ErrLibraryUnavailable 系统未安装解码所需的 faad2 或 libopus
+var ErrLibraryUnavailable = errors.New("native decoder library not available")
+
+// AACSource 提供 AAC 包的容器，MP4 与 ADTS 实现此接口
+type AACSource interface {
+	// Config AudioSpecificConfig
+	Config() []byte
+	// Timescale 时间单位，每秒的刻度数
+	Timescale() int
+	// Duration 总时长，单位 Timescale，未知时为 0
+	Duration() int64
+	// Skip 开头需要丢弃的编码器延迟，单位 Timescale
+	Skip() int64
+	// Packet 读取第 i 个包，超出范围时返回 io.EOF
+	Packet(i int) ([]byte, error)
+	// PacketStart 第 i 个包的起始时间，单位 Timescale
+	PacketStart(i int) int64
+	// PacketIndex 包含时间 t 的包
+	PacketIndex(t int64) int
+}
+
+// AACDecoder faad2 解码器，输出交错的立体声 float32
+type AACDecoder struct {
+	handle     uintptr
+	sampleRate int
+	out        []float32
+}
+
+// SampleRate 解码输出的采样率（含 SBR 倍频）
+func (d *AACDecoder) SampleRate() int {
+	return d.sampleRate
+}
+
+// opusMaxFrameSize 单个 Opus 包最长 120ms
+const opusMaxFrameSize = opusSampleRate * 120 / 1000
+
+// OpusDecoder libopus 解码器，固定输出 48kHz 交错立体声 float32
+type OpusDecoder struct {
+	handle uintptr
+	out    []float32
+}
+
+// SampleRate Opus 解码输出的采样率
+func (d *OpusDecoder) SampleRate() int {
+	return opusSampleRate
+}
@@ -0,0 +1,29 @@
+//go:build !darwin && !freebsd && !linux && !netbsd && !windows
+
+package codec
+
+func NewAACDecoder([]byte) (*AACDecoder, error) {
+	return nil, ErrLibraryUnavailable
+}
+
+func (d *AACDecoder) Decode([]byte) ([]float32, error) {
+	return nil, ErrLibraryUnavailable
+}
+
+func (d *AACDecoder) Reset() {}
+
+func (d *AACDecoder) Close() {}
+
+func NewOpusDecoder() (*OpusDecoder, error) {
+	return nil, ErrLibraryUnavailable
+}
+
+func (d *OpusDecoder) Decode([]byte) ([]float32, error) {
+	return nil, ErrLibraryUnavailable
+}
+
+func (d *OpusDecoder) Reset() error {
+	return ErrLibraryUnavailable
+}
+
+func (d *OpusDecoder) Close() {}
@@ -0,0 +1,147 @@
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+const (
+	faadFmt16Bit = 1
+)
+
+// faadConfig 对应 NeAACDecConfiguration
+type faadConfig struct {
+	defObjectType           uint8
+	defSampleRate           cULong
+	outputFormat            uint8
+	downMatrix              uint8
+	useOldADTSFormat        uint8
+	dontUpSampleImplicitSBR uint8
+}
+
+// faadFrameInfo 对应 NeAACDecFrameInfo
+type faadFrameInfo struct {
+	bytesConsumed    cULong
+	samples          cULong
+	channels         uint8
+	error            uint8
+	sampleRate       cULong
+	sbr              uint8
+	objectType       uint8
+	headerType       uint8
+	numFrontChannels uint8
+	numSideChannels  uint8
+	numBackChannels  uint8
+	numLFEChannels   uint8
+	channelPosition  [64]uint8
+	ps               uint8
+}
+
+var (
+	faadOnce sync.Once
+	faadErr  error
+
+	neAACDecOpen                    func() uintptr
+	neAACDecGetCurrentConfiguration func(h uintptr) *faadConfig
+	neAACDecSetConfiguration        func(h uintptr, config *faadConfig) uint8
+	neAACDecInit2                   func(h uintptr, buf *byte, size cULong, sampleRate *cULong, channels *uint8) int8
+	neAACDecDecode                  func(h uintptr, info *faadFrameInfo, buf *byte, size cULong) *int16
+	neAACDecPostSeekReset           func(h uintptr, frame int)
+	neAACDecGetErrorMessage         func(code uint8) string
+	neAACDecClose                   func(h uintptr)
+)
+
+func loadFaad() error {
+	faadOnce.Do(func() {
+		var lib uintptr
+		if lib, faadErr = loadLibrary(faadLibraryNames()); faadErr != nil {
+			return
+		}
+		faadErr = registerSymbols(lib, map[string]any{
+			"NeAACDecOpen":                    &neAACDecOpen,
+			"NeAACDecGetCurrentConfiguration": &neAACDecGetCurrentConfiguration,
+			"NeAACDecSetConfiguration":        &neAACDecSetConfiguration,
+			"NeAACDecInit2":                   &neAACDecInit2,
+			"NeAACDecDecode":                  &neAACDecDecode,
+			"NeAACDecPostSeekReset":           &neAACDecPostSeekReset,
+			"NeAACDecGetErrorMessage":         &neAACDecGetErrorMessage,
+			"NeAACDecClose":                   &neAACDecClose,
+		})
+	})
+	return faadErr
+}
+
+// NewAACDecoder 以 AudioSpecificConfig 初始化解码器，多声道下混为立体声
+func NewAACDecoder(config []byte) (*AACDecoder, error) {
+	if err := loadFaad(); err != nil {
+		return nil, err
+	}
+	if len(config) == 0 {
+		return nil, errors.New("empty AudioSpecificConfig")
+	}
+	h := neAACDecOpen()
+	if h == 0 {
+		return nil, errors.New("NeAACDecOpen failed")
+	}
+	conf := neAACDecGetCurrentConfiguration(h)
+	conf.outputFormat = faadFmt16Bit
+	conf.downMatrix = 1
+	neAACDecSetConfiguration(h, conf)
+
+	var (
+		sampleRate cULong
+		channels   uint8
+	)
+	if ret := neAACDecInit2(h, &config[0], cULong(len(config)), &sampleRate, &channels); ret < 0 || sampleRate == 0 {
+		neAACDecClose(h)
+		return nil, fmt.Errorf("NeAACDecInit2 failed: %d", ret)
+	}
+	return &AACDecoder{handle: h, sampleRate: int(sampleRate)}, nil
+}
+
+// Decode 解码一个 AAC 包，返回的切片在下一次调用前有效
+func (d *AACDecoder) Decode(packet []byte) ([]float32, error) {
+	if d.handle == 0 {
+		return nil, errors.New("aac decoder is closed")
+	}
+	if len(packet) == 0 {
+		return nil, nil
+	}
+	var info faadFrameInfo
+	out := neAACDecDecode(d.handle, &info, &packet[0], cULong(len(packet)))
+	if info.error != 0 {
+		return nil, fmt.Errorf("faad: %s", neAACDecGetErrorMessage(info.error))
+	}
+	if out == nil || info.samples == 0 || info.channels == 0 {
+		return nil, nil
+	}
+	samples := unsafe.Slice(out, int(info.samples))
+	channels := int(info.channels)
+	frames := len(samples) / channels
+	d.out = d.out[:0]
+	for i := range frames {
+		l := float32(samples[i*channels]) / 32768
+		r := l
+		if channels > 1 {
+			r = float32(samples[i*channels+1]) / 32768
+		}
+		d.out = append(d.out, l, r)
+	}
+	return d.out, nil
+}
+
+// Reset 跳转后清空解码器状态
+func (d *AACDecoder) Reset() {
+	neAACDecPostSeekReset(d.handle, 0)
+}
+
+func (d *AACDecoder) Close() {
+	if d.handle != 0 {
+		neAACDecClose(d.handle)
+		d.handle = 0
+	}
+}
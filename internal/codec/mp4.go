@@ -0,0 +1,489 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ErrIncomplete 文件尚未下载到能够解析的位置（如 moov 位于文件末尾）
+var ErrIncomplete = errors.New("incomplete file")
+
+// maxMoovSize moov 的大小上限，防止异常文件耗尽内存
+const maxMoovSize = 64 << 20
+
+// box MP4 box 头
+type box struct {
+	typ        string
+	offset     int64 // box 起始位置
+	headerSize int64
+	size       int64 // 含头部，0 表示延伸到文件末尾
+}
+
+// readBoxHeader 读取 r 当前位置的 box 头
+func readBoxHeader(r io.Reader, offset int64) (box, error) {
+	var h [16]byte
+	if _, err := io.ReadFull(r, h[:8]); err != nil {
+		return box{}, err
+	}
+	b := box{
+		typ:        string(h[4:8]),
+		offset:     offset,
+		headerSize: 8,
+		size:       int64(binary.BigEndian.Uint32(h[:4])),
+	}
+	if b.size == 1 {
+		if _, err := io.ReadFull(r, h[8:16]); err != nil {
+			return box{}, err
+		}
+		b.headerSize = 16
+		b.size = int64(binary.BigEndian.Uint64(h[8:16]))
+	}
+	if b.size != 0 && b.size < b.headerSize {
+		return box{}, fmt.Errorf("invalid %q box size %d", b.typ, b.size)
+	}
+	return b, nil
+}
+
+// children 解析内存中的 box 列表
+func children(data []byte) ([]box, error) {
+	var boxes []box
+	r := bytes.NewReader(data)
+	for r.Len() >= 8 {
+		offset := int64(len(data) - r.Len())
+		b, err := readBoxHeader(r, offset)
+		if err != nil {
+			return nil, err
+		}
+		if b.size == 0 {
+			b.size = int64(len(data)) - offset
+		}
+		if offset+b.size > int64(len(data)) {
+			return nil, fmt.Errorf("%q box exceeds parent", b.typ)
+		}
+		boxes = append(boxes, b)
+		if _, err = r.Seek(offset+b.size, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return boxes, nil
+}
+
+// payload box 的内容（不含头部）
+func (b box) payload(data []byte) []byte {
+	return data[b.offset+b.headerSize : b.offset+b.size]
+}
+
+// findPath 按路径查找子 box，返回其内容
+func findPath(data []byte, path ...string) ([]byte, bool) {
+	for _, name := range path {
+		boxes, err := children(data)
+		if err != nil {
+			return nil, false
+		}
+		found := false
+		for _, b := range boxes {
+			if b.typ == name {
+				data, found = b.payload(data), true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return data, true
+}
+
+// readMoov 扫描顶层 box 并读出 moov 的完整内容
+//
+// complete 为 false 时，文件可能仍在下载，读到末尾仍未找到 moov 返回 ErrIncomplete。
+func readMoov(r io.ReadSeeker, complete bool) ([]byte, error) {
+	var offset int64
+	for {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		b, err := readBoxHeader(r, offset)
+		if err != nil {
+			if !complete && (errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)) {
+				return nil, ErrIncomplete
+			}
+			return nil, err
+		}
+		if b.typ == "moov" {
+			if b.size == 0 || b.size > maxMoovSize {
+				return nil, fmt.Errorf("unsupported moov size %d", b.size)
+			}
+			data := make([]byte, b.size-b.headerSize)
+			if _, err = io.ReadFull(r, data); err != nil {
+				if !complete {
+					return nil, ErrIncomplete
+				}
+				return nil, err
+			}
+			return data, nil
+		}
+		if b.size == 0 {
+			return nil, errors.New("moov box not found")
+		}
+		offset += b.size
+	}
+}
+
+// MP4 MP4/M4A 容器中的第一条 AAC 音轨
+type MP4 struct {
+	r         io.ReadSeeker
+	config    []byte
+	timescale int
+	duration  int64
+	skip      int64
+
+	sizes   []uint32
+	offsets []int64
+	starts  []int64 // 每个包的起始时间，单位 timescale
+}
+
+// OpenMP4 解析 moov 并建立采样表，支持 moov 位于文件头的边下边播
+func OpenMP4(r io.ReadSeeker, complete bool) (*MP4, error) {
+	moov, err := readMoov(r, complete)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := findPath(moov, "mvex"); ok {
+		return nil, errors.New("fragmented mp4 is not supported")
+	}
+	traks, err := children(moov)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range traks {
+		if b.typ != "trak" {
+			continue
+		}
+		m, err := parseTrak(b.payload(moov))
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			m.r = r
+			return m, nil
+		}
+	}
+	return nil, errors.New("no AAC audio track found")
+}
+
+// parseTrak 解析音轨，非 AAC 音轨返回 nil
+func parseTrak(trak []byte) (*MP4, error) {
+	hdlr, ok := findPath(trak, "mdia", "hdlr")
+	if !ok || len(hdlr) < 12 || string(hdlr[8:12]) != "soun" {
+		return nil, nil
+	}
+	mdhd, ok := findPath(trak, "mdia", "mdhd")
+	if !ok || len(mdhd) < 24 {
+		return nil, errors.New("invalid mdhd box")
+	}
+	m := &MP4{}
+	if mdhd[0] == 1 {
+		if len(mdhd) < 36 {
+			return nil, errors.New("invalid mdhd box")
+		}
+		m.timescale = int(binary.BigEndian.Uint32(mdhd[20:24]))
+		m.duration = int64(binary.BigEndian.Uint64(mdhd[24:32]))
+	} else {
+		m.timescale = int(binary.BigEndian.Uint32(mdhd[12:16]))
+		m.duration = int64(binary.BigEndian.Uint32(mdhd[16:20]))
+	}
+	if m.timescale <= 0 {
+		return nil, errors.New("invalid mdhd timescale")
+	}
+
+	stbl, ok := findPath(trak, "mdia", "minf", "stbl")
+	if !ok {
+		return nil, errors.New("stbl box not found")
+	}
+	stsd, ok := findPath(stbl, "stsd")
+	if !ok || len(stsd) < 8 {
+		return nil, errors.New("stsd box not found")
+	}
+	config, err := parseStsd(stsd[8:])
+	if err != nil || config == nil {
+		return nil, err
+	}
+	m.config = config
+	if err = m.parseSampleTable(stbl); err != nil {
+		return nil, err
+	}
+	m.parseEditList(trak)
+	return m, nil
+}
+
+// parseStsd 从 mp4a 采样描述中取出 AudioSpecificConfig，非 AAC 返回 nil
+func parseStsd(entries []byte) ([]byte, error) {
+	boxes, err := children(entries)
+	if err != nil || len(boxes) == 0 {
+		return nil, errors.New("invalid stsd box")
+	}
+	entry := boxes[0]
+	if entry.typ != "mp4a" {
+		return nil, nil
+	}
+	p := entry.payload(entries)
+	// SampleEntry(8) + AudioSampleEntry(20)，QuickTime v1/v2 扩展更多字段
+	headerSize := 28
+	if len(p) >= 10 {
+		switch binary.BigEndian.Uint16(p[8:10]) {
+		case 1:
+			headerSize += 16
+		case 2:
+			headerSize += 36
+		}
+	}
+	if len(p) < headerSize {
+		return nil, errors.New("invalid mp4a box")
+	}
+	esds, ok := findPath(p[headerSize:], "esds")
+	if !ok || len(esds) < 4 {
+		return nil, errors.New("esds box not found")
+	}
+	return parseESDescriptor(esds[4:])
+}
+
+// readDescriptor 读取 MPEG-4 描述符的标签与内容
+func readDescriptor(b []byte) (tag byte, body, rest []byte, err error) {
+	if len(b) < 2 {
+		return 0, nil, nil, errors.New("short descriptor")
+	}
+	tag = b[0]
+	var size, i int
+	for i = 1; i < len(b) && i <= 4; i++ {
+		size = size<<7 | int(b[i]&0x7F)
+		if b[i]&0x80 == 0 {
+			break
+		}
+	}
+	i++
+	if i+size > len(b) {
+		return 0, nil, nil, errors.New("descriptor exceeds parent")
+	}
+	return tag, b[i : i+size], b[i+size:], nil
+}
+
+// parseESDescriptor 从 ES_Descriptor 中取出 DecoderSpecificInfo
+func parseESDescriptor(b []byte) ([]byte, error) {
+	tag, es, _, err := readDescriptor(b)
+	if err != nil || tag != 0x03 || len(es) < 3 {
+		return nil, errors.New("invalid ES descriptor")
+	}
+	flags := es[2]
+	es = es[3:]
+	if flags&0x80 != 0 && len(es) >= 2 {
+		es = es[2:]
+	}
+	if flags&0x40 != 0 && len(es) >= 1 {
+		es = es[1+int(es[0]):]
+	}
+	if flags&0x20 != 0 && len(es) >= 2 {
+		es = es[2:]
+	}
+	for len(es) > 0 {
+		var body []byte
+		if tag, body, es, err = readDescriptor(es); err != nil {
+			return nil, err
+		}
+		if tag != 0x04 || len(body) < 13 {
+			continue
+		}
+		switch body[0] {
+		case 0x40, 0x66, 0x67, 0x68: // MPEG-4 AAC、MPEG-2 AAC
+		default:
+			return nil, nil
+		}
+		rest := body[13:]
+		for len(rest) > 0 {
+			var info []byte
+			if tag, info, rest, err = readDescriptor(rest); err != nil {
+				return nil, err
+			}
+			if tag == 0x05 {
+				return info, nil
+			}
+		}
+	}
+	return nil, errors.New("decoder specific info not found")
+}
+
+func (m *MP4) parseSampleTable(stbl []byte) error {
+	stsz, ok := findPath(stbl, "stsz")
+	if !ok || len(stsz) < 12 {
+		return errors.New("stsz box not found")
+	}
+	fixed := binary.BigEndian.Uint32(stsz[4:8])
+	count := int(binary.BigEndian.Uint32(stsz[8:12]))
+	if fixed == 0 && len(stsz) < 12+4*count {
+		return errors.New("invalid stsz box")
+	}
+	m.sizes = make([]uint32, count)
+	for i := range m.sizes {
+		if fixed != 0 {
+			m.sizes[i] = fixed
+		} else {
+			m.sizes[i] = binary.BigEndian.Uint32(stsz[12+4*i:])
+		}
+	}
+
+	var chunks []int64
+	if stco, ok := findPath(stbl, "stco"); ok && len(stco) >= 8 {
+		n := int(binary.BigEndian.Uint32(stco[4:8]))
+		if len(stco) < 8+4*n {
+			return errors.New("invalid stco box")
+		}
+		for i := range n {
+			chunks = append(chunks, int64(binary.BigEndian.Uint32(stco[8+4*i:])))
+		}
+	} else if co64, ok := findPath(stbl, "co64"); ok && len(co64) >= 8 {
+		n := int(binary.BigEndian.Uint32(co64[4:8]))
+		if len(co64) < 8+8*n {
+			return errors.New("invalid co64 box")
+		}
+		for i := range n {
+			chunks = append(chunks, int64(binary.BigEndian.Uint64(co64[8+8*i:])))
+		}
+	} else {
+		return errors.New("chunk offset box not found")
+	}
+
+	stsc, ok := findPath(stbl, "stsc")
+	if !ok || len(stsc) < 8 {
+		return errors.New("stsc box not found")
+	}
+	entries := int(binary.BigEndian.Uint32(stsc[4:8]))
+	if len(stsc) < 8+12*entries {
+		return errors.New("invalid stsc box")
+	}
+	m.offsets = make([]int64, 0, count)
+	for e := range entries {
+		first := int(binary.BigEndian.Uint32(stsc[8+12*e:])) - 1
+		perChunk := int(binary.BigEndian.Uint32(stsc[12+12*e:]))
+		last := len(chunks)
+		if e+1 < entries {
+			last = int(binary.BigEndian.Uint32(stsc[8+12*(e+1):])) - 1
+		}
+		for c := first; c < last && c < len(chunks); c++ {
+			offset := chunks[c]
+			for range perChunk {
+				if len(m.offsets) == count {
+					break
+				}
+				m.offsets = append(m.offsets, offset)
+				offset += int64(m.sizes[len(m.offsets)-1])
+			}
+		}
+	}
+	if len(m.offsets) != count {
+		return errors.New("sample table is inconsistent")
+	}
+
+	stts, ok := findPath(stbl, "stts")
+	if !ok || len(stts) < 8 {
+		return errors.New("stts box not found")
+	}
+	entries = int(binary.BigEndian.Uint32(stts[4:8]))
+	if len(stts) < 8+8*entries {
+		return errors.New("invalid stts box")
+	}
+	m.starts = make([]int64, 0, count+1)
+	var t int64
+	for e := range entries {
+		n := int(binary.BigEndian.Uint32(stts[8+8*e:]))
+		delta := int64(binary.BigEndian.Uint32(stts[12+8*e:]))
+		for range n {
+			if len(m.starts) == count {
+				break
+			}
+			m.starts = append(m.starts, t)
+			t += delta
+		}
+	}
+	for len(m.starts) < count {
+		m.starts = append(m.starts, t)
+		t += aacFrameSamples
+	}
+	m.starts = append(m.starts, t)
+	if m.duration <= 0 || m.duration > t {
+		m.duration = t
+	}
+	return nil
+}
+
+// parseEditList 读取编辑列表中的起始偏移（编码器延迟），单位 timescale
+func (m *MP4) parseEditList(trak []byte) {
+	elst, ok := findPath(trak, "edts", "elst")
+	if !ok || len(elst) < 8 || binary.BigEndian.Uint32(elst[4:8]) == 0 {
+		return
+	}
+	// 编辑列表的时长单位为 mvhd 的 timescale，这里只取媒体起始时间
+	var mediaTime int64
+	if elst[0] == 1 {
+		if len(elst) < 28 {
+			return
+		}
+		mediaTime = int64(binary.BigEndian.Uint64(elst[16:24]))
+	} else {
+		if len(elst) < 20 {
+			return
+		}
+		mediaTime = int64(int32(binary.BigEndian.Uint32(elst[12:16])))
+	}
+	if mediaTime > 0 && mediaTime < m.duration {
+		m.skip = mediaTime
+		m.duration -= mediaTime
+	}
+}
+
+// Config AudioSpecificConfig
+func (m *MP4) Config() []byte {
+	return m.config
+}
+
+func (m *MP4) Timescale() int {
+	return m.timescale
+}
+
+// Duration 去掉编码器延迟后的时长，单位 timescale
+func (m *MP4) Duration() int64 {
+	return m.duration
+}
+
+// Skip 开头需要丢弃的编码器延迟，单位 timescale
+func (m *MP4) Skip() int64 {
+	return m.skip
+}
+
+// Packet 读取第 i 个 AAC 包，超出范围时返回 io.EOF
+func (m *MP4) Packet(i int) ([]byte, error) {
+	if i < 0 || i >= len(m.sizes) {
+		return nil, io.EOF
+	}
+	if _, err := m.r.Seek(m.offsets[i], io.SeekStart); err != nil {
+		return nil, err
+	}
+	b := make([]byte, m.sizes[i])
+	if _, err := io.ReadFull(m.r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (m *MP4) PacketStart(i int) int64 {
+	return m.starts[min(max(i, 0), len(m.starts)-1)]
+}
+
+// PacketIndex 包含时间 t 的包
+func (m *MP4) PacketIndex(t int64) int {
+	i := sort.Search(len(m.sizes), func(i int) bool { return m.starts[i+1] > t })
+	return min(i, max(len(m.sizes)-1, 0))
+}
@@ -0,0 +1,148 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	songtag "github.com/frolovo22/tag"
+)
+
+func be32(values ...uint32) []byte {
+	b := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint32(b[4*i:], v)
+	}
+	return b
+}
+
+// testMP4Packets 合成文件中的三个 AAC 包
+var testMP4Packets = [][]byte{[]byte("aaaa"), []byte("bbbbb"), []byte("cccccc")}
+
+// buildTestMP4 合成一个含单条 AAC 音轨的 MP4，三个包分布在两个块中
+func buildTestMP4(moovFirst bool) []byte {
+	ftyp := makeBox("ftyp", []byte("M4A "), be32(0), []byte("M4A isom"))
+	mdatPayload := bytes.Join(testMP4Packets, nil)
+
+	moov := func(mdatOffset uint32) []byte {
+		dataStart := mdatOffset + 8
+		asc := []byte{0x12, 0x10}
+		dsi := append([]byte{0x05, byte(len(asc))}, asc...)
+		dcd := append([]byte{0x40, 0x15, 0, 0, 0}, be32(128000, 128000)...)
+		dcd = append(dcd, dsi...)
+		es := append([]byte{0, 1, 0, 0x04, byte(len(dcd))}, dcd...)
+		es = append(es, 0x06, 0x01, 0x02)
+		esds := makeBox("esds", be32(0), append([]byte{0x03, byte(len(es))}, es...))
+		mp4a := makeBox("mp4a", make([]byte, 6), []byte{0, 1}, make([]byte, 8), []byte{0, 2, 0, 16}, be32(0, 44100<<16), esds)
+
+		stbl := makeBox("stbl",
+			makeBox("stsd", be32(0, 1), mp4a),
+			makeBox("stts", be32(0, 1, 3, 1024)),
+			makeBox("stsc", be32(0, 1, 1, 2, 1)),
+			makeBox("stsz", be32(0, 0, 3, 4, 5, 6)),
+			makeBox("stco", be32(0, 2, dataStart, dataStart+9)),
+		)
+		mdia := makeBox("mdia",
+			makeBox("mdhd", be32(0, 0, 0, 44100, 3072), []byte{0x55, 0xC4, 0, 0}),
+			makeBox("hdlr", be32(0, 0), []byte("soun"), make([]byte, 13)),
+			makeBox("minf", stbl),
+		)
+		edts := makeBox("edts", makeBox("elst", be32(0, 1, 2048, 1024, 1<<16)))
+		return makeBox("moov", makeBox("trak", edts, mdia))
+	}
+
+	if moovFirst {
+		size := len(moov(0))
+		m := moov(uint32(len(ftyp) + size))
+		return bytes.Join([][]byte{ftyp, m, makeBox("mdat", mdatPayload)}, nil)
+	}
+	return bytes.Join([][]byte{ftyp, makeBox("mdat", mdatPayload), moov(uint32(len(ftyp)))}, nil)
+}
+
+func assertPackets(t *testing.T, m *MP4) {
+	t.Helper()
+	for i, want := range testMP4Packets {
+		got, err := m.Packet(i)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("Packet(%d) = %q, %v, want %q", i, got, err, want)
+		}
+	}
+	if _, err := m.Packet(len(testMP4Packets)); !errors.Is(err, io.EOF) {
+		t.Errorf("Packet past end err = %v, want io.EOF", err)
+	}
+}
+
+func TestOpenMP4(t *testing.T) {
+	for _, moovFirst := range []bool{true, false} {
+		m, err := OpenMP4(bytes.NewReader(buildTestMP4(moovFirst)), true)
+		if err != nil {
+			t.Fatalf("OpenMP4(moovFirst=%v): %v", moovFirst, err)
+		}
+		if !bytes.Equal(m.Config(), []byte{0x12, 0x10}) || m.Timescale() != 44100 {
+			t.Errorf("Config = %x, Timescale = %d", m.Config(), m.Timescale())
+		}
+		if m.Skip() != 1024 || m.Duration() != 2048 {
+			t.Errorf("Skip = %d, Duration = %d, want 1024, 2048", m.Skip(), m.Duration())
+		}
+		if m.PacketIndex(1500) != 1 || m.PacketStart(2) != 2048 {
+			t.Errorf("PacketIndex(1500) = %d, PacketStart(2) = %d", m.PacketIndex(1500), m.PacketStart(2))
+		}
+		assertPackets(t, m)
+	}
+}
+
+func TestOpenMP4Incomplete(t *testing.T) {
+	data := buildTestMP4(false)
+	// moov 在末尾，尚未下载到
+	if _, err := OpenMP4(bytes.NewReader(data[:len(data)-20]), false); !errors.Is(err, ErrIncomplete) {
+		t.Errorf("OpenMP4 err = %v, want ErrIncomplete", err)
+	}
+	if _, err := OpenMP4(bytes.NewReader(data[:len(data)-20]), true); err == nil || errors.Is(err, ErrIncomplete) {
+		t.Errorf("OpenMP4 complete err = %v, want a parse error", err)
+	}
+}
+
+func TestWriteMP4Tags(t *testing.T) {
+	for _, moovFirst := range []bool{true, false} {
+		var out bytes.Buffer
+		tags := MP4Tags{Title: "标题", Artist: "歌手", Album: "专辑", AlbumArtist: "专辑歌手", Cover: []byte("\x89PNG"), CoverMime: "image/png"}
+		if err := WriteMP4Tags(bytes.NewReader(buildTestMP4(moovFirst)), &out, tags); err != nil {
+			t.Fatalf("WriteMP4Tags: %v", err)
+		}
+		// 再次写入只替换同名条目
+		var again bytes.Buffer
+		if err := WriteMP4Tags(bytes.NewReader(out.Bytes()), &again, MP4Tags{Title: "新标题"}); err != nil {
+			t.Fatalf("WriteMP4Tags again: %v", err)
+		}
+
+		m, err := OpenMP4(bytes.NewReader(again.Bytes()), true)
+		if err != nil {
+			t.Fatalf("OpenMP4 after tagging: %v", err)
+		}
+		assertPackets(t, m)
+
+		path := filepath.Join(t.TempDir(), "tagged.m4a")
+		if err = os.WriteFile(path, again.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		metadata, err := songtag.ReadMp4(file)
+		if err != nil {
+			t.Fatalf("songtag.ReadMp4: %v", err)
+		}
+		title, _ := metadata.GetTitle()
+		artist, _ := metadata.GetArtist()
+		album, _ := metadata.GetAlbum()
+		if title != "新标题" || artist != "歌手" || album != "专辑" {
+			t.Errorf("tags = %q, %q, %q", title, artist, album)
+		}
+		_ = metadata.Close()
+	}
+}
@@ -0,0 +1,253 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// MP4Tags 写入 iTunes 风格 ilst 的元数据，空字段保持原值
+type MP4Tags struct {
+	Title       string
+	Artist      string
+	Album       string
+	AlbumArtist string
+	Cover       []byte
+	CoverMime   string
+}
+
+// ilst data atom 的类型
+const (
+	mp4DataUTF8 = 1
+	mp4DataJPEG = 13
+	mp4DataPNG  = 14
+)
+
+func makeBox(typ string, payloads ...[]byte) []byte {
+	size := 8
+	for _, p := range payloads {
+		size += len(p)
+	}
+	b := make([]byte, 8, size)
+	binary.BigEndian.PutUint32(b, uint32(size))
+	copy(b[4:8], typ)
+	for _, p := range payloads {
+		b = append(b, p...)
+	}
+	return b
+}
+
+func dataItem(key string, dataType uint32, value []byte) []byte {
+	var head [8]byte // 版本与类型、locale
+	binary.BigEndian.PutUint32(head[:4], dataType)
+	return makeBox(key, makeBox("data", head[:], value))
+}
+
+// replaceChild 替换（不存在时追加）data 中类型为 typ 的子 box
+func replaceChild(data []byte, typ string, replace func(payload []byte) []byte) ([]byte, error) {
+	boxes, err := children(data)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(data))
+	found := false
+	for _, b := range boxes {
+		if b.typ == typ && !found {
+			found = true
+			out = append(out, makeBox(typ, replace(b.payload(data)))...)
+			continue
+		}
+		out = append(out, data[b.offset:b.offset+b.size]...)
+	}
+	if !found {
+		out = append(out, makeBox(typ, replace(nil))...)
+	}
+	return out, nil
+}
+
+func (t MP4Tags) items() map[string][]byte {
+	items := make(map[string][]byte)
+	for key, value := range map[string]string{
+		"\xa9nam": t.Title,
+		"\xa9ART": t.Artist,
+		"\xa9alb": t.Album,
+		"aART":    t.AlbumArtist,
+	} {
+		if value != "" {
+			items[key] = dataItem(key, mp4DataUTF8, []byte(value))
+		}
+	}
+	if len(t.Cover) > 0 {
+		dataType := uint32(mp4DataJPEG)
+		if t.CoverMime == "image/png" {
+			dataType = mp4DataPNG
+		}
+		items["covr"] = dataItem("covr", dataType, t.Cover)
+	}
+	return items
+}
+
+// updateIlst 用新的条目替换 ilst 中的同名条目，其余条目保留
+func updateIlst(ilst []byte, items map[string][]byte) []byte {
+	boxes, err := children(ilst)
+	if err != nil {
+		boxes = nil // 损坏的 ilst 直接重建
+	}
+	out := make([]byte, 0, len(ilst))
+	for _, b := range boxes {
+		if _, ok := items[b.typ]; ok {
+			continue
+		}
+		out = append(out, ilst[b.offset:b.offset+b.size]...)
+	}
+	for _, key := range []string{"\xa9nam", "\xa9ART", "\xa9alb", "aART", "covr"} {
+		out = append(out, items[key]...)
+	}
+	return out
+}
+
+// updateMeta 更新 meta 中的 ilst，meta 不存在时按 iTunes 格式新建
+func updateMeta(meta []byte, items map[string][]byte) ([]byte, error) {
+	var prefix []byte
+	if meta == nil {
+		prefix = make([]byte, 4)
+		hdlr := make([]byte, 25)
+		copy(hdlr[8:12], "mdir")
+		copy(hdlr[12:16], "appl")
+		meta = makeBox("hdlr", hdlr)
+	} else if len(meta) >= 8 && string(meta[4:8]) != "hdlr" {
+		// ISO 格式的 meta 是 FullBox，带 4 字节版本与标志；QuickTime 格式没有
+		prefix, meta = meta[:4], meta[4:]
+	}
+	body, err := replaceChild(meta, "ilst", func(ilst []byte) []byte {
+		return updateIlst(ilst, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, prefix...), body...), nil
+}
+
+// shiftChunkOffsets 把所有音视频轨道的块偏移加上 delta
+func shiftChunkOffsets(moov []byte, delta int64) error {
+	traks, err := children(moov)
+	if err != nil {
+		return err
+	}
+	for _, trak := range traks {
+		if trak.typ != "trak" {
+			continue
+		}
+		stbl, ok := findPath(trak.payload(moov), "mdia", "minf", "stbl")
+		if !ok {
+			continue
+		}
+		// findPath 返回的是 moov 的子切片，可以原地修改
+		if stco, ok := findPath(stbl, "stco"); ok && len(stco) >= 8 {
+			n := int(binary.BigEndian.Uint32(stco[4:8]))
+			for i := 0; i < n && 12+4*i <= len(stco); i++ {
+				v := int64(binary.BigEndian.Uint32(stco[8+4*i:])) + delta
+				if v < 0 || v > math.MaxUint32 {
+					return errors.New("chunk offset overflows stco")
+				}
+				binary.BigEndian.PutUint32(stco[8+4*i:], uint32(v))
+			}
+		}
+		if co64, ok := findPath(stbl, "co64"); ok && len(co64) >= 8 {
+			n := int(binary.BigEndian.Uint32(co64[4:8]))
+			for i := 0; i < n && 16+8*i <= len(co64); i++ {
+				v := int64(binary.BigEndian.Uint64(co64[8+8*i:])) + delta
+				binary.BigEndian.PutUint64(co64[8+8*i:], uint64(v))
+			}
+		}
+	}
+	return nil
+}
+
+// WriteMP4Tags 把 r 中的 MP4 文件写入 w，并更新 moov/udta/meta/ilst 中的元数据
+//
+// moov 位于 mdat 之前时，moov 大小的变化会同步修正 stco/co64 中的块偏移。
+func WriteMP4Tags(r io.ReadSeeker, w io.Writer, tags MP4Tags) error {
+	var (
+		moov       box
+		moovFound  bool
+		mdatBefore bool
+		offset     int64
+	)
+	for {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		b, err := readBoxHeader(r, offset)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if b.typ == "moov" {
+			moov, moovFound = b, true
+		}
+		if b.typ == "mdat" && !moovFound {
+			mdatBefore = true
+		}
+		if b.size == 0 {
+			break
+		}
+		offset += b.size
+	}
+	if !moovFound || moov.size == 0 || moov.size > maxMoovSize {
+		return errors.New("moov box not found")
+	}
+
+	data := make([]byte, moov.size-moov.headerSize)
+	if _, err := r.Seek(moov.offset+moov.headerSize, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+	items := tags.items()
+	var tagErr error
+	newMoov, err := replaceChild(data, "udta", func(udta []byte) []byte {
+		out, err := replaceChild(udta, "meta", func(meta []byte) []byte {
+			out, err := updateMeta(meta, items)
+			if err != nil {
+				tagErr = err
+			}
+			return out
+		})
+		if err != nil {
+			tagErr = err
+		}
+		return out
+	})
+	if err = errors.Join(err, tagErr); err != nil {
+		return err
+	}
+	newMoov = makeBox("moov", newMoov)
+	if delta := int64(len(newMoov)) - moov.size; delta != 0 && !mdatBefore {
+		if err = shiftChunkOffsets(newMoov[8:], delta); err != nil {
+			return err
+		}
+	}
+
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err = io.CopyN(w, r, moov.offset); err != nil {
+		return err
+	}
+	if _, err = w.Write(newMoov); err != nil {
+		return err
+	}
+	if _, err = r.Seek(moov.offset+moov.size, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, r); err != nil {
+		return fmt.Errorf("copy media data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package codec
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ebitengine/purego"
+)
+
+// loadLibrary 依次尝试 names，返回第一个能打开的库
+func loadLibrary(names []string) (uintptr, error) {
+	var errs []error
+	for _, name := range names {
+		lib, err := openLibrary(name)
+		if err == nil {
+			return lib, nil
+		}
+		errs = append(errs, err)
+	}
+	return 0, fmt.Errorf("%w: %w", ErrLibraryUnavailable, errors.Join(errs...))
+}
+
+// registerSymbols 把 C 函数绑定到 Go 函数指针，任一符号缺失即返回错误
+func registerSymbols(lib uintptr, symbols map[string]any) error {
+	for name, fptr := range symbols {
+		sym, err := lookupSymbol(lib, name)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrLibraryUnavailable, name, err)
+		}
+		purego.RegisterFunc(fptr, sym)
+	}
+	return nil
+}
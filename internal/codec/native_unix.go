@@ -0,0 +1,39 @@
+//go:build darwin || freebsd || linux || netbsd
+
+package codec
+
+import (
+	"runtime"
+
+	"github.com/ebitengine/purego"
+)
+
+// cULong C 的 unsigned long，类 Unix 系统上与指针等宽
+type cULong = uint
+
+func openLibrary(name string) (uintptr, error) {
+	return purego.Dlopen(name, purego.RTLD_NOW|purego.RTLD_LOCAL)
+}
+
+func lookupSymbol(lib uintptr, name string) (uintptr, error) {
+	return purego.Dlsym(lib, name)
+}
+
+// darwinLibraryNames Homebrew 安装的库不在默认搜索路径中
+func darwinLibraryNames(name string) []string {
+	return []string{name, "/opt/homebrew/lib/" + name, "/usr/local/lib/" + name}
+}
+
+func faadLibraryNames() []string {
+	if runtime.GOOS == "darwin" {
+		return darwinLibraryNames("libfaad.2.dylib")
+	}
+	return []string{"libfaad.so.2", "libfaad.so"}
+}
+
+func opusLibraryNames() []string {
+	if runtime.GOOS == "darwin" {
+		return darwinLibraryNames("libopus.0.dylib")
+	}
+	return []string{"libopus.so.0", "libopus.so"}
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package codec
+
+import "syscall"
+
+// cULong C 的 unsigned long，Windows 上固定为 32 位
+type cULong = uint32
+
+func openLibrary(name string) (uintptr, error) {
+	h, err := syscall.LoadLibrary(name)
+	return uintptr(h), err
+}
+
+func lookupSymbol(lib uintptr, name string) (uintptr, error) {
+	return syscall.GetProcAddress(syscall.Handle(lib), name)
+}
+
+func faadLibraryNames() []string {
+	return []string{"libfaad-2.dll", "libfaad2.dll", "faad.dll"}
+}
+
+func opusLibraryNames() []string {
+	return []string{"opus.dll", "libopus-0.dll", "libopus.dll"}
+}
@@ -0,0 +1,290 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// opusSampleRate Opus 的粒度位置始终以 48kHz 计
+const opusSampleRate = 48000
+
+const (
+	oggHeaderSize   = 27
+	oggContinued    = 0x01
+	oggMaxPageBytes = oggHeaderSize + 255 + 255*255
+)
+
+type oggPage struct {
+	flags    byte
+	granule  int64
+	serial   uint32
+	segments []byte
+	body     []byte
+	size     int64 // 整页长度
+}
+
+// readOggPage 读取 offset 处的一整页，withBody 为 false 时只读取页头
+//
+// 数据不足时返回 io.ErrUnexpectedEOF（或 io.EOF），调用方可以在下载更多数据后重试。
+func readOggPage(r io.ReadSeeker, offset int64, withBody bool) (oggPage, error) {
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return oggPage{}, err
+	}
+	var h [oggHeaderSize]byte
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		return oggPage{}, err
+	}
+	if string(h[:4]) != "OggS" {
+		return oggPage{}, fmt.Errorf("invalid ogg page at %d", offset)
+	}
+	p := oggPage{
+		flags:    h[5],
+		granule:  int64(binary.LittleEndian.Uint64(h[6:14])),
+		serial:   binary.LittleEndian.Uint32(h[14:18]),
+		segments: make([]byte, h[26]),
+	}
+	if _, err := io.ReadFull(r, p.segments); err != nil {
+		return oggPage{}, io.ErrUnexpectedEOF
+	}
+	var bodySize int64
+	for _, s := range p.segments {
+		bodySize += int64(s)
+	}
+	p.size = oggHeaderSize + int64(len(p.segments)) + bodySize
+	if withBody {
+		p.body = make([]byte, bodySize)
+		if _, err := io.ReadFull(r, p.body); err != nil {
+			return oggPage{}, io.ErrUnexpectedEOF
+		}
+	}
+	return p, nil
+}
+
+type oggIndexEntry struct {
+	offset  int64 // 下一页的位置
+	granule int64 // 本页结束时的粒度位置
+}
+
+// OggOpus Ogg 封装的 Opus 流，只读取第一条逻辑流
+type OggOpus struct {
+	r        io.ReadSeeker
+	serial   uint32
+	Channels int
+	PreSkip  int
+	Gain     int16 // Q7.8 dB
+	comments []string
+
+	dataStart int64
+	next      int64 // 下一页的位置
+	pending   [][]byte
+	partial   []byte
+	dropFirst bool // 跳转后丢弃从上一页延续过来的残包
+
+	index []oggIndexEntry
+}
+
+// OpenOggOpus 解析 OpusHead 与 OpusTags
+func OpenOggOpus(r io.ReadSeeker) (*OggOpus, error) {
+	o := &OggOpus{r: r}
+	first, err := readOggPage(r, 0, true)
+	if err != nil {
+		return nil, err
+	}
+	o.serial = first.serial
+	o.next = first.size
+	if err = o.parseHead(first.body); err != nil {
+		return nil, err
+	}
+	tags, err := o.Packet()
+	if err != nil {
+		return nil, err
+	}
+	o.comments, _ = parseComments(tags, "OpusTags")
+	if len(o.partial) > 0 || len(o.pending) > 0 {
+		return nil, errors.New("unexpected data after OpusTags")
+	}
+	o.dataStart = o.next
+	return o, nil
+}
+
+func (o *OggOpus) parseHead(b []byte) error {
+	if len(b) < 19 || string(b[:8]) != "OpusHead" {
+		return errors.New("OpusHead not found")
+	}
+	o.Channels = int(b[9])
+	o.PreSkip = int(binary.LittleEndian.Uint16(b[10:12]))
+	o.Gain = int16(binary.LittleEndian.Uint16(b[16:18]))
+	if mapping := b[18]; mapping != 0 && o.Channels > 2 {
+		return fmt.Errorf("unsupported opus channel mapping family %d", mapping)
+	}
+	if o.Channels < 1 || o.Channels > 2 {
+		return fmt.Errorf("unsupported opus channel count %d", o.Channels)
+	}
+	return nil
+}
+
+// parseComments 解析 Vorbis comment 结构
+func parseComments(b []byte, magic string) ([]string, error) {
+	if !bytes.HasPrefix(b, []byte(magic)) {
+		return nil, fmt.Errorf("%s not found", magic)
+	}
+	b = b[len(magic):]
+	readString := func() (string, bool) {
+		if len(b) < 4 {
+			return "", false
+		}
+		n := int(binary.LittleEndian.Uint32(b))
+		if n > len(b)-4 {
+			return "", false
+		}
+		s := string(b[4 : 4+n])
+		b = b[4+n:]
+		return s, true
+	}
+	if _, ok := readString(); !ok { // vendor
+		return nil, errors.New("invalid comment header")
+	}
+	if len(b) < 4 {
+		return nil, errors.New("invalid comment header")
+	}
+	count := int(binary.LittleEndian.Uint32(b))
+	b = b[4:]
+	comments := make([]string, 0, min(count, 64))
+	for range count {
+		s, ok := readString()
+		if !ok {
+			break
+		}
+		comments = append(comments, s)
+	}
+	return comments, nil
+}
+
+// Comments OpusTags 中的 "KEY=value" 列表
+func (o *OggOpus) Comments() []string {
+	return o.comments
+}
+
+// Packet 返回下一个 Opus 包
+//
+// 数据不足时返回 io.ErrUnexpectedEOF 或 io.EOF 且不改变读取位置，可在数据增加后重试。
+func (o *OggOpus) Packet() ([]byte, error) {
+	for len(o.pending) == 0 {
+		page, err := readOggPage(o.r, o.next, true)
+		if err != nil {
+			return nil, err
+		}
+		o.next += page.size
+		if page.serial != o.serial {
+			continue
+		}
+		o.appendIndex(oggIndexEntry{offset: o.next, granule: page.granule})
+
+		drop := o.dropFirst && page.flags&oggContinued != 0
+		o.dropFirst = false
+		if page.flags&oggContinued == 0 {
+			o.partial = nil
+		}
+		var pos int
+		for _, s := range page.segments {
+			o.partial = append(o.partial, page.body[pos:pos+int(s)]...)
+			pos += int(s)
+			if s == 255 {
+				continue
+			}
+			if drop {
+				drop = false
+			} else {
+				o.pending = append(o.pending, o.partial)
+			}
+			o.partial = nil
+		}
+		if drop {
+			// 残包跨越整页，继续在下一页丢弃
+			o.dropFirst, o.partial = true, nil
+		}
+	}
+	p := o.pending[0]
+	o.pending = o.pending[1:]
+	return p, nil
+}
+
+func (o *OggOpus) appendIndex(e oggIndexEntry) {
+	if e.granule < 0 {
+		return
+	}
+	if n := len(o.index); n == 0 || o.index[n-1].offset < e.offset {
+		o.index = append(o.index, e)
+	}
+}
+
+// SeekGranule 定位到不晚于粒度位置 g 开始的包，返回下一个包的起始粒度位置
+func (o *OggOpus) SeekGranule(g int64) (int64, error) {
+	// 补全索引直到越过目标位置，文件未下载完时只能跳到已有的范围
+	offset := o.dataStart
+	if n := len(o.index); n > 0 {
+		offset = o.index[n-1].offset
+	}
+	for len(o.index) == 0 || o.index[len(o.index)-1].granule <= g {
+		page, err := readOggPage(o.r, offset, false)
+		if err != nil {
+			break
+		}
+		offset += page.size
+		if page.serial == o.serial {
+			o.appendIndex(oggIndexEntry{offset: offset, granule: page.granule})
+		}
+	}
+
+	o.pending, o.partial = nil, nil
+	o.next, o.dropFirst = o.dataStart, false
+	var start int64
+	for _, e := range o.index {
+		if e.granule > g {
+			break
+		}
+		o.next, start, o.dropFirst = e.offset, e.granule, true
+	}
+	return start, nil
+}
+
+// OpusLastGranule 读取文件末尾最后一页的粒度位置，用于计算完整文件的时长
+func OpusLastGranule(r io.ReadSeeker) (int64, error) {
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	start := max(end-oggMaxPageBytes, 0)
+	if _, err = r.Seek(start, io.SeekStart); err != nil {
+		return 0, err
+	}
+	tail := make([]byte, end-start)
+	if _, err = io.ReadFull(r, tail); err != nil {
+		return 0, err
+	}
+	for i := bytes.LastIndex(tail, []byte("OggS")); i >= 0; i = bytes.LastIndex(tail[:i], []byte("OggS")) {
+		if i+14 > len(tail) {
+			continue
+		}
+		if g := int64(binary.LittleEndian.Uint64(tail[i+6 : i+14])); g >= 0 {
+			return g, nil
+		}
+	}
+	return 0, errors.New("ogg page not found")
+}
+
+// OpusDuration 完整 Ogg Opus 文件的时长，单位为 48kHz 采样
+func OpusDuration(r io.ReadSeeker) (int64, error) {
+	o, err := OpenOggOpus(r)
+	if err != nil {
+		return 0, err
+	}
+	g, err := OpusLastGranule(r)
+	if err != nil {
+		return 0, err
+	}
+	return max(g-int64(o.PreSkip), 0), nil
+}
@@ -0,0 +1,143 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// oggPageBytes 组装一页，segments 为 lacing 值，body 为各段数据的拼接
+func oggPageBytes(flags byte, granule int64, seq uint32, segments []byte, body []byte) []byte {
+	h := make([]byte, oggHeaderSize)
+	copy(h, "OggS")
+	h[5] = flags
+	binary.LittleEndian.PutUint64(h[6:], uint64(granule))
+	binary.LittleEndian.PutUint32(h[14:], 1)
+	binary.LittleEndian.PutUint32(h[18:], seq)
+	h[26] = byte(len(segments))
+	return bytes.Join([][]byte{h, segments, body}, nil)
+}
+
+func lacing(n int) []byte {
+	var s []byte
+	for ; n >= 255; n -= 255 {
+		s = append(s, 255)
+	}
+	return append(s, byte(n))
+}
+
+func fill(c byte, n int) []byte {
+	return bytes.Repeat([]byte{c}, n)
+}
+
+// buildTestOpus 合成 Ogg Opus 文件，包 c 跨越第二、三页
+func buildTestOpus() []byte {
+	head := append([]byte("OpusHead\x01\x02"), 0x38, 0x01) // PreSkip 312
+	head = append(head, be32(0)...)
+	head = append(head, 0x00, 0x01, 0x00) // Gain 256（1dB），映射族 0
+
+	tags := []byte("OpusTags")
+	tags = binary.LittleEndian.AppendUint32(tags, 4)
+	tags = append(tags, "test"...)
+	tags = binary.LittleEndian.AppendUint32(tags, 1)
+	tags = binary.LittleEndian.AppendUint32(tags, 11)
+	tags = append(tags, "TITLE=title"...)
+
+	a, b, c := fill('a', 100), fill('b', 300), fill('c', 400)
+	return bytes.Join([][]byte{
+		oggPageBytes(0x02, 0, 0, lacing(len(head)), head),
+		oggPageBytes(0, 0, 1, lacing(len(tags)), tags),
+		oggPageBytes(0, 1272, 2, append(lacing(100), lacing(300)...), append(a, b...)),
+		oggPageBytes(0, -1, 3, []byte{255}, c[:255]),
+		oggPageBytes(oggContinued, 2232, 4, []byte{145, 10}, append(c[255:], fill('d', 10)...)),
+	}, nil)
+}
+
+func TestOggOpus(t *testing.T) {
+	data := buildTestOpus()
+	o, err := OpenOggOpus(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("OpenOggOpus: %v", err)
+	}
+	if o.Channels != 2 || o.PreSkip != 312 || o.Gain != 256 {
+		t.Errorf("head = %d channels, %d pre-skip, %d gain", o.Channels, o.PreSkip, o.Gain)
+	}
+	if len(o.Comments()) != 1 || o.Comments()[0] != "TITLE=title" {
+		t.Errorf("Comments = %q", o.Comments())
+	}
+
+	want := [][]byte{fill('a', 100), fill('b', 300), fill('c', 400), fill('d', 10)}
+	for i, w := range want {
+		p, err := o.Packet()
+		if err != nil || !bytes.Equal(p, w) {
+			t.Fatalf("packet %d = %d bytes %v, want %d bytes of %q", i, len(p), err, len(w), w[0])
+		}
+	}
+	if _, err = o.Packet(); !errors.Is(err, io.EOF) {
+		t.Errorf("Packet at end err = %v, want io.EOF", err)
+	}
+
+	// 跳到第三页之后：从延续页开始读时丢弃残包
+	start, err := o.SeekGranule(2000)
+	if err != nil || start != 1272 {
+		t.Fatalf("SeekGranule(2000) = %d, %v, want 1272", start, err)
+	}
+	if p, err := o.Packet(); err != nil || !bytes.Equal(p, want[2]) {
+		t.Errorf("packet after seek = %d bytes, %v", len(p), err)
+	}
+	if start, _ = o.SeekGranule(100); start != 0 {
+		t.Errorf("SeekGranule(100) = %d, want 0", start)
+	}
+	if p, err := o.Packet(); err != nil || !bytes.Equal(p, want[0]) {
+		t.Errorf("packet after seek to start = %d bytes, %v", len(p), err)
+	}
+
+	if d, err := OpusDuration(bytes.NewReader(data)); err != nil || d != 2232-312 {
+		t.Errorf("OpusDuration = %d, %v, want %d", d, err, 2232-312)
+	}
+}
+
+func TestOggOpusGrowingFile(t *testing.T) {
+	data := buildTestOpus()
+	path := filepath.Join(t.TempDir(), "growing.opus")
+	cut := len(data) - 50
+	if err := os.WriteFile(path, data[:cut], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	o, err := OpenOggOpus(file)
+	if err != nil {
+		t.Fatalf("OpenOggOpus: %v", err)
+	}
+	for range 2 {
+		if _, err = o.Packet(); err != nil {
+			t.Fatalf("Packet: %v", err)
+		}
+	}
+	// 最后一页尚未下载完，重试前不应改变读取位置
+	for range 2 {
+		if _, err = o.Packet(); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("Packet on short data err = %v, want io.ErrUnexpectedEOF", err)
+		}
+	}
+
+	w, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write(data[cut:])
+	_ = w.Close()
+
+	if p, err := o.Packet(); err != nil || len(p) != 400 {
+		t.Errorf("Packet after append = %d bytes, %v, want 400", len(p), err)
+	}
+}
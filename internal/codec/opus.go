@@ -0,0 +1,85 @@
+//go:build darwin || freebsd || linux || netbsd || windows
+
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	opusOnce sync.Once
+	opusErr  error
+
+	opusDecoderCreate  func(fs int32, channels int32, err *int32) uintptr
+	opusDecodeFloat    func(h uintptr, data *byte, size int32, pcm *float32, frameSize int32, decodeFEC int32) int32
+	opusDecoderDestroy func(h uintptr)
+	opusStrerror       func(code int32) string
+)
+
+func loadOpus() error {
+	opusOnce.Do(func() {
+		var lib uintptr
+		if lib, opusErr = loadLibrary(opusLibraryNames()); opusErr != nil {
+			return
+		}
+		opusErr = registerSymbols(lib, map[string]any{
+			"opus_decoder_create":  &opusDecoderCreate,
+			"opus_decode_float":    &opusDecodeFloat,
+			"opus_decoder_destroy": &opusDecoderDestroy,
+			"opus_strerror":        &opusStrerror,
+		})
+	})
+	return opusErr
+}
+
+// NewOpusDecoder 创建 48kHz 立体声解码器，单声道流会被复制到两个声道
+func NewOpusDecoder() (*OpusDecoder, error) {
+	if err := loadOpus(); err != nil {
+		return nil, err
+	}
+	d := &OpusDecoder{out: make([]float32, opusMaxFrameSize*2)}
+	if err := d.create(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *OpusDecoder) create() error {
+	var code int32
+	h := opusDecoderCreate(opusSampleRate, 2, &code)
+	if code != 0 || h == 0 {
+		return fmt.Errorf("opus_decoder_create: %s", opusStrerror(code))
+	}
+	d.handle = h
+	return nil
+}
+
+// Decode 解码一个 Opus 包，返回的切片在下一次调用前有效
+func (d *OpusDecoder) Decode(packet []byte) ([]float32, error) {
+	if d.handle == 0 {
+		return nil, errors.New("opus decoder is closed")
+	}
+	if len(packet) == 0 {
+		return nil, nil
+	}
+	n := opusDecodeFloat(d.handle, &packet[0], int32(len(packet)), &d.out[0], opusMaxFrameSize, 0)
+	if n < 0 {
+		return nil, fmt.Errorf("opus_decode_float: %s", opusStrerror(n))
+	}
+	return d.out[:2*n], nil
+}
+
+// Reset 跳转后重建解码器，清空状态
+func (d *OpusDecoder) Reset() error {
+	d.Close()
+	return d.create()
+}
+
+func (d *OpusDecoder) Close() {
+	if d.handle != 0 {
+		opusDecoderDestroy(d.handle)
+		d.handle = 0
+	}
+}
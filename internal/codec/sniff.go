@@ -0,0 +1,86 @@
+// Package codec 识别音频容器并解复用 MP4、ADTS、Ogg，AAC 与 Opus 通过运行时加载的
+// faad2、libopus 解码（purego，无需 CGO），系统未安装对应库时返回 ErrLibraryUnavailable。
+package codec
+
+import "bytes"
+
+// Format 根据文件头识别出的容器格式
+type Format uint8
+
+const (
+	FormatUnknown Format = iota
+	FormatMP3
+	FormatFLAC
+	FormatWAV
+	FormatOggVorbis
+	FormatOggOpus
+	FormatMP4
+	FormatADTS
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatMP3:
+		return "mp3"
+	case FormatFLAC:
+		return "flac"
+	case FormatWAV:
+		return "wav"
+	case FormatOggVorbis:
+		return "ogg"
+	case FormatOggOpus:
+		return "opus"
+	case FormatMP4:
+		return "m4a"
+	case FormatADTS:
+		return "aac"
+	default:
+		return "unknown"
+	}
+}
+
+// SniffSize 识别格式建议读取的文件头长度
+const SniffSize = 512
+
+// Sniff 根据文件头识别容器格式，无法识别时返回 FormatUnknown
+func Sniff(header []byte) Format {
+	switch {
+	case len(header) >= 8 && string(header[4:8]) == "ftyp":
+		return FormatMP4
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return FormatFLAC
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		return FormatWAV
+	case bytes.HasPrefix(header, []byte("OggS")):
+		if bytes.Contains(header, []byte("OpusHead")) {
+			return FormatOggOpus
+		}
+		return FormatOggVorbis
+	case bytes.HasPrefix(header, []byte("ID3")):
+		// ID3 标签之后通常是 MP3，少数 AAC 裸流也会带标签
+		if size, ok := id3Size(header); ok && size < len(header) {
+			if f := Sniff(header[size:]); f == FormatADTS {
+				return f
+			}
+		}
+		return FormatMP3
+	case isADTSHeader(header):
+		return FormatADTS
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0 && header[1]&0x06 != 0:
+		return FormatMP3
+	}
+	return FormatUnknown
+}
+
+// id3Size ID3v2 标签的总长度
+func id3Size(header []byte) (int, bool) {
+	if len(header) < 10 {
+		return 0, false
+	}
+	size := int(header[6]&0x7F)<<21 | int(header[7]&0x7F)<<14 | int(header[8]&0x7F)<<7 | int(header[9]&0x7F)
+	size += 10
+	if header[5]&0x10 != 0 {
+		size += 10 // footer
+	}
+	return size, true
+}
@@ -0,0 +1,77 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSniff(t *testing.T) {
+	id3 := func(next ...byte) []byte {
+		return append([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 0}, next...)
+	}
+	adts := []byte{0xFF, 0xF1, 0x50, 0x80, 0x02, 0x1F, 0xFC}
+	tests := []struct {
+		name   string
+		header []byte
+		want   Format
+	}{
+		{"mp4", []byte("\x00\x00\x00\x20ftypM4A "), FormatMP4},
+		{"flac", []byte("fLaC\x00\x00\x00\x22"), FormatFLAC},
+		{"wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), FormatWAV},
+		{"vorbis", []byte("OggS\x00\x02\x00\x00\x01vorbis"), FormatOggVorbis},
+		{"opus", []byte("OggS\x00\x02\x00\x00OpusHead"), FormatOggOpus},
+		{"mp3 with id3", id3(0xFF, 0xFB, 0x90, 0x00), FormatMP3},
+		{"adts with id3", id3(adts...), FormatADTS},
+		{"adts", adts, FormatADTS},
+		{"mp3 frame", []byte{0xFF, 0xFB, 0x90, 0x00}, FormatMP3},
+		{"unknown", []byte("hello world"), FormatUnknown},
+		{"empty", nil, FormatUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sniff(tt.header); got != tt.want {
+				t.Errorf("Sniff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestADTS(t *testing.T) {
+	frame := func(payload ...byte) []byte {
+		// AAC LC, 44100Hz, 立体声，无 CRC
+		length := 7 + len(payload)
+		return append([]byte{
+			0xFF, 0xF1, 0x50, 0x80 | byte(length>>11),
+			byte(length >> 3), byte(length<<5) | 0x1F, 0xFC,
+		}, payload...)
+	}
+	var data []byte
+	for i := range 3 {
+		data = append(data, frame(byte(i), byte(i), byte(i))...)
+	}
+	data = append(data, append([]byte("TAG"), make([]byte, 125)...)...) // ID3v1 标签
+
+	a, err := OpenADTS(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("OpenADTS: %v", err)
+	}
+	if got := a.Config(); got[0] != 0x12 || got[1] != 0x10 {
+		t.Errorf("Config = %x, want 1210", got)
+	}
+	if a.Timescale() != 44100 {
+		t.Errorf("Timescale = %d", a.Timescale())
+	}
+	packet, err := a.Packet(2)
+	if err != nil || len(packet) != 3 || packet[0] != 2 {
+		t.Errorf("Packet(2) = %v, %v", packet, err)
+	}
+	if d := a.Duration(); d != 3*1024 {
+		t.Errorf("Duration = %d, want %d", d, 3*1024)
+	}
+	if _, err = a.Packet(3); err == nil {
+		t.Error("Packet(3) should fail")
+	}
+	if i := a.PacketIndex(2048); i != 2 {
+		t.Errorf("PacketIndex(2048) = %d", i)
+	}
+}
@@ -30,6 +30,7 @@ func NewConfigCommand() *gcli.Command {
 				{"Music Cache Dir", app.MusicCacheDir()},
 				{"Download Dir", app.DownloadDir()},
 				{"Download Lyric Dir", app.DownloadLyricDir()},
+				{"Offline Dir", app.OfflineDir()},
 				{"Runtime Dir", app.RuntimeDir()},
 				{"Loaded Configuration File", app.ConfigFilePath()},
 			}
@@ -0,0 +1,261 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gookit/gcli/v2"
+
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
+)
+
+var ctlOpts struct {
+	socket string
+	json   bool
+}
+
+const ctlUsage = `actions:
+  play [n]                   Resume playback, or play the n-th song in the queue
+  pause | resume | toggle | stop
+  next | prev
+  seek [forward|back] <pos>  Seek to a position, or move relatively (pos: sec or mm:ss)
+  volume [n|up|down]         Print, set (0-100) or step the volume
+  status                     Print the playing info (--json for scripts)
+  like | dislike             Like or unlike the playing song
+  shuffle [on|off]           Toggle or set shuffle
+  repeat [off|one|all]       Cycle or set the repeat mode
+  queue [list]               Print the play queue ("+" marks songs in Up Next)
+  queue add <id>...          Append songs to the playlist
+  queue next|enqueue <id>... Add songs to the front or the end of Up Next
+  queue clear                Clear Up Next`
+
+func NewCtlCommand() *gcli.Command {
+	cmd := &gcli.Command{
+		Name:   "ctl",
+		UseFor: "Control a running musicfox (TUI or daemon) via the local socket",
+		Examples: "{$binName} {$cmd} toggle\n" +
+			"  {$binName} {$cmd} status --json\n" +
+			"  {$binName} {$cmd} seek 1:30\n" +
+			"  {$binName} {$cmd} seek back 10\n" +
+			"  {$binName} {$cmd} volume up\n" +
+			"  {$binName} {$cmd} queue next 1859245776\n\n" + ctlUsage,
+		Config: func(c *gcli.Command) {
+			c.Flags.StrOpt(&ctlOpts.socket, "socket", "s", "", "Path of the control socket (default: <runtime dir>/musicfox.sock)")
+			c.Flags.BoolOpt(&ctlOpts.json, "json", "j", false, "Print the result as JSON")
+			c.AddArg("action", "The control action, see below")
+			c.AddArg("args", "Arguments of the action", false, true)
+		},
+		Func: runCtl,
+	}
+	return cmd
+}
+
+func runCtl(c *gcli.Command, args []string) error {
+	if len(args) == 0 {
+		c.ShowHelp()
+		return nil
+	}
+
+	socketPath := ctlOpts.socket
+	if socketPath == "" {
+		socketPath = ipc.SocketPath()
+	}
+	call := func(req ipc.Request, result any) error {
+		return ipc.Call(socketPath, req, result)
+	}
+
+	req, err := parseCtlArgs(args[0], args[1:])
+	if err != nil {
+		return err
+	}
+
+	// 相对跳转需要先获取当前进度
+	if req.relative {
+		var status ipc.Status
+		if err = call(ipc.Request{Cmd: ipc.CmdStatus}, &status); err != nil {
+			return err
+		}
+		req.Position = max(status.PassedDuration+req.Position, 0)
+	}
+
+	switch req.Cmd {
+	case ipc.CmdStatus:
+		var status ipc.Status
+		if err = call(req.Request, &status); err != nil {
+			return err
+		}
+		if ctlOpts.json {
+			return printJSON(status)
+		}
+		printStatus(status)
+	case ipc.CmdQueue:
+		var queue ipc.QueueInfo
+		if err = call(req.Request, &queue); err != nil {
+			return err
+		}
+		if ctlOpts.json {
+			return printJSON(queue)
+		}
+		printQueue(queue)
+	case ipc.CmdVolume:
+		var volume ipc.VolumeInfo
+		if err = call(req.Request, &volume); err != nil {
+			return err
+		}
+		if ctlOpts.json {
+			return printJSON(volume)
+		}
+		fmt.Println(volume.Volume)
+	default:
+		return call(req.Request, nil)
+	}
+	return nil
+}
+
+// ctlRequest 命令行参数解析结果，relative 表示 Position 为相对当前进度的偏移量
+type ctlRequest struct {
+	ipc.Request
+	relative bool
+}
+
+func parseCtlArgs(action string, args []string) (ctlRequest, error) {
+	req := ctlRequest{Request: ipc.Request{Cmd: action}}
+	argc := func(maxArgs int) error {
+		if len(args) > maxArgs {
+			return fmt.Errorf("too many arguments for %s: %s", action, strings.Join(args, " "))
+		}
+		return nil
+	}
+
+	switch action {
+	case ipc.CmdPause, ipc.CmdResume, ipc.CmdToggle, ipc.CmdStop, ipc.CmdNext, ipc.CmdPrev,
+		ipc.CmdStatus, ipc.CmdLike, ipc.CmdDislike:
+		return req, argc(0)
+	case ipc.CmdPlay:
+		if err := argc(1); err != nil || len(args) == 0 {
+			return req, err
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return req, fmt.Errorf("invalid queue position: %s", args[0])
+		}
+		index := n - 1
+		req.Index = &index
+	case ipc.CmdSeek:
+		sign := 0.0
+		if len(args) == 2 {
+			switch args[0] {
+			case "forward":
+				sign = 1
+			case "back":
+				sign = -1
+			default:
+				return req, fmt.Errorf("invalid seek direction: %s", args[0])
+			}
+			args = args[1:]
+		}
+		if len(args) != 1 {
+			return req, fmt.Errorf("seek requires a position")
+		}
+		position, err := parsePosition(args[0])
+		if err != nil {
+			return req, err
+		}
+		if sign != 0 {
+			req.Position, req.relative = sign*position, true
+		} else {
+			req.Position = position
+		}
+	case ipc.CmdVolume:
+		if err := argc(1); err != nil || len(args) == 0 {
+			return req, err
+		}
+		if args[0] == ipc.VolumeUp || args[0] == ipc.VolumeDown {
+			req.Action = args[0]
+			return req, nil
+		}
+		volume, err := strconv.Atoi(args[0])
+		if err != nil || volume < 0 || volume > 100 {
+			return req, fmt.Errorf("invalid volume: %s", args[0])
+		}
+		req.Volume = &volume
+	case ipc.CmdShuffle, ipc.CmdRepeat:
+		if err := argc(1); err != nil || len(args) == 0 {
+			return req, err
+		}
+		req.Action = args[0]
+	case ipc.CmdQueue:
+		if len(args) == 0 {
+			return req, nil
+		}
+		req.Action = args[0]
+		for _, arg := range args[1:] {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return req, fmt.Errorf("invalid song id: %s", arg)
+			}
+			req.SongIDs = append(req.SongIDs, id)
+		}
+	default:
+		return req, fmt.Errorf("unknown action: %s\n\n%s", action, ctlUsage)
+	}
+	return req, nil
+}
+
+// parsePosition 解析秒数或 mm:ss 形式的位置
+func parsePosition(s string) (float64, error) {
+	var seconds float64
+	for part := range strings.SplitSeq(s, ":") {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid position: %s", s)
+		}
+		seconds = seconds*60 + v
+	}
+	return seconds, nil
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(v)
+}
+
+func printStatus(s ipc.Status) {
+	fmt.Printf("State:    %s\n", s.State)
+	if s.TrackID != 0 {
+		fmt.Printf("Title:    %s\n", s.Name)
+		fmt.Printf("Artist:   %s\n", s.Artist)
+		fmt.Printf("Album:    %s\n", s.Album)
+		fmt.Printf("Position: %s / %s\n", formatSeconds(s.PassedDuration), formatSeconds(s.TotalDuration))
+	}
+	fmt.Printf("Volume:   %d\n", s.Volume)
+	fmt.Printf("Repeat:   %s\n", s.LoopStatus)
+	fmt.Printf("Shuffle:  %t\n", s.Shuffle)
+	if s.UpNext > 0 {
+		fmt.Printf("Up Next:  %d\n", s.UpNext)
+	}
+}
+
+func printQueue(q ipc.QueueInfo) {
+	fmt.Printf("Mode: %s\n", q.Mode)
+	for i, song := range q.Songs {
+		marker := " "
+		switch {
+		case i == q.Index:
+			marker = "*"
+		case i > q.Index && i <= q.Index+q.UpNext:
+			marker = "+"
+		}
+		fmt.Printf("%s %3d. %s - %s (%s)\n", marker, i+1, song.Name, song.Artist, formatSeconds(song.Duration))
+	}
+}
+
+func formatSeconds(seconds float64) string {
+	d := time.Duration(seconds) * time.Second
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
+)
+
+func TestParsePosition(t *testing.T) {
+	tests := map[string]float64{
+		"90":      90,
+		"12.5":    12.5,
+		"1:30":    90,
+		"1:02:03": 3723,
+	}
+	for in, want := range tests {
+		got, err := parsePosition(in)
+		if err != nil || got != want {
+			t.Errorf("parsePosition(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"", "abc", "1:xx", "-5"} {
+		if _, err := parsePosition(in); err == nil {
+			t.Errorf("parsePosition(%q): want error", in)
+		}
+	}
+}
+
+func TestParseCtlArgs(t *testing.T) {
+	req, err := parseCtlArgs(ipc.CmdPlay, []string{"3"})
+	if err != nil || req.Index == nil || *req.Index != 2 {
+		t.Fatalf("play 3 = %+v, %v, want index 2", req, err)
+	}
+
+	req, err = parseCtlArgs(ipc.CmdSeek, []string{"back", "0:10"})
+	if err != nil || req.Position != -10 || !req.relative {
+		t.Fatalf("seek back 0:10 = %+v, %v, want relative -10", req, err)
+	}
+
+	req, err = parseCtlArgs(ipc.CmdVolume, []string{ipc.VolumeUp})
+	if err != nil || req.Action != ipc.VolumeUp || req.Volume != nil {
+		t.Fatalf("volume up = %+v, %v", req, err)
+	}
+
+	req, err = parseCtlArgs(ipc.CmdVolume, nil)
+	if err != nil || req.Volume != nil {
+		t.Fatalf("volume = %+v, %v, want query", req, err)
+	}
+
+	req, err = parseCtlArgs(ipc.CmdQueue, []string{ipc.QueueActionNext, "1", "2"})
+	if err != nil || req.Action != ipc.QueueActionNext || len(req.SongIDs) != 2 {
+		t.Fatalf("queue next 1 2 = %+v, %v", req, err)
+	}
+
+	invalid := [][]string{
+		{"dance"},
+		{ipc.CmdPlay, "0"},
+		{ipc.CmdPause, "now"},
+		{ipc.CmdSeek},
+		{ipc.CmdSeek, "sideways", "10"},
+		{ipc.CmdVolume, "loud"},
+		{ipc.CmdVolume, "120"},
+		{ipc.CmdQueue, ipc.QueueActionAdd, "abc"},
+	}
+	for _, args := range invalid {
+		if _, err := parseCtlArgs(args[0], args[1:]); err == nil {
+			t.Errorf("parseCtlArgs(%q): want error", args)
+		}
+	}
+}
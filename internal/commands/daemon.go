@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gookit/gcli/v2"
+
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
+	"github.com/go-musicfox/go-musicfox/internal/ui"
+)
+
+var daemonOpts struct {
+	socket string
+}
+
+func NewDaemonCommand() *gcli.Command {
+	cmd := &gcli.Command{
+		Name:   "daemon",
+		UseFor: "Run the player without TUI, controlled via a local socket",
+		Examples: "{$binName} {$cmd}                         # Listen on the default socket\n" +
+			"  {$binName} {$cmd} --socket /tmp/mfox.sock  # Listen on a custom socket",
+		Config: func(c *gcli.Command) {
+			c.Flags.StrOpt(&daemonOpts.socket, "socket", "s", "", "Path of the control socket (default: <runtime dir>/musicfox.sock)")
+		},
+		Func: runDaemon,
+	}
+	return cmd
+}
+
+func runDaemon(_ *gcli.Command, _ []string) error {
+	prepareRuntime()
+
+	socketPath := daemonOpts.socket
+	if socketPath == "" {
+		socketPath = ipc.SocketPath()
+	}
+
+	netease := ui.NewNetease(nil)
+	if err := netease.ListenControl(socketPath); err != nil {
+		_ = netease.Player().Close()
+		return err
+	}
+	netease.InitHook(nil)
+	slog.Info("Daemon started", "socket", socketPath)
+	fmt.Printf("musicfox daemon is listening on %s\n", socketPath)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	<-sigCh
+
+	slog.Info("Daemon shutting down")
+	netease.CloseHook(nil)
+	return nil
+}
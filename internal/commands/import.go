@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gookit/gcli/v2"
+	cookiejar "github.com/juju/persistent-cookiejar"
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/netease"
+	"github.com/go-musicfox/go-musicfox/internal/playlistimport"
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/utils/app"
+	neteaseurl "github.com/go-musicfox/go-musicfox/utils/netease"
+	neteaseutil "github.com/go-musicfox/netease-music/util"
+)
+
+// importSearchLimit 每行搜索结果数
+const importSearchLimit = 10
+
+var importOpts struct {
+	name      string
+	threshold float64
+	yes       bool
+	dryRun    bool
+	private   bool
+	report    string
+}
+
+func NewImportCommand() *gcli.Command {
+	cmd := &gcli.Command{
+		Name:   "import",
+		UseFor: "Import an external playlist (CSV, M3U, \"Artist - Title\" text) as a Netease playlist",
+		Examples: "{$binName} {$cmd} spotify.csv                 # Review ambiguous matches interactively\n" +
+			"  {$binName} {$cmd} songs.txt --name Favorites   # Set the playlist name\n" +
+			"  {$binName} {$cmd} list.m3u8 --yes --report r.txt  # Skip ambiguous matches, save the report\n" +
+			"  {$binName} {$cmd} songs.txt --dry-run          # Only print the matches",
+		Config: func(c *gcli.Command) {
+			c.Flags.StrOpt(&importOpts.name, "name", "n", "", "Name of the new playlist (default: file name)")
+			c.Flags.Float64Opt(&importOpts.threshold, "threshold", "t", playlistimport.DefaultAcceptThreshold, "Minimum score (0-1) to accept a match without review")
+			c.Flags.BoolOpt(&importOpts.yes, "yes", "y", false, "Do not ask, skip ambiguous matches")
+			c.Flags.BoolOpt(&importOpts.dryRun, "dry-run", "", false, "Match only, do not create the playlist")
+			c.Flags.BoolOpt(&importOpts.private, "private", "", false, "Create a private playlist")
+			c.Flags.StrOpt(&importOpts.report, "report", "r", "", "Write the report to this file instead of stdout")
+			c.AddArg("file", "The playlist file to import", true)
+		},
+		Func: runImport,
+	}
+	return cmd
+}
+
+func runImport(_ *gcli.Command, args []string) error {
+	prepareRuntime()
+
+	path := args[0]
+	entries, err := playlistimport.ParseFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "read %s", path)
+	}
+	name := importOpts.name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	// 仅搜索时不需要登录
+	if err = loadNeteaseSession(); err != nil && !importOpts.dryRun {
+		return err
+	}
+
+	matcher := playlistimport.NewMatcher(func(keyword string) ([]structs.Song, error) {
+		return netease.SearchSongs(keyword, importSearchLimit)
+	}, playlistimport.Options{AcceptThreshold: importOpts.threshold})
+	results := matcher.MatchAll(entries, func(done, total int) {
+		fmt.Printf("\rMatching %d/%d", done, total)
+	})
+	fmt.Println()
+
+	if !importOpts.yes {
+		reviewResults(os.Stdin, os.Stdout, results)
+	}
+
+	songs := playlistimport.Songs(results)
+	if importOpts.dryRun {
+		for _, song := range songs {
+			fmt.Printf("  %d  %s - %s\n", song.Id, song.ArtistName(), song.Name)
+		}
+	} else if len(songs) == 0 {
+		fmt.Println("No songs matched, playlist not created.")
+	} else {
+		playlistId, err := netease.CreatePlaylist(name, importOpts.private)
+		if err != nil {
+			return errors.Wrap(err, "create playlist")
+		}
+		ids := make([]int64, len(songs))
+		for i, song := range songs {
+			ids[i] = song.Id
+		}
+		if err = netease.AddSongsToPlaylist(playlistId, ids); err != nil {
+			return errors.Wrapf(err, "add songs to playlist %d", playlistId)
+		}
+		fmt.Printf("Created playlist %q with %d songs: %s\n", name, len(songs), neteaseurl.WebUrlOfPlaylist(playlistId))
+	}
+
+	if importOpts.report == "" {
+		fmt.Println()
+		return playlistimport.WriteReport(os.Stdout, results)
+	}
+	f, err := os.Create(importOpts.report)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err = playlistimport.WriteReport(f, results); err != nil {
+		return err
+	}
+	fmt.Printf("Report written to %s\n", importOpts.report)
+	return nil
+}
+
+// loadNeteaseSession 复用 TUI 登录后保存的 Cookie，只读不写回
+func loadNeteaseSession() error {
+	if _, err := storage.NewTable().GetByKVModel(storage.User{}); err != nil {
+		return errors.New("not logged in, please log in from the TUI first")
+	}
+	jar, err := cookiejar.New(&cookiejar.Options{Filename: filepath.Join(app.DataDir(), "cookie")})
+	if err != nil {
+		return errors.Wrap(err, "load cookie")
+	}
+	neteaseutil.SetGlobalCookieJar(jar)
+	return nil
+}
+
+// reviewResults 逐条确认待确认的匹配：输入序号采用候选，直接回车或输入 s 跳过，q 跳过剩余全部
+func reviewResults(in io.Reader, out io.Writer, results []playlistimport.Result) {
+	var pending []int
+	for i := range results {
+		if results[i].Status == playlistimport.StatusReview {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	reader := bufio.NewReader(in)
+	_, _ = fmt.Fprintf(out, "%d lines need review. Enter a number to accept, Enter or s to skip, q to skip the rest.\n", len(pending))
+	for n, i := range pending {
+		r := &results[i]
+		_, _ = fmt.Fprintf(out, "\n[%d/%d] line %d: %s\n", n+1, len(pending), r.Entry.Line, r.Entry)
+		for j, c := range r.Candidates {
+			_, _ = fmt.Fprintf(out, "  %d) %.2f  %s - %s  [%s]\n", j+1, c.Score, c.Song.ArtistName(), c.Song.Name, c.Song.Album.Name)
+		}
+		for {
+			_, _ = fmt.Fprint(out, "> ")
+			line, err := reader.ReadString('\n')
+			answer := strings.ToLower(strings.TrimSpace(line))
+			if answer == "q" || (err != nil && answer == "") {
+				return
+			}
+			if answer == "" || answer == "s" {
+				break
+			}
+			if index, convErr := strconv.Atoi(answer); convErr == nil && index >= 1 && index <= len(r.Candidates) {
+				r.Choose(index - 1)
+				break
+			}
+			_, _ = fmt.Fprintf(out, "Please enter 1-%d, s or q.\n", len(r.Candidates))
+			if err != nil {
+				return
+			}
+		}
+	}
+}
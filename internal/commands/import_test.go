@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-musicfox/go-musicfox/internal/playlistimport"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+func reviewResult(ids ...int64) playlistimport.Result {
+	r := playlistimport.Result{Status: playlistimport.StatusReview, Chosen: -1}
+	for _, id := range ids {
+		r.Candidates = append(r.Candidates, playlistimport.Candidate{Song: structs.Song{Id: id}})
+	}
+	return r
+}
+
+func TestReviewResults(t *testing.T) {
+	results := []playlistimport.Result{
+		reviewResult(1, 2),
+		{Status: playlistimport.StatusMatched, Chosen: 0, Candidates: []playlistimport.Candidate{{Song: structs.Song{Id: 3}}}},
+		reviewResult(4, 5),
+		reviewResult(6),
+		reviewResult(7),
+	}
+	// 第一条输入无效后选 2，第二条跳过，第三条选 1，之后全部跳过
+	reviewResults(strings.NewReader("9\n2\ns\n1\nq\n"), io.Discard, results)
+
+	want := []int64{2, 3, 0, 6, 0}
+	for i, id := range want {
+		song, _ := results[i].Song()
+		if song.Id != id {
+			t.Errorf("results[%d] song = %d, want %d", i, song.Id, id)
+		}
+	}
+}
+
+func TestReviewResultsEOF(t *testing.T) {
+	results := []playlistimport.Result{reviewResult(1), reviewResult(2)}
+	reviewResults(strings.NewReader("1"), io.Discard, results)
+	if song, ok := results[0].Song(); !ok || song.Id != 1 {
+		t.Errorf("results[0] = %+v, want song 1 accepted", results[0])
+	}
+	if _, ok := results[1].Song(); ok {
+		t.Errorf("results[1] should be skipped after EOF")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/mattn/go-runewidth"
 
 	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
 	"github.com/go-musicfox/go-musicfox/internal/storage"
 	"github.com/go-musicfox/go-musicfox/internal/types"
 	"github.com/go-musicfox/go-musicfox/internal/ui"
@@ -30,25 +31,7 @@ func NewPlayerCommand() *gcli.Command {
 }
 
 func runPlayer(_ *gcli.Command, _ []string) error {
-	if GlobalOptions.PProfMode {
-		errorx.Go(func() {
-			panic(http.ListenAndServe(":"+strconv.Itoa(configs.AppConfig.Main.Pprof.Port), nil))
-		}, true)
-	}
-
-	// Sync CLI --debug flag to AppConfig so it's visible to all packages.
-	// Must be done here (inside runPlayer) because gcli parses flags during
-	// app.Run(), which happens after cmd/musicfox.go's init-time sync attempt.
-	if GlobalOptions.DebugMode {
-		configs.AppConfig.Main.Debug = true
-	}
-
-	if GlobalOptions.DebugMode || configs.AppConfig.Main.Debug {
-		slogx.LevelVar().Set(slog.LevelDebug)
-	}
-
-	http.DefaultClient.Timeout = types.AppHttpTimeout
-	neteaseutil.HTTPClientTimeout = types.AppHttpTimeout
+	prepareRuntime()
 	runewidth.DefaultCondition.EastAsianWidth = false
 
 	opts := model.DefaultOptions()
@@ -59,14 +42,15 @@ func runPlayer(_ *gcli.Command, _ []string) error {
 	model.SearchResult = types.SearchResult
 	ui.SetupI18n(configs.AppConfig.Main.Locale)
 
-	// DBManager 初始化
-	storage.DBManager = new(storage.LocalDBManager)
-
 	var (
 		netease      = ui.NewNetease(model.NewApp(opts))
 		eventHandler = ui.NewEventHandler(netease)
 	)
 	eventHandler.RegisterGlobalHotkeys(opts)
+	// 供 musicfox ctl 控制，已有实例占用套接字时仅记录日志
+	if err := netease.ListenControl(ipc.SocketPath()); err != nil {
+		slog.Warn("Control socket unavailable", slogx.Error(err))
+	}
 	netease.With(
 		model.WithHook(netease.InitHook, netease.CloseHook),
 		model.WithMainMenu(ui.NewMainMenu(netease), &model.MenuItem{Title: "网易云音乐"}),
@@ -104,3 +88,29 @@ func runPlayer(_ *gcli.Command, _ []string) error {
 
 	return netease.Run()
 }
+
+// prepareRuntime 初始化 TUI 与 daemon 模式共用的运行环境
+func prepareRuntime() {
+	if GlobalOptions.PProfMode {
+		errorx.Go(func() {
+			panic(http.ListenAndServe(":"+strconv.Itoa(configs.AppConfig.Main.Pprof.Port), nil))
+		}, true)
+	}
+
+	// Sync CLI --debug flag to AppConfig so it's visible to all packages.
+	// Must be done here (inside the command func) because gcli parses flags during
+	// app.Run(), which happens after cmd/musicfox.go's init-time sync attempt.
+	if GlobalOptions.DebugMode {
+		configs.AppConfig.Main.Debug = true
+	}
+
+	if GlobalOptions.DebugMode || configs.AppConfig.Main.Debug {
+		slogx.LevelVar().Set(slog.LevelDebug)
+	}
+
+	http.DefaultClient.Timeout = types.AppHttpTimeout
+	neteaseutil.HTTPClientTimeout = types.AppHttpTimeout
+
+	// DBManager 初始化
+	storage.DBManager = new(storage.LocalDBManager)
+}
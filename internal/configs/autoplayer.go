@@ -17,8 +17,8 @@ const (
 )
 
 func (p AutoPlayerPlaylist) SpecialPlaylist() string {
-	if p == AutoPlayerPlaylistName {
-		return strings.TrimPrefix(string(p), "name:")
+	if name, ok := strings.CutPrefix(string(p), "name:"); ok {
+		return name
 	}
 	return ""
 }
@@ -49,7 +49,8 @@ type AutoPlayerOptions struct {
 
 func AutoPlayerPlaylistFromString(playlist string) AutoPlayerPlaylist {
 	if strings.HasPrefix(playlist, "name:") {
-		return AutoPlayerPlaylistName
+		// 保留歌单名，供 SpecialPlaylist 取出
+		return AutoPlayerPlaylist(playlist)
 	}
 	switch playlist {
 	case "dailyReco":
@@ -0,0 +1,13 @@
+package configs
+
+import "testing"
+
+func TestAutoPlayerPlaylistKeepsName(t *testing.T) {
+	playlist := AutoPlayerPlaylistFromString("name:早安歌单")
+	if got := playlist.SpecialPlaylist(); got != "早安歌单" {
+		t.Fatalf("SpecialPlaylist() = %q, want the configured playlist name", got)
+	}
+	if got := AutoPlayerPlaylistFromString("dailyReco").SpecialPlaylist(); got != "" {
+		t.Fatalf("SpecialPlaylist() = %q for a builtin playlist", got)
+	}
+}
@@ -13,16 +13,21 @@ import (
 
 // Config 是所有应用配置的根结构体
 type Config struct {
-	Startup     StartupConfig     `koanf:"startup"`
-	Main        MainConfig        `koanf:"main"`
-	Theme       ThemeConfig       `koanf:"theme"`
-	Storage     StorageConfig     `koanf:"storage"`
-	Player      PlayerConfig      `koanf:"player"`
-	Autoplay    AutoplayConfig    `koanf:"autoplay"`
-	UNM         UNMConfig         `koanf:"unm"`
-	Reporter    ReporterConfig    `koanf:"reporter"`
-	Keybindings KeybindingsConfig `koanf:"keybindings"`
-	Share       map[string]string `koanf:"share"`
+	Startup        StartupConfig         `koanf:"startup"`
+	Main           MainConfig            `koanf:"main"`
+	Theme          ThemeConfig           `koanf:"theme"`
+	Storage        StorageConfig         `koanf:"storage"`
+	Player         PlayerConfig          `koanf:"player"`
+	Autoplay       AutoplayConfig        `koanf:"autoplay"`
+	SleepTimer     SleepTimerConfig      `koanf:"sleepTimer"`
+	SmartPlaylists []SmartPlaylistConfig `koanf:"smartPlaylists"`
+	UNM            UNMConfig             `koanf:"unm"`
+	Reporter       ReporterConfig        `koanf:"reporter"`
+	Remote         RemoteConfig          `koanf:"remote"`
+	MediaRenderer  MediaRendererConfig   `koanf:"mediaRenderer"`
+	MpdServer      MpdServerConfig       `koanf:"mpdServer"`
+	Keybindings    KeybindingsConfig     `koanf:"keybindings"`
+	Share          map[string]string     `koanf:"share"`
 }
 
 func (cfg *Config) FillToModelOpts(opts *model.Options) {
@@ -0,0 +1,217 @@
+package configs
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/utils/filex"
+)
+
+// EqualizerConfig `beep` 引擎的均衡器配置
+type EqualizerConfig struct {
+	// 是否启用均衡器
+	Enable bool `koanf:"enable"`
+	// 预设名称（对应预设文件中的 name 字段）
+	Preset string `koanf:"preset"`
+}
+
+const (
+	// BuiltinEqualizerDir 内置均衡器预设目录
+	BuiltinEqualizerDir = "embed/equalizer"
+	// UserEqualizerSubDir 用户配置目录下自定义均衡器预设的子目录
+	UserEqualizerSubDir = "equalizer"
+
+	// EqualizerBandCount 峰值频段数
+	EqualizerBandCount = 10
+	// EqualizerMaxGain 单个频段的最大增益（dB）
+	EqualizerMaxGain = 12.0
+	// EqualizerDefaultPreset 找不到配置的预设时使用的预设
+	EqualizerDefaultPreset = "Flat"
+	// EqualizerCustomPreset 在均衡器页面调整后保存的预设名称
+	EqualizerCustomPreset = "Custom"
+)
+
+// EqualizerBandFrequencies 峰值频段的中心频率（Hz）
+var EqualizerBandFrequencies = [EqualizerBandCount]float64{31, 62, 125, 250, 500, 1000, 2000, 4000, 8000, 16000}
+
+// EqualizerPreset 均衡器预设，增益单位均为 dB
+type EqualizerPreset struct {
+	Name        string    `toml:"name"`
+	Description string    `toml:"description"`
+	Preamp      float64   `toml:"preamp"`
+	LowShelf    float64   `toml:"lowShelf"`  // 低架滤波器（80Hz）
+	HighShelf   float64   `toml:"highShelf"` // 高架滤波器（12kHz）
+	Bands       []float64 `toml:"bands"`     // 各峰值频段，与 EqualizerBandFrequencies 一一对应
+}
+
+// Normalize 补齐或截断频段数并将增益限制在 ±EqualizerMaxGain 内
+func (p EqualizerPreset) Normalize() EqualizerPreset {
+	clamp := func(gain float64) float64 {
+		return min(max(gain, -EqualizerMaxGain), EqualizerMaxGain)
+	}
+	bands := make([]float64, EqualizerBandCount)
+	for i := range min(len(p.Bands), EqualizerBandCount) {
+		bands[i] = clamp(p.Bands[i])
+	}
+	p.Bands = bands
+	p.Preamp = clamp(p.Preamp)
+	p.LowShelf = clamp(p.LowShelf)
+	p.HighShelf = clamp(p.HighShelf)
+	return p
+}
+
+// builtinEqualizerPriority 内置预设的显示顺序，用户预设按名称排在其后
+var builtinEqualizerPriority = map[string]int{
+	"Flat":         0,
+	"Bass Boost":   1,
+	"Treble Boost": 2,
+	"Vocal":        3,
+	"Rock":         4,
+	"Classical":    5,
+	"Electronic":   6,
+}
+
+var equalizerRegistry = struct {
+	sync.RWMutex
+	userDir string
+	presets map[string]*EqualizerPreset
+}{}
+
+// LoadEqualizerPresets 加载内置与用户自定义的均衡器预设，用户预设覆盖同名内置预设
+func LoadEqualizerPresets(userConfigDir string) {
+	if userConfigDir == "" {
+		userConfigDir = defaultUserConfigDir()
+	}
+	userDir := filepath.Join(userConfigDir, UserEqualizerSubDir)
+
+	presets := make(map[string]*EqualizerPreset)
+	if entries, err := filex.ReadDirFromEmbed(BuiltinEqualizerDir); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".toml") {
+				continue
+			}
+			data, err := filex.ReadFileFromEmbed(filepath.Join(BuiltinEqualizerDir, entry.Name()))
+			if err != nil {
+				slog.Warn("failed to read built-in equalizer preset", "file", entry.Name(), "err", err)
+				continue
+			}
+			addEqualizerPreset(presets, data, entry.Name())
+		}
+	}
+	if entries, err := os.ReadDir(userDir); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".toml") {
+				continue
+			}
+			path := filepath.Join(userDir, entry.Name())
+			data, err := os.ReadFile(path)
+			if err != nil {
+				slog.Warn("failed to read user equalizer preset", "file", path, "err", err)
+				continue
+			}
+			addEqualizerPreset(presets, data, path)
+		}
+	}
+
+	equalizerRegistry.Lock()
+	defer equalizerRegistry.Unlock()
+	equalizerRegistry.userDir = userDir
+	equalizerRegistry.presets = presets
+}
+
+func addEqualizerPreset(presets map[string]*EqualizerPreset, data []byte, file string) {
+	preset, err := parseEqualizerPreset(data)
+	if err != nil {
+		slog.Warn("failed to parse equalizer preset", "file", file, "err", err)
+		return
+	}
+	presets[preset.Name] = preset
+}
+
+// parseEqualizerPreset 解析 TOML 格式的均衡器预设
+func parseEqualizerPreset(data []byte) (*EqualizerPreset, error) {
+	var preset EqualizerPreset
+	if _, err := toml.Decode(string(data), &preset); err != nil {
+		return nil, errors.Wrap(err, "parse equalizer preset")
+	}
+	if preset.Name == "" {
+		return nil, errors.New("equalizer preset missing 'name' field")
+	}
+	preset = preset.Normalize()
+	return &preset, nil
+}
+
+// EqualizerPresetNames 所有预设名称，内置预设在前
+func EqualizerPresetNames() []string {
+	equalizerRegistry.RLock()
+	defer equalizerRegistry.RUnlock()
+	names := make([]string, 0, len(equalizerRegistry.presets))
+	for name := range equalizerRegistry.presets {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		pi, iBuiltin := builtinEqualizerPriority[names[i]]
+		pj, jBuiltin := builtinEqualizerPriority[names[j]]
+		if iBuiltin != jBuiltin {
+			return iBuiltin
+		}
+		if iBuiltin {
+			return pi < pj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// FindEqualizerPreset 按名称（不区分大小写）查找预设，找不到时返回平直预设和 false
+func FindEqualizerPreset(name string) (EqualizerPreset, bool) {
+	equalizerRegistry.RLock()
+	defer equalizerRegistry.RUnlock()
+	if preset, ok := equalizerRegistry.presets[name]; ok {
+		return *preset, true
+	}
+	for presetName, preset := range equalizerRegistry.presets {
+		if strings.EqualFold(presetName, name) {
+			return *preset, true
+		}
+	}
+	return EqualizerPreset{Name: EqualizerDefaultPreset}.Normalize(), false
+}
+
+// SaveEqualizerPreset 将预设保存到用户预设目录并立即生效
+func SaveEqualizerPreset(preset EqualizerPreset) error {
+	preset = preset.Normalize()
+	if preset.Name == "" {
+		return errors.New("equalizer preset name is empty")
+	}
+
+	equalizerRegistry.Lock()
+	defer equalizerRegistry.Unlock()
+	if equalizerRegistry.userDir == "" {
+		equalizerRegistry.userDir = filepath.Join(defaultUserConfigDir(), UserEqualizerSubDir)
+	}
+	if err := os.MkdirAll(equalizerRegistry.userDir, 0o755); err != nil {
+		return errors.Wrap(err, "create equalizer preset dir")
+	}
+
+	var buf strings.Builder
+	if err := toml.NewEncoder(&buf).Encode(preset); err != nil {
+		return errors.Wrap(err, "encode equalizer preset")
+	}
+	fileName := strings.ToLower(strings.ReplaceAll(preset.Name, " ", "_")) + ".toml"
+	if err := os.WriteFile(filepath.Join(equalizerRegistry.userDir, fileName), []byte(buf.String()), 0o644); err != nil {
+		return errors.Wrap(err, "write equalizer preset")
+	}
+	if equalizerRegistry.presets == nil {
+		equalizerRegistry.presets = make(map[string]*EqualizerPreset)
+	}
+	equalizerRegistry.presets[preset.Name] = &preset
+	return nil
+}
@@ -0,0 +1,51 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadEqualizerPresets(t *testing.T) {
+	dir := t.TempDir()
+	userDir := filepath.Join(dir, UserEqualizerSubDir)
+	if err := os.MkdirAll(userDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	user := "name = \"Bass Boost\"\npreamp = -20.0\nbands = [1.0, 2.0]\n"
+	if err := os.WriteFile(filepath.Join(userDir, "mine.toml"), []byte(user), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	LoadEqualizerPresets(dir)
+
+	names := EqualizerPresetNames()
+	if len(names) < 7 || names[0] != "Flat" || names[1] != "Bass Boost" {
+		t.Fatalf("preset names = %v", names)
+	}
+	preset, ok := FindEqualizerPreset("bass boost")
+	if !ok || preset.Preamp != -EqualizerMaxGain || len(preset.Bands) != EqualizerBandCount || preset.Bands[1] != 2 {
+		t.Fatalf("user preset not normalized or not overriding: %+v", preset)
+	}
+	if _, ok := FindEqualizerPreset("missing"); ok {
+		t.Fatal("found a preset that does not exist")
+	}
+
+	custom := EqualizerPreset{Name: EqualizerCustomPreset, Bands: []float64{3}}
+	if err := SaveEqualizerPreset(custom); err != nil {
+		t.Fatal(err)
+	}
+	LoadEqualizerPresets(dir)
+	if saved, ok := FindEqualizerPreset(EqualizerCustomPreset); !ok || saved.Bands[0] != 3 {
+		t.Fatalf("saved preset = %+v, ok = %t", saved, ok)
+	}
+	if !slices.Contains(EqualizerPresetNames(), EqualizerCustomPreset) {
+		t.Fatal("custom preset missing from names")
+	}
+}
+
+func TestParseEqualizerPresetRequiresName(t *testing.T) {
+	if _, err := parseEqualizerPreset([]byte("preamp = 1.0\n")); err == nil {
+		t.Fatal("expected error for preset without name")
+	}
+}
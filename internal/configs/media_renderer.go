@@ -0,0 +1,11 @@
+package configs
+
+// MediaRendererConfig 投屏接收（DLNA MediaRenderer）配置
+type MediaRendererConfig struct {
+	// 是否在局域网中公布为 DLNA 渲染器
+	Enable bool `koanf:"enable"`
+	// 控制点中显示的设备名称
+	Name string `koanf:"name"`
+	// 监听地址，为空时自动选择局域网地址和端口
+	Bind string `koanf:"bind"`
+}
@@ -0,0 +1,11 @@
+package configs
+
+// MpdServerConfig MPD 协议服务配置
+type MpdServerConfig struct {
+	// 是否启动 MPD 协议服务
+	Enable bool `koanf:"enable"`
+	// 监听地址，如 "127.0.0.1:6600"，以 / 或 ~/ 开头时为 Unix 套接字路径
+	Bind string `koanf:"bind"`
+	// 连接密码，客户端需先发送 password 命令，监听非本机地址时必须设置
+	Password string `koanf:"password"`
+}
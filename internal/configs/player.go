@@ -32,6 +32,7 @@ type PlayerConfig struct {
 	Mpd  MpdConfig  `koanf:"mpd"`
 	Mpv  MpvConfig  `koanf:"mpv"`
 	Dlna DlnaConfig `koanf:"dlna"`
+	Null NullConfig `koanf:"null"`
 }
 
 // BeepConfig `beep` 引擎专属配置
@@ -42,6 +43,20 @@ type BeepConfig struct {
 	Gapless bool `koanf:"gapless"`
 	// 提前多少秒预加载下一首
 	GaplessPreloadSeconds int `koanf:"gaplessPreloadSeconds"`
+	// 歌曲间交叉淡入淡出的秒数，0 为关闭
+	CrossfadeSeconds int `koanf:"crossfadeSeconds"`
+	// 淡入淡出曲线: linear、equal-power
+	CrossfadeCurve string `koanf:"crossfadeCurve"`
+	// 响度均衡模式: off、track、album
+	ReplayGain string `koanf:"replayGain"`
+	// 响度均衡的目标响度（LUFS）
+	ReplayGainTarget float64 `koanf:"replayGainTarget"`
+	// 响度均衡的前置增益（dB）
+	ReplayGainPreamp float64 `koanf:"replayGainPreamp"`
+	// 按每首歌曲的原始采样率打开输出设备
+	NativeSampleRate bool `koanf:"nativeSampleRate"`
+
+	Equalizer EqualizerConfig `koanf:"equalizer"`
 }
 
 // MpdConfig `mpd` 引擎专属配置
@@ -56,16 +71,40 @@ type MpdConfig struct {
 	Addr string `koanf:"addr"`
 	// mpd自动启动
 	AutoStart bool `koanf:"autoStart"`
+	// mpd fifo 音频输出的管道路径，为空时可视化不可用
+	FifoPath string `koanf:"fifoPath"`
+	// fifo 音频输出的格式，与 mpd 配置中的 format 一致
+	FifoFormat string `koanf:"fifoFormat"`
+	// 提前将下一首排入 mpd 播放列表，实现无缝切换
+	Gapless bool `koanf:"gapless"`
 }
 
 // MpvConfig `mpv` 引擎专属配置
 type MpvConfig struct {
 	// mpv路径
 	Bin string `koanf:"bin"`
+	// 启动旁路进程为可视化提供 PCM
+	PCMTap bool `koanf:"pcmTap"`
+	// 提前将下一首追加到 mpv 播放列表，实现无缝切换
+	Gapless bool `koanf:"gapless"`
 }
 
 // DlnaConfig `dlna` 引擎专属配置
 type DlnaConfig struct {
+	// 设备描述 URL，为空时自动搜索局域网中的设备
 	DeviceUrl string `koanf:"deviceUrl"`
-	LocalIP   string `koanf:"localIP"`
+	// 本机局域网 IP，为空时按设备所在网络自动检测
+	LocalIP string `koanf:"localIP"`
+	// 搜索设备时等待响应的秒数
+	DiscoverySeconds int `koanf:"discoverySeconds"`
+	// 通过 SetNextAVTransportURI 提前将下一首排入设备队列，实现无缝切换
+	Gapless bool `koanf:"gapless"`
+}
+
+// NullConfig `null` 引擎专属配置
+type NullConfig struct {
+	// 播放速度倍数
+	Speed float64 `koanf:"speed"`
+	// 下载并解码歌曲，为频谱提供 PCM
+	Decode bool `koanf:"decode"`
 }
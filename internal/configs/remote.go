@@ -0,0 +1,11 @@
+package configs
+
+// RemoteConfig HTTP 远程控制配置
+type RemoteConfig struct {
+	// 是否启用 HTTP 远程控制
+	Enable bool `koanf:"enable"`
+	// 监听地址，如 "127.0.0.1:9527"，"0.0.0.0:9527" 允许局域网访问
+	Bind string `koanf:"bind"`
+	// 访问令牌，监听非本机地址时必须设置
+	Token string `koanf:"token"`
+}
@@ -22,6 +22,7 @@ type ReporterNeteaseOptions struct {
 type ReporterConfig struct {
 	Netease NeteaseReporterConfig `koanf:"netease"`
 	Lastfm  LastfmReporterConfig  `koanf:"lastfm"`
+	History HistoryReporterConfig `koanf:"history"`
 }
 
 // NeteaseReporterConfig 上报至网易云音乐的配置
@@ -45,3 +46,9 @@ type LastfmReporterConfig struct {
 	// Last.fm 上报跳过电台节目
 	SkipDjRadio bool `koanf:"skipDjRadio"`
 }
+
+// HistoryReporterConfig 本地听歌记录的配置
+type HistoryReporterConfig struct {
+	// 是否在本地记录每次播放，用于听歌统计
+	Enable bool `koanf:"enable"`
+}
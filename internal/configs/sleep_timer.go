@@ -0,0 +1,37 @@
+package configs
+
+import "github.com/go-musicfox/go-musicfox/internal/types"
+
+// SleepTimerConfig 睡眠定时与闹钟配置
+type SleepTimerConfig struct {
+	// 定时停止前音量淡出的秒数，0 为直接停止
+	FadeOutSeconds int `koanf:"fadeOutSeconds"`
+	// 闹钟列表
+	Alarms []AlarmConfig `koanf:"alarms"`
+}
+
+// AlarmConfig 闹钟：到点后按自动播放的规则开始播放
+type AlarmConfig struct {
+	// 触发时间，24 小时制 "07:30"
+	Time string `koanf:"time"`
+	// 生效的星期，1~7 表示周一至周日，为空表示每天
+	Weekdays []int `koanf:"weekdays"`
+	// 播放的歌单，取值同 [autoplay] 的 playlist
+	Playlist AutoPlayerPlaylist `koanf:"playlist"`
+	// 播放列表的起始偏移量，取值同 [autoplay] 的 offset
+	Offset int `koanf:"offset"`
+	// 播放模式，取值同 [autoplay] 的 mode
+	Mode types.Mode `koanf:"mode"`
+	// 开始播放前设置的音量（1~100），0 为保持当前音量
+	Volume int `koanf:"volume"`
+}
+
+// Autoplay 转换为自动播放配置以复用 AutoPlayer
+func (a AlarmConfig) Autoplay() AutoplayConfig {
+	return AutoplayConfig{
+		Enable:   true,
+		Playlist: a.Playlist,
+		Offset:   a.Offset,
+		Mode:     a.Mode,
+	}
+}
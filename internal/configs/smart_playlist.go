@@ -0,0 +1,18 @@
+package configs
+
+// SmartPlaylistConfig 智能歌单：按规则从来源歌曲中筛选，每次打开时重新计算
+type SmartPlaylistConfig struct {
+	// 歌单名
+	Name string `koanf:"name"`
+	// 候选歌曲来源，多个来源合并去重
+	// 可选: "like", "dailyReco", "name:歌单名", "history"（本地听歌记录）, "local"（本地音乐）
+	Sources []string `koanf:"sources"`
+	// 筛选规则，如 "artist ~ 周杰伦"、"duration < 4m"、"lastPlayed > 30d"
+	Rules []string `koanf:"rules"`
+	// 规则的组合方式，"all" 为全部满足，"any" 为满足任意一条
+	Match string `koanf:"match"`
+	// 排序，如 "plays desc"、"duration"、"random"，为空时保持来源中的顺序
+	Sort string `koanf:"sort"`
+	// 最多保留的歌曲数，0 为不限制
+	Limit int `koanf:"limit"`
+}
@@ -21,7 +21,17 @@ type StorageConfig struct {
 	// 下载文件名模板
 	FileNameTpl string `koanf:"fileNameTpl"`
 
-	Cache CacheConfig `koanf:"cache"`
+	Download DownloadConfig `koanf:"download"`
+	Cache    CacheConfig    `koanf:"cache"`
+	Local    LocalConfig    `koanf:"local"`
+}
+
+// DownloadConfig 批量下载队列相关设置
+type DownloadConfig struct {
+	// 同时下载的歌曲数
+	Concurrency int `koanf:"concurrency"`
+	// 单首歌曲下载失败后的最大重试次数
+	MaxRetries int `koanf:"maxRetries"`
 }
 
 // CacheConfig 音乐播放缓存相关设置
@@ -33,3 +43,11 @@ type CacheConfig struct {
 	// 缓存大小（以MB为单位），0为不使用缓存，-1为不限制
 	Limit int64 `koanf:"limit"`
 }
+
+// LocalConfig 本地音乐库相关设置
+type LocalConfig struct {
+	// 需要扫描的本地音乐目录
+	Dirs []string `koanf:"dirs"`
+	// 启动时是否在后台增量扫描
+	ScanOnStartup bool `koanf:"scanOnStartup"`
+}
@@ -0,0 +1,434 @@
+// Package download 实现批量下载队列：持久化任务、限制并发、记录进度并在失败后退避重试。
+package download
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/track"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// progressInterval 进度变化时触发更新回调的最小间隔
+const progressInterval = 200 * time.Millisecond
+
+// Queue 下载队列的持久化接口
+type Queue interface {
+	Jobs() ([]storage.DownloadJob, error)
+	Put(job storage.DownloadJob) error
+	Remove(songID int64) error
+}
+
+// Downloader 下载单首歌曲，通常为 track.Manager
+type Downloader interface {
+	DownloadSongWithProgress(ctx context.Context, song structs.Song, progress track.ProgressFunc) (string, error)
+}
+
+// Manager 下载管理器
+type Manager struct {
+	downloader  Downloader
+	queue       Queue
+	concurrency int
+	maxRetries  int
+	backoff     func(attempt int) time.Duration
+	onUpdate    func()
+	onDone      func(job storage.DownloadJob)
+
+	mu         sync.Mutex
+	cond       *sync.Cond
+	jobs       map[int64]*storage.DownloadJob
+	running    map[int64]runningJob
+	attempts   uint64
+	lastUpdate time.Time
+	started    bool
+	closed     bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// runningJob 一次正在进行的下载，删除后重新添加的同一首歌以 attempt 区分新旧两次下载
+type runningJob struct {
+	attempt uint64
+	cancel  context.CancelFunc
+}
+
+// Option 是用于配置 Manager 的函数类型。
+type Option func(*Manager)
+
+// WithQueue 指定任务存储，默认使用 storage.DownloadQueue
+func WithQueue(queue Queue) Option {
+	return func(m *Manager) {
+		m.queue = queue
+	}
+}
+
+// WithConcurrency 同时下载的歌曲数，默认 3
+func WithConcurrency(n int) Option {
+	return func(m *Manager) {
+		if n > 0 {
+			m.concurrency = n
+		}
+	}
+}
+
+// WithMaxRetries 单首歌曲失败后的最大重试次数，默认 3
+func WithMaxRetries(n int) Option {
+	return func(m *Manager) {
+		if n >= 0 {
+			m.maxRetries = n
+		}
+	}
+}
+
+// WithBackoff 自定义第 attempt 次失败后的等待时间
+func WithBackoff(backoff func(attempt int) time.Duration) Option {
+	return func(m *Manager) {
+		m.backoff = backoff
+	}
+}
+
+// WithUpdateHook 任务状态或进度变化时调用，进度变化的调用频率受限
+func WithUpdateHook(hook func()) Option {
+	return func(m *Manager) {
+		m.onUpdate = hook
+	}
+}
+
+// WithDoneHook 任务完成（成功或最终失败）时调用
+func WithDoneHook(hook func(job storage.DownloadJob)) Option {
+	return func(m *Manager) {
+		m.onDone = hook
+	}
+}
+
+// New 创建下载管理器，调用 Start 后开始下载
+func New(downloader Downloader, opts ...Option) *Manager {
+	m := &Manager{
+		downloader:  downloader,
+		queue:       storage.DownloadQueue{},
+		concurrency: 3,
+		maxRetries:  3,
+		backoff:     exponentialBackoff,
+		jobs:        make(map[int64]*storage.DownloadJob),
+		running:     make(map[int64]runningJob),
+	}
+	m.cond = sync.NewCond(&m.mu)
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// exponentialBackoff 2s、4s、8s…，最长 1 分钟
+func exponentialBackoff(attempt int) time.Duration {
+	d := 2 * time.Second << max(attempt-1, 0)
+	return min(d, time.Minute)
+}
+
+// Start 恢复上次未完成的任务并启动下载协程
+func (m *Manager) Start() {
+	m.mu.Lock()
+	if m.started || m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.started = true
+
+	jobs, err := m.queue.Jobs()
+	if err != nil {
+		// 队列桶尚未创建
+		slog.Debug("Download queue is empty", slogx.Error(err))
+	}
+	for i := range jobs {
+		job := jobs[i]
+		if job.State == storage.DownloadRunning || job.State == storage.DownloadRetrying {
+			job.State = storage.DownloadQueued
+		}
+		m.jobs[job.Song.Id] = &job
+	}
+	m.mu.Unlock()
+
+	for range m.concurrency {
+		m.wg.Add(1)
+		go m.worker()
+	}
+}
+
+// Close 停止下载，进行中的任务在下次启动时续传
+func (m *Manager) Close() {
+	m.mu.Lock()
+	m.closed = true
+	m.cancel()
+	m.cond.Broadcast()
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+// Add 将歌曲加入下载队列，已在队列中的歌曲会被跳过，已完成或失败的歌曲会重新下载。
+// 返回新加入的数量。
+func (m *Manager) Add(songs []structs.Song, group string) int {
+	m.mu.Lock()
+	var added int
+	now := time.Now().UnixNano()
+	for _, song := range songs {
+		if song.Id == 0 || song.IsLocal() {
+			continue
+		}
+		if job, ok := m.jobs[song.Id]; ok && job.State != storage.DownloadDone && job.State != storage.DownloadFailed {
+			continue
+		}
+		job := &storage.DownloadJob{
+			Song:      song,
+			Group:     group,
+			State:     storage.DownloadQueued,
+			Total:     -1,
+			CreatedAt: now + int64(added), // 保证同一批次内的顺序
+			UpdatedAt: now,
+		}
+		m.jobs[song.Id] = job
+		m.persist(*job)
+		added++
+	}
+	if added > 0 {
+		m.cond.Broadcast()
+	}
+	m.mu.Unlock()
+
+	if added > 0 {
+		m.notify(true)
+	}
+	return added
+}
+
+// Jobs 返回全部任务的快照，按加入顺序排列
+func (m *Manager) Jobs() []storage.DownloadJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]storage.DownloadJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt < jobs[j].CreatedAt
+	})
+	return jobs
+}
+
+// Retry 重新下载失败的任务
+func (m *Manager) Retry(songID int64) bool {
+	m.mu.Lock()
+	job, ok := m.jobs[songID]
+	if !ok || job.State != storage.DownloadFailed {
+		m.mu.Unlock()
+		return false
+	}
+	job.State, job.Attempts, job.Error = storage.DownloadQueued, 0, ""
+	job.UpdatedAt = time.Now().UnixNano()
+	m.persist(*job)
+	m.cond.Broadcast()
+	m.mu.Unlock()
+
+	m.notify(true)
+	return true
+}
+
+// Remove 取消并删除一个任务，已下载的文件不会被删除
+func (m *Manager) Remove(songID int64) {
+	m.mu.Lock()
+	if running, ok := m.running[songID]; ok {
+		running.cancel()
+		delete(m.running, songID)
+	}
+	delete(m.jobs, songID)
+	if err := m.queue.Remove(songID); err != nil {
+		slog.Warn("Failed to remove download job", "songId", songID, slogx.Error(err))
+	}
+	m.mu.Unlock()
+
+	m.notify(true)
+}
+
+// ClearFinished 删除已完成的任务记录
+func (m *Manager) ClearFinished() int {
+	m.mu.Lock()
+	var cleared int
+	for id, job := range m.jobs {
+		if job.State != storage.DownloadDone {
+			continue
+		}
+		delete(m.jobs, id)
+		if err := m.queue.Remove(id); err != nil {
+			slog.Warn("Failed to remove download job", "songId", id, slogx.Error(err))
+		}
+		cleared++
+	}
+	m.mu.Unlock()
+
+	if cleared > 0 {
+		m.notify(true)
+	}
+	return cleared
+}
+
+func (m *Manager) worker() {
+	defer m.wg.Done()
+	for {
+		job, attempt, ctx, ok := m.next()
+		if !ok {
+			return
+		}
+		path, err := m.downloader.DownloadSongWithProgress(ctx, job.Song, func(downloaded, total int64) {
+			m.progress(job.Song.Id, attempt, downloaded, total)
+		})
+		m.finish(job.Song.Id, attempt, path, err)
+	}
+}
+
+// next 阻塞直到有排队的任务或管理器关闭，返回的 attempt 用于 progress 与 finish
+func (m *Manager) next() (storage.DownloadJob, uint64, context.Context, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for {
+		if m.closed {
+			return storage.DownloadJob{}, 0, nil, false
+		}
+		var next *storage.DownloadJob
+		for _, job := range m.jobs {
+			if job.State == storage.DownloadQueued && (next == nil || job.CreatedAt < next.CreatedAt) {
+				next = job
+			}
+		}
+		if next != nil {
+			next.State = storage.DownloadRunning
+			next.Attempts++
+			next.UpdatedAt = time.Now().UnixNano()
+			m.persist(*next)
+
+			ctx, cancel := context.WithCancel(m.ctx)
+			m.attempts++
+			m.running[next.Song.Id] = runningJob{attempt: m.attempts, cancel: cancel}
+			go m.notify(true)
+			return *next, m.attempts, ctx, true
+		}
+		m.cond.Wait()
+	}
+}
+
+// current 本次下载是否仍是该歌曲正在进行的下载，调用方需持有锁
+func (m *Manager) current(songID int64, attempt uint64) bool {
+	running, ok := m.running[songID]
+	return ok && running.attempt == attempt
+}
+
+func (m *Manager) progress(songID int64, attempt uint64, downloaded, total int64) {
+	m.mu.Lock()
+	job, ok := m.jobs[songID]
+	if !ok || !m.current(songID, attempt) {
+		m.mu.Unlock()
+		return
+	}
+	job.Downloaded, job.Total = downloaded, total
+	m.mu.Unlock()
+
+	m.notify(false)
+}
+
+func (m *Manager) finish(songID int64, attempt uint64, path string, err error) {
+	m.mu.Lock()
+	if !m.current(songID, attempt) {
+		// 任务已被删除，可能已重新添加并开始了新的下载
+		m.mu.Unlock()
+		return
+	}
+	m.running[songID].cancel()
+	delete(m.running, songID)
+	job, ok := m.jobs[songID]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+
+	done := true
+	job.UpdatedAt = time.Now().UnixNano()
+	switch {
+	case err == nil || errors.Is(err, os.ErrExist):
+		job.State, job.Path, job.Error = storage.DownloadDone, path, ""
+		job.Existed = err != nil
+		if job.Total > 0 {
+			job.Downloaded = job.Total
+		}
+	case m.closed && errors.Is(err, context.Canceled):
+		// 退出时中断的任务下次启动继续
+		job.State = storage.DownloadQueued
+		done = false
+	case job.Attempts <= m.maxRetries && !errors.Is(err, track.ErrOffline):
+		job.State, job.Error = storage.DownloadRetrying, err.Error()
+		delay := m.backoff(job.Attempts)
+		slog.Warn("Download failed, will retry", "songId", songID, "attempt", job.Attempts, "delay", delay, slogx.Error(err))
+		time.AfterFunc(delay, func() { m.requeue(songID) })
+		done = false
+	default:
+		job.State, job.Error = storage.DownloadFailed, err.Error()
+		slog.Error("Download failed", "songId", songID, "attempts", job.Attempts, slogx.Error(err))
+	}
+	m.persist(*job)
+	snapshot := *job
+	m.mu.Unlock()
+
+	m.notify(true)
+	if done && m.onDone != nil {
+		m.onDone(snapshot)
+	}
+}
+
+// requeue 退避结束后重新排队
+func (m *Manager) requeue(songID int64) {
+	m.mu.Lock()
+	job, ok := m.jobs[songID]
+	if m.closed || !ok || job.State != storage.DownloadRetrying {
+		m.mu.Unlock()
+		return
+	}
+	job.State = storage.DownloadQueued
+	m.persist(*job)
+	m.cond.Broadcast()
+	m.mu.Unlock()
+
+	m.notify(true)
+}
+
+// persist 写入任务记录，调用方需持有锁
+func (m *Manager) persist(job storage.DownloadJob) {
+	if err := m.queue.Put(job); err != nil {
+		slog.Warn("Failed to persist download job", "songId", job.Song.Id, slogx.Error(err))
+	}
+}
+
+// notify 调用更新回调，force 为 false 时按 progressInterval 限流
+func (m *Manager) notify(force bool) {
+	if m.onUpdate == nil {
+		return
+	}
+	m.mu.Lock()
+	now := time.Now()
+	if !force && now.Sub(m.lastUpdate) < progressInterval {
+		m.mu.Unlock()
+		return
+	}
+	m.lastUpdate = now
+	m.mu.Unlock()
+
+	m.onUpdate()
+}
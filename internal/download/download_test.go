@@ -0,0 +1,310 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/track"
+)
+
+type memoryQueue struct {
+	mu   sync.Mutex
+	jobs map[int64]storage.DownloadJob
+}
+
+func newMemoryQueue() *memoryQueue {
+	return &memoryQueue{jobs: make(map[int64]storage.DownloadJob)}
+}
+
+func (q *memoryQueue) Jobs() ([]storage.DownloadJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var jobs []storage.DownloadJob
+	for _, job := range q.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (q *memoryQueue) Put(job storage.DownloadJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.jobs[job.Song.Id] = job
+	return nil
+}
+
+func (q *memoryQueue) Remove(songID int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.jobs, songID)
+	return nil
+}
+
+func (q *memoryQueue) get(songID int64) storage.DownloadJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.jobs[songID]
+}
+
+type fakeDownloader struct {
+	active    atomic.Int32
+	maxActive atomic.Int32
+	failures  map[int64]int // 每首歌前 n 次下载失败
+	err       error
+	block     chan struct{}
+
+	mu    sync.Mutex
+	calls map[int64]int
+}
+
+func (d *fakeDownloader) DownloadSongWithProgress(ctx context.Context, song structs.Song, progress track.ProgressFunc) (string, error) {
+	active := d.active.Add(1)
+	defer d.active.Add(-1)
+	for {
+		old := d.maxActive.Load()
+		if active <= old || d.maxActive.CompareAndSwap(old, active) {
+			break
+		}
+	}
+
+	d.mu.Lock()
+	d.calls[song.Id]++
+	call := d.calls[song.Id]
+	d.mu.Unlock()
+
+	if d.block != nil {
+		select {
+		case <-d.block:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	if call <= d.failures[song.Id] {
+		return "", d.err
+	}
+	progress(50, 100)
+	progress(100, 100)
+	return "/music/" + song.Name, nil
+}
+
+func songs(ids ...int64) []structs.Song {
+	var list []structs.Song
+	for _, id := range ids {
+		list = append(list, structs.Song{Id: id, Name: "song"})
+	}
+	return list
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func allInState(m *Manager, state storage.DownloadState) func() bool {
+	return func() bool {
+		for _, job := range m.Jobs() {
+			if job.State != state {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func TestDownloadsWithBoundedConcurrency(t *testing.T) {
+	block := make(chan struct{})
+	downloader := &fakeDownloader{block: block, calls: map[int64]int{}}
+	queue := newMemoryQueue()
+	m := New(downloader, WithQueue(queue), WithConcurrency(2))
+	m.Start()
+	defer m.Close()
+
+	if added := m.Add(songs(1, 2, 3, 4, 5), "playlist"); added != 5 {
+		t.Fatalf("added = %d, want 5", added)
+	}
+	if added := m.Add(songs(1), ""); added != 0 {
+		t.Fatalf("re-added queued song = %d, want 0", added)
+	}
+	waitFor(t, func() bool { return downloader.active.Load() == 2 })
+	close(block)
+	waitFor(t, allInState(m, storage.DownloadDone))
+
+	if got := downloader.maxActive.Load(); got != 2 {
+		t.Fatalf("max concurrent downloads = %d, want 2", got)
+	}
+	jobs := m.Jobs()
+	for i, job := range jobs {
+		if job.Song.Id != int64(i+1) || job.Downloaded != 100 || job.Path != "/music/song" || job.Group != "playlist" {
+			t.Fatalf("job %d = %+v", i, job)
+		}
+	}
+	if got := queue.get(3); got.State != storage.DownloadDone {
+		t.Fatalf("persisted job = %+v, want done", got)
+	}
+}
+
+func TestRetryWithBackoffThenFail(t *testing.T) {
+	downloader := &fakeDownloader{
+		failures: map[int64]int{1: 2, 2: 10},
+		err:      errors.New("network error"),
+		calls:    map[int64]int{},
+	}
+	var (
+		mu       sync.Mutex
+		doneJobs []storage.DownloadJob
+	)
+	m := New(downloader,
+		WithQueue(newMemoryQueue()),
+		WithMaxRetries(2),
+		WithBackoff(func(int) time.Duration { return time.Millisecond }),
+		WithDoneHook(func(job storage.DownloadJob) {
+			mu.Lock()
+			doneJobs = append(doneJobs, job)
+			mu.Unlock()
+		}))
+	m.Start()
+	defer m.Close()
+
+	m.Add(songs(1, 2), "")
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(doneJobs) == 2
+	})
+
+	jobs := m.Jobs()
+	if jobs[0].State != storage.DownloadDone || jobs[0].Attempts != 3 {
+		t.Fatalf("song 1 = %+v, want done after 3 attempts", jobs[0])
+	}
+	if jobs[1].State != storage.DownloadFailed || jobs[1].Attempts != 3 || jobs[1].Error != "network error" {
+		t.Fatalf("song 2 = %+v, want failed after 3 attempts", jobs[1])
+	}
+
+	if !m.Retry(2) {
+		t.Fatal("retry failed job")
+	}
+	waitFor(t, func() bool { return m.Jobs()[1].State == storage.DownloadFailed && m.Jobs()[1].Attempts == 3 })
+	if downloader.calls[2] != 6 {
+		t.Fatalf("song 2 download calls = %d, want 6", downloader.calls[2])
+	}
+}
+
+func TestOfflineErrorIsNotRetried(t *testing.T) {
+	downloader := &fakeDownloader{failures: map[int64]int{1: 1}, err: track.ErrOffline, calls: map[int64]int{}}
+	// 两个 worker 分别执行旧下载与新下载，旧的 worker 处理完 finish 后才会下载歌曲 2
+	m := New(downloader, WithQueue(newMemoryQueue()), WithConcurrency(2))
+	m.Start()
+	defer m.Close()
+
+	m.Add(songs(1), "")
+	waitFor(t, allInState(m, storage.DownloadFailed))
+	if downloader.calls[1] != 1 {
+		t.Fatalf("download calls = %d, want 1", downloader.calls[1])
+	}
+}
+
+func TestRestoresInterruptedJobs(t *testing.T) {
+	queue := newMemoryQueue()
+	_ = queue.Put(storage.DownloadJob{Song: structs.Song{Id: 1, Name: "a"}, State: storage.DownloadRunning, CreatedAt: 1})
+	_ = queue.Put(storage.DownloadJob{Song: structs.Song{Id: 2, Name: "b"}, State: storage.DownloadDone, CreatedAt: 2})
+
+	downloader := &fakeDownloader{calls: map[int64]int{}}
+	m := New(downloader, WithQueue(queue))
+	m.Start()
+	defer m.Close()
+
+	waitFor(t, allInState(m, storage.DownloadDone))
+	if downloader.calls[1] != 1 || downloader.calls[2] != 0 {
+		t.Fatalf("download calls = %v, want only the interrupted job", downloader.calls)
+	}
+	if cleared := m.ClearFinished(); cleared != 2 || len(m.Jobs()) != 0 {
+		t.Fatalf("cleared = %d, jobs left = %d", cleared, len(m.Jobs()))
+	}
+}
+
+func TestCloseKeepsRunningJobQueued(t *testing.T) {
+	queue := newMemoryQueue()
+	downloader := &fakeDownloader{block: make(chan struct{}), calls: map[int64]int{}}
+	m := New(downloader, WithQueue(queue))
+	m.Start()
+
+	m.Add(songs(1), "")
+	waitFor(t, func() bool { return downloader.active.Load() == 1 })
+	m.Close()
+
+	if got := queue.get(1); got.State != storage.DownloadQueued {
+		t.Fatalf("persisted state after close = %s, want queued", got.State)
+	}
+}
+
+type downloaderFunc func(ctx context.Context, song structs.Song, progress track.ProgressFunc) (string, error)
+
+func (f downloaderFunc) DownloadSongWithProgress(ctx context.Context, song structs.Song, progress track.ProgressFunc) (string, error) {
+	return f(ctx, song, progress)
+}
+
+func TestRemovedAttemptDoesNotAffectReaddedJob(t *testing.T) {
+	var calls atomic.Int32
+	releaseStale, releaseNew := make(chan struct{}), make(chan struct{})
+	newStarted, newCanceled := make(chan struct{}), make(chan struct{})
+	downloader := downloaderFunc(func(ctx context.Context, song structs.Song, progress track.ProgressFunc) (string, error) {
+		if song.Id == 2 {
+			return "/music/" + song.Name, nil
+		}
+		if calls.Add(1) == 1 {
+			// 被删除后迟迟才返回的旧下载
+			<-ctx.Done()
+			<-releaseStale
+			progress(1, 100)
+			return "", ctx.Err()
+		}
+		close(newStarted)
+		select {
+		case <-releaseNew:
+			progress(100, 100)
+			return "/music/" + song.Name, nil
+		case <-ctx.Done():
+			close(newCanceled)
+			return "", ctx.Err()
+		}
+	})
+	// 两个 worker 分别执行旧下载与新下载，旧的 worker 处理完 finish 后才会下载歌曲 2
+	m := New(downloader, WithQueue(newMemoryQueue()), WithConcurrency(2))
+	m.Start()
+	defer m.Close()
+
+	m.Add(songs(1), "")
+	waitFor(t, func() bool { return calls.Load() == 1 })
+	m.Remove(1)
+	if added := m.Add(songs(1), ""); added != 1 {
+		t.Fatalf("re-added removed song = %d, want 1", added)
+	}
+	<-newStarted
+
+	close(releaseStale)
+	m.Add(songs(2), "")
+	waitFor(t, func() bool { return m.Jobs()[1].State == storage.DownloadDone })
+	if job := m.Jobs()[0]; job.State != storage.DownloadRunning || job.Error != "" || job.Downloaded != 0 {
+		t.Fatalf("job after stale finish = %+v", job)
+	}
+	select {
+	case <-newCanceled:
+		t.Fatal("stale attempt canceled the new download")
+	default:
+	}
+
+	close(releaseNew)
+	waitFor(t, allInState(m, storage.DownloadDone))
+}
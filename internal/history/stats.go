@@ -0,0 +1,213 @@
+package history
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+// Period 统计周期
+type Period uint8
+
+const (
+	PeriodWeek  Period = iota // 本周（周一起）
+	PeriodMonth               // 本月
+	PeriodYear                // 今年
+	PeriodAll                 // 全部
+)
+
+// Periods 听歌统计页展示的周期
+var Periods = []Period{PeriodWeek, PeriodMonth, PeriodYear, PeriodAll}
+
+func (p Period) Name() string {
+	switch p {
+	case PeriodWeek:
+		return "本周"
+	case PeriodMonth:
+		return "本月"
+	case PeriodYear:
+		return "今年"
+	default:
+		return "全部"
+	}
+}
+
+// Start 周期的开始时间，PeriodAll 返回零值
+func (p Period) Start(now time.Time) time.Time {
+	y, m, d := now.Date()
+	switch p {
+	case PeriodWeek:
+		offset := (int(now.Weekday()) + 6) % 7 // 周一为 0
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, now.Location())
+	case PeriodMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, now.Location())
+	case PeriodYear:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, now.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// MinPlaysForSkipRate 参与跳过率排行的最少播放次数，避免只播放过一两次的歌曲排在前面
+const MinPlaysForSkipRate = 3
+
+// Count 某首歌、歌手或专辑的播放统计
+type Count struct {
+	ID     int64
+	Name   string
+	Song   structs.Song // 歌曲排行中的歌曲
+	Plays  int
+	Skips  int
+	Played time.Duration
+
+	LastPlayed time.Time // 最近一次开始播放的时间
+}
+
+// SkipRate 跳过率
+func (c Count) SkipRate() float64 {
+	if c.Plays == 0 {
+		return 0
+	}
+	return float64(c.Skips) / float64(c.Plays)
+}
+
+// Stats 一个周期内的听歌统计
+type Stats struct {
+	Plays    int
+	Skips    int
+	Listened time.Duration // 累计播放时长
+
+	Songs      []Count // 按播放次数排序
+	Artists    []Count
+	Albums     []Count
+	SkipRanked []Count // 按跳过率排序，只包含播放次数不少于 MinPlaysForSkipRate 的歌曲
+}
+
+// counter 按 key 累计播放次数，保持首次出现的顺序以便排序结果稳定
+type counter struct {
+	index  map[string]int
+	counts []Count
+}
+
+func (c *counter) add(key string, item Count, record storage.PlayRecord) {
+	if c.index == nil {
+		c.index = make(map[string]int)
+	}
+	i, ok := c.index[key]
+	if !ok {
+		i = len(c.counts)
+		c.index[key] = i
+		c.counts = append(c.counts, item)
+	}
+	c.counts[i].Plays++
+	c.counts[i].Played += record.Played
+	if !record.Completed {
+		c.counts[i].Skips++
+	}
+	if record.StartedAt.After(c.counts[i].LastPlayed) {
+		c.counts[i].LastPlayed = record.StartedAt
+	}
+}
+
+// ranked 按播放次数、播放时长降序
+func (c *counter) ranked() []Count {
+	slices.SortStableFunc(c.counts, func(a, b Count) int {
+		if n := cmp.Compare(b.Plays, a.Plays); n != 0 {
+			return n
+		}
+		return cmp.Compare(b.Played, a.Played)
+	})
+	return c.counts
+}
+
+// itemKey 有 ID 时按 ID 区分，否则按名称（本地歌曲等）
+func itemKey(id int64, name string) string {
+	if id != 0 {
+		return strconv.FormatInt(id, 10)
+	}
+	return "name:" + name
+}
+
+// SongKey 与 Stats.Songs 中的统计对应的 key
+func SongKey(song structs.Song) string {
+	return itemKey(song.Id, song.Name)
+}
+
+// Compute 统计播放记录
+func Compute(records []storage.PlayRecord) Stats {
+	var (
+		stats                  Stats
+		songs, artists, albums counter
+	)
+	for _, record := range records {
+		stats.Plays++
+		stats.Listened += record.Played
+		if !record.Completed {
+			stats.Skips++
+		}
+
+		song := record.Song
+		songs.add(SongKey(song), Count{ID: song.Id, Name: song.Name, Song: song}, record)
+		for _, artist := range song.Artists {
+			if artist.Name == "" {
+				continue
+			}
+			artists.add(itemKey(artist.Id, artist.Name), Count{ID: artist.Id, Name: artist.Name}, record)
+		}
+		if song.Album.Name != "" {
+			albums.add(itemKey(song.Album.Id, song.Album.Name), Count{ID: song.Album.Id, Name: song.Album.Name}, record)
+		}
+	}
+
+	stats.Songs = songs.ranked()
+	stats.Artists = artists.ranked()
+	stats.Albums = albums.ranked()
+	for _, c := range stats.Songs {
+		if c.Plays >= MinPlaysForSkipRate && c.Skips > 0 {
+			stats.SkipRanked = append(stats.SkipRanked, c)
+		}
+	}
+	slices.SortStableFunc(stats.SkipRanked, func(a, b Count) int {
+		if n := cmp.Compare(b.SkipRate(), a.SkipRate()); n != 0 {
+			return n
+		}
+		return cmp.Compare(b.Skips, a.Skips)
+	})
+	return stats
+}
+
+// Load 读取播放记录，按 Periods 中的各个周期分别统计
+func Load(now time.Time) ([]Stats, error) {
+	records, err := storage.PlayHistory{}.Records(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]Stats, len(Periods))
+	for i, period := range Periods {
+		start := period.Start(now)
+		// 记录按时间顺序排列，找到周期内的第一条即可
+		first, _ := slices.BinarySearchFunc(records, start, func(r storage.PlayRecord, t time.Time) int {
+			return r.StartedAt.Compare(t)
+		})
+		stats[i] = Compute(records[first:])
+	}
+	return stats, nil
+}
+
+// FormatDuration 格式化累计播放时长，如 "3小时25分钟"
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case hours == 0:
+		return strconv.Itoa(minutes) + "分钟"
+	case minutes == 0:
+		return strconv.Itoa(hours) + "小时"
+	default:
+		return strconv.Itoa(hours) + "小时" + strconv.Itoa(minutes) + "分钟"
+	}
+}
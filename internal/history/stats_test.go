@@ -0,0 +1,90 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+func TestPeriodStart(t *testing.T) {
+	// 2024-05-16 是周四
+	now := time.Date(2024, time.May, 16, 21, 30, 0, 0, time.Local)
+	tests := []struct {
+		period Period
+		want   time.Time
+	}{
+		{PeriodWeek, time.Date(2024, time.May, 13, 0, 0, 0, 0, time.Local)},
+		{PeriodMonth, time.Date(2024, time.May, 1, 0, 0, 0, 0, time.Local)},
+		{PeriodYear, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local)},
+		{PeriodAll, time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := tt.period.Start(now); !got.Equal(tt.want) {
+			t.Errorf("%s start = %v, want %v", tt.period.Name(), got, tt.want)
+		}
+	}
+
+	// 周日属于以周一开始的本周
+	sunday := time.Date(2024, time.May, 19, 8, 0, 0, 0, time.Local)
+	if got := PeriodWeek.Start(sunday); got.Day() != 13 {
+		t.Errorf("week start of sunday = %v", got)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	artistA := structs.Artist{Id: 1, Name: "A"}
+	artistB := structs.Artist{Id: 2, Name: "B"}
+	song1 := structs.Song{Id: 11, Name: "one", Artists: []structs.Artist{artistA}, Album: structs.Album{Id: 21, Name: "X"}}
+	song2 := structs.Song{Id: 12, Name: "two", Artists: []structs.Artist{artistA, artistB}, Album: structs.Album{Id: 22, Name: "Y"}}
+	song3 := structs.Song{Name: "local", Artists: []structs.Artist{{Name: "B"}}}
+
+	play := func(song structs.Song, played time.Duration, completed bool) storage.PlayRecord {
+		return storage.PlayRecord{Song: song, Played: played, Completed: completed}
+	}
+	stats := Compute([]storage.PlayRecord{
+		play(song1, 3*time.Minute, true),
+		play(song2, 10*time.Second, false),
+		play(song2, 5*time.Second, false),
+		play(song2, 4*time.Minute, true),
+		play(song1, 3*time.Minute, true),
+		play(song1, 3*time.Minute, true),
+		play(song3, time.Minute, true),
+	})
+
+	if stats.Plays != 7 || stats.Skips != 2 {
+		t.Errorf("plays = %d, skips = %d", stats.Plays, stats.Skips)
+	}
+	if want := 14*time.Minute + 15*time.Second; stats.Listened != want {
+		t.Errorf("listened = %v, want %v", stats.Listened, want)
+	}
+	if len(stats.Songs) != 3 || stats.Songs[0].ID != 11 || stats.Songs[0].Plays != 3 || stats.Songs[1].ID != 12 {
+		t.Fatalf("songs = %+v", stats.Songs)
+	}
+	// 同名但没有 ID 的歌手单独统计
+	if len(stats.Artists) != 3 || stats.Artists[0].Name != "A" || stats.Artists[0].Plays != 6 {
+		t.Fatalf("artists = %+v", stats.Artists)
+	}
+	if len(stats.Albums) != 2 || stats.Albums[0].ID != 21 {
+		t.Fatalf("albums = %+v", stats.Albums)
+	}
+	if len(stats.SkipRanked) != 1 || stats.SkipRanked[0].ID != 12 || stats.SkipRanked[0].Skips != 2 {
+		t.Fatalf("skip ranking = %+v", stats.SkipRanked)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		40 * time.Second:              "1分钟",
+		25 * time.Minute:              "25分钟",
+		2 * time.Hour:                 "2小时",
+		3*time.Hour + 25*time.Minute:  "3小时25分钟",
+		30*time.Hour + 59*time.Minute: "30小时59分钟",
+	}
+	for d, want := range tests {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// SSE 事件类型
+const (
+	EventState = "state" // 歌曲、播放状态、音量或播放模式变化，数据为 ipc.Status（不含 lrcText）
+	EventTime  = "time"  // 播放进度变化（每秒），数据为 TimeEvent
+	EventLyric = "lyric" // 当前歌词行变化，数据为 LyricEvent
+)
+
+// TimeEvent time 事件数据
+type TimeEvent struct {
+	Passed float64 `json:"passed"` // 秒
+	Total  float64 `json:"total"`  // 秒
+}
+
+// LyricEvent lyric 事件数据
+type LyricEvent struct {
+	Text string `json:"text"`
+}
+
+// eventState 单个 SSE 连接已推送的状态，用于判断是否需要推送新事件
+type eventState struct {
+	status ipc.Status
+	second int64
+	lyric  string
+	sent   bool
+}
+
+// diff 比较新状态并返回需要推送的事件，首次调用时返回全部事件
+func (e *eventState) diff(status ipc.Status, lyric string) []sseEvent {
+	passed := status.PassedDuration
+	second := int64(math.Floor(passed))
+	// 进度单独通过 time 事件推送，比较时忽略，避免每次轮询都触发 state 事件
+	status.LRCText, status.PassedDuration = "", 0
+
+	var events []sseEvent
+	if !e.sent || status != e.status {
+		current := status
+		current.PassedDuration = passed
+		events = append(events, sseEvent{name: EventState, data: current})
+	}
+	if !e.sent || second != e.second || status.TrackID != e.status.TrackID {
+		events = append(events, sseEvent{name: EventTime, data: TimeEvent{Passed: passed, Total: status.TotalDuration}})
+	}
+	if !e.sent || lyric != e.lyric {
+		events = append(events, sseEvent{name: EventLyric, data: LyricEvent{Text: lyric}})
+	}
+
+	e.status, e.second, e.lyric, e.sent = status, second, lyric, true
+	return events
+}
+
+type sseEvent struct {
+	name string
+	data any
+}
+
+func (ev sseEvent) writeTo(w io.Writer) error {
+	data, err := json.Marshal(ev.data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.name, data)
+	return err
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		slog.Debug("SSE flush not supported", slogx.Error(err))
+		return
+	}
+
+	poll := time.NewTicker(s.pollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(s.keepAlive)
+	defer keepAlive.Stop()
+
+	var state eventState
+	push := func() bool {
+		status, err := s.status()
+		if err != nil {
+			slog.Debug("SSE failed to get status", slogx.Error(err))
+			return true
+		}
+		events := state.diff(status, s.backend.CurrentLyric())
+		for _, ev := range events {
+			if err = ev.writeTo(w); err != nil {
+				return false
+			}
+		}
+		return len(events) == 0 || rc.Flush() == nil
+	}
+
+	if !push() {
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-poll.C:
+			if !push() {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
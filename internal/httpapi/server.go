@@ -0,0 +1,262 @@
+// Package httpapi 实现 HTTP 远程控制：REST 接口、SSE 事件流及内嵌的网页控制台。
+package httpapi
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
+	"github.com/go-musicfox/go-musicfox/utils/filex"
+)
+
+// maxBodySize 请求体的最大长度
+const maxBodySize = 1 << 20
+
+// ErrTokenRequired 监听非本机地址但未设置访问令牌
+var ErrTokenRequired = errors.New("remote: token is required when binding to a non-loopback address")
+
+// Backend 远程控制依赖的播放器能力
+type Backend interface {
+	ipc.Handler
+	// CurrentLyric 当前歌词行，没有歌词时为空
+	CurrentLyric() string
+}
+
+// postCommands 以 POST /<cmd> 形式暴露的命令，请求体与控制套接字的 ipc.Request 一致
+var postCommands = map[string]struct{}{
+	ipc.CmdPlay:    {},
+	ipc.CmdPause:   {},
+	ipc.CmdResume:  {},
+	ipc.CmdStop:    {},
+	ipc.CmdToggle:  {},
+	ipc.CmdNext:    {},
+	ipc.CmdPrev:    {},
+	ipc.CmdSeek:    {},
+	ipc.CmdVolume:  {},
+	ipc.CmdLike:    {},
+	ipc.CmdDislike: {},
+	ipc.CmdShuffle: {},
+	ipc.CmdRepeat:  {},
+	ipc.CmdQueue:   {},
+}
+
+// Server HTTP 远程控制服务
+type Server struct {
+	token    string
+	backend  Backend
+	listener net.Listener
+	server   *http.Server
+
+	// pollInterval SSE 检查播放状态变化的间隔
+	pollInterval time.Duration
+	// keepAlive SSE 心跳间隔
+	keepAlive time.Duration
+}
+
+// Listen 在 addr 上监听 HTTP 服务，token 为空时不校验身份，仅允许本机地址
+func Listen(addr, token string, backend Backend) (*Server, error) {
+	if token == "" && !isLoopback(addr) {
+		return nil, ErrTokenRequired
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", addr, err)
+	}
+
+	s := &Server{
+		token:        token,
+		backend:      backend,
+		listener:     listener,
+		pollInterval: 250 * time.Millisecond,
+		keepAlive:    15 * time.Second,
+	}
+	s.server = &http.Server{
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s, nil
+}
+
+// Addr 实际监听的地址
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Serve 处理请求直到 Close 被调用
+func (s *Server) Serve() error {
+	if err := s.server.Serve(s.listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Close 停止服务并断开所有连接（包括 SSE 连接）
+func (s *Server) Close() error {
+	err := s.server.Close()
+	// 未调用 Serve 时 http.Server 不会关闭 listener
+	_ = s.listener.Close()
+	return err
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.guard(s.handleIndex))
+	mux.HandleFunc("GET /now-playing", s.auth(s.handleNowPlaying))
+	mux.HandleFunc("GET /queue", s.auth(s.handleQueue))
+	mux.HandleFunc("GET /events", s.auth(s.handleEvents))
+	mux.HandleFunc("POST /{cmd}", s.auth(s.handleCommand))
+	return mux
+}
+
+// guard 拒绝可能由其他网页发起的请求，未设置令牌时这是唯一的防护：
+//   - Origin 存在且与本服务不同源
+//   - 未设置令牌时 Host 不是本机名称，防止 DNS 重绑定
+//   - POST 请求的 Content-Type 不是 application/json，表单无法跨站提交这种请求
+func (s *Server) guard(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" && !sameOrigin(origin, r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("cross-origin request from %s", origin))
+			return
+		}
+		if s.token == "" && !isLoopbackHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host %q is not allowed", r.Host))
+			return
+		}
+		if r.Method == http.MethodPost {
+			if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, errors.New("content type must be application/json"))
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// auth 在 guard 的基础上校验 Authorization: Bearer <token> 或 ?token=<token>（EventSource 无法设置请求头）
+func (s *Server) auth(next http.HandlerFunc) http.HandlerFunc {
+	return s.guard(func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			token := r.URL.Query().Get("token")
+			if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+				token = bearer
+			}
+			if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+				return
+			}
+		}
+		next(w, r)
+	})
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, _ *http.Request) {
+	page, err := filex.ReadFileFromEmbed("embed/web/index.html")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(page)
+}
+
+func (s *Server) handleNowPlaying(w http.ResponseWriter, _ *http.Request) {
+	s.dispatch(w, ipc.Request{Cmd: ipc.CmdStatus})
+}
+
+func (s *Server) handleQueue(w http.ResponseWriter, _ *http.Request) {
+	s.dispatch(w, ipc.Request{Cmd: ipc.CmdQueue, Action: ipc.QueueActionList})
+}
+
+func (s *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
+	cmd := r.PathValue("cmd")
+	if _, ok := postCommands[cmd]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown command: %q", cmd))
+		return
+	}
+
+	var req ipc.Request
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err = json.Unmarshal(body, &req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+			return
+		}
+	}
+	req.Cmd = cmd
+	s.dispatch(w, req)
+}
+
+func (s *Server) dispatch(w http.ResponseWriter, req ipc.Request) {
+	data, err := s.backend.Handle(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if data == nil {
+		data = ipc.Response{OK: true}
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (s *Server) status() (ipc.Status, error) {
+	data, err := s.backend.Handle(ipc.Request{Cmd: ipc.CmdStatus})
+	if err != nil {
+		return ipc.Status{}, err
+	}
+	status, ok := data.(ipc.Status)
+	if !ok {
+		return ipc.Status{}, fmt.Errorf("unexpected status type %T", data)
+	}
+	return status, nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, ipc.Response{Error: err.Error()})
+}
+
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	return err == nil && isLoopbackName(host)
+}
+
+// isLoopbackHost Host 请求头（端口可省略）是否为本机名称
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return isLoopbackName(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"))
+}
+
+func isLoopbackName(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// sameOrigin Origin 请求头是否与 Host 相同
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, host)
+}
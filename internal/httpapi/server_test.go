@@ -0,0 +1,259 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
+)
+
+type fakeBackend struct {
+	mu     sync.Mutex
+	status ipc.Status
+	lyric  string
+	got    []ipc.Request
+}
+
+func (b *fakeBackend) Handle(req ipc.Request) (any, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.got = append(b.got, req)
+	switch req.Cmd {
+	case ipc.CmdStatus:
+		return b.status, nil
+	case ipc.CmdQueue:
+		return ipc.QueueInfo{Index: 0, Songs: []ipc.QueueItem{{ID: 1, Name: "a"}}}, nil
+	case ipc.CmdSeek:
+		if req.Position < 0 {
+			return nil, errors.New("invalid position")
+		}
+	}
+	return nil, nil
+}
+
+func (b *fakeBackend) CurrentLyric() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lyric
+}
+
+func (b *fakeBackend) requests() []ipc.Request {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]ipc.Request(nil), b.got...)
+}
+
+func newTestServer(t *testing.T, token string, backend Backend) *httptest.Server {
+	t.Helper()
+	s := &Server{token: token, backend: backend, pollInterval: 10 * time.Millisecond, keepAlive: time.Minute}
+	ts := httptest.NewServer(s.routes())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func doRequest(t *testing.T, method, url, token, body string) (*http.Response, ipc.Response) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	var out ipc.Response
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return resp, out
+}
+
+func TestCommandsAndAuth(t *testing.T) {
+	backend := &fakeBackend{}
+	ts := newTestServer(t, "secret", backend)
+
+	if resp, _ := doRequest(t, http.MethodPost, ts.URL+"/next", "", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("no token status = %d, want 401", resp.StatusCode)
+	}
+	if resp, _ := doRequest(t, http.MethodPost, ts.URL+"/next", "wrong", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong token status = %d, want 401", resp.StatusCode)
+	}
+
+	resp, out := doRequest(t, http.MethodPost, ts.URL+"/next", "secret", "")
+	if resp.StatusCode != http.StatusOK || !out.OK {
+		t.Fatalf("next = %d %+v", resp.StatusCode, out)
+	}
+	resp, _ = doRequest(t, http.MethodPost, ts.URL+"/seek?token=secret", "", `{"position":42}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("seek status = %d", resp.StatusCode)
+	}
+	resp, out = doRequest(t, http.MethodPost, ts.URL+"/seek", "secret", `{"position":-1}`)
+	if resp.StatusCode != http.StatusBadRequest || out.Error != "invalid position" {
+		t.Fatalf("invalid seek = %d %+v", resp.StatusCode, out)
+	}
+	if resp, _ = doRequest(t, http.MethodPost, ts.URL+"/status", "secret", ""); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("POST /status = %d, want 404", resp.StatusCode)
+	}
+
+	got := backend.requests()
+	if len(got) != 3 || got[0].Cmd != ipc.CmdNext || got[1].Cmd != ipc.CmdSeek || got[1].Position != 42 {
+		t.Fatalf("requests = %+v", got)
+	}
+}
+
+func TestRejectsCrossSiteRequests(t *testing.T) {
+	backend := &fakeBackend{}
+	ts := newTestServer(t, "", backend)
+
+	send := func(header http.Header, host string) int {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/next", nil)
+		req.Header = header
+		if host != "" {
+			req.Host = host
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	jsonHeader := func(kv ...string) http.Header {
+		h := http.Header{"Content-Type": {"application/json"}}
+		for i := 0; i+1 < len(kv); i += 2 {
+			h.Set(kv[i], kv[i+1])
+		}
+		return h
+	}
+
+	if code := send(jsonHeader("Origin", "http://evil.example"), ""); code != http.StatusForbidden {
+		t.Errorf("cross-origin status = %d, want 403", code)
+	}
+	if code := send(jsonHeader(), "evil.example:9527"); code != http.StatusForbidden {
+		t.Errorf("rebinding host status = %d, want 403", code)
+	}
+	if code := send(http.Header{"Content-Type": {"text/plain"}}, ""); code != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain status = %d, want 415", code)
+	}
+	if code := send(jsonHeader("Origin", ts.URL), ""); code != http.StatusOK {
+		t.Errorf("same-origin status = %d, want 200", code)
+	}
+	if code := send(jsonHeader(), "localhost:9527"); code != http.StatusOK {
+		t.Errorf("localhost status = %d, want 200", code)
+	}
+	if got := backend.requests(); len(got) != 2 {
+		t.Errorf("requests = %+v, want only the two allowed ones", got)
+	}
+}
+
+func TestNowPlayingAndIndex(t *testing.T) {
+	backend := &fakeBackend{status: ipc.Status{Name: "song", State: "playing", Volume: 70}}
+	ts := newTestServer(t, "", backend)
+
+	resp, err := http.Get(ts.URL + "/now-playing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var status ipc.Status
+	if err = json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Name != "song" || status.Volume != 70 {
+		t.Fatalf("now-playing = %+v", status)
+	}
+
+	page, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer page.Body.Close()
+	if page.StatusCode != http.StatusOK || !strings.HasPrefix(page.Header.Get("Content-Type"), "text/html") {
+		t.Fatalf("index = %d %s", page.StatusCode, page.Header.Get("Content-Type"))
+	}
+}
+
+func TestEventsStream(t *testing.T) {
+	backend := &fakeBackend{status: ipc.Status{TrackID: 1, State: "playing", PassedDuration: 1.2, LRCText: "[00:00.00]x"}, lyric: "line 1"}
+	ts := newTestServer(t, "", backend)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type = %q", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	next := func() (string, string) {
+		var name, data string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read event: %v", err)
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case line == "" && name != "":
+				return name, data
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}
+
+	for _, want := range []string{EventState, EventTime, EventLyric} {
+		if name, data := next(); name != want {
+			t.Fatalf("event = %s %s, want %s", name, data, want)
+		} else if name == EventState && strings.Contains(data, "[00:00.00]") {
+			t.Fatalf("state event should not carry lrcText: %s", data)
+		}
+	}
+
+	// 仅进度变化时只推送 time 事件
+	backend.mu.Lock()
+	backend.status.PassedDuration = 2.5
+	backend.mu.Unlock()
+	if name, data := next(); name != EventTime || !strings.Contains(data, `"passed":2.5`) {
+		t.Fatalf("event = %s %s, want time", name, data)
+	}
+
+	backend.mu.Lock()
+	backend.lyric = "line 2"
+	backend.mu.Unlock()
+	if name, data := next(); name != EventLyric || !strings.Contains(data, "line 2") {
+		t.Fatalf("event = %s %s, want lyric", name, data)
+	}
+}
+
+func TestListenRequiresTokenForNonLoopback(t *testing.T) {
+	if _, err := Listen("0.0.0.0:0", "", &fakeBackend{}); !errors.Is(err, ErrTokenRequired) {
+		t.Fatalf("err = %v, want ErrTokenRequired", err)
+	}
+	s, err := Listen("127.0.0.1:0", "", &fakeBackend{})
+	if err != nil {
+		t.Fatalf("listen loopback: %v", err)
+	}
+	_ = s.Close()
+}
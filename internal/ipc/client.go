@@ -0,0 +1,53 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// callTimeout 单次请求的超时时间，songIds 相关命令需要请求网络，因此不宜过短
+const callTimeout = 30 * time.Second
+
+// ErrNotRunning 控制套接字上没有正在运行的实例
+var ErrNotRunning = errors.New("no running musicfox instance found on the control socket")
+
+// Call 连接 path 上的控制套接字发送一条请求，并将响应数据解码到 result（可为 nil）
+func Call(path string, req Request, result any) error {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED) {
+			return fmt.Errorf("%w: %s", ErrNotRunning, path)
+		}
+		return fmt.Errorf("dial %s: %w", path, err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(callTimeout))
+
+	if err = json.NewEncoder(conn).Encode(req); err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	var resp Response
+	if err = json.Unmarshal(line, &resp); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if !resp.OK {
+		return errors.New(resp.Error)
+	}
+	if result != nil && len(resp.Data) > 0 {
+		if err = json.Unmarshal(resp.Data, result); err != nil {
+			return fmt.Errorf("decode response data: %w", err)
+		}
+	}
+	return nil
+}
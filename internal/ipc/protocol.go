@@ -0,0 +1,134 @@
+// Package ipc 实现本地控制套接字：每行一个 JSON 请求，每行一个 JSON 响应。
+package ipc
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/go-musicfox/go-musicfox/internal/types"
+	"github.com/go-musicfox/go-musicfox/utils/app"
+)
+
+// 支持的命令
+const (
+	CmdPlay    = "play"    // 继续播放；指定 index 或 songIds 时切换播放
+	CmdPause   = "pause"   // 暂停
+	CmdResume  = "resume"  // 继续播放
+	CmdStop    = "stop"    // 停止
+	CmdToggle  = "toggle"  // 播放/暂停
+	CmdNext    = "next"    // 下一曲
+	CmdPrev    = "prev"    // 上一曲
+	CmdSeek    = "seek"    // 跳转到 position 秒
+	CmdVolume  = "volume"  // 设置音量为 volume，或按 action 为 up/down 调节，均未指定时仅返回当前音量
+	CmdStatus  = "status"  // 当前播放状态，见 Status
+	CmdLike    = "like"    // 喜欢当前歌曲
+	CmdDislike = "dislike" // 取消喜欢当前歌曲
+	CmdShuffle = "shuffle" // 随机播放，action 为 on/off，为空时切换
+	CmdRepeat  = "repeat"  // 循环模式，action 为 off/one/all，为空时轮换
+	CmdQueue   = "queue"   // 播放队列操作，见 QueueAction*
+)
+
+// shuffle 命令的模式
+const (
+	ShuffleOn  = "on"
+	ShuffleOff = "off"
+)
+
+// volume 命令的子操作
+const (
+	VolumeUp   = "up"
+	VolumeDown = "down"
+)
+
+// repeat 命令的模式
+const (
+	RepeatOff = "off" // 顺序播放
+	RepeatOne = "one" // 单曲循环
+	RepeatAll = "all" // 列表循环
+)
+
+// queue 命令的子操作
+const (
+	QueueActionList    = "list"    // 列出播放队列（默认）
+	QueueActionAdd     = "add"     // 将 songIds 添加到播放列表末尾
+	QueueActionNext    = "next"    // 将 songIds 添加到待播队列开头，作为下一曲
+	QueueActionEnqueue = "enqueue" // 将 songIds 添加到待播队列末尾
+	QueueActionClear   = "clear"   // 清空待播队列
+)
+
+// Request 控制请求
+type Request struct {
+	Cmd      string  `json:"cmd"`
+	Action   string  `json:"action,omitempty"`
+	Index    *int    `json:"index,omitempty"`
+	Position float64 `json:"position,omitempty"`
+	Volume   *int    `json:"volume,omitempty"`
+	SongIDs  []int64 `json:"songIds,omitempty"`
+}
+
+// Response 控制响应，Data 为命令相关的结果
+type Response struct {
+	OK    bool            `json:"ok"`
+	Error string          `json:"error,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// Handler 处理一条控制请求，返回值会被编码为 Response.Data
+type Handler interface {
+	Handle(req Request) (any, error)
+}
+
+// HandlerFunc 函数形式的 Handler
+type HandlerFunc func(req Request) (any, error)
+
+func (f HandlerFunc) Handle(req Request) (any, error) {
+	return f(req)
+}
+
+// SocketPath 默认的控制套接字路径
+func SocketPath() string {
+	return filepath.Join(app.RuntimeDir(), types.AppSocketFile)
+}
+
+// QueueItem 播放队列中的一首歌曲
+type QueueItem struct {
+	ID       int64   `json:"id"`
+	Name     string  `json:"name"`
+	Artist   string  `json:"artist"`
+	Album    string  `json:"album"`
+	Duration float64 `json:"duration"` // 秒
+}
+
+// QueueInfo queue list 的返回结果
+//
+// Songs 按播放顺序排列：播放列表中截至当前歌曲的部分、待播队列、播放列表的剩余部分。
+type QueueInfo struct {
+	Index  int         `json:"index"`
+	Mode   string      `json:"mode"`
+	Songs  []QueueItem `json:"songs"`
+	UpNext int         `json:"upNext"` // Index 之后属于待播队列的歌曲数
+}
+
+// Status status 的返回结果，与 remote_control.PlayingInfo 的字段一一对应
+type Status struct {
+	TotalDuration  float64 `json:"totalDuration"`  // 秒
+	PassedDuration float64 `json:"passedDuration"` // 秒
+	State          string  `json:"state"`          // playing/paused/stopped/interrupted/unknown
+	Volume         int     `json:"volume"`
+	TrackID        int64   `json:"trackId"`
+	PicUrl         string  `json:"picUrl"`
+	Name           string  `json:"name"`
+	Artist         string  `json:"artist"`
+	Album          string  `json:"album"`
+	AlbumArtist    string  `json:"albumArtist"`
+	LRCText        string  `json:"lrcText"`
+	LoopStatus     string  `json:"loopStatus"` // None/Track/Playlist
+	Shuffle        bool    `json:"shuffle"`
+	Queued         bool    `json:"queued"` // 当前歌曲来自待播队列
+	UpNext         int     `json:"upNext"` // 待播队列中等待播放的歌曲数
+}
+
+// VolumeInfo volume 的返回结果
+type VolumeInfo struct {
+	Volume int `json:"volume"`
+}
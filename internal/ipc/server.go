@@ -0,0 +1,163 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// maxLineSize 单条请求的最大长度
+const maxLineSize = 1 << 20
+
+// ErrAlreadyRunning 套接字已被其他正在运行的实例占用
+var ErrAlreadyRunning = errors.New("another musicfox instance is listening on the control socket")
+
+// Server 本地控制套接字服务
+type Server struct {
+	path     string
+	listener net.Listener
+	handler  Handler
+
+	// handleMu 保证请求串行处理，与 TUI 事件循环的调用方式保持一致
+	handleMu sync.Mutex
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// Listen 在 path 上监听 Unix 套接字。若存在残留的套接字文件则清理后重新监听。
+func Listen(path string, handler Handler) (*Server, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			_ = conn.Close()
+			return nil, ErrAlreadyRunning
+		}
+		if err = os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", path, err)
+	}
+	// 仅允许当前用户访问
+	_ = os.Chmod(path, 0600)
+
+	return &Server{
+		path:     path,
+		listener: listener,
+		handler:  handler,
+		conns:    make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Path 套接字路径
+func (s *Server) Path() string {
+	return s.path
+}
+
+// Serve 接受连接直到 Close 被调用
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close 停止监听并断开所有连接
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	err := s.listener.Close()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	_ = os.Remove(s.path)
+	return err
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+		s.wg.Done()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	encoder := json.NewEncoder(conn)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if err := encoder.Encode(s.handle(line)); err != nil {
+			slog.Debug("Failed to write control response", slogx.Error(err))
+			return
+		}
+	}
+}
+
+func (s *Server) handle(line []byte) Response {
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return Response{Error: "invalid request: " + err.Error()}
+	}
+
+	s.handleMu.Lock()
+	data, err := s.handler.Handle(req)
+	s.handleMu.Unlock()
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+
+	resp := Response{OK: true}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return Response{Error: "encode response: " + err.Error()}
+		}
+		resp.Data = raw
+	}
+	return resp
+}
@@ -0,0 +1,135 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func shortSocketPath(t *testing.T) string {
+	t.Helper()
+	// Unix 套接字路径长度有限，t.TempDir 可能过长
+	dir, err := os.MkdirTemp("", "mfox")
+	if err != nil {
+		t.Fatalf("mkdir temp: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return filepath.Join(dir, "ctl.sock")
+}
+
+func startServer(t *testing.T, path string, handler Handler) *Server {
+	t.Helper()
+	server, err := Listen(path, handler)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = server.Serve() }()
+	t.Cleanup(func() { _ = server.Close() })
+	return server
+}
+
+func TestServerHandlesJSONLines(t *testing.T) {
+	path := shortSocketPath(t)
+	var (
+		mu  sync.Mutex
+		got []Request
+	)
+	startServer(t, path, HandlerFunc(func(req Request) (any, error) {
+		mu.Lock()
+		got = append(got, req)
+		mu.Unlock()
+		if req.Cmd == "bad" {
+			return nil, errors.New("boom")
+		}
+		return map[string]string{"cmd": req.Cmd}, nil
+	}))
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.Write([]byte("{\"cmd\":\"seek\",\"position\":12.5}\n\n{\"cmd\":\"bad\"}\nnot json\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	readResp := func() Response {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		var resp Response
+		if err = json.Unmarshal(line, &resp); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		return resp
+	}
+
+	if resp := readResp(); !resp.OK || string(resp.Data) != `{"cmd":"seek"}` {
+		t.Fatalf("seek response = %+v", resp)
+	}
+	if resp := readResp(); resp.OK || resp.Error != "boom" {
+		t.Fatalf("bad response = %+v, want error boom", resp)
+	}
+	if resp := readResp(); resp.OK || resp.Error == "" {
+		t.Fatalf("invalid json response = %+v, want error", resp)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 || got[0].Position != 12.5 {
+		t.Fatalf("handled requests = %+v", got)
+	}
+}
+
+func TestListenReplacesStaleSocketAndRejectsRunningInstance(t *testing.T) {
+	path := shortSocketPath(t)
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatalf("write stale socket: %v", err)
+	}
+
+	server := startServer(t, path, HandlerFunc(func(Request) (any, error) { return nil, nil }))
+	if _, err := Listen(path, HandlerFunc(func(Request) (any, error) { return nil, nil })); !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("second listen err = %v, want ErrAlreadyRunning", err)
+	}
+
+	if err := server.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("socket file should be removed on close, stat err = %v", err)
+	}
+}
+
+func TestCallDecodesResponse(t *testing.T) {
+	path := shortSocketPath(t)
+	startServer(t, path, HandlerFunc(func(req Request) (any, error) {
+		if req.Cmd == CmdStatus {
+			return Status{Name: "song", State: "playing"}, nil
+		}
+		return nil, errors.New("boom")
+	}))
+
+	var status Status
+	if err := Call(path, Request{Cmd: CmdStatus}, &status); err != nil {
+		t.Fatalf("call status: %v", err)
+	}
+	if status.Name != "song" || status.State != "playing" {
+		t.Fatalf("status = %+v", status)
+	}
+	if err := Call(path, Request{Cmd: CmdNext}, nil); err == nil || err.Error() != "boom" {
+		t.Fatalf("call next err = %v, want boom", err)
+	}
+}
+
+func TestCallWithoutServer(t *testing.T) {
+	if err := Call(shortSocketPath(t), Request{Cmd: CmdStatus}, nil); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("err = %v, want ErrNotRunning", err)
+	}
+}
@@ -118,6 +118,12 @@ const (
 	OpActionOfPlayingSong
 
 	OpSwitchTheme
+	OpToggleOffline
+	OpEqualizer
+	OpSleepTimer
+	OpUpNext
+	OpMoveUpInUpNext
+	OpMoveDownInUpNext
 )
 
 var opNameToOperateMap = make(map[string]OperateType)
@@ -155,8 +161,8 @@ var keyBindingsRegistry = map[OperateType]OperationInfo{
 	OpLogout:                         {name: "logout", desc: "注销并退出"},
 	OpCurPlaylist:                    {name: "curPlaylist", desc: "显示当前播放列表"},
 	OpAppendSongsToNext:              {name: "appendSongsToNext", desc: "添加为下一曲播放"},
-	OpAppendSongsToEnd:               {name: "appendSongsAfterCurPlaylist", desc: "添加到播放列表末尾"},
-	OpDeleteSongFromPlaylist:         {name: "delSongFromCurPlaylist", desc: "从播放列表删除选中歌曲"},
+	OpAppendSongsToEnd:               {name: "appendSongsAfterCurPlaylist", desc: "添加到待播队列"},
+	OpDeleteSongFromPlaylist:         {name: "delSongFromCurPlaylist", desc: "从播放列表或本地歌单删除选中歌曲"},
 	OpLikePlayingSong:                {name: "likePlayingSong", desc: "喜欢播放中歌曲"},
 	OpDislikePlayingSong:             {name: "dislikePlayingSong", desc: "取消喜欢播放中歌曲"},
 	OpTrashPlayingSong:               {name: "trashPlayingSong", desc: "标记播放中歌曲为不喜欢"},
@@ -197,7 +203,14 @@ var keyBindingsRegistry = map[OperateType]OperationInfo{
 	OpActionOfSelected:    {name: "actionOfSelected", desc: "对于选中项或当前播放的操作"},
 	OpActionOfPlayingSong: {name: "actionOfPlayingSong", desc: "对于当前播放的操作"},
 
-	OpSwitchTheme: {name: "switchTheme", desc: "切换主题样式"},
+	OpSwitchTheme:   {name: "switchTheme", desc: "切换主题样式"},
+	OpToggleOffline: {name: "toggleOffline", desc: "切换离线模式"},
+	OpEqualizer:     {name: "equalizer", desc: "均衡器"},
+	OpSleepTimer:    {name: "sleepTimer", desc: "睡眠定时"},
+
+	OpUpNext:           {name: "upNext", desc: "显示待播队列"},
+	OpMoveUpInUpNext:   {name: "moveUpInUpNext", desc: "在待播队列或本地歌单中上移选中歌曲"},
+	OpMoveDownInUpNext: {name: "moveDownInUpNext", desc: "在待播队列或本地歌单中下移选中歌曲"},
 }
 
 // 默认操作 -> 快捷键数组映射
@@ -266,7 +279,14 @@ var defaultOtherOperateToKeys = map[OperateType][]string{
 	OpActionOfPlayingSong: {"M"},
 	OpToggleSortOrder:     {"|"},
 
-	OpSwitchTheme: {},
+	OpSwitchTheme:   {},
+	OpToggleOffline: {"ctrl+o"},
+	OpEqualizer:     {"ctrl+e"},
+	OpSleepTimer:    {"z"},
+
+	OpUpNext:           {"y", "Y"},
+	OpMoveUpInUpNext:   {"ctrl+up", "alt+k"},
+	OpMoveDownInUpNext: {"ctrl+down", "alt+j"},
 }
 
 var userOperateToKeys map[OperateType][]string
@@ -0,0 +1,346 @@
+package library
+
+import (
+	"context"
+	"hash/fnv"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+// SupportedExtensions 本地音乐库会索引的文件后缀
+var SupportedExtensions = []string{"mp3", "flac", "ogg", "wav", "m4a", "aac", "opus"}
+
+// UnknownName 标签缺失时歌手、专辑的分组名
+const UnknownName = "未知"
+
+// Index 本地音乐库索引的持久化接口
+type Index interface {
+	Tracks() ([]storage.LocalTrack, error)
+	Put(track storage.LocalTrack) error
+	Remove(path string) error
+}
+
+// Group 按歌手、专辑或文件夹聚合的一组歌曲
+type Group struct {
+	Name  string
+	Songs []structs.Song
+}
+
+// ScanResult 一次扫描的统计信息
+type ScanResult struct {
+	Total   int
+	Added   int
+	Updated int
+	Removed int
+}
+
+// Library 本地音乐库，负责扫描配置的目录并维护 bbolt 中的索引
+type Library struct {
+	dirs  []string
+	index Index
+
+	mu       sync.RWMutex
+	tracks   map[string]storage.LocalTrack
+	loaded   bool
+	scanning sync.Mutex
+}
+
+// Option 是用于配置 Library 的函数类型。
+type Option func(*Library)
+
+// WithIndex 指定索引存储，默认使用 storage.LocalLibrary
+func WithIndex(index Index) Option {
+	return func(l *Library) {
+		l.index = index
+	}
+}
+
+// New 创建本地音乐库
+func New(dirs []string, opts ...Option) *Library {
+	l := &Library{
+		index:  storage.LocalLibrary{},
+		tracks: make(map[string]storage.LocalTrack),
+	}
+	for _, dir := range dirs {
+		if dir = strings.TrimSpace(dir); dir == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		l.dirs = append(l.dirs, dir)
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Dirs 返回配置的扫描目录
+func (l *Library) Dirs() []string {
+	return l.dirs
+}
+
+// Load 从索引中加载已扫描的歌曲，仅首次调用时读取
+func (l *Library) Load() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.loaded {
+		return
+	}
+	l.loaded = true
+
+	tracks, err := l.index.Tracks()
+	if err != nil {
+		// 索引桶尚未创建
+		slog.Debug("Local library index is empty", "error", err)
+		return
+	}
+	for _, track := range tracks {
+		if !l.inDirs(track.Song.LocalPath) {
+			continue
+		}
+		l.tracks[track.Song.LocalPath] = track
+	}
+}
+
+// IsEmpty 索引中是否没有任何歌曲
+func (l *Library) IsEmpty() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.tracks) == 0
+}
+
+// Scan 增量扫描所有目录：未变化的文件直接复用索引，已删除的文件从索引移除
+func (l *Library) Scan(ctx context.Context) (ScanResult, error) {
+	l.scanning.Lock()
+	defer l.scanning.Unlock()
+	l.Load()
+
+	var result ScanResult
+	seen := make(map[string]struct{})
+	for _, dir := range l.dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				slog.Warn("Failed to walk local music dir", "path", path, "error", err)
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if d.IsDir() || !isSupported(path) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			seen[path] = struct{}{}
+
+			l.mu.RLock()
+			old, exists := l.tracks[path]
+			l.mu.RUnlock()
+			if exists && old.Size == info.Size() && old.ModTime == info.ModTime().UnixNano() {
+				return nil
+			}
+
+			track, err := newLocalTrack(path, info)
+			if err != nil {
+				slog.Warn("Failed to read local music tag", "path", path, "error", err)
+				return nil
+			}
+			if err = l.index.Put(track); err != nil {
+				return err
+			}
+			l.mu.Lock()
+			l.tracks[path] = track
+			l.mu.Unlock()
+			if exists {
+				result.Updated++
+			} else {
+				result.Added++
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return result, err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for path := range l.tracks {
+		if _, ok := seen[path]; ok {
+			continue
+		}
+		if err := l.index.Remove(path); err != nil {
+			return result, err
+		}
+		delete(l.tracks, path)
+		result.Removed++
+	}
+	result.Total = len(l.tracks)
+	return result, nil
+}
+
+// Songs 返回全部歌曲，按歌手、专辑、音轨号、路径排序
+func (l *Library) Songs() []structs.Song {
+	l.mu.RLock()
+	tracks := make([]storage.LocalTrack, 0, len(l.tracks))
+	for _, track := range l.tracks {
+		tracks = append(tracks, track)
+	}
+	l.mu.RUnlock()
+
+	sort.Slice(tracks, func(i, j int) bool {
+		a, b := tracks[i], tracks[j]
+		if a.Song.ArtistName() != b.Song.ArtistName() {
+			return a.Song.ArtistName() < b.Song.ArtistName()
+		}
+		if a.Song.Album.Name != b.Song.Album.Name {
+			return a.Song.Album.Name < b.Song.Album.Name
+		}
+		if a.TrackNumber != b.TrackNumber {
+			return a.TrackNumber < b.TrackNumber
+		}
+		return a.Song.LocalPath < b.Song.LocalPath
+	})
+
+	songs := make([]structs.Song, len(tracks))
+	for i, track := range tracks {
+		songs[i] = track.Song
+	}
+	return songs
+}
+
+// Artists 按歌手分组
+func (l *Library) Artists() []Group {
+	return groupBy(l.Songs(), func(song structs.Song) string {
+		if len(song.Artists) == 0 {
+			return UnknownName
+		}
+		return song.Artists[0].Name
+	})
+}
+
+// Albums 按专辑分组
+func (l *Library) Albums() []Group {
+	return groupBy(l.Songs(), func(song structs.Song) string {
+		return song.Album.Name
+	})
+}
+
+// Folders 按所在文件夹分组，分组名为相对于扫描目录的路径
+func (l *Library) Folders() []Group {
+	return groupBy(l.Songs(), func(song structs.Song) string {
+		dir := filepath.Dir(song.LocalPath)
+		for _, root := range l.dirs {
+			if rel, err := filepath.Rel(filepath.Dir(root), dir); err == nil && !strings.HasPrefix(rel, "..") {
+				return rel
+			}
+		}
+		return dir
+	})
+}
+
+func (l *Library) inDirs(path string) bool {
+	for _, dir := range l.dirs {
+		if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+func groupBy(songs []structs.Song, keyFn func(structs.Song) string) []Group {
+	var (
+		groups []Group
+		index  = make(map[string]int)
+	)
+	for _, song := range songs {
+		key := keyFn(song)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, Group{Name: key})
+		}
+		groups[i].Songs = append(groups[i].Songs, song)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+func isSupported(path string) bool {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	for _, supported := range SupportedExtensions {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
+
+func newLocalTrack(path string, info fs.FileInfo) (storage.LocalTrack, error) {
+	tag, err := readTrackTag(path)
+	if err != nil {
+		return storage.LocalTrack{}, err
+	}
+
+	song := structs.Song{
+		Id:        SongID(path),
+		Name:      tag.Title,
+		Duration:  tag.Duration,
+		LocalPath: path,
+	}
+	artist := tag.Artist
+	if artist == "" {
+		artist = tag.AlbumArtist
+	}
+	if artist == "" {
+		artist = UnknownName
+	}
+	for _, name := range strings.Split(artist, "/") {
+		if name = strings.TrimSpace(name); name != "" {
+			song.Artists = append(song.Artists, structs.Artist{Name: name})
+		}
+	}
+	song.Album.Name = tag.Album
+	if song.Album.Name == "" {
+		song.Album.Name = UnknownName
+	}
+	if tag.AlbumArtist != "" {
+		song.Album.Artists = []structs.Artist{{Name: tag.AlbumArtist}}
+	}
+
+	return storage.LocalTrack{
+		Song:        song,
+		TrackNumber: tag.TrackNumber,
+		Size:        info.Size(),
+		ModTime:     info.ModTime().UnixNano(),
+	}, nil
+}
+
+// SongID 根据文件路径生成稳定的负数 ID，避免与网易云歌曲 ID 冲突
+func SongID(path string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(path))
+	id := int64(h.Sum64() >> 1)
+	if id == 0 {
+		id = 1
+	}
+	return -id
+}
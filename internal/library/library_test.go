@@ -0,0 +1,147 @@
+package library
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bogem/id3v2/v2"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+)
+
+type memoryIndex struct {
+	tracks map[string]storage.LocalTrack
+	puts   int
+}
+
+func newMemoryIndex() *memoryIndex {
+	return &memoryIndex{tracks: make(map[string]storage.LocalTrack)}
+}
+
+func (m *memoryIndex) Tracks() ([]storage.LocalTrack, error) {
+	var tracks []storage.LocalTrack
+	for _, track := range m.tracks {
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+func (m *memoryIndex) Put(track storage.LocalTrack) error {
+	m.puts++
+	m.tracks[track.Song.LocalPath] = track
+	return nil
+}
+
+func (m *memoryIndex) Remove(path string) error {
+	delete(m.tracks, path)
+	return nil
+}
+
+func writeTaggedMp3(t *testing.T, path, title, artist, album string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if err != nil {
+		t.Fatalf("open id3v2: %v", err)
+	}
+	defer tag.Close()
+	tag.SetDefaultEncoding(id3v2.EncodingUTF8)
+	tag.SetTitle(title)
+	tag.SetArtist(artist)
+	tag.SetAlbum(album)
+	if err = tag.Save(); err != nil {
+		t.Fatalf("save id3v2: %v", err)
+	}
+}
+
+func TestScanIndexesTaggedFilesAndGroups(t *testing.T) {
+	root := t.TempDir()
+	writeTaggedMp3(t, filepath.Join(root, "a", "1.mp3"), "晴天", "周杰伦", "叶惠美")
+	writeTaggedMp3(t, filepath.Join(root, "a", "2.mp3"), "东风破", "周杰伦", "叶惠美")
+	writeTaggedMp3(t, filepath.Join(root, "b", "3.mp3"), "", "", "")
+	if err := os.WriteFile(filepath.Join(root, "b", "cover.jpg"), []byte("jpg"), 0644); err != nil {
+		t.Fatalf("write cover: %v", err)
+	}
+
+	index := newMemoryIndex()
+	lib := New([]string{root}, WithIndex(index))
+	result, err := lib.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if result.Total != 3 || result.Added != 3 {
+		t.Fatalf("scan result = %+v, want 3 added", result)
+	}
+
+	artists := lib.Artists()
+	if len(artists) != 2 || artists[0].Name != "周杰伦" || len(artists[0].Songs) != 2 {
+		t.Fatalf("artists = %+v, want 周杰伦 with 2 songs and 未知", artists)
+	}
+	albums := lib.Albums()
+	if len(albums) != 2 {
+		t.Fatalf("albums = %+v, want 2 groups", albums)
+	}
+	folders := lib.Folders()
+	if len(folders) != 2 || folders[0].Name != filepath.Join(filepath.Base(root), "a") {
+		t.Fatalf("folders = %+v, want grouped by sub dir", folders)
+	}
+
+	for _, song := range lib.Songs() {
+		if !song.IsLocal() || song.Id >= 0 {
+			t.Fatalf("song %+v should be local with negative id", song)
+		}
+		if song.LocalPath == filepath.Join(root, "b", "3.mp3") && song.Name != "3" {
+			t.Fatalf("untagged song name = %q, want file name", song.Name)
+		}
+	}
+}
+
+func TestScanIsIncrementalAndRemovesDeletedFiles(t *testing.T) {
+	root := t.TempDir()
+	kept := filepath.Join(root, "kept.mp3")
+	removed := filepath.Join(root, "removed.mp3")
+	writeTaggedMp3(t, kept, "kept", "x", "y")
+	writeTaggedMp3(t, removed, "removed", "x", "y")
+
+	index := newMemoryIndex()
+	if _, err := New([]string{root}, WithIndex(index)).Scan(context.Background()); err != nil {
+		t.Fatalf("first scan: %v", err)
+	}
+	if err := os.Remove(removed); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	// 新实例从索引加载，未变化的文件不应重新读取
+	index.puts = 0
+	lib := New([]string{root}, WithIndex(index))
+	result, err := lib.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("second scan: %v", err)
+	}
+	if index.puts != 0 {
+		t.Fatalf("unchanged file re-indexed %d times", index.puts)
+	}
+	if result.Removed != 1 || result.Total != 1 {
+		t.Fatalf("scan result = %+v, want 1 removed and 1 total", result)
+	}
+	if _, ok := index.tracks[removed]; ok {
+		t.Fatalf("deleted file still in index")
+	}
+}
+
+func TestSongIDIsStableAndNegative(t *testing.T) {
+	a, b := SongID("/music/a.mp3"), SongID("/music/a.mp3")
+	if a != b || a >= 0 {
+		t.Fatalf("SongID = (%d, %d), want equal negative ids", a, b)
+	}
+	if SongID("/music/b.mp3") == a {
+		t.Fatalf("different paths should have different ids")
+	}
+}
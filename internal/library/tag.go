@@ -0,0 +1,206 @@
+package library
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bogem/id3v2/v2"
+	songtag "github.com/frolovo22/tag"
+	goflac "github.com/go-flac/go-flac"
+	"github.com/gopxl/beep/wav"
+	"github.com/hajimehoshi/go-mp3"
+	"github.com/jfreymuth/oggvorbis"
+
+	"github.com/go-musicfox/go-musicfox/internal/codec"
+)
+
+// trackTag 从音频文件中读取到的元信息
+type trackTag struct {
+	Title       string
+	Artist      string
+	Album       string
+	AlbumArtist string
+	TrackNumber int
+	Duration    time.Duration
+}
+
+// readTrackTag 读取文件的标签与时长，标签缺失时以文件名作为标题
+func readTrackTag(path string) (trackTag, error) {
+	var tag trackTag
+
+	file, err := os.Open(path)
+	if err != nil {
+		return tag, err
+	}
+	defer file.Close()
+
+	// 以文件头识别格式，扩展名可能与实际格式不符
+	header := make([]byte, codec.SniffSize)
+	n, _ := io.ReadFull(file, header)
+	format := codec.Sniff(header[:n])
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return tag, err
+	}
+	switch format {
+	case codec.FormatOggVorbis:
+		if header, err := oggvorbis.GetCommentHeader(file); err == nil {
+			applyVorbisComments(header.Comments, &tag)
+		}
+	case codec.FormatOggOpus:
+		if ogg, err := codec.OpenOggOpus(file); err == nil {
+			applyVorbisComments(ogg.Comments(), &tag)
+		}
+	default:
+		switch songtag.CheckVersion(file) {
+		case songtag.VersionID3v22, songtag.VersionID3v23, songtag.VersionID3v24:
+			readID3v2Tag(path, &tag)
+		default:
+			readGenericTag(file, &tag)
+		}
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return tag, err
+	}
+	tag.Duration = readDuration(format, file)
+
+	if tag.Title == "" {
+		tag.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return tag, nil
+}
+
+func readID3v2Tag(path string, tag *trackTag) {
+	t, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if err != nil {
+		return
+	}
+	defer t.Close()
+
+	tag.Title = strings.TrimSpace(t.Title())
+	tag.Artist = strings.TrimSpace(t.Artist())
+	tag.Album = strings.TrimSpace(t.Album())
+	if tpe2 := t.GetTextFrame("TPE2"); tpe2.Text != "" {
+		tag.AlbumArtist = strings.TrimSpace(tpe2.Text)
+	}
+	if trck := t.GetTextFrame(t.CommonID("Track number/Position in set")); trck.Text != "" {
+		tag.TrackNumber = parseTrackNumber(trck.Text)
+	}
+}
+
+func readGenericTag(file *os.File, tag *trackTag) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	metadata, err := songtag.Read(file)
+	if err != nil {
+		return
+	}
+	if title, err := metadata.GetTitle(); err == nil {
+		tag.Title = strings.TrimSpace(title)
+	}
+	if artist, err := metadata.GetArtist(); err == nil {
+		tag.Artist = strings.TrimSpace(artist)
+	}
+	if album, err := metadata.GetAlbum(); err == nil {
+		tag.Album = strings.TrimSpace(album)
+	}
+	if albumArtist, err := metadata.GetAlbumArtist(); err == nil {
+		tag.AlbumArtist = strings.TrimSpace(albumArtist)
+	}
+	if number, _, err := metadata.GetTrackNumber(); err == nil {
+		tag.TrackNumber = number
+	}
+}
+
+// applyVorbisComments 解析 Ogg Vorbis 与 Opus 共用的 "KEY=value" 注释
+func applyVorbisComments(comments []string, tag *trackTag) {
+	for _, comment := range comments {
+		key, value, ok := strings.Cut(comment, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToUpper(key) {
+		case "TITLE":
+			tag.Title = value
+		case "ARTIST":
+			tag.Artist = value
+		case "ALBUM":
+			tag.Album = value
+		case "ALBUMARTIST":
+			tag.AlbumArtist = value
+		case "TRACKNUMBER":
+			tag.TrackNumber = parseTrackNumber(value)
+		}
+	}
+}
+
+// readDuration 计算音频时长，无法计算时返回 0
+func readDuration(format codec.Format, file *os.File) time.Duration {
+	switch format {
+	case codec.FormatMP3:
+		decoder, err := mp3.NewDecoder(file)
+		if err != nil || decoder.Length() <= 0 || decoder.SampleRate() <= 0 {
+			return 0
+		}
+		// go-mp3 固定输出 16bit 双声道，即每个采样 4 字节
+		samples := decoder.Length() / 4
+		return time.Duration(samples) * time.Second / time.Duration(decoder.SampleRate())
+	case codec.FormatFLAC:
+		f, err := goflac.ParseMetadata(file)
+		if err != nil {
+			return 0
+		}
+		info, err := f.GetStreamInfo()
+		if err != nil || info.SampleRate <= 0 {
+			return 0
+		}
+		return time.Duration(info.SampleCount) * time.Second / time.Duration(info.SampleRate)
+	case codec.FormatOggVorbis:
+		length, format, err := oggvorbis.GetLength(file)
+		if err != nil || format == nil || format.SampleRate <= 0 {
+			return 0
+		}
+		return time.Duration(length) * time.Second / time.Duration(format.SampleRate)
+	case codec.FormatOggOpus:
+		samples, err := codec.OpusDuration(file)
+		if err != nil {
+			return 0
+		}
+		return time.Duration(samples) * time.Second / 48000
+	case codec.FormatMP4:
+		m, err := codec.OpenMP4(file, true)
+		if err != nil {
+			return 0
+		}
+		return time.Duration(m.Duration()) * time.Second / time.Duration(m.Timescale())
+	case codec.FormatADTS:
+		a, err := codec.OpenADTS(file)
+		if err != nil {
+			return 0
+		}
+		return time.Duration(a.Duration()) * time.Second / time.Duration(a.Timescale())
+	case codec.FormatWAV:
+		streamer, format, err := wav.Decode(file)
+		if err != nil || format.SampleRate <= 0 {
+			return 0
+		}
+		return format.SampleRate.D(streamer.Len())
+	}
+	return 0
+}
+
+// parseTrackNumber 解析 "3" 或 "3/12" 形式的音轨号
+func parseTrackNumber(s string) int {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "/")
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,354 @@
+package mediarenderer
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+const (
+	transportActions = "Play,Pause,Stop,Seek"
+	// maxCounter 不支持字节计数时 RelCount/AbsCount 返回的值
+	maxCounter = "2147483647"
+	// trackEndGrace 开始播放后的这段时间内，播放器报告的停止视为仍在加载
+	trackEndGrace = 2 * time.Second
+)
+
+func (s *Server) avTransportAction(action string, args map[string]string) ([]arg, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch action {
+	case "SetAVTransportURI":
+		uri := strings.TrimSpace(args["CurrentURI"])
+		if err := checkURI(uri); err != nil {
+			return nil, err
+		}
+		metadata := args["CurrentURIMetaData"]
+		s.transport.uri, s.transport.metadata = uri, metadata
+		s.transport.meta = parseMetadata(metadata, uri)
+		s.transport.nextURI, s.transport.nextMetadata, s.transport.nextMeta = "", "", Metadata{}
+		// 播放中设置新地址时立即切换，否则等待控制点发送 Play
+		if s.transport.playing && uri != "" {
+			return nil, s.startLocked()
+		}
+		if s.backend.Status().Casting {
+			s.backend.Stop()
+		}
+		s.transport.loaded = ""
+		s.transport.playing = false
+	case "SetNextAVTransportURI":
+		uri := strings.TrimSpace(args["NextURI"])
+		if err := checkURI(uri); err != nil {
+			return nil, err
+		}
+		metadata := args["NextURIMetaData"]
+		s.transport.nextURI, s.transport.nextMetadata = uri, metadata
+		s.transport.nextMeta = parseMetadata(metadata, uri)
+	case "Play":
+		return nil, s.playLocked()
+	case "Pause":
+		if st := s.backend.Status(); st.Casting && st.State == types.Playing {
+			s.backend.Pause()
+		}
+		s.transport.playing = false
+	case "Stop":
+		if st := s.backend.Status(); st.Casting && st.State != types.Stopped {
+			s.backend.Stop()
+		}
+		s.transport.playing = false
+	case "Seek":
+		return nil, s.seekLocked(args["Unit"], args["Target"])
+	case "Next":
+		if s.transport.nextURI == "" {
+			return nil, errTransitionNotAvailable
+		}
+		s.advanceLocked()
+		return nil, s.startLocked()
+	case "Previous":
+		return nil, errTransitionNotAvailable
+	case "GetTransportInfo":
+		return []arg{
+			{"CurrentTransportState", s.transportStateLocked(s.backend.Status())},
+			{"CurrentTransportStatus", "OK"},
+			{"CurrentSpeed", "1"},
+		}, nil
+	case "GetPositionInfo":
+		st := s.backend.Status()
+		track, position := "0", time.Duration(0)
+		if s.transport.uri != "" {
+			track = "1"
+		}
+		if st.Casting && s.transport.loaded != "" {
+			position = st.Position
+		}
+		return []arg{
+			{"Track", track},
+			{"TrackDuration", formatClock(s.durationLocked(st))},
+			{"TrackMetaData", s.transport.metadata},
+			{"TrackURI", s.transport.uri},
+			{"RelTime", formatClock(position)},
+			{"AbsTime", formatClock(position)},
+			{"RelCount", maxCounter},
+			{"AbsCount", maxCounter},
+		}, nil
+	case "GetMediaInfo":
+		st := s.backend.Status()
+		tracks, medium := "0", "NONE"
+		if s.transport.uri != "" {
+			tracks, medium = "1", "NETWORK"
+		}
+		return []arg{
+			{"NrTracks", tracks},
+			{"MediaDuration", formatClock(s.durationLocked(st))},
+			{"CurrentURI", s.transport.uri},
+			{"CurrentURIMetaData", s.transport.metadata},
+			{"NextURI", s.transport.nextURI},
+			{"NextURIMetaData", s.transport.nextMetadata},
+			{"PlayMedium", medium},
+			{"RecordMedium", "NOT_IMPLEMENTED"},
+			{"WriteStatus", "NOT_IMPLEMENTED"},
+		}, nil
+	case "GetDeviceCapabilities":
+		return []arg{
+			{"PlayMedia", "NETWORK"},
+			{"RecMedia", "NOT_IMPLEMENTED"},
+			{"RecQualityModes", "NOT_IMPLEMENTED"},
+		}, nil
+	case "GetTransportSettings":
+		return []arg{{"PlayMode", "NORMAL"}, {"RecQualityMode", "NOT_IMPLEMENTED"}}, nil
+	case "GetCurrentTransportActions":
+		return []arg{{"Actions", transportActions}}, nil
+	default:
+		return nil, errInvalidAction
+	}
+	return nil, nil
+}
+
+// playLocked 继续播放暂停的投送内容，或开始播放当前地址
+func (s *Server) playLocked() error {
+	if s.transport.uri == "" {
+		return errTransitionNotAvailable
+	}
+	st := s.backend.Status()
+	if st.Casting && s.transport.loaded == s.transport.uri {
+		switch st.State {
+		case types.Paused:
+			s.backend.Resume()
+			s.transport.playing = true
+			return nil
+		case types.Playing:
+			s.transport.playing = true
+			return nil
+		}
+	}
+	return s.startLocked()
+}
+
+// startLocked 把当前地址交给播放器
+func (s *Server) startLocked() error {
+	if err := s.backend.PlayURI(s.transport.uri, s.transport.meta); err != nil {
+		s.transport.loaded = ""
+		s.transport.playing = false
+		return errResourceNotFound
+	}
+	s.transport.loaded = s.transport.uri
+	s.transport.playing = true
+	s.transport.startedAt = time.Now()
+	return nil
+}
+
+// advanceLocked 把 SetNextAVTransportURI 设置的地址切换为当前地址
+func (s *Server) advanceLocked() {
+	s.transport.uri, s.transport.metadata, s.transport.meta = s.transport.nextURI, s.transport.nextMetadata, s.transport.nextMeta
+	s.transport.nextURI, s.transport.nextMetadata, s.transport.nextMeta = "", "", Metadata{}
+}
+
+func (s *Server) seekLocked(unit, target string) error {
+	switch unit {
+	case "REL_TIME", "ABS_TIME":
+	default:
+		return errSeekModeNotSupported
+	}
+	position, err := parseClock(target)
+	if err != nil {
+		return errIllegalSeekTarget
+	}
+	st := s.backend.Status()
+	if !st.Casting || s.transport.loaded == "" {
+		return errTransitionNotAvailable
+	}
+	if d := s.durationLocked(st); d > 0 && position > d {
+		return errIllegalSeekTarget
+	}
+	s.backend.Seek(position)
+	return nil
+}
+
+// transportStateLocked 根据播放器状态得到 TransportState
+func (s *Server) transportStateLocked(st Status) string {
+	switch {
+	case s.transport.uri == "":
+		return "NO_MEDIA_PRESENT"
+	case !st.Casting || s.transport.loaded == "":
+		return "STOPPED"
+	}
+	switch st.State {
+	case types.Playing:
+		return "PLAYING"
+	case types.Paused:
+		return "PAUSED_PLAYBACK"
+	}
+	if s.transport.playing {
+		return "TRANSITIONING"
+	}
+	return "STOPPED"
+}
+
+// durationLocked 优先使用元数据中的时长，播放器通常无法提前得知网络流的长度
+func (s *Server) durationLocked(st Status) time.Duration {
+	if s.transport.meta.Duration > 0 {
+		return s.transport.meta.Duration
+	}
+	if st.Casting && st.Duration < 24*time.Hour {
+		return st.Duration
+	}
+	return 0
+}
+
+// checkTrackEndLocked 投送的歌曲播放结束后继续播放 SetNextAVTransportURI 设置的地址
+func (s *Server) checkTrackEndLocked(st Status) {
+	if !s.transport.playing {
+		return
+	}
+	if !st.Casting {
+		// 用户在本机播放了其他歌曲
+		s.transport.playing = false
+		s.transport.loaded = ""
+		return
+	}
+	if st.State != types.Stopped || time.Since(s.transport.startedAt) < trackEndGrace {
+		return
+	}
+	if s.transport.nextURI == "" {
+		s.transport.playing = false
+		return
+	}
+	s.advanceLocked()
+	_ = s.startLocked()
+}
+
+func (s *Server) renderingControlAction(action string, args map[string]string) ([]arg, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch action {
+	case "ListPresets":
+		return []arg{{"CurrentPresetNameList", "FactoryDefaults"}}, nil
+	case "SelectPreset":
+		if args["PresetName"] != "FactoryDefaults" {
+			return nil, errInvalidArgs
+		}
+	case "GetVolume":
+		return []arg{{"CurrentVolume", strconv.Itoa(s.volumeLocked(s.backend.Status()))}}, nil
+	case "SetVolume":
+		volume, err := strconv.Atoi(strings.TrimSpace(args["DesiredVolume"]))
+		if err != nil || volume < 0 || volume > 100 {
+			return nil, errInvalidArgs
+		}
+		s.muted = false
+		s.backend.SetVolume(volume)
+	case "GetMute":
+		return []arg{{"CurrentMute", boolValue(s.muted)}}, nil
+	case "SetMute":
+		mute, err := parseBool(args["DesiredMute"])
+		if err != nil {
+			return nil, errInvalidArgs
+		}
+		switch {
+		case mute && !s.muted:
+			s.unmuteVolume = s.backend.Status().Volume
+			s.muted = true
+			s.backend.SetVolume(0)
+		case !mute && s.muted:
+			s.muted = false
+			s.backend.SetVolume(s.unmuteVolume)
+		}
+	default:
+		return nil, errInvalidAction
+	}
+	return nil, nil
+}
+
+// volumeLocked 静音时返回静音前的音量，用户在本机调节音量后取消静音状态
+func (s *Server) volumeLocked(st Status) int {
+	if s.muted {
+		if st.Volume == 0 {
+			return s.unmuteVolume
+		}
+		s.muted = false
+	}
+	return st.Volume
+}
+
+func connectionManagerAction(action string, args map[string]string) ([]arg, error) {
+	switch action {
+	case "GetProtocolInfo":
+		return []arg{{"Source", ""}, {"Sink", sinkProtocolInfo}}, nil
+	case "GetCurrentConnectionIDs":
+		return []arg{{"ConnectionIDs", "0"}}, nil
+	case "GetCurrentConnectionInfo":
+		if args["ConnectionID"] != "0" {
+			return nil, &soapError{706, "Invalid connection reference"}
+		}
+		return []arg{
+			{"RcsID", "0"},
+			{"AVTransportID", "0"},
+			{"ProtocolInfo", ""},
+			{"PeerConnectionManager", ""},
+			{"PeerConnectionID", "-1"},
+			{"Direction", "Input"},
+			{"Status", "OK"},
+		}, nil
+	default:
+		return nil, errInvalidAction
+	}
+}
+
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "true", "yes":
+		return true, nil
+	case "0", "false", "no":
+		return false, nil
+	}
+	return false, errInvalidArgs
+}
+
+func boolValue(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// checkURI 只接受 http/https 地址，空地址表示清除；
+// 局域网内任意设备都能调用，不能让控制点借此打开本机文件（file://）或其他协议
+func checkURI(uri string) error {
+	if uri == "" {
+		return nil
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return errResourceNotFound
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return nil
+	default:
+		return errResourceNotFound
+	}
+}
@@ -0,0 +1,160 @@
+package mediarenderer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// didlLite 只解析音频播放需要的字段，元素按本地名称匹配，不区分 dc/upnp 命名空间
+type didlLite struct {
+	Items []struct {
+		Title       string `xml:"title"`
+		Creator     string `xml:"creator"`
+		Artist      string `xml:"artist"`
+		Album       string `xml:"album"`
+		AlbumArtURI string `xml:"albumArtURI"`
+		Res         []struct {
+			Duration     string `xml:"duration,attr"`
+			ProtocolInfo string `xml:"protocolInfo,attr"`
+			URI          string `xml:",chardata"`
+		} `xml:"res"`
+	} `xml:"item"`
+}
+
+// mimeFormats MIME 类型对应的播放格式
+var mimeFormats = map[string]string{
+	"audio/mpeg":      "mp3",
+	"audio/mp3":       "mp3",
+	"audio/x-mpeg":    "mp3",
+	"audio/flac":      "flac",
+	"audio/x-flac":    "flac",
+	"audio/wav":       "wav",
+	"audio/wave":      "wav",
+	"audio/x-wav":     "wav",
+	"audio/ogg":       "ogg",
+	"audio/vorbis":    "ogg",
+	"application/ogg": "ogg",
+	"audio/mp4":       "m4a",
+	"audio/x-m4a":     "m4a",
+	"audio/aac":       "aac",
+	"audio/opus":      "opus",
+}
+
+// sinkProtocolInfo ConnectionManager 公布的可接收格式
+var sinkProtocolInfo = strings.Join([]string{
+	"http-get:*:audio/mpeg:*",
+	"http-get:*:audio/mp3:*",
+	"http-get:*:audio/flac:*",
+	"http-get:*:audio/x-flac:*",
+	"http-get:*:audio/wav:*",
+	"http-get:*:audio/x-wav:*",
+	"http-get:*:audio/ogg:*",
+	"http-get:*:application/ogg:*",
+	"http-get:*:audio/mp4:*",
+	"http-get:*:audio/x-m4a:*",
+	"http-get:*:audio/aac:*",
+	"http-get:*:audio/opus:*",
+}, ",")
+
+// parseMetadata 解析 DIDL-Lite 元数据，元数据缺失或无法解析时从地址推断格式
+func parseMetadata(didl, uri string) Metadata {
+	var meta Metadata
+	var doc didlLite
+	if didl != "" && xml.Unmarshal([]byte(didl), &doc) == nil && len(doc.Items) > 0 {
+		item := doc.Items[0]
+		meta.Title = strings.TrimSpace(item.Title)
+		meta.Artist = strings.TrimSpace(item.Artist)
+		if meta.Artist == "" {
+			meta.Artist = strings.TrimSpace(item.Creator)
+		}
+		meta.Album = strings.TrimSpace(item.Album)
+		meta.AlbumArtURI = strings.TrimSpace(item.AlbumArtURI)
+		// 同一项目可能提供多个资源，优先使用与投送地址一致的那个
+		if len(item.Res) > 0 {
+			res := item.Res[0]
+			for _, r := range item.Res {
+				if strings.TrimSpace(r.URI) == uri {
+					res = r
+					break
+				}
+			}
+			if d, err := parseClock(res.Duration); err == nil {
+				meta.Duration = d
+			}
+			if fields := strings.Split(res.ProtocolInfo, ":"); len(fields) >= 3 {
+				mime, _, _ := strings.Cut(fields[2], ";")
+				meta.Format = mimeFormats[strings.ToLower(mime)]
+			}
+		}
+	}
+	if meta.Format == "" {
+		meta.Format = formatFromURI(uri)
+	}
+	if meta.Title == "" {
+		meta.Title = titleFromURI(uri)
+	}
+	return meta
+}
+
+func formatFromURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	switch ext := strings.ToLower(strings.TrimPrefix(path.Ext(u.Path), ".")); ext {
+	case "mp3", "flac", "wav", "ogg", "m4a", "aac", "opus":
+		return ext
+	case "oga":
+		return "ogg"
+	}
+	return ""
+}
+
+func titleFromURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return u.Host
+	}
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// parseClock 解析 H+:MM:SS[.F+] 格式的时间
+func parseClock(s string) (time.Duration, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil || hours < 0 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	// 秒可能带有小数（.F+）或分数（.F0/F1），分数形式只取整秒
+	sec := parts[2]
+	if whole, frac, ok := strings.Cut(sec, "."); ok && strings.Contains(frac, "/") {
+		sec = whole
+	}
+	seconds, err := strconv.ParseFloat(sec, 64)
+	if err != nil || seconds < 0 || seconds >= 60 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second)), nil
+}
+
+// formatClock 格式化为 H:MM:SS
+func formatClock(d time.Duration) string {
+	d = max(d, 0).Truncate(time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
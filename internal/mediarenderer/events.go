@@ -0,0 +1,372 @@
+package mediarenderer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UPnP GENA 事件：控制点订阅后，状态变化通过 NOTIFY 推送 LastChange
+
+const (
+	defaultSubscriptionTimeout = 30 * time.Minute
+	maxCallbacks               = 4
+	eventQueueSize             = 16
+)
+
+type subscriber struct {
+	sid       string
+	service   string
+	callbacks []string
+	expires   time.Time
+	// queue 待发送的事件，由每个订阅者独立的 goroutine 按顺序发送
+	queue chan []byte
+	seq   uint32
+}
+
+func (s *Server) handleEvent(w http.ResponseWriter, r *http.Request) {
+	svc, ok := lookupService(r.PathValue("service"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case "SUBSCRIBE":
+		if sid := r.Header.Get("SID"); sid != "" {
+			s.renew(w, r, svc, sid)
+			return
+		}
+		s.subscribe(w, r, svc)
+	case "UNSUBSCRIBE":
+		s.subMu.Lock()
+		sub, ok := s.subs[r.Header.Get("SID")]
+		if ok && sub.service == svc.ID {
+			s.removeSubscriberLocked(sub)
+		}
+		s.subMu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) subscribe(w http.ResponseWriter, r *http.Request, svc serviceSpec) {
+	callbacks := parseCallbacks(r.Header.Get("CALLBACK"))
+	if r.Header.Get("NT") != "upnp:event" || len(callbacks) == 0 {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	sid, err := newSID()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	timeout := parseTimeout(r.Header.Get("TIMEOUT"))
+	sub := &subscriber{
+		sid:       sid,
+		service:   svc.ID,
+		callbacks: callbacks,
+		expires:   time.Now().Add(timeout),
+		queue:     make(chan []byte, eventQueueSize),
+	}
+	// 初始事件必须是订阅者收到的第一个事件（SEQ 0），与轮询推送互斥
+	s.mu.Lock()
+	sub.queue <- s.initialEventLocked(svc.ID)
+	s.subMu.Lock()
+	s.subs[sid] = sub
+	s.subMu.Unlock()
+	s.mu.Unlock()
+
+	writeSubscribed(w, sid, timeout)
+	go s.deliver(sub)
+}
+
+func (s *Server) renew(w http.ResponseWriter, r *http.Request, svc serviceSpec, sid string) {
+	if r.Header.Get("CALLBACK") != "" || r.Header.Get("NT") != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	timeout := parseTimeout(r.Header.Get("TIMEOUT"))
+	s.subMu.Lock()
+	sub, ok := s.subs[sid]
+	if ok && sub.service == svc.ID && time.Now().Before(sub.expires) {
+		sub.expires = time.Now().Add(timeout)
+	} else {
+		ok = false
+	}
+	s.subMu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	writeSubscribed(w, sid, timeout)
+}
+
+func writeSubscribed(w http.ResponseWriter, sid string, timeout time.Duration) {
+	w.Header().Set("SID", sid)
+	w.Header().Set("TIMEOUT", fmt.Sprintf("Second-%d", int(timeout.Seconds())))
+	w.Header().Set("Content-Length", "0")
+	w.WriteHeader(http.StatusOK)
+}
+
+// parseCallbacks 解析 CALLBACK 头中尖括号包裹的回调地址，只接受 http 地址
+func parseCallbacks(header string) []string {
+	var callbacks []string
+	for _, part := range strings.Split(header, "<")[1:] {
+		raw, _, ok := strings.Cut(part, ">")
+		if !ok {
+			continue
+		}
+		if u, err := url.Parse(raw); err == nil && u.Scheme == "http" && u.Host != "" {
+			callbacks = append(callbacks, raw)
+		}
+		if len(callbacks) == maxCallbacks {
+			break
+		}
+	}
+	return callbacks
+}
+
+func parseTimeout(header string) time.Duration {
+	if seconds, ok := strings.CutPrefix(header, "Second-"); ok {
+		if n, err := strconv.Atoi(seconds); err == nil && n > 0 {
+			return min(time.Duration(n)*time.Second, defaultSubscriptionTimeout)
+		}
+	}
+	return defaultSubscriptionTimeout
+}
+
+func newSID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	h := hex.EncodeToString(b[:])
+	return fmt.Sprintf("uuid:%s-%s-%s-%s-%s", h[0:8], h[8:12], h[12:16], h[16:20], h[20:32]), nil
+}
+
+// watch 定期检查播放器状态，推送变化并处理投送歌曲的自然结束
+func (s *Server) watch() {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-ticker.C:
+			s.poll()
+			s.expireSubscriptions()
+		}
+	}
+}
+
+func (s *Server) poll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkTrackEndLocked(s.backend.Status())
+	st := s.backend.Status()
+	for _, event := range []struct {
+		service string
+		vars    []arg
+	}{
+		{avTransportID, s.avTransportVarsLocked(st)},
+		{renderingControlID, s.renderingControlVarsLocked(st)},
+	} {
+		diff := diffVars(s.lastVars[event.service], event.vars)
+		if len(diff) == 0 {
+			continue
+		}
+		s.lastVars[event.service] = mergeVars(s.lastVars[event.service], diff)
+		s.publish(event.service, lastChangeEvent(event.service, diff))
+	}
+}
+
+func (s *Server) avTransportVarsLocked(st Status) []arg {
+	tracks := "0"
+	if s.transport.uri != "" {
+		tracks = "1"
+	}
+	duration := formatClock(s.durationLocked(st))
+	return []arg{
+		{"TransportState", s.transportStateLocked(st)},
+		{"TransportStatus", "OK"},
+		{"TransportPlaySpeed", "1"},
+		{"CurrentPlayMode", "NORMAL"},
+		{"NumberOfTracks", tracks},
+		{"CurrentTrack", tracks},
+		{"AVTransportURI", s.transport.uri},
+		{"AVTransportURIMetaData", s.transport.metadata},
+		{"CurrentTrackURI", s.transport.uri},
+		{"CurrentTrackMetaData", s.transport.metadata},
+		{"NextAVTransportURI", s.transport.nextURI},
+		{"NextAVTransportURIMetaData", s.transport.nextMetadata},
+		{"CurrentTrackDuration", duration},
+		{"CurrentMediaDuration", duration},
+		{"CurrentTransportActions", transportActions},
+	}
+}
+
+func (s *Server) renderingControlVarsLocked(st Status) []arg {
+	return []arg{
+		{"Volume", strconv.Itoa(s.volumeLocked(st))},
+		{"Mute", boolValue(s.muted)},
+	}
+}
+
+// diffVars 返回 next 中与 prev 不同的变量
+func diffVars(prev, next []arg) []arg {
+	var diff []arg
+	for _, v := range next {
+		if i := slices.IndexFunc(prev, func(p arg) bool { return p.name == v.name }); i < 0 || prev[i].value != v.value {
+			diff = append(diff, v)
+		}
+	}
+	return diff
+}
+
+func mergeVars(prev, diff []arg) []arg {
+	merged := slices.Clone(prev)
+	for _, v := range diff {
+		if i := slices.IndexFunc(merged, func(p arg) bool { return p.name == v.name }); i >= 0 {
+			merged[i] = v
+		} else {
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
+
+// initialEventLocked 订阅后立即发送的全部事件变量
+func (s *Server) initialEventLocked(service string) []byte {
+	st := s.backend.Status()
+	switch service {
+	case avTransportID:
+		return lastChangeEvent(service, s.avTransportVarsLocked(st))
+	case renderingControlID:
+		return lastChangeEvent(service, s.renderingControlVarsLocked(st))
+	}
+	return propertySet([]arg{
+		{"SourceProtocolInfo", ""},
+		{"SinkProtocolInfo", sinkProtocolInfo},
+		{"CurrentConnectionIDs", "0"},
+	})
+}
+
+// lastChangeEvent AVTransport 与 RenderingControl 的变量合并在 LastChange 中推送
+func lastChangeEvent(service string, vars []arg) []byte {
+	namespace := "urn:schemas-upnp-org:metadata-1-0/AVT/"
+	if service == renderingControlID {
+		namespace = "urn:schemas-upnp-org:metadata-1-0/RCS/"
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<Event xmlns="%s"><InstanceID val="0">`, namespace)
+	for _, v := range vars {
+		fmt.Fprintf(&buf, "<%s", v.name)
+		if service == renderingControlID {
+			buf.WriteString(` channel="Master"`)
+		}
+		buf.WriteString(` val="`)
+		_ = xml.EscapeText(&buf, []byte(v.value))
+		buf.WriteString(`"/>`)
+	}
+	buf.WriteString(`</InstanceID></Event>`)
+	return propertySet([]arg{{"LastChange", buf.String()}})
+}
+
+func propertySet(vars []arg) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="utf-8"?><e:propertyset xmlns:e="urn:schemas-upnp-org:event-1-0">`)
+	for _, v := range vars {
+		fmt.Fprintf(&buf, "<e:property><%s>", v.name)
+		_ = xml.EscapeText(&buf, []byte(v.value))
+		fmt.Fprintf(&buf, "</%s></e:property>", v.name)
+	}
+	buf.WriteString(`</e:propertyset>`)
+	return buf.Bytes()
+}
+
+// publish 把事件加入服务所有订阅者的发送队列，队列已满时丢弃
+func (s *Server) publish(service string, body []byte) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for _, sub := range s.subs {
+		if sub.service != service {
+			continue
+		}
+		select {
+		case sub.queue <- body:
+		default:
+			slog.Warn("DLNA renderer: event queue full, drop event", "sid", sub.sid)
+		}
+	}
+}
+
+// deliver 依次发送订阅者队列中的事件，直到订阅被取消
+func (s *Server) deliver(sub *subscriber) {
+	for body := range sub.queue {
+		s.notify(sub, body)
+		// SEQ 溢出后从 1 开始，0 只用于初始事件
+		if sub.seq++; sub.seq == 0 {
+			sub.seq = 1
+		}
+	}
+}
+
+// notify 依次尝试订阅者的回调地址，直到有一个成功
+func (s *Server) notify(sub *subscriber, body []byte) {
+	for _, callback := range sub.callbacks {
+		req, err := http.NewRequest("NOTIFY", callback, bytes.NewReader(body))
+		if err != nil {
+			continue
+		}
+		req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+		req.Header.Set("NT", "upnp:event")
+		req.Header.Set("NTS", "upnp:propchange")
+		req.Header.Set("SID", sub.sid)
+		req.Header.Set("SEQ", strconv.FormatUint(uint64(sub.seq), 10))
+		resp, err := s.client.Do(req)
+		if err != nil {
+			slog.Debug("DLNA renderer: event delivery failed", "callback", callback, "error", err)
+			continue
+		}
+		_ = resp.Body.Close()
+		return
+	}
+}
+
+func (s *Server) removeSubscriberLocked(sub *subscriber) {
+	delete(s.subs, sub.sid)
+	close(sub.queue)
+}
+
+func (s *Server) expireSubscriptions() {
+	now := time.Now()
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for _, sub := range s.subs {
+		if now.After(sub.expires) {
+			s.removeSubscriberLocked(sub)
+		}
+	}
+}
+
+func (s *Server) closeSubscriptions() {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for _, sub := range s.subs {
+		s.removeSubscriberLocked(sub)
+	}
+}
@@ -0,0 +1,220 @@
+package mediarenderer
+
+import "encoding/xml"
+
+// 三个服务的 SCPD（服务描述），部分控制点会据此判断设备支持的操作
+
+const (
+	avTransportID       = "AVTransport"
+	renderingControlID  = "RenderingControl"
+	connectionManagerID = "ConnectionManager"
+)
+
+type argSpec struct {
+	name     string
+	out      bool
+	variable string
+}
+
+type actionSpec struct {
+	name string
+	args []argSpec
+}
+
+type variableSpec struct {
+	name     string
+	dataType string
+	events   bool
+	allowed  []string
+}
+
+type serviceSpec struct {
+	ID        string
+	Type      string
+	actions   []actionSpec
+	variables []variableSpec
+}
+
+func in(name, variable string) argSpec  { return argSpec{name: name, variable: variable} }
+func out(name, variable string) argSpec { return argSpec{name: name, out: true, variable: variable} }
+
+func str(name string, allowed ...string) variableSpec {
+	return variableSpec{name: name, dataType: "string", allowed: allowed}
+}
+
+var instanceID = in("InstanceID", "A_ARG_TYPE_InstanceID")
+
+var services = []serviceSpec{
+	{
+		ID:   avTransportID,
+		Type: "urn:schemas-upnp-org:service:AVTransport:1",
+		actions: []actionSpec{
+			{"SetAVTransportURI", []argSpec{instanceID, in("CurrentURI", "AVTransportURI"), in("CurrentURIMetaData", "AVTransportURIMetaData")}},
+			{"SetNextAVTransportURI", []argSpec{instanceID, in("NextURI", "NextAVTransportURI"), in("NextURIMetaData", "NextAVTransportURIMetaData")}},
+			{"GetMediaInfo", []argSpec{instanceID,
+				out("NrTracks", "NumberOfTracks"), out("MediaDuration", "CurrentMediaDuration"),
+				out("CurrentURI", "AVTransportURI"), out("CurrentURIMetaData", "AVTransportURIMetaData"),
+				out("NextURI", "NextAVTransportURI"), out("NextURIMetaData", "NextAVTransportURIMetaData"),
+				out("PlayMedium", "PlaybackStorageMedium"), out("RecordMedium", "RecordStorageMedium"),
+				out("WriteStatus", "RecordMediumWriteStatus")}},
+			{"GetTransportInfo", []argSpec{instanceID,
+				out("CurrentTransportState", "TransportState"), out("CurrentTransportStatus", "TransportStatus"),
+				out("CurrentSpeed", "TransportPlaySpeed")}},
+			{"GetPositionInfo", []argSpec{instanceID,
+				out("Track", "CurrentTrack"), out("TrackDuration", "CurrentTrackDuration"),
+				out("TrackMetaData", "CurrentTrackMetaData"), out("TrackURI", "CurrentTrackURI"),
+				out("RelTime", "RelativeTimePosition"), out("AbsTime", "AbsoluteTimePosition"),
+				out("RelCount", "RelativeCounterPosition"), out("AbsCount", "AbsoluteCounterPosition")}},
+			{"GetDeviceCapabilities", []argSpec{instanceID,
+				out("PlayMedia", "PossiblePlaybackStorageMedia"), out("RecMedia", "PossibleRecordStorageMedia"),
+				out("RecQualityModes", "PossibleRecordQualityModes")}},
+			{"GetTransportSettings", []argSpec{instanceID, out("PlayMode", "CurrentPlayMode"), out("RecQualityMode", "CurrentRecordQualityMode")}},
+			{"GetCurrentTransportActions", []argSpec{instanceID, out("Actions", "CurrentTransportActions")}},
+			{"Play", []argSpec{instanceID, in("Speed", "TransportPlaySpeed")}},
+			{"Pause", []argSpec{instanceID}},
+			{"Stop", []argSpec{instanceID}},
+			{"Seek", []argSpec{instanceID, in("Unit", "A_ARG_TYPE_SeekMode"), in("Target", "A_ARG_TYPE_SeekTarget")}},
+			{"Next", []argSpec{instanceID}},
+			{"Previous", []argSpec{instanceID}},
+		},
+		variables: []variableSpec{
+			str("TransportState", "STOPPED", "PLAYING", "PAUSED_PLAYBACK", "TRANSITIONING", "NO_MEDIA_PRESENT"),
+			str("TransportStatus", "OK", "ERROR_OCCURRED"),
+			str("PlaybackStorageMedium", "NONE", "NETWORK"),
+			str("RecordStorageMedium", "NOT_IMPLEMENTED"),
+			str("PossiblePlaybackStorageMedia"),
+			str("PossibleRecordStorageMedia"),
+			str("CurrentPlayMode", "NORMAL"),
+			str("TransportPlaySpeed", "1"),
+			str("RecordMediumWriteStatus", "NOT_IMPLEMENTED"),
+			str("CurrentRecordQualityMode", "NOT_IMPLEMENTED"),
+			str("PossibleRecordQualityModes"),
+			{name: "NumberOfTracks", dataType: "ui4"},
+			{name: "CurrentTrack", dataType: "ui4"},
+			str("CurrentTrackDuration"),
+			str("CurrentMediaDuration"),
+			str("CurrentTrackMetaData"),
+			str("CurrentTrackURI"),
+			str("AVTransportURI"),
+			str("AVTransportURIMetaData"),
+			str("NextAVTransportURI"),
+			str("NextAVTransportURIMetaData"),
+			str("RelativeTimePosition"),
+			str("AbsoluteTimePosition"),
+			{name: "RelativeCounterPosition", dataType: "i4"},
+			{name: "AbsoluteCounterPosition", dataType: "i4"},
+			str("CurrentTransportActions"),
+			{name: "LastChange", dataType: "string", events: true},
+			str("A_ARG_TYPE_SeekMode", "REL_TIME", "ABS_TIME", "TRACK_NR"),
+			str("A_ARG_TYPE_SeekTarget"),
+			{name: "A_ARG_TYPE_InstanceID", dataType: "ui4"},
+		},
+	},
+	{
+		ID:   renderingControlID,
+		Type: "urn:schemas-upnp-org:service:RenderingControl:1",
+		actions: []actionSpec{
+			{"ListPresets", []argSpec{instanceID, out("CurrentPresetNameList", "PresetNameList")}},
+			{"SelectPreset", []argSpec{instanceID, in("PresetName", "A_ARG_TYPE_PresetName")}},
+			{"GetMute", []argSpec{instanceID, in("Channel", "A_ARG_TYPE_Channel"), out("CurrentMute", "Mute")}},
+			{"SetMute", []argSpec{instanceID, in("Channel", "A_ARG_TYPE_Channel"), in("DesiredMute", "Mute")}},
+			{"GetVolume", []argSpec{instanceID, in("Channel", "A_ARG_TYPE_Channel"), out("CurrentVolume", "Volume")}},
+			{"SetVolume", []argSpec{instanceID, in("Channel", "A_ARG_TYPE_Channel"), in("DesiredVolume", "Volume")}},
+		},
+		variables: []variableSpec{
+			str("PresetNameList"),
+			{name: "LastChange", dataType: "string", events: true},
+			{name: "Mute", dataType: "boolean"},
+			{name: "Volume", dataType: "ui2"},
+			str("A_ARG_TYPE_Channel", "Master"),
+			{name: "A_ARG_TYPE_InstanceID", dataType: "ui4"},
+			str("A_ARG_TYPE_PresetName", "FactoryDefaults"),
+		},
+	},
+	{
+		ID:   connectionManagerID,
+		Type: "urn:schemas-upnp-org:service:ConnectionManager:1",
+		actions: []actionSpec{
+			{"GetProtocolInfo", []argSpec{out("Source", "SourceProtocolInfo"), out("Sink", "SinkProtocolInfo")}},
+			{"GetCurrentConnectionIDs", []argSpec{out("ConnectionIDs", "CurrentConnectionIDs")}},
+			{"GetCurrentConnectionInfo", []argSpec{in("ConnectionID", "A_ARG_TYPE_ConnectionID"),
+				out("RcsID", "A_ARG_TYPE_RcsID"), out("AVTransportID", "A_ARG_TYPE_AVTransportID"),
+				out("ProtocolInfo", "A_ARG_TYPE_ProtocolInfo"), out("PeerConnectionManager", "A_ARG_TYPE_ConnectionManager"),
+				out("PeerConnectionID", "A_ARG_TYPE_ConnectionID"), out("Direction", "A_ARG_TYPE_Direction"),
+				out("Status", "A_ARG_TYPE_ConnectionStatus")}},
+		},
+		variables: []variableSpec{
+			{name: "SourceProtocolInfo", dataType: "string", events: true},
+			{name: "SinkProtocolInfo", dataType: "string", events: true},
+			{name: "CurrentConnectionIDs", dataType: "string", events: true},
+			str("A_ARG_TYPE_ConnectionStatus", "OK", "ContentFormatMismatch", "InsufficientBandwidth", "UnreliableChannel", "Unknown"),
+			str("A_ARG_TYPE_ConnectionManager"),
+			str("A_ARG_TYPE_Direction", "Input", "Output"),
+			str("A_ARG_TYPE_ProtocolInfo"),
+			{name: "A_ARG_TYPE_ConnectionID", dataType: "i4"},
+			{name: "A_ARG_TYPE_AVTransportID", dataType: "i4"},
+			{name: "A_ARG_TYPE_RcsID", dataType: "i4"},
+		},
+	},
+}
+
+func lookupService(id string) (serviceSpec, bool) {
+	for _, svc := range services {
+		if svc.ID == id {
+			return svc, true
+		}
+	}
+	return serviceSpec{}, false
+}
+
+type scpdDocument struct {
+	XMLName     xml.Name `xml:"urn:schemas-upnp-org:service-1-0 scpd"`
+	SpecVersion struct {
+		Major int `xml:"major"`
+		Minor int `xml:"minor"`
+	} `xml:"specVersion"`
+	Actions   []scpdAction   `xml:"actionList>action"`
+	Variables []scpdVariable `xml:"serviceStateTable>stateVariable"`
+}
+
+type scpdAction struct {
+	Name string         `xml:"name"`
+	Args []scpdArgument `xml:"argumentList>argument"`
+}
+
+type scpdArgument struct {
+	Name      string `xml:"name"`
+	Direction string `xml:"direction"`
+	Variable  string `xml:"relatedStateVariable"`
+}
+
+type scpdVariable struct {
+	SendEvents string   `xml:"sendEvents,attr"`
+	Name       string   `xml:"name"`
+	DataType   string   `xml:"dataType"`
+	Allowed    []string `xml:"allowedValueList>allowedValue,omitempty"`
+}
+
+func (svc serviceSpec) scpd() scpdDocument {
+	var doc scpdDocument
+	doc.SpecVersion.Major = 1
+	for _, action := range svc.actions {
+		a := scpdAction{Name: action.name}
+		for _, arg := range action.args {
+			direction := "in"
+			if arg.out {
+				direction = "out"
+			}
+			a.Args = append(a.Args, scpdArgument{Name: arg.name, Direction: direction, Variable: arg.variable})
+		}
+		doc.Actions = append(doc.Actions, a)
+	}
+	for _, v := range svc.variables {
+		sendEvents := "no"
+		if v.events {
+			sendEvents = "yes"
+		}
+		doc.Variables = append(doc.Variables, scpdVariable{SendEvents: sendEvents, Name: v.name, DataType: v.dataType, Allowed: v.allowed})
+	}
+	return doc
+}
@@ -0,0 +1,303 @@
+// Package mediarenderer 将 musicfox 作为 UPnP/DLNA MediaRenderer 发布到局域网，
+// 手机等控制点可以把音频投送过来，由当前配置的播放引擎播放。
+package mediarenderer
+
+import (
+	"crypto/sha1"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+const (
+	deviceType   = "urn:schemas-upnp-org:device:MediaRenderer:1"
+	manufacturer = "go-musicfox"
+)
+
+// Metadata 控制点随地址一起发送的 DIDL-Lite 元数据
+type Metadata struct {
+	Title       string
+	Artist      string
+	Album       string
+	AlbumArtURI string
+	Duration    time.Duration
+	// Format 根据 MIME 类型或扩展名推断的格式（mp3、flac、wav、ogg），无法判断时为空
+	Format string
+}
+
+// Status 播放器的当前状态
+type Status struct {
+	State    types.State
+	Position time.Duration
+	Duration time.Duration
+	Volume   int
+	// Casting 是否仍在播放投送的内容，用户在本机切换歌曲后为 false
+	Casting bool
+}
+
+// Backend 渲染器依赖的播放器能力，Pause、Resume、Stop、Seek 只会在 Casting 时调用
+type Backend interface {
+	// PlayURI 播放投送的地址
+	PlayURI(uri string, meta Metadata) error
+	Pause()
+	Resume()
+	Stop()
+	Seek(position time.Duration)
+	SetVolume(volume int)
+	Status() Status
+}
+
+// Server UPnP MediaRenderer 服务，提供设备描述、SOAP 控制与 GENA 事件
+type Server struct {
+	name     string
+	udn      string
+	backend  Backend
+	listener net.Listener
+	server   *http.Server
+	// localIP 通过 SSDP 公布的本机地址
+	localIP string
+	ssdp    *ssdpAdvertiser
+
+	mu        sync.Mutex
+	transport transport
+	muted     bool
+	// unmuteVolume 静音前的音量
+	unmuteVolume int
+	// lastVars 上次推送的事件变量，用于只推送变化的部分
+	lastVars map[string][]arg
+
+	subMu sync.Mutex
+	subs  map[string]*subscriber
+
+	client       *http.Client
+	pollInterval time.Duration
+	closed       chan struct{}
+	closeOnce    sync.Once
+}
+
+// transport AVTransport 服务的状态
+type transport struct {
+	uri          string
+	metadata     string
+	meta         Metadata
+	nextURI      string
+	nextMetadata string
+	nextMeta     Metadata
+	// loaded 已交给播放器的地址
+	loaded string
+	// playing 控制点要求播放（Play 之后、Pause/Stop 之前），用于区分播放结束与手动停止
+	playing   bool
+	startedAt time.Time
+}
+
+// Listen 在 bind 上监听 HTTP 服务，bind 为空或未指定 IP 时自动选择局域网地址和端口
+func Listen(bind, name string, backend Backend) (*Server, error) {
+	host, port := "", "0"
+	if bind != "" {
+		var err error
+		if host, port, err = net.SplitHostPort(bind); err != nil {
+			return nil, fmt.Errorf("invalid bind address %q: %w", bind, err)
+		}
+	}
+	localIP := host
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		var err error
+		if localIP, err = lanIP(); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("tcp4", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", bind, err)
+	}
+
+	if name == "" {
+		name = types.AppName
+	}
+	s := newServer(name, backend)
+	s.listener = listener
+	s.localIP = localIP
+	s.server = &http.Server{
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s, nil
+}
+
+func newServer(name string, backend Backend) *Server {
+	return &Server{
+		name:         name,
+		udn:          deviceUDN(name),
+		backend:      backend,
+		lastVars:     make(map[string][]arg),
+		subs:         make(map[string]*subscriber),
+		client:       &http.Client{Timeout: 3 * time.Second},
+		pollInterval: 500 * time.Millisecond,
+		closed:       make(chan struct{}),
+	}
+}
+
+// Addr 实际监听的地址
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Location 设备描述地址
+func (s *Server) Location() string {
+	port := s.listener.Addr().(*net.TCPAddr).Port
+	return fmt.Sprintf("http://%s/description.xml", net.JoinHostPort(s.localIP, fmt.Sprint(port)))
+}
+
+// Serve 在局域网中公布设备并处理请求，直到 Close 被调用
+func (s *Server) Serve() error {
+	ssdp, err := newSSDPAdvertiser(s.localIP, s.Location(), s.udn)
+	if err != nil {
+		// 没有 SSDP 时控制点无法自动发现，但仍可以手动添加设备描述地址
+		slog.Warn("DLNA renderer: SSDP unavailable", "error", err)
+	} else {
+		s.ssdp = ssdp
+		go ssdp.run()
+	}
+	go s.watch()
+
+	if err := s.server.Serve(s.listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Close 发送 ssdp:byebye 并停止服务
+func (s *Server) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		s.closeSubscriptions()
+	})
+	if s.ssdp != nil {
+		s.ssdp.close()
+	}
+	err := s.server.Close()
+	_ = s.listener.Close()
+	return err
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /description.xml", s.handleDescription)
+	mux.HandleFunc("GET /{service}/scpd.xml", s.handleSCPD)
+	mux.HandleFunc("POST /{service}/control", s.handleControl)
+	mux.HandleFunc("/{service}/event", s.handleEvent)
+	return mux
+}
+
+type deviceDescription struct {
+	XMLName     xml.Name `xml:"urn:schemas-upnp-org:device-1-0 root"`
+	DLNA        string   `xml:"xmlns:dlna,attr"`
+	SpecVersion struct {
+		Major int `xml:"major"`
+		Minor int `xml:"minor"`
+	} `xml:"specVersion"`
+	Device struct {
+		DeviceType       string               `xml:"deviceType"`
+		FriendlyName     string               `xml:"friendlyName"`
+		Manufacturer     string               `xml:"manufacturer"`
+		ManufacturerURL  string               `xml:"manufacturerURL"`
+		ModelDescription string               `xml:"modelDescription"`
+		ModelName        string               `xml:"modelName"`
+		ModelNumber      string               `xml:"modelNumber"`
+		UDN              string               `xml:"UDN"`
+		DLNADoc          string               `xml:"dlna:X_DLNADOC"`
+		Services         []descriptionService `xml:"serviceList>service"`
+	} `xml:"device"`
+}
+
+type descriptionService struct {
+	ServiceType string `xml:"serviceType"`
+	ServiceID   string `xml:"serviceId"`
+	SCPDURL     string `xml:"SCPDURL"`
+	ControlURL  string `xml:"controlURL"`
+	EventSubURL string `xml:"eventSubURL"`
+}
+
+func (s *Server) handleDescription(w http.ResponseWriter, _ *http.Request) {
+	var desc deviceDescription
+	desc.DLNA = "urn:schemas-dlna-org:device-1-0"
+	desc.SpecVersion.Major = 1
+	desc.Device.DeviceType = deviceType
+	desc.Device.FriendlyName = s.name
+	desc.Device.Manufacturer = manufacturer
+	desc.Device.ManufacturerURL = types.AppGithubUrl
+	desc.Device.ModelDescription = "Musicfox DLNA renderer"
+	desc.Device.ModelName = types.AppName
+	desc.Device.ModelNumber = types.AppVersion
+	desc.Device.UDN = s.udn
+	desc.Device.DLNADoc = "DMR-1.50"
+	for _, svc := range services {
+		desc.Device.Services = append(desc.Device.Services, descriptionService{
+			ServiceType: svc.Type,
+			ServiceID:   "urn:upnp-org:serviceId:" + svc.ID,
+			SCPDURL:     "/" + svc.ID + "/scpd.xml",
+			ControlURL:  "/" + svc.ID + "/control",
+			EventSubURL: "/" + svc.ID + "/event",
+		})
+	}
+	writeXML(w, desc)
+}
+
+func (s *Server) handleSCPD(w http.ResponseWriter, r *http.Request) {
+	svc, ok := lookupService(r.PathValue("service"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeXML(w, svc.scpd())
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(data)
+}
+
+// deviceUDN 由主机名和设备名生成固定的 UDN，重启后控制点仍能识别为同一设备
+func deviceUDN(name string) string {
+	host, _ := os.Hostname()
+	sum := sha1.Sum([]byte(types.AppName + "/renderer/" + host + "/" + name))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// lanIP 返回访问 SSDP 多播地址时使用的本机 IP
+func lanIP() (string, error) {
+	// UDP 的 Dial 不会发送数据，只用于查询路由
+	if conn, err := net.Dial("udp4", ssdpAddr); err == nil {
+		defer conn.Close()
+		if local, ok := conn.LocalAddr().(*net.UDPAddr); ok && !local.IP.IsUnspecified() {
+			return local.IP.String(), nil
+		}
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+	for _, a := range addrs {
+		if ipNet, ok := a.(*net.IPNet); ok && ipNet.IP.To4() != nil && ipNet.IP.IsPrivate() {
+			return ipNet.IP.String(), nil
+		}
+	}
+	return "", errors.New("DLNA renderer: no LAN address found, please set mediaRenderer.bind")
+}
@@ -0,0 +1,436 @@
+package mediarenderer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+type fakeBackend struct {
+	mu     sync.Mutex
+	status Status
+	played []string
+	metas  []Metadata
+}
+
+func (b *fakeBackend) PlayURI(uri string, meta Metadata) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.played = append(b.played, uri)
+	b.metas = append(b.metas, meta)
+	b.status = Status{State: types.Playing, Volume: b.status.Volume, Casting: true, Duration: meta.Duration}
+	return nil
+}
+
+func (b *fakeBackend) set(f func(st *Status)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	f(&b.status)
+}
+
+func (b *fakeBackend) Pause()                 { b.set(func(st *Status) { st.State = types.Paused }) }
+func (b *fakeBackend) Resume()                { b.set(func(st *Status) { st.State = types.Playing }) }
+func (b *fakeBackend) Stop()                  { b.set(func(st *Status) { st.State = types.Stopped }) }
+func (b *fakeBackend) Seek(pos time.Duration) { b.set(func(st *Status) { st.Position = pos }) }
+func (b *fakeBackend) SetVolume(volume int)   { b.set(func(st *Status) { st.Volume = volume }) }
+
+func (b *fakeBackend) playedURIs() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.played...)
+}
+
+func (b *fakeBackend) lastMeta() Metadata {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.metas[len(b.metas)-1]
+}
+
+func (b *fakeBackend) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.status
+}
+
+func newTestServer(t *testing.T, backend Backend) (*Server, *httptest.Server) {
+	t.Helper()
+	s := newServer("musicfox test", backend)
+	ts := httptest.NewServer(s.routes())
+	t.Cleanup(func() {
+		ts.Close()
+		s.closeSubscriptions()
+	})
+	return s, ts
+}
+
+// soapCall 发送 SOAP 请求，返回响应中的参数；UPnP 错误以 error 返回
+func soapCall(t *testing.T, ts *httptest.Server, service, action string, args ...string) (map[string]string, error) {
+	t.Helper()
+	var body strings.Builder
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&body, "<%s>%s</%s>", args[i], html.EscapeString(args[i+1]), args[i])
+	}
+	envelope := fmt.Sprintf(`<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>`+
+		`<u:%s xmlns:u="urn:schemas-upnp-org:service:%s:1">%s</u:%s></s:Body></s:Envelope>`, action, service, body.String(), action)
+	resp, err := http.Post(ts.URL+"/"+service+"/control", `text/xml; charset="utf-8"`, strings.NewReader(envelope))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		var fault struct {
+			Code int `xml:"Body>Fault>detail>UPnPError>errorCode"`
+		}
+		_ = xml.Unmarshal(data, &fault)
+		return nil, fmt.Errorf("UPnP error %d", fault.Code)
+	}
+	gotAction, out, err := parseSOAPRequest(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotAction != action+"Response" {
+		t.Fatalf("response action = %q", gotAction)
+	}
+	return out, nil
+}
+
+func mustCall(t *testing.T, ts *httptest.Server, service, action string, args ...string) map[string]string {
+	t.Helper()
+	out, err := soapCall(t, ts, service, action, args...)
+	if err != nil {
+		t.Fatalf("%s: %v", action, err)
+	}
+	return out
+}
+
+const testDIDL = `<DIDL-Lite xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/">` +
+	`<item id="1" parentID="0" restricted="1"><dc:title>晴天</dc:title><upnp:artist>周杰伦</upnp:artist><upnp:album>叶惠美</upnp:album>` +
+	`<upnp:albumArtURI>http://phone/cover.jpg</upnp:albumArtURI><upnp:class>object.item.audioItem.musicTrack</upnp:class>` +
+	`<res protocolInfo="http-get:*:audio/x-flac:*" duration="0:04:29.000">http://phone/song</res></item></DIDL-Lite>`
+
+func TestDescriptionListsServices(t *testing.T) {
+	_, ts := newTestServer(t, &fakeBackend{})
+	resp, err := http.Get(ts.URL + "/description.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var root struct {
+		Device struct {
+			DeviceType   string `xml:"deviceType"`
+			FriendlyName string `xml:"friendlyName"`
+			UDN          string `xml:"UDN"`
+			Services     []struct {
+				ServiceType string `xml:"serviceType"`
+				ControlURL  string `xml:"controlURL"`
+				SCPDURL     string `xml:"SCPDURL"`
+			} `xml:"serviceList>service"`
+		} `xml:"device"`
+	}
+	if err = xml.NewDecoder(resp.Body).Decode(&root); err != nil {
+		t.Fatal(err)
+	}
+	if root.Device.DeviceType != deviceType || root.Device.FriendlyName != "musicfox test" || !strings.HasPrefix(root.Device.UDN, "uuid:") {
+		t.Fatalf("device = %+v", root.Device)
+	}
+	if len(root.Device.Services) != 3 {
+		t.Fatalf("services = %+v", root.Device.Services)
+	}
+	for _, svc := range root.Device.Services {
+		resp, err := http.Get(ts.URL + svc.SCPDURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var scpd scpdDocument
+		err = xml.NewDecoder(resp.Body).Decode(&scpd)
+		_ = resp.Body.Close()
+		if err != nil || len(scpd.Actions) == 0 {
+			t.Fatalf("%s: scpd err=%v actions=%d", svc.SCPDURL, err, len(scpd.Actions))
+		}
+	}
+}
+
+func TestCastPlayPauseSeek(t *testing.T) {
+	backend := &fakeBackend{status: Status{State: types.Playing, Volume: 60}}
+	_, ts := newTestServer(t, backend)
+
+	if got := mustCall(t, ts, avTransportID, "GetTransportInfo", "InstanceID", "0"); got["CurrentTransportState"] != "NO_MEDIA_PRESENT" {
+		t.Fatalf("initial state = %v", got)
+	}
+	mustCall(t, ts, avTransportID, "SetAVTransportURI", "InstanceID", "0", "CurrentURI", "http://phone/song", "CurrentURIMetaData", testDIDL)
+	if len(backend.playedURIs()) != 0 {
+		t.Fatal("SetAVTransportURI should not start playback")
+	}
+	if got := mustCall(t, ts, avTransportID, "GetTransportInfo", "InstanceID", "0"); got["CurrentTransportState"] != "STOPPED" {
+		t.Fatalf("state after SetAVTransportURI = %v", got)
+	}
+
+	mustCall(t, ts, avTransportID, "Play", "InstanceID", "0", "Speed", "1")
+	meta := backend.lastMeta()
+	if meta.Title != "晴天" || meta.Artist != "周杰伦" || meta.Album != "叶惠美" || meta.Format != "flac" || meta.Duration != 269*time.Second {
+		t.Fatalf("meta = %+v", meta)
+	}
+	if got := mustCall(t, ts, avTransportID, "GetTransportInfo", "InstanceID", "0"); got["CurrentTransportState"] != "PLAYING" {
+		t.Fatalf("state after Play = %v", got)
+	}
+
+	mustCall(t, ts, avTransportID, "Pause", "InstanceID", "0")
+	mustCall(t, ts, avTransportID, "Seek", "InstanceID", "0", "Unit", "REL_TIME", "Target", "0:01:05")
+	pos := mustCall(t, ts, avTransportID, "GetPositionInfo", "InstanceID", "0")
+	if pos["RelTime"] != "0:01:05" || pos["TrackDuration"] != "0:04:29" || pos["TrackURI"] != "http://phone/song" {
+		t.Fatalf("position = %v", pos)
+	}
+	// 暂停后再次 Play 应继续播放而不是重新加载
+	mustCall(t, ts, avTransportID, "Play", "InstanceID", "0", "Speed", "1")
+	if played := backend.playedURIs(); len(played) != 1 {
+		t.Fatalf("played = %v, want resume", played)
+	}
+	if st := backend.Status(); st.State != types.Playing {
+		t.Fatalf("state = %v, want playing", st.State)
+	}
+
+	if _, err := soapCall(t, ts, avTransportID, "Seek", "InstanceID", "0", "Unit", "TRACK_NR", "Target", "2"); err == nil || !strings.Contains(err.Error(), "710") {
+		t.Fatalf("TRACK_NR seek err = %v", err)
+	}
+	if _, err := soapCall(t, ts, avTransportID, "Play", "InstanceID", "1"); err == nil || !strings.Contains(err.Error(), "718") {
+		t.Fatalf("invalid instance err = %v", err)
+	}
+
+	// 用户在本机播放其他歌曲后，渲染器报告停止
+	backend.set(func(st *Status) { st.Casting = false })
+	if got := mustCall(t, ts, avTransportID, "GetTransportInfo", "InstanceID", "0"); got["CurrentTransportState"] != "STOPPED" {
+		t.Fatalf("state after local playback = %v", got)
+	}
+}
+
+func TestCastAdvancesToNextURI(t *testing.T) {
+	backend := &fakeBackend{}
+	s, ts := newTestServer(t, backend)
+
+	mustCall(t, ts, avTransportID, "SetAVTransportURI", "InstanceID", "0", "CurrentURI", "http://phone/a.mp3", "CurrentURIMetaData", "")
+	mustCall(t, ts, avTransportID, "Play", "InstanceID", "0", "Speed", "1")
+	mustCall(t, ts, avTransportID, "SetNextAVTransportURI", "InstanceID", "0", "NextURI", "http://phone/b.ogg", "NextURIMetaData", "")
+
+	// 播放结束
+	backend.set(func(st *Status) { st.State = types.Stopped })
+	s.mu.Lock()
+	s.transport.startedAt = time.Now().Add(-time.Minute)
+	s.mu.Unlock()
+	s.poll()
+
+	if played := backend.playedURIs(); len(played) != 2 || played[1] != "http://phone/b.ogg" {
+		t.Fatalf("played = %v", played)
+	}
+	if meta := backend.lastMeta(); meta.Format != "ogg" || meta.Title != "b" {
+		t.Fatalf("meta from URI = %+v", meta)
+	}
+	media := mustCall(t, ts, avTransportID, "GetMediaInfo", "InstanceID", "0")
+	if media["CurrentURI"] != "http://phone/b.ogg" || media["NextURI"] != "" {
+		t.Fatalf("media = %v", media)
+	}
+
+	// 没有下一首时停在结束状态
+	backend.set(func(st *Status) { st.State = types.Stopped })
+	s.mu.Lock()
+	s.transport.startedAt = time.Now().Add(-time.Minute)
+	s.mu.Unlock()
+	s.poll()
+	if got := mustCall(t, ts, avTransportID, "GetTransportInfo", "InstanceID", "0"); got["CurrentTransportState"] != "STOPPED" {
+		t.Fatalf("state = %v", got)
+	}
+	if played := backend.playedURIs(); len(played) != 2 {
+		t.Fatalf("played = %v", played)
+	}
+}
+
+func TestRejectNonHTTPURI(t *testing.T) {
+	backend := &fakeBackend{}
+	_, ts := newTestServer(t, backend)
+
+	for _, uri := range []string{"file:///etc/passwd", "FILE:///etc/passwd", "/etc/passwd", "smb://nas/song.mp3", "http:///etc/passwd"} {
+		if _, err := soapCall(t, ts, avTransportID, "SetAVTransportURI", "InstanceID", "0", "CurrentURI", uri, "CurrentURIMetaData", ""); err == nil || !strings.Contains(err.Error(), "716") {
+			t.Errorf("SetAVTransportURI(%q) err = %v, want 716", uri, err)
+		}
+		if _, err := soapCall(t, ts, avTransportID, "SetNextAVTransportURI", "InstanceID", "0", "NextURI", uri, "NextURIMetaData", ""); err == nil || !strings.Contains(err.Error(), "716") {
+			t.Errorf("SetNextAVTransportURI(%q) err = %v, want 716", uri, err)
+		}
+	}
+	if _, err := soapCall(t, ts, avTransportID, "Play", "InstanceID", "0", "Speed", "1"); err == nil {
+		t.Error("Play without a valid URI should fail")
+	}
+	if played := backend.playedURIs(); len(played) != 0 {
+		t.Fatalf("played = %v", played)
+	}
+	media := mustCall(t, ts, avTransportID, "GetMediaInfo", "InstanceID", "0")
+	if media["CurrentURI"] != "" || media["NextURI"] != "" {
+		t.Fatalf("media = %v", media)
+	}
+
+	mustCall(t, ts, avTransportID, "SetAVTransportURI", "InstanceID", "0", "CurrentURI", "HTTPS://phone/a.mp3", "CurrentURIMetaData", "")
+}
+
+func TestRenderingControlVolumeAndMute(t *testing.T) {
+	backend := &fakeBackend{status: Status{Volume: 40}}
+	_, ts := newTestServer(t, backend)
+
+	mustCall(t, ts, renderingControlID, "SetVolume", "InstanceID", "0", "Channel", "Master", "DesiredVolume", "70")
+	if got := mustCall(t, ts, renderingControlID, "GetVolume", "InstanceID", "0", "Channel", "Master"); got["CurrentVolume"] != "70" {
+		t.Fatalf("volume = %v", got)
+	}
+	if _, err := soapCall(t, ts, renderingControlID, "SetVolume", "InstanceID", "0", "Channel", "Master", "DesiredVolume", "150"); err == nil {
+		t.Fatal("volume 150 accepted")
+	}
+
+	mustCall(t, ts, renderingControlID, "SetMute", "InstanceID", "0", "Channel", "Master", "DesiredMute", "1")
+	if backend.Status().Volume != 0 {
+		t.Fatalf("muted volume = %d", backend.Status().Volume)
+	}
+	if got := mustCall(t, ts, renderingControlID, "GetVolume", "InstanceID", "0", "Channel", "Master"); got["CurrentVolume"] != "70" {
+		t.Fatalf("volume while muted = %v", got)
+	}
+	mustCall(t, ts, renderingControlID, "SetMute", "InstanceID", "0", "Channel", "Master", "DesiredMute", "0")
+	if backend.Status().Volume != 70 {
+		t.Fatalf("unmuted volume = %d", backend.Status().Volume)
+	}
+
+	if got := mustCall(t, ts, connectionManagerID, "GetProtocolInfo"); !strings.Contains(got["Sink"], "audio/mpeg") {
+		t.Fatalf("protocol info = %v", got)
+	}
+}
+
+func TestEventSubscription(t *testing.T) {
+	backend := &fakeBackend{}
+	s, ts := newTestServer(t, backend)
+
+	type notification struct {
+		seq        string
+		lastChange string
+	}
+	notifications := make(chan notification, 10)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var set struct {
+			LastChange string `xml:"property>LastChange"`
+		}
+		_ = xml.NewDecoder(r.Body).Decode(&set)
+		notifications <- notification{seq: r.Header.Get("SEQ"), lastChange: set.LastChange}
+	}))
+	defer callback.Close()
+
+	req, _ := http.NewRequest("SUBSCRIBE", ts.URL+"/AVTransport/event", nil)
+	req.Header.Set("CALLBACK", "<"+callback.URL+"/notify>")
+	req.Header.Set("NT", "upnp:event")
+	req.Header.Set("TIMEOUT", "Second-300")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	sid := resp.Header.Get("SID")
+	if resp.StatusCode != http.StatusOK || sid == "" || resp.Header.Get("TIMEOUT") != "Second-300" {
+		t.Fatalf("subscribe: %s sid=%q timeout=%q", resp.Status, sid, resp.Header.Get("TIMEOUT"))
+	}
+
+	next := func() notification {
+		t.Helper()
+		select {
+		case n := <-notifications:
+			return n
+		case <-time.After(2 * time.Second):
+			t.Fatal("no event received")
+			return notification{}
+		}
+	}
+	if n := next(); n.seq != "0" || !strings.Contains(n.lastChange, `<TransportState val="NO_MEDIA_PRESENT"/>`) {
+		t.Fatalf("initial event = %+v", n)
+	}
+
+	mustCall(t, ts, avTransportID, "SetAVTransportURI", "InstanceID", "0", "CurrentURI", "http://phone/a.mp3", "CurrentURIMetaData", "")
+	mustCall(t, ts, avTransportID, "Play", "InstanceID", "0", "Speed", "1")
+	s.poll()
+	deadline := time.After(2 * time.Second)
+	for {
+		var n notification
+		select {
+		case n = <-notifications:
+		case <-deadline:
+			t.Fatal("PLAYING event not received")
+		}
+		if n.seq == "0" {
+			t.Fatalf("event sequence restarted: %+v", n)
+		}
+		if strings.Contains(n.lastChange, `<TransportState val="PLAYING"/>`) {
+			break
+		}
+	}
+
+	req, _ = http.NewRequest("UNSUBSCRIBE", ts.URL+"/AVTransport/event", nil)
+	req.Header.Set("SID", sid)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unsubscribe: %s", resp.Status)
+	}
+	s.subMu.Lock()
+	remaining := len(s.subs)
+	s.subMu.Unlock()
+	if remaining != 0 {
+		t.Fatalf("subscriptions left: %d", remaining)
+	}
+}
+
+func TestSSDPSearchResponses(t *testing.T) {
+	a := &ssdpAdvertiser{location: "http://192.168.1.2:8200/description.xml", udn: "uuid:test", server: "test"}
+	search := func(st string) [][]byte {
+		return a.searchResponses([]byte("M-SEARCH * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\nMAN: \"ssdp:discover\"\r\nMX: 2\r\nST: " + st + "\r\n\r\n"))
+	}
+
+	responses := search(deviceType)
+	if len(responses) != 1 {
+		t.Fatalf("responses = %d", len(responses))
+	}
+	resp := string(responses[0])
+	for _, want := range []string{"HTTP/1.1 200 OK", "LOCATION: http://192.168.1.2:8200/description.xml", "USN: uuid:test::" + deviceType} {
+		if !strings.Contains(resp, want) {
+			t.Fatalf("response missing %q:\n%s", want, resp)
+		}
+	}
+	if n := len(search("ssdp:all")); n != 3+len(services) {
+		t.Fatalf("ssdp:all responses = %d", n)
+	}
+	if n := len(search("urn:schemas-upnp-org:device:MediaServer:1")); n != 0 {
+		t.Fatalf("unrelated search answered %d times", n)
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"0:04:29":      269 * time.Second,
+		"00:00:05.500": 5500 * time.Millisecond,
+		"1:02:03.1/2":  time.Hour + 2*time.Minute + 3*time.Second,
+	} {
+		if got, err := parseClock(in); err != nil || got != want {
+			t.Errorf("parseClock(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "12", "0:61:00", "a:00:00"} {
+		if _, err := parseClock(in); err == nil {
+			t.Errorf("parseClock(%q) succeeded", in)
+		}
+	}
+	if got := formatClock(time.Hour + 5*time.Second + 300*time.Millisecond); got != "1:00:05" {
+		t.Errorf("formatClock = %q", got)
+	}
+}
@@ -0,0 +1,147 @@
+package mediarenderer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// soapError UPnP 规范定义的错误码
+type soapError struct {
+	code int
+	desc string
+}
+
+func (e *soapError) Error() string {
+	return fmt.Sprintf("UPnP error %d: %s", e.code, e.desc)
+}
+
+var (
+	errInvalidAction          = &soapError{401, "Invalid Action"}
+	errInvalidArgs            = &soapError{402, "Invalid Args"}
+	errActionFailed           = &soapError{501, "Action Failed"}
+	errTransitionNotAvailable = &soapError{701, "Transition not available"}
+	errSeekModeNotSupported   = &soapError{710, "Seek mode not supported"}
+	errIllegalSeekTarget      = &soapError{711, "Illegal seek target"}
+	errResourceNotFound       = &soapError{716, "Resource not found"}
+	errInvalidInstanceID      = &soapError{718, "Invalid InstanceID"}
+)
+
+// arg 动作的输出参数或事件变量，保持声明顺序
+type arg struct {
+	name  string
+	value string
+}
+
+type soapArgument struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// parseSOAPRequest 解析 SOAP 请求，返回 Body 中的动作名称和参数
+func parseSOAPRequest(r io.Reader) (string, map[string]string, error) {
+	dec := xml.NewDecoder(r)
+	var inBody bool
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if !inBody {
+			inBody = start.Name.Local == "Body"
+			continue
+		}
+		var call struct {
+			Args []soapArgument `xml:",any"`
+		}
+		if err = dec.DecodeElement(&call, &start); err != nil {
+			return "", nil, err
+		}
+		args := make(map[string]string, len(call.Args))
+		for _, a := range call.Args {
+			args[a.XMLName.Local] = a.Value
+		}
+		return start.Name.Local, args, nil
+	}
+}
+
+func (s *Server) handleControl(w http.ResponseWriter, r *http.Request) {
+	svc, ok := lookupService(r.PathValue("service"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	action, args, err := parseSOAPRequest(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeSOAPError(w, errInvalidArgs)
+		return
+	}
+	if id, ok := args["InstanceID"]; ok && id != "0" {
+		writeSOAPError(w, errInvalidInstanceID)
+		return
+	}
+
+	var out []arg
+	switch svc.ID {
+	case avTransportID:
+		out, err = s.avTransportAction(action, args)
+	case renderingControlID:
+		out, err = s.renderingControlAction(action, args)
+	case connectionManagerID:
+		out, err = connectionManagerAction(action, args)
+	}
+	if err != nil {
+		slog.Debug("DLNA renderer: action failed", "service", svc.ID, "action", action, "error", err)
+		soapErr, ok := err.(*soapError)
+		if !ok {
+			soapErr = errActionFailed
+		}
+		writeSOAPError(w, soapErr)
+		return
+	}
+	// 状态变化尽快推送给订阅者，不必等待下一次轮询
+	go s.poll()
+	writeSOAPResponse(w, svc.Type, action, out)
+}
+
+const soapEnvelopeStart = `<?xml version="1.0" encoding="utf-8"?>` +
+	`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`
+
+const soapEnvelopeEnd = `</s:Body></s:Envelope>`
+
+func writeSOAPResponse(w http.ResponseWriter, serviceType, action string, out []arg) {
+	var buf bytes.Buffer
+	buf.WriteString(soapEnvelopeStart)
+	fmt.Fprintf(&buf, `<u:%sResponse xmlns:u="%s">`, action, serviceType)
+	for _, a := range out {
+		fmt.Fprintf(&buf, "<%s>", a.name)
+		_ = xml.EscapeText(&buf, []byte(a.value))
+		fmt.Fprintf(&buf, "</%s>", a.name)
+	}
+	fmt.Fprintf(&buf, `</u:%sResponse>`, action)
+	buf.WriteString(soapEnvelopeEnd)
+
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	w.Header().Set("EXT", "")
+	_, _ = w.Write(buf.Bytes())
+}
+
+func writeSOAPError(w http.ResponseWriter, err *soapError) {
+	var buf bytes.Buffer
+	buf.WriteString(soapEnvelopeStart)
+	buf.WriteString(`<s:Fault><faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring><detail>`)
+	fmt.Fprintf(&buf, `<UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>%d</errorCode><errorDescription>%s</errorDescription></UPnPError>`, err.code, err.desc)
+	buf.WriteString(`</detail></s:Fault>`)
+	buf.WriteString(soapEnvelopeEnd)
+
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusInternalServerError)
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,183 @@
+package mediarenderer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+const (
+	ssdpAddr = "239.255.255.250:1900"
+	// ssdpMaxAge 公告的有效期，在过期前重新公告
+	ssdpMaxAge         = 1800
+	ssdpNotifyInterval = 10 * time.Minute
+)
+
+// ssdpAdvertiser 在多播组中公布设备（NOTIFY）并响应控制点的 M-SEARCH
+type ssdpAdvertiser struct {
+	conn     *net.UDPConn
+	group    *net.UDPAddr
+	location string
+	udn      string
+	server   string
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newSSDPAdvertiser(localIP, location, udn string) (*ssdpAdvertiser, error) {
+	group, err := net.ResolveUDPAddr("udp4", ssdpAddr)
+	if err != nil {
+		return nil, err
+	}
+	// 多网卡时在 localIP 所在的网卡上收发多播
+	conn, err := net.ListenMulticastUDP("udp4", interfaceByIP(localIP), group)
+	if err != nil {
+		return nil, err
+	}
+	return &ssdpAdvertiser{
+		conn:     conn,
+		group:    group,
+		location: location,
+		udn:      udn,
+		server:   fmt.Sprintf("%s/1.0 UPnP/1.0 %s/%s", runtime.GOOS, types.AppName, strings.TrimPrefix(types.AppVersion, "v")),
+		closed:   make(chan struct{}),
+	}, nil
+}
+
+func interfaceByIP(ip string) *net.Interface {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	for _, iface := range ifaces {
+		addrs, _ := iface.Addrs()
+		for _, a := range addrs {
+			if ipNet, ok := a.(*net.IPNet); ok && ipNet.IP.String() == ip {
+				return &iface
+			}
+		}
+	}
+	return nil
+}
+
+// targets 设备需要公布的所有通知类型（NT）及对应的 USN
+func (a *ssdpAdvertiser) targets() [][2]string {
+	targets := [][2]string{
+		{"upnp:rootdevice", a.udn + "::upnp:rootdevice"},
+		{a.udn, a.udn},
+		{deviceType, a.udn + "::" + deviceType},
+	}
+	for _, svc := range services {
+		targets = append(targets, [2]string{svc.Type, a.udn + "::" + svc.Type})
+	}
+	return targets
+}
+
+func (a *ssdpAdvertiser) run() {
+	go a.serve()
+	a.notify("ssdp:alive")
+	ticker := time.NewTicker(ssdpNotifyInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.closed:
+			return
+		case <-ticker.C:
+			a.notify("ssdp:alive")
+		}
+	}
+}
+
+// serve 响应多播组中的 M-SEARCH 请求
+func (a *ssdpAdvertiser) serve() {
+	buf := make([]byte, 2048)
+	for {
+		n, addr, err := a.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-a.closed:
+			default:
+				slog.Warn("DLNA renderer: SSDP read failed", "error", err)
+			}
+			return
+		}
+		for _, resp := range a.searchResponses(buf[:n]) {
+			if _, err = a.conn.WriteToUDP(resp, addr); err != nil {
+				slog.Debug("DLNA renderer: SSDP response failed", "addr", addr, "error", err)
+			}
+		}
+	}
+}
+
+// searchResponses 解析 M-SEARCH 请求，返回需要单播回复的响应
+func (a *ssdpAdvertiser) searchResponses(data []byte) [][]byte {
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil || req.Method != "M-SEARCH" || req.Header.Get("MAN") != `"ssdp:discover"` {
+		return nil
+	}
+	st := req.Header.Get("ST")
+	var responses [][]byte
+	for _, target := range a.targets() {
+		if st != "ssdp:all" && st != target[0] {
+			continue
+		}
+		responses = append(responses, fmt.Appendf(nil, "HTTP/1.1 200 OK\r\n"+
+			"CACHE-CONTROL: max-age=%d\r\n"+
+			"DATE: %s\r\n"+
+			"EXT:\r\n"+
+			"LOCATION: %s\r\n"+
+			"SERVER: %s\r\n"+
+			"ST: %s\r\n"+
+			"USN: %s\r\n"+
+			"\r\n", ssdpMaxAge, time.Now().UTC().Format(http.TimeFormat), a.location, a.server, target[0], target[1]))
+	}
+	return responses
+}
+
+// notify 向多播组发送 ssdp:alive 或 ssdp:byebye
+func (a *ssdpAdvertiser) notify(nts string) {
+	for _, target := range a.targets() {
+		var msg []byte
+		if nts == "ssdp:byebye" {
+			msg = fmt.Appendf(nil, "NOTIFY * HTTP/1.1\r\n"+
+				"HOST: %s\r\n"+
+				"NT: %s\r\n"+
+				"NTS: %s\r\n"+
+				"USN: %s\r\n"+
+				"\r\n", ssdpAddr, target[0], nts, target[1])
+		} else {
+			msg = fmt.Appendf(nil, "NOTIFY * HTTP/1.1\r\n"+
+				"HOST: %s\r\n"+
+				"CACHE-CONTROL: max-age=%d\r\n"+
+				"LOCATION: %s\r\n"+
+				"NT: %s\r\n"+
+				"NTS: %s\r\n"+
+				"SERVER: %s\r\n"+
+				"USN: %s\r\n"+
+				"\r\n", ssdpAddr, ssdpMaxAge, a.location, target[0], nts, a.server, target[1])
+		}
+		if _, err := a.conn.WriteToUDP(msg, a.group); err != nil {
+			slog.Debug("DLNA renderer: SSDP notify failed", "nts", nts, "error", err)
+			return
+		}
+	}
+}
+
+// close 通知控制点设备下线
+func (a *ssdpAdvertiser) close() {
+	a.closeOnce.Do(func() {
+		close(a.closed)
+		a.notify("ssdp:byebye")
+		_ = a.conn.Close()
+	})
+}
@@ -0,0 +1,288 @@
+package mpdserver
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// MPD 协议的错误码
+const (
+	ackErrorNotList    = 1
+	ackErrorArg        = 2
+	ackErrorPassword   = 3
+	ackErrorPermission = 4
+	ackErrorUnknown    = 5
+	ackErrorNoExist    = 50
+	ackErrorSystem     = 52
+)
+
+// ackError 以 "ACK [code@index] {command} message" 返回给客户端的错误
+type ackError struct {
+	code int
+	msg  string
+}
+
+func (e *ackError) Error() string {
+	return e.msg
+}
+
+func ackf(code int, format string, args ...any) *ackError {
+	return &ackError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// response 命令的输出
+type response struct {
+	bytes.Buffer
+}
+
+func (r *response) kv(key string, value any) {
+	fmt.Fprintf(r, "%s: %v\n", key, value)
+}
+
+type commandListMode uint8
+
+const (
+	listNone commandListMode = iota
+	listPlain
+	listOK
+)
+
+// client 一个客户端连接
+type client struct {
+	server *Server
+	w      *bufio.Writer
+	lines  chan string
+	done   chan struct{}
+	authed bool
+
+	listMode commandListMode
+	list     [][]string
+
+	// snapshot 上次 idle 返回时的状态，期间的变化会在下一次 idle 时立即返回
+	snapshot snapshot
+}
+
+// readLines 逐行读取命令，连接断开时关闭 lines
+func (c *client) readLines(conn net.Conn) {
+	defer close(c.lines)
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	for scanner.Scan() {
+		select {
+		case c.lines <- strings.TrimSuffix(scanner.Text(), "\r"):
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *client) run() error {
+	if _, err := fmt.Fprintf(c.w, "OK MPD %s\n", protocolVersion); err != nil {
+		return err
+	}
+	if err := c.w.Flush(); err != nil {
+		return err
+	}
+	for line := range c.lines {
+		quit, err := c.handleLine(line)
+		if err != nil {
+			return err
+		}
+		if err = c.w.Flush(); err != nil || quit {
+			return err
+		}
+	}
+	return io.EOF
+}
+
+// handleLine 处理一行输入，返回是否需要关闭连接
+func (c *client) handleLine(line string) (bool, error) {
+	args, err := splitArgs(line)
+	if err != nil {
+		c.writeAck(0, "", ackf(ackErrorArg, "%s", err))
+		return false, nil
+	}
+	if len(args) == 0 {
+		c.writeAck(0, "", ackf(ackErrorUnknown, "No command given"))
+		return false, nil
+	}
+
+	if c.listMode != listNone {
+		if args[0] != "command_list_end" {
+			c.list = append(c.list, args)
+			return false, nil
+		}
+		list, mode := c.list, c.listMode
+		c.list, c.listMode = nil, listNone
+		for i, cmd := range list {
+			var resp response
+			if err := c.execute(cmd, &resp); err != nil {
+				_, _ = c.w.Write(resp.Bytes())
+				c.writeAck(i, cmd[0], err)
+				return false, nil
+			}
+			_, _ = c.w.Write(resp.Bytes())
+			if mode == listOK {
+				_, _ = c.w.WriteString("list_OK\n")
+			}
+		}
+		_, _ = c.w.WriteString("OK\n")
+		return false, nil
+	}
+
+	switch args[0] {
+	case "close":
+		return true, nil
+	case "command_list_begin":
+		c.listMode = listPlain
+		return false, nil
+	case "command_list_ok_begin":
+		c.listMode = listOK
+		return false, nil
+	case "command_list_end":
+		c.writeAck(0, args[0], ackf(ackErrorNotList, "not in command list mode"))
+		return false, nil
+	case "idle":
+		if !c.authed {
+			c.writeAck(0, args[0], ackf(ackErrorPermission, "you don't have permission for %q", args[0]))
+			return false, nil
+		}
+		return c.idle(args[1:])
+	case "noidle":
+		// 不在 idle 状态时忽略
+		return false, nil
+	}
+
+	var resp response
+	if err := c.execute(args, &resp); err != nil {
+		_, _ = c.w.Write(resp.Bytes())
+		c.writeAck(0, args[0], err)
+		return false, nil
+	}
+	_, _ = c.w.Write(resp.Bytes())
+	_, _ = c.w.WriteString("OK\n")
+	return false, nil
+}
+
+func (c *client) execute(args []string, resp *response) error {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return ackf(ackErrorUnknown, "unknown command %q", args[0])
+	}
+	if !c.authed && !cmd.public {
+		return ackf(ackErrorPermission, "you don't have permission for %q", args[0])
+	}
+	if len(args)-1 < cmd.minArgs || (cmd.maxArgs >= 0 && len(args)-1 > cmd.maxArgs) {
+		return ackf(ackErrorArg, "wrong number of arguments for %q", args[0])
+	}
+	return cmd.fn(c, args[1:], resp)
+}
+
+func (c *client) writeAck(index int, command string, err error) {
+	var ack *ackError
+	if !errors.As(err, &ack) {
+		ack = ackf(ackErrorSystem, "%s", err)
+	}
+	fmt.Fprintf(c.w, "ACK [%d@%d] {%s} %s\n", ack.code, index, command, ack.msg)
+}
+
+// idle 等待 subsystems 中的变化，收到 noidle 时立即返回
+func (c *client) idle(subsystems []string) (bool, error) {
+	for _, name := range subsystems {
+		if !knownSubsystems[name] {
+			c.writeAck(0, "idle", ackf(ackErrorArg, "Unrecognized idle event: %s", name))
+			return false, nil
+		}
+	}
+
+	ticker := time.NewTicker(c.server.pollInterval)
+	defer ticker.Stop()
+	for {
+		current, err := c.server.snapshot()
+		if err == nil {
+			if changed := c.snapshot.changes(current, subsystems); len(changed) > 0 {
+				c.snapshot = c.snapshot.advance(current, changed)
+				c.writeChanged(changed)
+				return false, nil
+			}
+		}
+
+		select {
+		case line, ok := <-c.lines:
+			if !ok {
+				return true, nil
+			}
+			if strings.TrimSpace(line) != "noidle" {
+				// idle 期间只允许 noidle，与 MPD 一致直接断开
+				return true, nil
+			}
+			if err == nil {
+				changed := c.snapshot.changes(current, subsystems)
+				c.snapshot = c.snapshot.advance(current, changed)
+				c.writeChanged(changed)
+			} else {
+				_, _ = c.w.WriteString("OK\n")
+			}
+			return false, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *client) writeChanged(changed []string) {
+	for _, name := range changed {
+		fmt.Fprintf(c.w, "changed: %s\n", name)
+	}
+	_, _ = c.w.WriteString("OK\n")
+}
+
+// splitArgs 按 MPD 的规则拆分参数：空白分隔，双引号内可以用反斜杠转义
+func splitArgs(line string) ([]string, error) {
+	var (
+		args []string
+		cur  strings.Builder
+	)
+	line = strings.TrimSpace(line)
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == ' ' || line[i] == '\t':
+			i++
+		case line[i] == '"':
+			cur.Reset()
+			i++
+			for {
+				if i >= len(line) {
+					return nil, errors.New("missing closing '\"'")
+				}
+				if line[i] == '\\' && i+1 < len(line) {
+					cur.WriteByte(line[i+1])
+					i += 2
+					continue
+				}
+				if line[i] == '"' {
+					i++
+					break
+				}
+				cur.WriteByte(line[i])
+				i++
+			}
+			if i < len(line) && line[i] != ' ' && line[i] != '\t' {
+				return nil, errors.New("space expected after closing '\"'")
+			}
+			args = append(args, cur.String())
+		default:
+			start := i
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+				i++
+			}
+			args = append(args, line[start:i])
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,707 @@
+package mpdserver
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
+	"github.com/go-musicfox/go-musicfox/utils/netease"
+)
+
+// command 一条 MPD 命令，maxArgs 为 -1 时不限制参数个数
+type command struct {
+	fn      func(c *client, args []string, r *response) error
+	public  bool
+	minArgs int
+	maxArgs int
+}
+
+var commands map[string]command
+
+func init() {
+	// 在 init 中赋值，避免 commands 与 cmdCommands 之间的初始化循环
+	commands = map[string]command{
+		"ping":        {fn: cmdNoop, public: true},
+		"password":    {fn: cmdPassword, public: true, minArgs: 1, maxArgs: 1},
+		"commands":    {fn: cmdCommands, public: true},
+		"notcommands": {fn: cmdNotCommands, public: true},
+		"tagtypes":    {fn: cmdTagTypes, public: true, maxArgs: -1},
+		"urlhandlers": {fn: cmdURLHandlers},
+		"binarylimit": {fn: cmdNoop, minArgs: 1, maxArgs: 1},
+
+		"status":      {fn: cmdStatus},
+		"currentsong": {fn: cmdCurrentSong},
+		"stats":       {fn: cmdStats},
+
+		"play":     {fn: cmdPlay, maxArgs: 1},
+		"playid":   {fn: cmdPlayID, maxArgs: 1},
+		"pause":    {fn: cmdPause, maxArgs: 1},
+		"stop":     {fn: cmdSimple(ipc.CmdStop)},
+		"next":     {fn: cmdSimple(ipc.CmdNext)},
+		"previous": {fn: cmdSimple(ipc.CmdPrev)},
+		"seek":     {fn: cmdSeek, minArgs: 2, maxArgs: 2},
+		"seekid":   {fn: cmdSeekID, minArgs: 2, maxArgs: 2},
+		"seekcur":  {fn: cmdSeekCur, minArgs: 1, maxArgs: 1},
+
+		"setvol":  {fn: cmdSetVol, minArgs: 1, maxArgs: 1},
+		"volume":  {fn: cmdVolume, minArgs: 1, maxArgs: 1},
+		"getvol":  {fn: cmdGetVol},
+		"random":  {fn: cmdRandom, minArgs: 1, maxArgs: 1},
+		"repeat":  {fn: cmdRepeat, minArgs: 1, maxArgs: 1},
+		"single":  {fn: cmdSingle, minArgs: 1, maxArgs: 1},
+		"consume": {fn: cmdConsume, minArgs: 1, maxArgs: 1},
+
+		"replay_gain_status": {fn: cmdReplayGainStatus},
+
+		"playlistinfo":   {fn: cmdPlaylistInfo, maxArgs: 1},
+		"playlistid":     {fn: cmdPlaylistID, maxArgs: 1},
+		"playlist":       {fn: cmdPlaylist},
+		"plchanges":      {fn: cmdPlChanges, minArgs: 1, maxArgs: 2},
+		"plchangesposid": {fn: cmdPlChangesPosID, minArgs: 1, maxArgs: 2},
+		"add":            {fn: cmdAdd, minArgs: 1, maxArgs: 2},
+		"addid":          {fn: cmdAddID, minArgs: 1, maxArgs: 2},
+
+		"outputs": {fn: cmdOutputs},
+
+		// musicfox 没有本地音乐库、存储的播放列表等概念，返回空结果以免客户端报错
+		"decoders":      {fn: cmdNoop},
+		"listplaylists": {fn: cmdNoop},
+		"lsinfo":        {fn: cmdNoop, maxArgs: 1},
+		"list":          {fn: cmdNoop, minArgs: 1, maxArgs: -1},
+		"find":          {fn: cmdNoop, minArgs: 1, maxArgs: -1},
+		"search":        {fn: cmdNoop, minArgs: 1, maxArgs: -1},
+		"channels":      {fn: cmdNoop},
+		"readmessages":  {fn: cmdNoop},
+		"listmounts":    {fn: cmdNoop},
+		"listneighbors": {fn: cmdNoop},
+	}
+}
+
+func cmdNoop(*client, []string, *response) error {
+	return nil
+}
+
+func cmdSimple(cmd string) func(*client, []string, *response) error {
+	return func(c *client, _ []string, _ *response) error {
+		return c.call(ipc.Request{Cmd: cmd})
+	}
+}
+
+// call 执行一条控制请求，错误以 ACK_ERROR_SYSTEM 返回
+func (c *client) call(req ipc.Request) error {
+	if _, err := c.server.backend.Handle(req); err != nil {
+		return ackf(ackErrorSystem, "%s", err)
+	}
+	return nil
+}
+
+func cmdPassword(c *client, args []string, _ *response) error {
+	if c.server.password == "" || args[0] != c.server.password {
+		return ackf(ackErrorPassword, "incorrect password")
+	}
+	c.authed = true
+	return nil
+}
+
+func cmdCommands(c *client, _ []string, r *response) error {
+	for _, name := range commandNames() {
+		if c.authed || commands[name].public {
+			r.kv("command", name)
+		}
+	}
+	return nil
+}
+
+func cmdNotCommands(c *client, _ []string, r *response) error {
+	if c.authed {
+		return nil
+	}
+	for _, name := range commandNames() {
+		if !commands[name].public {
+			r.kv("command", name)
+		}
+	}
+	return nil
+}
+
+func commandNames() []string {
+	names := []string{"close", "command_list_begin", "command_list_ok_begin", "command_list_end", "idle", "noidle"}
+	for name := range commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// cmdTagTypes 只支持固定的几种标签，子命令（clear/all/enable/disable）直接忽略
+func cmdTagTypes(_ *client, args []string, r *response) error {
+	if len(args) > 0 {
+		return nil
+	}
+	for _, tag := range []string{"Artist", "Album", "Title"} {
+		r.kv("tagtype", tag)
+	}
+	return nil
+}
+
+func cmdURLHandlers(_ *client, _ []string, r *response) error {
+	r.kv("handler", "netease:")
+	r.kv("handler", "https://")
+	return nil
+}
+
+func mpdState(state string) string {
+	switch state {
+	case "playing":
+		return "play"
+	case "paused":
+		return "pause"
+	default:
+		return "stop"
+	}
+}
+
+// songID 队列中的歌曲没有独立的 id，使用位置加一
+func songID(pos int) int {
+	return pos + 1
+}
+
+func boolFlag(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func cmdStatus(c *client, _ []string, r *response) error {
+	status, err := c.server.status()
+	if err != nil {
+		return err
+	}
+	queue, err := c.server.queue()
+	if err != nil {
+		return err
+	}
+	state := mpdState(status.State)
+
+	r.kv("volume", status.Volume)
+	r.kv("repeat", boolFlag(status.LoopStatus != "None"))
+	r.kv("random", boolFlag(status.Shuffle))
+	r.kv("single", boolFlag(status.LoopStatus == "Track"))
+	r.kv("consume", 0)
+	r.kv("playlist", c.server.playlistVersion(queue))
+	r.kv("playlistlength", len(queue.Songs))
+	r.kv("mixrampdb", "0.000000")
+	r.kv("state", state)
+	if queue.Index >= 0 && queue.Index < len(queue.Songs) {
+		r.kv("song", queue.Index)
+		r.kv("songid", songID(queue.Index))
+	}
+	if state != "stop" {
+		r.kv("time", fmt.Sprintf("%d:%d", int(status.PassedDuration), int(math.Round(status.TotalDuration))))
+		r.kv("elapsed", fmt.Sprintf("%.3f", status.PassedDuration))
+		r.kv("duration", fmt.Sprintf("%.3f", status.TotalDuration))
+	}
+	if next, ok := nextIndex(status, queue); ok {
+		r.kv("nextsong", next)
+		r.kv("nextsongid", songID(next))
+	}
+	return nil
+}
+
+// nextIndex 根据循环模式推算下一首的位置，随机播放时无法预知；待播队列不受播放模式影响
+func nextIndex(status ipc.Status, queue ipc.QueueInfo) (int, bool) {
+	n := len(queue.Songs)
+	if queue.Index < 0 || queue.Index >= n {
+		return 0, false
+	}
+	if queue.UpNext > 0 {
+		return queue.Index + 1, queue.Index+1 < n
+	}
+	if status.Shuffle {
+		return 0, false
+	}
+	switch status.LoopStatus {
+	case "Track":
+		return queue.Index, true
+	case "Playlist":
+		return (queue.Index + 1) % n, true
+	default:
+		return queue.Index + 1, queue.Index+1 < n
+	}
+}
+
+func cmdCurrentSong(c *client, _ []string, r *response) error {
+	status, err := c.server.status()
+	if err != nil {
+		return err
+	}
+	queue, err := c.server.queue()
+	if err != nil {
+		return err
+	}
+	if queue.Index >= 0 && queue.Index < len(queue.Songs) && queue.Songs[queue.Index].ID == status.TrackID {
+		writeSong(r, queue.Index, queue.Songs[queue.Index])
+		return nil
+	}
+	if status.TrackID == 0 && status.Name == "" {
+		return nil
+	}
+	// 当前播放的不在队列中（如投屏），只返回基本信息
+	writeSong(r, -1, ipc.QueueItem{
+		ID:       status.TrackID,
+		Name:     status.Name,
+		Artist:   status.Artist,
+		Album:    status.Album,
+		Duration: status.TotalDuration,
+	})
+	return nil
+}
+
+func writeSong(r *response, pos int, song ipc.QueueItem) {
+	if song.ID > 0 {
+		r.kv("file", netease.WebUrlOfSong(song.ID))
+	} else {
+		r.kv("file", fmt.Sprintf("musicfox:%d", pos))
+	}
+	r.kv("Title", song.Name)
+	if song.Artist != "" {
+		r.kv("Artist", song.Artist)
+	}
+	if song.Album != "" {
+		r.kv("Album", song.Album)
+	}
+	r.kv("Time", int(math.Round(song.Duration)))
+	r.kv("duration", fmt.Sprintf("%.3f", song.Duration))
+	if pos >= 0 {
+		r.kv("Pos", pos)
+		r.kv("Id", songID(pos))
+	}
+}
+
+func cmdStats(c *client, _ []string, r *response) error {
+	r.kv("uptime", int(time.Since(c.server.started).Seconds()))
+	r.kv("playtime", 0)
+	r.kv("artists", 0)
+	r.kv("albums", 0)
+	r.kv("songs", 0)
+	r.kv("db_playtime", 0)
+	r.kv("db_update", 0)
+	return nil
+}
+
+func parseInt(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, ackf(ackErrorArg, "Integer expected: %s", s)
+	}
+	return n, nil
+}
+
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, ackf(ackErrorArg, "Number expected: %s", s)
+	}
+	return f, nil
+}
+
+func parseBool(s string) (bool, error) {
+	switch s {
+	case "0":
+		return false, nil
+	case "1":
+		return true, nil
+	default:
+		return false, ackf(ackErrorArg, "Boolean (0/1) expected: %s", s)
+	}
+}
+
+// playPos 播放队列中 pos 位置的歌曲，pos 为 -1 时继续播放
+func (c *client) playPos(pos int) error {
+	if pos < 0 {
+		return c.call(ipc.Request{Cmd: ipc.CmdPlay})
+	}
+	queue, err := c.server.queue()
+	if err != nil {
+		return err
+	}
+	if pos >= len(queue.Songs) {
+		return ackf(ackErrorArg, "Bad song index")
+	}
+	return c.call(ipc.Request{Cmd: ipc.CmdPlay, Index: &pos})
+}
+
+func cmdPlay(c *client, args []string, _ *response) error {
+	pos := -1
+	if len(args) > 0 {
+		var err error
+		if pos, err = parseInt(args[0]); err != nil {
+			return err
+		}
+	}
+	return c.playPos(pos)
+}
+
+func cmdPlayID(c *client, args []string, _ *response) error {
+	if len(args) == 0 {
+		return c.playPos(-1)
+	}
+	id, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	if id < 1 {
+		return c.playPos(-1)
+	}
+	return c.playPos(id - 1)
+}
+
+func cmdPause(c *client, args []string, _ *response) error {
+	if len(args) == 0 {
+		return c.call(ipc.Request{Cmd: ipc.CmdToggle})
+	}
+	pause, err := parseBool(args[0])
+	if err != nil {
+		return err
+	}
+	if pause {
+		return c.call(ipc.Request{Cmd: ipc.CmdPause})
+	}
+	return c.call(ipc.Request{Cmd: ipc.CmdResume})
+}
+
+// seekPos 跳转到队列中 pos 位置歌曲的 position 秒，不是当前歌曲时先切换
+func (c *client) seekPos(pos int, position string) error {
+	seconds, err := parseFloat(position)
+	if err != nil {
+		return err
+	}
+	if seconds < 0 {
+		return ackf(ackErrorArg, "Negative position")
+	}
+	queue, err := c.server.queue()
+	if err != nil {
+		return err
+	}
+	if pos < 0 || pos >= len(queue.Songs) {
+		return ackf(ackErrorArg, "Bad song index")
+	}
+	if pos != queue.Index {
+		if err = c.call(ipc.Request{Cmd: ipc.CmdPlay, Index: &pos}); err != nil {
+			return err
+		}
+	}
+	return c.call(ipc.Request{Cmd: ipc.CmdSeek, Position: seconds})
+}
+
+func cmdSeek(c *client, args []string, _ *response) error {
+	pos, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	return c.seekPos(pos, args[1])
+}
+
+func cmdSeekID(c *client, args []string, _ *response) error {
+	id, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	return c.seekPos(id-1, args[1])
+}
+
+// cmdSeekCur 以 + 或 - 开头时相对当前进度跳转
+func cmdSeekCur(c *client, args []string, _ *response) error {
+	seconds, err := parseFloat(args[0])
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(args[0], "+") || strings.HasPrefix(args[0], "-") {
+		status, err := c.server.status()
+		if err != nil {
+			return err
+		}
+		seconds = max(status.PassedDuration+seconds, 0)
+	} else if seconds < 0 {
+		return ackf(ackErrorArg, "Negative position")
+	}
+	return c.call(ipc.Request{Cmd: ipc.CmdSeek, Position: seconds})
+}
+
+func (c *client) setVolume(volume int) error {
+	if volume < 0 || volume > 100 {
+		return ackf(ackErrorArg, "Invalid volume value")
+	}
+	return c.call(ipc.Request{Cmd: ipc.CmdVolume, Volume: &volume})
+}
+
+func cmdSetVol(c *client, args []string, _ *response) error {
+	volume, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	return c.setVolume(volume)
+}
+
+func cmdVolume(c *client, args []string, _ *response) error {
+	delta, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	status, err := c.server.status()
+	if err != nil {
+		return err
+	}
+	return c.setVolume(min(max(status.Volume+delta, 0), 100))
+}
+
+func cmdGetVol(c *client, _ []string, r *response) error {
+	status, err := c.server.status()
+	if err != nil {
+		return err
+	}
+	r.kv("volume", status.Volume)
+	return nil
+}
+
+func cmdRandom(c *client, args []string, _ *response) error {
+	on, err := parseBool(args[0])
+	if err != nil {
+		return err
+	}
+	mode := ipc.ShuffleOff
+	if on {
+		mode = ipc.ShuffleOn
+	}
+	return c.call(ipc.Request{Cmd: ipc.CmdShuffle, Action: mode})
+}
+
+// setLoop 把 MPD 的 repeat/single 组合映射为 musicfox 的循环模式：
+// single 为 1 时单曲循环，否则 repeat 为 1 时列表循环，都为 0 时顺序播放
+func (c *client) setLoop(repeat, single bool) error {
+	mode := ipc.RepeatOff
+	switch {
+	case single:
+		mode = ipc.RepeatOne
+	case repeat:
+		mode = ipc.RepeatAll
+	}
+	return c.call(ipc.Request{Cmd: ipc.CmdRepeat, Action: mode})
+}
+
+func cmdRepeat(c *client, args []string, _ *response) error {
+	repeat, err := parseBool(args[0])
+	if err != nil {
+		return err
+	}
+	status, err := c.server.status()
+	if err != nil {
+		return err
+	}
+	return c.setLoop(repeat, repeat && status.LoopStatus == "Track")
+}
+
+func cmdSingle(c *client, args []string, _ *response) error {
+	single, err := parseBool(args[0])
+	if err != nil {
+		return err
+	}
+	status, err := c.server.status()
+	if err != nil {
+		return err
+	}
+	return c.setLoop(status.LoopStatus != "None", single)
+}
+
+func cmdConsume(_ *client, args []string, _ *response) error {
+	on, err := parseBool(args[0])
+	if err != nil {
+		return err
+	}
+	if on {
+		return ackf(ackErrorArg, "consume mode is not supported")
+	}
+	return nil
+}
+
+func cmdReplayGainStatus(_ *client, _ []string, r *response) error {
+	r.kv("replay_gain_mode", "off")
+	return nil
+}
+
+// parseRange 解析 POS 或 START:END（END 可省略），返回左闭右开区间
+func parseRange(arg string, length int) (int, int, error) {
+	startStr, endStr, isRange := strings.Cut(arg, ":")
+	start, err := parseInt(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	end := start + 1
+	if isRange {
+		end = length
+		if endStr != "" {
+			if end, err = parseInt(endStr); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	if start < 0 || end < start {
+		return 0, 0, ackf(ackErrorArg, "Bad song index")
+	}
+	if !isRange && start >= length {
+		return 0, 0, ackf(ackErrorArg, "Bad song index")
+	}
+	return min(start, length), min(end, length), nil
+}
+
+func (c *client) writeQueue(r *response, arg string) error {
+	queue, err := c.server.queue()
+	if err != nil {
+		return err
+	}
+	start, end := 0, len(queue.Songs)
+	if arg != "" {
+		if start, end, err = parseRange(arg, len(queue.Songs)); err != nil {
+			return err
+		}
+	}
+	for pos := start; pos < end; pos++ {
+		writeSong(r, pos, queue.Songs[pos])
+	}
+	return nil
+}
+
+func cmdPlaylistInfo(c *client, args []string, r *response) error {
+	var arg string
+	if len(args) > 0 {
+		arg = args[0]
+	}
+	return c.writeQueue(r, arg)
+}
+
+func cmdPlaylistID(c *client, args []string, r *response) error {
+	if len(args) == 0 {
+		return c.writeQueue(r, "")
+	}
+	id, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	if id < 1 {
+		return ackf(ackErrorNoExist, "No such song")
+	}
+	return c.writeQueue(r, strconv.Itoa(id-1))
+}
+
+func cmdPlaylist(c *client, _ []string, r *response) error {
+	queue, err := c.server.queue()
+	if err != nil {
+		return err
+	}
+	for pos, song := range queue.Songs {
+		fmt.Fprintf(r, "%d:file: %s\n", pos, netease.WebUrlOfSong(song.ID))
+	}
+	return nil
+}
+
+// changedSince 客户端持有的版本与当前不同时认为整个队列都已变化
+func (c *client) changedSince(version string) (ipc.QueueInfo, bool, error) {
+	v, err := strconv.ParseUint(version, 10, 32)
+	if err != nil {
+		return ipc.QueueInfo{}, false, ackf(ackErrorArg, "Integer expected: %s", version)
+	}
+	queue, err := c.server.queue()
+	if err != nil {
+		return ipc.QueueInfo{}, false, err
+	}
+	return queue, uint32(v) != c.server.playlistVersion(queue), nil
+}
+
+func plChangesRange(args []string, length int) (int, int, error) {
+	if len(args) < 2 {
+		return 0, length, nil
+	}
+	return parseRange(args[1], length)
+}
+
+func cmdPlChanges(c *client, args []string, r *response) error {
+	queue, changed, err := c.changedSince(args[0])
+	if err != nil || !changed {
+		return err
+	}
+	start, end, err := plChangesRange(args, len(queue.Songs))
+	if err != nil {
+		return err
+	}
+	for pos := start; pos < end; pos++ {
+		writeSong(r, pos, queue.Songs[pos])
+	}
+	return nil
+}
+
+func cmdPlChangesPosID(c *client, args []string, r *response) error {
+	queue, changed, err := c.changedSince(args[0])
+	if err != nil || !changed {
+		return err
+	}
+	start, end, err := plChangesRange(args, len(queue.Songs))
+	if err != nil {
+		return err
+	}
+	for pos := start; pos < end; pos++ {
+		r.kv("cpos", pos)
+		r.kv("Id", songID(pos))
+	}
+	return nil
+}
+
+// addSong 添加歌曲到队列，指定位置时只支持当前歌曲之后（+0 或当前位置加一），返回新歌曲的位置
+func (c *client) addSong(args []string) (int, error) {
+	id, ok := netease.ParseSongId(args[0])
+	if !ok {
+		return 0, ackf(ackErrorNoExist, "Unsupported URI scheme")
+	}
+	queue, err := c.server.queue()
+	if err != nil {
+		return 0, err
+	}
+
+	action, pos := ipc.QueueActionAdd, len(queue.Songs)
+	if len(args) > 1 && args[1] != strconv.Itoa(len(queue.Songs)) {
+		if args[1] != "+0" && args[1] != strconv.Itoa(queue.Index+1) {
+			return 0, ackf(ackErrorArg, "only appending or inserting after the current song is supported")
+		}
+		action, pos = ipc.QueueActionNext, queue.Index+1
+	}
+	if err = c.call(ipc.Request{Cmd: ipc.CmdQueue, Action: action, SongIDs: []int64{id}}); err != nil {
+		return 0, err
+	}
+	return pos, nil
+}
+
+func cmdAdd(c *client, args []string, _ *response) error {
+	_, err := c.addSong(args)
+	return err
+}
+
+func cmdAddID(c *client, args []string, r *response) error {
+	pos, err := c.addSong(args)
+	if err != nil {
+		return err
+	}
+	r.kv("Id", songID(pos))
+	return nil
+}
+
+func cmdOutputs(_ *client, _ []string, r *response) error {
+	r.kv("outputid", 0)
+	r.kv("outputname", "musicfox")
+	r.kv("plugin", "musicfox")
+	r.kv("outputenabled", 1)
+	return nil
+}
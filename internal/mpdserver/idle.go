@@ -0,0 +1,164 @@
+package mpdserver
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
+)
+
+// knownSubsystems idle 可以等待的子系统，musicfox 只会产生 player、mixer、options 和 playlist
+var knownSubsystems = map[string]bool{
+	"database":        true,
+	"update":          true,
+	"stored_playlist": true,
+	"playlist":        true,
+	"player":          true,
+	"mixer":           true,
+	"output":          true,
+	"options":         true,
+	"partition":       true,
+	"sticker":         true,
+	"subscription":    true,
+	"message":         true,
+	"neighbor":        true,
+	"mount":           true,
+}
+
+// seekTolerance 播放进度与预期相差超过该值时视为跳转
+const seekTolerance = 2 * time.Second
+
+// snapshot idle 用于比较的播放器状态
+type snapshot struct {
+	state     string
+	trackID   int64
+	index     int
+	elapsed   time.Duration
+	at        time.Time
+	volume    int
+	loop      string
+	shuffle   bool
+	plVersion uint32
+}
+
+func (s *Server) status() (ipc.Status, error) {
+	data, err := s.backend.Handle(ipc.Request{Cmd: ipc.CmdStatus})
+	if err != nil {
+		return ipc.Status{}, err
+	}
+	status, ok := data.(ipc.Status)
+	if !ok {
+		return ipc.Status{}, fmt.Errorf("unexpected status type %T", data)
+	}
+	return status, nil
+}
+
+func (s *Server) queue() (ipc.QueueInfo, error) {
+	data, err := s.backend.Handle(ipc.Request{Cmd: ipc.CmdQueue, Action: ipc.QueueActionList})
+	if err != nil {
+		return ipc.QueueInfo{}, err
+	}
+	queue, ok := data.(ipc.QueueInfo)
+	if !ok {
+		return ipc.QueueInfo{}, fmt.Errorf("unexpected queue type %T", data)
+	}
+	return queue, nil
+}
+
+// playlistVersion 队列内容变化时递增的版本号，对应 status 中的 playlist
+func (s *Server) playlistVersion(queue ipc.QueueInfo) uint32 {
+	h := fnv.New64a()
+	for _, song := range queue.Songs {
+		_, _ = fmt.Fprintf(h, "%d\x00%s\x00", song.ID, song.Name)
+	}
+	sum := h.Sum64()
+
+	s.plMu.Lock()
+	defer s.plMu.Unlock()
+	if sum != s.plHash {
+		// 第一次读取队列时不算变化
+		if s.plHash != 0 {
+			s.plVersion++
+		}
+		s.plHash = sum
+	}
+	return s.plVersion
+}
+
+func (s *Server) snapshot() (snapshot, error) {
+	status, err := s.status()
+	if err != nil {
+		return snapshot{}, err
+	}
+	queue, err := s.queue()
+	if err != nil {
+		return snapshot{}, err
+	}
+	return snapshot{
+		state:     mpdState(status.State),
+		trackID:   status.TrackID,
+		index:     queue.Index,
+		elapsed:   time.Duration(status.PassedDuration * float64(time.Second)),
+		at:        time.Now(),
+		volume:    status.Volume,
+		loop:      status.LoopStatus,
+		shuffle:   status.Shuffle,
+		plVersion: s.playlistVersion(queue),
+	}, nil
+}
+
+// changes 返回从 old 到 cur 发生变化且在 subsystems 中的子系统，subsystems 为空时表示全部
+func (old snapshot) changes(cur snapshot, subsystems []string) []string {
+	want := func(name string) bool {
+		return len(subsystems) == 0 || slices.Contains(subsystems, name)
+	}
+
+	var changed []string
+	if want("playlist") && old.plVersion != cur.plVersion {
+		changed = append(changed, "playlist")
+	}
+	if want("player") && old.playerChanged(cur) {
+		changed = append(changed, "player")
+	}
+	if want("mixer") && old.volume != cur.volume {
+		changed = append(changed, "mixer")
+	}
+	if want("options") && (old.loop != cur.loop || old.shuffle != cur.shuffle) {
+		changed = append(changed, "options")
+	}
+	return changed
+}
+
+// advance 只更新已经通知过的子系统，其余子系统的变化留到之后的 idle
+func (old snapshot) advance(cur snapshot, changed []string) snapshot {
+	next := old
+	for _, name := range changed {
+		switch name {
+		case "playlist":
+			next.plVersion = cur.plVersion
+		case "player":
+			next.state, next.trackID, next.index = cur.state, cur.trackID, cur.index
+			next.elapsed, next.at = cur.elapsed, cur.at
+		case "mixer":
+			next.volume = cur.volume
+		case "options":
+			next.loop, next.shuffle = cur.loop, cur.shuffle
+		}
+	}
+	return next
+}
+
+func (old snapshot) playerChanged(cur snapshot) bool {
+	if old.state != cur.state || old.trackID != cur.trackID || old.index != cur.index {
+		return true
+	}
+	// 播放中进度自然增长，偏离预期过多说明发生了跳转
+	expected := old.elapsed
+	if old.state == "play" {
+		expected += cur.at.Sub(old.at)
+	}
+	return math.Abs(float64(cur.elapsed-expected)) > float64(seekTolerance)
+}
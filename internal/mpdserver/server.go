@@ -0,0 +1,204 @@
+// Package mpdserver 实现 MPD 协议服务端，ncmpcpp、mpc、手机上的 MPD 客户端及状态栏插件可以直接控制 musicfox。
+package mpdserver
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// protocolVersion 连接时公布的 MPD 协议版本
+const protocolVersion = "0.23.5"
+
+// maxLineSize 单条命令的最大长度
+const maxLineSize = 64 << 10
+
+// ErrPasswordRequired 监听非本机 TCP 地址但未设置密码
+var ErrPasswordRequired = errors.New("mpd server: password is required when binding to a non-loopback address")
+
+// Server MPD 协议服务，命令通过与控制套接字相同的 ipc.Handler 执行
+type Server struct {
+	network  string
+	addr     string
+	password string
+	backend  ipc.Handler
+	listener net.Listener
+	started  time.Time
+
+	// pollInterval idle 检查状态变化的间隔
+	pollInterval time.Duration
+
+	plMu      sync.Mutex
+	plHash    uint64
+	plVersion uint32
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// Listen 在 bind 上监听，以 / 或 ~/ 开头时为 Unix 套接字，否则为 TCP 地址；
+// password 为空时不校验身份，此时 TCP 只允许监听本机地址
+func Listen(bind, password string, backend ipc.Handler) (*Server, error) {
+	network, addr := parseBind(bind)
+	if network == "tcp" && password == "" && !isLoopback(addr) {
+		return nil, ErrPasswordRequired
+	}
+
+	if network == "unix" {
+		if err := removeStaleSocket(addr); err != nil {
+			return nil, err
+		}
+	}
+	listener, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", bind, err)
+	}
+	if network == "unix" {
+		// 仅允许当前用户访问
+		_ = os.Chmod(addr, 0600)
+	}
+	return newServer(network, addr, password, backend, listener), nil
+}
+
+func newServer(network, addr, password string, backend ipc.Handler, listener net.Listener) *Server {
+	return &Server{
+		network:      network,
+		addr:         addr,
+		password:     password,
+		backend:      backend,
+		listener:     listener,
+		started:      time.Now(),
+		pollInterval: 500 * time.Millisecond,
+		plVersion:    1,
+		conns:        make(map[net.Conn]struct{}),
+	}
+}
+
+func parseBind(bind string) (network, addr string) {
+	switch {
+	case strings.HasPrefix(bind, "/"):
+		return "unix", bind
+	case strings.HasPrefix(bind, "~/"):
+		home, _ := os.UserHomeDir()
+		return "unix", filepath.Join(home, bind[2:])
+	default:
+		return "tcp", bind
+	}
+}
+
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// removeStaleSocket 清理残留的套接字文件，文件仍在被监听时返回错误
+func removeStaleSocket(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("mpd server: %s is already in use", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("remove stale socket: %w", err)
+	}
+	return nil
+}
+
+// Addr 实际监听的地址
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Serve 接受连接直到 Close 被调用
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close 停止监听并断开所有连接（包括处于 idle 的连接）
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	err := s.listener.Close()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	if s.network == "unix" {
+		_ = os.Remove(s.addr)
+	}
+	return err
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+		s.wg.Done()
+	}()
+
+	c := &client{
+		server: s,
+		w:      bufio.NewWriter(conn),
+		lines:  make(chan string),
+		done:   make(chan struct{}),
+		authed: s.password == "",
+	}
+	defer close(c.done)
+	go c.readLines(conn)
+	c.snapshot, _ = s.snapshot()
+	if err := c.run(); err != nil {
+		slog.Debug("mpd server: connection closed", slogx.Error(err))
+	}
+}
@@ -0,0 +1,292 @@
+package mpdserver
+
+import (
+	"bufio"
+	"net"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
+)
+
+type fakeBackend struct {
+	mu     sync.Mutex
+	status ipc.Status
+	queue  ipc.QueueInfo
+	got    []ipc.Request
+}
+
+func (b *fakeBackend) Handle(req ipc.Request) (any, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.got = append(b.got, req)
+	switch req.Cmd {
+	case ipc.CmdStatus:
+		return b.status, nil
+	case ipc.CmdQueue:
+		return b.queue, nil
+	case ipc.CmdPause:
+		b.status.State = "paused"
+	case ipc.CmdVolume:
+		if req.Volume != nil {
+			b.status.Volume = *req.Volume
+		}
+	}
+	return nil, nil
+}
+
+func (b *fakeBackend) requests() []ipc.Request {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]ipc.Request(nil), b.got...)
+}
+
+func (b *fakeBackend) setState(state string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.status.State = state
+}
+
+func newBackend() *fakeBackend {
+	return &fakeBackend{
+		status: ipc.Status{
+			State:          "playing",
+			TrackID:        2,
+			Name:           "b",
+			Volume:         60,
+			PassedDuration: 12.5,
+			TotalDuration:  200,
+			LoopStatus:     "Playlist",
+		},
+		queue: ipc.QueueInfo{Index: 1, Songs: []ipc.QueueItem{
+			{ID: 1, Name: "a", Artist: "x", Duration: 100},
+			{ID: 2, Name: "b", Artist: "y", Album: "z", Duration: 200},
+		}},
+	}
+}
+
+type testConn struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dial(t *testing.T, password string, backend ipc.Handler) *testConn {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := newServer("tcp", listener.Addr().String(), password, backend, listener)
+	s.pollInterval = 10 * time.Millisecond
+	go func() { _ = s.Serve() }()
+	t.Cleanup(func() { _ = s.Close() })
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	c := &testConn{t: t, conn: conn, r: bufio.NewReader(conn)}
+	if greeting := c.readLine(); greeting != "OK MPD "+protocolVersion {
+		t.Fatalf("greeting = %q", greeting)
+	}
+	return c
+}
+
+func (c *testConn) readLine() string {
+	c.t.Helper()
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		c.t.Fatalf("read: %v", err)
+	}
+	return strings.TrimSuffix(line, "\n")
+}
+
+// send 发送一行命令，返回直到 OK 或 ACK 的所有输出行
+func (c *testConn) send(line string) []string {
+	c.t.Helper()
+	if _, err := c.conn.Write([]byte(line + "\n")); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+	var lines []string
+	for {
+		l := c.readLine()
+		lines = append(lines, l)
+		if l == "OK" || strings.HasPrefix(l, "ACK ") {
+			return lines
+		}
+	}
+}
+
+func TestStatusAndCurrentSong(t *testing.T) {
+	c := dial(t, "", newBackend())
+
+	got := c.send("status")
+	for _, want := range []string{"volume: 60", "repeat: 1", "single: 0", "random: 0", "state: play", "song: 1", "songid: 2",
+		"playlistlength: 2", "elapsed: 12.500", "duration: 200.000", "nextsong: 0", "nextsongid: 1"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("status missing %q: %v", want, got)
+		}
+	}
+
+	got = c.send("currentsong")
+	want := []string{"file: https://music.163.com/#/song?id=2", "Title: b", "Artist: y", "Album: z", "Time: 200", "duration: 200.000", "Pos: 1", "Id: 2", "OK"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("currentsong = %v, want %v", got, want)
+	}
+}
+
+func TestPlaylistInfo(t *testing.T) {
+	c := dial(t, "", newBackend())
+
+	if got := c.send("playlistinfo"); countPrefix(got, "file: ") != 2 {
+		t.Errorf("playlistinfo = %v", got)
+	}
+	if got := c.send("playlistinfo 1:"); countPrefix(got, "file: ") != 1 || !slices.Contains(got, "Pos: 1") {
+		t.Errorf("playlistinfo 1: = %v", got)
+	}
+	if got := c.send("playlistid 1"); !slices.Contains(got, "Title: a") {
+		t.Errorf("playlistid 1 = %v", got)
+	}
+	if got := c.send("playlistinfo 5"); !strings.HasPrefix(got[0], "ACK [2@0] {playlistinfo}") {
+		t.Errorf("playlistinfo 5 = %v", got)
+	}
+	if got := c.send("plchanges 1"); len(got) != 1 {
+		t.Errorf("plchanges with current version = %v", got)
+	}
+}
+
+func TestPlaybackCommands(t *testing.T) {
+	backend := newBackend()
+	c := dial(t, "", backend)
+
+	for _, line := range []string{"play 0", "pause 1", "pause 0", "pause", "next", "previous", "seekcur -2.5", "setvol 30", "volume +5", "random 1", "single 1"} {
+		if got := c.send(line); got[len(got)-1] != "OK" {
+			t.Fatalf("%s = %v", line, got)
+		}
+	}
+
+	var cmds []string
+	for _, req := range backend.requests() {
+		if req.Cmd != ipc.CmdStatus && req.Cmd != ipc.CmdQueue {
+			cmds = append(cmds, req.Cmd+req.Action)
+		}
+	}
+	want := []string{"play", "pause", "resume", "toggle", "next", "prev", "seek", "volume", "volume", "shuffleon", "repeatone"}
+	if !reflect.DeepEqual(cmds, want) {
+		t.Errorf("commands = %v, want %v", cmds, want)
+	}
+
+	reqs := backend.requests()
+	for _, req := range reqs {
+		if req.Cmd == ipc.CmdSeek && req.Position != 10 {
+			t.Errorf("seekcur position = %v, want 10", req.Position)
+		}
+	}
+	if got := c.send("getvol"); got[0] != "volume: 35" {
+		t.Errorf("getvol = %v", got)
+	}
+	if got := c.send("play 9"); !strings.HasPrefix(got[0], "ACK [2@0] {play}") {
+		t.Errorf("play 9 = %v", got)
+	}
+}
+
+func TestCommandList(t *testing.T) {
+	c := dial(t, "", newBackend())
+
+	_, _ = c.conn.Write([]byte("command_list_ok_begin\nping\nsetvol 10\ncommand_list_end\n"))
+	got := []string{c.readLine(), c.readLine(), c.readLine()}
+	if want := []string{"list_OK", "list_OK", "OK"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("command list = %v, want %v", got, want)
+	}
+
+	_, _ = c.conn.Write([]byte("command_list_begin\nping\nfoo\nping\ncommand_list_end\n"))
+	if got := c.readLine(); got != `ACK [5@1] {foo} unknown command "foo"` {
+		t.Errorf("failed command list = %q", got)
+	}
+}
+
+func TestPassword(t *testing.T) {
+	c := dial(t, "secret", newBackend())
+
+	if got := c.send("status"); !strings.HasPrefix(got[0], "ACK [4@0] {status}") {
+		t.Errorf("status without password = %v", got)
+	}
+	if got := c.send("password wrong"); !strings.HasPrefix(got[0], "ACK [3@0] {password}") {
+		t.Errorf("wrong password = %v", got)
+	}
+	if got := c.send("password secret"); got[0] != "OK" {
+		t.Errorf("password = %v", got)
+	}
+	if got := c.send("status"); got[len(got)-1] != "OK" {
+		t.Errorf("status with password = %v", got)
+	}
+}
+
+func TestListenRequiresPassword(t *testing.T) {
+	if _, err := Listen("0.0.0.0:0", "", newBackend()); err != ErrPasswordRequired {
+		t.Errorf("Listen on 0.0.0.0 without password: %v", err)
+	}
+}
+
+func TestIdle(t *testing.T) {
+	backend := newBackend()
+	c := dial(t, "", backend)
+
+	_, _ = c.conn.Write([]byte("idle player\n"))
+	time.Sleep(30 * time.Millisecond)
+	backend.setState("paused")
+	if got := []string{c.readLine(), c.readLine()}; !reflect.DeepEqual(got, []string{"changed: player", "OK"}) {
+		t.Errorf("idle = %v", got)
+	}
+
+	// 与 player 无关的变化不会唤醒 idle player，noidle 立即返回
+	_ = c.send("setvol 10")
+	_, _ = c.conn.Write([]byte("idle player\n"))
+	time.Sleep(30 * time.Millisecond)
+	if got := c.send("noidle"); !reflect.DeepEqual(got, []string{"OK"}) {
+		t.Errorf("noidle = %v", got)
+	}
+
+	// 之前的音量变化在下一次 idle 时立即返回
+	if got := c.send("idle"); !reflect.DeepEqual(got, []string{"changed: mixer", "OK"}) {
+		t.Errorf("idle mixer = %v", got)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+		err  bool
+	}{
+		{line: "play", want: []string{"play"}},
+		{line: "  seek 1  20.5 ", want: []string{"seek", "1", "20.5"}},
+		{line: `add "netease:1 2" x`, want: []string{"add", "netease:1 2", "x"}},
+		{line: `find "a \"b\" \\c"`, want: []string{"find", `a "b" \c`}},
+		{line: `add "abc`, err: true},
+		{line: `add "a"b`, err: true},
+	}
+	for _, tt := range tests {
+		got, err := splitArgs(tt.line)
+		if (err != nil) != tt.err || (!tt.err && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("splitArgs(%q) = %v, %v", tt.line, got, err)
+		}
+	}
+}
+
+func countPrefix(lines []string, prefix string) int {
+	n := 0
+	for _, l := range lines {
+		if strings.HasPrefix(l, prefix) {
+			n++
+		}
+	}
+	return n
+}
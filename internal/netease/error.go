@@ -2,6 +2,8 @@ package netease
 
 import (
 	"fmt"
+
+	_struct "github.com/go-musicfox/go-musicfox/utils/struct"
 )
 
 type Error struct {
@@ -13,4 +15,20 @@ func (e Error) Error() string {
 	return fmt.Sprintf("code: %d, msg: %s", e.CodeType, e.Msg)
 }
 
-var NetworkErr = Error{CodeType: -1, Msg: "网络错误"}
+var (
+	NetworkErr   = Error{CodeType: -1, Msg: "网络错误"}
+	NeedLoginErr = Error{CodeType: int(_struct.NeedLogin), Msg: "需要登录"}
+	TooManyErr   = Error{CodeType: int(_struct.TooManyRequests), Msg: "请求过于频繁"}
+)
+
+// codeErr 将需要区分处理的响应码转为对应错误，其余视为网络错误
+func codeErr(codeType _struct.ResCode) error {
+	switch codeType {
+	case _struct.NeedLogin:
+		return NeedLoginErr
+	case _struct.TooManyRequests:
+		return TooManyErr
+	default:
+		return NetworkErr
+	}
+}
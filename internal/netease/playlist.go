@@ -73,3 +73,43 @@ Loop:
 	}
 	return
 }
+
+// CreatePlaylist 新建歌单，返回歌单 ID
+func CreatePlaylist(name string, private bool) (playlistId int64, err error) {
+	createService := service.PlaylistCreateService{Name: name}
+	if private {
+		createService.Privacy = "10"
+	}
+	code, response := createService.PlaylistCreate()
+	if codeType := _struct.CheckCode(code); codeType != _struct.Success {
+		err = codeErr(codeType)
+		return
+	}
+	if playlistId, err = jsonparser.GetInt(response, "id"); err == nil {
+		return
+	}
+	if playlistId, err = jsonparser.GetInt(response, "playlist", "id"); err != nil {
+		err = Error{Msg: "新建歌单失败:" + name}
+	}
+	return
+}
+
+// addTracksBatchSize 每次请求添加的歌曲数，过多时接口会失败
+const addTracksBatchSize = 200
+
+// AddSongsToPlaylist 按顺序将歌曲分批加入歌单
+func AddSongsToPlaylist(playlistId int64, songIds []int64) error {
+	for start := 0; start < len(songIds); start += addTracksBatchSize {
+		end := min(start+addTracksBatchSize, len(songIds))
+		ids := make([]string, 0, end-start)
+		for _, id := range songIds[start:end] {
+			ids = append(ids, strconv.FormatInt(id, 10))
+		}
+		addService := service.PlaylistTrackAddService{Id: strconv.FormatInt(playlistId, 10), SongIds: ids}
+		code, _ := addService.AddTracks()
+		if codeType := _struct.CheckCode(code); codeType != _struct.Success {
+			return codeErr(codeType)
+		}
+	}
+	return nil
+}
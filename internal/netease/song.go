@@ -2,6 +2,7 @@ package netease
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/go-musicfox/netease-music/service"
 
@@ -61,3 +62,38 @@ func FetchLikeSongs(userId int64, getAll bool) (playlist []structs.Song, err err
 	playlist = songs
 	return
 }
+
+// FetchSongsByIds 通过歌曲 ID 获取歌曲详情，返回顺序与接口一致
+func FetchSongsByIds(ids []int64) (songs []structs.Song, err error) {
+	if len(ids) == 0 {
+		return
+	}
+	idStrs := make([]string, len(ids))
+	for i, id := range ids {
+		idStrs[i] = strconv.FormatInt(id, 10)
+	}
+	songDetail := service.SongDetailService{Ids: strings.Join(idStrs, ",")}
+	code, response := songDetail.SongDetail()
+	if _struct.CheckCode(code) != _struct.Success {
+		err = NetworkErr
+		return
+	}
+	songs = _struct.GetSongsOfSongDetail(response)
+	return
+}
+
+// SearchSongs 按关键词搜索单曲
+func SearchSongs(keyword string, limit int) (songs []structs.Song, err error) {
+	searchService := service.SearchService{
+		S:     keyword,
+		Type:  "1",
+		Limit: strconv.Itoa(limit),
+	}
+	code, response := searchService.Search()
+	if codeType := _struct.CheckCode(code); codeType != _struct.Success {
+		err = codeErr(codeType)
+		return
+	}
+	songs = _struct.GetSongsOfSearchResult(response)
+	return
+}
@@ -0,0 +1,143 @@
+package player
+
+import (
+	"io"
+	"time"
+
+	"github.com/gopxl/beep"
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/codec"
+)
+
+// aacStreamer 逐包解码 MP4/ADTS 中的 AAC
+//
+// 文件仍在下载时读不到完整的包，Stream 返回 ok=false 且 Err() 为 nil，
+// 由播放器等待数据后重试；包序号只在读取成功后前进，重试不会丢包。
+type aacStreamer struct {
+	src    codec.AACSource
+	dec    *codec.AACDecoder
+	closer io.Closer
+	rate   int
+
+	length  int  // 总采样数，单位为输出采样率
+	trim    bool // length 来自容器时按其截断末尾的填充
+	packet  int
+	buf     []float32 // 已解码未输出的交错立体声
+	discard int       // 待丢弃的采样数（编码器延迟或跳转预滚）
+	pos     int
+	err     error
+}
+
+func decodeAAC(r io.ReadSeekCloser, format codec.Format, duration time.Duration, finalized bool) (beep.StreamSeekCloser, beep.Format, error) {
+	var (
+		src codec.AACSource
+		err error
+	)
+	if format == codec.FormatADTS {
+		src, err = codec.OpenADTS(r)
+	} else {
+		src, err = codec.OpenMP4(r, finalized)
+	}
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	dec, err := codec.NewAACDecoder(src.Config())
+	if err != nil {
+		return nil, beep.Format{}, errors.Wrap(err, "aac decoder (faad2) unavailable")
+	}
+	s := &aacStreamer{src: src, dec: dec, closer: r, rate: dec.SampleRate()}
+	// ADTS 需要扫描整个文件才知道时长，下载中只能按歌曲信息估算
+	if _, isADTS := src.(*codec.ADTS); !isADTS || finalized {
+		if d := src.Duration(); d > 0 {
+			s.length, s.trim = s.frames(d), true
+		}
+	}
+	if s.length == 0 {
+		s.length = int(duration.Seconds() * float64(s.rate))
+	}
+	s.discard = s.frames(src.Skip())
+	return s, beep.Format{SampleRate: beep.SampleRate(s.rate), NumChannels: 2, Precision: 2}, nil
+}
+
+// frames 把容器时间换算为输出采样数，HE-AAC 的输出采样率可能是 timescale 的两倍
+func (s *aacStreamer) frames(t int64) int {
+	return int(t * int64(s.rate) / int64(s.src.Timescale()))
+}
+
+func (s *aacStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	for n < len(samples) {
+		if s.trim && s.pos >= s.length {
+			return n, n > 0
+		}
+		if len(s.buf) == 0 {
+			packet, err := s.src.Packet(s.packet)
+			if err != nil {
+				if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+					s.err = err
+				}
+				return n, n > 0
+			}
+			s.packet++
+			// 单个包损坏时跳过，不中断播放
+			if s.buf, err = s.dec.Decode(packet); err != nil {
+				continue
+			}
+			d := min(s.discard, len(s.buf)/2)
+			s.buf, s.discard = s.buf[2*d:], s.discard-d
+			continue
+		}
+		k := min(len(samples)-n, len(s.buf)/2)
+		if s.trim {
+			k = min(k, s.length-s.pos)
+		}
+		copyInterleaved(samples[n:n+k], s.buf)
+		s.buf = s.buf[2*k:]
+		s.pos += k
+		n += k
+	}
+	return n, true
+}
+
+func (s *aacStreamer) Err() error {
+	return s.err
+}
+
+func (s *aacStreamer) ResetError() {
+	s.err = nil
+}
+
+func (s *aacStreamer) Len() int {
+	return s.length
+}
+
+func (s *aacStreamer) Position() int {
+	return s.pos
+}
+
+// Seek 从目标的前一个包开始解码，丢弃预滚部分，避免解码器冷启动的失真
+func (s *aacStreamer) Seek(p int) error {
+	if p < 0 || (s.length > 0 && p > s.length) {
+		return errors.Errorf("seek position %d out of range [0, %d]", p, s.length)
+	}
+	t := int64(p)*int64(s.src.Timescale())/int64(s.rate) + s.src.Skip()
+	i := max(s.src.PacketIndex(t)-1, 0)
+	s.dec.Reset()
+	s.packet, s.buf = i, nil
+	s.discard = s.frames(t - s.src.PacketStart(i))
+	s.pos = p
+	return nil
+}
+
+func (s *aacStreamer) Close() error {
+	s.dec.Close()
+	return s.closer.Close()
+}
+
+// copyInterleaved 把交错立体声 float32 写入 beep 的采样缓冲
+func copyInterleaved(dst [][2]float64, src []float32) {
+	for i := range dst {
+		dst[i][0] = float64(src[2*i])
+		dst[i][1] = float64(src[2*i+1])
+	}
+}
@@ -0,0 +1,91 @@
+package player
+
+import (
+	"math"
+	"time"
+
+	"github.com/gopxl/beep"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+// crossfader 歌曲间交叉淡入淡出的配置
+type crossfader struct {
+	duration time.Duration
+	curve    string
+}
+
+// newCrossfader 未开启淡入淡出时返回 nil
+func newCrossfader(cfg configs.BeepConfig) *crossfader {
+	if cfg.CrossfadeSeconds <= 0 {
+		return nil
+	}
+	curve := cfg.CrossfadeCurve
+	if curve != types.CrossfadeLinear {
+		curve = types.CrossfadeEqualPower
+	}
+	return &crossfader{
+		duration: time.Duration(cfg.CrossfadeSeconds) * time.Second,
+		curve:    curve,
+	}
+}
+
+// allowed 同一专辑的连续歌曲通常本身就是连贯的，此时不做淡入淡出而是无缝衔接
+func (c *crossfader) allowed(from, to URLMusic) bool {
+	if from.Id == to.Id {
+		return false
+	}
+	if from.Album.Id != 0 || to.Album.Id != 0 {
+		return from.Album.Id != to.Album.Id
+	}
+	return from.Album.Name == "" || from.Album.Name != to.Album.Name
+}
+
+// envelope 返回进度 t（0~1）处淡入、淡出两侧的增益
+func (c *crossfader) envelope(t float64) (in, out float64) {
+	t = min(max(t, 0), 1)
+	if c.curve == types.CrossfadeLinear {
+		return t, 1 - t
+	}
+	// 等功率曲线：两侧能量之和保持不变，避免中段音量下陷
+	return math.Sin(t * math.Pi / 2), math.Cos(t * math.Pi / 2)
+}
+
+// activeFade 正在淡出的上一首歌
+type activeFade struct {
+	curve   *crossfader
+	out     beep.Streamer
+	pos     int
+	length  int
+	release func()
+	buf     [][2]float64
+}
+
+// mix 将上一首的剩余部分按包络叠加到 samples（下一首）上，返回淡出是否已结束
+func (f *activeFade) mix(samples [][2]float64) bool {
+	if len(f.buf) < len(samples) {
+		f.buf = make([][2]float64, len(samples))
+	}
+	buf := f.buf[:len(samples)]
+	n, ok := f.out.Stream(buf)
+	for i := range samples {
+		in, out := f.curve.envelope(float64(f.pos+i) / float64(f.length))
+		samples[i][0] *= in
+		samples[i][1] *= in
+		if i < n {
+			samples[i][0] += buf[i][0] * out
+			samples[i][1] += buf[i][1] * out
+		}
+	}
+	f.pos += len(samples)
+	return !ok || n < len(samples) || f.pos >= f.length
+}
+
+// close 释放上一首的解码器和缓存文件
+func (f *activeFade) close() {
+	if f.release != nil {
+		f.release()
+		f.release = nil
+	}
+}
@@ -0,0 +1,85 @@
+package player
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+func TestCrossfadeEnvelope(t *testing.T) {
+	equalPower := newCrossfader(configs.BeepConfig{CrossfadeSeconds: 5, CrossfadeCurve: "unknown"})
+	if equalPower.curve != types.CrossfadeEqualPower {
+		t.Fatalf("curve = %q, want equal-power fallback", equalPower.curve)
+	}
+	for _, at := range []float64{0, 0.25, 0.5, 0.9, 1} {
+		in, out := equalPower.envelope(at)
+		if math.Abs(in*in+out*out-1) > 1e-9 {
+			t.Fatalf("equal-power at %.2f: in=%f out=%f, power not constant", at, in, out)
+		}
+	}
+
+	linear := newCrossfader(configs.BeepConfig{CrossfadeSeconds: 5, CrossfadeCurve: types.CrossfadeLinear})
+	if in, out := linear.envelope(0.25); in != 0.25 || out != 0.75 {
+		t.Fatalf("linear at 0.25: in=%f out=%f", in, out)
+	}
+	if newCrossfader(configs.BeepConfig{}) != nil {
+		t.Fatal("crossfade should be disabled with zero seconds")
+	}
+}
+
+func TestCrossfadeSkipsSameAlbum(t *testing.T) {
+	c := &crossfader{curve: types.CrossfadeLinear}
+	song := func(id, albumID int64, album string) URLMusic {
+		return URLMusic{Song: structs.Song{Id: id, Album: structs.Album{Id: albumID, Name: album}}}
+	}
+	tests := []struct {
+		from, to URLMusic
+		want     bool
+	}{
+		{song(1, 10, "A"), song(2, 10, "A"), false},
+		{song(1, 10, "A"), song(2, 11, "A"), true},
+		{song(1, 0, "Local"), song(2, 0, "Local"), false},
+		{song(1, 0, ""), song(2, 0, ""), true},
+		{song(1, 10, "A"), song(1, 11, "B"), false},
+	}
+	for i, tt := range tests {
+		if got := c.allowed(tt.from, tt.to); got != tt.want {
+			t.Fatalf("case %d: allowed = %t, want %t", i, got, tt.want)
+		}
+	}
+}
+
+func TestActiveFadeMix(t *testing.T) {
+	out := &sampleStreamer{samples: [][2]float64{{1, 1}, {1, 1}, {1, 1}, {1, 1}}}
+	released := false
+	fade := &activeFade{
+		curve:   &crossfader{curve: types.CrossfadeLinear},
+		out:     out,
+		length:  4,
+		release: func() { released = true },
+	}
+
+	next := [][2]float64{{1, 1}, {1, 1}}
+	if fade.mix(next) {
+		t.Fatal("fade finished too early")
+	}
+	// 线性曲线下两侧增益之和为 1
+	if next[0][0] != 1 || next[1][0] != 1 {
+		t.Fatalf("first chunk = %v, want constant sum", next)
+	}
+	next = [][2]float64{{0, 0}, {0, 0}}
+	if !fade.mix(next) {
+		t.Fatal("fade should finish after its length")
+	}
+	if next[0][0] != 0.5 || next[1][0] != 0.25 {
+		t.Fatalf("second chunk = %v, want the tail fading out", next)
+	}
+	fade.close()
+	fade.close()
+	if !released {
+		t.Fatal("release not called")
+	}
+}
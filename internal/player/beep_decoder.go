@@ -16,6 +16,7 @@ import (
 	"github.com/pkg/errors"
 	minimp3pkg "github.com/tosone/minimp3"
 
+	"github.com/go-musicfox/go-musicfox/internal/codec"
 	"github.com/go-musicfox/go-musicfox/internal/configs"
 	"github.com/go-musicfox/go-musicfox/internal/types"
 )
@@ -26,7 +27,34 @@ func DecodeSong(t SongType, r io.ReadSeekCloser) (streamer beep.StreamSeekCloser
 	return decodeSong(t, r, 0, false)
 }
 
+// sniffSongType 根据文件头识别歌曲类型，无法识别时沿用 fallback（通常来自扩展名）
+func sniffSongType(r io.ReadSeeker, fallback SongType) (SongType, codec.Format) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return fallback, codec.FormatUnknown
+	}
+	header := make([]byte, codec.SniffSize)
+	n, _ := io.ReadFull(r, header)
+	_, _ = r.Seek(0, io.SeekStart)
+	format := codec.Sniff(header[:n])
+	switch format {
+	case codec.FormatMP3:
+		return Mp3, format
+	case codec.FormatWAV:
+		return Wav, format
+	case codec.FormatOggVorbis:
+		return Ogg, format
+	case codec.FormatFLAC:
+		return Flac, format
+	case codec.FormatMP4, codec.FormatADTS:
+		return Aac, format
+	case codec.FormatOggOpus:
+		return Opus, format
+	}
+	return fallback, format
+}
+
 func decodeSong(t SongType, r io.ReadSeekCloser, duration time.Duration, finalized bool) (streamer beep.StreamSeekCloser, format beep.Format, err error) {
+	t, container := sniffSongType(r, t)
 	switch t {
 	case Mp3:
 		gaplessDelay, gaplessPadding, hasGaplessPadding := mp3GaplessSamples(r)
@@ -53,6 +81,10 @@ func decodeSong(t SongType, r io.ReadSeekCloser, duration time.Duration, finaliz
 		streamer, format, err = vorbis.Decode(r)
 	case Flac:
 		streamer, format, err = flac.Decode(r)
+	case Aac:
+		streamer, format, err = decodeAAC(r, container, duration, finalized)
+	case Opus:
+		streamer, format, err = decodeOpus(r, duration, finalized)
 	default:
 		err = errors.Errorf("Unknown song type(%d)", t)
 	}
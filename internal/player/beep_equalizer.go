@@ -0,0 +1,155 @@
+package player
+
+import (
+	"math"
+	"sync"
+
+	"github.com/gopxl/beep"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+)
+
+const (
+	equalizerLowShelfFrequency  = 80.0
+	equalizerHighShelfFrequency = 12000.0
+	equalizerShelfQ             = math.Sqrt2 / 2
+	equalizerPeakQ              = math.Sqrt2 // 约 1 个倍频程带宽
+	equalizerFilterCount        = configs.EqualizerBandCount + 2
+)
+
+// equalizer 参数均衡器：低架 + 10 段峰值 + 高架，系数按 RBJ Audio EQ Cookbook 计算
+type equalizer struct {
+	mu      sync.Mutex
+	enabled bool
+	preset  configs.EqualizerPreset
+
+	rate    beep.SampleRate
+	dirty   bool
+	preamp  float64
+	filters [2][equalizerFilterCount]biquad // [声道][滤波器]
+	active  [equalizerFilterCount]bool      // 增益为 0 或超出奈奎斯特频率的滤波器跳过
+}
+
+func newEqualizer(enabled bool, preset configs.EqualizerPreset) *equalizer {
+	return &equalizer{enabled: enabled, preset: preset.Normalize(), dirty: true}
+}
+
+// set 实时修改设置，下一次处理时重新计算系数，一直生效的滤波器保留状态以避免爆音
+func (e *equalizer) set(enabled bool, preset configs.EqualizerPreset) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if enabled && !e.enabled {
+		// 关闭期间滤波器未处理音频，重新开启时视为全部重新生效
+		e.active = [equalizerFilterCount]bool{}
+	}
+	e.enabled = enabled
+	e.preset = preset.Normalize()
+	e.dirty = true
+}
+
+func (e *equalizer) settings() (bool, configs.EqualizerPreset) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.enabled, e.preset
+}
+
+// process 就地处理一段采样率为 rate 的音频
+func (e *equalizer) process(samples [][2]float64, rate beep.SampleRate) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.enabled || rate <= 0 || len(samples) == 0 {
+		return
+	}
+	if e.dirty || e.rate != rate {
+		if e.rate != rate {
+			e.filters = [2][equalizerFilterCount]biquad{}
+		}
+		e.rate = rate
+		e.rebuild()
+		e.dirty = false
+	}
+
+	for i := range samples {
+		for ch := range 2 {
+			x := samples[i][ch] * e.preamp
+			for f := range equalizerFilterCount {
+				if e.active[f] {
+					x = e.filters[ch][f].process(x)
+				}
+			}
+			samples[i][ch] = x
+		}
+	}
+}
+
+// rebuild 按当前预设和采样率重新计算各滤波器系数
+func (e *equalizer) rebuild() {
+	fs := float64(e.rate)
+	e.preamp = math.Pow(10, e.preset.Preamp/20)
+
+	set := func(index int, gain, freq float64, coefficients func(gain, freq, fs float64) biquad) {
+		wasActive := e.active[index]
+		e.active[index] = gain != 0 && freq < fs*0.45
+		if !e.active[index] {
+			return
+		}
+		c := coefficients(gain, freq, fs)
+		for ch := range 2 {
+			// 一直生效的滤波器沿用状态避免爆音；重新生效时旧状态已过期，从零开始
+			if wasActive {
+				c.z1, c.z2 = e.filters[ch][index].z1, e.filters[ch][index].z2
+			}
+			e.filters[ch][index] = c
+		}
+	}
+	set(0, e.preset.LowShelf, equalizerLowShelfFrequency, lowShelfFilter)
+	for i, freq := range configs.EqualizerBandFrequencies {
+		set(i+1, e.preset.Bands[i], freq, peakingFilter)
+	}
+	set(equalizerFilterCount-1, e.preset.HighShelf, equalizerHighShelfFrequency, highShelfFilter)
+}
+
+func peakingFilter(gain, freq, fs float64) biquad {
+	a := math.Pow(10, gain/40)
+	w0 := 2 * math.Pi * freq / fs
+	alpha := math.Sin(w0) / (2 * equalizerPeakQ)
+	cos := math.Cos(w0)
+	a0 := 1 + alpha/a
+	return biquad{
+		b0: (1 + alpha*a) / a0,
+		b1: -2 * cos / a0,
+		b2: (1 - alpha*a) / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha/a) / a0,
+	}
+}
+
+func lowShelfFilter(gain, freq, fs float64) biquad {
+	a := math.Pow(10, gain/40)
+	w0 := 2 * math.Pi * freq / fs
+	cos := math.Cos(w0)
+	beta := 2 * math.Sqrt(a) * math.Sin(w0) / (2 * equalizerShelfQ)
+	a0 := (a + 1) + (a-1)*cos + beta
+	return biquad{
+		b0: a * ((a + 1) - (a-1)*cos + beta) / a0,
+		b1: 2 * a * ((a - 1) - (a+1)*cos) / a0,
+		b2: a * ((a + 1) - (a-1)*cos - beta) / a0,
+		a1: -2 * ((a - 1) + (a+1)*cos) / a0,
+		a2: ((a + 1) + (a-1)*cos - beta) / a0,
+	}
+}
+
+func highShelfFilter(gain, freq, fs float64) biquad {
+	a := math.Pow(10, gain/40)
+	w0 := 2 * math.Pi * freq / fs
+	cos := math.Cos(w0)
+	beta := 2 * math.Sqrt(a) * math.Sin(w0) / (2 * equalizerShelfQ)
+	a0 := (a + 1) - (a-1)*cos + beta
+	return biquad{
+		b0: a * ((a + 1) + (a-1)*cos + beta) / a0,
+		b1: -2 * a * ((a - 1) + (a+1)*cos) / a0,
+		b2: a * ((a + 1) + (a-1)*cos - beta) / a0,
+		a1: 2 * ((a - 1) - (a+1)*cos) / a0,
+		a2: ((a + 1) - (a-1)*cos - beta) / a0,
+	}
+}
@@ -0,0 +1,91 @@
+package player
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gopxl/beep"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+)
+
+func rms(samples [][2]float64) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += s[0] * s[0]
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func TestEqualizerBoostsBand(t *testing.T) {
+	const rate = beep.SampleRate(44100)
+	bands := make([]float64, configs.EqualizerBandCount)
+	bands[5] = 6 // 1kHz
+	eq := newEqualizer(true, configs.EqualizerPreset{Name: "test", Bands: bands})
+
+	tone := sineSamples(rate, 1000, 0.1, 1)
+	eq.process(tone, rate)
+	// 跳过滤波器的起振阶段
+	if gain := 20 * math.Log10(rms(tone[rate/2:])/(0.1/math.Sqrt2)); math.Abs(gain-6) > 0.2 {
+		t.Fatalf("1kHz gain = %.2f dB, want 6", gain)
+	}
+
+	low := sineSamples(rate, 62, 0.1, 1)
+	eq.process(low, rate)
+	if gain := 20 * math.Log10(rms(low[rate/2:])/(0.1/math.Sqrt2)); math.Abs(gain) > 0.5 {
+		t.Fatalf("62Hz gain = %.2f dB, want about 0", gain)
+	}
+}
+
+func TestEqualizerDisabledAndPreamp(t *testing.T) {
+	const rate = beep.SampleRate(48000)
+	preset := configs.EqualizerPreset{Name: "loud", Preamp: -6, LowShelf: 12, HighShelf: 12}
+	eq := newEqualizer(false, preset)
+	samples := sineSamples(rate, 440, 0.5, 1)
+	eq.process(samples, rate)
+	if samples[100] != sineSamples(rate, 440, 0.5, 1)[100] {
+		t.Fatal("disabled equalizer changed the signal")
+	}
+
+	eq.set(true, configs.EqualizerPreset{Name: "quiet", Preamp: -6})
+	eq.process(samples, rate)
+	if got := rms(samples) / (0.5 / math.Sqrt2); math.Abs(got-math.Pow(10, -6.0/20)) > 1e-3 {
+		t.Fatalf("preamp ratio = %f, want -6 dB", got)
+	}
+	if enabled, current := eq.settings(); !enabled || current.Name != "quiet" || len(current.Bands) != configs.EqualizerBandCount {
+		t.Fatalf("settings = %t %+v", enabled, current)
+	}
+}
+
+func TestEqualizerResetsReactivatedFilter(t *testing.T) {
+	const rate = beep.SampleRate(44100)
+	bands := make([]float64, configs.EqualizerBandCount)
+	bands[5] = 6
+	boost := configs.EqualizerPreset{Name: "test", Bands: bands}
+	eq := newEqualizer(true, boost)
+	eq.process(sineSamples(rate, 1000, 0.5, 1), rate)
+
+	// 频段关闭后再打开，静音输入不应带出旧的滤波器状态
+	eq.set(true, configs.EqualizerPreset{Name: "flat", Bands: make([]float64, configs.EqualizerBandCount)})
+	eq.process(make([][2]float64, 16), rate)
+	eq.set(true, boost)
+	silence := make([][2]float64, 16)
+	eq.process(silence, rate)
+	for i, s := range silence {
+		if s != [2]float64{} {
+			t.Fatalf("sample %d = %v after band reactivated, want silence", i, s)
+		}
+	}
+
+	// 关闭均衡器再开启同理
+	eq.process(sineSamples(rate, 1000, 0.5, 1), rate)
+	eq.set(false, boost)
+	eq.set(true, boost)
+	silence = make([][2]float64, 16)
+	eq.process(silence, rate)
+	for i, s := range silence {
+		if s != [2]float64{} {
+			t.Fatalf("sample %d = %v after re-enable, want silence", i, s)
+		}
+	}
+}
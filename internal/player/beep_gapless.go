@@ -11,6 +11,7 @@ import (
 	"sync/atomic"
 
 	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/effects"
 
 	"github.com/go-musicfox/go-musicfox/utils/app"
 	"github.com/go-musicfox/go-musicfox/utils/iox"
@@ -20,6 +21,7 @@ type preparedGapless struct {
 	fromID int64
 	music  URLMusic
 	raw    beep.StreamSeekCloser
+	gain   *effects.Gain
 	stream beep.Streamer
 	format beep.Format
 	file   *os.File
@@ -112,7 +114,7 @@ func (g *gaplessState) cancel() {
 	g.mu.Unlock()
 }
 
-func (g *gaplessState) preload(fromID int64, music URLMusic, outputRate beep.SampleRate, client *http.Client, closed <-chan struct{}) {
+func (g *gaplessState) preload(fromID int64, music URLMusic, outputRate beep.SampleRate, client *http.Client, loudness *loudnessAnalyzer, closed <-chan struct{}) {
 	id := g.generation.Add(1)
 	ctx, cancel := context.WithCancel(context.Background())
 	g.mu.Lock()
@@ -128,7 +130,7 @@ func (g *gaplessState) preload(fromID int64, music URLMusic, outputRate beep.Sam
 	g.mu.Unlock()
 
 	go func() {
-		prepared := prepareGapless(ctx, fromID, music, outputRate, client, closed)
+		prepared := prepareGapless(ctx, fromID, music, outputRate, client, loudness, closed)
 		g.mu.Lock()
 		defer g.mu.Unlock()
 		if id != g.generation.Load() || g.preloading != music.Id {
@@ -143,18 +145,22 @@ func (g *gaplessState) preload(fromID int64, music URLMusic, outputRate beep.Sam
 	}()
 }
 
-func (g *gaplessState) takeIfReady(currentID int64) *preparedGapless {
+// takeIf 仅当预加载完成且 accept 返回 true 时取出，否则保留给后续使用
+func (g *gaplessState) takeIf(currentID int64, accept func(*preparedGapless) bool) *preparedGapless {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	if g.preloaded == nil || g.preloaded.fromID != currentID {
 		return nil
 	}
+	if accept != nil && !accept(g.preloaded) {
+		return nil
+	}
 	prepared := g.preloaded
 	g.preloaded = nil
 	return prepared
 }
 
-func prepareGapless(ctx context.Context, fromID int64, music URLMusic, outputRate beep.SampleRate, client *http.Client, closed <-chan struct{}) *preparedGapless {
+func prepareGapless(ctx context.Context, fromID int64, music URLMusic, outputRate beep.SampleRate, client *http.Client, loudness *loudnessAnalyzer, closed <-chan struct{}) *preparedGapless {
 	select {
 	case <-closed:
 		return nil
@@ -212,10 +218,19 @@ func prepareGapless(ctx context.Context, fromID int64, music URLMusic, outputRat
 		_ = os.Remove(file.Name())
 		return nil
 	}
-	var stream beep.Streamer = raw
+	var (
+		stream beep.Streamer = raw
+		gain   *effects.Gain
+	)
+	if loudness != nil {
+		// 预加载时文件已完整，直接算好增益，保证切换瞬间即为正确音量
+		multiplier, _ := loudness.analyze(ctx, music, file.Name())
+		gain = &effects.Gain{Streamer: raw, Gain: multiplier - 1}
+		stream = gain
+	}
 	if format.SampleRate != outputRate {
-		stream = beep.Resample(resampleQuiality, format.SampleRate, outputRate, raw)
+		stream = beep.Resample(resampleQuiality, format.SampleRate, outputRate, stream)
 	}
 	slog.Info("gapless preload ready", "song_id", music.Id, "bytes", bytes, "sample_rate", format.SampleRate, "samples", raw.Len())
-	return &preparedGapless{fromID: fromID, music: music, raw: raw, stream: stream, format: format, file: file}
+	return &preparedGapless{fromID: fromID, music: music, raw: raw, gain: gain, stream: stream, format: format, file: file}
 }
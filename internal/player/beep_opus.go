@@ -0,0 +1,136 @@
+package player
+
+import (
+	"io"
+	"math"
+	"time"
+
+	"github.com/gopxl/beep"
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/codec"
+)
+
+// opusPreroll 跳转时提前解码的采样数，Opus 建议至少 80ms 以收敛解码器状态
+const opusPreroll = 48000 * 80 / 1000
+
+// opusStreamer 逐包解码 Ogg Opus，输出固定为 48kHz 立体声
+//
+// 与 aacStreamer 相同，下载中数据不足时返回 ok=false 且 Err() 为 nil。
+type opusStreamer struct {
+	ogg    *codec.OggOpus
+	dec    *codec.OpusDecoder
+	closer io.Closer
+	gain   float32 // OpusHead 中的输出增益
+
+	length  int
+	trim    bool
+	buf     []float32
+	discard int
+	pos     int
+	err     error
+}
+
+func decodeOpus(r io.ReadSeekCloser, duration time.Duration, finalized bool) (beep.StreamSeekCloser, beep.Format, error) {
+	ogg, err := codec.OpenOggOpus(r)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	dec, err := codec.NewOpusDecoder()
+	if err != nil {
+		return nil, beep.Format{}, errors.Wrap(err, "opus decoder (libopus) unavailable")
+	}
+	s := &opusStreamer{
+		ogg:     ogg,
+		dec:     dec,
+		closer:  r,
+		gain:    float32(math.Pow(10, float64(ogg.Gain)/(20*256))),
+		discard: ogg.PreSkip,
+	}
+	if finalized {
+		if d, err := codec.OpusDuration(r); err == nil && d > 0 {
+			s.length, s.trim = int(d), true
+		}
+	}
+	if s.length == 0 {
+		s.length = int(duration.Seconds() * float64(dec.SampleRate()))
+	}
+	return s, beep.Format{SampleRate: beep.SampleRate(dec.SampleRate()), NumChannels: 2, Precision: 2}, nil
+}
+
+func (s *opusStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	for n < len(samples) {
+		if s.trim && s.pos >= s.length {
+			return n, n > 0
+		}
+		if len(s.buf) == 0 {
+			packet, err := s.ogg.Packet()
+			if err != nil {
+				if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+					s.err = err
+				}
+				return n, n > 0
+			}
+			if s.buf, err = s.dec.Decode(packet); err != nil {
+				continue
+			}
+			d := min(s.discard, len(s.buf)/2)
+			s.buf, s.discard = s.buf[2*d:], s.discard-d
+			continue
+		}
+		k := min(len(samples)-n, len(s.buf)/2)
+		if s.trim {
+			k = min(k, s.length-s.pos)
+		}
+		if s.gain != 1 {
+			for i := range 2 * k {
+				s.buf[i] *= s.gain
+			}
+		}
+		copyInterleaved(samples[n:n+k], s.buf)
+		s.buf = s.buf[2*k:]
+		s.pos += k
+		n += k
+	}
+	return n, true
+}
+
+func (s *opusStreamer) Err() error {
+	return s.err
+}
+
+func (s *opusStreamer) ResetError() {
+	s.err = nil
+}
+
+func (s *opusStreamer) Len() int {
+	return s.length
+}
+
+func (s *opusStreamer) Position() int {
+	return s.pos
+}
+
+func (s *opusStreamer) Seek(p int) error {
+	if p < 0 || (s.length > 0 && p > s.length) {
+		return errors.Errorf("seek position %d out of range [0, %d]", p, s.length)
+	}
+	// 粒度位置包含 PreSkip
+	target := int64(p + s.ogg.PreSkip)
+	start, err := s.ogg.SeekGranule(max(target-opusPreroll, 0))
+	if err != nil {
+		return err
+	}
+	if err = s.dec.Reset(); err != nil {
+		return err
+	}
+	s.buf = nil
+	s.discard = int(target - start)
+	s.pos = p
+	return nil
+}
+
+func (s *opusStreamer) Close() error {
+	s.dec.Close()
+	return s.closer.Close()
+}
@@ -0,0 +1,275 @@
+package player
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ebitengine/oto/v3"
+	"github.com/gopxl/beep"
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+const (
+	outputChannels      = 2
+	outputBitDepth      = 32 // 以 32 位浮点交给 oto，与其驱动层格式一致，不再量化为 16 位
+	outputBytesPerFrame = outputChannels * outputBitDepth / 8
+	outputBufferTime    = 200 * time.Millisecond // 驱动与 Player 各占一半
+)
+
+var (
+	otoContextMu   sync.Mutex
+	otoContext     *oto.Context
+	otoContextRate beep.SampleRate
+)
+
+// openOtoContext 在本进程中以 rate 打开输出设备，返回设备实际的采样率
+//
+// oto 每个进程只能创建一个音频上下文且不能关闭，之后的调用直接返回已打开的设备。
+func openOtoContext(rate beep.SampleRate) (*oto.Context, beep.SampleRate, error) {
+	otoContextMu.Lock()
+	defer otoContextMu.Unlock()
+	if otoContext != nil {
+		return otoContext, otoContextRate, nil
+	}
+	ctx, ready, err := oto.NewContext(&oto.NewContextOptions{
+		SampleRate:   int(rate),
+		ChannelCount: outputChannels,
+		Format:       oto.FormatFloat32LE,
+		BufferSize:   outputBufferTime / 2,
+	})
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to open audio output")
+	}
+	<-ready
+	otoContext, otoContextRate = ctx, rate
+	return ctx, rate, nil
+}
+
+// beepOutput 将 beep.Streamer 输出到音频设备，代替 speaker 包
+//
+// 每次 play 都重新开始一段输出并丢弃上一段已缓冲的样本，切歌时不会再播出上一首的尾音。
+// 原始采样率模式下设备可以按新的采样率重新打开，见 open。
+type beepOutput struct {
+	mu      sync.Mutex // 持有时不会再从 streamer 读取样本，作用同 speaker.Lock
+	native  bool
+	rate    beep.SampleRate // 设备的采样率，未打开时为 0
+	device  outputDevice
+	playing uint64 // 每次 play 加一，旧的读取随之结束
+}
+
+// outputDevice 以固定的采样率播放 32 位浮点 PCM
+type outputDevice interface {
+	// play 停止当前的播放后从 r 读取样本播放
+	play(r io.Reader) error
+	// stop 停止播放并丢弃已缓冲的样本
+	stop()
+}
+
+func newBeepOutput(native bool) *beepOutput {
+	return &beepOutput{native: native}
+}
+
+// open 以 rate 打开输出设备
+//
+// oto 每个进程只能创建一个音频上下文且不能关闭，因此原始采样率模式下每个采样率使用一个
+// 输出子进程，采样率变化时结束旧的子进程再按新的采样率启动；否则在本进程中打开设备，
+// 之后沿用设备的采样率。
+func (o *beepOutput) open(rate beep.SampleRate) error {
+	if o.rate == rate || (o.device != nil && !o.native) {
+		return nil
+	}
+	o.clear()
+	if o.native {
+		o.device, o.rate = &processDevice{rate: rate}, rate
+		return nil
+	}
+	ctx, rate, err := openOtoContext(rate)
+	if err != nil {
+		return err
+	}
+	o.device, o.rate = &otoDevice{ctx: ctx, rate: rate}, rate
+	return nil
+}
+
+// play 丢弃当前的输出后从 s 开始播放，s 结束后输出停止
+func (o *beepOutput) play(s beep.Streamer) {
+	o.clear()
+	o.mu.Lock()
+	o.playing++
+	reader := &outputReader{o: o, s: s, playing: o.playing}
+	o.mu.Unlock()
+	if o.device == nil {
+		return
+	}
+	if err := o.device.play(reader); err != nil {
+		slog.Error("failed to start audio output", "sample_rate", int(o.rate), slogx.Error(err))
+	}
+}
+
+// clear 停止播放并丢弃已缓冲的样本
+func (o *beepOutput) clear() {
+	o.mu.Lock()
+	o.playing++
+	o.mu.Unlock()
+	if o.device != nil {
+		o.device.stop()
+	}
+}
+
+func (o *beepOutput) lock() {
+	o.mu.Lock()
+}
+
+func (o *beepOutput) unlock() {
+	o.mu.Unlock()
+}
+
+// outputReader 将 s 的样本编码为 oto 读取的 32 位浮点小端数据
+type outputReader struct {
+	o       *beepOutput
+	s       beep.Streamer
+	playing uint64
+	buf     [][2]float64
+}
+
+func (r *outputReader) Read(p []byte) (int, error) {
+	n := len(p) / outputBytesPerFrame
+	if n == 0 {
+		return 0, nil
+	}
+	if len(r.buf) < n {
+		r.buf = make([][2]float64, n)
+	}
+
+	r.o.mu.Lock()
+	// play 或 clear 之后旧的 Player 不再读取 s
+	if r.o.playing != r.playing {
+		r.o.mu.Unlock()
+		return 0, io.EOF
+	}
+	n, ok := r.s.Stream(r.buf[:n])
+	r.o.mu.Unlock()
+	if !ok && n == 0 {
+		if err := r.s.Err(); err != nil {
+			return 0, errors.Wrap(err, "streamer returned error when requesting samples")
+		}
+		return 0, io.EOF
+	}
+
+	for i, frame := range r.buf[:n] {
+		for c, val := range frame {
+			val = max(-1, min(1, val))
+			binary.LittleEndian.PutUint32(p[i*outputBytesPerFrame+c*4:], math.Float32bits(float32(val)))
+		}
+	}
+	return n * outputBytesPerFrame, nil
+}
+
+// otoDevice 本进程中的 oto 输出，每段输出使用一个 oto.Player
+type otoDevice struct {
+	ctx    *oto.Context
+	rate   beep.SampleRate
+	player *oto.Player
+}
+
+func (d *otoDevice) play(r io.Reader) error {
+	d.stop()
+	d.player = d.ctx.NewPlayer(r)
+	d.player.SetBufferSize(d.rate.N(outputBufferTime/2) * outputBytesPerFrame)
+	d.player.Play()
+	return nil
+}
+
+func (d *otoDevice) stop() {
+	if d.player != nil {
+		_ = d.player.Close()
+		d.player = nil
+	}
+}
+
+// outputRateEnv 设置了该环境变量的进程是输出子进程，以其值为采样率播放标准输入中的 PCM
+const outputRateEnv = "MUSICFOX_AUDIO_OUTPUT_RATE"
+
+// outputCommand 创建以 rate 播放标准输入的输出子进程
+var outputCommand = func(rate beep.SampleRate) (*exec.Cmd, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(executable)
+	cmd.Env = append(os.Environ(), outputRateEnv+"="+strconv.Itoa(int(rate)))
+	cmd.Stderr = os.Stderr
+	return cmd, nil
+}
+
+// processDevice 由输出子进程播放，每段输出使用一个子进程，停止时直接结束子进程以丢弃其缓冲
+type processDevice struct {
+	rate beep.SampleRate
+	cmd  *exec.Cmd
+}
+
+func (d *processDevice) play(r io.Reader) error {
+	d.stop()
+	cmd, err := outputCommand(d.rate)
+	if err != nil {
+		return err
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+	d.cmd = cmd
+	go func() {
+		// 子进程被结束时写入失败，r 随之被放弃
+		_, _ = io.Copy(stdin, r)
+		_ = stdin.Close()
+		_ = cmd.Wait()
+	}()
+	return nil
+}
+
+func (d *processDevice) stop() {
+	if d.cmd != nil {
+		_ = d.cmd.Process.Kill()
+		d.cmd = nil
+	}
+}
+
+// RunOutputProcess 当前进程是输出子进程时播放标准输入直到结束并返回 true，否则返回 false
+func RunOutputProcess() bool {
+	value, ok := os.LookupEnv(outputRateEnv)
+	if !ok {
+		return false
+	}
+	rate, err := strconv.Atoi(value)
+	if err != nil || rate <= 0 {
+		fmt.Fprintf(os.Stderr, "invalid %s: %q\n", outputRateEnv, value)
+		os.Exit(1)
+	}
+	ctx, _, err := openOtoContext(beep.SampleRate(rate))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	device := &otoDevice{ctx: ctx, rate: beep.SampleRate(rate)}
+	_ = device.play(os.Stdin)
+	for device.player.IsPlaying() {
+		time.Sleep(50 * time.Millisecond)
+	}
+	// 等驱动中的缓冲播完
+	time.Sleep(outputBufferTime)
+	return true
+}
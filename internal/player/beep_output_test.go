@@ -0,0 +1,121 @@
+package player
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopxl/beep"
+)
+
+func TestOutputReaderEncodesFloat32(t *testing.T) {
+	o := &beepOutput{playing: 1}
+	r := &outputReader{o: o, s: &sampleStreamer{samples: [][2]float64{{0.5, -0.25}, {2, -2}}}, playing: 1}
+	buf := make([]byte, 3*outputBytesPerFrame)
+	n, err := r.Read(buf)
+	if err != nil || n != 2*outputBytesPerFrame {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	// 超出范围的样本被截断到 [-1, 1]，小于 16 位精度的值不会丢失
+	for i, want := range []float32{0.5, -0.25, 1, -1} {
+		if got := math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:])); got != want {
+			t.Errorf("sample %d = %v, want %v", i, got, want)
+		}
+	}
+	if n, err = r.Read(buf); n != 0 || err != io.EOF {
+		t.Fatalf("after end n=%d err=%v, want EOF", n, err)
+	}
+}
+
+func TestOutputReaderStopsAfterReplay(t *testing.T) {
+	o := &beepOutput{playing: 1}
+	s := &sampleStreamer{samples: make([][2]float64, 8)}
+	r := &outputReader{o: o, s: s, playing: 1}
+	o.playing++
+	if n, err := r.Read(make([]byte, outputBytesPerFrame)); n != 0 || err != io.EOF {
+		t.Fatalf("stale reader n=%d err=%v, want EOF", n, err)
+	}
+	if s.pos != 0 {
+		t.Fatalf("stale reader consumed %d samples", s.pos)
+	}
+}
+
+func TestGaplessOnlyJoinsTracksWithTheSameRate(t *testing.T) {
+	p := &beepPlayer{curFormat: beep.Format{SampleRate: 44100}}
+	if !p.sameRate(&preparedGapless{format: beep.Format{SampleRate: 44100}}) {
+		t.Error("same rate rejected")
+	}
+	if p.sameRate(&preparedGapless{format: beep.Format{SampleRate: 96000}}) {
+		t.Error("different rate joined gaplessly")
+	}
+}
+
+// TestOutputProcessHelper 作为输出子进程运行：记录采样率与收到的字节数
+func TestOutputProcessHelper(t *testing.T) {
+	path := os.Getenv("MUSICFOX_TEST_OUTPUT_FILE")
+	if path == "" {
+		t.Skip("helper process")
+	}
+	n, _ := io.Copy(io.Discard, os.Stdin)
+	_ = os.WriteFile(path, []byte(fmt.Sprintf("%s %d", os.Getenv(outputRateEnv), n)), 0o644)
+}
+
+// fakeOutputCommand 用测试程序自身代替输出子进程，第 i 个子进程写入 dir/i
+func fakeOutputCommand(t *testing.T, dir string) {
+	var started int
+	old := outputCommand
+	t.Cleanup(func() { outputCommand = old })
+	outputCommand = func(rate beep.SampleRate) (*exec.Cmd, error) {
+		started++
+		cmd := exec.Command(os.Args[0], "-test.run=^TestOutputProcessHelper$")
+		cmd.Env = append(os.Environ(),
+			fmt.Sprintf("%s=%d", outputRateEnv, rate),
+			"MUSICFOX_TEST_OUTPUT_FILE="+filepath.Join(dir, fmt.Sprint(started)))
+		return cmd, nil
+	}
+}
+
+func waitOutputFile(t *testing.T, path string) string {
+	t.Helper()
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if data, err := os.ReadFile(path); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	}
+	t.Fatalf("output process did not finish: %s", path)
+	return ""
+}
+
+func TestNativeOutputReopensOnRateChange(t *testing.T) {
+	dir := t.TempDir()
+	fakeOutputCommand(t, dir)
+	o := newBeepOutput(true)
+
+	if err := o.open(44100); err != nil {
+		t.Fatal(err)
+	}
+	o.play(&sampleStreamer{samples: make([][2]float64, 100)})
+	if got, want := waitOutputFile(t, filepath.Join(dir, "1")), fmt.Sprintf("44100 %d", 100*outputBytesPerFrame); got != want {
+		t.Fatalf("first output = %q, want %q", got, want)
+	}
+
+	// 采样率变化时按新的采样率启动输出，不再重采样
+	if err := o.open(96000); err != nil {
+		t.Fatal(err)
+	}
+	if o.rate != 96000 {
+		t.Fatalf("rate = %d, want 96000", o.rate)
+	}
+	o.play(&sampleStreamer{samples: make([][2]float64, 50)})
+	if got, want := waitOutputFile(t, filepath.Join(dir, "2")), fmt.Sprintf("96000 %d", 50*outputBytesPerFrame); got != want {
+		t.Fatalf("second output = %q, want %q", got, want)
+	}
+	o.clear()
+}
@@ -15,8 +15,9 @@ import (
 
 	"github.com/gopxl/beep"
 	"github.com/gopxl/beep/effects"
-	"github.com/gopxl/beep/speaker"
+	"github.com/pkg/errors"
 
+	"github.com/go-musicfox/go-musicfox/internal/codec"
 	"github.com/go-musicfox/go-musicfox/internal/configs"
 	"github.com/go-musicfox/go-musicfox/internal/types"
 	"github.com/go-musicfox/go-musicfox/utils/app"
@@ -28,7 +29,7 @@ import (
 )
 
 const (
-	sampleRate       = beep.SampleRate(44100)
+	sampleRate       = beep.SampleRate(44100) // 未开启原始采样率时输出设备的采样率
 	resampleQuiality = 4
 )
 
@@ -45,6 +46,9 @@ type beepPlayer struct {
 
 	curStreamer beep.StreamSeekCloser
 	curFormat   beep.Format
+	curGain     *effects.Gain // 当前歌曲的响度均衡环节，未开启时为 nil
+	loudness    *loudnessAnalyzer
+	eq          *equalizer
 
 	state             atomic.Uint32 // types.State, atomically read/written (setState runs under p.l; State() is called from the UI thread)
 	ctrl              *beep.Ctrl
@@ -54,8 +58,12 @@ type beepPlayer struct {
 	musicChan         chan URLMusic
 	httpClient        *http.Client
 	gapless           *gaplessState
+	crossfade         *crossfader
+	fade              *activeFade // 正在淡出的上一首，仅在交叉淡入淡出期间非空
 	gaplessOutput     beep.Streamer
 	gaplessOutputRate beep.SampleRate
+	output            *beepOutput
+	outputRate        beep.SampleRate // 输出设备的采样率，设备未打开时为 0
 
 	close chan struct{}
 
@@ -76,13 +84,20 @@ func NewBeepPlayer() *beepPlayer {
 			Silent: false,
 		},
 		httpClient: &http.Client{},
+		output:     newBeepOutput(configs.AppConfig.Player.Beep.NativeSampleRate),
 		close:      make(chan struct{}),
 	}
-	if configs.AppConfig.Player.Beep.Gapless {
+	p.crossfade = newCrossfader(configs.AppConfig.Player.Beep)
+	// 交叉淡入淡出复用无缝播放的预加载
+	if configs.AppConfig.Player.Beep.Gapless || p.crossfade != nil {
 		p.gapless = newGaplessState()
 	}
+	p.loudness = newLoudnessAnalyzer(configs.AppConfig.Player.Beep)
+	eqCfg := configs.AppConfig.Player.Beep.Equalizer
+	preset, _ := configs.FindEqualizerPreset(eqCfg.Preset)
+	p.eq = newEqualizer(eqCfg.Enable, preset)
 
-	if configs.AppConfig.Main.Visualizer.Enable || (configs.AppConfig.Main.Lyric.DesktopLyrics.SpectrumEnabled && desktopLyricsAvailable) {
+	if spectrumWanted() {
 		p.spectrum = NewPCMAnalyzer(configs.AppConfig.Main.FrameRate.Interval())
 	}
 
@@ -103,6 +118,7 @@ func (p *beepPlayer) listen() {
 		ctx        context.Context
 		cancel     context.CancelFunc
 		prevSongId int64
+		downloaded chan struct{} // 当前歌曲下载结束（含失败）时关闭
 		doneHandle = func() {
 			select {
 			case done <- struct{}{}:
@@ -111,8 +127,9 @@ func (p *beepPlayer) listen() {
 		}
 	)
 
-	if err = speaker.Init(sampleRate, sampleRate.N(time.Millisecond*200)); err != nil {
-		panic(err)
+	// 原始采样率模式下等解码出第一首歌后再打开输出设备
+	if !configs.AppConfig.Player.Beep.NativeSampleRate {
+		p.initOutput(sampleRate)
 	}
 
 	cacheFile := filepath.Join(app.RuntimeDir(), "beep_playing")
@@ -164,8 +181,10 @@ func (p *beepPlayer) listen() {
 				}
 
 				// 边下载边播放
-				go func(ctx context.Context, cacheWFile *os.File, read io.ReadCloser) {
+				downloaded = make(chan struct{})
+				go func(ctx context.Context, cacheWFile *os.File, read io.ReadCloser, downloaded chan<- struct{}) {
 					_, _ = iox.CopyClose(ctx, cacheWFile, read)
+					close(downloaded)
 					p.l.Lock()
 					defer p.l.Unlock()
 					if p.curStreamer == nil {
@@ -194,7 +213,8 @@ func (p *beepPlayer) listen() {
 						p.ctrl.Streamer = beep.Seq(p.resampleStreamer(p.curFormat.SampleRate), beep.Callback(doneHandle))
 					}
 					p.cacheDownloaded = true
-				}(ctx, p.cacheWriter, reader)
+					p.analyzeLoudness(ctx, cacheFile)
+				}(ctx, p.cacheWriter, reader, downloaded)
 
 				N := 512
 				if p.curMusic.Type == Flac {
@@ -213,12 +233,24 @@ func (p *beepPlayer) listen() {
 				}
 			}
 
-			if p.curStreamer, p.curFormat, err = decodeSong(p.curMusic.Type, p.cacheReader, p.curMusic.Duration, p.cacheDownloaded); err != nil {
+			if err = p.decodeCache(downloaded); err != nil {
+				slog.Error("decode song err", slog.Int64("song_id", p.curMusic.Id), slogx.Error(err))
 				p.stopNoLock()
 				goto nextLoop
 			}
 
-			slog.Info("current song sample rate", slog.Int("sample_rate", int(p.curFormat.SampleRate)))
+			// 原始采样率模式下采样率变化时按新的采样率重新打开
+			if p.outputRate == 0 || p.output.native {
+				p.initOutput(p.curFormat.SampleRate)
+			}
+			slog.Info("current song sample rate", slog.Int("sample_rate", int(p.curFormat.SampleRate)), slog.Int("output_rate", int(p.outputRate)))
+
+			if p.loudness != nil {
+				p.curGain = p.loudness.newStage(p.curMusic.Id, p.curStreamer)
+				if p.cacheDownloaded {
+					p.analyzeLoudness(ctx, cacheFile)
+				}
+			}
 
 			if p.spectrum != nil {
 				p.spectrumConsumer = p.spectrum.NewConsumer()
@@ -226,7 +258,7 @@ func (p *beepPlayer) listen() {
 
 			p.ctrl.Streamer = beep.Seq(p.resampleStreamer(p.curFormat.SampleRate), beep.Callback(doneHandle))
 			p.volume.Streamer = p.ctrl
-			speaker.Play(p.volume)
+			p.output.play(p.volume)
 
 			// 计时器
 			p.timer = timex.NewTimer(timex.Options{
@@ -260,6 +292,36 @@ func (p *beepPlayer) listen() {
 	}
 }
 
+// decodeCache 解码缓存文件，调用方需持有 p.l
+//
+// 文件类型以文件头为准并回写到 curMusic，避免扩展名与实际格式不符。moov 位于文件末尾的
+// MP4 在下载完成前无法解析，此时释放锁等待更多数据后重试。
+func (p *beepPlayer) decodeCache(downloaded <-chan struct{}) (err error) {
+	for {
+		p.curMusic.Type, _ = sniffSongType(p.cacheReader, p.curMusic.Type)
+		p.curStreamer, p.curFormat, err = decodeSong(p.curMusic.Type, p.cacheReader, p.curMusic.Duration, p.cacheDownloaded)
+		if !errors.Is(err, codec.ErrIncomplete) || p.cacheDownloaded || downloaded == nil {
+			return err
+		}
+
+		p.l.Unlock()
+		select {
+		case <-downloaded:
+			p.l.Lock()
+			// 下载协程见 curStreamer 为空会直接退出，由这里标记下载完成
+			p.cacheDownloaded = true
+		case <-time.After(500 * time.Millisecond):
+			p.l.Lock()
+		case <-p.close:
+			p.l.Lock()
+			return err
+		}
+		if types.State(p.state.Load()) == types.Stopped {
+			return err
+		}
+	}
+}
+
 // Play 播放音乐
 func (p *beepPlayer) Play(music URLMusic) {
 	timer := time.NewTimer(time.Second)
@@ -279,7 +341,7 @@ func (p *beepPlayer) Preload(music URLMusic) {
 			outputRate = p.curFormat.SampleRate
 		}
 		p.l.Unlock()
-		p.gapless.preload(fromID, music, outputRate, p.httpClient, p.close)
+		p.gapless.preload(fromID, music, outputRate, p.httpClient, p.loudness, p.close)
 	}
 }
 
@@ -347,15 +409,15 @@ func (p *beepPlayer) Seek(duration time.Duration) {
 	if duration < 0 || !p.cacheDownloaded {
 		return
 	}
-	// FIXME: 暂时仅对MP3格式提供跳转功能
+	// FIXME: 暂时仅对MP3、AAC、Opus格式提供跳转功能
 	// FLAC格式(其他未测)跳转会占用大量CPU资源，比特率越高占用越高
 	// 导致Seek方法卡住20-40秒的时间，之后方可随意跳转
 	// minimp3未实现Seek
-	if p.curStreamer == nil || p.curMusic.Type != Mp3 || configs.AppConfig.Player.Beep.Mp3Decoder == types.BeepMiniMp3Decoder {
+	if p.curStreamer == nil || !p.seekable() {
 		return
 	}
 	if types.State(p.state.Load()) == types.Playing || types.State(p.state.Load()) == types.Paused {
-		speaker.Lock()
+		p.output.lock()
 		newPos := p.curFormat.SampleRate.N(duration)
 
 		if newPos < 0 {
@@ -373,10 +435,21 @@ func (p *beepPlayer) Seek(duration time.Duration) {
 		if p.timer != nil {
 			p.timer.SetPassed(duration)
 		}
-		speaker.Unlock()
+		p.output.unlock()
 	}
 }
 
+// seekable AAC、Opus 按包索引跳转，开销很小
+func (p *beepPlayer) seekable() bool {
+	switch p.curMusic.Type {
+	case Mp3:
+		return configs.AppConfig.Player.Beep.Mp3Decoder != types.BeepMiniMp3Decoder
+	case Aac, Opus:
+		return true
+	}
+	return false
+}
+
 // UpVolume 调大音量
 func (p *beepPlayer) UpVolume() {
 	if p.volume.Volume >= 0 {
@@ -500,8 +573,7 @@ func (p *beepPlayer) Close() {
 	if p.spectrum != nil {
 		p.spectrum.Close()
 	}
-	speaker.Clear()
-	speaker.Close()
+	p.output.clear()
 }
 
 func (p *beepPlayer) reset() {
@@ -516,6 +588,10 @@ func (p *beepPlayer) reset() {
 	if p.cacheWriter != nil {
 		_ = p.cacheWriter.Close()
 	}
+	if p.fade != nil {
+		p.fade.close()
+		p.fade = nil
+	}
 	if p.gaplessCachePath != "" {
 		_ = os.Remove(p.gaplessCachePath)
 		p.gaplessCachePath = ""
@@ -524,11 +600,12 @@ func (p *beepPlayer) reset() {
 		_ = p.curStreamer.Close()
 		p.curStreamer = nil
 	}
+	p.curGain = nil
 	p.cacheDownloaded = false
 	p.spectrumConsumer = nil
 	p.gaplessOutput = nil
 	p.gaplessOutputRate = 0
-	speaker.Clear()
+	p.output.clear()
 }
 
 func (p *beepPlayer) streamer(samples [][2]float64) (n int, ok bool) {
@@ -541,14 +618,12 @@ func (p *beepPlayer) streamer(samples [][2]float64) (n int, ok bool) {
 			return filled, filled == len(samples)
 		}
 
-		current := beep.Streamer(p.curStreamer)
-		if p.gaplessOutput != nil {
-			current = p.gaplessOutput
-		}
+		p.maybeStartCrossfade()
+		current := p.trackStream()
 		var prepared *preparedGapless
 		chunk, streamOK, switched := streamAcrossBoundary(samples[filled:], current, func() beep.Streamer {
 			if p.gapless != nil {
-				prepared = p.gapless.takeIfReady(p.curMusic.Id)
+				prepared = p.gapless.takeIf(p.curMusic.Id, p.sameRate)
 			}
 			if prepared == nil {
 				return nil
@@ -559,6 +634,12 @@ func (p *beepPlayer) streamer(samples [][2]float64) (n int, ok bool) {
 		if switched {
 			p.finishGapless(prepared)
 		}
+		if p.fade != nil && chunk > 0 && p.fade.mix(samples[filled-chunk:filled]) {
+			p.fade.close()
+			p.fade = nil
+		}
+		// 均衡器在频谱采集之前，频谱显示均衡后的信号
+		p.eq.process(samples[filled-chunk:filled], p.streamRate())
 
 		// Spectrum: feed PCM samples to analyzer.
 		if p.spectrumConsumer != nil && chunk > 0 {
@@ -568,7 +649,7 @@ func (p *beepPlayer) streamer(samples [][2]float64) (n int, ok bool) {
 				samplesL[i] = float32(samples[filled-chunk+i][0])
 				samplesR[i] = float32(samples[filled-chunk+i][1])
 			}
-			p.spectrumConsumer(float64(sampleRate), samplesL, samplesR)
+			p.spectrumConsumer(float64(p.streamRate()), samplesL, samplesR)
 		}
 
 		err := p.curStreamer.Err()
@@ -608,24 +689,25 @@ func (p *beepPlayer) streamer(samples [][2]float64) (n int, ok bool) {
 }
 
 func (p *beepPlayer) finishGapless(prepared *preparedGapless) {
-	old := p.curStreamer
+	p.switchTrack(prepared, 0)()
+}
+
+// switchTrack 切换到预加载的歌曲并上报切换，remaining 为上一首还将继续（淡出）播放的时长。
+// 返回释放上一首解码器与缓存文件的函数，淡出结束前不能调用。
+func (p *beepPlayer) switchTrack(prepared *preparedGapless, remaining time.Duration) (release func()) {
+	old, oldReader, oldCachePath := p.curStreamer, p.cacheReader, p.gaplessCachePath
 	var playedTime time.Duration
 	if p.timer != nil {
-		playedTime = p.timer.ActualRuntime()
-	}
-	if p.cacheReader != nil {
-		_ = p.cacheReader.Close()
+		playedTime = p.timer.ActualRuntime() + remaining
 	}
 	if p.cacheWriter != nil {
 		_ = p.cacheWriter.Close()
 		p.cacheWriter = nil
 	}
-	if p.gaplessCachePath != "" {
-		_ = os.Remove(p.gaplessCachePath)
-	}
 	p.curMusic = prepared.music
 	p.curStreamer = prepared.raw
 	p.curFormat = prepared.format
+	p.curGain = prepared.gain
 	p.gaplessOutput = prepared.stream
 	p.cacheReader = prepared.file
 	p.gaplessCachePath = prepared.file.Name()
@@ -633,23 +715,134 @@ func (p *beepPlayer) finishGapless(prepared *preparedGapless) {
 	if p.timer != nil {
 		p.timer.Reset()
 	}
-	if old != nil {
-		_ = old.Close()
-	}
 	select {
 	case p.gapless.transitions <- GaplessTransition{Music: prepared.music, PlayedTime: playedTime}:
 	default:
 	}
+	return func() {
+		if oldReader != nil {
+			_ = oldReader.Close()
+		}
+		if oldCachePath != "" {
+			_ = os.Remove(oldCachePath)
+		}
+		if old != nil {
+			_ = old.Close()
+		}
+	}
+}
+
+// maybeStartCrossfade 当前歌曲剩余时长进入淡出区间且下一首已预加载时开始交叉淡入淡出。
+// 下一首立即成为当前歌曲（进度、歌词从其开头计算），上一首在 fade 中继续播放至结束。
+// 调用方需持有 p.l。
+func (p *beepPlayer) maybeStartCrossfade() {
+	if p.crossfade == nil || p.fade != nil || p.gapless == nil || !p.cacheDownloaded ||
+		p.curStreamer == nil || p.curFormat.SampleRate == 0 || p.gaplessOutputRate == 0 {
+		return
+	}
+	outputRate := p.gaplessOutputRate
+	remaining := outputRate.N(p.curFormat.SampleRate.D(p.curStreamer.Len() - p.curStreamer.Position()))
+	if remaining <= 0 || remaining > outputRate.N(p.crossfade.duration) {
+		return
+	}
+	from := p.curMusic
+	prepared := p.gapless.takeIf(from.Id, func(next *preparedGapless) bool {
+		// 同专辑或下一首过短时保留给无缝切换；原始采样率模式下采样率不同时需要重新打开设备，不做淡入淡出
+		return p.crossfade.allowed(from, next.music) && next.format.SampleRate.D(next.raw.Len()) > 2*outputRate.D(remaining) &&
+			(!p.output.native || p.sameRate(next))
+	})
+	if prepared == nil {
+		return
+	}
+	slog.Info("crossfade start", "from", from.Id, "to", prepared.music.Id, "duration", outputRate.D(remaining))
+	out := p.trackStream()
+	release := p.switchTrack(prepared, outputRate.D(remaining))
+	p.fade = &activeFade{curve: p.crossfade, out: out, length: remaining, release: release}
+}
+
+// trackStream 当前歌曲经响度均衡后的流。
+// 增益环节位于每首歌自身的流上（早于 effects.Volume），无缝切换时随歌曲一起切换。
+func (p *beepPlayer) trackStream() beep.Streamer {
+	if p.gaplessOutput != nil {
+		return p.gaplessOutput
+	}
+	if p.curGain == nil {
+		return p.curStreamer
+	}
+	// MP3 下载完成后 curStreamer 会被重新创建
+	p.curGain.Streamer = p.curStreamer
+	return p.curGain
+}
+
+// analyzeLoudness 在歌曲完整下载后后台计算响度均衡增益，调用方需持有 p.l
+func (p *beepPlayer) analyzeLoudness(ctx context.Context, path string) {
+	if p.loudness == nil || p.curGain == nil {
+		return
+	}
+	stage, music := p.curGain, p.curMusic
+	go func() {
+		multiplier, ok := p.loudness.analyze(ctx, music, path)
+		if !ok || ctx.Err() != nil {
+			return
+		}
+		p.output.lock()
+		stage.Gain = multiplier - 1
+		p.output.unlock()
+	}()
+}
+
+// initOutput 以 rate 打开输出设备，见 beepOutput.open
+func (p *beepPlayer) initOutput(rate beep.SampleRate) {
+	if err := p.output.open(rate); err != nil {
+		panic(err)
+	}
+	p.outputRate = p.output.rate
 }
 
 func (p *beepPlayer) resampleStreamer(old beep.SampleRate) beep.Streamer {
 	if p.gapless != nil {
 		p.gaplessOutputRate = old
 	}
-	if old == sampleRate {
+	if old == p.outputRate {
 		return beep.StreamerFunc(p.streamer)
 	}
-	return beep.Resample(resampleQuiality, old, sampleRate, beep.StreamerFunc(p.streamer))
+	return beep.Resample(resampleQuiality, old, p.outputRate, beep.StreamerFunc(p.streamer))
+}
+
+// OutputFormat 当前歌曲的采样率与输出设备的格式
+func (p *beepPlayer) OutputFormat() (OutputFormat, bool) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	if p.curStreamer == nil || p.outputRate == 0 {
+		return OutputFormat{}, false
+	}
+	return OutputFormat{
+		SourceRate: int(p.curFormat.SampleRate),
+		SampleRate: int(p.outputRate),
+		BitDepth:   outputBitDepth,
+	}, true
+}
+
+// streamRate p.streamer 输出的采样率，交叉淡入淡出时下一首会被重采样到上一首的采样率
+func (p *beepPlayer) streamRate() beep.SampleRate {
+	if p.gaplessOutputRate != 0 {
+		return p.gaplessOutputRate
+	}
+	return p.curFormat.SampleRate
+}
+
+// sameRate 下一首与当前输出的采样率相同时才无缝衔接；不同时让当前歌曲正常结束，
+// 下一首在原始采样率模式下按其采样率重新打开设备，否则只重采样一次，不在上一首的采样率上再转一次
+func (p *beepPlayer) sameRate(next *preparedGapless) bool {
+	return next.format.SampleRate == p.streamRate()
+}
+
+func (p *beepPlayer) SetEqualizer(enabled bool, preset configs.EqualizerPreset) {
+	p.eq.set(enabled, preset)
+}
+
+func (p *beepPlayer) Equalizer() (bool, configs.EqualizerPreset) {
+	return p.eq.settings()
 }
 
 func (p *beepPlayer) Spectrum() SpectrumFrame {
@@ -0,0 +1,240 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bogem/id3v2/v2"
+	goflac "github.com/go-flac/go-flac"
+	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/effects"
+	"github.com/jfreymuth/oggvorbis"
+
+	"github.com/go-musicfox/go-musicfox/internal/codec"
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// maxLoudnessCache 内存中保留的响度信息条数，超出后清空重新计算
+const maxLoudnessCache = 512
+
+// replayGainInfo 一首歌的 ReplayGain 信息，增益相对于 ReplayGain 2.0 参考响度（-18 LUFS）
+type replayGainInfo struct {
+	TrackGain, AlbumGain float64 // dB
+	TrackPeak, AlbumPeak float64 // 线性采样峰值，0 表示未知
+	HasTrack, HasAlbum   bool
+}
+
+// set 按标签名写入对应字段，返回是否为 ReplayGain 标签
+func (info *replayGainInfo) set(key, value string) bool {
+	parse := func() (float64, bool) {
+		// 形如 "-6.50 dB" 或 "0.988553"
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			return 0, false
+		}
+		v, err := strconv.ParseFloat(fields[0], 64)
+		return v, err == nil && !math.IsNaN(v) && !math.IsInf(v, 0)
+	}
+	switch strings.ToUpper(key) {
+	case "REPLAYGAIN_TRACK_GAIN":
+		info.TrackGain, info.HasTrack = parse()
+	case "REPLAYGAIN_ALBUM_GAIN":
+		info.AlbumGain, info.HasAlbum = parse()
+	case "REPLAYGAIN_TRACK_PEAK":
+		info.TrackPeak, _ = parse()
+	case "REPLAYGAIN_ALBUM_PEAK":
+		info.AlbumPeak, _ = parse()
+	default:
+		return false
+	}
+	return true
+}
+
+// readReplayGain 从 ID3v2 TXXX 帧、FLAC 或 Ogg（Vorbis、Opus）的注释中读取 ReplayGain 标签
+func readReplayGain(t SongType, r io.ReadSeeker) (info replayGainInfo, ok bool) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return info, false
+	}
+	defer func() { _, _ = r.Seek(0, io.SeekStart) }()
+
+	t, _ = sniffSongType(r, t)
+	switch t {
+	case Mp3:
+		tag, err := id3v2.ParseReader(r, id3v2.Options{Parse: true, ParseFrames: []string{"TXXX"}})
+		if err != nil {
+			return info, false
+		}
+		for _, frame := range tag.GetFrames("TXXX") {
+			if udtf, isText := frame.(id3v2.UserDefinedTextFrame); isText {
+				info.set(udtf.Description, udtf.Value)
+			}
+		}
+	case Flac:
+		file, err := goflac.ParseMetadata(r)
+		if err != nil {
+			return info, false
+		}
+		for _, meta := range file.Meta {
+			if meta.Type == goflac.VorbisComment {
+				readVorbisReplayGain(parseVorbisCommentBlock(meta.Data), &info)
+			}
+		}
+	case Ogg:
+		header, err := oggvorbis.GetCommentHeader(r)
+		if err != nil {
+			return info, false
+		}
+		readVorbisReplayGain(header.Comments, &info)
+	case Opus:
+		ogg, err := codec.OpenOggOpus(r)
+		if err != nil {
+			return info, false
+		}
+		readVorbisReplayGain(ogg.Comments(), &info)
+	}
+	return info, info.HasTrack || info.HasAlbum
+}
+
+func readVorbisReplayGain(comments []string, info *replayGainInfo) {
+	for _, comment := range comments {
+		if key, value, found := strings.Cut(comment, "="); found {
+			info.set(key, value)
+		}
+	}
+}
+
+// parseVorbisCommentBlock 解析 FLAC METADATA_BLOCK_VORBIS_COMMENT（小端长度前缀）
+func parseVorbisCommentBlock(data []byte) []string {
+	r := bytes.NewReader(data)
+	readString := func() (string, bool) {
+		var length uint32
+		if binary.Read(r, binary.LittleEndian, &length) != nil || int64(length) > int64(r.Len()) {
+			return "", false
+		}
+		buf := make([]byte, length)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", false
+		}
+		return string(buf), true
+	}
+
+	if _, ok := readString(); !ok { // vendor string
+		return nil
+	}
+	var count uint32
+	if binary.Read(r, binary.LittleEndian, &count) != nil {
+		return nil
+	}
+	var comments []string
+	for range count {
+		comment, ok := readString()
+		if !ok {
+			break
+		}
+		comments = append(comments, comment)
+	}
+	return comments
+}
+
+// loudnessAnalyzer 计算 beep 引擎每首歌的响度均衡增益
+type loudnessAnalyzer struct {
+	mode   string
+	target float64 // LUFS
+	preamp float64 // dB
+
+	mu    sync.Mutex
+	cache map[int64]replayGainInfo
+}
+
+// newLoudnessAnalyzer 未开启响度均衡时返回 nil
+func newLoudnessAnalyzer(cfg configs.BeepConfig) *loudnessAnalyzer {
+	if cfg.ReplayGain != types.ReplayGainTrack && cfg.ReplayGain != types.ReplayGainAlbum {
+		return nil
+	}
+	return &loudnessAnalyzer{
+		mode:   cfg.ReplayGain,
+		target: cfg.ReplayGainTarget,
+		preamp: cfg.ReplayGainPreamp,
+		cache:  make(map[int64]replayGainInfo),
+	}
+}
+
+// newStage 创建单首歌的增益环节，已有响度信息时直接应用
+func (a *loudnessAnalyzer) newStage(songID int64, s beep.Streamer) *effects.Gain {
+	stage := &effects.Gain{Streamer: s}
+	a.mu.Lock()
+	info, ok := a.cache[songID]
+	a.mu.Unlock()
+	if ok {
+		stage.Gain = a.multiplier(info) - 1
+	}
+	return stage
+}
+
+// analyze 读取 ReplayGain 标签，没有标签时对完整文件做 EBU R128 测量，返回线性增益倍数
+func (a *loudnessAnalyzer) analyze(ctx context.Context, music URLMusic, path string) (float64, bool) {
+	a.mu.Lock()
+	info, ok := a.cache[music.Id]
+	a.mu.Unlock()
+	if ok {
+		return a.multiplier(info), true
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return 1, false
+	}
+	defer file.Close()
+
+	info, ok = readReplayGain(music.Type, file)
+	if !ok {
+		streamer, format, decodeErr := decodeSong(music.Type, file, music.Duration, true)
+		if decodeErr != nil {
+			slog.Warn("loudness analysis decode error", "song_id", music.Id, slogx.Error(decodeErr))
+			return 1, false
+		}
+		loudness, peak, measureErr := measureLoudness(ctx, streamer, format.SampleRate)
+		if measureErr != nil || math.IsInf(loudness, -1) {
+			slog.Debug("loudness analysis skipped", "song_id", music.Id, slogx.Error(measureErr))
+			return 1, false
+		}
+		info = replayGainInfo{TrackGain: replayGainReference - loudness, TrackPeak: peak, HasTrack: true}
+		slog.Info("measured loudness", "song_id", music.Id, "lufs", loudness, "peak", peak)
+	}
+
+	if ctx.Err() != nil {
+		// 切歌后缓存文件可能已被覆盖，结果不可信
+		return 1, false
+	}
+	a.mu.Lock()
+	if len(a.cache) >= maxLoudnessCache {
+		clear(a.cache)
+	}
+	a.cache[music.Id] = info
+	a.mu.Unlock()
+	return a.multiplier(info), true
+}
+
+// multiplier 按模式、目标响度和前置增益计算线性增益倍数，并避免峰值削波
+func (a *loudnessAnalyzer) multiplier(info replayGainInfo) float64 {
+	gain, peak := info.TrackGain, info.TrackPeak
+	if (a.mode == types.ReplayGainAlbum && info.HasAlbum) || !info.HasTrack {
+		gain, peak = info.AlbumGain, info.AlbumPeak
+	}
+	db := gain + (a.target - replayGainReference) + a.preamp
+	linear := math.Pow(10, db/20)
+	if peak > 0 && linear*peak > 1 {
+		linear = 1 / peak
+	}
+	return linear
+}
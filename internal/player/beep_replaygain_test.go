@@ -0,0 +1,132 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/bogem/id3v2/v2"
+	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/effects"
+
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+func sineSamples(rate beep.SampleRate, freq, amplitude float64, seconds int) [][2]float64 {
+	samples := make([][2]float64, int(rate)*seconds)
+	for i := range samples {
+		v := amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(rate))
+		samples[i] = [2]float64{v, v}
+	}
+	return samples
+}
+
+func TestMeasureLoudnessMatchesReferenceSine(t *testing.T) {
+	// EBU Tech 3341 测试 1：每声道 -23 dBFS 的 1kHz 立体声正弦波为 -23 LUFS
+	amplitude := math.Pow(10, -23.0/20)
+	for _, rate := range []beep.SampleRate{44100, 48000} {
+		stream := &sampleStreamer{samples: sineSamples(rate, 1000, amplitude, 10)}
+		loudness, peak, err := measureLoudness(context.Background(), stream, rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(loudness-(-23)) > 0.1 {
+			t.Fatalf("rate %d: loudness = %.2f LUFS, want -23", rate, loudness)
+		}
+		if math.Abs(peak-amplitude) > 1e-3 {
+			t.Fatalf("rate %d: peak = %f, want %f", rate, peak, amplitude)
+		}
+	}
+}
+
+func TestMeasureLoudnessGatesSilence(t *testing.T) {
+	const rate = beep.SampleRate(48000)
+	samples := append(sineSamples(rate, 1000, math.Pow(10, -23.0/20), 5), make([][2]float64, int(rate)*20)...)
+	loudness, _, err := measureLoudness(context.Background(), &sampleStreamer{samples: samples}, rate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(loudness-(-23)) > 0.2 {
+		t.Fatalf("loudness with trailing silence = %.2f LUFS, want about -23", loudness)
+	}
+
+	silence, _, _ := measureLoudness(context.Background(), &sampleStreamer{samples: make([][2]float64, int(rate))}, rate)
+	if !math.IsInf(silence, -1) {
+		t.Fatalf("silence loudness = %f, want -Inf", silence)
+	}
+}
+
+func TestReadReplayGainFromID3v2(t *testing.T) {
+	tag := id3v2.NewEmptyTag()
+	tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{Encoding: id3v2.EncodingUTF8, Description: "REPLAYGAIN_TRACK_GAIN", Value: "-6.50 dB"})
+	tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{Encoding: id3v2.EncodingUTF8, Description: "replaygain_track_peak", Value: "0.988553"})
+	var buf bytes.Buffer
+	if _, err := tag.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	buf.WriteString("mp3 frames")
+
+	info, ok := readReplayGain(Mp3, bytes.NewReader(buf.Bytes()))
+	if !ok || info.TrackGain != -6.5 || info.TrackPeak != 0.988553 || info.HasAlbum {
+		t.Fatalf("info = %+v, ok = %t", info, ok)
+	}
+}
+
+func TestParseVorbisCommentBlock(t *testing.T) {
+	var block bytes.Buffer
+	writeString := func(s string) {
+		_ = binary.Write(&block, binary.LittleEndian, uint32(len(s)))
+		block.WriteString(s)
+	}
+	writeString("reference libFLAC")
+	_ = binary.Write(&block, binary.LittleEndian, uint32(2))
+	writeString("TITLE=song")
+	writeString("REPLAYGAIN_ALBUM_GAIN=+2.10 dB")
+
+	var info replayGainInfo
+	readVorbisReplayGain(parseVorbisCommentBlock(block.Bytes()), &info)
+	if !info.HasAlbum || info.AlbumGain != 2.1 || info.HasTrack {
+		t.Fatalf("info = %+v", info)
+	}
+	if comments := parseVorbisCommentBlock(block.Bytes()[:10]); comments != nil {
+		t.Fatalf("truncated block parsed as %q", comments)
+	}
+}
+
+func TestLoudnessMultiplier(t *testing.T) {
+	info := replayGainInfo{TrackGain: -6, AlbumGain: -3, HasTrack: true, HasAlbum: true}
+	track := &loudnessAnalyzer{mode: types.ReplayGainTrack, target: -18}
+	if got := track.multiplier(info); math.Abs(got-math.Pow(10, -6.0/20)) > 1e-9 {
+		t.Fatalf("track multiplier = %f", got)
+	}
+	album := &loudnessAnalyzer{mode: types.ReplayGainAlbum, target: -14, preamp: 1}
+	if got := album.multiplier(info); math.Abs(got-math.Pow(10, 2.0/20)) > 1e-9 {
+		t.Fatalf("album multiplier = %f, want +2 dB", got)
+	}
+	// 缺少专辑增益时回退到单曲增益
+	if got := album.multiplier(replayGainInfo{TrackGain: -5, HasTrack: true}); math.Abs(got-1) > 1e-9 {
+		t.Fatalf("album fallback multiplier = %f, want 0 dB", got)
+	}
+	// 正增益不会让峰值超过满幅
+	loud := replayGainInfo{TrackGain: 6, TrackPeak: 0.8, HasTrack: true}
+	if got := track.multiplier(loud); got != 1/0.8 {
+		t.Fatalf("clipped multiplier = %f, want %f", got, 1/0.8)
+	}
+}
+
+func TestTrackGainSwitchesAtGaplessBoundary(t *testing.T) {
+	current := &effects.Gain{Streamer: &sampleStreamer{samples: [][2]float64{{1, 1}, {1, 1}}}, Gain: -0.5}
+	next := &effects.Gain{Streamer: &sampleStreamer{samples: [][2]float64{{1, 1}, {1, 1}}}, Gain: 1}
+	buffer := make([][2]float64, 4)
+
+	n, _, switched := streamAcrossBoundary(buffer, current, func() beep.Streamer { return next })
+	if n != 4 || !switched {
+		t.Fatalf("n=%d switched=%v", n, switched)
+	}
+	// 去爆音处理只平滑边界处的直流跳变，最后一个采样应为下一首的完整增益
+	if buffer[0][0] != 0.5 || buffer[1][0] != 0.5 || buffer[3][0] != 2 {
+		t.Fatalf("samples = %v, want each track scaled by its own gain", buffer)
+	}
+}
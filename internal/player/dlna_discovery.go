@@ -0,0 +1,326 @@
+package player
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ssdpMulticastAddr        = "239.255.255.250:1900"
+	ssdpMediaRendererTarget  = "urn:schemas-upnp-org:device:MediaRenderer:1"
+	avTransportServiceType   = "urn:schemas-upnp-org:service:AVTransport:1"
+	renderingControlService  = "urn:schemas-upnp-org:service:RenderingControl:1"
+	defaultDiscoveryDuration = 3 * time.Second
+)
+
+const mSearchTpl = "M-SEARCH * HTTP/1.1\r\n" +
+	"HOST: 239.255.255.250:1900\r\n" +
+	"MAN: \"ssdp:discover\"\r\n" +
+	"MX: %d\r\n" +
+	"ST: %s\r\n" +
+	"\r\n"
+
+// DlnaRenderer 局域网中支持 AVTransport 的 DLNA 渲染设备
+type DlnaRenderer struct {
+	Name     string // friendlyName
+	Model    string
+	Location string // 设备描述 URL，即 player.dlna.deviceUrl
+	USN      string
+}
+
+// Host 设备描述 URL 中的主机地址
+func (r DlnaRenderer) Host() string {
+	if u, err := url.Parse(r.Location); err == nil {
+		return u.Hostname()
+	}
+	return ""
+}
+
+type discoverOptions struct {
+	timeout  time.Duration
+	ssdpAddr string
+	localIP  string
+	client   *http.Client
+}
+
+type DiscoverOption func(*discoverOptions)
+
+// WithDiscoverTimeout 等待设备响应的时长
+func WithDiscoverTimeout(timeout time.Duration) DiscoverOption {
+	return func(o *discoverOptions) {
+		if timeout > 0 {
+			o.timeout = timeout
+		}
+	}
+}
+
+// WithSSDPAddress 替换 SSDP 多播地址，用于测试时指向本地的模拟设备
+func WithSSDPAddress(addr string) DiscoverOption {
+	return func(o *discoverOptions) {
+		o.ssdpAddr = addr
+	}
+}
+
+// WithDiscoverLocalIP 从指定的本机 IP 发送搜索请求，多网卡时用于选择网络
+func WithDiscoverLocalIP(ip string) DiscoverOption {
+	return func(o *discoverOptions) {
+		o.localIP = ip
+	}
+}
+
+// DiscoverDlnaRenderers 通过 SSDP M-SEARCH 搜索局域网中的 MediaRenderer，
+// 并读取设备描述，只返回提供 AVTransport 服务的设备
+func DiscoverDlnaRenderers(ctx context.Context, opts ...DiscoverOption) ([]DlnaRenderer, error) {
+	o := discoverOptions{
+		timeout:  defaultDiscoveryDuration,
+		ssdpAddr: ssdpMulticastAddr,
+		client:   &http.Client{Timeout: 2 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	found, err := searchSSDP(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		renderers []DlnaRenderer
+	)
+	for _, r := range found {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			desc, err := fetchDlnaDescription(ctx, o.client, r.Location)
+			if err != nil {
+				slog.Debug("DLNA: skip renderer", "location", r.Location, "error", err)
+				return
+			}
+			r.Name, r.Model = desc.name, desc.model
+			if r.Name == "" {
+				r.Name = r.Host()
+			}
+			mu.Lock()
+			renderers = append(renderers, r)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	slices.SortFunc(renderers, func(a, b DlnaRenderer) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Location, b.Location)
+	})
+	return renderers, nil
+}
+
+// searchSSDP 发送 M-SEARCH 并收集超时前的响应，按设备描述 URL 去重
+func searchSSDP(ctx context.Context, o discoverOptions) ([]DlnaRenderer, error) {
+	target, err := net.ResolveUDPAddr("udp4", o.ssdpAddr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenPacket("udp4", net.JoinHostPort(o.localIP, "0"))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(o.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	mx := min(max(int(o.timeout/time.Second), 1), 5)
+	request := fmt.Appendf(nil, mSearchTpl, mx, ssdpMediaRendererTarget)
+	// UDP 可能丢包，多发送一次
+	for range 2 {
+		if _, err = conn.WriteTo(request, target); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		renderers []DlnaRenderer
+		seen      = make(map[string]bool)
+		buf       = make([]byte, 2048)
+	)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				break
+			}
+			return renderers, err
+		}
+		r, ok := parseSSDPResponse(buf[:n])
+		if !ok || seen[r.Location] {
+			continue
+		}
+		seen[r.Location] = true
+		renderers = append(renderers, r)
+	}
+	return renderers, ctx.Err()
+}
+
+// parseSSDPResponse 解析 M-SEARCH 的单播响应
+func parseSSDPResponse(data []byte) (DlnaRenderer, bool) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	if err != nil {
+		return DlnaRenderer{}, false
+	}
+	_ = resp.Body.Close()
+	location := resp.Header.Get("Location")
+	if resp.StatusCode != http.StatusOK || location == "" {
+		return DlnaRenderer{}, false
+	}
+	if st := resp.Header.Get("St"); st != "" && st != ssdpMediaRendererTarget && st != "ssdp:all" {
+		return DlnaRenderer{}, false
+	}
+	return DlnaRenderer{Location: location, USN: resp.Header.Get("Usn")}, true
+}
+
+type dlnaDescription struct {
+	name              string
+	model             string
+	avTransport       string
+	avTransportEvents string
+	renderingControl  string
+}
+
+// fetchDlnaDescription 读取设备描述，解析服务控制地址
+func fetchDlnaDescription(ctx context.Context, client *http.Client, location string) (dlnaDescription, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return dlnaDescription{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return dlnaDescription{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return dlnaDescription{}, fmt.Errorf("device description: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return dlnaDescription{}, err
+	}
+	return parseDlnaDescription(location, data)
+}
+
+func parseDlnaDescription(location string, data []byte) (dlnaDescription, error) {
+	var root dlnaRoot
+	if err := xml.Unmarshal(data, &root); err != nil {
+		slog.Debug("DLNA: raw XML", "data", string(data))
+		return dlnaDescription{}, err
+	}
+
+	base, err := url.Parse(location)
+	if err != nil {
+		return dlnaDescription{}, err
+	}
+	if root.URLBase != "" {
+		if u, err := url.Parse(root.URLBase); err == nil {
+			base = u
+		}
+	}
+
+	desc := dlnaDescription{name: root.Device.FriendlyName, model: root.Device.ModelName}
+	// 部分设备把 MediaRenderer 作为嵌入设备声明
+	for _, device := range append([]dlnaDevice{root.Device}, root.Device.Devices...) {
+		for _, svc := range device.Services {
+			controlURL := resolveControlURL(base, svc.ControlURL)
+			switch svc.ServiceType {
+			case avTransportServiceType:
+				if desc.avTransport == "" {
+					desc.avTransport = controlURL
+					desc.avTransportEvents = resolveControlURL(base, svc.EventSubURL)
+				}
+			case renderingControlService:
+				if desc.renderingControl == "" {
+					desc.renderingControl = controlURL
+				}
+			}
+		}
+	}
+	if desc.avTransport == "" {
+		return desc, errors.New("DLNA: AVTransport service not found or invalid")
+	}
+	return desc, nil
+}
+
+func resolveControlURL(base *url.URL, controlURL string) string {
+	if controlURL == "" {
+		return ""
+	}
+	ref, err := url.Parse(controlURL)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// DetectLocalIP 返回访问 target（设备描述 URL）时使用的本机 IP，target 为空时按 SSDP 多播路由选择
+func DetectLocalIP(target string) (string, error) {
+	addr := ssdpMulticastAddr
+	if target != "" {
+		u, err := url.Parse(target)
+		if err != nil {
+			return "", err
+		}
+		port := u.Port()
+		if port == "" {
+			port = "80"
+		}
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+	// UDP 的 Dial 不会发送数据，只用于查询路由
+	if conn, err := net.Dial("udp4", addr); err == nil {
+		defer conn.Close()
+		if local, ok := conn.LocalAddr().(*net.UDPAddr); ok && !local.IP.IsUnspecified() {
+			return local.IP.String(), nil
+		}
+	}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", err
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, _ := iface.Addrs()
+		for _, a := range addrs {
+			if ipNet, ok := a.(*net.IPNet); ok && ipNet.IP.To4() != nil && ipNet.IP.IsPrivate() {
+				return ipNet.IP.String(), nil
+			}
+		}
+	}
+	return "", errors.New("DLNA: no LAN address found, please set player.dlna.localIP")
+}
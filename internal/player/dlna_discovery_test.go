@@ -0,0 +1,290 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+const fakeRendererDescription = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:MediaRenderer:1</deviceType>
+    <friendlyName>%s</friendlyName>
+    <modelName>Fake Speaker</modelName>
+    <serviceList>
+      <service>
+        <serviceType>urn:schemas-upnp-org:service:AVTransport:1</serviceType>
+        <controlURL>/upnp/control/AVTransport</controlURL>
+        <eventSubURL>/upnp/event/AVTransport</eventSubURL>
+      </service>
+      <service>
+        <serviceType>urn:schemas-upnp-org:service:RenderingControl:1</serviceType>
+        <controlURL>upnp/control/RenderingControl</controlURL>
+      </service>
+    </serviceList>
+  </device>
+</root>`
+
+// fakeRenderer 模拟设备描述和 SOAP 控制接口，记录收到的动作
+type fakeRenderer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	actions  []string
+	bodies   map[string]string
+	reject   map[string]bool // 返回错误的动作，模拟不支持的设备
+	callback string          // GENA 订阅的回调地址
+}
+
+func newFakeRenderer(t *testing.T, name string) *fakeRenderer {
+	r := &fakeRenderer{bodies: make(map[string]string), reject: make(map[string]bool)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/description.xml", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, fakeRendererDescription, name)
+	})
+	mux.HandleFunc("/upnp/control/", func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		action := req.Header.Get("SOAPAction")
+		action = action[strings.Index(action, "#")+1 : len(action)-1]
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.actions = append(r.actions, action)
+		r.bodies[action] = string(body)
+		if r.reject[action] {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	mux.HandleFunc("/upnp/event/AVTransport", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "SUBSCRIBE" {
+			return
+		}
+		r.mu.Lock()
+		r.callback = strings.Trim(req.Header.Get("CALLBACK"), "<>")
+		r.mu.Unlock()
+		w.Header().Set("SID", "uuid:fake-subscription")
+		w.Header().Set("TIMEOUT", "Second-1800")
+	})
+	r.Server = httptest.NewServer(mux)
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *fakeRenderer) Actions() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.actions...)
+}
+
+func (r *fakeRenderer) Body(action string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bodies[action]
+}
+
+func (r *fakeRenderer) Reject(action string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reject[action] = true
+}
+
+func (r *fakeRenderer) Callback() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.callback
+}
+
+// fakeSSDPResponder 对收到的 M-SEARCH 回复给定的设备描述地址
+func fakeSSDPResponder(t *testing.T, locations ...string) string {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if !strings.HasPrefix(string(buf[:n]), "M-SEARCH") || !strings.Contains(string(buf[:n]), ssdpMediaRendererTarget) {
+				continue
+			}
+			for i, location := range locations {
+				resp := fmt.Sprintf("HTTP/1.1 200 OK\r\nCACHE-CONTROL: max-age=1800\r\nLOCATION: %s\r\nST: %s\r\nUSN: uuid:%d::%s\r\n\r\n",
+					location, ssdpMediaRendererTarget, i, ssdpMediaRendererTarget)
+				_, _ = conn.WriteTo([]byte(resp), addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestDiscoverDlnaRenderers(t *testing.T) {
+	livingRoom := newFakeRenderer(t, "Living Room")
+	bedroom := newFakeRenderer(t, "Bedroom")
+	notRenderer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `<root><device><friendlyName>NAS</friendlyName></device></root>`)
+	}))
+	defer notRenderer.Close()
+
+	addr := fakeSSDPResponder(t,
+		livingRoom.URL+"/description.xml",
+		bedroom.URL+"/description.xml",
+		notRenderer.URL+"/description.xml")
+
+	renderers, err := DiscoverDlnaRenderers(context.Background(),
+		WithSSDPAddress(addr), WithDiscoverTimeout(300*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// M-SEARCH 发送了两次，重复的响应应被去重；不提供 AVTransport 的设备被忽略
+	if len(renderers) != 2 {
+		t.Fatalf("renderers = %+v, want 2", renderers)
+	}
+	if renderers[0].Name != "Bedroom" || renderers[1].Name != "Living Room" {
+		t.Fatalf("renderers not sorted by name: %+v", renderers)
+	}
+	if renderers[0].Model != "Fake Speaker" || renderers[0].Host() != "127.0.0.1" || renderers[0].USN == "" {
+		t.Fatalf("renderer = %+v", renderers[0])
+	}
+}
+
+func TestParseDlnaDescriptionResolvesControlURLs(t *testing.T) {
+	// MediaRenderer 作为嵌入设备声明，URLBase 指向另一个端口
+	data := `<root><URLBase>http://10.0.0.2:8080/dmr/</URLBase><device><friendlyName>TV</friendlyName>
+<deviceList><device><serviceList>
+<service><serviceType>urn:schemas-upnp-org:service:AVTransport:1</serviceType><controlURL>avt/control</controlURL></service>
+</serviceList></device></deviceList></device></root>`
+	desc, err := parseDlnaDescription("http://10.0.0.2:49152/description.xml", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.name != "TV" || desc.avTransport != "http://10.0.0.2:8080/dmr/avt/control" || desc.renderingControl != "" {
+		t.Fatalf("desc = %+v", desc)
+	}
+}
+
+func TestParseSSDPResponseIgnoresOtherTargets(t *testing.T) {
+	resp := "HTTP/1.1 200 OK\r\nLOCATION: http://10.0.0.3/desc.xml\r\nST: urn:schemas-upnp-org:device:MediaServer:1\r\n\r\n"
+	if _, ok := parseSSDPResponse([]byte(resp)); ok {
+		t.Fatal("MediaServer response accepted")
+	}
+	if _, ok := parseSSDPResponse([]byte("NOTIFY * HTTP/1.1\r\n\r\n")); ok {
+		t.Fatal("NOTIFY accepted as a search response")
+	}
+}
+
+func TestDlnaSwitchRendererResumesPlayback(t *testing.T) {
+	first := newFakeRenderer(t, "First")
+	second := newFakeRenderer(t, "Second")
+
+	p, err := NewDlnaPlayer(first.URL+"/description.xml", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	dlna := p.(*dlnaPlayer)
+
+	if cur, ok := dlna.Renderer(); !ok || cur.Name != "First" {
+		t.Fatalf("renderer = %+v, %v", cur, ok)
+	}
+	p.Play(URLMusic{URL: "http://music.example/song.mp3", Song: structs.Song{Id: 1}})
+	dlna.curPos = 42 * time.Second
+
+	if err := dlna.SwitchRenderer(second.URL + "/description.xml"); err != nil {
+		t.Fatal(err)
+	}
+	if cur, _ := dlna.Renderer(); cur.Name != "Second" {
+		t.Fatalf("renderer after switch = %+v", cur)
+	}
+	if actions := first.Actions(); actions[len(actions)-1] != "Stop" {
+		t.Fatalf("old renderer actions = %v, want playback stopped", actions)
+	}
+	want := []string{"SetAVTransportURI", "Play", "Seek"}
+	if actions := second.Actions(); strings.Join(actions, ",") != strings.Join(want, ",") {
+		t.Fatalf("new renderer actions = %v, want %v", actions, want)
+	}
+	if p.State() != types.Playing {
+		t.Fatalf("state = %v", p.State())
+	}
+
+	if err := dlna.SwitchRenderer("http://127.0.0.1:1/description.xml"); err == nil {
+		t.Fatal("switching to an unreachable renderer succeeded")
+	}
+	if cur, _ := dlna.Renderer(); cur.Name != "Second" {
+		t.Fatalf("failed switch changed the renderer to %+v", cur)
+	}
+}
+
+// startAutoConnect 以配置启动时的方式在后台搜索设备
+func startAutoConnect(t *testing.T, preferred, ssdpAddr string) *dlnaPlayer {
+	t.Helper()
+	p := newDlnaPlayer(preferred, "127.0.0.1")
+	p.deviceURL = ""
+	p.discoverTimeout = 300 * time.Millisecond
+	if err := p.startHTTPServer(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.discoverCancel = cancel
+	t.Cleanup(p.Close)
+	go p.worker()
+	go p.autoConnect(ctx, preferred, WithSSDPAddress(ssdpAddr))
+	return p
+}
+
+func waitRenderer(t *testing.T, p *dlnaPlayer) DlnaRenderer {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if cur, ok := p.Renderer(); ok {
+			return cur
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("no renderer connected")
+	return DlnaRenderer{}
+}
+
+func TestDlnaAutoConnectPrefersConfiguredRenderer(t *testing.T) {
+	bedroom := newFakeRenderer(t, "Bedroom")
+	livingRoom := newFakeRenderer(t, "Living Room")
+	addr := fakeSSDPResponder(t, bedroom.URL+"/description.xml", livingRoom.URL+"/description.xml")
+
+	p := startAutoConnect(t, livingRoom.URL+"/description.xml", addr)
+	if cur := waitRenderer(t, p); cur.Name != "Living Room" {
+		t.Fatalf("renderer = %+v, want the configured one", cur)
+	}
+
+	// 配置的设备不可用时连接搜索到的设备
+	p = startAutoConnect(t, "http://127.0.0.1:1/description.xml", addr)
+	if cur := waitRenderer(t, p); cur.Name != "Bedroom" {
+		t.Fatalf("renderer = %+v, want the first discovered one", cur)
+	}
+}
+
+func TestDlnaAutoConnectReportsNoRenderer(t *testing.T) {
+	p := startAutoConnect(t, "", fakeSSDPResponder(t))
+	select {
+	case s := <-p.StateChan():
+		if s != types.Interrupted {
+			t.Fatalf("state = %v, want Interrupted", s)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no state sent when no renderer was found")
+	}
+	if _, ok := p.Renderer(); ok {
+		t.Fatal("renderer connected")
+	}
+}
@@ -0,0 +1,103 @@
+package player
+
+import (
+	"fmt"
+	"html"
+	"log/slog"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+const setNextAvTransportURIBody = `<u:SetNextAVTransportURI xmlns:u="urn:schemas-upnp-org:service:AVTransport:1">
+  <InstanceID>0</InstanceID>
+  <NextURI>%s</NextURI>
+  <NextURIMetaData></NextURIMetaData>
+</u:SetNextAVTransportURI>`
+
+// 通过 SetNextAVTransportURI 让设备在当前歌曲结束时直接切换到下一首。
+// 设备不支持该动作时退回到停止后再设置新地址的方式
+
+// Preload 将下一首排入设备的播放队列
+func (p *dlnaPlayer) Preload(music URLMusic) {
+	result := make(chan any)
+	p.cmdQueue <- command{cmd: cmdPreload, param: music, result: result}
+	<-result
+}
+
+// CancelPreload 清除设备上排队的下一首
+func (p *dlnaPlayer) CancelPreload() {
+	result := make(chan any)
+	p.cmdQueue <- command{cmd: cmdCancelPreload, result: result}
+	<-result
+}
+
+func (p *dlnaPlayer) GaplessTransitionChan() <-chan GaplessTransition {
+	return p.transitionChan
+}
+
+// queueNext 在 worker 中执行 SetNextAVTransportURI
+func (p *dlnaPlayer) queueNext(music URLMusic) {
+	if p.nextUnsupported || p.controlURL == "" || p.state == types.Stopped {
+		return
+	}
+	uri := p.shareMusic(music)
+	if uri == p.audioURL {
+		// 单曲循环时无法通过地址区分是否已切换，交给常规的停止后重新播放
+		return
+	}
+	if _, err := p.doSOAP("AVTransport", "SetNextAVTransportURI", fmt.Sprintf(setNextAvTransportURIBody, html.EscapeString(uri))); err != nil {
+		slog.Info("DLNA: renderer does not support SetNextAVTransportURI, gapless disabled", "error", err)
+		p.nextUnsupported = true
+		p.unshareMusic(music.Id)
+		return
+	}
+	slog.Info("DLNA: queued next track", "audioURL", uri)
+	p.next, p.nextURL = &music, uri
+}
+
+// cancelNext 清除设备上排队的下一首
+func (p *dlnaPlayer) cancelNext() {
+	if p.next == nil {
+		return
+	}
+	_, _ = p.doSOAP("AVTransport", "SetNextAVTransportURI", fmt.Sprintf(setNextAvTransportURIBody, ""))
+	p.clearNext()
+}
+
+// clearNext 丢弃排队的下一首
+func (p *dlnaPlayer) clearNext() {
+	if p.next == nil {
+		return
+	}
+	if p.next.Id != p.music.Id {
+		p.unshareMusic(p.next.Id)
+	}
+	p.next, p.nextURL = nil, ""
+}
+
+// checkTrackURI 设备当前播放的地址变为排队的下一首时完成无缝切换
+func (p *dlnaPlayer) checkTrackURI(uri string) {
+	if p.next == nil || uri == "" || uri != p.nextURL {
+		return
+	}
+	played := p.PlayedTime()
+	prev, next := p.music, *p.next
+	p.next, p.nextURL = nil, ""
+	if prev.Id != next.Id {
+		p.unshareMusic(prev.Id)
+	}
+
+	p.musicMu.Lock()
+	p.music, p.audioURL, p.audioDur = next, uri, next.Duration
+	p.musicMu.Unlock()
+	p.curPos, p.startTime, p.pausedTime = 0, time.Now(), 0
+	p.stopCheck = nil
+	slog.Info("DLNA: renderer advanced to queued track", "audioURL", uri)
+
+	select {
+	case p.transitionChan <- GaplessTransition{Music: next, PlayedTime: played}:
+	default:
+		slog.Warn("DLNA: gapless transition channel full, drop transition")
+	}
+}
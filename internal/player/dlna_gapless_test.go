@@ -0,0 +1,114 @@
+package player
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+func lastChangeNotify(inner string) string {
+	event := `<Event xmlns="urn:schemas-upnp-org:metadata-1-0/AVT/"><InstanceID val="0">` + inner + `</InstanceID></Event>`
+	return `<?xml version="1.0"?><e:propertyset xmlns:e="urn:schemas-upnp-org:event-1-0"><e:property><LastChange>` +
+		html.EscapeString(event) + `</LastChange></e:property></e:propertyset>`
+}
+
+func sendNotify(t *testing.T, callback, sid, body string) {
+	req, err := http.NewRequest("NOTIFY", callback, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("SID", sid)
+	req.Header.Set("NT", "upnp:event")
+	req.Header.Set("NTS", "upnp:propchange")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("NOTIFY status = %s", resp.Status)
+	}
+}
+
+func TestParseGENANotify(t *testing.T) {
+	ev, err := parseGENANotify([]byte(lastChangeNotify(
+		`<TransportState val="PLAYING"/><CurrentTrackURI val="http://music.example/2.mp3?a=1&amp;b=2"/>`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.transportState == nil || *ev.transportState != "PLAYING" {
+		t.Fatalf("transport state = %v", ev.transportState)
+	}
+	if ev.currentTrackURI == nil || *ev.currentTrackURI != "http://music.example/2.mp3?a=1&b=2" {
+		t.Fatalf("track uri = %v", ev.currentTrackURI)
+	}
+	if ev.avTransportURI != nil {
+		t.Fatal("unchanged variable reported")
+	}
+}
+
+func TestDlnaGaplessTransitionFromEvent(t *testing.T) {
+	renderer := newFakeRenderer(t, "Speaker")
+	p, err := NewDlnaPlayer(renderer.URL+"/description.xml", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	gapless := p.(GaplessPlayer)
+	if renderer.Callback() == "" {
+		t.Fatal("player did not subscribe to AVTransport events")
+	}
+
+	p.Play(URLMusic{URL: "http://music.example/1.mp3", Song: structs.Song{Id: 1, Duration: time.Minute}})
+	next := URLMusic{URL: "http://music.example/2.mp3?a=1&b=2", Song: structs.Song{Id: 2, Duration: 2 * time.Minute}}
+	gapless.Preload(next)
+	if body := renderer.Body("SetNextAVTransportURI"); !strings.Contains(body, "2.mp3?a=1&amp;b=2") {
+		t.Fatalf("SetNextAVTransportURI body = %q", body)
+	}
+
+	// 其他订阅的事件被忽略
+	sendNotify(t, renderer.Callback(), "uuid:other", lastChangeNotify(`<CurrentTrackURI val="`+html.EscapeString(next.URL)+`"/>`))
+	sendNotify(t, renderer.Callback(), "uuid:fake-subscription", lastChangeNotify(`<CurrentTrackURI val="`+html.EscapeString(next.URL)+`"/>`))
+	select {
+	case transition := <-gapless.GaplessTransitionChan():
+		if transition.Music.Id != 2 {
+			t.Fatalf("transition to %d", transition.Music.Id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no gapless transition after the renderer advanced")
+	}
+	if cur := p.CurMusic(); cur.Id != 2 || cur.Duration != 2*time.Minute {
+		t.Fatalf("current music = %+v", cur)
+	}
+}
+
+func TestDlnaGaplessFallsBackWhenUnsupported(t *testing.T) {
+	renderer := newFakeRenderer(t, "Old TV")
+	renderer.Reject("SetNextAVTransportURI")
+	p, err := NewDlnaPlayer(renderer.URL+"/description.xml", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	gapless := p.(GaplessPlayer)
+
+	p.Play(URLMusic{URL: "http://music.example/1.mp3", Song: structs.Song{Id: 1}})
+	for i := range 2 {
+		gapless.Preload(URLMusic{URL: fmt.Sprintf("http://music.example/%d.mp3", i+2), Song: structs.Song{Id: int64(i + 2)}})
+	}
+	// 设备拒绝后不再尝试排队，歌曲结束时按常规方式切换
+	actions := renderer.Actions()
+	if n := len(slices.DeleteFunc(actions, func(a string) bool { return a != "SetNextAVTransportURI" })); n != 1 {
+		t.Fatalf("SetNextAVTransportURI sent %d times", n)
+	}
+	p.Play(URLMusic{URL: "http://music.example/2.mp3", Song: structs.Song{Id: 2}})
+	if cur := p.CurMusic(); cur.Id != 2 {
+		t.Fatalf("current music = %+v", cur)
+	}
+}
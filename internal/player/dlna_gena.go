@@ -0,0 +1,249 @@
+package player
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+// UPnP GENA 事件订阅：设备通过 NOTIFY 主动推送 AVTransport 的 LastChange，
+// 订阅成功后不再轮询播放状态，只轮询播放进度
+
+const (
+	genaEventsPath      = "/dlna/events"
+	genaTimeout         = 30 * time.Minute
+	genaRenewBefore     = time.Minute
+	dlnaStopConfirmWait = 1500 * time.Millisecond
+)
+
+type genaSubscription struct {
+	sid     string
+	expires time.Time
+}
+
+// dlnaEvent 一次 LastChange 通知中 InstanceID 0 的变化，未变化的字段为 nil
+type dlnaEvent struct {
+	sid             string
+	transportState  *string
+	currentTrackURI *string
+	avTransportURI  *string
+}
+
+type lastChangeValue struct {
+	Val string `xml:"val,attr"`
+}
+
+type lastChangeEvent struct {
+	Instances []struct {
+		ID              string           `xml:"val,attr"`
+		TransportState  *lastChangeValue `xml:"TransportState"`
+		CurrentTrackURI *lastChangeValue `xml:"CurrentTrackURI"`
+		AVTransportURI  *lastChangeValue `xml:"AVTransportURI"`
+	} `xml:"InstanceID"`
+}
+
+type genaPropertySet struct {
+	LastChange []string `xml:"property>LastChange"`
+}
+
+// parseGENANotify 解析 NOTIFY 请求体，LastChange 的值本身是转义后的 XML
+func parseGENANotify(body []byte) (dlnaEvent, error) {
+	var set genaPropertySet
+	if err := xml.Unmarshal(body, &set); err != nil {
+		return dlnaEvent{}, err
+	}
+	var ev dlnaEvent
+	for _, lastChange := range set.LastChange {
+		var change lastChangeEvent
+		if err := xml.Unmarshal([]byte(lastChange), &change); err != nil {
+			return dlnaEvent{}, err
+		}
+		for _, instance := range change.Instances {
+			if instance.ID != "0" {
+				continue
+			}
+			if instance.TransportState != nil {
+				ev.transportState = &instance.TransportState.Val
+			}
+			if instance.CurrentTrackURI != nil {
+				ev.currentTrackURI = &instance.CurrentTrackURI.Val
+			}
+			if instance.AVTransportURI != nil {
+				ev.avTransportURI = &instance.AVTransportURI.Val
+			}
+		}
+	}
+	return ev, nil
+}
+
+// serveGENANotify 接收设备推送的事件，交给 worker 处理
+func (p *dlnaPlayer) serveGENANotify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "NOTIFY" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	ev, err := parseGENANotify(body)
+	if err != nil {
+		slog.Debug("DLNA: invalid event", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	ev.sid = r.Header.Get("SID")
+	select {
+	case p.eventChan <- ev:
+	default:
+		slog.Warn("DLNA: event queue full, drop event")
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// subscribe 订阅当前设备的 AVTransport 事件，失败时退回轮询
+func (p *dlnaPlayer) subscribe() {
+	p.unsubscribe()
+	if p.eventSubURL == "" || p.httpServer == nil {
+		return
+	}
+	req, err := http.NewRequest("SUBSCRIBE", p.eventSubURL, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("CALLBACK", fmt.Sprintf("<http://%s:%d%s>", p.localIP, p.httpPort, genaEventsPath))
+	req.Header.Set("NT", "upnp:event")
+	req.Header.Set("TIMEOUT", fmt.Sprintf("Second-%d", int(genaTimeout.Seconds())))
+	sub, err := p.doGENA(req)
+	if err != nil {
+		slog.Info("DLNA: event subscription unavailable, polling transport state", "error", err)
+		return
+	}
+	p.sub = sub
+	slog.Info("DLNA: subscribed to AVTransport events", "expires", sub.expires)
+}
+
+// renewSubscription 在订阅过期前续订，续订失败时重新订阅
+func (p *dlnaPlayer) renewSubscription() {
+	if p.sub.sid == "" || time.Until(p.sub.expires) > genaRenewBefore {
+		return
+	}
+	req, err := http.NewRequest("SUBSCRIBE", p.eventSubURL, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("SID", p.sub.sid)
+	req.Header.Set("TIMEOUT", fmt.Sprintf("Second-%d", int(genaTimeout.Seconds())))
+	sub, err := p.doGENA(req)
+	if err != nil {
+		slog.Warn("DLNA: failed to renew event subscription", "error", err)
+		p.sub = genaSubscription{}
+		p.subscribe()
+		return
+	}
+	p.sub = sub
+}
+
+func (p *dlnaPlayer) unsubscribe() {
+	if p.sub.sid == "" {
+		return
+	}
+	sid := p.sub.sid
+	p.sub = genaSubscription{}
+	req, err := http.NewRequest("UNSUBSCRIBE", p.eventSubURL, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("SID", sid)
+	if resp, err := p.httpClient.Do(req); err == nil {
+		_ = resp.Body.Close()
+	}
+}
+
+func (p *dlnaPlayer) doGENA(req *http.Request) (genaSubscription, error) {
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return genaSubscription{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return genaSubscription{}, fmt.Errorf("subscribe: %s", resp.Status)
+	}
+	sid := resp.Header.Get("SID")
+	if sid == "" {
+		return genaSubscription{}, errors.New("subscribe: missing SID")
+	}
+	timeout := genaTimeout
+	if seconds, ok := strings.CutPrefix(resp.Header.Get("TIMEOUT"), "Second-"); ok {
+		if n, err := strconv.Atoi(seconds); err == nil && n > 0 {
+			timeout = time.Duration(n) * time.Second
+		}
+	}
+	return genaSubscription{sid: sid, expires: time.Now().Add(timeout)}, nil
+}
+
+// handleEvent 在 worker 中处理设备推送的状态变化
+func (p *dlnaPlayer) handleEvent(ev dlnaEvent) {
+	if ev.sid == "" || ev.sid != p.sub.sid {
+		return
+	}
+	for _, uri := range []*string{ev.currentTrackURI, ev.avTransportURI} {
+		if uri != nil {
+			p.checkTrackURI(*uri)
+		}
+	}
+	if ev.transportState != nil {
+		p.applyTransportState(*ev.transportState)
+	}
+}
+
+// applyTransportState 同步设备上的播放状态（包括在设备上直接操作的暂停、播放）
+func (p *dlnaPlayer) applyTransportState(state string) {
+	switch state {
+	case "STOPPED", "NO_MEDIA_PRESENT":
+		if p.state == types.Stopped {
+			return
+		}
+		// 设置新地址和切换到下一首时设备会短暂报告 STOPPED，事件也可能晚于命令到达，稍后查询确认
+		p.stopCheck = time.After(dlnaStopConfirmWait)
+	case "PLAYING":
+		p.stopCheck = nil
+		if p.state == types.Paused {
+			p.pausedTime += time.Since(p.pauseStart)
+			p.state = types.Playing
+			p.sendState()
+		}
+	case "PAUSED_PLAYBACK":
+		if p.state == types.Playing {
+			p.pauseStart = time.Now()
+			p.state = types.Paused
+			p.sendState()
+		}
+	}
+}
+
+// confirmStopped 收到 STOPPED 事件后确认设备是否真的停止，或者已经切到排队的下一首
+func (p *dlnaPlayer) confirmStopped() {
+	p.stopCheck = nil
+	if p.state == types.Stopped {
+		return
+	}
+	if info, err := p.getPositionInfo(); err == nil {
+		p.checkTrackURI(info.trackURI)
+	}
+	if state, err := p.getTransportInfo(); err != nil || (state != "STOPPED" && state != "NO_MEDIA_PRESENT") {
+		return
+	}
+	p.clearNext()
+	p.state = types.Stopped
+	p.sendState()
+}
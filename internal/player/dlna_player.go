@@ -4,6 +4,7 @@ package player
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
@@ -11,11 +12,13 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/go-musicfox/go-musicfox/internal/configs"
 	"github.com/go-musicfox/go-musicfox/internal/types"
 )
 
@@ -79,6 +82,11 @@ const (
 	cmdStop
 	cmdSeek
 	cmdSetVolume
+	cmdSwitchRenderer
+	cmdAutoConnect
+	cmdNoRenderer
+	cmdPreload
+	cmdCancelPreload
 )
 
 type command struct {
@@ -88,15 +96,21 @@ type command struct {
 }
 
 type dlnaPlayer struct {
+	deviceMu            sync.RWMutex
 	deviceURL           string
+	deviceName          string
 	controlURL          string
 	renderingControlURL string
+	eventSubURL         string
+	musicMu             sync.RWMutex
 	audioURL            string
 	audioDur            time.Duration
+	music               URLMusic
 	httpClient          *http.Client
 	state               types.State
 	stateChan           chan types.State
 	closed              chan struct{}
+	closeOnce           sync.Once
 	cmdQueue            chan command
 
 	curPos   time.Duration
@@ -105,6 +119,7 @@ type dlnaPlayer struct {
 	httpServer *http.Server
 	httpPort   int
 	localIP    string
+	autoIP     bool // 未配置 localIP，按当前设备所在网络自动选择
 	fileMap    map[int64]string
 	fileMapMu  sync.RWMutex
 
@@ -114,12 +129,28 @@ type dlnaPlayer struct {
 	wasEverPlayed bool
 
 	cachedVolume int
+	polls        int
+
+	discoverTimeout time.Duration
+	discoverCancel  context.CancelFunc // 取消启动时的后台搜索
+
+	// 无缝播放
+	next            *URLMusic
+	nextURL         string
+	nextUnsupported bool
+	transitionChan  chan GaplessTransition
+
+	// GENA 事件订阅
+	sub       genaSubscription
+	eventChan chan dlnaEvent
+	stopCheck <-chan time.Time
 }
 
-func NewDlnaPlayer(deviceURL, localIP string) (Player, error) {
+func newDlnaPlayer(deviceURL, localIP string) *dlnaPlayer {
 	p := &dlnaPlayer{
 		deviceURL:  deviceURL,
 		localIP:    localIP,
+		autoIP:     localIP == "",
 		httpClient: &http.Client{Timeout: 1 * time.Second},
 		state:      types.Stopped,
 		stateChan:  make(chan types.State, 10),
@@ -127,7 +158,19 @@ func NewDlnaPlayer(deviceURL, localIP string) (Player, error) {
 		timeChan:   make(chan time.Duration, 1),
 		fileMap:    make(map[int64]string),
 		cmdQueue:   make(chan command, 10),
+
+		discoverTimeout: defaultDiscoveryDuration,
+		transitionChan:  make(chan GaplessTransition, 1),
+		eventChan:       make(chan dlnaEvent, 16),
 	}
+	if p.autoIP {
+		p.localIP, _ = DetectLocalIP(deviceURL)
+	}
+	return p
+}
+
+func NewDlnaPlayer(deviceURL, localIP string) (Player, error) {
+	p := newDlnaPlayer(deviceURL, localIP)
 	if err := p.startHTTPServer(); err != nil {
 		return nil, err
 	}
@@ -139,6 +182,79 @@ func NewDlnaPlayer(deviceURL, localIP string) (Player, error) {
 	return p, nil
 }
 
+// newDlnaPlayerFromConfig 在后台连接配置的设备，不可用时搜索局域网中的设备，
+// 仍找不到时以未连接状态运行，之后可在菜单中选择设备
+func newDlnaPlayerFromConfig(cfg configs.DlnaConfig) (Player, error) {
+	p := newDlnaPlayer(cfg.DeviceUrl, cfg.LocalIP)
+	if cfg.DiscoverySeconds > 0 {
+		p.discoverTimeout = time.Duration(cfg.DiscoverySeconds) * time.Second
+	}
+	if err := p.startHTTPServer(); err != nil {
+		return nil, err
+	}
+
+	// 连接成功前不显示为已连接
+	p.deviceURL = ""
+	ctx, cancel := context.WithCancel(context.Background())
+	p.discoverCancel = cancel
+	go p.worker()
+	go p.autoConnect(ctx, cfg.DeviceUrl)
+	return p, nil
+}
+
+// autoConnect 优先连接 preferred，不可用时搜索设备，配置的设备出现在结果中时仍优先选择它；
+// 连接交给 worker 执行，期间用户已在菜单中选择了设备时不再切换
+func (p *dlnaPlayer) autoConnect(ctx context.Context, preferred string, opts ...DiscoverOption) {
+	if preferred != "" && p.connectFound(ctx, preferred) == nil {
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	renderers, err := p.discoverRenderers(ctx, opts...)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		slog.Warn("DLNA: renderer discovery failed", "error", err)
+	}
+	if i := slices.IndexFunc(renderers, func(r DlnaRenderer) bool { return r.Location == preferred }); i > 0 {
+		renderers[0], renderers[i] = renderers[i], renderers[0]
+	}
+	for _, r := range renderers {
+		if p.connectFound(ctx, r.Location) == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+
+	slog.Warn("DLNA: no renderer connected, choose one from the menu")
+	select {
+	case p.cmdQueue <- command{cmd: cmdNoRenderer}:
+	case <-ctx.Done():
+	}
+}
+
+// connectFound 让 worker 连接自动找到的设备
+func (p *dlnaPlayer) connectFound(ctx context.Context, location string) error {
+	result := make(chan any, 1)
+	select {
+	case p.cmdQueue <- command{cmd: cmdAutoConnect, param: location, result: result}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case r := <-result:
+		err, _ := r.(error)
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 type dlnaRoot struct {
 	XMLName xml.Name   `xml:"root"`
 	URLBase string     `xml:"URLBase"`
@@ -146,66 +262,52 @@ type dlnaRoot struct {
 }
 
 type dlnaDevice struct {
-	Services []dlnaService `xml:"serviceList>service"`
+	FriendlyName string        `xml:"friendlyName"`
+	ModelName    string        `xml:"modelName"`
+	Services     []dlnaService `xml:"serviceList>service"`
+	Devices      []dlnaDevice  `xml:"deviceList>device"`
 }
 
 type dlnaService struct {
 	ServiceType string `xml:"serviceType"`
 	ControlURL  string `xml:"controlURL"`
+	EventSubURL string `xml:"eventSubURL"`
 }
 
 func (p *dlnaPlayer) initControlURL() error {
-	slog.Debug("DLNA: fetching device description", "url", p.deviceURL)
-	resp, err := p.httpClient.Get(p.deviceURL)
-	if err != nil {
-		slog.Error("DLNA: failed to fetch device description", "error", err)
-		return err
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			slog.Error("DLNA: failed to close response body", "error", err)
-		}
-	}()
-	xmlData, err := io.ReadAll(resp.Body)
+	return p.connect(p.deviceURL)
+}
+
+// connect 读取设备描述并切换到该设备，在 worker 启动前或 worker 中调用
+func (p *dlnaPlayer) connect(deviceURL string) error {
+	slog.Debug("DLNA: fetching device description", "url", deviceURL)
+	desc, err := fetchDlnaDescription(context.Background(), p.httpClient, deviceURL)
 	if err != nil {
-		slog.Error("DLNA: failed to read device description", "error", err)
+		slog.Error("DLNA: failed to load device description", "url", deviceURL, "error", err)
 		return err
 	}
-
-	var r dlnaRoot
-	if err := xml.Unmarshal(xmlData, &r); err != nil {
-		slog.Error("DLNA: failed to parse device description", "error", err)
-		slog.Debug("DLNA: raw XML", "data", string(xmlData))
-		return err
+	slog.Info("DLNA: found AVTransport control URL", "url", desc.avTransport)
+	if desc.renderingControl == "" {
+		slog.Error("DLNA: RenderingControl service not found")
+	} else {
+		slog.Info("DLNA: found RenderingControl control URL", "url", desc.renderingControl)
 	}
 
-	base := r.URLBase
-	if base == "" {
-		base = p.deviceURL[:len(p.deviceURL)-len("/description.xml")] + "/"
-	}
-	base = strings.TrimRight(base, "/")
-	slog.Debug("DLNA: using base URL", "base", base)
+	p.unsubscribe()
+	p.deviceMu.Lock()
+	p.deviceURL, p.deviceName = deviceURL, desc.name
+	p.deviceMu.Unlock()
+	p.controlURL, p.renderingControlURL, p.eventSubURL = desc.avTransport, desc.renderingControl, desc.avTransportEvents
+	p.nextUnsupported = false
 
-	for _, svc := range r.Device.Services {
-		slog.Debug("DLNA: found service", "type", svc.ServiceType, "controlURL", svc.ControlURL)
-		controlURL := p.normalizeURL(base, svc.ControlURL)
-		switch svc.ServiceType {
-		case "urn:schemas-upnp-org:service:AVTransport:1":
-			p.controlURL = controlURL
-			slog.Info("DLNA: found AVTransport control URL", "url", controlURL)
-		case "urn:schemas-upnp-org:service:RenderingControl:1":
-			p.renderingControlURL = controlURL
-			slog.Info("DLNA: found RenderingControl control URL", "url", controlURL)
+	if p.autoIP && p.httpServer != nil {
+		// 新设备可能在另一个网络中，需要换用对应网卡的地址
+		if ip, err := DetectLocalIP(deviceURL); err == nil && ip != p.localIP {
+			p.localIP = ip
+			p.restartHTTPServer()
 		}
 	}
-
-	if p.controlURL == "" {
-		return errors.New("DLNA: AVTransport service not found or invalid")
-	}
-	if p.renderingControlURL == "" {
-		slog.Error("DLNA: RenderingControl service not found")
-	}
-
+	p.subscribe()
 	return nil
 }
 
@@ -216,31 +318,53 @@ func (p *dlnaPlayer) worker() {
 		select {
 		case <-ticker.C:
 			p.pollStateTask()
+			p.renewSubscription()
 		case cmd := <-p.cmdQueue:
 			p.executeCmd(cmd)
+		case ev := <-p.eventChan:
+			p.handleEvent(ev)
+		case <-p.stopCheck:
+			p.confirmStopped()
 		case <-p.closed:
+			p.unsubscribe()
 			return
 		}
 	}
 }
 
 func (p *dlnaPlayer) pollStateTask() {
-	state, _ := p.getTransportInfo()
-	if state == "STOPPED" || state == "NO_MEDIA_PRESENT" {
-		p.state = types.Stopped
-		p.sendState()
+	if p.controlURL == "" {
 		return
 	}
-	curPos, _, _ := p.getPositionInfo()
-	if curPos > 0 {
-		p.curPos = curPos
+	p.polls++
+	subscribed := p.sub.sid != ""
+	// 订阅了事件时播放状态由设备推送，不再轮询
+	if !subscribed {
+		state, _ := p.getTransportInfo()
+		if state == "STOPPED" || state == "NO_MEDIA_PRESENT" {
+			if p.next != nil {
+				p.confirmStopped()
+				return
+			}
+			p.state = types.Stopped
+			p.sendState()
+			return
+		}
+	}
+	info, _ := p.getPositionInfo()
+	// 未订阅事件时通过当前曲目地址发现设备已切换到下一首
+	p.checkTrackURI(info.trackURI)
+	if info.relTime > 0 {
+		p.curPos = info.relTime
 		select {
-		case p.timeChan <- curPos:
+		case p.timeChan <- info.relTime:
 		default:
 		}
 	}
-	if vol, err := p.getVolume(); err == nil {
-		p.cachedVolume = vol
+	if !subscribed || p.polls%6 == 0 {
+		if vol, err := p.getVolume(); err == nil {
+			p.cachedVolume = vol
+		}
 	}
 }
 
@@ -248,28 +372,7 @@ func (p *dlnaPlayer) executeCmd(cmd command) {
 	switch cmd.cmd {
 	case cmdPlay:
 		music := cmd.param.(URLMusic)
-		p.audioURL = music.URL
-		p.audioDur = music.Duration
-		p.fileMapMu.Lock()
-		for k := range p.fileMap {
-			delete(p.fileMap, k)
-		}
-		p.fileMapMu.Unlock()
-		audioURL := music.URL
-		if strings.HasPrefix(audioURL, "file://") {
-			localPath := strings.TrimPrefix(audioURL, "file://")
-			p.fileMapMu.Lock()
-			p.fileMap[music.Id] = localPath
-			p.fileMapMu.Unlock()
-			audioURL = fmt.Sprintf("http://%s:%d/dlna/%d", p.localIP, p.httpPort, music.Id)
-		}
-		p.audioURL = audioURL
-
-		slog.Info("DLNA: setting AVTransport URI", "audioURL", p.audioURL)
-		p.doSOAP("AVTransport", "SetAVTransportURI", fmt.Sprintf(setAvTransportURIBody, p.audioURL))
-
-		slog.Info("DLNA: starting playback")
-		p.doSOAP("AVTransport", "Play", playBody)
+		p.load(music)
 
 		p.state = types.Playing
 		p.sendState()
@@ -293,6 +396,8 @@ func (p *dlnaPlayer) executeCmd(cmd command) {
 		cmd.result <- true
 
 	case cmdStop:
+		p.clearNext()
+		p.stopCheck = nil
 		p.doSOAP("AVTransport", "Stop", stopBody)
 		p.curPos = 0
 		p.state = types.Stopped
@@ -316,6 +421,144 @@ func (p *dlnaPlayer) executeCmd(cmd command) {
 			p.cachedVolume = volume
 		}
 		cmd.result <- true
+
+	case cmdSwitchRenderer:
+		cmd.result <- p.switchRenderer(cmd.param.(string))
+
+	case cmdAutoConnect:
+		if p.controlURL != "" {
+			cmd.result <- nil
+			break
+		}
+		cmd.result <- p.switchRenderer(cmd.param.(string))
+
+	case cmdNoRenderer:
+		// 没有可用设备时通知界面，不能发送 Stopped，否则会被当作播放结束而切到下一首
+		if p.controlURL == "" {
+			p.state = types.Interrupted
+			p.sendState()
+		}
+
+	case cmdPreload:
+		p.cancelNext()
+		p.queueNext(cmd.param.(URLMusic))
+		cmd.result <- true
+
+	case cmdCancelPreload:
+		p.cancelNext()
+		cmd.result <- true
+	}
+}
+
+// load 将歌曲投送到当前设备并开始播放，本地文件经内置 HTTP server 提供
+func (p *dlnaPlayer) load(music URLMusic) {
+	p.next, p.nextURL, p.stopCheck = nil, "", nil
+	p.fileMapMu.Lock()
+	clear(p.fileMap)
+	p.fileMapMu.Unlock()
+	audioURL := p.shareMusic(music)
+
+	p.musicMu.Lock()
+	p.music, p.audioURL, p.audioDur = music, audioURL, music.Duration
+	p.musicMu.Unlock()
+
+	if p.controlURL == "" {
+		slog.Warn("DLNA: no renderer connected")
+		return
+	}
+	slog.Info("DLNA: setting AVTransport URI", "audioURL", p.audioURL)
+	p.doSOAP("AVTransport", "SetAVTransportURI", fmt.Sprintf(setAvTransportURIBody, p.audioURL))
+
+	slog.Info("DLNA: starting playback")
+	p.doSOAP("AVTransport", "Play", playBody)
+}
+
+// shareMusic 返回设备访问歌曲的地址，本地文件登记后经内置 HTTP server 提供
+func (p *dlnaPlayer) shareMusic(music URLMusic) string {
+	localPath, ok := strings.CutPrefix(music.URL, "file://")
+	if !ok {
+		return music.URL
+	}
+	p.fileMapMu.Lock()
+	p.fileMap[music.Id] = localPath
+	p.fileMapMu.Unlock()
+	return fmt.Sprintf("http://%s:%d/dlna/%d", p.localIP, p.httpPort, music.Id)
+}
+
+func (p *dlnaPlayer) unshareMusic(id int64) {
+	p.fileMapMu.Lock()
+	delete(p.fileMap, id)
+	p.fileMapMu.Unlock()
+}
+
+// switchRenderer 切换到新设备，正在播放的歌曲从当前位置在新设备上继续
+func (p *dlnaPlayer) switchRenderer(deviceURL string) error {
+	oldControlURL := p.controlURL
+	if err := p.connect(deviceURL); err != nil {
+		return err
+	}
+	if oldControlURL != "" && oldControlURL != p.controlURL && p.state != types.Stopped {
+		controlURL := p.controlURL
+		p.controlURL = oldControlURL
+		_, _ = p.doSOAP("AVTransport", "Stop", stopBody)
+		p.controlURL = controlURL
+	}
+	if p.state == types.Stopped || p.music.URL == "" {
+		return nil
+	}
+
+	position := p.curPos
+	p.load(p.music)
+	if position > 0 {
+		p.doSOAP("AVTransport", "Seek", fmt.Sprintf(seekBody, formatDuration(position)))
+	}
+	if p.state == types.Paused {
+		p.doSOAP("AVTransport", "Pause", pauseBody)
+	}
+	return nil
+}
+
+// Renderer 当前连接的设备
+func (p *dlnaPlayer) Renderer() (DlnaRenderer, bool) {
+	p.deviceMu.RLock()
+	defer p.deviceMu.RUnlock()
+	if p.deviceURL == "" {
+		return DlnaRenderer{}, false
+	}
+	return DlnaRenderer{Name: p.deviceName, Location: p.deviceURL}, true
+}
+
+// DiscoverRenderers 搜索局域网中的设备，配置了 localIP 时从该地址所在网卡发送请求
+func (p *dlnaPlayer) DiscoverRenderers(ctx context.Context) ([]DlnaRenderer, error) {
+	return p.discoverRenderers(ctx)
+}
+
+func (p *dlnaPlayer) discoverRenderers(ctx context.Context, extra ...DiscoverOption) ([]DlnaRenderer, error) {
+	opts := []DiscoverOption{WithDiscoverTimeout(p.discoverTimeout)}
+	if !p.autoIP {
+		opts = append(opts, WithDiscoverLocalIP(p.localIP))
+	}
+	return DiscoverDlnaRenderers(ctx, append(opts, extra...)...)
+}
+
+// SwitchRenderer 运行中切换投送设备
+func (p *dlnaPlayer) SwitchRenderer(location string) error {
+	result := make(chan any)
+	p.cmdQueue <- command{cmd: cmdSwitchRenderer, param: location, result: result}
+	if err, _ := (<-result).(error); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p *dlnaPlayer) restartHTTPServer() {
+	if p.httpServer != nil {
+		if err := p.httpServer.Close(); err != nil {
+			slog.Error("DLNA: failed to close HTTP server", "error", err)
+		}
+	}
+	if err := p.startHTTPServer(); err != nil {
+		slog.Error("DLNA: failed to restart HTTP server", "error", err)
 	}
 }
 
@@ -329,9 +572,11 @@ func (p *dlnaPlayer) startHTTPServer() error {
 		p.httpPort = listener.Addr().(*net.TCPAddr).Port
 		mux := http.NewServeMux()
 		mux.HandleFunc("/dlna/", p.serveLocalFile)
-		p.httpServer = &http.Server{Handler: mux}
+		mux.HandleFunc(genaEventsPath, p.serveGENANotify)
+		server := &http.Server{Handler: mux}
+		p.httpServer = server
 		go func() {
-			if err := p.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
 				slog.Error("DLNA: HTTP server error", "error", err)
 			}
 		}()
@@ -382,10 +627,17 @@ func (p *dlnaPlayer) doSOAP(service, action, body string) ([]byte, error) {
 	return io.ReadAll(resp.Body)
 }
 
-func (p *dlnaPlayer) getPositionInfo() (time.Duration, time.Duration, error) {
+// dlnaPositionInfo GetPositionInfo 的结果
+type dlnaPositionInfo struct {
+	relTime  time.Duration
+	duration time.Duration
+	trackURI string
+}
+
+func (p *dlnaPlayer) getPositionInfo() (dlnaPositionInfo, error) {
 	respBody, err := p.doSOAP("AVTransport", "GetPositionInfo", getPositionInfoBody)
 	if err != nil {
-		return 0, 0, err
+		return dlnaPositionInfo{}, err
 	}
 
 	type envelopeResponse struct {
@@ -393,13 +645,14 @@ func (p *dlnaPlayer) getPositionInfo() (time.Duration, time.Duration, error) {
 			GetPositionInfoResponse struct {
 				TrackDuration string `xml:"TrackDuration"`
 				RelTime       string `xml:"RelTime"`
+				TrackURI      string `xml:"TrackURI"`
 			} `xml:"GetPositionInfoResponse"`
 		} `xml:"Body"`
 	}
 
 	var env envelopeResponse
 	if err := xml.Unmarshal(respBody, &env); err != nil {
-		return 0, 0, err
+		return dlnaPositionInfo{}, err
 	}
 
 	parse := func(t string) time.Duration {
@@ -421,9 +674,12 @@ func (p *dlnaPlayer) getPositionInfo() (time.Duration, time.Duration, error) {
 		return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second + time.Duration(ms)*time.Millisecond
 	}
 
-	curPos := parse(env.Body.GetPositionInfoResponse.RelTime)
-	totalDur := parse(env.Body.GetPositionInfoResponse.TrackDuration)
-	return curPos, totalDur, nil
+	response := env.Body.GetPositionInfoResponse
+	return dlnaPositionInfo{
+		relTime:  parse(response.RelTime),
+		duration: parse(response.TrackDuration),
+		trackURI: response.TrackURI,
+	}, nil
 }
 
 // sendState sends state update non-blockingly, with 2 second timeout
@@ -466,17 +722,11 @@ func (p *dlnaPlayer) getTransportInfo() (string, error) {
 	return env.Body.GetTransportInfoResponse.CurrentTransportState, nil
 }
 
-func (p *dlnaPlayer) normalizeURL(base, controlURL string) string {
-	if controlURL != "" && !bytes.HasPrefix([]byte(controlURL), []byte("http")) {
-		controlURL = base + controlURL
-	}
-	return controlURL
-}
-
 func (p *dlnaPlayer) CurMusic() URLMusic {
-	music := URLMusic{
-		URL: p.audioURL,
-	}
+	p.musicMu.RLock()
+	defer p.musicMu.RUnlock()
+	music := p.music
+	music.URL = p.audioURL
 	music.Duration = p.audioDur
 	return music
 }
@@ -574,10 +824,11 @@ func (p *dlnaPlayer) DownVolume() {
 }
 
 func (p *dlnaPlayer) Close() {
-	if p.closed != nil {
-		close(p.closed)
-		p.closed = nil
+	if p.discoverCancel != nil {
+		p.discoverCancel()
 	}
+	// worker 仍在读取 closed，不能置空
+	p.closeOnce.Do(func() { close(p.closed) })
 	if p.httpServer != nil {
 		if err := p.httpServer.Close(); err != nil {
 			slog.Error("DLNA: failed to close HTTP server", "error", err)
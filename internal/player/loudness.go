@@ -0,0 +1,170 @@
+package player
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/gopxl/beep"
+)
+
+// EBU R128 / ITU-R BS.1770 响度测量
+
+const (
+	loudnessBlockDuration = 400 * time.Millisecond
+	loudnessBlockSubs     = 4     // 测量块由 4 个 100ms 子块组成，相邻块重叠 75%
+	loudnessAbsoluteGate  = -70.0 // 绝对门限（LUFS）
+	loudnessRelativeGate  = -10.0 // 相对门限（LU）
+	loudnessChunkSize     = 4096
+	replayGainReference   = -18.0 // ReplayGain 2.0 参考响度（LUFS）
+	loudnessChannelOffset = -0.691
+)
+
+// biquad 二阶 IIR 滤波器
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	z1, z2             float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.z1
+	f.z1 = f.b1*x - f.a1*y + f.z2
+	f.z2 = f.b2*x - f.a2*y
+	return y
+}
+
+// kWeighting 按采样率生成 K 加权滤波器（高架滤波 + 高通滤波）
+func kWeighting(rate beep.SampleRate) [2]biquad {
+	fs := float64(rate)
+
+	// 第一级：模拟头部声学效应的高架滤波器
+	f0, gain, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / fs)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	// 第二级：RLB 高通滤波器
+	f0, q = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / fs)
+	a0 = 1 + k/q + k*k
+	highPass := biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	return [2]biquad{shelf, highPass}
+}
+
+// loudnessMeter 累计 K 加权后的能量并计算门限积分响度
+type loudnessMeter struct {
+	filters    [2][2]biquad // [声道][级]
+	subSize    int
+	subCount   int
+	subEnergy  float64
+	subBlocks  [loudnessBlockSubs]float64 // 最近 4 个子块的能量
+	subTotal   int                        // 已完成的子块数
+	blocks     []float64                  // 每个 400ms 块的均方值
+	samplePeak float64
+}
+
+func newLoudnessMeter(rate beep.SampleRate) *loudnessMeter {
+	weighting := kWeighting(rate)
+	return &loudnessMeter{
+		filters: [2][2]biquad{weighting, weighting},
+		subSize: max(rate.N(loudnessBlockDuration/loudnessBlockSubs), 1),
+	}
+}
+
+func (m *loudnessMeter) write(samples [][2]float64) {
+	for _, sample := range samples {
+		for ch := range 2 {
+			m.samplePeak = max(m.samplePeak, math.Abs(sample[ch]))
+			y := m.filters[ch][0].process(sample[ch])
+			y = m.filters[ch][1].process(y)
+			m.subEnergy += y * y
+		}
+		m.subCount++
+		if m.subCount < m.subSize {
+			continue
+		}
+
+		m.subBlocks[m.subTotal%loudnessBlockSubs] = m.subEnergy
+		m.subTotal++
+		if m.subTotal >= loudnessBlockSubs {
+			var energy float64
+			for _, e := range m.subBlocks {
+				energy += e
+			}
+			m.blocks = append(m.blocks, energy/float64(m.subSize*loudnessBlockSubs))
+		}
+		m.subCount, m.subEnergy = 0, 0
+	}
+}
+
+// integrated 门限积分响度（LUFS），有效音频不足时返回 -Inf
+func (m *loudnessMeter) integrated() float64 {
+	mean := func(threshold float64) float64 {
+		var (
+			sum   float64
+			count int
+		)
+		for _, z := range m.blocks {
+			if blockLoudness(z) > threshold {
+				sum += z
+				count++
+			}
+		}
+		if count == 0 {
+			return 0
+		}
+		return sum / float64(count)
+	}
+
+	absolute := mean(loudnessAbsoluteGate)
+	if absolute == 0 {
+		return math.Inf(-1)
+	}
+	relative := blockLoudness(absolute) + loudnessRelativeGate
+	gated := mean(max(relative, loudnessAbsoluteGate))
+	if gated == 0 {
+		return math.Inf(-1)
+	}
+	return blockLoudness(gated)
+}
+
+func blockLoudness(meanSquare float64) float64 {
+	if meanSquare <= 0 {
+		return math.Inf(-1)
+	}
+	return loudnessChannelOffset + 10*math.Log10(meanSquare)
+}
+
+// measureLoudness 读完整个 streamer 并返回积分响度（LUFS）与采样峰值
+func measureLoudness(ctx context.Context, s beep.Streamer, rate beep.SampleRate) (loudness, peak float64, err error) {
+	meter := newLoudnessMeter(rate)
+	buf := make([][2]float64, loudnessChunkSize)
+	for {
+		if err = ctx.Err(); err != nil {
+			return 0, 0, err
+		}
+		n, ok := s.Stream(buf)
+		meter.write(buf[:n])
+		if !ok {
+			break
+		}
+	}
+	if err = s.Err(); err != nil {
+		return 0, 0, err
+	}
+	return meter.integrated(), meter.samplePeak, nil
+}
@@ -0,0 +1,84 @@
+package player
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// 把下一首追加到 mpd 播放列表并关闭 single 模式，mpd 播完当前歌曲后直接衔接。
+// 播放中的歌曲 id 变为排队的下一首时完成无缝切换，随后恢复 single 模式
+
+// Preload 将下一首排入 mpd 播放列表
+func (p *mpdPlayer) Preload(music URLMusic) {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	p.clearNext()
+	id, err := p.addSong(music, -1)
+	if err != nil {
+		slog.Warn("mpd: 预加载下一首失败", slogx.Error(err))
+		return
+	}
+	if err = p.client().Single(false); err != nil {
+		slog.Warn("mpd: 关闭 single 模式失败", slogx.Error(err))
+		mpdErrorHandler(p.client().DeleteID(id), true)
+		return
+	}
+	p.next, p.nextSongId = &music, id
+}
+
+// CancelPreload 移除排队的下一首
+func (p *mpdPlayer) CancelPreload() {
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.clearNext()
+}
+
+func (p *mpdPlayer) GaplessTransitionChan() <-chan GaplessTransition {
+	return p.transitionChan
+}
+
+// clearNext 调用方需持有 p.l
+func (p *mpdPlayer) clearNext() {
+	if p.next == nil {
+		return
+	}
+	mpdErrorHandler(p.client().DeleteID(p.nextSongId), true)
+	mpdErrorHandler(p.client().Single(true), true)
+	p.next, p.nextSongId = nil, 0
+}
+
+// checkGaplessTransition mpd 正在播放的歌曲变为排队的下一首时完成切换
+func (p *mpdPlayer) checkGaplessTransition(songId string) {
+	p.l.Lock()
+	if p.next == nil || songId != strconv.Itoa(p.nextSongId) {
+		p.l.Unlock()
+		return
+	}
+	played := p.PlayedTime()
+	prevSongId, next := p.curSongId, *p.next
+	p.curMusic, p.curSongId = next, p.nextSongId
+	p.next, p.nextSongId = nil, 0
+	mpdErrorHandler(p.client().DeleteID(prevSongId), true)
+	mpdErrorHandler(p.client().Single(true), true)
+
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.timer = p.newTimer()
+	p.latestPlayTime = time.Now()
+	if p.pcm != nil {
+		p.pcm.Reset()
+	}
+	p.l.Unlock()
+
+	slog.Info("mpd: 无缝切换到下一首", slog.Int64("songId", next.Id))
+	select {
+	case p.transitionChan <- GaplessTransition{Music: next, PlayedTime: played}:
+	default:
+		slog.Warn("mpd: gapless transition channel full, drop transition")
+	}
+}
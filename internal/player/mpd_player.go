@@ -53,6 +53,12 @@ type mpdPlayer struct {
 	stateChan chan types.State
 	musicChan chan URLMusic
 
+	pcm *pcmTap // fifo 输出旁路，供可视化使用
+
+	next           *URLMusic // 已排入 mpd 播放列表的下一首
+	nextSongId     int
+	transitionChan chan GaplessTransition
+
 	close chan struct{}
 }
 
@@ -62,9 +68,14 @@ type MpdConfig struct {
 	Network    string
 	Address    string
 	AutoStart  bool
+	FifoPath   string // mpd fifo 输出的管道路径
+	FifoFormat string // fifo 输出的格式，如 44100:16:2
 }
 
 func NewMpdPlayer(conf *MpdConfig) *mpdPlayer {
+	// 先于 mpd 创建管道，避免 mpd 关闭输出时删除它自己创建的管道
+	pcm := newMpdPCMTap(conf)
+
 	cmd := exec.Command(conf.Bin)
 	if conf.ConfigFile != "" {
 		cmd.Args = append(cmd.Args, conf.ConfigFile)
@@ -109,7 +120,10 @@ func NewMpdPlayer(conf *MpdConfig) *mpdPlayer {
 		timeChan:  make(chan time.Duration, 1),
 		stateChan: make(chan types.State, 10),
 		musicChan: make(chan URLMusic, 1),
+		pcm:       pcm,
 		close:     make(chan struct{}),
+
+		transitionChan: make(chan GaplessTransition, 1),
 	}
 
 	errorx.WaitGoStart(p.listen)
@@ -119,6 +133,24 @@ func NewMpdPlayer(conf *MpdConfig) *mpdPlayer {
 	return p
 }
 
+// newMpdPCMTap 配置了 fifo 输出且需要可视化时读取 mpd 输出的 PCM
+func newMpdPCMTap(conf *MpdConfig) *pcmTap {
+	if conf.FifoPath == "" || !spectrumWanted() {
+		return nil
+	}
+	format, err := parsePCMFormat(conf.FifoFormat)
+	if err != nil {
+		slog.Warn("mpd fifo 格式无效，可视化不可用", slogx.Error(err))
+		return nil
+	}
+	pcm, err := newPCMTap(conf.FifoPath, format, false)
+	if err != nil {
+		slog.Warn("打开 mpd fifo 失败，可视化不可用", slogx.Error(err))
+		return nil
+	}
+	return pcm
+}
+
 var _client *mpd.Client
 
 func (p *mpdPlayer) client() *mpd.Client {
@@ -137,6 +169,10 @@ func (p *mpdPlayer) syncMpdStatus(subsystem string) {
 	status, err := p.client().Status()
 	mpdErrorHandler(err, true)
 
+	if subsystem == "player" {
+		p.checkGaplessTransition(status["songid"])
+	}
+
 	state := stateMapping[status["state"]]
 	if subsystem == "player" && (state != types.Stopped || time.Since(p.latestPlayTime) >= time.Second*2) {
 		switch state {
@@ -189,73 +225,90 @@ func (p *mpdPlayer) listen() {
 			p.latestPlayTime = time.Now()
 			// 重置
 			{
+				if p.pcm != nil {
+					p.pcm.Reset()
+				}
 				if p.timer != nil {
 					p.timer.Stop()
 				}
+				p.l.Lock()
+				p.clearNext()
+				p.l.Unlock()
 				if p.curSongId != 0 {
 					err = p.client().DeleteID(p.curSongId)
 					mpdErrorHandler(err, true)
 				}
 			}
 
-			var (
-				url     = p.curMusic.URL
-				isLocal = strings.HasPrefix(p.curMusic.URL, "file://")
-			)
-			if isLocal {
-				url = path.Base(p.curMusic.URL)
-			}
-
-			if isLocal {
-				_, err = p.client().Rescan(url)
-				mpdErrorHandler(err, false)
-				for {
-					var attr map[string]string
-					if attr, err = p.client().Status(); err != nil {
-						mpdErrorHandler(err, true)
-						break
-					}
-					if _, ok := attr["updating_db"]; ok {
-						continue
-					}
-					// 确保更新完成
-					break
-				}
-			}
-
-			p.curSongId, err = p.client().AddID(url, 0)
+			p.curSongId, err = p.addSong(p.curMusic, 0)
 			mpdErrorHandler(err, false)
 
-			// 计时器
-			p.timer = timex.NewTimer(timex.Options{
-				Duration:       8760 * time.Hour,
-				TickerInternal: configs.AppConfig.Main.FrameRate.Interval(),
-				OnRun:          func(started bool) {},
-				OnPause:        func() {},
-				OnDone:         func(stopped bool) {},
-				OnTick: func() {
-					select {
-					case p.timeChan <- p.timer.Passed():
-					default:
-					}
-				},
-			})
+			p.timer = p.newTimer()
 
 			err = p.client().PlayID(p.curSongId)
 			mpdErrorHandler(err, false)
-			if !isLocal {
-				// Doing this because github.com/fhs/gompd/v2/mpd hasn't implement "addtagid" yet
-				command := "addtagid %d %s %s"
-				err = p.client().Command(command, p.curSongId, "artist", p.curMusic.ArtistName()).OK()
-				mpdErrorHandler(err, true)
-				err = p.client().Command(command, p.curSongId, "album", p.curMusic.Album.Name).OK()
-				mpdErrorHandler(err, true)
-				err = p.client().Command(command, p.curSongId, "title", p.curMusic.Name).OK()
+			p.Resume()
+		}
+	}
+}
+
+// addSong 把歌曲加入 mpd 播放列表的 pos 位置，pos 为负数时追加到末尾
+func (p *mpdPlayer) addSong(music URLMusic, pos int) (int, error) {
+	var (
+		url     = music.URL
+		isLocal = strings.HasPrefix(music.URL, "file://")
+	)
+	if isLocal {
+		url = path.Base(music.URL)
+		if _, err := p.client().Rescan(url); err != nil {
+			return 0, err
+		}
+		for {
+			attr, err := p.client().Status()
+			if err != nil {
 				mpdErrorHandler(err, true)
+				break
 			}
-			p.Resume()
+			if _, ok := attr["updating_db"]; ok {
+				continue
+			}
+			// 确保更新完成
+			break
 		}
 	}
+
+	id, err := p.client().AddID(url, pos)
+	if err != nil {
+		return 0, err
+	}
+	if !isLocal {
+		// Doing this because github.com/fhs/gompd/v2/mpd hasn't implement "addtagid" yet
+		command := "addtagid %d %s %s"
+		err = p.client().Command(command, id, "artist", music.ArtistName()).OK()
+		mpdErrorHandler(err, true)
+		err = p.client().Command(command, id, "album", music.Album.Name).OK()
+		mpdErrorHandler(err, true)
+		err = p.client().Command(command, id, "title", music.Name).OK()
+		mpdErrorHandler(err, true)
+	}
+	return id, nil
+}
+
+// newTimer 计时器，歌曲结束依赖 mpd 的状态变化
+func (p *mpdPlayer) newTimer() *timex.Timer {
+	return timex.NewTimer(timex.Options{
+		Duration:       8760 * time.Hour,
+		TickerInternal: configs.AppConfig.Main.FrameRate.Interval(),
+		OnRun:          func(started bool) {},
+		OnPause:        func() {},
+		OnDone:         func(stopped bool) {},
+		OnTick: func() {
+			select {
+			case p.timeChan <- p.timer.Passed():
+			default:
+			}
+		},
+	})
 }
 
 func (p *mpdPlayer) watch() {
@@ -416,6 +469,10 @@ func (p *mpdPlayer) Close() {
 		p.close = nil
 	}
 
+	if p.pcm != nil {
+		p.pcm.Close()
+	}
+
 	err = p.client().Stop()
 	mpdErrorHandler(err, true)
 
@@ -431,3 +488,17 @@ func (p *mpdPlayer) Close() {
 		_ = cmd.Run()
 	}
 }
+
+func (p *mpdPlayer) Spectrum() SpectrumFrame {
+	if p.pcm == nil {
+		return SpectrumFrame{}
+	}
+	return p.pcm.Spectrum()
+}
+
+func (p *mpdPlayer) RawSamples() RawSampleFrame {
+	if p.pcm == nil {
+		return RawSampleFrame{}
+	}
+	return p.pcm.RawSamples()
+}
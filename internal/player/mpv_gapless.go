@@ -0,0 +1,69 @@
+package player
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// 通过 loadfile append 把下一首追加到 mpv 的播放列表，配合 --gapless-audio
+// 在当前歌曲自然结束时直接衔接；此时收到的 end-file(eof) 视为无缝切换而非停止
+
+// Preload 将下一首追加到 mpv 播放列表
+func (p *mpvPlayer) Preload(music URLMusic) {
+	p.nextMu.Lock()
+	defer p.nextMu.Unlock()
+
+	// 只保留正在播放的歌曲，同时清掉之前无缝切换留下的已播放条目
+	_ = p.sendCommand(`{ "command": ["playlist-clear"] }`)
+	p.next = nil
+	cmd := fmt.Sprintf(`{ "command": ["loadfile", %s, "append"] }`, jsonString(music.URL))
+	if err := p.sendCommand(cmd); err != nil {
+		slog.Warn("mpv Preload: 追加下一首失败", slogx.Error(err))
+		return
+	}
+	slog.Info("mpv Preload: 已追加下一首", slog.String("name", music.Name))
+	p.next = &music
+}
+
+// CancelPreload 移除排队的下一首
+func (p *mpvPlayer) CancelPreload() {
+	if p.takeNext() != nil {
+		_ = p.sendCommand(`{ "command": ["playlist-clear"] }`)
+	}
+}
+
+func (p *mpvPlayer) GaplessTransitionChan() <-chan GaplessTransition {
+	return p.transitionChan
+}
+
+// takeNext 取出并清除排队的下一首
+func (p *mpvPlayer) takeNext() *URLMusic {
+	p.nextMu.Lock()
+	defer p.nextMu.Unlock()
+	next := p.next
+	p.next = nil
+	return next
+}
+
+// finishGapless mpv 已衔接到排队的下一首，重置计时并通知上层
+func (p *mpvPlayer) finishGapless(next URLMusic) {
+	played := p.PlayedTime()
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.curMusic = next
+	p.timer = p.newTimer(configs.AppConfig.Main.FrameRate.Interval())
+	go p.timer.Run()
+	p.setMediaTitle(next)
+	p.pcmTap.load(next.URL)
+	slog.Info("mpv watch: 无缝切换到下一首", slog.String("name", next.Name))
+
+	select {
+	case p.transitionChan <- GaplessTransition{Music: next, PlayedTime: played}:
+	default:
+		slog.Warn("mpv: gapless transition channel full, drop transition")
+	}
+}
@@ -0,0 +1,81 @@
+package player
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+func newTestMpvPlayer(t *testing.T) *mpvPlayer {
+	previousConfig := configs.AppConfig
+	configs.AppConfig = &configs.Config{}
+	t.Cleanup(func() { configs.AppConfig = previousConfig })
+
+	p := &mpvPlayer{
+		state:          types.Playing,
+		timeChan:       make(chan time.Duration, 1),
+		stateChan:      make(chan types.State, 10),
+		songLoadedCh:   make(chan struct{}, 1),
+		transitionChan: make(chan GaplessTransition, 1),
+		curMusic:       URLMusic{Song: structs.Song{Id: 1}},
+	}
+	p.timer = p.newTimer(time.Second)
+	t.Cleanup(func() {
+		if p.timer != nil {
+			p.timer.Stop()
+		}
+	})
+	return p
+}
+
+func TestMpvEndOfFileWithQueuedTrackIsGapless(t *testing.T) {
+	p := newTestMpvPlayer(t)
+	p.next = &URLMusic{URL: "http://music.example/2.mp3", Song: structs.Song{Id: 2, Name: "two"}}
+
+	p.handleIPCEvents(`{"event":"end-file","reason":"eof","playlist_entry_id":1}` + "\n" + `{"event":"start-file","playlist_entry_id":2}`)
+
+	select {
+	case transition := <-p.transitionChan:
+		if transition.Music.Id != 2 {
+			t.Errorf("transition to %d, want 2", transition.Music.Id)
+		}
+	default:
+		t.Fatal("no gapless transition")
+	}
+	if p.CurMusic().Id != 2 || p.next != nil {
+		t.Errorf("cur = %d, next = %v", p.CurMusic().Id, p.next)
+	}
+	if len(p.stateChan) != 0 {
+		t.Errorf("gapless transition must not report Stopped, got %v", <-p.stateChan)
+	}
+}
+
+func TestMpvEndOfFileWithoutQueuedTrackStops(t *testing.T) {
+	p := newTestMpvPlayer(t)
+
+	p.handleIPCEvents(`{"event":"end-file","reason":"eof","playlist_entry_id":1}`)
+
+	if state := <-p.stateChan; state != types.Stopped {
+		t.Errorf("state = %v, want Stopped", state)
+	}
+	if len(p.transitionChan) != 0 {
+		t.Error("unexpected gapless transition")
+	}
+}
+
+func TestMpvErrorDropsQueuedTrack(t *testing.T) {
+	p := newTestMpvPlayer(t)
+	p.next = &URLMusic{Song: structs.Song{Id: 2}}
+
+	p.handleIPCEvents(`{"event":"end-file","reason":"error","playlist_entry_id":1}`)
+
+	if state := <-p.stateChan; state != types.Stopped {
+		t.Errorf("state = %v, want Stopped", state)
+	}
+	if p.next != nil || len(p.transitionChan) != 0 {
+		t.Error("queued track should be dropped on error")
+	}
+}
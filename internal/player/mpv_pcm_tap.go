@@ -0,0 +1,180 @@
+package player
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// mpvPCMFormat 旁路进程输出的格式，与启动参数保持一致
+var mpvPCMFormat = pcmFormat{SampleRate: 44100, Channels: 2}
+
+// mpvPCMTap 旁路 mpv 进程，以 --ao=pcm 把同一首歌解码写入命名管道
+//
+// 发声仍由主 mpv 进程负责，旁路进程只为可视化提供 PCM：
+// 切歌、暂停、跳转等命令同步转发，读取端按采样率限速以跟上实际播放进度。
+type mpvPCMTap struct {
+	cmd      *exec.Cmd
+	ipcPath  string
+	fifoPath string
+	pcm      *pcmTap
+
+	mu     sync.Mutex
+	conn   net.Conn
+	broken bool // IPC 连接超时后不再重试，避免阻塞播放控制
+}
+
+// newMpvPCMTap 启动旁路进程，失败时返回 nil，可视化不可用但不影响播放
+func newMpvPCMTap(binPath string) *mpvPCMTap {
+	dir := filepath.Dir(ipcServerPath())
+	t := &mpvPCMTap{
+		ipcPath:  filepath.Join(dir, "mpvsocket-pcm"),
+		fifoPath: filepath.Join(dir, "mpv-pcm.fifo"),
+	}
+
+	var err error
+	if t.pcm, err = newPCMTap(t.fifoPath, mpvPCMFormat, true); err != nil {
+		slog.Warn("mpv PCM 旁路: 打开管道失败，可视化不可用", slogx.Error(err))
+		return nil
+	}
+
+	args := []string{
+		"--no-video",
+		"--no-terminal",
+		"--idle",
+		"--input-ipc-server=" + t.ipcPath,
+		"--input-media-keys=no",
+		"--cache=yes",
+		"--ao=pcm",
+		"--ao-pcm-file=" + t.fifoPath,
+		"--ao-pcm-waveheader=no",
+		"--audio-format=s16",
+		fmt.Sprintf("--audio-samplerate=%d", mpvPCMFormat.SampleRate),
+		"--audio-channels=stereo",
+		"--volume=100",
+	}
+	t.cmd = exec.Command(binPath, args...)
+	if err = t.cmd.Start(); err != nil {
+		slog.Warn("mpv PCM 旁路: 启动失败，可视化不可用", slogx.Error(err))
+		t.pcm.Close()
+		_ = os.Remove(t.fifoPath)
+		return nil
+	}
+	slog.Info("mpv PCM 旁路: 已启动", slog.Int("pid", t.cmd.Process.Pid), slog.String("fifo", t.fifoPath))
+	return t
+}
+
+// send 向旁路进程发送 IPC 命令，连接在首次使用时建立（进程可能尚未就绪）
+func (t *mpvPCMTap) send(cmd string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.broken {
+		return
+	}
+	if t.conn == nil {
+		var err error
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if t.conn, err = net.Dial("unix", t.ipcPath); err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				slog.Warn("mpv PCM 旁路: 连接IPC失败，停止转发命令", slogx.Error(err))
+				t.broken = true
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		// 与主进程相同，持续读取回包，防止 mpv 发送缓冲写满
+		go func(conn net.Conn) {
+			buf := make([]byte, 4096)
+			for {
+				if _, err := conn.Read(buf); err != nil {
+					return
+				}
+			}
+		}(t.conn)
+	}
+
+	_ = t.conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
+	if _, err := t.conn.Write([]byte(cmd + "\n")); err != nil {
+		slog.Warn("mpv PCM 旁路: 发送命令失败", slogx.Error(err))
+		_ = t.conn.Close()
+		t.conn = nil
+	}
+}
+
+func (t *mpvPCMTap) load(url string) {
+	if t == nil {
+		return
+	}
+	t.pcm.Reset()
+	t.send(fmt.Sprintf(`{ "command": ["loadfile", %s, "replace"] }`, jsonString(url)))
+	t.send(`{ "command": ["set_property", "pause", false] }`)
+}
+
+func (t *mpvPCMTap) setPause(pause bool) {
+	t.send(fmt.Sprintf(`{ "command": ["set_property", "pause", %t] }`, pause))
+}
+
+func (t *mpvPCMTap) seek(duration time.Duration) {
+	t.send(fmt.Sprintf(`{ "command": ["set_property", "time-pos", %f] }`, duration.Seconds()))
+}
+
+func (t *mpvPCMTap) stop() {
+	t.send(`{ "command": ["stop"] }`)
+}
+
+func (t *mpvPCMTap) Spectrum() SpectrumFrame {
+	if t == nil {
+		return SpectrumFrame{}
+	}
+	return t.pcm.Spectrum()
+}
+
+func (t *mpvPCMTap) RawSamples() RawSampleFrame {
+	if t == nil {
+		return RawSampleFrame{}
+	}
+	return t.pcm.RawSamples()
+}
+
+// Close 退出旁路进程并删除管道
+func (t *mpvPCMTap) Close() {
+	if t == nil {
+		return
+	}
+	t.send(`{ "command": ["quit"] }`)
+	// 先关闭读取端，避免旁路进程阻塞在写管道上无法退出
+	t.pcm.Close()
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = t.cmd.Process.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		_ = t.cmd.Process.Kill()
+	}
+
+	t.mu.Lock()
+	if t.conn != nil {
+		_ = t.conn.Close()
+		t.conn = nil
+	}
+	t.mu.Unlock()
+	_ = os.Remove(t.fifoPath)
+}
@@ -52,11 +52,18 @@ type mpvPlayer struct {
 	loadSeqLock  sync.Mutex
 	loadWDCancel chan struct{} // 取消上一个切歌watchdog
 	loadWDMutex  sync.Mutex    // 保护 loadWDCancel
+
+	pcmTap *mpvPCMTap // 可视化旁路进程，未启用时为 nil
+
+	next           *URLMusic  // 已追加到 mpv 播放列表的下一首
+	nextMu         sync.Mutex // 保护 next
+	transitionChan chan GaplessTransition
 }
 
 // MpvConfig MPV播放器配置
 type MpvConfig struct {
 	BinPath string // MPV可执行文件路径
+	PCMTap  bool   // 是否启动旁路进程为可视化提供 PCM
 }
 
 // NewMpvPlayer 创建新的MPV播放器实例
@@ -82,6 +89,8 @@ func NewMpvPlayer(conf *MpvConfig) *mpvPlayer {
 		musicChan:    make(chan URLMusic, 1),
 		closeCh:      make(chan struct{}),
 		songLoadedCh: make(chan struct{}, 1),
+
+		transitionChan: make(chan GaplessTransition, 1),
 	}
 
 	if err := p.startDaemon(); err != nil {
@@ -90,6 +99,10 @@ func NewMpvPlayer(conf *MpvConfig) *mpvPlayer {
 	}
 
 	slog.Info("mpv daemon: 守护进程启动完成", slog.String("ipc", ipcServerPath()))
+
+	if conf != nil && conf.PCMTap && spectrumWanted() {
+		p.pcmTap = newMpvPCMTap(binPath)
+	}
 	return p
 }
 
@@ -106,6 +119,7 @@ func (p *mpvPlayer) startDaemon() error {
 		"--log-file=" + ipcLogPath(),
 		"--audio-device=auto", // 自动选择音频设备
 		"--input-media-keys=no",
+		"--gapless-audio=yes",                // 预加载的下一首无缝衔接
 		fmt.Sprintf("--volume=%d", p.volume), // 设置音量
 	}
 
@@ -192,6 +206,8 @@ func (p *mpvPlayer) handleNewSong(music URLMusic) {
 	)
 
 	p.curMusic = music
+	// loadfile replace 会清空 mpv 的播放列表，排队的下一首随之失效
+	p.takeNext()
 
 	// 停止旧 timer
 	if p.timer != nil {
@@ -208,18 +224,13 @@ func (p *mpvPlayer) handleNewSong(music URLMusic) {
 		return
 	}
 	slog.Info("mpv handleNewSong: loadfile发送成功")
+	p.pcmTap.load(music.URL)
 
 	// 加固：切歌watchdog，超时未收到 file-loaded 则重建连接并重发 loadfile
 	p.armSongLoadedWatchdog(cmd)
 
 	// 设置媒体标题
-	if title := buildMpvMediaTitle(music); title != "" {
-		titleCmd := fmt.Sprintf(`{ "command": ["set_property", "media-title", %s] }`, jsonString(title))
-		slog.Debug("mpv handleNewSong: 设置media-title", slog.String("title", title))
-		if err := p.sendCommand(titleCmd); err != nil {
-			slog.Warn("mpv handleNewSong: 设置media-title失败", slogx.Error(err))
-		}
-	}
+	p.setMediaTitle(music)
 	slog.Info("mpv handleNewSong: media-title设置完成")
 
 	// 创建 timer 追踪播放位置（参考 mpdPlayer）
@@ -229,7 +240,19 @@ func (p *mpvPlayer) handleNewSong(music URLMusic) {
 		slog.Duration("interval", interval),
 		slog.String("duration", "8760h"),
 	)
-	p.timer = timex.NewTimer(timex.Options{
+	p.timer = p.newTimer(interval)
+	p.Resume()
+
+	// go p.timer.Run()
+	// slog.Info("mpv handleNewSong: timer已启动")
+
+	// p.setState(types.Playing)
+	// slog.Info("mpv handleNewSong: 完成，状态已设为Playing")
+}
+
+// newTimer 创建追踪播放位置的 timer
+func (p *mpvPlayer) newTimer(interval time.Duration) *timex.Timer {
+	return timex.NewTimer(timex.Options{
 		Duration:       8760 * time.Hour,
 		TickerInternal: interval,
 		OnRun:          func(started bool) {},
@@ -242,13 +265,6 @@ func (p *mpvPlayer) handleNewSong(music URLMusic) {
 			}
 		},
 	})
-	p.Resume()
-
-	// go p.timer.Run()
-	// slog.Info("mpv handleNewSong: timer已启动")
-
-	// p.setState(types.Playing)
-	// slog.Info("mpv handleNewSong: 完成，状态已设为Playing")
 }
 
 // songLoadTimeout 切歌watchdog超时：loadfile 发出后等待 file-loaded 的最长时限
@@ -359,44 +375,7 @@ func (p *mpvPlayer) watch() {
 				slog.Int("readCount", readCount),
 				slog.String("raw", msg),
 			)
-
-			// 检测歌曲结束
-			// end-file 有多种 reason：
-			//   eof       — 自然播放结束（需要触发下一首）
-			//   stop      — 手动停止（Stop() 已处理）
-			//   new-file  — loadfile replace 替换文件（忽略，切歌中）
-			//   error     — 播放出错
-			// 只对 eof 触发 Stopped → 自动下一首
-			if strings.Contains(msg, `"event":"end-file"`) {
-				isEOF := strings.Contains(msg, `"reason":"eof"`)
-				isError := strings.Contains(msg, `"reason":"error"`)
-				slog.Info("mpv watch: 检测到end-file事件",
-					slog.Bool("isEOF", isEOF),
-					slog.Bool("isError", isError),
-				)
-
-				// 只有自然结束(eof)或出错才触发状态变更
-				if isEOF || isError {
-					slog.Info("mpv watch: 触发歌曲结束", slog.Bool("isEOF", isEOF))
-					if p.timer != nil {
-						slog.Debug("mpv watch: 停止timer")
-						p.timer.Stop()
-						p.timer = nil
-					}
-					p.setState(types.Stopped)
-					slog.Info("mpv watch: 状态已设为Stopped")
-				} else {
-					slog.Info("mpv watch: end-file忽略（非自然结束，可能是切歌中）")
-				}
-			}
-
-			// 切歌watchdog信号（加固）：file-loaded 表示新歌已成功加载
-			if strings.Contains(msg, `"event":"file-loaded"`) {
-				select {
-				case p.songLoadedCh <- struct{}{}:
-				default:
-				}
-			}
+			p.handleIPCEvents(msg)
 		}
 		conn.Close()
 		slog.Debug("mpv watch: 事件连接已关闭，1秒后重试")
@@ -410,6 +389,62 @@ func (p *mpvPlayer) watch() {
 	}
 }
 
+// handleIPCEvents 处理事件连接上读到的一批 IPC 事件
+func (p *mpvPlayer) handleIPCEvents(msg string) {
+	// 检测歌曲结束
+	// end-file 有多种 reason：
+	//   eof       — 自然播放结束（需要触发下一首）
+	//   stop      — 手动停止（Stop() 已处理）
+	//   new-file  — loadfile replace 替换文件（忽略，切歌中）
+	//   error     — 播放出错
+	// 只对 eof 触发 Stopped → 自动下一首；已预加载下一首时 mpv 自行衔接，视为无缝切换
+	if strings.Contains(msg, `"event":"end-file"`) {
+		isEOF := strings.Contains(msg, `"reason":"eof"`)
+		isError := strings.Contains(msg, `"reason":"error"`)
+		slog.Info("mpv watch: 检测到end-file事件",
+			slog.Bool("isEOF", isEOF),
+			slog.Bool("isError", isError),
+		)
+
+		next := p.takeNext()
+		switch {
+		case isEOF && next != nil:
+			p.finishGapless(*next)
+		case isEOF || isError:
+			// 只有自然结束(eof)或出错才触发状态变更
+			slog.Info("mpv watch: 触发歌曲结束", slog.Bool("isEOF", isEOF))
+			if p.timer != nil {
+				slog.Debug("mpv watch: 停止timer")
+				p.timer.Stop()
+				p.timer = nil
+			}
+			p.setState(types.Stopped)
+			slog.Info("mpv watch: 状态已设为Stopped")
+		default:
+			slog.Info("mpv watch: end-file忽略（非自然结束，可能是切歌中）")
+		}
+	}
+
+	// 切歌watchdog信号（加固）：file-loaded 表示新歌已成功加载
+	if strings.Contains(msg, `"event":"file-loaded"`) {
+		select {
+		case p.songLoadedCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// setMediaTitle 设置媒体标题
+func (p *mpvPlayer) setMediaTitle(music URLMusic) {
+	if title := buildMpvMediaTitle(music); title != "" {
+		titleCmd := fmt.Sprintf(`{ "command": ["set_property", "media-title", %s] }`, jsonString(title))
+		slog.Debug("mpv: 设置media-title", slog.String("title", title))
+		if err := p.sendCommand(titleCmd); err != nil {
+			slog.Warn("mpv: 设置media-title失败", slogx.Error(err))
+		}
+	}
+}
+
 func buildMpvMediaTitle(music URLMusic) string {
 	name := strings.TrimSpace(music.Name)
 	if name == "" {
@@ -583,6 +618,7 @@ func (p *mpvPlayer) Pause() {
 	}
 
 	_ = p.sendCommand(`{ "command": ["set_property", "pause", true] }`)
+	p.pcmTap.setPause(true)
 	if p.timer != nil {
 		slog.Debug("mpv Pause: 暂停timer")
 		p.timer.Pause()
@@ -600,6 +636,7 @@ func (p *mpvPlayer) Resume() {
 	}
 
 	_ = p.sendCommand(`{ "command": ["set_property", "pause", false] }`)
+	p.pcmTap.setPause(false)
 	if p.timer != nil {
 		slog.Debug("mpv Resume: 恢复timer")
 		go p.timer.Run()
@@ -612,6 +649,8 @@ func (p *mpvPlayer) Resume() {
 func (p *mpvPlayer) Stop() {
 	slog.Info("mpv Stop: 请求停止")
 	_ = p.sendCommand(`{ "command": ["stop"] }`)
+	p.pcmTap.stop()
+	p.takeNext()
 	if p.timer != nil {
 		slog.Debug("mpv Stop: 停止timer")
 		p.timer.Stop()
@@ -641,6 +680,7 @@ func (p *mpvPlayer) Seek(duration time.Duration) {
 		slog.Error("mpv Seek: 跳转命令发送失败", slogx.Error(err))
 		return
 	}
+	p.pcmTap.seek(duration)
 
 	if p.timer != nil {
 		slog.Debug("mpv Seek: 更新timer位置")
@@ -760,6 +800,9 @@ func (p *mpvPlayer) Close() {
 	// 关闭 IPC 命令连接
 	p.closeIPCConn()
 
+	// 退出可视化旁路进程
+	p.pcmTap.Close()
+
 	// 发送 quit 命令让 mpv 正常退出
 	slog.Info("mpv Close: 发送quit命令")
 	if err := p.sendCommand(`{ "command": ["quit"] }`); err != nil {
@@ -786,3 +829,13 @@ func (p *mpvPlayer) Close() {
 
 	slog.Info("mpv Close: 关闭完成")
 }
+
+// Spectrum 频谱数据，来自旁路进程
+func (p *mpvPlayer) Spectrum() SpectrumFrame {
+	return p.pcmTap.Spectrum()
+}
+
+// RawSamples 原始采样，来自旁路进程
+func (p *mpvPlayer) RawSamples() RawSampleFrame {
+	return p.pcmTap.RawSamples()
+}
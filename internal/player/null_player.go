@@ -0,0 +1,397 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopxl/beep"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+	"github.com/go-musicfox/go-musicfox/utils/app"
+	"github.com/go-musicfox/go-musicfox/utils/errorx"
+	"github.com/go-musicfox/go-musicfox/utils/iox"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// nullDefaultDuration 歌曲时长未知且不解码时按此时长计时
+const nullDefaultDuration = 3 * time.Minute
+
+// NullConfig `null` 引擎配置
+type NullConfig struct {
+	// Speed 时钟倍速，大于 1 时加速播放，用于测试
+	Speed float64
+	// Decode 下载并解码音频，以得到准确时长并为频谱提供 PCM
+	Decode bool
+	// Tick 时钟步进间隔，为 0 时使用界面刷新间隔
+	Tick time.Duration
+}
+
+// nullPlayer 不打开音频设备的播放引擎，按实际时间（或倍速）推进进度
+//
+// 用于无声卡的服务器（听歌记录、远程控制）以及界面播放流程的集成测试。
+type nullPlayer struct {
+	conf NullConfig
+	l    sync.Mutex
+
+	curMusic URLMusic
+	duration time.Duration
+	passed   time.Duration // 当前歌曲的播放进度
+	played   time.Duration // 实际播放时长，不含跳转
+	volume   int
+	loading  context.CancelFunc // 解码模式下正在下载当前歌曲
+
+	stream    beep.StreamSeekCloser
+	format    beep.Format
+	cachePath string
+	samples   [][2]float64
+
+	state      atomic.Uint32
+	timeChan   chan time.Duration
+	stateChan  chan types.State
+	httpClient *http.Client
+	close      chan struct{}
+	closeOnce  sync.Once
+
+	spectrum         *PCMAnalyzer
+	spectrumConsumer func(sampleRate float64, samplesL, samplesR []float32)
+}
+
+func NewNullPlayer(config *NullConfig) *nullPlayer {
+	conf := *config
+	if conf.Speed <= 0 {
+		conf.Speed = 1
+	}
+	if conf.Tick <= 0 {
+		conf.Tick = configs.AppConfig.Main.FrameRate.Interval()
+	}
+	p := &nullPlayer{
+		conf:       conf,
+		volume:     100,
+		timeChan:   make(chan time.Duration, 1),
+		stateChan:  make(chan types.State, 10),
+		httpClient: &http.Client{},
+		close:      make(chan struct{}),
+		cachePath:  filepath.Join(app.RuntimeDir(), "null_playing"),
+	}
+	if conf.Decode && spectrumWanted() {
+		p.spectrum = NewPCMAnalyzer(conf.Tick)
+	}
+	p.state.Store(uint32(types.Stopped))
+	errorx.Go(p.run, true)
+	return p
+}
+
+// run 时钟循环，每个步进按经过的时间乘以倍速推进进度
+func (p *nullPlayer) run() {
+	ticker := time.NewTicker(p.conf.Tick)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-p.close:
+			return
+		case now := <-ticker.C:
+			elapsed := time.Duration(float64(now.Sub(last)) * p.conf.Speed)
+			last = now
+			p.l.Lock()
+			if types.State(p.state.Load()) == types.Playing {
+				p.advanceNoLock(elapsed)
+			}
+			p.l.Unlock()
+		}
+	}
+}
+
+// advanceNoLock 推进 elapsed 的进度，解码模式下同时读取对应的采样
+func (p *nullPlayer) advanceNoLock(elapsed time.Duration) {
+	ended := false
+	if p.stream != nil {
+		n := p.format.SampleRate.N(elapsed)
+		if cap(p.samples) < n {
+			p.samples = make([][2]float64, n)
+		}
+		got, ok := p.stream.Stream(p.samples[:n])
+		p.feedSpectrum(p.samples[:got])
+		elapsed = p.format.SampleRate.D(got)
+		ended = !ok || got < n
+	}
+	p.passed += elapsed
+	p.played += elapsed
+	if p.duration > 0 && p.passed >= p.duration {
+		p.passed, ended = p.duration, true
+	}
+
+	select {
+	case p.timeChan <- p.passed:
+	default:
+	}
+	if ended {
+		p.setState(types.Stopped)
+	}
+}
+
+func (p *nullPlayer) feedSpectrum(samples [][2]float64) {
+	if p.spectrumConsumer == nil || len(samples) == 0 {
+		return
+	}
+	samplesL := make([]float32, len(samples))
+	samplesR := make([]float32, len(samples))
+	for i, s := range samples {
+		samplesL[i], samplesR[i] = float32(s[0]), float32(s[1])
+	}
+	p.spectrumConsumer(float64(p.format.SampleRate), samplesL, samplesR)
+}
+
+func (p *nullPlayer) setState(state types.State) {
+	p.state.Store(uint32(state))
+	select {
+	case p.stateChan <- state:
+	case <-time.After(time.Second * 2):
+	}
+}
+
+// Play 开始计时；解码模式下先在后台下载并解码，完成后才进入播放状态
+func (p *nullPlayer) Play(music URLMusic) {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	p.resetNoLock()
+	p.curMusic = music
+	p.duration = music.Duration
+	if p.spectrum != nil {
+		p.spectrumConsumer = p.spectrum.NewConsumer()
+	}
+	if !p.conf.Decode {
+		if p.duration <= 0 {
+			p.duration = nullDefaultDuration
+		}
+		p.setState(types.Playing)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.loading = cancel
+	errorx.Go(func() { p.load(ctx, music) }, true)
+}
+
+// load 下载到缓存文件并解码，失败时停止播放，由界面按错误处理切到下一首
+func (p *nullPlayer) load(ctx context.Context, music URLMusic) {
+	stream, format, err := p.download(ctx, music)
+	p.l.Lock()
+	defer p.l.Unlock()
+	if ctx.Err() != nil {
+		if stream != nil {
+			_ = stream.Close()
+		}
+		return
+	}
+	p.loading = nil
+	if err != nil {
+		slog.Error("null player: load song failed", slog.Int64("song_id", music.Id), slogx.Error(err))
+		p.setState(types.Stopped)
+		return
+	}
+	p.stream, p.format = stream, format
+	if length := format.SampleRate.D(stream.Len()); length > 0 {
+		p.duration = length
+	}
+	p.setState(types.Playing)
+}
+
+func (p *nullPlayer) download(ctx context.Context, music URLMusic) (beep.StreamSeekCloser, beep.Format, error) {
+	var reader io.ReadCloser
+	if path, ok := strings.CutPrefix(music.URL, "file://"); ok {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, beep.Format{}, err
+		}
+		reader = file
+	} else {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, music.URL, nil)
+		if err != nil {
+			return nil, beep.Format{}, err
+		}
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			return nil, beep.Format{}, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return nil, beep.Format{}, fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+		reader = resp.Body
+	}
+
+	file, err := os.Create(p.cachePath)
+	if err != nil {
+		_ = reader.Close()
+		return nil, beep.Format{}, err
+	}
+	if _, err = iox.CopyClose(ctx, file, reader); err != nil {
+		_ = file.Close()
+		return nil, beep.Format{}, err
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, beep.Format{}, err
+	}
+	stream, format, err := decodeSong(music.Type, file, music.Duration, true)
+	if err != nil {
+		_ = file.Close()
+		return nil, beep.Format{}, err
+	}
+	return stream, format, nil
+}
+
+// resetNoLock 取消下载并释放上一首的解码器
+func (p *nullPlayer) resetNoLock() {
+	if p.loading != nil {
+		p.loading()
+		p.loading = nil
+	}
+	if p.stream != nil {
+		_ = p.stream.Close()
+		p.stream = nil
+	}
+	p.passed, p.played, p.duration = 0, 0, 0
+	p.spectrumConsumer = nil
+}
+
+func (p *nullPlayer) CurMusic() URLMusic {
+	p.l.Lock()
+	defer p.l.Unlock()
+	return p.curMusic
+}
+
+func (p *nullPlayer) Pause() {
+	if p.State() != types.Playing {
+		return
+	}
+	p.setState(types.Paused)
+}
+
+func (p *nullPlayer) Resume() {
+	p.l.Lock()
+	defer p.l.Unlock()
+	// 解码模式下仍在加载，加载完成后自动开始
+	if p.State() == types.Playing || p.loading != nil || p.curMusic.Id == 0 && p.curMusic.URL == "" {
+		return
+	}
+	p.setState(types.Playing)
+}
+
+func (p *nullPlayer) Stop() {
+	if p.State() == types.Stopped {
+		return
+	}
+	p.setState(types.Stopped)
+}
+
+func (p *nullPlayer) Toggle() {
+	switch p.State() {
+	case types.Playing:
+		p.Pause()
+	default:
+		p.Resume()
+	}
+}
+
+func (p *nullPlayer) Seek(duration time.Duration) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	if duration < 0 {
+		duration = 0
+	}
+	if p.duration > 0 && duration > p.duration {
+		duration = p.duration
+	}
+	if p.stream != nil {
+		pos := min(p.format.SampleRate.N(duration), p.stream.Len())
+		if err := p.stream.Seek(pos); err != nil {
+			slog.Error("null player: seek error", slogx.Error(err))
+			return
+		}
+	}
+	p.passed = duration
+}
+
+func (p *nullPlayer) PassedTime() time.Duration {
+	p.l.Lock()
+	defer p.l.Unlock()
+	return p.passed
+}
+
+func (p *nullPlayer) PlayedTime() time.Duration {
+	p.l.Lock()
+	defer p.l.Unlock()
+	return p.played
+}
+
+func (p *nullPlayer) TimeChan() <-chan time.Duration {
+	return p.timeChan
+}
+
+func (p *nullPlayer) State() types.State {
+	return types.State(p.state.Load())
+}
+
+func (p *nullPlayer) StateChan() <-chan types.State {
+	return p.stateChan
+}
+
+func (p *nullPlayer) Volume() int {
+	p.l.Lock()
+	defer p.l.Unlock()
+	return p.volume
+}
+
+func (p *nullPlayer) SetVolume(volume int) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.volume = min(max(volume, 0), 100)
+}
+
+func (p *nullPlayer) UpVolume() {
+	p.SetVolume(p.Volume() + 5)
+}
+
+func (p *nullPlayer) DownVolume() {
+	p.SetVolume(p.Volume() - 5)
+}
+
+func (p *nullPlayer) Spectrum() SpectrumFrame {
+	if p.spectrum == nil {
+		return SpectrumFrame{}
+	}
+	return p.spectrum.Spectrum()
+}
+
+func (p *nullPlayer) RawSamples() RawSampleFrame {
+	if p.spectrum == nil {
+		return RawSampleFrame{}
+	}
+	return p.spectrum.RawSamples()
+}
+
+func (p *nullPlayer) Close() {
+	p.closeOnce.Do(func() {
+		close(p.close)
+		p.l.Lock()
+		p.resetNoLock()
+		p.l.Unlock()
+		if p.spectrum != nil {
+			p.spectrum.Close()
+		}
+		_ = os.Remove(p.cachePath)
+	})
+}
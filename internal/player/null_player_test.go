@@ -0,0 +1,118 @@
+package player
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+func waitState(t *testing.T, p *nullPlayer, want types.State) {
+	t.Helper()
+	for {
+		select {
+		case s := <-p.StateChan():
+			if s == want {
+				return
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for state %v, current %v", want, p.State())
+		}
+	}
+}
+
+func TestNullPlayerClock(t *testing.T) {
+	previousConfig := configs.AppConfig
+	configs.AppConfig = &configs.Config{}
+	t.Cleanup(func() { configs.AppConfig = previousConfig })
+
+	p := NewNullPlayer(&NullConfig{Speed: 100, Tick: 5 * time.Millisecond})
+	defer p.Close()
+
+	p.Play(URLMusic{URL: "http://example.com/a.mp3", Song: structs.Song{Id: 1, Duration: 2 * time.Second}})
+	waitState(t, p, types.Playing)
+
+	p.Pause()
+	waitState(t, p, types.Paused)
+	paused := p.PassedTime()
+	time.Sleep(30 * time.Millisecond)
+	if p.PassedTime() != paused {
+		t.Errorf("position advanced while paused: %v -> %v", paused, p.PassedTime())
+	}
+
+	p.Seek(time.Second)
+	p.Resume()
+	waitState(t, p, types.Playing)
+	select {
+	case d := <-p.TimeChan():
+		if d < time.Second {
+			t.Errorf("time after seek = %v, want >= 1s", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no time event after resume")
+	}
+
+	waitState(t, p, types.Stopped)
+	if p.PassedTime() != 2*time.Second {
+		t.Errorf("PassedTime at end = %v, want 2s", p.PassedTime())
+	}
+	if played := p.PlayedTime(); played >= 2*time.Second {
+		t.Errorf("PlayedTime = %v, should exclude the seek", played)
+	}
+}
+
+// writeTestWav 写入 duration 时长的 16 位立体声静音 WAV
+func writeTestWav(t *testing.T, duration time.Duration) string {
+	t.Helper()
+	const rate = 8000
+	dataSize := uint32(rate*duration/time.Second) * 4
+	h := make([]byte, 44)
+	copy(h, "RIFF")
+	binary.LittleEndian.PutUint32(h[4:], 36+dataSize)
+	copy(h[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(h[16:], 16)
+	binary.LittleEndian.PutUint16(h[20:], 1)
+	binary.LittleEndian.PutUint16(h[22:], 2)
+	binary.LittleEndian.PutUint32(h[24:], rate)
+	binary.LittleEndian.PutUint32(h[28:], rate*4)
+	binary.LittleEndian.PutUint16(h[32:], 4)
+	binary.LittleEndian.PutUint16(h[34:], 16)
+	copy(h[36:], "data")
+	binary.LittleEndian.PutUint32(h[40:], dataSize)
+
+	path := filepath.Join(t.TempDir(), "silence.wav")
+	if err := os.WriteFile(path, append(h, make([]byte, dataSize)...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNullPlayerDecode(t *testing.T) {
+	previousConfig := configs.AppConfig
+	configs.AppConfig = &configs.Config{}
+	t.Cleanup(func() { configs.AppConfig = previousConfig })
+
+	p := NewNullPlayer(&NullConfig{Speed: 100, Decode: true, Tick: 5 * time.Millisecond})
+	p.cachePath = filepath.Join(t.TempDir(), "null_playing")
+	defer p.Close()
+
+	// 歌曲信息中的时长不准确时以解码结果为准
+	p.Play(URLMusic{
+		URL:  "file://" + writeTestWav(t, time.Second),
+		Song: structs.Song{Id: 1, Duration: time.Minute},
+		Type: Wav,
+	})
+	waitState(t, p, types.Playing)
+	waitState(t, p, types.Stopped)
+	if d := p.PassedTime(); d != time.Second {
+		t.Errorf("PassedTime at end = %v, want 1s", d)
+	}
+
+	p.Play(URLMusic{URL: "file:///nonexistent.wav", Song: structs.Song{Id: 2}, Type: Wav})
+	waitState(t, p, types.Stopped)
+}
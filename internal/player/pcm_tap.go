@@ -0,0 +1,193 @@
+package player
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/utils/errorx"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+var errPCMTapUnsupported = errors.New("当前系统不支持命名管道")
+
+// pcmChunksPerSecond 每秒读取的块数，决定送入频谱分析器的粒度
+const pcmChunksPerSecond = 50
+
+// pcmFormat 命名管道中交错 PCM 的格式，仅支持 16 位小端
+type pcmFormat struct {
+	SampleRate int
+	Channels   int
+}
+
+// parsePCMFormat 解析 MPD 风格的格式字符串 "采样率:位深:声道数"，如 "44100:16:2"
+func parsePCMFormat(s string) (pcmFormat, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 3 {
+		return pcmFormat{}, fmt.Errorf("无效的 PCM 格式 %q，应为 \"采样率:位深:声道数\"", s)
+	}
+	rate, err := strconv.Atoi(parts[0])
+	if err != nil || rate <= 0 {
+		return pcmFormat{}, fmt.Errorf("无效的采样率 %q", parts[0])
+	}
+	if parts[1] != "16" {
+		return pcmFormat{}, fmt.Errorf("仅支持 16 位 PCM，当前为 %q", parts[1])
+	}
+	channels, err := strconv.Atoi(parts[2])
+	if err != nil || channels < 1 || channels > 2 {
+		return pcmFormat{}, fmt.Errorf("仅支持单声道或立体声，当前为 %q", parts[2])
+	}
+	return pcmFormat{SampleRate: rate, Channels: channels}, nil
+}
+
+func (f pcmFormat) frameBytes() int {
+	return 2 * f.Channels
+}
+
+// decodeS16LE 把交错的 s16le 数据拆成左右声道，单声道时左右相同
+func decodeS16LE(buf []byte, channels int) (samplesL, samplesR []float32) {
+	frames := len(buf) / (2 * channels)
+	samplesL = make([]float32, frames)
+	samplesR = make([]float32, frames)
+	for i := range frames {
+		off := i * 2 * channels
+		samplesL[i] = float32(int16(binary.LittleEndian.Uint16(buf[off:]))) / math.MaxInt16
+		if channels == 1 {
+			samplesR[i] = samplesL[i]
+			continue
+		}
+		samplesR[i] = float32(int16(binary.LittleEndian.Uint16(buf[off+2:]))) / math.MaxInt16
+	}
+	return
+}
+
+// pcmTap 从命名管道读取外部播放器旁路输出的 PCM，喂给频谱分析器
+//
+// MPD 的 fifo 输出自身按实时速率写入；mpv 的 --ao=pcm 会尽快解码，
+// 此时 paced 为 true，由读取端按采样率限速，写入端随管道写满而阻塞。
+type pcmTap struct {
+	format   pcmFormat
+	paced    bool
+	file     *os.File
+	analyzer *PCMAnalyzer
+
+	mu       sync.Mutex
+	consumer func(sampleRate float64, samplesL, samplesR []float32)
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+}
+
+// newPCMTap 打开（不存在时创建）命名管道并开始读取
+func newPCMTap(path string, format pcmFormat, paced bool) (*pcmTap, error) {
+	if err := makeFifo(path); err != nil {
+		return nil, err
+	}
+	file, err := openFifo(path)
+	if err != nil {
+		return nil, err
+	}
+	t := &pcmTap{
+		format:   format,
+		paced:    paced,
+		file:     file,
+		analyzer: NewPCMAnalyzer(configs.AppConfig.Main.FrameRate.Interval()),
+		closeCh:  make(chan struct{}),
+	}
+	t.consumer = t.analyzer.NewConsumer()
+	errorx.Go(t.run, true)
+	return t, nil
+}
+
+// run 持续读取管道；写入端尚未打开或已关闭时读到 EOF，稍后重试
+func (t *pcmTap) run() {
+	for {
+		err := t.consume(t.file)
+		select {
+		case <-t.closeCh:
+			return
+		default:
+		}
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			slog.Warn("PCM 旁路读取失败", slogx.Error(err))
+		}
+		select {
+		case <-t.closeCh:
+			return
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// consume 按块读取 r 直到出错，每块解码后交给当前的 consumer
+func (t *pcmTap) consume(r io.Reader) error {
+	chunkFrames := max(t.format.SampleRate/pcmChunksPerSecond, 1)
+	chunkDuration := time.Second / pcmChunksPerSecond
+	buf := make([]byte, chunkFrames*t.format.frameBytes())
+	next := time.Now()
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return err
+		}
+		samplesL, samplesR := decodeS16LE(buf, t.format.Channels)
+		t.mu.Lock()
+		consumer := t.consumer
+		t.mu.Unlock()
+		consumer(float64(t.format.SampleRate), samplesL, samplesR)
+
+		if !t.paced {
+			continue
+		}
+		next = next.Add(chunkDuration)
+		wait := time.Until(next)
+		if wait < -time.Second {
+			// 暂停或写入端阻塞过久，重新对齐时钟，避免随后突发读取
+			next = time.Now()
+			continue
+		}
+		if wait > 0 {
+			select {
+			case <-t.closeCh:
+				return nil
+			case <-time.After(wait):
+			}
+		}
+	}
+}
+
+// Reset 切歌时清空频谱，避免上一首的残留数据
+func (t *pcmTap) Reset() {
+	t.mu.Lock()
+	t.consumer = t.analyzer.NewConsumer()
+	t.mu.Unlock()
+}
+
+func (t *pcmTap) Spectrum() SpectrumFrame {
+	return t.analyzer.Spectrum()
+}
+
+func (t *pcmTap) RawSamples() RawSampleFrame {
+	return t.analyzer.RawSamples()
+}
+
+func (t *pcmTap) Close() {
+	t.closeOnce.Do(func() {
+		close(t.closeCh)
+		_ = t.file.Close()
+		t.analyzer.Close()
+	})
+}
+
+// spectrumWanted 可视化或桌面歌词频谱开启时才需要采集 PCM
+func spectrumWanted() bool {
+	return configs.AppConfig.Main.Visualizer.Enable || (configs.AppConfig.Main.Lyric.DesktopLyrics.SpectrumEnabled && desktopLyricsAvailable)
+}
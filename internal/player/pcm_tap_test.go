@@ -0,0 +1,95 @@
+package player
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+)
+
+func TestParsePCMFormat(t *testing.T) {
+	format, err := parsePCMFormat(" 48000:16:1 ")
+	if err != nil || format != (pcmFormat{SampleRate: 48000, Channels: 1}) {
+		t.Fatalf("parsePCMFormat = %+v, %v", format, err)
+	}
+	for _, invalid := range []string{"", "44100:16", "44100:24:2", "44100:16:6", "x:16:2", "0:16:2"} {
+		if _, err := parsePCMFormat(invalid); err == nil {
+			t.Errorf("parsePCMFormat(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestDecodeS16LE(t *testing.T) {
+	buf := binary.LittleEndian.AppendUint16(nil, uint16(math.MaxInt16))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(0))
+	buf = binary.LittleEndian.AppendUint16(buf, 0x8001) // -32767
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(math.MaxInt16/2+1))
+
+	samplesL, samplesR := decodeS16LE(buf, 2)
+	if len(samplesL) != 2 || samplesL[0] != 1 || samplesR[0] != 0 || samplesL[1] != -1 || samplesR[1] < 0.49 || samplesR[1] > 0.51 {
+		t.Errorf("stereo = %v %v", samplesL, samplesR)
+	}
+
+	samplesL, samplesR = decodeS16LE(buf[:4], 1)
+	if len(samplesL) != 2 || samplesL[0] != 1 || samplesR[0] != 1 || samplesR[1] != 0 {
+		t.Errorf("mono = %v %v", samplesL, samplesR)
+	}
+}
+
+func TestPCMTapReadsFifo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("named pipes are not supported")
+	}
+	previousConfig := configs.AppConfig
+	configs.AppConfig = &configs.Config{}
+	t.Cleanup(func() { configs.AppConfig = previousConfig })
+
+	path := filepath.Join(t.TempDir(), "pcm.fifo")
+	tap, err := newPCMTap(path, pcmFormat{SampleRate: 8000, Channels: 2}, false)
+	if err != nil {
+		t.Fatalf("newPCMTap: %v", err)
+	}
+	defer tap.Close()
+
+	// 写入端晚于读取端打开，读取端应在 EOF 后继续等待
+	time.Sleep(300 * time.Millisecond)
+	writer, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open writer: %v", err)
+	}
+	defer writer.Close()
+
+	var buf []byte
+	for i := range 1600 {
+		v := int16(math.Sin(2*math.Pi*440*float64(i)/8000) * 16000)
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(v))
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(-v))
+	}
+	if _, err = writer.Write(buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		frame := tap.RawSamples()
+		if frame.Count > 0 {
+			if frame.SampleRate != 8000 {
+				t.Errorf("sample rate = %v", frame.SampleRate)
+			}
+			if frame.SamplesL[1] != -frame.SamplesR[1] {
+				t.Errorf("channels not split: %v %v", frame.SamplesL[1], frame.SamplesR[1])
+			}
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatal("no samples read from fifo")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+
+package player
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// makeFifo 创建命名管道，已存在时检查其类型
+func makeFifo(path string) error {
+	info, err := os.Stat(path)
+	if err == nil {
+		if info.Mode()&os.ModeNamedPipe == 0 {
+			return fmt.Errorf("%s 已存在且不是命名管道", path)
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	if err = syscall.Mkfifo(path, 0o600); err != nil && !os.IsExist(err) {
+		return fmt.Errorf("创建命名管道失败: %w", err)
+	}
+	return nil
+}
+
+// openFifo 以非阻塞方式打开读取端，不必等待写入端就绪，Close 时可中断读取
+func openFifo(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package player
+
+import "os"
+
+func makeFifo(string) error {
+	return errPCMTapUnsupported
+}
+
+func openFifo(string) (*os.File, error) {
+	return nil, errPCMTapUnsupported
+}
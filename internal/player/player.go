@@ -1,6 +1,7 @@
 package player
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -44,6 +45,34 @@ type GaplessPlayer interface {
 	GaplessTransitionChan() <-chan GaplessTransition
 }
 
+// EqualizerPlayer is implemented by players that apply the parametric
+// equalizer themselves and can change its settings while playing.
+type EqualizerPlayer interface {
+	SetEqualizer(enabled bool, preset configs.EqualizerPreset)
+	Equalizer() (enabled bool, preset configs.EqualizerPreset)
+}
+
+// OutputFormat describes the PCM format a player sends to the audio device.
+type OutputFormat struct {
+	SourceRate int // sample rate of the decoded track
+	SampleRate int // sample rate of the audio device
+	BitDepth   int
+}
+
+// OutputFormatPlayer is implemented by players that drive the audio device
+// themselves and know the format it was opened with.
+type OutputFormatPlayer interface {
+	OutputFormat() (OutputFormat, bool)
+}
+
+// RendererPlayer is implemented by players that cast to a network renderer
+// and can switch to another renderer while running.
+type RendererPlayer interface {
+	Renderer() (DlnaRenderer, bool)
+	DiscoverRenderers(ctx context.Context) ([]DlnaRenderer, error)
+	SwitchRenderer(location string) error
+}
+
 func NewPlayerFromConfig() Player {
 	cfg := configs.AppConfig
 	var player Player
@@ -66,6 +95,8 @@ func NewPlayerFromConfig() Player {
 			Network:    cfg.Player.Mpd.Network,
 			Address:    cfg.Player.Mpd.Addr,
 			AutoStart:  cfg.Player.Mpd.AutoStart,
+			FifoPath:   cfg.Player.Mpd.FifoPath,
+			FifoFormat: cfg.Player.Mpd.FifoFormat,
 		})
 	case types.OsxPlayer:
 		player = NewOsxPlayer()
@@ -79,18 +110,18 @@ func NewPlayerFromConfig() Player {
 		}
 		player = NewMpvPlayer(&MpvConfig{
 			BinPath: cfg.Player.Mpv.Bin,
+			PCMTap:  cfg.Player.Mpv.PCMTap,
 		})
 	case types.DlnaPlayer:
-		if cfg.Player.Dlna.DeviceUrl == "" {
-			panic("缺少DLNA配置: deviceUrl")
-		}
-		if cfg.Player.Dlna.LocalIP == "" {
-			panic("缺少DLNA配置: localIP (必须指定本机局域网IP)")
-		}
-		player, err = NewDlnaPlayer(cfg.Player.Dlna.DeviceUrl, cfg.Player.Dlna.LocalIP)
+		player, err = newDlnaPlayerFromConfig(cfg.Player.Dlna)
 		if err != nil {
 			panic(err)
 		}
+	case types.NullPlayer:
+		player = NewNullPlayer(&NullConfig{
+			Speed:  cfg.Player.Null.Speed,
+			Decode: cfg.Player.Null.Decode,
+		})
 	default:
 		panic("unknown player engine")
 	}
@@ -10,6 +10,8 @@ const (
 	Wav
 	Ogg
 	Flac
+	Aac  // MP4/M4A 或 ADTS 封装的 AAC
+	Opus // Ogg 封装的 Opus
 )
 
 var SongTypeMapping = map[string]SongType{
@@ -17,6 +19,10 @@ var SongTypeMapping = map[string]SongType{
 	"wav":  Wav,
 	"ogg":  Ogg,
 	"flac": Flac,
+	"m4a":  Aac,
+	"mp4":  Aac,
+	"aac":  Aac,
+	"opus": Opus,
 }
 
 type URLMusic struct {
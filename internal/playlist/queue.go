@@ -0,0 +1,234 @@
+package playlist
+
+import (
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+// Queue 待播队列，队列中的歌曲在播放列表的下一首之前播放
+//
+// 队列独立于播放列表：不受播放模式（随机等）影响，切换播放列表后仍然保留，并持久化到存储。
+// 零值可直接使用。
+type Queue struct {
+	mu      sync.RWMutex
+	playing *structs.Song  // 正在播放的队列歌曲
+	songs   []structs.Song // 等待播放的歌曲
+}
+
+// Songs 等待播放的歌曲
+func (q *Queue) Songs() []structs.Song {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return slices.Clone(q.songs)
+}
+
+// Len 等待播放的歌曲数
+func (q *Queue) Len() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return len(q.songs)
+}
+
+// Current 正在播放的队列歌曲，播放的是播放列表中的歌曲时返回 false
+func (q *Queue) Current() (structs.Song, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.playing == nil {
+		return structs.Song{}, false
+	}
+	return *q.playing, true
+}
+
+// Peek 下一首要播放的队列歌曲
+func (q *Queue) Peek() (structs.Song, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if len(q.songs) == 0 {
+		return structs.Song{}, false
+	}
+	return q.songs[0], true
+}
+
+// PushFront 添加到队列开头，作为下一曲播放
+func (q *Queue) PushFront(songs ...structs.Song) {
+	_ = q.Insert(0, songs...)
+}
+
+// PushBack 添加到队列末尾
+func (q *Queue) PushBack(songs ...structs.Song) {
+	q.mu.Lock()
+	q.songs = append(q.songs, songs...)
+	q.mu.Unlock()
+	go q.saveStateAsync()
+}
+
+// Insert 插入到队列的 index 位置
+func (q *Queue) Insert(index int, songs ...structs.Song) error {
+	q.mu.Lock()
+	if index < 0 || index > len(q.songs) {
+		q.mu.Unlock()
+		return newPlaylistError("queue insert", ErrIndexOutOfRange)
+	}
+	q.songs = slices.Insert(q.songs, index, songs...)
+	q.mu.Unlock()
+	go q.saveStateAsync()
+	return nil
+}
+
+// Advance 取出队列的第一首作为正在播放的歌曲，队列为空时返回 false 并清除正在播放的队列歌曲
+func (q *Queue) Advance() (structs.Song, bool) {
+	q.mu.Lock()
+	if len(q.songs) == 0 {
+		changed := q.playing != nil
+		q.playing = nil
+		q.mu.Unlock()
+		if changed {
+			go q.saveStateAsync()
+		}
+		return structs.Song{}, false
+	}
+	song := q.songs[0]
+	q.songs = slices.Delete(q.songs, 0, 1)
+	q.playing = &song
+	q.mu.Unlock()
+	go q.saveStateAsync()
+	return song, true
+}
+
+// Take 取出 index 位置的歌曲作为正在播放的歌曲
+func (q *Queue) Take(index int) (structs.Song, error) {
+	q.mu.Lock()
+	if index < 0 || index >= len(q.songs) {
+		q.mu.Unlock()
+		return structs.Song{}, newPlaylistError("queue take", ErrIndexOutOfRange)
+	}
+	song := q.songs[index]
+	q.songs = slices.Delete(q.songs, index, index+1)
+	q.playing = &song
+	q.mu.Unlock()
+	go q.saveStateAsync()
+	return song, nil
+}
+
+// Requeue 把正在播放的队列歌曲放回队列开头，用于从队列歌曲回到上一首
+func (q *Queue) Requeue() bool {
+	q.mu.Lock()
+	if q.playing == nil {
+		q.mu.Unlock()
+		return false
+	}
+	q.songs = slices.Insert(q.songs, 0, *q.playing)
+	q.playing = nil
+	q.mu.Unlock()
+	go q.saveStateAsync()
+	return true
+}
+
+// ClearCurrent 开始播放播放列表中的歌曲
+func (q *Queue) ClearCurrent() {
+	q.mu.Lock()
+	changed := q.playing != nil
+	q.playing = nil
+	q.mu.Unlock()
+	if changed {
+		go q.saveStateAsync()
+	}
+}
+
+// Remove 移除 index 位置的歌曲
+func (q *Queue) Remove(index int) (structs.Song, error) {
+	q.mu.Lock()
+	if index < 0 || index >= len(q.songs) {
+		q.mu.Unlock()
+		return structs.Song{}, newPlaylistError("queue remove", ErrIndexOutOfRange)
+	}
+	song := q.songs[index]
+	q.songs = slices.Delete(q.songs, index, index+1)
+	q.mu.Unlock()
+	go q.saveStateAsync()
+	return song, nil
+}
+
+// Move 把 from 位置的歌曲移动到 to 位置
+func (q *Queue) Move(from, to int) error {
+	q.mu.Lock()
+	if from < 0 || from >= len(q.songs) || to < 0 || to >= len(q.songs) {
+		q.mu.Unlock()
+		return newPlaylistError("queue move", ErrIndexOutOfRange)
+	}
+	song := q.songs[from]
+	q.songs = slices.Insert(slices.Delete(q.songs, from, from+1), to, song)
+	q.mu.Unlock()
+	go q.saveStateAsync()
+	return nil
+}
+
+// Clear 清空等待播放的歌曲
+func (q *Queue) Clear() {
+	q.mu.Lock()
+	q.songs = nil
+	q.mu.Unlock()
+	go q.saveStateAsync()
+}
+
+func (q *Queue) snapshot() storage.UpNextQueue {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	snapshot := storage.UpNextQueue{Songs: slices.Clone(q.songs)}
+	if q.playing != nil {
+		playing := *q.playing
+		snapshot.Playing = &playing
+	}
+	return snapshot
+}
+
+// SaveState 保存队列到存储
+func (q *Queue) SaveState() error {
+	if storage.DBManager == nil {
+		// 存储未初始化（如测试环境）时跳过保存
+		return nil
+	}
+	snapshot := q.snapshot()
+	table := storage.NewTable()
+	return table.SetByKVModel(snapshot, snapshot)
+}
+
+// LoadState 从存储加载队列
+func (q *Queue) LoadState() error {
+	if storage.DBManager == nil {
+		return nil
+	}
+
+	table := storage.NewTable()
+	jsonStr, err := table.GetByKVModel(storage.UpNextQueue{})
+	if err != nil || len(jsonStr) == 0 {
+		return err
+	}
+	var snapshot storage.UpNextQueue
+	if err = json.Unmarshal(jsonStr, &snapshot); err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.playing = snapshot.Playing
+	q.songs = snapshot.Songs
+	return nil
+}
+
+// saveStateAsync 异步保存状态，避免阻塞主线程
+func (q *Queue) saveStateAsync() {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("panic when Queue.saveStateAsync", slog.Any("err", r))
+		}
+	}()
+	if err := q.SaveState(); err != nil {
+		slog.Warn("save up next queue failed", slog.Any("err", err))
+	}
+}
@@ -0,0 +1,107 @@
+package playlist
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+func queueIds(q *Queue) []int64 {
+	var ids []int64
+	for _, song := range q.Songs() {
+		ids = append(ids, song.Id)
+	}
+	return ids
+}
+
+func equalIds(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestQueueOrder(t *testing.T) {
+	var q Queue
+	q.PushBack(structs.Song{Id: 2}, structs.Song{Id: 3})
+	q.PushFront(structs.Song{Id: 1})
+	if err := q.Insert(3, structs.Song{Id: 4}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if err := q.Insert(5, structs.Song{Id: 5}); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Insert out of range err = %v", err)
+	}
+	if got := queueIds(&q); !equalIds(got, []int64{1, 2, 3, 4}) {
+		t.Fatalf("queue = %v", got)
+	}
+
+	if err := q.Move(3, 0); err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	if err := q.Move(1, 2); err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	if got := queueIds(&q); !equalIds(got, []int64{4, 2, 1, 3}) {
+		t.Fatalf("queue after move = %v", got)
+	}
+	if song, err := q.Remove(1); err != nil || song.Id != 2 {
+		t.Errorf("Remove(1) = %d, %v", song.Id, err)
+	}
+	if got := queueIds(&q); !equalIds(got, []int64{4, 1, 3}) {
+		t.Fatalf("queue after remove = %v", got)
+	}
+}
+
+func TestQueuePlaying(t *testing.T) {
+	var q Queue
+	if _, ok := q.Current(); ok {
+		t.Fatal("empty queue should not have a playing song")
+	}
+	q.PushBack(structs.Song{Id: 1}, structs.Song{Id: 2}, structs.Song{Id: 3})
+
+	if song, ok := q.Advance(); !ok || song.Id != 1 {
+		t.Fatalf("Advance = %d, %v", song.Id, ok)
+	}
+	if song, ok := q.Current(); !ok || song.Id != 1 {
+		t.Errorf("Current = %d, %v", song.Id, ok)
+	}
+
+	// 回到上一首时队列歌曲放回开头
+	if !q.Requeue() {
+		t.Fatal("Requeue should succeed while playing a queued song")
+	}
+	if _, ok := q.Current(); ok {
+		t.Error("Current after Requeue should be empty")
+	}
+	if got := queueIds(&q); !equalIds(got, []int64{1, 2, 3}) {
+		t.Fatalf("queue after requeue = %v", got)
+	}
+
+	if song, err := q.Take(2); err != nil || song.Id != 3 {
+		t.Fatalf("Take(2) = %d, %v", song.Id, err)
+	}
+	if song, _ := q.Current(); song.Id != 3 {
+		t.Errorf("Current after Take = %d", song.Id)
+	}
+	q.Clear()
+	if q.Len() != 0 {
+		t.Errorf("Len after Clear = %d", q.Len())
+	}
+	if _, ok := q.Current(); !ok {
+		t.Error("Clear should keep the playing song")
+	}
+
+	// 队列播完后回到播放列表
+	if _, ok := q.Advance(); ok {
+		t.Error("Advance on empty queue should fail")
+	}
+	if _, ok := q.Current(); ok {
+		t.Error("Advance on empty queue should clear the playing song")
+	}
+}
@@ -0,0 +1,55 @@
+package playlistfile
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type jsonPlaylist struct {
+	Name   string      `json:"name"`
+	Tracks []jsonTrack `json:"tracks"`
+}
+
+type jsonTrack struct {
+	Location string   `json:"location"`
+	Title    string   `json:"title,omitempty"`
+	Artists  []string `json:"artists,omitempty"`
+	Album    string   `json:"album,omitempty"`
+	Duration int64    `json:"duration,omitempty"` // 毫秒
+}
+
+func encodeJSON(w io.Writer, name string, tracks []track) error {
+	playlist := jsonPlaylist{Name: name, Tracks: make([]jsonTrack, 0, len(tracks))}
+	for _, t := range tracks {
+		playlist.Tracks = append(playlist.Tracks, jsonTrack{
+			Location: t.Location,
+			Title:    t.Title,
+			Artists:  t.Artists,
+			Album:    t.Album,
+			Duration: t.Duration.Milliseconds(),
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(playlist)
+}
+
+func decodeJSON(r io.Reader) (string, []track, error) {
+	var playlist jsonPlaylist
+	if err := json.NewDecoder(r).Decode(&playlist); err != nil {
+		return "", nil, err
+	}
+	tracks := make([]track, 0, len(playlist.Tracks))
+	for _, j := range playlist.Tracks {
+		tracks = append(tracks, track{
+			Location: j.Location,
+			Title:    j.Title,
+			Artists:  j.Artists,
+			Album:    j.Album,
+			Duration: time.Duration(j.Duration) * time.Millisecond,
+		})
+	}
+	return playlist.Name, tracks, nil
+}
@@ -0,0 +1,96 @@
+package playlistfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	m3uHeader   = "#EXTM3U"
+	m3uPlaylist = "#PLAYLIST:"
+	m3uExtInf   = "#EXTINF:"
+)
+
+// encodeM3U8 扩展 M3U：#EXTINF:<秒数>,<歌手> - <歌名> 后跟位置
+func encodeM3U8(w io.Writer, name string, tracks []track) error {
+	bw := bufio.NewWriter(w)
+	_, _ = fmt.Fprintln(bw, m3uHeader)
+	if name != "" {
+		_, _ = fmt.Fprintln(bw, m3uPlaylist+oneLine(name))
+	}
+	for _, t := range tracks {
+		seconds := -1
+		if t.Duration > 0 {
+			seconds = int(t.Duration.Round(time.Second) / time.Second)
+		}
+		display := oneLine(t.Title)
+		if len(t.Artists) > 0 {
+			display = oneLine(strings.Join(t.Artists, ",")) + " - " + display
+		}
+		_, _ = fmt.Fprintf(bw, "%s%d,%s\n%s\n", m3uExtInf, seconds, display, t.Location)
+	}
+	return bw.Flush()
+}
+
+func decodeM3U8(r io.Reader) (string, []track, error) {
+	var (
+		name    string
+		tracks  []track
+		pending track
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, m3uPlaylist):
+			name = strings.TrimSpace(strings.TrimPrefix(line, m3uPlaylist))
+		case strings.HasPrefix(line, m3uExtInf):
+			pending = parseExtInf(strings.TrimPrefix(line, m3uExtInf))
+		case strings.HasPrefix(line, "#"):
+			// 其他注释与扩展标签
+		default:
+			pending.Location = line
+			tracks = append(tracks, pending)
+			pending = track{}
+		}
+	}
+	return name, tracks, scanner.Err()
+}
+
+// parseExtInf 解析 #EXTINF 之后的 "<秒数>[ 属性...],<歌手> - <歌名>"
+func parseExtInf(s string) track {
+	var t track
+	info, display, _ := strings.Cut(s, ",")
+	if fields := strings.Fields(info); len(fields) > 0 {
+		if seconds, err := strconv.ParseFloat(fields[0], 64); err == nil && seconds > 0 {
+			t.Duration = time.Duration(seconds * float64(time.Second))
+		}
+	}
+	display = strings.TrimSpace(display)
+	if artists, title, ok := strings.Cut(display, " - "); ok {
+		t.Title = strings.TrimSpace(title)
+		for _, artist := range strings.Split(artists, ",") {
+			if artist = strings.TrimSpace(artist); artist != "" {
+				t.Artists = append(t.Artists, artist)
+			}
+		}
+	} else {
+		t.Title = display
+	}
+	return t
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
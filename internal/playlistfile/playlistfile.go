@@ -0,0 +1,225 @@
+// Package playlistfile 以 M3U8、XSPF、JSON 格式导入导出歌单，与其他播放器交换
+//
+// 网易云歌曲以网页链接（https://music.163.com/#/song?id=N）作为位置，本地音乐为文件路径。
+package playlistfile
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/library"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/utils/netease"
+)
+
+// Format 歌单文件格式
+type Format string
+
+const (
+	FormatM3U8 Format = "m3u8"
+	FormatXSPF Format = "xspf"
+	FormatJSON Format = "json"
+)
+
+// Formats 支持的格式，导出时默认使用第一个
+var Formats = []Format{FormatM3U8, FormatXSPF, FormatJSON}
+
+// FormatFromPath 根据扩展名判断格式，.m3u 视为 UTF-8 编码的 M3U8
+func FormatFromPath(path string) (Format, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".m3u8", ".m3u":
+		return FormatM3U8, nil
+	case ".xspf":
+		return FormatXSPF, nil
+	case ".json":
+		return FormatJSON, nil
+	default:
+		return "", errors.Errorf("不支持的歌单格式: %q，可选 .m3u8、.xspf、.json", ext)
+	}
+}
+
+// Playlist 导入导出的歌单
+type Playlist struct {
+	Name    string
+	Songs   []structs.Song
+	Skipped int // 导入时无法识别的条目数
+}
+
+// track 各格式共用的条目，位置为本地文件路径或网易云歌曲链接
+type track struct {
+	Location string
+	Title    string
+	Artists  []string
+	Album    string
+	Duration time.Duration
+}
+
+func trackOfSong(song structs.Song) track {
+	t := track{
+		Location: song.LocalPath,
+		Title:    song.Name,
+		Album:    song.Album.Name,
+		Duration: song.Duration,
+	}
+	if !song.IsLocal() {
+		t.Location = netease.WebUrlOfSong(song.Id)
+	}
+	for _, artist := range song.Artists {
+		t.Artists = append(t.Artists, artist.Name)
+	}
+	return t
+}
+
+// song 将条目还原为歌曲，baseDir 用于解析相对路径；无法识别的位置返回 false
+func (t track) song(baseDir string) (structs.Song, bool) {
+	song := structs.Song{Name: t.Title, Duration: t.Duration}
+	song.Album.Name = t.Album
+	for _, name := range t.Artists {
+		song.Artists = append(song.Artists, structs.Artist{Name: name})
+	}
+
+	if id, ok := netease.ParseSongId(t.Location); ok {
+		song.Id = id
+		return song, true
+	}
+	path, ok := localPath(t.Location, baseDir)
+	if !ok {
+		return song, false
+	}
+	song.Id = library.SongID(path)
+	song.LocalPath = path
+	if song.Name == "" {
+		song.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return song, true
+}
+
+// localPath 解析 file:// 链接、绝对路径或相对于歌单文件的路径
+func localPath(location, baseDir string) (string, bool) {
+	location = strings.TrimSpace(location)
+	if location == "" {
+		return "", false
+	}
+	if u, err := url.Parse(location); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+		if u.Scheme != "file" {
+			// 其他在线音源
+			return "", false
+		}
+		location = u.Path
+		if runtime.GOOS == "windows" {
+			location = strings.TrimPrefix(location, "/")
+		}
+	}
+	location = filepath.FromSlash(location)
+	if !filepath.IsAbs(location) {
+		location = filepath.Join(baseDir, location)
+	}
+	return filepath.Clean(location), true
+}
+
+// fileURL 本地路径对应的 file:// 链接
+func fileURL(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// Encode 按格式写出歌单
+func Encode(w io.Writer, format Format, playlist Playlist) error {
+	tracks := make([]track, len(playlist.Songs))
+	for i, song := range playlist.Songs {
+		tracks[i] = trackOfSong(song)
+	}
+	switch format {
+	case FormatM3U8:
+		return encodeM3U8(w, playlist.Name, tracks)
+	case FormatXSPF:
+		return encodeXSPF(w, playlist.Name, tracks)
+	case FormatJSON:
+		return encodeJSON(w, playlist.Name, tracks)
+	}
+	return errors.Errorf("不支持的歌单格式: %q", format)
+}
+
+// Decode 按格式读取歌单，baseDir 用于解析相对路径
+func Decode(r io.Reader, format Format, baseDir string) (Playlist, error) {
+	var (
+		name   string
+		tracks []track
+		err    error
+	)
+	switch format {
+	case FormatM3U8:
+		name, tracks, err = decodeM3U8(r)
+	case FormatXSPF:
+		name, tracks, err = decodeXSPF(r)
+	case FormatJSON:
+		name, tracks, err = decodeJSON(r)
+	default:
+		err = errors.Errorf("不支持的歌单格式: %q", format)
+	}
+	if err != nil {
+		return Playlist{}, err
+	}
+
+	playlist := Playlist{Name: name}
+	for _, t := range tracks {
+		if song, ok := t.song(baseDir); ok {
+			playlist.Songs = append(playlist.Songs, song)
+		} else {
+			playlist.Skipped++
+		}
+	}
+	return playlist, nil
+}
+
+// Export 写出歌单文件，格式由扩展名决定
+func Export(path string, playlist Playlist) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = Encode(f, format, playlist); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Import 读取歌单文件，格式由扩展名决定；文件中没有歌单名时使用文件名
+func Import(path string) (Playlist, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return Playlist{}, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Playlist{}, err
+	}
+	defer f.Close()
+
+	playlist, err := Decode(f, format, filepath.Dir(path))
+	if err != nil {
+		return Playlist{}, errors.WithMessagef(err, "解析 %s 失败", filepath.Base(path))
+	}
+	if playlist.Name == "" {
+		playlist.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return playlist, nil
+}
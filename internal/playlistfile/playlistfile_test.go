@@ -0,0 +1,116 @@
+package playlistfile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/library"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+func testPlaylist(dir string) Playlist {
+	local := filepath.Join(dir, "music", "晴天 (live).flac")
+	return Playlist{
+		Name: "通勤 & 跑步",
+		Songs: []structs.Song{
+			{
+				Id:       186016,
+				Name:     "晴天",
+				Duration: 269 * time.Second,
+				Artists:  []structs.Artist{{Id: 6452, Name: "周杰伦"}},
+				Album:    structs.Album{Name: "叶惠美"},
+			},
+			{
+				Id:        library.SongID(local),
+				Name:      "晴天 (live)",
+				Duration:  300 * time.Second,
+				LocalPath: local,
+				Artists:   []structs.Artist{{Name: "周杰伦"}, {Name: "五月天"}},
+			},
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	want := testPlaylist(dir)
+	for _, format := range Formats {
+		var buf bytes.Buffer
+		if err := Encode(&buf, format, want); err != nil {
+			t.Fatalf("%s: encode: %v", format, err)
+		}
+		got, err := Decode(&buf, format, dir)
+		if err != nil {
+			t.Fatalf("%s: decode: %v", format, err)
+		}
+		if got.Name != want.Name || got.Skipped != 0 || len(got.Songs) != len(want.Songs) {
+			t.Fatalf("%s: got %+v", format, got)
+		}
+		for i, song := range got.Songs {
+			w := want.Songs[i]
+			if song.Id != w.Id || song.LocalPath != w.LocalPath || song.Name != w.Name ||
+				song.ArtistName() != w.ArtistName() || song.Duration != w.Duration {
+				t.Errorf("%s: song %d = %+v, want %+v", format, i, song, w)
+			}
+		}
+	}
+}
+
+func TestDecodeM3U8(t *testing.T) {
+	dir := t.TempDir()
+	content := "\ufeff#EXTM3U\n" +
+		"#EXTINF:123,Artist A, Artist B - Title\n" +
+		"sub/a.mp3\n" +
+		"\n" +
+		"# comment\n" +
+		"http://example.com/stream.mp3\n" +
+		"#EXTINF:-1 tvg-id=\"x\",Untitled\n" +
+		"netease:186016\n" +
+		"https://music.163.com/song?id=1\n"
+	got, err := Decode(strings.NewReader(content), FormatM3U8, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Skipped != 1 || len(got.Songs) != 3 {
+		t.Fatalf("got %+v", got)
+	}
+	a := got.Songs[0]
+	if a.LocalPath != filepath.Join(dir, "sub", "a.mp3") || a.Name != "Title" || a.ArtistName() != "Artist A,Artist B" || a.Duration != 123*time.Second {
+		t.Errorf("local song = %+v", a)
+	}
+	if got.Songs[1].Id != 186016 || got.Songs[1].Name != "Untitled" || got.Songs[1].Duration != 0 {
+		t.Errorf("netease song = %+v", got.Songs[1])
+	}
+	if got.Songs[2].Id != 1 {
+		t.Errorf("url song = %+v", got.Songs[2])
+	}
+}
+
+func TestImportExport(t *testing.T) {
+	dir := t.TempDir()
+	playlist := testPlaylist(dir)
+	playlist.Name = ""
+
+	path := filepath.Join(dir, "out", "mix.xspf")
+	if err := Export(path, playlist); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Import(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "mix" || len(got.Songs) != 2 {
+		t.Errorf("got %+v", got)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "a.pls"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Import(filepath.Join(dir, "a.pls")); err == nil {
+		t.Error("unsupported extension should fail")
+	}
+}
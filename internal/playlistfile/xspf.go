@@ -0,0 +1,76 @@
+package playlistfile
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+)
+
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"http://xspf.org/ns/0/ playlist"`
+	Version string      `xml:"version,attr"`
+	Title   string      `xml:"title,omitempty"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location []string `xml:"location"`
+	Title    string   `xml:"title,omitempty"`
+	Creator  string   `xml:"creator,omitempty"`
+	Album    string   `xml:"album,omitempty"`
+	Duration int64    `xml:"duration,omitempty"` // 毫秒
+}
+
+// encodeXSPF XSPF 要求 location 为 URI，本地文件写为 file:// 链接
+func encodeXSPF(w io.Writer, name string, tracks []track) error {
+	playlist := xspfPlaylist{Version: "1", Title: name}
+	for _, t := range tracks {
+		location := t.Location
+		if !strings.Contains(location, "://") {
+			location = fileURL(location)
+		}
+		playlist.Tracks = append(playlist.Tracks, xspfTrack{
+			Location: []string{location},
+			Title:    t.Title,
+			Creator:  strings.Join(t.Artists, ","),
+			Album:    t.Album,
+			Duration: t.Duration.Milliseconds(),
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(playlist); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func decodeXSPF(r io.Reader) (string, []track, error) {
+	var playlist xspfPlaylist
+	if err := xml.NewDecoder(r).Decode(&playlist); err != nil {
+		return "", nil, err
+	}
+	tracks := make([]track, 0, len(playlist.Tracks))
+	for _, x := range playlist.Tracks {
+		t := track{
+			Title:    strings.TrimSpace(x.Title),
+			Album:    strings.TrimSpace(x.Album),
+			Duration: time.Duration(x.Duration) * time.Millisecond,
+		}
+		if len(x.Location) > 0 {
+			t.Location = strings.TrimSpace(x.Location[0])
+		}
+		for _, artist := range strings.Split(x.Creator, ",") {
+			if artist = strings.TrimSpace(artist); artist != "" {
+				t.Artists = append(t.Artists, artist)
+			}
+		}
+		tracks = append(tracks, t)
+	}
+	return strings.TrimSpace(playlist.Title), tracks, nil
+}
@@ -0,0 +1,278 @@
+// Package playlistimport 将其他平台导出的歌单（CSV、M3U、"歌手 - 歌名" 文本）逐条在网易云中搜索，
+// 按歌名、歌手、专辑、时长的相似度为候选打分：高于阈值自动采用，相近的候选交由用户确认，其余列入报告。
+package playlistimport
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Entry 待匹配的一行
+type Entry struct {
+	Line     int    // 在文件中的行号，从 1 开始
+	Raw      string // 原始内容，用于报告
+	Title    string
+	Artist   string
+	Album    string
+	Duration time.Duration
+}
+
+// String 用于展示的 "歌手 - 歌名"
+func (e Entry) String() string {
+	if e.Artist == "" {
+		return e.Title
+	}
+	return e.Artist + " - " + e.Title
+}
+
+// Format 输入文件格式
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatM3U  Format = "m3u"
+	FormatText Format = "text"
+)
+
+// FormatFromPath 根据扩展名判断格式，未知扩展名按每行 "歌手 - 歌名" 的文本处理
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".m3u", ".m3u8":
+		return FormatM3U
+	default:
+		return FormatText
+	}
+}
+
+// ParseFile 读取文件中的全部条目
+func ParseFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f, FormatFromPath(path))
+}
+
+// Parse 按格式读取条目，缺少歌名的行会被忽略
+func Parse(r io.Reader, format Format) ([]Entry, error) {
+	var (
+		entries []Entry
+		err     error
+	)
+	switch format {
+	case FormatCSV:
+		entries, err = parseCSV(r)
+	case FormatM3U:
+		entries, err = parseM3U(r)
+	case FormatText:
+		entries, err = parseText(r)
+	default:
+		err = errors.Errorf("unsupported format: %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no entries found")
+	}
+	return entries, nil
+}
+
+func parseText(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := newLineScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "//") {
+			continue
+		}
+		entry := Entry{Line: line, Raw: text}
+		entry.Artist, entry.Title = splitArtistTitle(text)
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// parseM3U 优先使用 #EXTINF 中的 "歌手 - 歌名"，没有时从文件名中解析
+func parseM3U(r io.Reader) ([]Entry, error) {
+	var (
+		entries  []Entry
+		extinf   string
+		duration time.Duration
+	)
+	scanner := newLineScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		switch {
+		case text == "":
+		case strings.HasPrefix(text, "#EXTINF:"):
+			info, display, _ := strings.Cut(strings.TrimPrefix(text, "#EXTINF:"), ",")
+			extinf = strings.TrimSpace(display)
+			duration = 0
+			if fields := strings.Fields(info); len(fields) > 0 {
+				if seconds, err := strconv.ParseFloat(fields[0], 64); err == nil && seconds > 0 {
+					duration = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		case strings.HasPrefix(text, "#"):
+		default:
+			display := extinf
+			if display == "" {
+				base := filepath.Base(filepath.FromSlash(text))
+				display = strings.TrimSuffix(base, filepath.Ext(base))
+			}
+			entry := Entry{Line: line, Raw: text, Duration: duration}
+			if extinf != "" {
+				entry.Raw = extinf
+			}
+			entry.Artist, entry.Title = splitArtistTitle(display)
+			entries = append(entries, entry)
+			extinf, duration = "", 0
+		}
+	}
+	return entries, scanner.Err()
+}
+
+var csvColumns = map[string][]string{
+	"title":    {"title", "name", "song", "song name", "track", "track name", "歌名", "歌曲", "歌曲名", "标题"},
+	"artist":   {"artist", "artists", "artist name", "artist name(s)", "artist(s)", "singer", "歌手", "艺人", "歌手名"},
+	"album":    {"album", "album name", "album title", "专辑", "专辑名"},
+	"duration": {"duration", "duration (ms)", "duration_ms", "length", "time", "时长"},
+}
+
+// parseCSV 有表头时按列名识别，没有表头时依次视为歌名、歌手、专辑、时长
+func parseCSV(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var (
+		entries      []Entry
+		columns      = map[string]int{"title": 0, "artist": 1, "album": 2, "duration": 3}
+		durationInMs bool
+	)
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if first {
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+			if header := csvHeader(record); header != nil {
+				columns = header
+				if i, ok := header["duration"]; ok {
+					durationInMs = strings.Contains(strings.ToLower(record[i]), "ms")
+				}
+				continue
+			}
+		}
+
+		field := func(name string) string {
+			if index, ok := columns[name]; ok && index < len(record) {
+				return strings.TrimSpace(record[index])
+			}
+			return ""
+		}
+		entry := Entry{
+			Line:     line,
+			Raw:      strings.Join(record, ","),
+			Title:    field("title"),
+			Artist:   field("artist"),
+			Album:    field("album"),
+			Duration: parseDuration(field("duration"), durationInMs),
+		}
+		if entry.Title == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func csvHeader(record []string) map[string]int {
+	header := make(map[string]int)
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for column, aliases := range csvColumns {
+			if _, ok := header[column]; ok {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					header[column] = i
+					break
+				}
+			}
+		}
+	}
+	if _, ok := header["title"]; !ok {
+		return nil
+	}
+	return header
+}
+
+// parseDuration 支持 "3:45"、"1:02:03"、秒数与毫秒数
+func parseDuration(s string, inMs bool) time.Duration {
+	if s == "" {
+		return 0
+	}
+	if strings.Contains(s, ":") {
+		var seconds int
+		for _, part := range strings.Split(s, ":") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return 0
+			}
+			seconds = seconds*60 + n
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	// 没有单位时，超过 10 万视为毫秒
+	if inMs || n >= 100000 {
+		return time.Duration(n * float64(time.Millisecond))
+	}
+	return time.Duration(n * float64(time.Second))
+}
+
+var artistTitleSeparator = regexp.MustCompile(`\s+[-–—]\s+`)
+
+// splitArtistTitle 拆分 "歌手 - 歌名"，没有分隔符时整行视为歌名
+func splitArtistTitle(s string) (artist, title string) {
+	loc := artistTitleSeparator.FindStringIndex(s)
+	if loc == nil {
+		return "", strings.TrimSpace(s)
+	}
+	return strings.TrimSpace(s[:loc[0]]), strings.TrimSpace(s[loc[1]:])
+}
+
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return scanner
+}
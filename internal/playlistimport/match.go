@@ -0,0 +1,317 @@
+package playlistimport
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+// Searcher 按关键词搜索歌曲，正式使用时为网易云搜索接口，测试中为录制的搜索结果
+type Searcher func(keyword string) ([]structs.Song, error)
+
+// Status 匹配结果
+type Status int
+
+const (
+	StatusMatched   Status = iota // 自动采用得分最高的候选
+	StatusReview                  // 候选得分相近或不够高，确认前不采用
+	StatusUnmatched               // 没有可用的候选
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusMatched:
+		return "matched"
+	case StatusReview:
+		return "review"
+	default:
+		return "unmatched"
+	}
+}
+
+// Candidate 搜索到的候选歌曲及其得分（0~1）
+type Candidate struct {
+	Song  structs.Song
+	Score float64
+}
+
+// Result 一行的匹配结果
+type Result struct {
+	Entry      Entry
+	Status     Status
+	Candidates []Candidate // 按得分从高到低
+	Chosen     int         // 采用的候选下标，-1 表示不采用
+	Err        error       // 搜索失败时的错误
+}
+
+// Song 最终采用的歌曲
+func (r Result) Song() (structs.Song, bool) {
+	if r.Chosen < 0 || r.Chosen >= len(r.Candidates) {
+		return structs.Song{}, false
+	}
+	return r.Candidates[r.Chosen].Song, true
+}
+
+// Choose 确认时采用第 index 个候选，index 为负数时跳过该行
+func (r *Result) Choose(index int) {
+	if index < 0 || index >= len(r.Candidates) {
+		r.Chosen = -1
+		return
+	}
+	r.Chosen = index
+}
+
+// Options 匹配参数，零值使用默认值
+type Options struct {
+	AcceptThreshold float64 // 自动采用的最低得分，默认 0.85
+	ReviewThreshold float64 // 低于此得分视为未匹配，默认 0.5
+	MaxCandidates   int     // 每行保留的候选数，默认 5
+}
+
+const (
+	DefaultAcceptThreshold = 0.85
+	defaultReviewThreshold = 0.5
+	defaultMaxCandidates   = 5
+
+	// acceptMargin 最高分与次高分（不同歌曲）至少相差这么多才自动采用
+	acceptMargin = 0.05
+)
+
+// Matcher 逐行搜索并为候选打分
+type Matcher struct {
+	search Searcher
+	opts   Options
+}
+
+func NewMatcher(search Searcher, opts Options) *Matcher {
+	if opts.AcceptThreshold <= 0 {
+		opts.AcceptThreshold = DefaultAcceptThreshold
+	}
+	if opts.ReviewThreshold <= 0 {
+		opts.ReviewThreshold = defaultReviewThreshold
+	}
+	if opts.ReviewThreshold > opts.AcceptThreshold {
+		opts.ReviewThreshold = opts.AcceptThreshold
+	}
+	if opts.MaxCandidates <= 0 {
+		opts.MaxCandidates = defaultMaxCandidates
+	}
+	return &Matcher{search: search, opts: opts}
+}
+
+// MatchAll 依次匹配全部条目，progress 可为 nil
+func (m *Matcher) MatchAll(entries []Entry, progress func(done, total int)) []Result {
+	results := make([]Result, 0, len(entries))
+	for i, entry := range entries {
+		results = append(results, m.Match(entry))
+		if progress != nil {
+			progress(i+1, len(entries))
+		}
+	}
+	return results
+}
+
+// Match 搜索 "歌名 歌手"，没有结果时只搜索歌名
+func (m *Matcher) Match(entry Entry) Result {
+	result := Result{Entry: entry, Status: StatusUnmatched, Chosen: -1}
+
+	songs, err := m.search(strings.TrimSpace(entry.Title + " " + strings.Join(splitArtists(entry.Artist), " ")))
+	if err == nil && len(songs) == 0 && entry.Artist != "" {
+		songs, err = m.search(entry.Title)
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	seen := make(map[int64]struct{}, len(songs))
+	for _, song := range songs {
+		if _, ok := seen[song.Id]; ok {
+			continue
+		}
+		seen[song.Id] = struct{}{}
+		result.Candidates = append(result.Candidates, Candidate{Song: song, Score: Score(entry, song)})
+	}
+	// 分数相同时保持搜索结果的顺序
+	slices.SortStableFunc(result.Candidates, func(a, b Candidate) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	if len(result.Candidates) > m.opts.MaxCandidates {
+		result.Candidates = result.Candidates[:m.opts.MaxCandidates]
+	}
+	if len(result.Candidates) == 0 {
+		return result
+	}
+
+	best := result.Candidates[0]
+	switch {
+	case best.Score >= m.opts.AcceptThreshold && m.unambiguous(result.Candidates):
+		result.Status = StatusMatched
+		result.Chosen = 0
+	case best.Score >= m.opts.ReviewThreshold:
+		result.Status = StatusReview
+	}
+	return result
+}
+
+// unambiguous 次高分与最高分相差足够大，或两者只是同一首歌的不同版本
+func (m *Matcher) unambiguous(candidates []Candidate) bool {
+	best := candidates[0]
+	for _, other := range candidates[1:] {
+		if best.Score-other.Score >= acceptMargin {
+			return true
+		}
+		if !sameTrack(best.Song, other.Song) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameTrack(a, b structs.Song) bool {
+	return normalize(a.Name) == normalize(b.Name) && normalize(a.ArtistName()) == normalize(b.ArtistName())
+}
+
+// 各项的权重，条目中缺少的项不参与计算
+const (
+	titleWeight    = 0.55
+	artistWeight   = 0.30
+	albumWeight    = 0.05
+	durationWeight = 0.10
+)
+
+// Score 条目与歌曲的相似度，0~1
+func Score(entry Entry, song structs.Song) float64 {
+	score := titleWeight * textSimilarity(entry.Title, song.Name)
+	total := titleWeight
+	if entry.Artist != "" {
+		score += artistWeight * artistSimilarity(entry.Artist, song)
+		total += artistWeight
+	}
+	if entry.Album != "" {
+		score += albumWeight * textSimilarity(entry.Album, song.Album.Name)
+		total += albumWeight
+	}
+	if entry.Duration > 0 && song.Duration > 0 {
+		score += durationWeight * durationSimilarity(entry.Duration, song.Duration)
+		total += durationWeight
+	}
+	return score / total
+}
+
+// durationSimilarity 相差 3 秒以内视为相同，30 秒以上视为完全不同
+func durationSimilarity(a, b time.Duration) float64 {
+	const (
+		same = 3 * time.Second
+		diff = 30 * time.Second
+	)
+	d := a - b
+	if d < 0 {
+		d = -d
+	}
+	switch {
+	case d <= same:
+		return 1
+	case d >= diff:
+		return 0
+	}
+	return float64(diff-d) / float64(diff-same)
+}
+
+var artistSeparator = regexp.MustCompile(`(?i)\s*(?:,|，|、|&|/|;|；|\s+feat\.?\s+|\s+ft\.?\s+|\s+x\s+)\s*`)
+
+func splitArtists(s string) []string {
+	var names []string
+	for _, name := range artistSeparator.Split(s, -1) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// artistSimilarity 条目中每位歌手与歌曲歌手的最佳相似度的平均值
+func artistSimilarity(artist string, song structs.Song) float64 {
+	names := splitArtists(artist)
+	if len(names) == 0 || len(song.Artists) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, name := range names {
+		var best float64
+		for _, a := range song.Artists {
+			best = max(best, textSimilarity(name, a.Name))
+		}
+		sum += best
+	}
+	return max(sum/float64(len(names)), textSimilarity(artist, song.ArtistName()))
+}
+
+var decorations = regexp.MustCompile(`\s*(?:\([^)]*\)|\[[^\]]*]|（[^）]*）|【[^】]*】|\s-\s.*$)`)
+
+// textSimilarity 忽略大小写、全半角、空白与标点；去掉括号中的版本说明后相同时略低于完全相同
+func textSimilarity(a, b string) float64 {
+	full := ratio(normalize(a), normalize(b))
+	if full == 1 {
+		return 1
+	}
+	core := ratio(normalize(decorations.ReplaceAllString(a, "")), normalize(decorations.ReplaceAllString(b, "")))
+	return max(full, core*0.95)
+}
+
+// normalize 全角转半角并转为小写，只保留字母和数字
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\u3000':
+			continue
+		case r >= '\uff01' && r <= '\uff5e':
+			r -= 0xfee0
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// ratio 基于编辑距离的相似度
+func ratio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
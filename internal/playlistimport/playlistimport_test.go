@@ -0,0 +1,259 @@
+package playlistimport
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	_struct "github.com/go-musicfox/go-musicfox/utils/struct"
+)
+
+// fixtureSearcher 使用 testdata/search.json 中录制的 cloudsearch 响应，未录制的关键词视为测试错误
+func fixtureSearcher(t *testing.T) Searcher {
+	t.Helper()
+	data, err := os.ReadFile("testdata/search.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var responses map[string]json.RawMessage
+	if err = json.Unmarshal(data, &responses); err != nil {
+		t.Fatal(err)
+	}
+	return func(keyword string) ([]structs.Song, error) {
+		response, ok := responses[keyword]
+		if !ok {
+			t.Errorf("no recorded search response for %q", keyword)
+			return nil, nil
+		}
+		return _struct.GetSongsOfSearchResult(response), nil
+	}
+}
+
+const testCSV = "\ufeffTrack Name,Artist Name(s),Album Name,Duration (ms)\n" +
+	"晴天,周杰伦,叶惠美,269560\n" +
+	"Love Story,Taylor Swift,Fearless,235280\n" +
+	"Nothing Song,Nobody,,\n" +
+	"稻香,Jay Chou,,\n" +
+	"Hello,Adele,25,295502\n"
+
+func TestParseCSV(t *testing.T) {
+	entries, err := Parse(strings.NewReader(testCSV), FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("got %d entries, want 5", len(entries))
+	}
+	want := Entry{Line: 2, Raw: "晴天,周杰伦,叶惠美,269560", Title: "晴天", Artist: "周杰伦", Album: "叶惠美", Duration: 269560 * time.Millisecond}
+	if entries[0] != want {
+		t.Errorf("entries[0] = %+v, want %+v", entries[0], want)
+	}
+	if entries[2].Line != 4 || entries[2].Album != "" || entries[2].Duration != 0 {
+		t.Errorf("entries[2] = %+v", entries[2])
+	}
+}
+
+func TestParseCSVWithoutHeader(t *testing.T) {
+	entries, err := Parse(strings.NewReader("晴天,周杰伦,叶惠美,4:29\n,missing title\n稻香,周杰伦\n"), FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Title != "晴天" || entries[0].Artist != "周杰伦" || entries[0].Duration != 269*time.Second {
+		t.Errorf("entries[0] = %+v", entries[0])
+	}
+	if entries[1].Line != 3 || entries[1].Title != "稻香" {
+		t.Errorf("entries[1] = %+v", entries[1])
+	}
+}
+
+func TestParseText(t *testing.T) {
+	input := "周杰伦 - 晴天\n# comment\n\nAdele \u2013 Hello\nJust A Title\n"
+	entries, err := Parse(strings.NewReader(input), FormatText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{
+		{Line: 1, Raw: "周杰伦 - 晴天", Artist: "周杰伦", Title: "晴天"},
+		{Line: 4, Raw: "Adele \u2013 Hello", Artist: "Adele", Title: "Hello"},
+		{Line: 5, Raw: "Just A Title", Title: "Just A Title"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entries[%d] = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+}
+
+func TestParseM3U(t *testing.T) {
+	input := "#EXTM3U\n#EXTINF:269,周杰伦 - 晴天\n/music/a.mp3\n/music/Adele - Hello.flac\n"
+	entries, err := Parse(strings.NewReader(input), FormatM3U)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if e := entries[0]; e.Line != 3 || e.Artist != "周杰伦" || e.Title != "晴天" || e.Duration != 269*time.Second {
+		t.Errorf("entries[0] = %+v", e)
+	}
+	if e := entries[1]; e.Artist != "Adele" || e.Title != "Hello" || e.Duration != 0 {
+		t.Errorf("entries[1] = %+v", e)
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	if _, err := Parse(strings.NewReader("# nothing\n"), FormatText); err == nil {
+		t.Error("expected error for empty input")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		inMs bool
+		want time.Duration
+	}{
+		{"3:45", false, 225 * time.Second},
+		{"1:02:03", false, time.Hour + 2*time.Minute + 3*time.Second},
+		{"225", false, 225 * time.Second},
+		{"225000", false, 225 * time.Second},
+		{"225", true, 225 * time.Millisecond},
+		{"abc", false, 0},
+	}
+	for _, tt := range tests {
+		if got := parseDuration(tt.in, tt.inMs); got != tt.want {
+			t.Errorf("parseDuration(%q, %v) = %v, want %v", tt.in, tt.inMs, got, tt.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	song := structs.Song{
+		Name:     "晴天",
+		Duration: 269560 * time.Millisecond,
+		Album:    structs.Album{Name: "叶惠美"},
+		Artists:  []structs.Artist{{Name: "周杰伦"}},
+	}
+	if got := Score(Entry{Title: "晴天", Artist: "周杰伦", Album: "叶惠美", Duration: 269 * time.Second}, song); got != 1 {
+		t.Errorf("exact match score = %v, want 1", got)
+	}
+	// 全角字符与大小写不影响得分
+	latin := structs.Song{Name: "Hello", Artists: []structs.Artist{{Name: "Adele"}}}
+	if got := Score(Entry{Title: "\uff28\uff45\uff4c\uff4c\uff4f", Artist: "ADELE"}, latin); got != 1 {
+		t.Errorf("full-width score = %v, want 1", got)
+	}
+	wrongArtist := Score(Entry{Title: "晴天", Artist: "五月天"}, song)
+	if wrongArtist >= DefaultAcceptThreshold {
+		t.Errorf("wrong artist score = %v, want below %v", wrongArtist, DefaultAcceptThreshold)
+	}
+	multiple := structs.Song{Name: "Señorita", Artists: []structs.Artist{{Name: "Shawn Mendes"}, {Name: "Camila Cabello"}}}
+	if got := Score(Entry{Title: "Señorita", Artist: "Shawn Mendes feat. Camila Cabello"}, multiple); got != 1 {
+		t.Errorf("multiple artists score = %v, want 1", got)
+	}
+}
+
+func TestMatchAll(t *testing.T) {
+	entries, err := Parse(strings.NewReader(testCSV), FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls int
+	results := NewMatcher(fixtureSearcher(t), Options{}).MatchAll(entries, func(done, total int) {
+		calls++
+		if done != calls || total != len(entries) {
+			t.Errorf("progress(%d, %d), want (%d, %d)", done, total, calls, len(entries))
+		}
+	})
+
+	tests := []struct {
+		status Status
+		songId int64 // 采用的歌曲，0 表示不采用
+		bestId int64 // 得分最高的候选，0 表示没有候选
+	}{
+		{StatusMatched, 186016, 186016},
+		{StatusReview, 0, 19292984}, // 与 Taylor's Version 得分相近
+		{StatusUnmatched, 0, 0},     // 搜索无结果
+		{StatusReview, 0, 185709},   // 仅按歌名搜索到，歌手不同
+		{StatusMatched, 34408349, 34408349},
+	}
+	for i, tt := range tests {
+		r := results[i]
+		if r.Status != tt.status {
+			t.Errorf("%s: status = %v, want %v", r.Entry, r.Status, tt.status)
+		}
+		song, ok := r.Song()
+		if ok != (tt.songId != 0) || song.Id != tt.songId {
+			t.Errorf("%s: song = %d (%v), want %d", r.Entry, song.Id, ok, tt.songId)
+		}
+		var bestId int64
+		if len(r.Candidates) > 0 {
+			bestId = r.Candidates[0].Song.Id
+		}
+		if bestId != tt.bestId {
+			t.Errorf("%s: best candidate = %d, want %d", r.Entry, bestId, tt.bestId)
+		}
+	}
+}
+
+func TestMatchSearchError(t *testing.T) {
+	searchErr := errors.New("network error")
+	matcher := NewMatcher(func(string) ([]structs.Song, error) { return nil, searchErr }, Options{})
+	r := matcher.Match(Entry{Title: "晴天", Artist: "周杰伦"})
+	if r.Status != StatusUnmatched || !errors.Is(r.Err, searchErr) {
+		t.Errorf("result = %+v", r)
+	}
+}
+
+func TestReport(t *testing.T) {
+	entries, err := Parse(strings.NewReader(testCSV), FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := NewMatcher(fixtureSearcher(t), Options{}).MatchAll(entries, nil)
+	results[1].Choose(0)
+	results[3].Choose(-1)
+
+	var ids []int64
+	for _, song := range Songs(results) {
+		ids = append(ids, song.Id)
+	}
+	if want := []int64{186016, 19292984, 34408349}; !slices.Equal(ids, want) {
+		t.Errorf("songs = %v, want %v", ids, want)
+	}
+
+	summary := Summarize(results)
+	if want := (Summary{Total: 5, Matched: 2, Reviewed: 1, Pending: 1, Unmatched: 1}); summary != want {
+		t.Errorf("summary = %+v, want %+v", summary, want)
+	}
+
+	var buf bytes.Buffer
+	if err = WriteReport(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	report := buf.String()
+	for _, want := range []string{
+		"Imported 3/5 (auto 2, reviewed 1), not imported 2",
+		"line 4     Nothing Song,Nobody,,  [unmatched]",
+		"line 5     稻香,Jay Chou,,  [skipped]  best: 周杰伦 - 稻香",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "晴天") {
+		t.Errorf("report should not list imported lines:\n%s", report)
+	}
+}
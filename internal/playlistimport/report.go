@@ -0,0 +1,94 @@
+package playlistimport
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+// Summary 匹配结果统计
+type Summary struct {
+	Total     int
+	Matched   int // 自动采用
+	Reviewed  int // 确认后采用
+	Pending   int // 待确认或确认时跳过
+	Unmatched int // 没有候选或搜索失败
+}
+
+// Imported 将被加入歌单的行数
+func (s Summary) Imported() int {
+	return s.Matched + s.Reviewed
+}
+
+func Summarize(results []Result) Summary {
+	summary := Summary{Total: len(results)}
+	for _, r := range results {
+		_, ok := r.Song()
+		switch {
+		case r.Status == StatusMatched && ok:
+			summary.Matched++
+		case ok:
+			summary.Reviewed++
+		case r.Status == StatusReview:
+			summary.Pending++
+		default:
+			summary.Unmatched++
+		}
+	}
+	return summary
+}
+
+// Songs 采用的歌曲，保持文件中的顺序并去除重复
+func Songs(results []Result) []structs.Song {
+	var (
+		songs []structs.Song
+		seen  = make(map[int64]struct{})
+	)
+	for _, r := range results {
+		song, ok := r.Song()
+		if !ok {
+			continue
+		}
+		if _, dup := seen[song.Id]; dup {
+			continue
+		}
+		seen[song.Id] = struct{}{}
+		songs = append(songs, song)
+	}
+	return songs
+}
+
+// WriteReport 写出统计以及未导入的行
+func WriteReport(w io.Writer, results []Result) error {
+	bw := bufio.NewWriter(w)
+	s := Summarize(results)
+	_, _ = fmt.Fprintf(bw, "Imported %d/%d (auto %d, reviewed %d), not imported %d\n",
+		s.Imported(), s.Total, s.Matched, s.Reviewed, s.Pending+s.Unmatched)
+
+	header := false
+	for _, r := range results {
+		if _, ok := r.Song(); ok {
+			continue
+		}
+		if !header {
+			_, _ = fmt.Fprintln(bw, "\nNot imported:")
+			header = true
+		}
+		reason := "unmatched"
+		switch {
+		case r.Err != nil:
+			reason = "error: " + r.Err.Error()
+		case r.Status == StatusReview:
+			reason = "skipped"
+		}
+		_, _ = fmt.Fprintf(bw, "  line %-5d %s  [%s]", r.Entry.Line, r.Entry.Raw, reason)
+		if len(r.Candidates) > 0 {
+			best := r.Candidates[0]
+			_, _ = fmt.Fprintf(bw, "  best: %s - %s (%.2f)", best.Song.ArtistName(), best.Song.Name, best.Score)
+		}
+		_, _ = fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
@@ -3,7 +3,6 @@
 package remote_control
 
 import (
-	"fmt"
 	"log"
 	"math"
 	"reflect"
@@ -236,12 +235,12 @@ func MapFromPlayingInfo(info PlayingInfo) MetadataMap {
 	if info.TrackID == 0 {
 		// No song
 		return MetadataMap{
-			"mpris:trackid": dbus.ObjectPath("/org/mpris/MediaPlayer2/TrackList/NoTrack"),
+			"mpris:trackid": noTrack,
 		}
 	}
 
 	m := &MetadataMap{
-		"mpris:trackid": dbus.ObjectPath(fmt.Sprintf("/org/mpd/Tracks/%d", info.TrackID)),
+		"mpris:trackid": songTrackPath(info.TrackID),
 		"mpris:length":  info.TotalDuration / time.Microsecond,
 		"mpris:artUrl":  app.AddResizeParamForPicUrl(info.PicUrl, 1024),
 	}
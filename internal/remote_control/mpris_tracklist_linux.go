@@ -0,0 +1,400 @@
+//go:build linux
+
+package remote_control
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/utils/netease"
+)
+
+const (
+	trackListIface = "org.mpris.MediaPlayer2.TrackList"
+	playlistsIface = "org.mpris.MediaPlayer2.Playlists"
+
+	noTrack          = dbus.ObjectPath("/org/mpris/MediaPlayer2/TrackList/NoTrack")
+	trackPathBase    = "/org/musicfox/Tracks/"
+	playlistPathBase = "/org/musicfox/Playlists/"
+)
+
+// songTrackPath 不在播放队列中的歌曲在 MPRIS 中的 id
+//
+// 本地歌曲的 id 为负数，对象路径中不能出现 '-'，因此以 local 前缀区分。
+func songTrackPath(id int64) dbus.ObjectPath {
+	if id < 0 {
+		return dbus.ObjectPath(fmt.Sprintf("/org/mpd/Tracks/local%d", -id))
+	}
+	return dbus.ObjectPath(fmt.Sprintf("/org/mpd/Tracks/%d", id))
+}
+
+// trackEntry 播放队列中的一项，同一首歌在队列中出现多次时各项的 id 不同
+type trackEntry struct {
+	songID int64
+	path   dbus.ObjectPath
+}
+
+func playlistPath(id int64) dbus.ObjectPath {
+	return dbus.ObjectPath(playlistPathBase + strconv.FormatInt(id, 10))
+}
+
+func playlistIdFromPath(path dbus.ObjectPath) (int64, bool) {
+	rest, ok := strings.CutPrefix(string(path), playlistPathBase)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(rest, 10, 64)
+	return id, err == nil
+}
+
+// MapFromTrack 队列中 id 为 path 的一项的元数据
+func MapFromTrack(track Track, path dbus.ObjectPath) MetadataMap {
+	m := MapFromPlayingInfo(PlayingInfo{
+		TotalDuration: track.Duration,
+		TrackID:       track.ID,
+		PicUrl:        track.PicUrl,
+		Name:          track.Name,
+		Artist:        track.Artist,
+		Album:         track.Album,
+		AlbumArtist:   track.AlbumArtist,
+	})
+	m["mpris:trackid"] = path
+	return m
+}
+
+// TrackList is a DBus object satisfying the `org.mpris.MediaPlayer2.TrackList` interface.
+// https://specifications.freedesktop.org/mpris-spec/latest/Track_List_Interface.html
+type TrackList struct {
+	*RemoteControl
+
+	ctrl TrackListController
+}
+
+func (t *TrackList) properties() map[string]*prop.Prop {
+	return map[string]*prop.Prop{
+		"Tracks":        {Value: []dbus.ObjectPath{}, Emit: prop.EmitInvalidates},
+		"CanEditTracks": {Value: true, Emit: prop.EmitConst},
+	}
+}
+
+// indexOf 返回 id 对应的项在当前队列中的位置
+func (t *TrackList) indexOf(id dbus.ObjectPath) int {
+	tracks, _ := t.ctrl.CtrlTrackList()
+	return slices.Index(t.syncTrackEntries(tracks), id)
+}
+
+// GetTracksMetadata gets all the metadata available for a set of tracks.
+// https://specifications.freedesktop.org/mpris-spec/latest/Track_List_Interface.html#Method:GetTracksMetadata
+func (t *TrackList) GetTracksMetadata(ids []dbus.ObjectPath) ([]MetadataMap, *dbus.Error) {
+	tracks, _ := t.ctrl.CtrlTrackList()
+	paths := t.syncTrackEntries(tracks)
+	metadata := make([]MetadataMap, 0, len(ids))
+	for _, id := range ids {
+		if i := slices.Index(paths, id); i >= 0 {
+			metadata = append(metadata, MapFromTrack(tracks[i], id))
+		}
+	}
+	return metadata, nil
+}
+
+// AddTrack adds a song (id, netease:id or song url) after the given track.
+// https://specifications.freedesktop.org/mpris-spec/latest/Track_List_Interface.html#Method:AddTrack
+func (t *TrackList) AddTrack(uri string, afterTrack dbus.ObjectPath, setAsCurrent bool) *dbus.Error {
+	songId, ok := netease.ParseSongId(uri)
+	if !ok {
+		return dbus.MakeFailedError(errors.Errorf("unsupported uri: %s", uri))
+	}
+
+	index := 0
+	if afterTrack != noTrack {
+		i := t.indexOf(afterTrack)
+		if i < 0 {
+			return dbus.MakeFailedError(errors.Errorf("unknown track: %s", afterTrack))
+		}
+		index = i + 1
+	}
+	if err := t.ctrl.CtrlAddTrack(songId, index, setAsCurrent); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	t.refreshTrackList()
+	return nil
+}
+
+// RemoveTrack removes an item from the tracklist.
+// https://specifications.freedesktop.org/mpris-spec/latest/Track_List_Interface.html#Method:RemoveTrack
+func (t *TrackList) RemoveTrack(id dbus.ObjectPath) *dbus.Error {
+	i := t.indexOf(id)
+	if i < 0 {
+		return dbus.MakeFailedError(errors.Errorf("unknown track: %s", id))
+	}
+	if err := t.ctrl.CtrlRemoveTrack(i); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	t.refreshTrackList()
+	return nil
+}
+
+// GoTo skips to the specified track in the tracklist.
+// https://specifications.freedesktop.org/mpris-spec/latest/Track_List_Interface.html#Method:GoTo
+func (t *TrackList) GoTo(id dbus.ObjectPath) *dbus.Error {
+	i := t.indexOf(id)
+	if i < 0 {
+		return dbus.MakeFailedError(errors.Errorf("unknown track: %s", id))
+	}
+	if err := t.ctrl.CtrlGoTo(i); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	return nil
+}
+
+// syncTrackEntries 为 tracks 中的每一项分配 id 并返回
+//
+// 按顺序沿用上一次队列中同一首歌的项，因此插入、删除其他歌曲后已有项的 id 不变；
+// 新的项使用递增的序号作为 id，不会与之前用过的 id 重复。
+func (s *RemoteControl) syncTrackEntries(tracks []Track) []dbus.ObjectPath {
+	s.listMu.Lock()
+	defer s.listMu.Unlock()
+
+	entries := make([]trackEntry, len(tracks))
+	paths := make([]dbus.ObjectPath, len(tracks))
+	old := s.trackEntries
+	for i, track := range tracks {
+		if j := slices.IndexFunc(old, func(e trackEntry) bool { return e.songID == track.ID }); j >= 0 {
+			entries[i] = old[j]
+			old = old[j+1:]
+		} else {
+			s.nextTrackID++
+			entries[i] = trackEntry{songID: track.ID, path: dbus.ObjectPath(trackPathBase + strconv.FormatUint(s.nextTrackID, 10))}
+		}
+		paths[i] = entries[i].path
+	}
+	s.trackEntries = entries
+	return paths
+}
+
+// currentTrackPath 正在播放的歌曲在 MPRIS 中的 id，歌曲来自播放队列时为队列中当前项的 id
+func (s *RemoteControl) currentTrackPath(songID int64) dbus.ObjectPath {
+	if s.trackList != nil {
+		tracks, current := s.trackList.CtrlTrackList()
+		paths := s.syncTrackEntries(tracks)
+		if current >= 0 && current < len(tracks) && tracks[current].ID == songID {
+			return paths[current]
+		}
+	}
+	return songTrackPath(songID)
+}
+
+// refreshTrackList 队列变化时更新 Tracks 属性并发送 TrackListReplaced 信号
+func (s *RemoteControl) refreshTrackList() {
+	if s.props == nil || s.trackList == nil {
+		return
+	}
+	tracks, current := s.trackList.CtrlTrackList()
+	ids := s.syncTrackEntries(tracks)
+
+	s.listMu.Lock()
+	changed := s.tracks == nil || !slices.Equal(s.tracks, ids)
+	s.tracks = ids
+	s.listMu.Unlock()
+	if !changed {
+		return
+	}
+
+	s.props.SetMust(trackListIface, "Tracks", ids)
+	currentTrack := noTrack
+	if current >= 0 && current < len(ids) {
+		currentTrack = ids[current]
+	}
+	_ = s.dbus.Emit("/org/mpris/MediaPlayer2", trackListIface+".TrackListReplaced", ids, currentTrack)
+	s.refreshActivePlaylist()
+}
+
+// mprisPlaylist MPRIS 中的歌单，(oss)
+type mprisPlaylist struct {
+	Id   dbus.ObjectPath
+	Name string
+	Icon string
+}
+
+// maybePlaylist ActivePlaylist 属性的值，(b(oss))
+type maybePlaylist struct {
+	Valid    bool
+	Playlist mprisPlaylist
+}
+
+// Playlists is a DBus object satisfying the `org.mpris.MediaPlayer2.Playlists` interface.
+// https://specifications.freedesktop.org/mpris-spec/latest/Playlists_Interface.html
+type Playlists struct {
+	*RemoteControl
+
+	ctrl PlaylistsController
+}
+
+func (p *Playlists) properties() map[string]*prop.Prop {
+	return map[string]*prop.Prop{
+		"PlaylistCount":  {Value: uint32(0), Emit: prop.EmitTrue},
+		"Orderings":      {Value: []string{"UserDefined", "Alphabetical"}, Emit: prop.EmitConst},
+		"ActivePlaylist": {Value: maybePlaylist{Playlist: mprisPlaylist{Id: "/"}}, Emit: prop.EmitTrue},
+	}
+}
+
+// ActivatePlaylist starts playing the given playlist.
+// https://specifications.freedesktop.org/mpris-spec/latest/Playlists_Interface.html#Method:ActivatePlaylist
+func (p *Playlists) ActivatePlaylist(id dbus.ObjectPath) *dbus.Error {
+	playlistId, ok := playlistIdFromPath(id)
+	if !ok {
+		return dbus.MakeFailedError(errors.Errorf("unknown playlist: %s", id))
+	}
+	if err := p.ctrl.CtrlActivatePlaylist(playlistId); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	p.refreshTrackList()
+	p.refreshActivePlaylist()
+	return nil
+}
+
+// GetPlaylists gets a set of playlists.
+// https://specifications.freedesktop.org/mpris-spec/latest/Playlists_Interface.html#Method:GetPlaylists
+func (p *Playlists) GetPlaylists(index, maxCount uint32, order string, reverseOrder bool) ([]mprisPlaylist, *dbus.Error) {
+	playlists, err := p.loadPlaylists()
+	if err != nil {
+		return nil, dbus.MakeFailedError(err)
+	}
+	if order == "Alphabetical" {
+		slices.SortStableFunc(playlists, func(a, b Playlist) int { return strings.Compare(a.Name, b.Name) })
+	}
+	if reverseOrder {
+		slices.Reverse(playlists)
+	}
+
+	result := []mprisPlaylist{}
+	for i := int(index); i < len(playlists) && uint32(len(result)) < maxCount; i++ {
+		result = append(result, mprisPlaylist{Id: playlistPath(playlists[i].ID), Name: playlists[i].Name})
+	}
+	return result, nil
+}
+
+// loadPlaylists 获取用户歌单并更新 PlaylistCount
+func (s *RemoteControl) loadPlaylists() ([]Playlist, error) {
+	playlists, err := s.playlists.CtrlPlaylists()
+	if err != nil {
+		return nil, err
+	}
+	s.listMu.Lock()
+	s.playlistCache = slices.Clone(playlists)
+	s.listMu.Unlock()
+
+	s.props.SetMust(playlistsIface, "PlaylistCount", uint32(len(playlists)))
+	s.refreshActivePlaylist()
+	return playlists, nil
+}
+
+// loadPlaylistsOnce 在第一次有歌曲播放（此时通常已登录）时后台加载歌单数量，失败时下次重试
+func (s *RemoteControl) loadPlaylistsOnce() {
+	if s.props == nil || s.playlists == nil || !s.playlistsLoading.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		if _, err := s.loadPlaylists(); err != nil {
+			s.playlistsLoading.Store(false)
+		}
+	}()
+}
+
+// refreshActivePlaylist 播放队列来自的歌单变化时更新 ActivePlaylist
+func (s *RemoteControl) refreshActivePlaylist() {
+	if s.props == nil || s.playlists == nil {
+		return
+	}
+	active := maybePlaylist{Playlist: mprisPlaylist{Id: "/"}}
+	if id := s.playlists.CtrlActivePlaylist(); id != 0 {
+		active = maybePlaylist{Valid: true, Playlist: mprisPlaylist{Id: playlistPath(id)}}
+		s.listMu.Lock()
+		if i := slices.IndexFunc(s.playlistCache, func(p Playlist) bool { return p.ID == id }); i >= 0 {
+			active.Playlist.Name = s.playlistCache[i].Name
+		}
+		s.listMu.Unlock()
+	}
+
+	s.listMu.Lock()
+	changed := active != s.activePlaylist
+	s.activePlaylist = active
+	s.listMu.Unlock()
+	if changed {
+		s.props.SetMust(playlistsIface, "ActivePlaylist", active)
+	}
+}
+
+var trackListIntrospection = introspect.Interface{
+	Name: trackListIface,
+	Properties: []introspect.Property{
+		{Name: "Tracks", Type: "ao", Access: "read"},
+		{Name: "CanEditTracks", Type: "b", Access: "read"},
+	},
+	Methods: []introspect.Method{
+		{
+			Name: "GetTracksMetadata",
+			Args: []introspect.Arg{
+				{Name: "TrackIds", Type: "ao", Direction: "in"},
+				{Name: "Metadata", Type: "aa{sv}", Direction: "out"},
+			},
+		},
+		{
+			Name: "AddTrack",
+			Args: []introspect.Arg{
+				{Name: "Uri", Type: "s", Direction: "in"},
+				{Name: "AfterTrack", Type: "o", Direction: "in"},
+				{Name: "SetAsCurrent", Type: "b", Direction: "in"},
+			},
+		},
+		{
+			Name: "RemoveTrack",
+			Args: []introspect.Arg{{Name: "TrackId", Type: "o", Direction: "in"}},
+		},
+		{
+			Name: "GoTo",
+			Args: []introspect.Arg{{Name: "TrackId", Type: "o", Direction: "in"}},
+		},
+	},
+	Signals: []introspect.Signal{
+		{
+			Name: "TrackListReplaced",
+			Args: []introspect.Arg{
+				{Name: "Tracks", Type: "ao"},
+				{Name: "CurrentTrack", Type: "o"},
+			},
+		},
+	},
+}
+
+var playlistsIntrospection = introspect.Interface{
+	Name: playlistsIface,
+	Properties: []introspect.Property{
+		{Name: "PlaylistCount", Type: "u", Access: "read"},
+		{Name: "Orderings", Type: "as", Access: "read"},
+		{Name: "ActivePlaylist", Type: "(b(oss))", Access: "read"},
+	},
+	Methods: []introspect.Method{
+		{
+			Name: "ActivatePlaylist",
+			Args: []introspect.Arg{{Name: "PlaylistId", Type: "o", Direction: "in"}},
+		},
+		{
+			Name: "GetPlaylists",
+			Args: []introspect.Arg{
+				{Name: "Index", Type: "u", Direction: "in"},
+				{Name: "MaxCount", Type: "u", Direction: "in"},
+				{Name: "Order", Type: "s", Direction: "in"},
+				{Name: "ReverseOrder", Type: "b", Direction: "in"},
+				{Name: "Playlists", Type: "a(oss)", Direction: "out"},
+			},
+		},
+	},
+}
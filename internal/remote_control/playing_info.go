@@ -20,4 +20,6 @@ type PlayingInfo struct {
 	LRCText        string
 	LoopStatus     string // "None", "Track", "Playlist"
 	Shuffle        bool
+	Queued         bool // 当前歌曲来自待播队列
+	UpNext         int  // 待播队列中等待播放的歌曲数
 }
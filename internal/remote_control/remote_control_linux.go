@@ -8,6 +8,7 @@ import (
 	"math"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-musicfox/go-musicfox/internal/types"
@@ -23,12 +24,12 @@ type MediaPlayer2 struct {
 
 func (m *MediaPlayer2) properties() map[string]*prop.Prop {
 	return map[string]*prop.Prop{
-		"CanQuit":             newProp(true, nil),          // https://specifications.freedesktop.org/mpris-spec/latest/Media_Player.html#Property:CanQuit
-		"CanRaise":            newProp(false, nil),         // https://specifications.freedesktop.org/mpris-spec/latest/Media_Player.html#Property:CanRaise
-		"HasTrackList":        newProp(true, nil),          // https://specifications.freedesktop.org/mpris-spec/latest/Media_Player.html#Property:HasTrackList
-		"Identity":            newProp(types.AppName, nil), // https://specifications.freedesktop.org/mpris-spec/latest/Media_Player.html#Property:Identity
-		"SupportedUriSchemes": newProp([]string{}, nil),    // https://specifications.freedesktop.org/mpris-spec/latest/Media_Player.html#Property:SupportedUriSchemes
-		"SupportedMimeTypes":  newProp([]string{}, nil),    // https://specifications.freedesktop.org/mpris-spec/latest/Media_Player.html#Property:SupportedMimeTypes
+		"CanQuit":             newProp(true, nil),                         // https://specifications.freedesktop.org/mpris-spec/latest/Media_Player.html#Property:CanQuit
+		"CanRaise":            newProp(false, nil),                        // https://specifications.freedesktop.org/mpris-spec/latest/Media_Player.html#Property:CanRaise
+		"HasTrackList":        newProp(m.trackList != nil, nil),           // https://specifications.freedesktop.org/mpris-spec/latest/Media_Player.html#Property:HasTrackList
+		"Identity":            newProp(types.AppName, nil),                // https://specifications.freedesktop.org/mpris-spec/latest/Media_Player.html#Property:Identity
+		"SupportedUriSchemes": newProp([]string{"netease", "https"}, nil), // https://specifications.freedesktop.org/mpris-spec/latest/Media_Player.html#Property:SupportedUriSchemes
+		"SupportedMimeTypes":  newProp([]string{}, nil),                   // https://specifications.freedesktop.org/mpris-spec/latest/Media_Player.html#Property:SupportedMimeTypes
 	}
 }
 
@@ -46,19 +47,38 @@ type RemoteControl struct {
 	props        *prop.Properties
 	once         sync.Once
 	currentTrack dbus.ObjectPath
+
+	// trackList、playlists 为 player 实现的可选接口，未实现时为 nil
+	trackList TrackListController
+	playlists PlaylistsController
+
+	listMu           sync.Mutex
+	tracks           []dbus.ObjectPath
+	trackEntries     []trackEntry
+	nextTrackID      uint64
+	playlistCache    []Playlist
+	activePlaylist   maybePlaylist
+	playlistsLoading atomic.Bool
 }
 
 func NewRemoteControl(p Controller, nowInfo PlayingInfo) *RemoteControl {
-	ctrl := &RemoteControl{
-		player: p,
-		name:   fmt.Sprintf("org.mpris.MediaPlayer2.musicfox.instance%d", os.Getpid()),
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		log.Default().Printf("[MPRIS] init dbus error: %+v", err)
+		return &RemoteControl{player: p}
 	}
+	return newRemoteControl(conn, p, nowInfo)
+}
 
-	var err error
-	if ctrl.dbus, err = dbus.SessionBus(); err != nil {
-		log.Default().Printf("[MPRIS] init dbus error: %+v", err)
-		return ctrl
+func newRemoteControl(conn *dbus.Conn, p Controller, nowInfo PlayingInfo) *RemoteControl {
+	ctrl := &RemoteControl{
+		player:         p,
+		name:           fmt.Sprintf("org.mpris.MediaPlayer2.musicfox.instance%d", os.Getpid()),
+		dbus:           conn,
+		activePlaylist: maybePlaylist{Playlist: mprisPlaylist{Id: "/"}},
 	}
+	ctrl.trackList, _ = p.(TrackListController)
+	ctrl.playlists, _ = p.(PlaylistsController)
 
 	mp2 := &MediaPlayer2{RemoteControl: ctrl}
 	_ = ctrl.dbus.Export(mp2, "/org/mpris/MediaPlayer2", "org.mpris.MediaPlayer2")
@@ -67,15 +87,26 @@ func NewRemoteControl(p Controller, nowInfo PlayingInfo) *RemoteControl {
 	mprisPlayer.createStatus(nowInfo)
 	_ = ctrl.dbus.Export(mprisPlayer, "/org/mpris/MediaPlayer2", "org.mpris.MediaPlayer2.Player")
 
-	_ = ctrl.dbus.Export(introspect.NewIntrospectable(ctrl.IntrospectNode()), "/org/mpris/MediaPlayer2", "org.freedesktop.DBus.Introspectable")
-
-	ctrl.props, _ = prop.Export(ctrl.dbus, "/org/mpris/MediaPlayer2", map[string]map[string]*prop.Prop{
+	props := map[string]map[string]*prop.Prop{
 		"org.mpris.MediaPlayer2":        mp2.properties(),
 		"org.mpris.MediaPlayer2.Player": mprisPlayer.props,
-	})
+	}
+	if ctrl.trackList != nil {
+		trackList := &TrackList{RemoteControl: ctrl, ctrl: ctrl.trackList}
+		_ = ctrl.dbus.Export(trackList, "/org/mpris/MediaPlayer2", trackListIface)
+		props[trackListIface] = trackList.properties()
+	}
+	if ctrl.playlists != nil {
+		playlists := &Playlists{RemoteControl: ctrl, ctrl: ctrl.playlists}
+		_ = ctrl.dbus.Export(playlists, "/org/mpris/MediaPlayer2", playlistsIface)
+		props[playlistsIface] = playlists.properties()
+	}
 
-	_, err = ctrl.dbus.RequestName(ctrl.name, dbus.NameFlagReplaceExisting)
-	if err != nil {
+	_ = ctrl.dbus.Export(introspect.NewIntrospectable(ctrl.IntrospectNode()), "/org/mpris/MediaPlayer2", "org.freedesktop.DBus.Introspectable")
+
+	ctrl.props, _ = prop.Export(ctrl.dbus, "/org/mpris/MediaPlayer2", props)
+
+	if _, err := ctrl.dbus.RequestName(ctrl.name, dbus.NameFlagReplaceExisting); err != nil {
 		log.Default().Printf("[MPRIS] dbus request name error: %+v", err)
 	}
 
@@ -83,7 +114,7 @@ func NewRemoteControl(p Controller, nowInfo PlayingInfo) *RemoteControl {
 }
 
 func (s *RemoteControl) IntrospectNode() *introspect.Node {
-	return &introspect.Node{
+	node := &introspect.Node{
 		Name: s.name,
 		Interfaces: []introspect.Interface{
 			introspect.IntrospectData,
@@ -261,13 +292,15 @@ func (s *RemoteControl) IntrospectNode() *introspect.Node {
 					},
 				},
 			},
-			// TODO: This interface is not fully implemented.
-			// introspect.Interface{
-			// 	Name: "org.mpris.MediaPlayer2.TrackList",
-
-			// },
 		},
 	}
+	if s.trackList != nil {
+		node.Interfaces = append(node.Interfaces, trackListIntrospection)
+	}
+	if s.playlists != nil {
+		node.Interfaces = append(node.Interfaces, playlistsIntrospection)
+	}
+	return node
 }
 
 func (s *RemoteControl) SetPlayingInfo(info PlayingInfo) {
@@ -280,9 +313,13 @@ func (s *RemoteControl) SetPlayingInfo(info PlayingInfo) {
 		s.setProp("org.mpris.MediaPlayer2.Player", "PlaybackStatus", dbus.MakeVariant(playbackStatus))
 	}
 
+	s.refreshTrackList()
 	if info.TrackID != 0 {
-		s.setProp("org.mpris.MediaPlayer2.Player", "Metadata", dbus.MakeVariant(MapFromPlayingInfo(info)))
-		s.currentTrack = dbus.ObjectPath(fmt.Sprintf("/org/mpd/Tracks/%d", info.TrackID))
+		s.currentTrack = s.currentTrackPath(info.TrackID)
+		metadata := MapFromPlayingInfo(info)
+		metadata["mpris:trackid"] = s.currentTrack
+		s.setProp("org.mpris.MediaPlayer2.Player", "Metadata", dbus.MakeVariant(metadata))
+		s.loadPlaylistsOnce()
 	}
 
 	newVolume := math.Max(0, float64(info.Volume)/100.0)
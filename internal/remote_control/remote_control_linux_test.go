@@ -0,0 +1,242 @@
+//go:build linux
+
+package remote_control
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+type fakeController struct {
+	mu        sync.Mutex
+	tracks    []Track
+	current   int
+	playlists []Playlist
+	active    int64
+}
+
+func (c *fakeController) CtrlPause()              {}
+func (c *fakeController) CtrlResume()             {}
+func (c *fakeController) CtrlStop()               {}
+func (c *fakeController) CtrlToggle()             {}
+func (c *fakeController) CtrlNext()               {}
+func (c *fakeController) CtrlPrevious()           {}
+func (c *fakeController) CtrlSeek(time.Duration)  {}
+func (c *fakeController) CtrlSetVolume(int)       {}
+func (c *fakeController) CtrlLikeNowPlaying()     {}
+func (c *fakeController) CtrlDislikeNowPlaying()  {}
+func (c *fakeController) CtrlShuffle()            {}
+func (c *fakeController) CtrlRepeat()             {}
+func (c *fakeController) CtrlSetRepeat(mode any)  {}
+func (c *fakeController) CtrlSetShuffle(mode any) {}
+
+func (c *fakeController) CtrlTrackList() ([]Track, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.tracks), c.current
+}
+
+func (c *fakeController) CtrlGoTo(index int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current = index
+	return nil
+}
+
+func (c *fakeController) CtrlAddTrack(songId int64, index int, setAsCurrent bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tracks = slices.Insert(c.tracks, index, Track{ID: songId, Name: "added"})
+	if setAsCurrent {
+		c.current = index
+	}
+	return nil
+}
+
+func (c *fakeController) CtrlRemoveTrack(index int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tracks = slices.Delete(c.tracks, index, index+1)
+	return nil
+}
+
+func (c *fakeController) CtrlPlaylists() ([]Playlist, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.playlists), nil
+}
+
+func (c *fakeController) CtrlActivatePlaylist(id int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active = id
+	c.tracks = []Track{{ID: id * 10, Name: "from playlist"}}
+	c.current = 0
+	return nil
+}
+
+func (c *fakeController) CtrlActivePlaylist() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.active
+}
+
+// privateBus 启动一个独立的 dbus-daemon，避免影响桌面会话
+func privateBus(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon not found")
+	}
+	out, err := exec.Command("dbus-daemon", "--session", "--fork", "--print-address=1", "--print-pid=1").Output()
+	if err != nil {
+		t.Skipf("start dbus-daemon: %v", err)
+	}
+	lines := strings.Fields(string(out))
+	if len(lines) != 2 {
+		t.Fatalf("unexpected dbus-daemon output: %q", out)
+	}
+	pid, _ := strconv.Atoi(lines[1])
+	t.Cleanup(func() {
+		if proc, err := os.FindProcess(pid); err == nil {
+			_ = proc.Signal(syscall.SIGTERM)
+		}
+	})
+	return lines[0]
+}
+
+func connect(t *testing.T, addr string) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.Connect(addr)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestTrackListAndPlaylists(t *testing.T) {
+	addr := privateBus(t)
+	ctrl := &fakeController{
+		tracks: []Track{
+			{ID: 1, Name: "one", Artist: "a", Duration: time.Minute},
+			{ID: -2, Name: "local"},
+			{ID: 1, Name: "one again"},
+		},
+		playlists: []Playlist{{ID: 7, Name: "b list"}, {ID: 8, Name: "a list"}},
+	}
+	rc := newRemoteControl(connect(t, addr), ctrl, PlayingInfo{})
+
+	client := connect(t, addr)
+	if err := client.AddMatchSignal(dbus.WithMatchInterface(trackListIface), dbus.WithMatchMember("TrackListReplaced")); err != nil {
+		t.Fatalf("add match: %v", err)
+	}
+	signals := make(chan *dbus.Signal, 10)
+	client.Signal(signals)
+	waitReplaced := func() []dbus.ObjectPath {
+		t.Helper()
+		select {
+		case sig := <-signals:
+			return sig.Body[0].([]dbus.ObjectPath)
+		case <-time.After(5 * time.Second):
+			t.Fatal("TrackListReplaced not received")
+		}
+		return nil
+	}
+
+	rc.SetPlayingInfo(PlayingInfo{State: types.Playing, TrackID: 1, Name: "one"})
+	ids := waitReplaced()
+	if len(ids) != 3 || ids[0] == ids[2] {
+		t.Fatalf("TrackListReplaced = %v, want 3 distinct ids", ids)
+	}
+	for _, id := range ids {
+		if !id.IsValid() || strings.Contains(string(id), "-") {
+			t.Errorf("invalid track id %q", id)
+		}
+	}
+
+	obj := client.Object(rc.name, "/org/mpris/MediaPlayer2")
+	hasTrackList, err := obj.GetProperty("org.mpris.MediaPlayer2.HasTrackList")
+	if err != nil || hasTrackList.Value() != true {
+		t.Errorf("HasTrackList = %v, %v", hasTrackList, err)
+	}
+	nowPlaying, err := obj.GetProperty("org.mpris.MediaPlayer2.Player.Metadata")
+	if err != nil || nowPlaying.Value().(map[string]dbus.Variant)["mpris:trackid"].Value() != ids[0] {
+		t.Errorf("Metadata = %v, %v, want trackid %s", nowPlaying, err, ids[0])
+	}
+
+	var metadata []map[string]dbus.Variant
+	if err = obj.Call(trackListIface+".GetTracksMetadata", 0, []dbus.ObjectPath{ids[2], trackPathBase + "99"}).Store(&metadata); err != nil {
+		t.Fatalf("GetTracksMetadata: %v", err)
+	}
+	if len(metadata) != 1 || metadata[0]["xesam:title"].Value() != "one again" || metadata[0]["mpris:trackid"].Value() != ids[2] {
+		t.Errorf("GetTracksMetadata = %v", metadata)
+	}
+
+	if err = obj.Call(trackListIface+".AddTrack", 0, "https://music.163.com/#/song?id=9", ids[0], false).Err; err != nil {
+		t.Fatalf("AddTrack: %v", err)
+	}
+	got := waitReplaced()
+	if len(got) != 4 || got[0] != ids[0] || slices.Contains(ids, got[1]) || !slices.Equal(got[2:], ids[1:]) {
+		t.Errorf("TrackListReplaced after AddTrack = %v, previous %v", got, ids)
+	}
+	added := got[1]
+
+	// 删除重复歌曲中的第二项，第一项保持不变
+	if err = obj.Call(trackListIface+".RemoveTrack", 0, ids[2]).Err; err != nil {
+		t.Fatalf("RemoveTrack: %v", err)
+	}
+	if got = waitReplaced(); !slices.Equal(got, []dbus.ObjectPath{ids[0], added, ids[1]}) {
+		t.Errorf("TrackListReplaced after RemoveTrack = %v", got)
+	}
+	if tracks, _ := ctrl.CtrlTrackList(); len(tracks) != 3 || tracks[0].Name != "one" || tracks[2].Name != "local" {
+		t.Errorf("tracks after RemoveTrack = %+v", tracks)
+	}
+
+	if err = obj.Call(trackListIface+".GoTo", 0, ids[1]).Err; err != nil {
+		t.Fatalf("GoTo: %v", err)
+	}
+	if _, current := ctrl.CtrlTrackList(); current != 2 {
+		t.Errorf("current after GoTo = %d, want 2", current)
+	}
+	if err = obj.Call(trackListIface+".GoTo", 0, ids[2]).Err; err == nil {
+		t.Error("GoTo removed track should fail")
+	}
+
+	var playlists []mprisPlaylist
+	if err = obj.Call(playlistsIface+".GetPlaylists", 0, uint32(0), uint32(10), "Alphabetical", false).Store(&playlists); err != nil {
+		t.Fatalf("GetPlaylists: %v", err)
+	}
+	if len(playlists) != 2 || playlists[0].Name != "a list" || playlists[0].Id != playlistPath(8) {
+		t.Errorf("GetPlaylists = %+v", playlists)
+	}
+	count, err := obj.GetProperty(playlistsIface + ".PlaylistCount")
+	if err != nil || count.Value() != uint32(2) {
+		t.Errorf("PlaylistCount = %v, %v", count, err)
+	}
+
+	if err = obj.Call(playlistsIface+".ActivatePlaylist", 0, playlistPath(7)).Err; err != nil {
+		t.Fatalf("ActivatePlaylist: %v", err)
+	}
+	if got = waitReplaced(); len(got) != 1 || slices.Contains(ids, got[0]) || got[0] == added {
+		t.Errorf("TrackListReplaced after ActivatePlaylist = %v", got)
+	}
+	var active maybePlaylist
+	v, err := obj.GetProperty(playlistsIface + ".ActivePlaylist")
+	if err == nil {
+		err = dbus.Store([]any{v.Value()}, &active)
+	}
+	if err != nil || !active.Valid || active.Playlist.Id != playlistPath(7) || active.Playlist.Name != "b list" {
+		t.Errorf("ActivePlaylist = %+v, %v", active, err)
+	}
+}
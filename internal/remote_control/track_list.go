@@ -0,0 +1,42 @@
+package remote_control
+
+import "time"
+
+// Track 播放队列中的一首歌曲
+type Track struct {
+	ID          int64
+	Name        string
+	Artist      string
+	Album       string
+	AlbumArtist string
+	PicUrl      string
+	Duration    time.Duration
+}
+
+// Playlist 用户的歌单
+type Playlist struct {
+	ID   int64
+	Name string
+}
+
+// TrackListController 可选实现，Controller 同时实现时提供播放队列的查看与编辑（MPRIS TrackList）
+type TrackListController interface {
+	// CtrlTrackList 当前播放队列及正在播放的位置
+	CtrlTrackList() (tracks []Track, current int)
+	// CtrlGoTo 播放队列中 index 位置的歌曲
+	CtrlGoTo(index int) error
+	// CtrlAddTrack 把歌曲插入到队列的 index 位置，setAsCurrent 为 true 时立即播放
+	CtrlAddTrack(songId int64, index int, setAsCurrent bool) error
+	// CtrlRemoveTrack 从队列中移除 index 位置的歌曲
+	CtrlRemoveTrack(index int) error
+}
+
+// PlaylistsController 可选实现，Controller 同时实现时可以浏览并播放用户歌单（MPRIS Playlists）
+type PlaylistsController interface {
+	// CtrlPlaylists 当前用户的歌单
+	CtrlPlaylists() ([]Playlist, error)
+	// CtrlActivatePlaylist 用歌单替换播放队列并开始播放
+	CtrlActivatePlaylist(id int64) error
+	// CtrlActivePlaylist 播放队列来自的歌单，不是歌单时返回 0
+	CtrlActivePlaylist() int64
+}
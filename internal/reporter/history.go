@@ -0,0 +1,70 @@
+package reporter
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// historyRecorder 在本地记录每次播放
+//
+// 与上报到远端的 reporter 不同，不足 20 秒的播放也会记录（作为跳过），
+// 并且在调用方的 goroutine 中同步执行，保证开始与结束一一对应。
+type historyRecorder struct {
+	source func() string // 当前播放来源
+	engine string
+	save   func(*storage.PlayRecord) error
+
+	song      structs.Song
+	from      string
+	startedAt time.Time
+}
+
+// WithHistory 记录本地听歌历史，source 返回开始播放时的来源菜单
+func WithHistory(source func() string, engine string) Option {
+	return func(m *MasterReporter) {
+		m.history = &historyRecorder{
+			source: source,
+			engine: engine,
+			save:   storage.PlayHistory{}.Add,
+		}
+	}
+}
+
+func (h *historyRecorder) start(song structs.Song) {
+	h.song = song
+	h.startedAt = time.Now()
+	h.from = ""
+	if h.source != nil {
+		h.from = h.source()
+	}
+}
+
+func (h *historyRecorder) end(playedTime time.Duration) {
+	if h.song.Id == 0 {
+		return
+	}
+	record := &storage.PlayRecord{
+		Song:      h.song,
+		Source:    h.from,
+		StartedAt: h.startedAt,
+		Played:    playedTime,
+		Completed: playCompleted(h.song.Duration, playedTime),
+		Engine:    h.engine,
+	}
+	h.song = structs.Song{}
+	if err := h.save(record); err != nil {
+		slog.Warn("save play history failed", slogx.Error(err))
+	}
+}
+
+// playCompleted 播放超过八成或距结尾不足 10 秒视为完整播放，否则视为跳过；时长未知时以 30 秒为界
+func playCompleted(duration, played time.Duration) bool {
+	if duration <= 0 {
+		return played >= 30*time.Second
+	}
+	return played >= duration*4/5 || duration-played <= 10*time.Second
+}
@@ -0,0 +1,53 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+func TestHistoryRecordsEveryPlay(t *testing.T) {
+	var records []storage.PlayRecord
+	master := NewService(WithHistory(func() string { return "daily_songs" }, "null")).(*MasterReporter)
+	master.history.save = func(record *storage.PlayRecord) error {
+		records = append(records, *record)
+		return nil
+	}
+
+	song := structs.Song{Id: 1, Duration: 3 * time.Minute}
+	master.ReportEnd(time.Minute) // 尚未开始播放
+	master.ReportStart(song)
+	master.ReportEnd(5 * time.Second)
+	master.ReportStart(song)
+	master.ReportEnd(2*time.Minute + 55*time.Second)
+
+	if len(records) != 2 {
+		t.Fatalf("records = %+v, want 2", records)
+	}
+	if r := records[0]; r.Completed || r.Played != 5*time.Second || r.Source != "daily_songs" || r.Engine != "null" || r.StartedAt.IsZero() {
+		t.Errorf("skipped record = %+v", r)
+	}
+	if !records[1].Completed {
+		t.Errorf("second record should be completed: %+v", records[1])
+	}
+}
+
+func TestPlayCompleted(t *testing.T) {
+	tests := []struct {
+		duration, played time.Duration
+		want             bool
+	}{
+		{4 * time.Minute, 3*time.Minute + 20*time.Second, true},
+		{4 * time.Minute, 2 * time.Minute, false},
+		{20 * time.Second, 12 * time.Second, true}, // 距结尾不足 10 秒
+		{0, time.Minute, true},
+		{0, 10 * time.Second, false},
+	}
+	for _, tt := range tests {
+		if got := playCompleted(tt.duration, tt.played); got != tt.want {
+			t.Errorf("playCompleted(%v, %v) = %v, want %v", tt.duration, tt.played, got, tt.want)
+		}
+	}
+}
@@ -18,11 +18,17 @@ func newNeteaseReporter() reporter {
 }
 
 func (n *neteaseReporter) reportStart(song structs.Song) {
+	if song.IsLocal() {
+		return
+	}
 	n.buildNeteaseReportService(song, 0).Playstart()
 }
 
 func (n *neteaseReporter) reportEnd(song structs.Song, passedTime time.Duration) {
-	svc := n.buildNeteaseReportService(song, passedTime) 
+	if song.IsLocal() {
+		return
+	}
+	svc := n.buildNeteaseReportService(song, passedTime)
 
 	switch {
 	case math.Abs(song.Duration.Seconds()-passedTime.Seconds()) <= 10:
@@ -23,6 +23,7 @@ type MasterReporter struct {
 	mu          sync.Mutex
 	currentSong structs.Song
 	reporters   []reporter
+	history     *historyRecorder
 }
 
 type Option func(*MasterReporter)
@@ -58,6 +59,9 @@ func (m *MasterReporter) ReportStart(song structs.Song) {
 		return
 	}
 
+	if m.history != nil {
+		m.history.start(song)
+	}
 	m.currentSong = song
 	for _, r := range m.reporters {
 		go func(rp reporter) {
@@ -70,6 +74,10 @@ func (m *MasterReporter) ReportEnd(passedTime time.Duration) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.history != nil {
+		m.history.end(passedTime)
+	}
+
 	if m.currentSong.Id == 0 {
 		return
 	}
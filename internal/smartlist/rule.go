@@ -0,0 +1,231 @@
+package smartlist
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/history"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+type fieldKind uint8
+
+const (
+	kindText     fieldKind = iota // 文本，支持 = != ~ !~
+	kindNumber                    // 数字
+	kindDuration                  // 时长，如 4m、3m30s、90s
+	kindAge                       // 距今时长，如 30d、2w、12h，从未播放视为无穷久
+	kindBool                      // true / false
+)
+
+// field 可用于规则与排序的歌曲字段
+type field struct {
+	kind   fieldKind
+	text   func(song structs.Song) []string
+	number func(song structs.Song, facts *facts) float64
+}
+
+var fields = map[string]field{
+	"name": {kind: kindText, text: func(song structs.Song) []string { return []string{song.Name} }},
+	"artist": {kind: kindText, text: func(song structs.Song) []string {
+		names := make([]string, len(song.Artists))
+		for i, artist := range song.Artists {
+			names[i] = artist.Name
+		}
+		return names
+	}},
+	"album":    {kind: kindText, text: func(song structs.Song) []string { return []string{song.Album.Name} }},
+	"duration": {kind: kindDuration, number: func(song structs.Song, _ *facts) float64 { return song.Duration.Seconds() }},
+	"liked": {kind: kindBool, number: func(song structs.Song, f *facts) float64 {
+		return boolNumber(f.liked != nil && f.liked(song.Id))
+	}},
+	"local": {kind: kindBool, number: func(song structs.Song, _ *facts) float64 { return boolNumber(song.IsLocal()) }},
+	"plays": {kind: kindNumber, number: func(song structs.Song, f *facts) float64 { return float64(f.count(song).Plays) }},
+	"skips": {kind: kindNumber, number: func(song structs.Song, f *facts) float64 { return float64(f.count(song).Skips) }},
+	"skipRate": {kind: kindNumber, number: func(song structs.Song, f *facts) float64 {
+		return f.count(song).SkipRate()
+	}},
+	"lastPlayed": {kind: kindAge, number: func(song structs.Song, f *facts) float64 {
+		last := f.count(song).LastPlayed
+		if last.IsZero() {
+			return math.Inf(1)
+		}
+		return f.now.Sub(last).Seconds()
+	}},
+}
+
+func boolNumber(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// facts 评估规则时用到的本地数据
+type facts struct {
+	now   time.Time
+	liked func(id int64) bool
+	plays map[string]history.Count
+}
+
+func (f *facts) count(song structs.Song) history.Count {
+	return f.plays[history.SongKey(song)]
+}
+
+// operators 按长度降序，保证先匹配 "<=" 再匹配 "<"
+var operators = []string{"!=", "!~", "<=", ">=", "=", "~", "<", ">"}
+
+// Rule 一条筛选规则，形如 "artist ~ 周杰伦|林俊杰"、"duration < 4m"
+type Rule struct {
+	Field string
+	Op    string
+	Value string
+
+	field  field
+	texts  []string // 文本取值，"|" 分隔表示任意一个
+	number float64
+}
+
+// ParseRule 解析规则
+func ParseRule(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	end := strings.IndexAny(s, "!=<>~")
+	if end <= 0 {
+		return Rule{}, errors.Errorf("无效的规则: %q", s)
+	}
+	rule := Rule{Field: strings.TrimSpace(s[:end])}
+	rest := s[end:]
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			rule.Op = op
+			break
+		}
+	}
+	if rule.Op == "" {
+		return Rule{}, errors.Errorf("无效的规则: %q", s)
+	}
+	rule.Value = strings.Trim(strings.TrimSpace(rest[len(rule.Op):]), `"'`)
+
+	var ok bool
+	if rule.field, ok = fields[rule.Field]; !ok {
+		return Rule{}, errors.Errorf("未知的字段 %q: %q", rule.Field, s)
+	}
+	if err := rule.parseValue(); err != nil {
+		return Rule{}, errors.WithMessagef(err, "规则 %q", s)
+	}
+	return rule, nil
+}
+
+func (r *Rule) parseValue() error {
+	var err error
+	switch r.field.kind {
+	case kindText:
+		switch r.Op {
+		case "=", "!=", "~", "!~":
+		default:
+			return errors.Errorf("文本字段不支持 %s", r.Op)
+		}
+		for _, text := range strings.Split(r.Value, "|") {
+			r.texts = append(r.texts, strings.ToLower(strings.TrimSpace(text)))
+		}
+		return nil
+	case kindBool:
+		if r.Op != "=" && r.Op != "!=" {
+			return errors.Errorf("布尔字段不支持 %s", r.Op)
+		}
+		var b bool
+		if b, err = strconv.ParseBool(r.Value); err == nil {
+			r.number = boolNumber(b)
+		}
+		return err
+	}
+
+	if r.Op == "~" || r.Op == "!~" {
+		return errors.Errorf("%s 只能用于文本字段", r.Op)
+	}
+	switch r.field.kind {
+	case kindDuration, kindAge:
+		var d time.Duration
+		if d, err = ParseDuration(r.Value); err == nil {
+			r.number = d.Seconds()
+		}
+	default:
+		if pct, ok := strings.CutSuffix(r.Value, "%"); ok {
+			if r.number, err = strconv.ParseFloat(pct, 64); err == nil {
+				r.number /= 100
+			}
+		} else {
+			r.number, err = strconv.ParseFloat(r.Value, 64)
+		}
+	}
+	return err
+}
+
+// match 歌曲是否满足规则
+func (r Rule) match(song structs.Song, f *facts) bool {
+	if r.field.kind == kindText {
+		return r.matchText(r.field.text(song))
+	}
+	v := r.field.number(song, f)
+	switch r.Op {
+	case "=":
+		return v == r.number
+	case "!=":
+		return v != r.number
+	case "<":
+		return v < r.number
+	case "<=":
+		return v <= r.number
+	case ">":
+		return v > r.number
+	case ">=":
+		return v >= r.number
+	}
+	return false
+}
+
+// matchText 多个值（如多位歌手）中任意一个满足即可；否定运算要求全部不满足
+func (r Rule) matchText(values []string) bool {
+	negate := r.Op == "!=" || r.Op == "!~"
+	for _, value := range values {
+		value = strings.ToLower(value)
+		for _, text := range r.texts {
+			var hit bool
+			if r.Op == "~" || r.Op == "!~" {
+				hit = strings.Contains(value, text)
+			} else {
+				hit = value == text
+			}
+			if hit {
+				return !negate
+			}
+		}
+	}
+	return negate
+}
+
+// ParseDuration 在 time.ParseDuration 的基础上支持天（d）、周（w），纯数字视为秒
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(n * float64(time.Second)), nil
+	}
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if v, ok := strings.CutSuffix(s, suffix); ok {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return 0, errors.Errorf("无效的时长: %q", s)
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.Errorf("无效的时长: %q", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,180 @@
+package smartlist
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/history"
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+// Env 计算智能歌单需要的外部数据
+type Env struct {
+	UserId  int64                 // 获取网易云来源的用户，未登录为 0
+	Liked   func(id int64) bool   // 是否为喜欢的歌曲
+	Local   func() []structs.Song // 本地音乐库中的歌曲
+	Records []storage.PlayRecord  // 本地听歌记录
+	Now     time.Time
+}
+
+// Playlist 解析后的智能歌单
+type Playlist struct {
+	Name    string
+	Sources []string
+
+	rules    []Rule
+	matchAny bool
+	sortBy   string // 为空时保持来源顺序
+	desc     bool
+	random   bool
+	limit    int
+}
+
+// New 解析智能歌单配置
+func New(cfg configs.SmartPlaylistConfig) (*Playlist, error) {
+	p := &Playlist{
+		Name:    cfg.Name,
+		Sources: cfg.Sources,
+		limit:   max(cfg.Limit, 0),
+	}
+	if p.Name == "" {
+		return nil, errors.New("智能歌单缺少名称")
+	}
+	if len(p.Sources) == 0 {
+		p.Sources = []string{SourceLike}
+	}
+	for _, source := range p.Sources {
+		if !validSource(source) {
+			return nil, errors.Errorf("未知的来源: %q", source)
+		}
+	}
+
+	switch strings.ToLower(cfg.Match) {
+	case "", "all":
+	case "any":
+		p.matchAny = true
+	default:
+		return nil, errors.Errorf("无效的 match: %q", cfg.Match)
+	}
+
+	for _, s := range cfg.Rules {
+		rule, err := ParseRule(s)
+		if err != nil {
+			return nil, err
+		}
+		p.rules = append(p.rules, rule)
+	}
+
+	sortBy, order, _ := strings.Cut(strings.TrimSpace(cfg.Sort), " ")
+	switch order = strings.ToLower(strings.TrimSpace(order)); order {
+	case "", "asc":
+	case "desc":
+		p.desc = true
+	default:
+		return nil, errors.Errorf("无效的排序方向: %q", order)
+	}
+	switch sortBy {
+	case "":
+	case "random":
+		p.random = true
+	default:
+		if _, ok := fields[sortBy]; !ok {
+			return nil, errors.Errorf("未知的排序字段: %q", sortBy)
+		}
+		p.sortBy = sortBy
+	}
+	return p, nil
+}
+
+// NeedsLogin 是否有来源需要登录网易云
+func (p *Playlist) NeedsLogin() bool {
+	return slices.ContainsFunc(p.Sources, isNeteaseSource)
+}
+
+// Songs 获取各来源的歌曲并按规则筛选、排序
+func (p *Playlist) Songs(env Env) ([]structs.Song, error) {
+	var candidates []structs.Song
+	for _, source := range p.Sources {
+		songs, err := fetchSource(source, env)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "获取 %s 失败", source)
+		}
+		candidates = append(candidates, songs...)
+	}
+	return p.Filter(candidates, env), nil
+}
+
+// Filter 对候选歌曲去重后按规则筛选、排序并截取
+func (p *Playlist) Filter(candidates []structs.Song, env Env) []structs.Song {
+	f := &facts{now: env.Now, liked: env.Liked, plays: make(map[string]history.Count)}
+	if f.now.IsZero() {
+		f.now = time.Now()
+	}
+	for _, c := range history.Compute(env.Records).Songs {
+		f.plays[history.SongKey(c.Song)] = c
+	}
+
+	seen := make(map[string]struct{}, len(candidates))
+	songs := make([]structs.Song, 0, len(candidates))
+	for _, song := range candidates {
+		key := history.SongKey(song)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		if p.match(song, f) {
+			songs = append(songs, song)
+		}
+	}
+
+	switch {
+	case p.random:
+		rand.Shuffle(len(songs), func(i, j int) { songs[i], songs[j] = songs[j], songs[i] })
+	case p.sortBy != "":
+		p.sort(songs, f)
+	}
+	if p.limit > 0 && len(songs) > p.limit {
+		songs = songs[:p.limit]
+	}
+	return songs
+}
+
+func (p *Playlist) match(song structs.Song, f *facts) bool {
+	if len(p.rules) == 0 {
+		return true
+	}
+	for _, rule := range p.rules {
+		if rule.match(song, f) == p.matchAny {
+			return p.matchAny
+		}
+	}
+	return !p.matchAny
+}
+
+func (p *Playlist) sort(songs []structs.Song, f *facts) {
+	field := fields[p.sortBy]
+	compare := func(a, b structs.Song) int {
+		if field.kind == kindText {
+			return strings.Compare(strings.Join(field.text(a), ","), strings.Join(field.text(b), ","))
+		}
+		va, vb := field.number(a, f), field.number(b, f)
+		if field.kind == kindAge {
+			// 按播放时间排序：asc 为最早播放（含从未播放）在前
+			va, vb = -va, -vb
+		}
+		return cmp.Compare(va, vb)
+	}
+	slices.SortStableFunc(songs, func(a, b structs.Song) int {
+		if p.desc {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+}
@@ -0,0 +1,109 @@
+package smartlist
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+func testSong(id int64, name, artist string, duration time.Duration) structs.Song {
+	return structs.Song{Id: id, Name: name, Duration: duration, Artists: []structs.Artist{{Name: artist}}}
+}
+
+func songIds(songs []structs.Song) []int64 {
+	ids := make([]int64, len(songs))
+	for i, song := range songs {
+		ids[i] = song.Id
+	}
+	return ids
+}
+
+func TestParseRule(t *testing.T) {
+	valid := []string{"artist ~ 周杰伦|林俊杰", "duration<4m", "lastPlayed > 30d", "liked = true", "skipRate >= 50%", `name != "晴天"`}
+	for _, s := range valid {
+		if _, err := ParseRule(s); err != nil {
+			t.Errorf("ParseRule(%q): %v", s, err)
+		}
+	}
+	invalid := []string{"", "artist", "genre = rock", "duration ~ 4m", "name < a", "liked > 1", "plays = many", "lastPlayed > 1 month"}
+	for _, s := range invalid {
+		if _, err := ParseRule(s); err == nil {
+			t.Errorf("ParseRule(%q) should fail", s)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	now := time.Date(2024, time.May, 16, 12, 0, 0, 0, time.Local)
+	songs := []structs.Song{
+		testSong(1, "晴天", "周杰伦", 4*time.Minute+29*time.Second),
+		testSong(2, "稻香", "周杰伦", 3*time.Minute+43*time.Second),
+		testSong(3, "江南", "林俊杰", 4*time.Minute+27*time.Second),
+		testSong(4, "七里香", "周杰伦", 4*time.Minute+59*time.Second),
+		testSong(2, "稻香", "周杰伦", 3*time.Minute+43*time.Second), // 重复
+	}
+	record := func(song structs.Song, daysAgo int) storage.PlayRecord {
+		return storage.PlayRecord{Song: song, StartedAt: now.AddDate(0, 0, -daysAgo), Played: song.Duration, Completed: true}
+	}
+	env := Env{
+		Liked: func(id int64) bool { return id != 3 },
+		Records: []storage.PlayRecord{
+			record(songs[0], 40),
+			record(songs[1], 3),
+			record(songs[1], 2),
+			record(songs[2], 1),
+		},
+		Now: now,
+	}
+
+	tests := []struct {
+		name string
+		cfg  configs.SmartPlaylistConfig
+		want []int64
+	}{
+		{"all rules", configs.SmartPlaylistConfig{Rules: []string{"artist = 周杰伦", "liked = true", "duration < 4m30s"}}, []int64{1, 2}},
+		{"any rule", configs.SmartPlaylistConfig{Rules: []string{"artist ~ 林", "duration < 4m"}, Match: "any"}, []int64{2, 3}},
+		{"not played recently", configs.SmartPlaylistConfig{Rules: []string{"lastPlayed > 30d"}}, []int64{1, 4}},
+		{"sort and limit", configs.SmartPlaylistConfig{Sort: "plays desc", Limit: 2}, []int64{2, 1}},
+		{"last played first", configs.SmartPlaylistConfig{Sort: "lastPlayed desc"}, []int64{3, 2, 1, 4}},
+		{"negated text", configs.SmartPlaylistConfig{Rules: []string{"name !~ 香"}}, []int64{1, 3}},
+	}
+	for _, tt := range tests {
+		tt.cfg.Name = tt.name
+		p, err := New(tt.cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := songIds(p.Filter(songs, env)); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewValidatesConfig(t *testing.T) {
+	invalid := []configs.SmartPlaylistConfig{
+		{},
+		{Name: "a", Sources: []string{"name:"}},
+		{Name: "a", Sources: []string{"fm"}},
+		{Name: "a", Match: "some"},
+		{Name: "a", Sort: "genre"},
+		{Name: "a", Sort: "plays down"},
+	}
+	for _, cfg := range invalid {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) should fail", cfg)
+		}
+	}
+
+	p, err := New(configs.SmartPlaylistConfig{Name: "offline", Sources: []string{SourceHistory, SourceLocal}})
+	if err != nil || p.NeedsLogin() {
+		t.Errorf("local sources should not need login: %v", err)
+	}
+	if p, _ := New(configs.SmartPlaylistConfig{Name: "default"}); !p.NeedsLogin() {
+		t.Error("default source is the like list and needs login")
+	}
+}
@@ -0,0 +1,64 @@
+package smartlist
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/netease"
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+const (
+	SourceLike      = "like"      // 我喜欢的音乐
+	SourceDailyReco = "dailyReco" // 每日推荐
+	SourceHistory   = "history"   // 本地听歌记录中的歌曲，最近播放的在前
+	SourceLocal     = "local"     // 本地音乐库
+	sourceName      = "name:"     // 指定歌单名 name:[歌单名]
+)
+
+func validSource(source string) bool {
+	switch source {
+	case SourceLike, SourceDailyReco, SourceHistory, SourceLocal:
+		return true
+	}
+	name, ok := strings.CutPrefix(source, sourceName)
+	return ok && name != ""
+}
+
+func isNeteaseSource(source string) bool {
+	return source != SourceHistory && source != SourceLocal
+}
+
+func fetchSource(source string, env Env) ([]structs.Song, error) {
+	if isNeteaseSource(source) && env.UserId == 0 {
+		return nil, errors.New("账号未登录")
+	}
+	switch source {
+	case SourceLike:
+		return netease.FetchLikeSongs(env.UserId, true)
+	case SourceDailyReco:
+		return netease.FetchDailySongs()
+	case SourceHistory:
+		return historySongs(env.Records), nil
+	case SourceLocal:
+		if env.Local == nil {
+			return nil, nil
+		}
+		return env.Local(), nil
+	default:
+		name, _ := strings.CutPrefix(source, sourceName)
+		return netease.FetchUserPlaylistByName(env.UserId, name, true)
+	}
+}
+
+// historySongs 听歌记录中出现过的歌曲，最近播放的在前
+func historySongs(records []storage.PlayRecord) []structs.Song {
+	songs := make([]structs.Song, 0, len(records))
+	for _, record := range slices.Backward(records) {
+		songs = append(songs, record.Song)
+	}
+	return songs
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+// DownloadState 下载任务状态
+type DownloadState string
+
+const (
+	DownloadQueued   DownloadState = "queued"
+	DownloadRunning  DownloadState = "running"
+	DownloadRetrying DownloadState = "retrying"
+	DownloadDone     DownloadState = "done"
+	DownloadFailed   DownloadState = "failed"
+)
+
+// DownloadJob 下载队列中的一首歌
+type DownloadJob struct {
+	Song       structs.Song  `json:"song"`
+	Group      string        `json:"group"` // 来源歌单/专辑/歌手名，单曲下载时为空
+	State      DownloadState `json:"state"`
+	Downloaded int64         `json:"downloaded"` // 已下载字节数
+	Total      int64         `json:"total"`      // 文件大小，未知时为 -1
+	Attempts   int           `json:"attempts"`
+	Error      string        `json:"error,omitempty"`
+	Path       string        `json:"path,omitempty"`
+	Existed    bool          `json:"existed,omitempty"` // 文件已存在，未重新下载
+	CreatedAt  int64         `json:"createdAt"`         // UnixNano，用于保持队列顺序
+	UpdatedAt  int64         `json:"updatedAt"`
+}
+
+// DownloadQueue 下载队列，每首歌对应 download_queue 桶中的一条记录，key 为歌曲 ID
+type DownloadQueue struct{}
+
+func (d DownloadQueue) GetDbName() string {
+	return types.AppDBName
+}
+
+func (d DownloadQueue) GetTableName() string {
+	return "download_queue"
+}
+
+// Jobs 读取全部下载任务
+func (d DownloadQueue) Jobs() ([]DownloadJob, error) {
+	var jobs []DownloadJob
+	err := NewTable().AllMap(d, func(_, v []byte) error {
+		var job DownloadJob
+		if err := json.Unmarshal(v, &job); err != nil {
+			// 单条记录损坏不影响其余记录
+			return nil
+		}
+		jobs = append(jobs, job)
+		return nil
+	})
+	return jobs, err
+}
+
+// Put 写入或覆盖一个下载任务
+func (d DownloadQueue) Put(job DownloadJob) error {
+	return NewTable().Set(d, downloadJobKey(job.Song.Id), job)
+}
+
+// Remove 删除一个下载任务
+func (d DownloadQueue) Remove(songID int64) error {
+	return NewTable().Delete(d, downloadJobKey(songID))
+}
+
+func downloadJobKey(songID int64) []byte {
+	return []byte(strconv.FormatInt(songID, 10))
+}
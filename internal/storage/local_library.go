@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+// LocalTrack 本地音乐库中的一条索引记录
+type LocalTrack struct {
+	Song        structs.Song `json:"song"`
+	TrackNumber int          `json:"trackNumber"`
+	Size        int64        `json:"size"`
+	ModTime     int64        `json:"modTime"` // 文件修改时间（UnixNano），用于增量扫描
+}
+
+// LocalLibrary 本地音乐库索引，每个文件对应 local_library 桶中的一条记录，key 为文件路径
+type LocalLibrary struct{}
+
+func (l LocalLibrary) GetDbName() string {
+	return types.AppDBName
+}
+
+func (l LocalLibrary) GetTableName() string {
+	return "local_library"
+}
+
+// Tracks 读取全部索引记录
+func (l LocalLibrary) Tracks() ([]LocalTrack, error) {
+	var tracks []LocalTrack
+	err := NewTable().AllMap(l, func(_, v []byte) error {
+		var track LocalTrack
+		if err := json.Unmarshal(v, &track); err != nil {
+			// 单条记录损坏不影响其余记录
+			return nil
+		}
+		tracks = append(tracks, track)
+		return nil
+	})
+	return tracks, err
+}
+
+// Put 写入或覆盖一条索引记录
+func (l LocalLibrary) Put(track LocalTrack) error {
+	return NewTable().Set(l, []byte(track.Song.LocalPath), track)
+}
+
+// Remove 删除一条索引记录
+func (l LocalLibrary) Remove(path string) error {
+	return NewTable().Delete(l, []byte(path))
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+// LocalPlaylist 仅保存在本地的歌单，网易云歌曲与本地音乐文件可以混合
+type LocalPlaylist struct {
+	ID        uint64         `json:"id"`
+	Name      string         `json:"name"`
+	Songs     []structs.Song `json:"songs"`
+	CreatedAt int64          `json:"createdAt"` // Unix 秒
+	UpdatedAt int64          `json:"updatedAt"`
+}
+
+func (p *LocalPlaylist) SetID(id uint64) {
+	p.ID = id
+}
+
+// LocalPlaylists 本地歌单，每个歌单对应 local_playlists 桶中的一条记录，key 为自增 ID
+type LocalPlaylists struct{}
+
+func (l LocalPlaylists) GetDbName() string {
+	return types.AppDBName
+}
+
+func (l LocalPlaylists) GetTableName() string {
+	return "local_playlists"
+}
+
+// All 读取全部本地歌单，按创建顺序排列
+func (l LocalPlaylists) All() ([]LocalPlaylist, error) {
+	var playlists []LocalPlaylist
+	err := NewTable().AllMap(l, func(_, v []byte) error {
+		var playlist LocalPlaylist
+		if err := json.Unmarshal(v, &playlist); err != nil {
+			// 单条记录损坏不影响其余记录
+			return nil
+		}
+		playlists = append(playlists, playlist)
+		return nil
+	})
+	return playlists, err
+}
+
+// Get 读取单个歌单
+func (l LocalPlaylists) Get(id uint64) (LocalPlaylist, error) {
+	var playlist LocalPlaylist
+	value, err := NewTable().GetByID(l, id)
+	if err != nil {
+		return playlist, err
+	}
+	if value == nil {
+		return playlist, errors.Errorf("local playlist %d not exists", id)
+	}
+	err = json.Unmarshal(value, &playlist)
+	return playlist, err
+}
+
+// Add 新建歌单，ID 由数据库分配
+func (l LocalPlaylists) Add(playlist *LocalPlaylist) error {
+	_, err := NewTable().IncrAdd(l, playlist)
+	return err
+}
+
+// Put 覆盖保存歌单
+func (l LocalPlaylists) Put(playlist LocalPlaylist) error {
+	return NewTable().SetByID(l, playlist.ID, playlist)
+}
+
+// Remove 删除歌单
+func (l LocalPlaylists) Remove(id uint64) error {
+	return NewTable().DeleteByID(l, id)
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+// 离线固定的集合类型
+const (
+	OfflinePinPlaylist = "playlist"
+	OfflinePinAlbum    = "album"
+)
+
+// OfflinePin 一个已固定到离线存储的歌单或专辑，保存歌曲元数据以便离线浏览
+type OfflinePin struct {
+	Kind     string         `json:"kind"`
+	ID       int64          `json:"id"`
+	Name     string         `json:"name"`
+	Songs    []structs.Song `json:"songs"`
+	PinnedAt int64          `json:"pinnedAt"` // Unix 秒
+}
+
+// Key 记录在 offline_pins 桶中的 key
+func (p OfflinePin) Key() string {
+	return fmt.Sprintf("%s:%d", p.Kind, p.ID)
+}
+
+// OfflinePins 已固定的歌单/专辑，每个集合对应 offline_pins 桶中的一条记录
+type OfflinePins struct{}
+
+func (o OfflinePins) GetDbName() string {
+	return types.AppDBName
+}
+
+func (o OfflinePins) GetTableName() string {
+	return "offline_pins"
+}
+
+// All 读取全部固定记录，按固定时间倒序排列
+func (o OfflinePins) All() ([]OfflinePin, error) {
+	var pins []OfflinePin
+	err := NewTable().AllMap(o, func(_, v []byte) error {
+		var pin OfflinePin
+		if err := json.Unmarshal(v, &pin); err != nil {
+			// 单条记录损坏不影响其余记录
+			return nil
+		}
+		pins = append(pins, pin)
+		return nil
+	})
+	sort.SliceStable(pins, func(i, j int) bool {
+		return pins[i].PinnedAt > pins[j].PinnedAt
+	})
+	return pins, err
+}
+
+// Put 写入或覆盖一条固定记录
+func (o OfflinePins) Put(pin OfflinePin) error {
+	return NewTable().Set(o, []byte(pin.Key()), pin)
+}
+
+// Remove 删除一条固定记录
+func (o OfflinePins) Remove(pin OfflinePin) error {
+	return NewTable().Delete(o, []byte(pin.Key()))
+}
+
+// OfflineMode 离线模式开关
+type OfflineMode struct{}
+
+func (o OfflineMode) GetDbName() string {
+	return types.AppDBName
+}
+
+func (o OfflineMode) GetTableName() string {
+	return "default_bucket"
+}
+
+func (o OfflineMode) GetKey() string {
+	return "offline_mode"
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+// PlayRecord 一次播放记录
+type PlayRecord struct {
+	ID        uint64        `json:"id"`
+	Song      structs.Song  `json:"song"`
+	Source    string        `json:"source,omitempty"` // 播放来源菜单
+	StartedAt time.Time     `json:"startedAt"`
+	Played    time.Duration `json:"played"`    // 实际播放时长，不含暂停与跳转
+	Completed bool          `json:"completed"` // 完整播放，否则视为跳过
+	Engine    string        `json:"engine,omitempty"`
+}
+
+func (r *PlayRecord) SetID(id uint64) {
+	r.ID = id
+}
+
+// PlayHistory 本地听歌记录，每次播放对应 play_history 桶中的一条记录，key 为自增 ID
+type PlayHistory struct{}
+
+func (h PlayHistory) GetDbName() string {
+	return types.AppDBName
+}
+
+func (h PlayHistory) GetTableName() string {
+	return "play_history"
+}
+
+// Add 追加一条播放记录
+func (h PlayHistory) Add(record *PlayRecord) error {
+	_, err := NewTable().IncrAdd(h, record)
+	return err
+}
+
+// Records 读取 since 之后开始的播放记录，按时间顺序排列；since 为零值时读取全部
+func (h PlayHistory) Records(since time.Time) ([]PlayRecord, error) {
+	var records []PlayRecord
+	err := NewTable().AllMap(h, func(_, v []byte) error {
+		var record PlayRecord
+		if err := json.Unmarshal(v, &record); err != nil {
+			// 单条记录损坏不影响其余记录
+			return nil
+		}
+		if record.StartedAt.Before(since) {
+			return nil
+		}
+		records = append(records, record)
+		return nil
+	})
+	return records, err
+}
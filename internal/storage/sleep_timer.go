@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+// SleepTimer 睡眠定时状态，重启后恢复
+type SleepTimer struct {
+	Mode     string    `json:"mode"`
+	Deadline time.Time `json:"deadline,omitempty"` // 按时长停止时的截止时间
+	Songs    int       `json:"songs,omitempty"`    // 按歌曲数停止时剩余的歌曲数（含当前歌曲）
+	Volume   int       `json:"volume,omitempty"`   // 淡出前的音量，淡出中途退出时用于恢复
+}
+
+func (t SleepTimer) GetDbName() string {
+	return types.AppDBName
+}
+
+func (t SleepTimer) GetTableName() string {
+	return "default_bucket"
+}
+
+func (t SleepTimer) GetKey() string {
+	return "sleep_timer"
+}
+
+// AlarmsFired 每个闹钟最近一次触发的日期（2006-01-02）
+type AlarmsFired map[string]string
+
+func (a AlarmsFired) GetDbName() string {
+	return types.AppDBName
+}
+
+func (a AlarmsFired) GetTableName() string {
+	return "default_bucket"
+}
+
+func (a AlarmsFired) GetKey() string {
+	return "alarms_fired"
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+// UpNextQueue 待播队列，重启后恢复
+type UpNextQueue struct {
+	Playing *structs.Song  `json:"playing,omitempty"` // 正在播放的队列歌曲，播放的是播放列表中的歌曲时为空
+	Songs   []structs.Song `json:"songs"`
+}
+
+func (q UpNextQueue) GetDbName() string {
+	return types.AppDBName
+}
+
+func (q UpNextQueue) GetTableName() string {
+	return "default_bucket"
+}
+
+func (q UpNextQueue) GetKey() string {
+	return "up_next_queue"
+}
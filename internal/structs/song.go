@@ -15,9 +15,15 @@ type Song struct {
 	Duration         time.Duration `json:"duration"`
 	Artists          []Artist      `json:"artists"`
 	Album            `json:"album"`
-	DjRadioEpisodeId int64   `json:"djRadioEpisodeId"` // 若为播客，则非 0
-	DjRadio          DjRadio `json:"djRadio"`          // 播客，电台使用
-	UnMatched        bool    `json:"unMatched"`        // 云盘内资源匹配状态
+	DjRadioEpisodeId int64   `json:"djRadioEpisodeId"`    // 若为播客，则非 0
+	DjRadio          DjRadio `json:"djRadio"`             // 播客，电台使用
+	UnMatched        bool    `json:"unMatched"`           // 云盘内资源匹配状态
+	LocalPath        string  `json:"localPath,omitempty"` // 本地音乐文件路径，若为本地音乐则非空
+}
+
+// IsLocal 是否为本地音乐库中的歌曲
+func (s Song) IsLocal() bool {
+	return s.LocalPath != ""
 }
 
 func (s Song) ArtistName() string {
@@ -0,0 +1,140 @@
+package track
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/composer"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/utils/netease"
+)
+
+// remoteFetcherStub 使用真实的 HTTP 下载，仅替换播放链接的获取
+type remoteFetcherStub struct {
+	*fetcher
+	info netease.PlayableInfo
+}
+
+func (f *remoteFetcherStub) FetchPlayableInfo(context.Context, int64) (*netease.PlayableInfo, error) {
+	info := f.info
+	return &info, nil
+}
+
+func newRangeServer(t *testing.T, content []byte, ranges *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*ranges = append(*ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "song.mp3", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newResumeTestManager(t *testing.T, url string, size int64) *Manager {
+	t.Helper()
+	return &Manager{
+		fetcher: &remoteFetcherStub{
+			fetcher: NewFetcher().(*fetcher),
+			info:    netease.PlayableInfo{URL: url, MusicType: "mp3", Size: size},
+		},
+		tagger:      noopTagger{},
+		cacher:      &Cacher{musicDir: t.TempDir()},
+		nameGen:     composer.NewFileNameGenerator(),
+		downloadDir: t.TempDir(),
+	}
+}
+
+func TestDownloadResumesPartialFile(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 100))
+	var ranges []string
+	server := newRangeServer(t, content, &ranges)
+	manager := newResumeTestManager(t, server.URL, int64(len(content)))
+	song := structs.Song{Id: 1, Name: "song"}
+
+	fileName, _ := manager.nameGen.Song(song, "mp3")
+	finalPath := filepath.Join(manager.downloadDir, fileName)
+	if err := os.WriteFile(finalPath+".part", content[:400], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var reports [][2]int64
+	path, err := manager.DownloadSongWithProgress(context.Background(), song, func(downloaded, total int64) {
+		reports = append(reports, [2]int64{downloaded, total})
+	})
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	if path != finalPath {
+		t.Fatalf("path = %q, want %q", path, finalPath)
+	}
+	got, _ := os.ReadFile(path)
+	if !bytes.Equal(got, content) {
+		t.Fatalf("downloaded %d bytes, content mismatch", len(got))
+	}
+	if _, err = os.Stat(finalPath + ".part"); !os.IsNotExist(err) {
+		t.Fatalf("partial file should be renamed, stat err = %v", err)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=400-" {
+		t.Fatalf("range headers = %q, want [bytes=400-]", ranges)
+	}
+	if first, last := reports[0], reports[len(reports)-1]; first != [2]int64{400, 1000} || last != [2]int64{1000, 1000} {
+		t.Fatalf("progress = %v ... %v", first, last)
+	}
+}
+
+func TestDownloadRestartsWhenPartialBelongsToAnotherFile(t *testing.T) {
+	content := []byte(strings.Repeat("abcdefghij", 50))
+	var ranges []string
+	server := newRangeServer(t, content, &ranges)
+	// 期望大小与服务端不一致，说明 .part 来自其他音质的文件
+	manager := newResumeTestManager(t, server.URL, 999)
+	song := structs.Song{Id: 2, Name: "song"}
+
+	fileName, _ := manager.nameGen.Song(song, "mp3")
+	partPath := filepath.Join(manager.downloadDir, fileName) + ".part"
+	if err := os.WriteFile(partPath, []byte("stale data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := manager.DownloadSong(context.Background(), song)
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	got, _ := os.ReadFile(path)
+	if !bytes.Equal(got, content) {
+		t.Fatalf("content mismatch: %q", got[:20])
+	}
+	if len(ranges) != 2 || ranges[1] != "" {
+		t.Fatalf("range headers = %q, want a resume attempt then a full download", ranges)
+	}
+}
+
+func TestDownloadFillsPlayCache(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 100))
+	var ranges []string
+	server := newRangeServer(t, content, &ranges)
+	manager := newResumeTestManager(t, server.URL, int64(len(content)))
+	manager.cacher.maxSize = -1
+	song := structs.Song{Id: 3, Name: "song"}
+
+	if _, err := manager.DownloadSong(context.Background(), song); err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	stream, fileType, err := manager.cacher.Get(song.Id, manager.quality)
+	if err != nil {
+		t.Fatalf("song should be cached after download: %v", err)
+	}
+	defer stream.Close()
+	got, _ := io.ReadAll(stream)
+	if fileType != "mp3" || !bytes.Equal(got, content) {
+		t.Fatalf("cached %s with %d bytes, want mp3 with the downloaded content", fileType, len(got))
+	}
+}
@@ -20,6 +20,21 @@ type Fetcher interface {
 	FetchCloudLyric(ctx context.Context, userID, songID int64) (structs.LRCData, error)
 }
 
+// RangeStream 从 Offset 处开始的音频流，Total 为完整文件大小，未知时为 -1
+type RangeStream struct {
+	io.ReadCloser
+	Offset int64
+	Total  int64
+}
+
+// RangeFetcher 支持断点续传的 Fetcher
+type RangeFetcher interface {
+	FetchStreamFrom(ctx context.Context, source PlayableSource, offset int64) (*RangeStream, error)
+}
+
+// ErrRangeNotSatisfiable 请求的续传位置超出文件大小
+var ErrRangeNotSatisfiable = errors.New("requested range not satisfiable")
+
 type fetcher struct {
 	httpClient *http.Client
 	quality    service.SongQualityLevel
@@ -61,20 +76,45 @@ func (f *fetcher) FetchPlayableInfo(ctx context.Context, songID int64) (*netease
 }
 
 func (f *fetcher) FetchStream(ctx context.Context, source PlayableSource) (io.ReadCloser, error) {
+	stream, err := f.FetchStreamFrom(ctx, source, 0)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// FetchStreamFrom 通过 HTTP Range 从 offset 处继续获取音频流。
+// 服务端不支持 Range 时返回完整的流，此时 RangeStream.Offset 为 0。
+func (f *fetcher) FetchStreamFrom(ctx context.Context, source PlayableSource, offset int64) (*RangeStream, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.Info.URL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create http request for song %d: %w", source.Id, err)
 	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
 
 	resp, err := f.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("http get failed for song %d (%s): %w", source.Id, source.Info.URL, err)
 	}
-	if resp.StatusCode != http.StatusOK {
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return &RangeStream{ReadCloser: resp.Body, Total: resp.ContentLength}, nil
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		total := int64(-1)
+		if resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
+		}
+		return &RangeStream{ReadCloser: resp.Body, Offset: offset, Total: total}, nil
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+		return nil, ErrRangeNotSatisfiable
+	default:
 		resp.Body.Close()
 		return nil, fmt.Errorf("invalid http status for song %d: %s", source.Id, resp.Status)
 	}
-	return resp.Body, nil
 }
 
 func (f *fetcher) FetchLyric(ctx context.Context, songID int64) (structs.LRCData, error) {
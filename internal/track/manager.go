@@ -8,10 +8,10 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 
 	"github.com/go-musicfox/netease-music/service"
-	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/singleflight"
 
 	"github.com/go-musicfox/go-musicfox/internal/composer"
@@ -20,14 +20,15 @@ import (
 	"github.com/go-musicfox/go-musicfox/utils/netease"
 )
 
-var supportedFileExtensions = []string{"mp3", "flac"}
+var supportedFileExtensions = []string{"mp3", "flac", "m4a", "aac", "opus"}
 
 type persistJob struct {
 	ctx           context.Context
 	stream        io.ReadCloser
 	finalFilePath string
 	source        PlayableSource
-	isFromCache   bool
+	progress      ProgressFunc
+	total         int64
 }
 
 // Manager 是 songmanager 包的统一入口和协调器。
@@ -42,8 +43,13 @@ type Manager struct {
 	quality     service.SongQualityLevel
 	sfGroup     singleflight.Group
 	cloudUserID atomic.Int64
+	offline     *OfflineStore
+	offlineMode atomic.Bool
 }
 
+// ErrOffline 离线模式下歌曲没有本地音源
+var ErrOffline = errors.New("song is not available offline")
+
 // ManagerOption 是用于配置 Manager 的函数类型。
 type ManagerOption func(*Manager)
 
@@ -71,6 +77,9 @@ func NewManager(opts ...ManagerOption) *Manager {
 	if m.nameGen == nil {
 		m.nameGen = composer.NewFileNameGenerator()
 	}
+	if m.offline == nil {
+		m.offline = NewOfflineStore(app.OfflineDir())
+	}
 
 	return m
 }
@@ -134,9 +143,31 @@ func WithDownloadLyricDir(dir string) ManagerOption {
 	}
 }
 
+// WithOfflineStore 是一个配置选项，用于提供一个自定义的离线存储。
+func WithOfflineStore(store *OfflineStore) ManagerOption {
+	return func(m *Manager) {
+		m.offline = store
+	}
+}
+
+// SetOfflineMode 开启或关闭离线模式，开启后只使用本地音源和歌词，不再访问网络。
+func (m *Manager) SetOfflineMode(offline bool) {
+	m.offlineMode.Store(offline)
+}
+
+// OfflineMode 是否处于离线模式
+func (m *Manager) OfflineMode() bool {
+	return m.offlineMode.Load()
+}
+
 // ResolvePlayableSource 是 Manager 最核心的公共方法。
-// 它解析一首歌的最佳可播放源，查找顺序: 已下载文件 -> 缓存文件 -> 远程网络。
+// 它解析一首歌的最佳可播放源，查找顺序: 离线存储 -> 已下载文件 -> 缓存文件 -> 远程网络。
+// 离线模式下本地均未命中时返回 ErrOffline。
 func (m *Manager) ResolvePlayableSource(ctx context.Context, song structs.Song) (PlayableSource, error) {
+	if song.IsLocal() {
+		return resolveLocalSource(song)
+	}
+
 	source, err := m.resolveSongSource(ctx, song)
 	if err != nil {
 		return PlayableSource{}, err
@@ -149,11 +180,23 @@ func (m *Manager) ResolvePlayableSource(ctx context.Context, song structs.Song)
 	return source, nil
 }
 
+// ProgressFunc 下载进度回调，total 未知时为 -1
+type ProgressFunc func(downloaded, total int64)
+
 // DownloadSong 下载一首歌并返回其本地路径。
 func (m *Manager) DownloadSong(ctx context.Context, song structs.Song) (string, error) {
+	return m.DownloadSongWithProgress(ctx, song, nil)
+}
+
+// DownloadSongWithProgress 下载一首歌并通过 progress 报告已写入的字节数。
+// 从网络下载时未完成的部分保存为 .part 文件，再次下载时通过 HTTP Range 续传。
+func (m *Manager) DownloadSongWithProgress(ctx context.Context, song structs.Song, progress ProgressFunc) (string, error) {
 	if song.Id == 0 {
 		return "", fmt.Errorf("Song does not exist, id = 0")
 	}
+	if song.IsLocal() {
+		return song.LocalPath, os.ErrExist
+	}
 	key := fmt.Sprintf("song-download-%d", song.Id)
 	result, err, _ := m.sfGroup.Do(key, func() (any, error) {
 		source, err := m.resolveSongSource(ctx, song)
@@ -164,12 +207,12 @@ func (m *Manager) DownloadSong(ctx context.Context, song structs.Song) (string,
 		switch source.Type {
 		case SourceDownloaded:
 			return source.Path, os.ErrExist
-		case SourceCached:
-			slog.Debug("Persisting song from cache to downloads", "songId", song.Id)
-			return m.persistCachedSource(ctx, source)
+		case SourceCached, SourceOffline:
+			slog.Debug("Persisting song from local source to downloads", "songId", song.Id, "type", source.Type)
+			return m.persistCachedSource(ctx, source, progress)
 		case SourceRemote:
 			slog.Debug("Persisting song from remote to downloads", "songId", song.Id)
-			return m.persistRemoteSource(ctx, source)
+			return m.persistRemoteSource(ctx, source, progress)
 		}
 		return "", fmt.Errorf("unknown source type encountered for song %d", song.Id)
 	})
@@ -185,6 +228,9 @@ func (m *Manager) DownloadLyric(ctx context.Context, song structs.Song) (string,
 	if song.Id == 0 {
 		return "", errors.New("Song does not exist, id = 0")
 	}
+	if song.IsLocal() {
+		return "", errors.New("lyric download is not supported for local songs")
+	}
 	key := fmt.Sprintf("lyric-download-%d", song.Id)
 	result, err, _ := m.sfGroup.Do(key, func() (any, error) {
 		fileName, err := m.nameGen.Lyric(song, "lrc")
@@ -219,6 +265,21 @@ func (m *Manager) DownloadLyric(ctx context.Context, song structs.Song) (string,
 
 // GetLyric 获取一首歌的歌词。
 func (m *Manager) GetLyric(ctx context.Context, song structs.Song) (structs.LRCData, error) {
+	if song.IsLocal() {
+		return readLocalLyric(song)
+	}
+	if m.offline != nil {
+		lrc, err := m.offline.Lyric(song.Id)
+		if err == nil {
+			return lrc, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("Failed to read offline lyric", "songId", song.Id, "error", err)
+		}
+	}
+	if m.OfflineMode() {
+		return structs.LRCData{}, ErrOffline
+	}
 	cloudUserID := m.cloudUserID.Load()
 	preferCloudLyric := shouldPreferCloudLyric(song)
 	key := fmt.Sprintf("lyric-fetch-%d-%d-%t", cloudUserID, song.Id, preferCloudLyric)
@@ -245,6 +306,35 @@ func (m *Manager) GetLyric(ctx context.Context, song structs.Song) (structs.LRCD
 	return result.(structs.LRCData), nil
 }
 
+// resolveLocalSource 本地音乐库中的歌曲直接使用文件路径播放。
+func resolveLocalSource(song structs.Song) (PlayableSource, error) {
+	if _, err := os.Stat(song.LocalPath); err != nil {
+		return PlayableSource{}, fmt.Errorf("local song unavailable: %w", err)
+	}
+	return PlayableSource{
+		Song: song,
+		Type: SourceLocal,
+		Path: song.LocalPath,
+		Info: &netease.PlayableInfo{
+			URL:       "file://" + song.LocalPath,
+			MusicType: strings.ToLower(strings.TrimPrefix(filepath.Ext(song.LocalPath), ".")),
+		},
+	}, nil
+}
+
+// readLocalLyric 读取与本地歌曲同名的 .lrc 文件，不存在时返回空歌词。
+func readLocalLyric(song structs.Song) (structs.LRCData, error) {
+	lrcPath := strings.TrimSuffix(song.LocalPath, filepath.Ext(song.LocalPath)) + ".lrc"
+	data, err := os.ReadFile(lrcPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return structs.LRCData{}, nil
+		}
+		return structs.LRCData{}, err
+	}
+	return structs.LRCData{Original: string(data)}, nil
+}
+
 func shouldPreferCloudLyric(song structs.Song) bool {
 	if song.UnMatched {
 		return true
@@ -271,51 +361,21 @@ func (m *Manager) ClearCache() error {
 	return m.cacher.Clear()
 }
 
-// resolveSongSource 严格按 已下载 -> 缓存 -> 网络的顺序解析音源。
+// resolveSongSource 严格按 离线存储 -> 已下载 -> 缓存 -> 网络的顺序解析音源。
 func (m *Manager) resolveSongSource(ctx context.Context, song structs.Song) (PlayableSource, error) {
 	key := fmt.Sprintf("song-resolve-%d", song.Id)
 	result, err, _ := m.sfGroup.Do(key, func() (any, error) {
-		// 检查下载目录
-		for _, ext := range supportedFileExtensions {
-			fileName, err := m.nameGen.Song(song, ext)
-			if err != nil {
-				slog.Warn("Failed to generate potential filename", "songId", song.Id, "ext", ext, "error", err)
-				continue
-			}
-			finalFilePath := filepath.Join(m.downloadDir, fileName)
-			if _, err := os.Stat(finalFilePath); err == nil {
-				slog.Debug("Resolved source: Downloaded", "songId", song.Id)
-				return PlayableSource{
-					Song: song,
-					Type: SourceDownloaded,
-					Path: finalFilePath,
-					Info: &netease.PlayableInfo{
-						URL:       "file://" + finalFilePath,
-						MusicType: ext,
-					},
-				}, nil
-			}
+		source, ok, err := m.resolveLocalCopy(song)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return source, nil
 		}
 
-		// 检查缓存
-		if !m.cacher.IsDisabled() {
-			cachePath, fileType, cacheErr := m.cacher.GetPath(song.Id, m.quality)
-			if cacheErr == nil {
-				slog.Debug("Resolved source: Cached", "songId", song.Id)
-				return PlayableSource{
-					Song: song,
-					Type: SourceCached,
-					Path: cachePath,
-					Info: &netease.PlayableInfo{
-						URL:       "file://" + cachePath,
-						MusicType: fileType,
-					},
-				}, nil
-			}
-			if !errors.Is(cacheErr, os.ErrNotExist) {
-				slog.Error("Cache system error during source resolution", "songId", song.Id, "error", cacheErr)
-				return nil, cacheErr
-			}
+		if m.OfflineMode() {
+			slog.Debug("Local sources miss in offline mode", "songId", song.Id)
+			return nil, ErrOffline
 		}
 
 		// 从网络获取
@@ -338,6 +398,57 @@ func (m *Manager) resolveSongSource(ctx context.Context, song structs.Song) (Pla
 	return result.(PlayableSource), nil
 }
 
+// resolveLocalCopy 依次查找离线存储、下载目录和缓存，均未命中时 ok 为 false。
+func (m *Manager) resolveLocalCopy(song structs.Song) (source PlayableSource, ok bool, err error) {
+	fileSource := func(typ SourceType, path, ext string) PlayableSource {
+		return PlayableSource{
+			Song: song,
+			Type: typ,
+			Path: path,
+			Info: &netease.PlayableInfo{
+				URL:       "file://" + path,
+				MusicType: ext,
+			},
+		}
+	}
+
+	// 检查离线存储
+	if m.offline != nil {
+		if path, ext, err := m.offline.SongPath(song.Id); err == nil {
+			slog.Debug("Resolved source: Offline", "songId", song.Id)
+			return fileSource(SourceOffline, path, ext), true, nil
+		}
+	}
+
+	// 检查下载目录
+	for _, ext := range supportedFileExtensions {
+		fileName, err := m.nameGen.Song(song, ext)
+		if err != nil {
+			slog.Warn("Failed to generate potential filename", "songId", song.Id, "ext", ext, "error", err)
+			continue
+		}
+		finalFilePath := filepath.Join(m.downloadDir, fileName)
+		if _, err := os.Stat(finalFilePath); err == nil {
+			slog.Debug("Resolved source: Downloaded", "songId", song.Id)
+			return fileSource(SourceDownloaded, finalFilePath, ext), true, nil
+		}
+	}
+
+	// 检查缓存
+	if !m.cacher.IsDisabled() {
+		cachePath, fileType, cacheErr := m.cacher.GetPath(song.Id, m.quality)
+		if cacheErr == nil {
+			slog.Debug("Resolved source: Cached", "songId", song.Id)
+			return fileSource(SourceCached, cachePath, fileType), true, nil
+		}
+		if !errors.Is(cacheErr, os.ErrNotExist) {
+			slog.Error("Cache system error during source resolution", "songId", song.Id, "error", cacheErr)
+			return PlayableSource{}, false, cacheErr
+		}
+	}
+	return PlayableSource{}, false, nil
+}
+
 func (m *Manager) backgroundCache(ctx context.Context, source PlayableSource) {
 	cacheKey := fmt.Sprintf("song-cache-%d", source.Id)
 	m.sfGroup.Do(cacheKey, func() (any, error) {
@@ -359,24 +470,37 @@ func (m *Manager) backgroundCache(ctx context.Context, source PlayableSource) {
 	})
 }
 
-func (m *Manager) persistCachedSource(ctx context.Context, source PlayableSource) (string, error) {
+func (m *Manager) persistCachedSource(ctx context.Context, source PlayableSource, progress ProgressFunc) (string, error) {
 	if err := m.ensureDirExists(m.downloadDir); err != nil {
 		return "", err
 	}
 	fileName, _ := m.nameGen.Song(source.Song, source.Info.MusicType)
 	finalFilePath := filepath.Join(m.downloadDir, fileName)
 
-	stream, _, err := m.cacher.Get(source.Id, m.quality)
+	var (
+		stream io.ReadCloser
+		err    error
+	)
+	if source.Type == SourceOffline {
+		stream, err = os.Open(source.Path)
+	} else {
+		stream, _, err = m.cacher.Get(source.Id, m.quality)
+	}
 	if err != nil {
 		return "", err
 	}
 
+	total := int64(-1)
+	if info, statErr := os.Stat(source.Path); statErr == nil {
+		total = info.Size()
+	}
 	job := persistJob{
 		ctx:           ctx,
 		stream:        stream,
 		finalFilePath: finalFilePath,
 		source:        source,
-		isFromCache:   true,
+		progress:      progress,
+		total:         total,
 	}
 	if err := m.persistStream(job); err != nil {
 		return "", err
@@ -387,34 +511,109 @@ func (m *Manager) persistCachedSource(ctx context.Context, source PlayableSource
 	return finalFilePath, nil
 }
 
-func (m *Manager) persistRemoteSource(ctx context.Context, source PlayableSource) (string, error) {
+// persistRemoteSource 下载到 <文件名>.part，完成后重命名。
+// 若 .part 已存在则从其末尾续传；服务端不支持 Range 或文件大小与预期不符时重新下载。
+func (m *Manager) persistRemoteSource(ctx context.Context, source PlayableSource, progress ProgressFunc) (string, error) {
 	if err := m.ensureDirExists(m.downloadDir); err != nil {
 		return "", err
 	}
-	stream, err := m.fetcher.FetchStream(ctx, source)
+	fileName, _ := m.nameGen.Song(source.Song, source.Info.MusicType)
+	filePath := filepath.Join(m.downloadDir, fileName)
+	partPath := filePath + ".part"
+
+	stream, err := m.fetchPartial(ctx, source, partPath)
 	if err != nil {
 		return "", err
 	}
+	defer stream.Close()
 
-	fileName, _ := m.nameGen.Song(source.Song, source.Info.MusicType)
-	filePath := filepath.Join(m.downloadDir, fileName)
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if stream.Offset == 0 {
+		flags |= os.O_TRUNC
+	}
+	partFile, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to open partial file: %w", err)
+	}
 
-	job := persistJob{
-		ctx:           ctx,
-		stream:        stream,
-		finalFilePath: filePath,
-		source:        source,
-		isFromCache:   false,
+	writer := &progressWriter{writer: partFile, written: stream.Offset, total: stream.Total, progress: progress}
+	writer.report()
+	_, copyErr := io.Copy(writer, contextReader{ctx: ctx, reader: stream})
+	if err = errors.Join(copyErr, partFile.Close()); err != nil {
+		// 保留 .part 以便下次续传
+		return "", err
 	}
-	if err := m.persistStream(job); err != nil {
+	if stream.Total >= 0 && writer.written != stream.Total {
+		return "", fmt.Errorf("incomplete download for song %d: %d of %d bytes", source.Id, writer.written, stream.Total)
+	}
+
+	if err = os.Rename(partPath, filePath); err != nil {
 		return "", err
 	}
+	// 写入标签前填充播放缓存，与播放时缓存的原始文件一致
+	m.cacheDownloadedFile(source, filePath)
 	if err := m.tagger.SetSongTag(filePath, source.Song); err != nil {
 		slog.Warn("Song downloaded, but failed to set metadata.", "file", filePath, "error", err)
 	}
 	return filePath, nil
 }
 
+// cacheDownloadedFile 将下载完成的文件复制到播放缓存，失败不影响下载结果
+func (m *Manager) cacheDownloadedFile(source PlayableSource, filePath string) {
+	if m.cacher.IsDisabled() {
+		return
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		slog.Warn("Download cache: open file failed", "songId", source.Id, "error", err)
+		return
+	}
+	defer file.Close()
+	if err = m.cacher.Put(source.Song, m.quality, source.Info.MusicType, file); err != nil {
+		slog.Warn("Download cache: put failed", "songId", source.Id, "error", err)
+	}
+}
+
+// fetchPartial 根据已下载的 .part 文件大小决定是否续传
+func (m *Manager) fetchPartial(ctx context.Context, source PlayableSource, partPath string) (*RangeStream, error) {
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	rangeFetcher, ok := m.fetcher.(RangeFetcher)
+	if !ok {
+		stream, err := m.fetcher.FetchStream(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		return &RangeStream{ReadCloser: stream, Total: -1}, nil
+	}
+
+	if offset > 0 && source.Info.Size > 0 && offset >= source.Info.Size {
+		offset = 0
+	}
+	stream, err := rangeFetcher.FetchStreamFrom(ctx, source, offset)
+	if errors.Is(err, ErrRangeNotSatisfiable) && offset > 0 {
+		slog.Debug("Partial file is not resumable, restarting download", "songId", source.Id)
+		return rangeFetcher.FetchStreamFrom(ctx, source, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 音质或链接变化后 .part 属于另一个文件，不能续传
+	if stream.Offset > 0 && source.Info.Size > 0 && stream.Total >= 0 && stream.Total != source.Info.Size {
+		slog.Debug("Partial file size mismatch, restarting download", "songId", source.Id,
+			"expected", source.Info.Size, "total", stream.Total)
+		stream.Close()
+		return rangeFetcher.FetchStreamFrom(ctx, source, 0)
+	}
+	if stream.Offset > 0 {
+		slog.Debug("Resuming download", "songId", source.Id, "offset", stream.Offset)
+	}
+	return stream, nil
+}
+
 func (m *Manager) persistStream(job persistJob) error {
 	defer job.stream.Close()
 
@@ -424,42 +623,49 @@ func (m *Manager) persistStream(job persistJob) error {
 	}
 	defer os.Remove(tempFile.Name())
 
-	var (
-		writer     io.Writer = tempFile
-		eg         errgroup.Group
-		pipeWriter *io.PipeWriter
-	)
+	writer := &progressWriter{writer: tempFile, total: job.total, progress: job.progress}
+	_, copyErr := io.Copy(writer, contextReader{ctx: job.ctx, reader: job.stream})
+	closeErr := tempFile.Close()
 
-	if !job.isFromCache && !m.cacher.IsDisabled() {
-		var pipeReader *io.PipeReader
-		pipeReader, pipeWriter = io.Pipe()
+	if finalErr := errors.Join(copyErr, closeErr); finalErr != nil {
+		return finalErr
+	}
 
-		eg.Go(func() error {
-			defer pipeReader.Close()
-			return m.cacher.Put(job.source.Song, m.quality, job.source.Info.MusicType, pipeReader)
-		})
+	return os.Rename(tempFile.Name(), job.finalFilePath)
+}
 
-		writer = io.MultiWriter(tempFile, pipeWriter)
-	}
+// progressWriter 统计写入的字节数并回调进度
+type progressWriter struct {
+	writer   io.Writer
+	written  int64
+	total    int64
+	progress ProgressFunc
+}
 
-	_, copyErr := io.Copy(writer, job.stream)
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	w.report()
+	return n, err
+}
 
-	if pipeWriter != nil {
-		if copyErr != nil {
-			pipeWriter.CloseWithError(copyErr)
-		} else {
-			pipeWriter.Close()
-		}
+func (w *progressWriter) report() {
+	if w.progress != nil {
+		w.progress(w.written, w.total)
 	}
+}
 
-	waitErr := eg.Wait()
-	closeErr := tempFile.Close()
+// contextReader 在 ctx 取消后停止读取
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
 
-	if finalErr := errors.Join(copyErr, waitErr, closeErr); finalErr != nil {
-		return finalErr
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
 	}
-
-	return os.Rename(tempFile.Name(), job.finalFilePath)
+	return r.reader.Read(p)
 }
 
 func (m *Manager) ensureDirExists(dir string) error {
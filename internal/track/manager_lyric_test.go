@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -154,3 +156,40 @@ func TestGetLyricKeepsRegularLyricsForMatchedCloudSong(t *testing.T) {
 		t.Fatalf("fetch calls = (cloud %d, regular %d), want (0, 1)", fetcher.cloudCalls, fetcher.regularCalls)
 	}
 }
+
+func TestGetLyricReadsSidecarFileForLocalSong(t *testing.T) {
+	dir := t.TempDir()
+	songPath := filepath.Join(dir, "song.flac")
+	if err := os.WriteFile(filepath.Join(dir, "song.lrc"), []byte("[00:00.00]local"), 0644); err != nil {
+		t.Fatalf("write lrc: %v", err)
+	}
+	fetcher := &lyricFetcherStub{regular: structs.LRCData{Original: "[00:00.00]regular"}}
+	manager := &Manager{fetcher: fetcher}
+
+	got, err := manager.GetLyric(context.Background(), structs.Song{Id: -1, LocalPath: songPath})
+	if err != nil {
+		t.Fatalf("get lyric: %v", err)
+	}
+	if got.Original != "[00:00.00]local" {
+		t.Fatalf("original lyric = %q, want sidecar lyric", got.Original)
+	}
+	if fetcher.regularCalls != 0 || fetcher.cloudCalls != 0 {
+		t.Fatalf("local song should not fetch remote lyric")
+	}
+}
+
+func TestResolvePlayableSourceUsesLocalPath(t *testing.T) {
+	songPath := filepath.Join(t.TempDir(), "song.ogg")
+	if err := os.WriteFile(songPath, []byte("ogg"), 0644); err != nil {
+		t.Fatalf("write song: %v", err)
+	}
+	manager := &Manager{}
+
+	source, err := manager.ResolvePlayableSource(context.Background(), structs.Song{Id: -1, LocalPath: songPath})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if source.Type != SourceLocal || source.Info.URL != "file://"+songPath || source.Info.MusicType != "ogg" {
+		t.Fatalf("source = %+v (info %+v), want local ogg file", source, source.Info)
+	}
+}
@@ -0,0 +1,159 @@
+package track
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/utils/app"
+)
+
+// coverTimeout 下载离线封面的超时时间
+const coverTimeout = 30 * time.Second
+
+// IsAvailableOffline 判断歌曲在不访问网络的情况下是否可以播放。
+func (m *Manager) IsAvailableOffline(song structs.Song) bool {
+	if song.IsLocal() {
+		_, err := os.Stat(song.LocalPath)
+		return err == nil
+	}
+	_, ok, err := m.resolveLocalCopy(song)
+	return ok && err == nil
+}
+
+// IsPinned 判断歌曲音频是否已保存到离线存储
+func (m *Manager) IsPinned(songID int64) bool {
+	return m.offline != nil && m.offline.Has(songID)
+}
+
+// OfflineCoverPath 返回已离线保存的封面路径，不存在时返回空字符串。
+func (m *Manager) OfflineCoverPath(songID int64) string {
+	if m.offline == nil {
+		return ""
+	}
+	path := m.offline.CoverPath(songID)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// PinSong 将一首歌的音频、歌词和封面保存到离线存储，已保存的部分会被跳过。
+// 音频优先从下载目录或缓存复制，均未命中时从网络下载。
+func (m *Manager) PinSong(ctx context.Context, song structs.Song) error {
+	if song.Id == 0 || song.IsLocal() {
+		return fmt.Errorf("song %q cannot be pinned", song.Name)
+	}
+	if m.offline == nil {
+		return errors.New("offline store is not configured")
+	}
+	key := fmt.Sprintf("song-pin-%d", song.Id)
+	_, err, _ := m.sfGroup.Do(key, func() (any, error) {
+		if !m.offline.Has(song.Id) {
+			path, err := m.pinAudio(ctx, song)
+			if err != nil {
+				return nil, err
+			}
+			if err = m.tagger.SetSongTag(path, song); err != nil {
+				slog.Warn("Song pinned, but failed to set metadata.", "file", path, "error", err)
+			}
+		}
+
+		if _, err := m.offline.Lyric(song.Id); err != nil {
+			if err = m.pinLyric(ctx, song); err != nil {
+				slog.Warn("Failed to pin lyric", "songId", song.Id, "error", err)
+			}
+		}
+
+		if song.PicUrl != "" && m.OfflineCoverPath(song.Id) == "" {
+			if err := m.pinCover(ctx, song); err != nil {
+				slog.Warn("Failed to pin cover", "songId", song.Id, "error", err)
+			}
+		}
+		return nil, nil
+	})
+	return err
+}
+
+// UnpinSong 删除一首歌的离线文件
+func (m *Manager) UnpinSong(songID int64) error {
+	if m.offline == nil {
+		return nil
+	}
+	return m.offline.Remove(songID)
+}
+
+func (m *Manager) pinAudio(ctx context.Context, song structs.Song) (string, error) {
+	source, ok, err := m.resolveLocalCopy(song)
+	if err != nil {
+		return "", err
+	}
+
+	var stream io.ReadCloser
+	if ok {
+		slog.Debug("Pinning song from local source", "songId", song.Id, "type", source.Type)
+		if stream, err = os.Open(source.Path); err != nil {
+			return "", err
+		}
+	} else {
+		if m.OfflineMode() {
+			return "", ErrOffline
+		}
+		slog.Debug("Pinning song from remote", "songId", song.Id)
+		info, err := m.fetcher.FetchPlayableInfo(ctx, song.Id)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch playable info: %w", err)
+		}
+		source = PlayableSource{Song: song, Type: SourceRemote, Info: info}
+		if stream, err = m.fetcher.FetchStream(ctx, source); err != nil {
+			return "", err
+		}
+	}
+	defer stream.Close()
+
+	return m.offline.PutSong(song.Id, source.Info.MusicType, stream)
+}
+
+func (m *Manager) pinLyric(ctx context.Context, song structs.Song) error {
+	if m.OfflineMode() {
+		return ErrOffline
+	}
+	lrc, err := m.GetLyric(ctx, song)
+	if err != nil {
+		return err
+	}
+	return m.offline.PutLyric(song.Id, lrc)
+}
+
+func (m *Manager) pinCover(ctx context.Context, song structs.Song) error {
+	if m.OfflineMode() {
+		return ErrOffline
+	}
+	ctx, cancel := context.WithTimeout(ctx, coverTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, app.AddResizeParamForPicUrl(song.PicUrl, 1024), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad status getting cover: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return m.offline.PutCover(song.Id, data)
+}
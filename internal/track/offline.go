@@ -0,0 +1,143 @@
+package track
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+// OfflineStore 保存已固定（离线）歌曲的音频、歌词与封面。
+// 目录独立于缓存目录，Cacher 的容量清理不会删除其中的文件。
+//
+// 文件布局: <id>.<ext> 音频，<id>.lyric.json 歌词，<id>.jpg 封面。
+type OfflineStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// NewOfflineStore 创建以 dir 为根目录的离线存储。
+func NewOfflineStore(dir string) *OfflineStore {
+	return &OfflineStore{dir: dir}
+}
+
+// Dir 离线存储的根目录
+func (s *OfflineStore) Dir() string {
+	return s.dir
+}
+
+// SongPath 返回离线音频文件的路径及格式，不存在时返回 os.ErrNotExist。
+func (s *OfflineStore) SongPath(songID int64) (string, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, ext := range supportedFileExtensions {
+		path := filepath.Join(s.dir, fmt.Sprintf("%d.%s", songID, ext))
+		if _, err := os.Stat(path); err == nil {
+			return path, ext, nil
+		}
+	}
+	return "", "", os.ErrNotExist
+}
+
+// Has 判断歌曲音频是否已离线保存
+func (s *OfflineStore) Has(songID int64) bool {
+	_, _, err := s.SongPath(songID)
+	return err == nil
+}
+
+// PutSong 将音频数据写入离线存储并返回文件路径。
+func (s *OfflineStore) PutSong(songID int64, fileType string, data io.Reader) (string, error) {
+	path := filepath.Join(s.dir, fmt.Sprintf("%d.%s", songID, fileType))
+	if err := s.writeFile(path, data); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// PutLyric 保存歌曲歌词
+func (s *OfflineStore) PutLyric(songID int64, lrc structs.LRCData) error {
+	data, err := json.Marshal(lrc)
+	if err != nil {
+		return err
+	}
+	return s.writeBytes(s.lyricPath(songID), data)
+}
+
+// Lyric 读取离线歌词，不存在时返回 os.ErrNotExist。
+func (s *OfflineStore) Lyric(songID int64) (structs.LRCData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var lrc structs.LRCData
+	data, err := os.ReadFile(s.lyricPath(songID))
+	if err != nil {
+		return lrc, err
+	}
+	err = json.Unmarshal(data, &lrc)
+	return lrc, err
+}
+
+// PutCover 保存歌曲封面
+func (s *OfflineStore) PutCover(songID int64, data []byte) error {
+	return s.writeBytes(s.CoverPath(songID), data)
+}
+
+// CoverPath 离线封面文件路径（文件不一定存在）
+func (s *OfflineStore) CoverPath(songID int64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%d.jpg", songID))
+}
+
+// Remove 删除一首歌的全部离线文件
+func (s *OfflineStore) Remove(songID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths := []string{s.lyricPath(songID), s.CoverPath(songID)}
+	for _, ext := range supportedFileExtensions {
+		paths = append(paths, filepath.Join(s.dir, fmt.Sprintf("%d.%s", songID, ext)))
+	}
+	var errs []error
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *OfflineStore) lyricPath(songID int64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%d.lyric.json", songID))
+}
+
+func (s *OfflineStore) writeBytes(path string, data []byte) error {
+	return s.writeFile(path, bytes.NewReader(data))
+}
+
+// writeFile 先写入临时文件再重命名，避免中断时留下不完整的文件。
+// 下载期间不持有锁，仅在重命名时加锁。
+func (s *OfflineStore) writeFile(path string, data io.Reader) error {
+	if err := os.MkdirAll(s.dir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create offline dir: %w", err)
+	}
+	tempFile, err := os.CreateTemp(s.dir, "offline-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, copyErr := io.Copy(tempFile, data)
+	if err = errors.Join(copyErr, tempFile.Close()); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return os.Rename(tempFile.Name(), path)
+}
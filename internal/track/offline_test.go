@@ -0,0 +1,130 @@
+package track
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-musicfox/go-musicfox/internal/composer"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/utils/netease"
+)
+
+type offlineFetcherStub struct {
+	lyricFetcherStub
+	infoCalls   int
+	streamCalls int
+}
+
+func (f *offlineFetcherStub) FetchPlayableInfo(context.Context, int64) (*netease.PlayableInfo, error) {
+	f.infoCalls++
+	return &netease.PlayableInfo{URL: "http://example.com/song.mp3", MusicType: "mp3"}, nil
+}
+
+func (f *offlineFetcherStub) FetchStream(context.Context, PlayableSource) (io.ReadCloser, error) {
+	f.streamCalls++
+	return io.NopCloser(strings.NewReader("audio")), nil
+}
+
+type noopTagger struct{}
+
+func (noopTagger) SetSongTag(string, structs.Song) error { return nil }
+
+func newOfflineTestManager(t *testing.T, fetcher Fetcher) *Manager {
+	t.Helper()
+	return &Manager{
+		fetcher:     fetcher,
+		tagger:      noopTagger{},
+		cacher:      &Cacher{musicDir: t.TempDir()},
+		nameGen:     composer.NewFileNameGenerator(),
+		downloadDir: t.TempDir(),
+		offline:     NewOfflineStore(t.TempDir()),
+	}
+}
+
+func TestOfflineStoreRoundTrip(t *testing.T) {
+	store := NewOfflineStore(t.TempDir())
+	if store.Has(1) {
+		t.Fatal("empty store should not have song")
+	}
+	path, err := store.PutSong(1, "flac", strings.NewReader("flac"))
+	if err != nil {
+		t.Fatalf("put song: %v", err)
+	}
+	if got, ext, err := store.SongPath(1); err != nil || got != path || ext != "flac" {
+		t.Fatalf("song path = (%q, %q, %v), want (%q, flac)", got, ext, err, path)
+	}
+	if err = store.PutLyric(1, structs.LRCData{Original: "[00:00.00]a", Yrc: "yrc"}); err != nil {
+		t.Fatalf("put lyric: %v", err)
+	}
+	if lrc, err := store.Lyric(1); err != nil || lrc.Original != "[00:00.00]a" || lrc.Yrc != "yrc" {
+		t.Fatalf("lyric = %+v, %v", lrc, err)
+	}
+	if err = store.PutCover(1, []byte("jpg")); err != nil {
+		t.Fatalf("put cover: %v", err)
+	}
+
+	if err = store.Remove(1); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	entries, _ := os.ReadDir(store.Dir())
+	if len(entries) != 0 {
+		t.Fatalf("files left after remove: %v", entries)
+	}
+}
+
+func TestPinSongIsPreferredAndWorksOffline(t *testing.T) {
+	fetcher := &offlineFetcherStub{lyricFetcherStub: lyricFetcherStub{regular: structs.LRCData{Original: "[00:00.00]pinned"}}}
+	manager := newOfflineTestManager(t, fetcher)
+	song := structs.Song{Id: 7, Name: "song"}
+
+	if err := manager.PinSong(context.Background(), song); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+	if !manager.IsPinned(song.Id) || fetcher.streamCalls != 1 {
+		t.Fatalf("pinned = %t, stream calls = %d", manager.IsPinned(song.Id), fetcher.streamCalls)
+	}
+	// 重复固定不会再次下载
+	if err := manager.PinSong(context.Background(), song); err != nil || fetcher.streamCalls != 1 {
+		t.Fatalf("re-pin: err %v, stream calls = %d", err, fetcher.streamCalls)
+	}
+
+	manager.SetOfflineMode(true)
+	source, err := manager.ResolvePlayableSource(context.Background(), song)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if source.Type != SourceOffline || source.Info.MusicType != "mp3" {
+		t.Fatalf("source = %+v, want offline mp3", source)
+	}
+	lrc, err := manager.GetLyric(context.Background(), song)
+	if err != nil || lrc.Original != "[00:00.00]pinned" {
+		t.Fatalf("lyric = %+v, %v", lrc, err)
+	}
+	if fetcher.regularCalls != 1 {
+		t.Fatalf("lyric fetch calls = %d, want 1 (only while pinning)", fetcher.regularCalls)
+	}
+}
+
+func TestOfflineModeDoesNotUseNetwork(t *testing.T) {
+	fetcher := &offlineFetcherStub{}
+	manager := newOfflineTestManager(t, fetcher)
+	manager.SetOfflineMode(true)
+	song := structs.Song{Id: 8}
+
+	if _, err := manager.ResolvePlayableSource(context.Background(), song); !errors.Is(err, ErrOffline) {
+		t.Fatalf("resolve err = %v, want ErrOffline", err)
+	}
+	if _, err := manager.GetLyric(context.Background(), song); !errors.Is(err, ErrOffline) {
+		t.Fatalf("lyric err = %v, want ErrOffline", err)
+	}
+	if manager.IsAvailableOffline(song) {
+		t.Fatal("song should be unavailable offline")
+	}
+	if fetcher.infoCalls != 0 || fetcher.regularCalls != 0 {
+		t.Fatalf("network calls in offline mode: info %d, lyric %d", fetcher.infoCalls, fetcher.regularCalls)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/go-flac/flacpicture"
+	"github.com/go-musicfox/go-musicfox/internal/codec"
 	"github.com/go-musicfox/go-musicfox/internal/structs"
 	"github.com/go-musicfox/go-musicfox/utils/app"
 
@@ -39,6 +40,12 @@ func (m *metadataTagger) SetSongTag(filePath string, song structs.Song) (err err
 		return err
 	}
 
+	header := make([]byte, codec.SniffSize)
+	n, _ := io.ReadFull(file, header)
+	if codec.Sniff(header[:n]) == codec.FormatMP4 {
+		file.Close()
+		return m.setMP4Tag(filePath, song)
+	}
 	version := songtag.CheckVersion(file)
 	file.Close()
 
@@ -116,6 +123,48 @@ func (m *metadataTagger) setGenericTag(filePath string, song structs.Song) error
 	return nil
 }
 
+// setMP4Tag 写入 iTunes 风格的 ilst 元数据，songtag 不支持写入 MP4
+func (m *metadataTagger) setMP4Tag(filePath string, song structs.Song) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file for mp4 tagging: %w", err)
+	}
+	defer file.Close()
+
+	tags := codec.MP4Tags{
+		Title:       song.Name,
+		Artist:      song.ArtistName(),
+		Album:       song.Album.Name,
+		AlbumArtist: song.Album.ArtistName(),
+	}
+	if coverData, mimeType, err := m.fetchCover(song.PicUrl); err == nil {
+		tags.Cover, tags.CoverMime = coverData, mimeType
+	} else {
+		slog.Warn("Failed to fetch cover image for mp4", "songId", song.Id, "error", err)
+	}
+
+	tempPath := filePath + ".tmp"
+	temp, err := os.Create(tempPath)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	if err = codec.WriteMP4Tags(file, temp, tags); err != nil {
+		_ = temp.Close()
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to write mp4 tags: %w", err)
+	}
+	if err = temp.Close(); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	_ = file.Close()
+
+	if err = os.Rename(tempPath, filePath); err != nil {
+		return fmt.Errorf("failed to rename temp file to original: %w", err)
+	}
+	return nil
+}
+
 func (m *metadataTagger) fetchCover(picURL string) ([]byte, string, error) {
 	resp, err := m.httpClient.Get(app.AddResizeParamForPicUrl(picURL, 1024))
 	if err != nil {
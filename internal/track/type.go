@@ -12,6 +12,8 @@ const (
 	SourceDownloaded SourceType = iota // 来源于最终下载目录
 	SourceCached                       // 来源于缓存
 	SourceRemote                       // 来源于网络
+	SourceLocal                        // 来源于本地音乐库
+	SourceOffline                      // 来源于离线存储（已固定的歌单/专辑）
 )
 
 type PlayableSource struct {
@@ -27,6 +27,7 @@ const StartupTickDuration = time.Millisecond * 16
 const AppLocalDataDir = "go-musicfox"
 const AppDBName = "musicfox"
 const AppTomlFile = "config.toml"
+const AppSocketFile = "musicfox.sock"
 const AppPrimaryRandom = "random"
 const AppPrimaryColor = "#f90022"
 const SubmitText = "确认"
@@ -45,10 +46,18 @@ const MpvPlayer = "mpv"            // mpv player
 const OsxPlayer = "osx"            // osx
 const WinMediaPlayer = "win_media" // win media player
 const DlnaPlayer = "dlna"          // dlna
+const NullPlayer = "null"          // 静音输出，不打开音频设备
 
 const BeepGoMp3Decoder = "go-mp3"
 const BeepMiniMp3Decoder = "minimp3"
 
+const ReplayGainOff = "off"     // 不做响度均衡
+const ReplayGainTrack = "track" // 按单曲增益
+const ReplayGainAlbum = "album" // 按专辑增益
+
+const CrossfadeLinear = "linear"          // 线性淡入淡出
+const CrossfadeEqualPower = "equal-power" // 等功率淡入淡出
+
 const MaxPlayErrCount = 3
 
 const SearchPageSize = 100
@@ -38,6 +38,12 @@ const (
 	iconTune           = "󰘳 " // 播放控制（调节）
 	iconSong           = "󰎈 " // 歌曲（音符）
 	iconPlaylist       = "󰲹 " // 歌单（播放列表）
+	iconQueuePlayNext  = "󰐑 " // 下一首播放
+	iconQueueAdd       = "󰐒 " // 添加到待播队列
+	iconArrowUp        = "󰁝 " // 上移
+	iconArrowDown      = "󰁅 " // 下移
+	iconRename         = "󰏫 " // 重命名
+	iconExport         = "󰈇 " // 导出
 )
 
 // itemIndent 为分组标题（Header）下的操作项前导缩进，
@@ -145,6 +151,40 @@ func actionItemsForMenu(n *Netease, from string, playing bool, selectedIndex int
 		actions = append(actions, buildPlaylistActions(n)...)
 	}
 
+	if isSelected {
+		actions = append(actions, buildOfflineActions(n, menu, selectedIndex)...)
+		actions = append(actions, buildDownloadActions(n, menu, selectedIndex)...)
+		actions = append(actions, buildLocalPlaylistActions(n, menu, selectedIndex)...)
+	}
+
+	if isSelected && isSongsProvider(menu) && from != UpNextMenuKey {
+		actions = append(actions, ActionItem{
+			title:  model.MenuItem{Title: iconQueuePlayNext + "下一首播放"},
+			action: func() { addSongsToUpNext(n, true) },
+			group:  "queue",
+		}, ActionItem{
+			title:  model.MenuItem{Title: iconQueueAdd + "添加到待播队列"},
+			action: func() { addSongsToUpNext(n, false) },
+			group:  "queue",
+		})
+	}
+
+	if isSelected && from == UpNextMenuKey {
+		actions = append(actions, ActionItem{
+			title:  model.MenuItem{Title: iconArrowUp + "上移"},
+			action: func() { moveSongInUpNext(n, -1) },
+			group:  "queue",
+		}, ActionItem{
+			title:  model.MenuItem{Title: iconArrowDown + "下移"},
+			action: func() { moveSongInUpNext(n, 1) },
+			group:  "queue",
+		}, ActionItem{
+			title: model.MenuItem{Title: iconDelete + "从待播队列移除"},
+			page:  func() model.Page { return delSongFromUpNext(n) },
+			group: "queue",
+		})
+	}
+
 	if isSelected && from == CurPlaylistKey {
 		actions = append(actions, ActionItem{
 			title: model.MenuItem{Title: iconDelete + "从播放列表移除"},
@@ -276,6 +316,96 @@ func buildPlaylistActions(n *Netease) []ActionItem {
 	return items
 }
 
+// buildOfflineActions 离线保存/取消离线保存歌单、专辑
+func buildOfflineActions(n *Netease, menu model.Menu, selectedIndex int) []ActionItem {
+	switch m := menu.(type) {
+	case *PlaylistDetailMenu, *AlbumDetailMenu:
+		return []ActionItem{{
+			title:  model.MenuItem{Title: iconDownload + "离线保存当前列表"},
+			action: func() { pinCurrentList(n) },
+			group:  "offline",
+		}}
+	case *OfflinePinsMenu:
+		index := m.RealDataIndex(selectedIndex)
+		if index < 0 || index >= len(m.Pins()) {
+			return nil
+		}
+		pin := m.Pins()[index]
+		return []ActionItem{{
+			title: model.MenuItem{Title: iconDelete + "取消离线保存"},
+			action: func() {
+				unpinCollection(n, pin)
+				m.refresh()
+				n.MustMain().RefreshMenuList()
+			},
+			group: "offline",
+		}}
+	case *OfflineSongsMenu:
+		return []ActionItem{{
+			title:  model.MenuItem{Title: iconDelete + "取消离线保存当前列表"},
+			action: func() { unpinCollection(n, m.Pin()) },
+			group:  "offline",
+		}}
+	case PlaylistsMenu:
+		return []ActionItem{{
+			title: model.MenuItem{Title: iconDownload + "离线保存歌单"},
+			page:  func() model.Page { return pinSelectedPlaylist(n) },
+			group: "offline",
+		}}
+	case AlbumsMenu:
+		return []ActionItem{{
+			title: model.MenuItem{Title: iconDownload + "离线保存专辑"},
+			page:  func() model.Page { return pinSelectedAlbum(n) },
+			group: "offline",
+		}}
+	}
+	return nil
+}
+
+// buildLocalPlaylistActions 本地歌单的重命名、导出、删除及歌曲排序
+func buildLocalPlaylistActions(n *Netease, menu model.Menu, selectedIndex int) []ActionItem {
+	switch m := menu.(type) {
+	case *LocalPlaylistsMenu:
+		playlist, ok := m.Playlist(m.RealDataIndex(selectedIndex))
+		if !ok {
+			return nil
+		}
+		return []ActionItem{{
+			title: model.MenuItem{Title: iconRename + "重命名"},
+			page:  func() model.Page { return newRenameLocalPlaylistPage(n, playlist) },
+			group: "local_playlist",
+		}, {
+			title: model.MenuItem{Title: iconExport + "导出为 M3U8 / XSPF / JSON"},
+			page:  func() model.Page { return newExportLocalPlaylistPage(n, playlist) },
+			group: "local_playlist",
+		}, {
+			title:  model.MenuItem{Title: iconDelete + "删除歌单"},
+			action: func() { confirmDeleteLocalPlaylist(n, playlist) },
+			group:  "local_playlist",
+		}}
+	case *LocalPlaylistDetailMenu:
+		playlist := m.Playlist()
+		return []ActionItem{{
+			title:  model.MenuItem{Title: iconArrowUp + "上移"},
+			action: func() { moveSongInLocalPlaylist(n, -1) },
+			group:  "local_playlist",
+		}, {
+			title:  model.MenuItem{Title: iconArrowDown + "下移"},
+			action: func() { moveSongInLocalPlaylist(n, 1) },
+			group:  "local_playlist",
+		}, {
+			title: model.MenuItem{Title: iconPlaylistRemove + "从本地歌单移除"},
+			page:  func() model.Page { return delSongFromLocalPlaylist(n) },
+			group: "local_playlist",
+		}, {
+			title: model.MenuItem{Title: iconExport + "导出当前歌单"},
+			page:  func() model.Page { return newExportLocalPlaylistPage(n, playlist) },
+			group: "local_playlist",
+		}}
+	}
+	return nil
+}
+
 func buildSongActions(n *Netease, isSelected bool) []ActionItem {
 	items := []ActionItem{
 		{
@@ -343,6 +473,11 @@ func buildSongActions(n *Netease, isSelected bool) []ActionItem {
 			page:  func() model.Page { return openAddSongToUserPlaylistMenu(n, isSelected, false) },
 			group: "playlist",
 		},
+		{
+			title: model.MenuItem{Title: iconPlaylistAdd + "添加至本地歌单"},
+			page:  func() model.Page { return openAddSongToLocalPlaylistMenu(n, isSelected) },
+			group: "playlist",
+		},
 		{
 			title:  model.MenuItem{Title: iconShuffle + "相似的歌曲"},
 			action: func() { findSimilarSongs(n, isSelected) },
@@ -0,0 +1,326 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/httpapi"
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
+	"github.com/go-musicfox/go-musicfox/internal/lyric"
+	"github.com/go-musicfox/go-musicfox/internal/netease"
+	control "github.com/go-musicfox/go-musicfox/internal/remote_control"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+// controlMenuKey 通过控制套接字替换播放列表时使用的菜单 Key
+const controlMenuKey = "remote_control"
+
+var _ httpapi.Backend = (*ControlHandler)(nil)
+
+// ControlHandler 处理来自本地控制套接字及 HTTP 远程控制的命令
+type ControlHandler struct {
+	player *Player
+
+	// mu 保证不同来源的命令串行执行
+	mu sync.Mutex
+}
+
+func NewControlHandler(p *Player) *ControlHandler {
+	return &ControlHandler{player: p}
+}
+
+func (h *ControlHandler) Handle(req ipc.Request) (any, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	p := h.player
+	switch req.Cmd {
+	case ipc.CmdPlay:
+		return nil, h.play(req)
+	case ipc.CmdPause:
+		p.Pause()
+	case ipc.CmdResume:
+		p.Resume()
+	case ipc.CmdStop:
+		p.Stop()
+	case ipc.CmdToggle:
+		h.toggle()
+	case ipc.CmdNext:
+		p.NextSong(true)
+	case ipc.CmdPrev:
+		p.PreviousSong(true)
+	case ipc.CmdSeek:
+		if req.Position < 0 {
+			return nil, errors.Errorf("invalid position: %v", req.Position)
+		}
+		p.Seek(time.Duration(req.Position * float64(time.Second)))
+	case ipc.CmdVolume:
+		return h.volume(req)
+	case ipc.CmdStatus:
+		return statusOf(p.PlayingInfo()), nil
+	case ipc.CmdLike:
+		p.likeOrDislike(true)
+	case ipc.CmdDislike:
+		p.likeOrDislike(false)
+	case ipc.CmdShuffle:
+		return nil, h.shuffle(req.Action)
+	case ipc.CmdRepeat:
+		return nil, h.repeat(req.Action)
+	case ipc.CmdQueue:
+		return h.queue(req)
+	default:
+		return nil, errors.Errorf("unknown command: %q", req.Cmd)
+	}
+	return nil, nil
+}
+
+func (h *ControlHandler) play(req ipc.Request) error {
+	p := h.player
+	if len(req.SongIDs) > 0 {
+		songs, err := netease.FetchSongsByIds(req.SongIDs)
+		if err != nil {
+			return errors.Wrap(err, "fetch songs")
+		}
+		if len(songs) == 0 {
+			return errors.New("no song found")
+		}
+		index := 0
+		if req.Index != nil {
+			index = *req.Index
+		}
+		if index < 0 || index >= len(songs) {
+			return errors.Errorf("index out of range: %d", index)
+		}
+		p.replacePlaylist(index, songs, controlMenuKey)
+		p.StartPlay()
+		return nil
+	}
+
+	if req.Index != nil {
+		return p.playAt(*req.Index)
+	}
+
+	switch p.State() {
+	case types.Paused:
+		p.Resume()
+	case types.Playing:
+	default:
+		if len(p.Playlist()) == 0 {
+			if p.upNext.Len() == 0 {
+				return errors.New("playlist is empty")
+			}
+			p.NextSong(true)
+			return nil
+		}
+		p.StartPlay()
+	}
+	return nil
+}
+
+func (h *ControlHandler) toggle() {
+	p := h.player
+	switch p.State() {
+	case types.Paused:
+		p.Resume()
+	case types.Playing:
+		p.Pause()
+	default:
+		if len(p.Playlist()) > 0 {
+			p.StartPlay()
+		} else if p.upNext.Len() > 0 {
+			p.NextSong(true)
+		}
+	}
+}
+
+func (h *ControlHandler) volume(req ipc.Request) (any, error) {
+	p := h.player
+	switch req.Action {
+	case "":
+		if req.Volume == nil {
+			break
+		}
+		if *req.Volume < 0 || *req.Volume > 100 {
+			return nil, errors.Errorf("invalid volume: %d", *req.Volume)
+		}
+		p.SetVolume(*req.Volume)
+	case ipc.VolumeUp:
+		p.UpVolume()
+	case ipc.VolumeDown:
+		p.DownVolume()
+	default:
+		return nil, errors.Errorf("unknown volume action: %q", req.Action)
+	}
+	return ipc.VolumeInfo{Volume: p.Volume()}, nil
+}
+
+func (h *ControlHandler) shuffle(mode string) error {
+	p := h.player
+	switch mode {
+	case "":
+		p.toggleShuffle()
+	case ipc.ShuffleOn:
+		p.setShuffle(1)
+	case ipc.ShuffleOff:
+		p.setShuffle(0)
+	default:
+		return errors.Errorf("unknown shuffle mode: %q", mode)
+	}
+	return nil
+}
+
+func (h *ControlHandler) repeat(mode string) error {
+	p := h.player
+	switch mode {
+	case "":
+		p.cycleRepeat()
+	case ipc.RepeatOff:
+		p.setRepeat(0)
+	case ipc.RepeatOne:
+		p.setRepeat(1)
+	case ipc.RepeatAll:
+		p.setRepeat(2)
+	default:
+		return errors.Errorf("unknown repeat mode: %q", mode)
+	}
+	return nil
+}
+
+func (h *ControlHandler) queue(req ipc.Request) (any, error) {
+	p := h.player
+	switch req.Action {
+	case "", ipc.QueueActionList:
+		return h.queueInfo(), nil
+	case ipc.QueueActionAdd, ipc.QueueActionNext, ipc.QueueActionEnqueue:
+		if len(req.SongIDs) == 0 {
+			return nil, errors.New("songIds is required")
+		}
+		songs, err := netease.FetchSongsByIds(req.SongIDs)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch songs")
+		}
+		if req.Action == ipc.QueueActionAdd {
+			playlist := append(slices.Clone(p.Playlist()), songs...)
+			p.replacePlaylist(p.CurSongIndex(), playlist, p.playingMenuKey+"modified")
+		} else {
+			p.enqueue(songs, req.Action == ipc.QueueActionNext)
+		}
+		return h.queueInfo(), nil
+	case ipc.QueueActionClear:
+		p.upNext.Clear()
+		p.upNextChanged()
+		return h.queueInfo(), nil
+	default:
+		return nil, errors.Errorf("unknown queue action: %q", req.Action)
+	}
+}
+
+func (h *ControlHandler) queueInfo() ipc.QueueInfo {
+	p := h.player
+	view := p.upNextView()
+	info := ipc.QueueInfo{
+		Index:  view.current(),
+		Mode:   p.Mode().Name(),
+		UpNext: len(view.queue),
+	}
+	for _, song := range view.songs() {
+		info.Songs = append(info.Songs, queueItemOf(song))
+	}
+	return info
+}
+
+func queueItemOf(song structs.Song) ipc.QueueItem {
+	return ipc.QueueItem{
+		ID:       song.Id,
+		Name:     song.Name,
+		Artist:   song.ArtistName(),
+		Album:    song.Album.Name,
+		Duration: song.Duration.Seconds(),
+	}
+}
+
+// CurrentLyric 当前歌词行，有翻译时以换行分隔
+func (h *ControlHandler) CurrentLyric() string {
+	if h.player.lyricService == nil {
+		return ""
+	}
+	return currentLyricText(h.player.lyricService.State())
+}
+
+func currentLyricText(state lyric.State) string {
+	if !state.IsRunning {
+		return ""
+	}
+
+	var content, translation string
+	if state.YRCEnabled && len(state.YRCLines) > 0 {
+		if state.YRCLineIndex < 0 || state.YRCLineIndex >= len(state.YRCLines) {
+			return ""
+		}
+		line := state.YRCLines[state.YRCLineIndex]
+		var sb strings.Builder
+		for _, w := range line.Words {
+			sb.WriteString(w.Word)
+		}
+		content, translation = sb.String(), line.TranslatedLyric
+	} else {
+		if state.CurrentIndex < 0 || state.CurrentIndex >= len(state.Fragments) {
+			return ""
+		}
+		f := state.Fragments[state.CurrentIndex]
+		content, translation = f.Content, state.TranslatedFragments[f.StartTimeMs]
+	}
+
+	if state.ShowTranslation && translation != "" {
+		return content + "\n" + translation
+	}
+	return content
+}
+
+func statusOf(info control.PlayingInfo) ipc.Status {
+	return ipc.Status{
+		TotalDuration:  info.TotalDuration.Seconds(),
+		PassedDuration: info.PassedDuration.Seconds(),
+		State:          stateName(info.State),
+		Volume:         info.Volume,
+		TrackID:        info.TrackID,
+		PicUrl:         info.PicUrl,
+		Name:           info.Name,
+		Artist:         info.Artist,
+		Album:          info.Album,
+		AlbumArtist:    info.AlbumArtist,
+		LRCText:        info.LRCText,
+		LoopStatus:     info.LoopStatus,
+		Shuffle:        info.Shuffle,
+		Queued:         info.Queued,
+		UpNext:         info.UpNext,
+	}
+}
+
+func stateName(state types.State) string {
+	switch state {
+	case types.Playing:
+		return "playing"
+	case types.Paused:
+		return "paused"
+	case types.Stopped:
+		return "stopped"
+	case types.Interrupted:
+		return "interrupted"
+	default:
+		return "unknown"
+	}
+}
+
+// replacePlaylist 替换播放列表，替换后播放列表不再与任何菜单关联
+func (p *Player) replacePlaylist(index int, songs []structs.Song, menuKey string) {
+	p.InitSongManager(index, songs)
+	p.playingMenu = nil
+	p.playingMenuKey = menuKey
+	p.playlistUpdateAt = time.Now()
+}
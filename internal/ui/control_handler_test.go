@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
+	"github.com/go-musicfox/go-musicfox/internal/lyric"
+	"github.com/go-musicfox/go-musicfox/internal/playlist"
+	control "github.com/go-musicfox/go-musicfox/internal/remote_control"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+func TestControlHandlerQueueList(t *testing.T) {
+	p := &Player{playlistManager: playlist.NewPlaylistManager()}
+	songs := []structs.Song{
+		{Id: 1, Name: "a", Duration: 90 * time.Second, Artists: []structs.Artist{{Name: "x"}, {Name: "y"}}},
+		{Id: 2, Name: "b", Album: structs.Album{Name: "album"}},
+	}
+	if err := p.playlistManager.Initialize(1, songs); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := NewControlHandler(p).Handle(ipc.Request{Cmd: ipc.CmdQueue})
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	info, ok := data.(ipc.QueueInfo)
+	if !ok {
+		t.Fatalf("queue data type = %T, want ipc.QueueInfo", data)
+	}
+	if info.Index != 1 || len(info.Songs) != 2 {
+		t.Fatalf("queue info = %+v, want index 1 with 2 songs", info)
+	}
+	if got := info.Songs[0]; got.Artist != "x,y" || got.Duration != 90 {
+		t.Fatalf("first item = %+v", got)
+	}
+	if info.Songs[1].Album != "album" {
+		t.Fatalf("second item = %+v", info.Songs[1])
+	}
+}
+
+func TestControlHandlerRejectsInvalidRequests(t *testing.T) {
+	p := &Player{playlistManager: playlist.NewPlaylistManager()}
+	if err := p.playlistManager.Initialize(0, []structs.Song{{Id: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	h := NewControlHandler(p)
+	outOfRange, tooLoud := 5, 101
+
+	tests := []ipc.Request{
+		{Cmd: "dance"},
+		{Cmd: ipc.CmdSeek, Position: -1},
+		{Cmd: ipc.CmdPlay, Index: &outOfRange},
+		{Cmd: ipc.CmdQueue, Action: ipc.QueueActionAdd},
+		{Cmd: ipc.CmdQueue, Action: "shuffle"},
+		{Cmd: ipc.CmdVolume, Volume: &tooLoud},
+		{Cmd: ipc.CmdVolume, Action: "louder"},
+		{Cmd: ipc.CmdShuffle, Action: "maybe"},
+		{Cmd: ipc.CmdRepeat, Action: "twice"},
+	}
+	for _, req := range tests {
+		if _, err := h.Handle(req); err == nil {
+			t.Errorf("request %+v: want error", req)
+		}
+	}
+}
+
+func TestStatusOfPlayingInfo(t *testing.T) {
+	status := statusOf(control.PlayingInfo{
+		TotalDuration:  3 * time.Minute,
+		PassedDuration: 1500 * time.Millisecond,
+		State:          types.Paused,
+		Volume:         60,
+		TrackID:        42,
+		Name:           "song",
+		LoopStatus:     "Track",
+	})
+	if status.State != "paused" || status.TotalDuration != 180 || status.PassedDuration != 1.5 {
+		t.Fatalf("status = %+v", status)
+	}
+	if status.TrackID != 42 || status.Volume != 60 || status.LoopStatus != "Track" {
+		t.Fatalf("status = %+v", status)
+	}
+}
+
+func TestCurrentLyricText(t *testing.T) {
+	state := lyric.State{
+		IsRunning:           true,
+		Fragments:           []lyric.LRCFragment{{StartTimeMs: 0, Content: "first"}, {StartTimeMs: 1000, Content: "second"}},
+		TranslatedFragments: map[int64]string{1000: "第二"},
+		CurrentIndex:        1,
+	}
+	if got := currentLyricText(state); got != "second" {
+		t.Fatalf("lyric = %q, want second", got)
+	}
+	state.ShowTranslation = true
+	if got := currentLyricText(state); got != "second\n第二" {
+		t.Fatalf("lyric = %q, want translation", got)
+	}
+
+	state.YRCEnabled = true
+	state.YRCLines = []lyric.YRCLine{{Words: []lyric.YRCWord{{Word: "逐"}, {Word: "字"}}}}
+	state.YRCLineIndex = 0
+	if got := currentLyricText(state); got != "逐字" {
+		t.Fatalf("yrc lyric = %q", got)
+	}
+
+	state.IsRunning = false
+	if got := currentLyricText(state); got != "" {
+		t.Fatalf("stopped lyric = %q, want empty", got)
+	}
+}
@@ -221,6 +221,10 @@ func (r *CoverRenderer) View(a *model.App, main *model.Main) (view string, lines
 
 	song := r.state.CurSong()
 	picUrl := getCoverUrl(song)
+	// 已离线保存的封面优先使用本地文件
+	if path := r.netease.trackManager.OfflineCoverPath(song.Id); path != "" {
+		picUrl = "file://" + path
+	}
 
 	if picUrl == "" {
 		return "", 0
@@ -25,6 +25,7 @@ func TestCustomPagesFillExplicitAppBackground(t *testing.T) {
 		"lastfm-auth":        NewLastfmAuthPage(netease),
 		"lastfm-qr-auth":     NewLastfmQRAuthPage(netease, login, nil),
 		"lastfm-api-account": newLastfmCustomAPIPageForBackgroundTest(netease),
+		"equalizer":          NewEqualizerPage(netease, login),
 	}
 	for name, page := range pages {
 		t.Run(name, func(t *testing.T) {
@@ -43,6 +44,7 @@ func TestCustomPagesLeaveTransparentAppBackgroundUnpainted(t *testing.T) {
 		"lastfm-auth":        NewLastfmAuthPage(netease),
 		"lastfm-qr-auth":     NewLastfmQRAuthPage(netease, login, nil),
 		"lastfm-api-account": newLastfmCustomAPIPageForBackgroundTest(netease),
+		"equalizer":          NewEqualizerPage(netease, login),
 	}
 	for name, page := range pages {
 		t.Run(name, func(t *testing.T) {
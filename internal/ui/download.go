@@ -0,0 +1,275 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+
+	"github.com/anhoder/foxful-cli/model"
+	"github.com/go-musicfox/netease-music/service"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/download"
+	"github.com/go-musicfox/go-musicfox/internal/netease"
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+	"github.com/go-musicfox/go-musicfox/utils/notify"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+	_struct "github.com/go-musicfox/go-musicfox/utils/struct"
+)
+
+// newDownloadManager 创建批量下载管理器，由 InitHook 启动、CloseHook 关闭
+func newDownloadManager(n *Netease) *download.Manager {
+	cfg := configs.AppConfig.Storage.Download
+	return download.New(n.trackManager,
+		download.WithConcurrency(cfg.Concurrency),
+		download.WithMaxRetries(cfg.MaxRetries),
+		download.WithUpdateHook(n.onDownloadUpdate),
+		download.WithDoneHook(n.onDownloadDone))
+}
+
+// onDownloadUpdate 下载管理页面可见时刷新进度
+func (n *Netease) onDownloadUpdate() {
+	if n.Headless() {
+		return
+	}
+	main := n.MustMain()
+	if _, ok := main.CurMenu().(*DownloadsMenu); !ok {
+		return
+	}
+	main.RefreshMenuList()
+	n.rerender()
+}
+
+// onDownloadDone 单曲下载逐首通知，批量下载在整组结束后汇总通知
+func (n *Netease) onDownloadDone(job storage.DownloadJob) {
+	song := job.Song
+	succeeded := job.State == storage.DownloadDone
+	if succeeded && configs.AppConfig.Storage.DownloadSongWithLyric {
+		if job.Group == "" {
+			handleLyricDownload(n, song)
+		} else if _, err := n.trackManager.DownloadLyric(context.Background(), song); err != nil {
+			slog.Warn("歌词下载失败", "song", song.Name, "id", song.Id, slogx.Error(err))
+		}
+	}
+
+	if job.Group != "" {
+		n.notifyGroupDownloaded(job.Group)
+		return
+	}
+	switch {
+	case succeeded && job.Existed:
+		slog.Info("歌曲文件已存在，跳过下载", "song", song.Name, "id", song.Id, "path", job.Path)
+		notify.Notify(notify.NotifyContent{
+			Title:   model.T(MsgOperationDownloadExists),
+			Text:    filepath.Base(job.Path),
+			GroupId: types.GroupID,
+			Level:   notify.ToastWarning,
+		})
+	case succeeded:
+		slog.Info("歌曲下载成功", "song", song.Name, "id", song.Id, "path", job.Path)
+		notify.Notify(notify.NotifyContent{
+			Title:   model.T(MsgOperationDownloadSuccess),
+			Text:    filepath.Base(job.Path),
+			GroupId: types.GroupID,
+			Level:   notify.ToastSuccess,
+		})
+	default:
+		notify.Notify(notify.NotifyContent{
+			Title:   model.T(MsgOperationDownloadFailed),
+			Text:    job.Error,
+			GroupId: types.GroupID,
+			Level:   notify.ToastError,
+		})
+	}
+}
+
+// notifyGroupDownloaded 同一批次的任务全部结束后发送汇总通知
+func (n *Netease) notifyGroupDownloaded(group string) {
+	var done, failed int
+	for _, job := range n.downloadMgr.Jobs() {
+		if job.Group != group {
+			continue
+		}
+		switch job.State {
+		case storage.DownloadDone:
+			done++
+		case storage.DownloadFailed:
+			failed++
+		default:
+			return
+		}
+	}
+
+	content := notify.NotifyContent{
+		Title:   "批量下载完成",
+		Text:    fmt.Sprintf("%s：%d 首已下载", group, done),
+		GroupId: types.GroupID,
+		Level:   notify.ToastSuccess,
+	}
+	if failed > 0 {
+		content.Text += fmt.Sprintf("，%d 首失败", failed)
+		content.Level = notify.ToastWarning
+	}
+	notify.Notify(content)
+}
+
+// enqueueDownloads 将歌曲加入下载队列，group 为空表示单曲下载
+func enqueueDownloads(n *Netease, songs []structs.Song, group string) {
+	if n.IsOffline() {
+		notify.Notify(notify.NotifyContent{
+			Title:   model.T(MsgOperationDownloadFailed),
+			Text:    "离线模式下无法下载",
+			GroupId: types.GroupID,
+			Level:   notify.ToastWarning,
+		})
+		return
+	}
+
+	added := n.downloadMgr.Add(songs, group)
+	slog.Info("加入下载队列", "group", group, "total", len(songs), "added", added)
+	name := group
+	if name == "" && len(songs) > 0 {
+		name = songs[0].Name
+	}
+	content := notify.NotifyContent{GroupId: types.GroupID}
+	switch {
+	case added == 0:
+		content.Title, content.Text = "已在下载队列中", name
+	case group == "":
+		content.Title, content.Text = model.T(MsgOperationDownloading), name
+	default:
+		content.Title, content.Text = "已加入下载队列", fmt.Sprintf("%s：%d 首", name, added)
+	}
+	notify.Notify(content)
+}
+
+// downloadSelectedPlaylist 下载选中歌单的全部歌曲
+func downloadSelectedPlaylist(n *Netease, playlist structs.Playlist) model.Page {
+	return NewOperation(n, func(n *Netease) model.Page {
+		codeType, songs := netease.FetchSongsOfPlaylist(playlist.Id, true)
+		if codeType != _struct.Success {
+			notifyBatchDownloadFailed(playlist.Name, "获取歌单歌曲失败")
+			return nil
+		}
+		enqueueDownloads(n, songs, playlist.Name)
+		return nil
+	}).ShowLoading().Execute()
+}
+
+// downloadSelectedAlbum 下载选中专辑的全部歌曲
+func downloadSelectedAlbum(n *Netease, album structs.Album) model.Page {
+	return NewOperation(n, func(n *Netease) model.Page {
+		songs, ok := fetchSongsOfAlbum(album.Id)
+		if !ok {
+			notifyBatchDownloadFailed(album.Name, "获取专辑歌曲失败")
+			return nil
+		}
+		enqueueDownloads(n, songs, album.Name)
+		return nil
+	}).ShowLoading().Execute()
+}
+
+// downloadSelectedArtist 下载选中歌手的热门歌曲
+func downloadSelectedArtist(n *Netease, artist structs.Artist) model.Page {
+	return NewOperation(n, func(n *Netease) model.Page {
+		artistSongService := service.ArtistTopSongService{Id: strconv.FormatInt(artist.Id, 10)}
+		code, response := artistSongService.ArtistTopSong()
+		if _struct.CheckCode(code) != _struct.Success {
+			notifyBatchDownloadFailed(artist.Name, "获取歌手热门歌曲失败")
+			return nil
+		}
+		enqueueDownloads(n, _struct.GetSongsOfArtist(response), artist.Name)
+		return nil
+	}).ShowLoading().Execute()
+}
+
+func notifyBatchDownloadFailed(name, reason string) {
+	notify.Notify(notify.NotifyContent{
+		Title:   "批量下载失败",
+		Text:    name + "：" + reason,
+		GroupId: types.GroupID,
+		Level:   notify.ToastError,
+	})
+}
+
+// buildDownloadActions 批量下载歌单、专辑、歌手或当前列表，以及下载管理页的任务操作
+func buildDownloadActions(n *Netease, menu model.Menu, selectedIndex int) []ActionItem {
+	index := menu.RealDataIndex(selectedIndex)
+	var items []ActionItem
+	if m, ok := menu.(*DownloadsMenu); ok {
+		return downloadsMenuActions(n, m, index)
+	}
+	if m, ok := menu.(SongsMenu); ok && len(m.Songs()) > 1 {
+		songs := m.Songs()
+		group := n.MustMain().MenuTitle().Title
+		items = append(items, ActionItem{
+			title:  model.MenuItem{Title: iconDownload + "下载当前列表"},
+			action: func() { enqueueDownloads(n, songs, group) },
+			group:  "download",
+		})
+	}
+	if m, ok := menu.(PlaylistsMenu); ok && index >= 0 && index < len(m.Playlists()) {
+		playlist := m.Playlists()[index]
+		items = append(items, ActionItem{
+			title: model.MenuItem{Title: iconDownload + "下载整个歌单"},
+			page:  func() model.Page { return downloadSelectedPlaylist(n, playlist) },
+			group: "download",
+		})
+	}
+	if m, ok := menu.(AlbumsMenu); ok && index >= 0 && index < len(m.Albums()) {
+		album := m.Albums()[index]
+		items = append(items, ActionItem{
+			title: model.MenuItem{Title: iconDownload + "下载整个专辑"},
+			page:  func() model.Page { return downloadSelectedAlbum(n, album) },
+			group: "download",
+		})
+	}
+	if m, ok := menu.(ArtistsMenu); ok && index >= 0 && index < len(m.Artists()) {
+		artist := m.Artists()[index]
+		items = append(items, ActionItem{
+			title: model.MenuItem{Title: iconDownload + "下载歌手热门歌曲"},
+			page:  func() model.Page { return downloadSelectedArtist(n, artist) },
+			group: "download",
+		})
+	}
+	return items
+}
+
+// downloadsMenuActions 重试、删除选中任务及清除已完成任务
+func downloadsMenuActions(n *Netease, m *DownloadsMenu, index int) []ActionItem {
+	refresh := func() { n.MustMain().RefreshMenuList() }
+	var items []ActionItem
+	if jobs := m.Jobs(); index >= 0 && index < len(jobs) {
+		job := jobs[index]
+		if job.State == storage.DownloadFailed {
+			items = append(items, ActionItem{
+				title:  model.MenuItem{Title: iconDownload + "重试"},
+				action: func() { n.downloadMgr.Retry(job.Song.Id); refresh() },
+				group:  "download",
+			})
+		}
+		items = append(items, ActionItem{
+			title:  model.MenuItem{Title: iconDelete + "删除任务"},
+			action: func() { n.downloadMgr.Remove(job.Song.Id); refresh() },
+			group:  "download",
+		})
+	}
+	items = append(items, ActionItem{
+		title: model.MenuItem{Title: iconDelete + "清除已完成任务"},
+		action: func() {
+			cleared := n.downloadMgr.ClearFinished()
+			refresh()
+			notify.Notify(notify.NotifyContent{
+				Title:   "已清除已完成任务",
+				Text:    fmt.Sprintf("共 %d 个", cleared),
+				GroupId: types.GroupID,
+			})
+		},
+		group: "download",
+	})
+	return items
+}
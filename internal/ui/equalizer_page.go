@@ -0,0 +1,266 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/anhoder/foxful-cli/model"
+	"github.com/anhoder/foxful-cli/style"
+	"github.com/anhoder/foxful-cli/util"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/player"
+	mfoxapp "github.com/go-musicfox/go-musicfox/utils/app"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+const EqualizerPageType model.PageType = "equalizer"
+
+const (
+	equalizerRowCount   = configs.EqualizerBandCount + 3 // 前置增益 + 低架 + 峰值频段 + 高架
+	equalizerGainStep   = 1.0
+	equalizerLabelWidth = 12
+)
+
+// EqualizerPage 均衡器页面，调整实时生效，保存后写入配置
+type EqualizerPage struct {
+	netease *Netease
+	from    model.Page
+	eq      player.EqualizerPlayer // 当前引擎不支持均衡器时为 nil
+
+	enabled     bool
+	preset      configs.EqualizerPreset
+	modified    bool
+	presetNames []string
+	selected    int
+	statusMsg   string
+}
+
+func NewEqualizerPage(netease *Netease, from model.Page) *EqualizerPage {
+	page := &EqualizerPage{
+		netease:     netease,
+		from:        from,
+		presetNames: configs.EqualizerPresetNames(),
+	}
+	if netease.player != nil {
+		page.eq, _ = netease.player.Player.(player.EqualizerPlayer)
+	}
+	if page.eq != nil {
+		page.enabled, page.preset = page.eq.Equalizer()
+	} else {
+		if configs.AppConfig != nil {
+			page.enabled = configs.AppConfig.Player.Beep.Equalizer.Enable
+			page.preset, _ = configs.FindEqualizerPreset(configs.AppConfig.Player.Beep.Equalizer.Preset)
+		} else {
+			page.preset, _ = configs.FindEqualizerPreset(configs.EqualizerDefaultPreset)
+		}
+		page.statusMsg = "当前播放引擎不支持均衡器，保存的设置将在使用 beep 引擎时生效"
+	}
+	return page
+}
+
+func (p *EqualizerPage) IgnoreQuitKeyMsg(_ tea.KeyMsg) bool {
+	return true
+}
+
+func (p *EqualizerPage) Type() model.PageType {
+	return EqualizerPageType
+}
+
+func (p *EqualizerPage) Msg() tea.Msg {
+	return nil
+}
+
+func (p *EqualizerPage) Update(msg tea.Msg, _ *model.App) (model.Page, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return p, nil
+	}
+	switch keyMsg.String() {
+	case "b", "esc":
+		return p.from, p.netease.RerenderCmd(true)
+	case "up", "k":
+		p.adjust(equalizerGainStep)
+	case "down", "j":
+		p.adjust(-equalizerGainStep)
+	case "left", "h":
+		p.selected = (p.selected + equalizerRowCount - 1) % equalizerRowCount
+	case "right", "l":
+		p.selected = (p.selected + 1) % equalizerRowCount
+	case "0":
+		p.adjust(-*p.gain(p.selected))
+	case "p", "tab":
+		p.switchPreset(1)
+	case "P", "shift+tab":
+		p.switchPreset(-1)
+	case "e", "space":
+		p.enabled = !p.enabled
+		p.apply()
+	case "enter", "s":
+		p.save()
+	}
+	return p, nil
+}
+
+// gain 第 row 行对应的增益字段
+func (p *EqualizerPage) gain(row int) *float64 {
+	switch row {
+	case 0:
+		return &p.preset.Preamp
+	case 1:
+		return &p.preset.LowShelf
+	case equalizerRowCount - 1:
+		return &p.preset.HighShelf
+	default:
+		return &p.preset.Bands[row-2]
+	}
+}
+
+func (p *EqualizerPage) adjust(delta float64) {
+	gain := p.gain(p.selected)
+	value := min(max(*gain+delta, -configs.EqualizerMaxGain), configs.EqualizerMaxGain)
+	if value == *gain {
+		return
+	}
+	*gain = value
+	p.modified = true
+	p.apply()
+}
+
+func (p *EqualizerPage) switchPreset(step int) {
+	if len(p.presetNames) == 0 {
+		return
+	}
+	index := slices.Index(p.presetNames, p.preset.Name)
+	if index < 0 && step < 0 {
+		index = 0
+	}
+	index = (index + step + len(p.presetNames)) % len(p.presetNames)
+	p.preset, _ = configs.FindEqualizerPreset(p.presetNames[index])
+	p.modified = false
+	p.apply()
+}
+
+func (p *EqualizerPage) apply() {
+	p.statusMsg = ""
+	if p.eq != nil {
+		p.eq.SetEqualizer(p.enabled, p.preset)
+	}
+}
+
+// save 修改过的频段保存为 Custom 预设，并将开关和预设名称写入配置文件
+func (p *EqualizerPage) save() {
+	if p.modified {
+		p.preset.Name = configs.EqualizerCustomPreset
+		p.preset.Description = "Adjusted in the equalizer page"
+		if err := configs.SaveEqualizerPreset(p.preset); err != nil {
+			slog.Error("Failed to save equalizer preset", slogx.Error(err))
+			p.statusMsg = util.SetFgStyle("保存预设失败: "+err.Error(), lipgloss.BrightRed)
+			return
+		}
+		p.modified = false
+		p.presetNames = configs.EqualizerPresetNames()
+	}
+	if err := writeEqualizerConfig(mfoxapp.ConfigFilePath(), p.enabled, p.preset.Name); err != nil {
+		slog.Error("Failed to persist equalizer config", slogx.Error(err))
+		p.statusMsg = util.SetFgStyle("写入配置失败: "+err.Error(), lipgloss.BrightRed)
+		return
+	}
+	if configs.AppConfig != nil {
+		configs.AppConfig.Player.Beep.Equalizer = configs.EqualizerConfig{Enable: p.enabled, Preset: p.preset.Name}
+	}
+	p.statusMsg = util.SetFgStyle("已保存", lipgloss.BrightGreen)
+}
+
+func writeEqualizerConfig(path string, enabled bool, preset string) error {
+	if err := configs.SetTOMLValue(path, []string{"player", "beep", "equalizer", "enable"}, enabled); err != nil {
+		return err
+	}
+	return configs.SetTOMLValue(path, []string{"player", "beep", "equalizer", "preset"}, preset)
+}
+
+func (p *EqualizerPage) View(a *model.App) string {
+	var builder strings.Builder
+
+	var top int
+	mainPage := p.netease.MustMain()
+	builder.WriteString(pageTitleView(a, mainPage, &top))
+	builder.WriteString(pageMenuTitleView(a, mainPage, &top, &model.MenuItem{Title: "均衡器"}))
+	builder.WriteString("\n\n")
+
+	space := style.CurrentStyleSet().AppBackground.Render(strings.Repeat(" ", max(0, mainPage.MenuStartColumn())))
+	writeLine := func(line string) {
+		builder.WriteString(space)
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+
+	state := "关闭"
+	if p.enabled {
+		state = "开启"
+	}
+	presetName := p.preset.Name
+	if p.modified {
+		presetName += "（已修改）"
+	}
+	writeLine(fmt.Sprintf("状态: %s    预设: %s", state, presetName))
+	builder.WriteString("\n")
+
+	for row := range equalizerRowCount {
+		line := fmt.Sprintf("%s %+5.1f dB  %s", runewidth.FillRight(equalizerRowLabel(row), equalizerLabelWidth), *p.gain(row), equalizerSlider(*p.gain(row)))
+		if row == p.selected {
+			writeLine(util.GetPrimaryFontStyle(true).Render("> " + line))
+		} else {
+			writeLine("  " + line)
+		}
+	}
+	builder.WriteString("\n")
+
+	if p.statusMsg != "" {
+		writeLine(p.statusMsg)
+	}
+	writeLine(util.SetFgStyle("←/→ 选择频段  ↑/↓ 调整增益  0 归零  p/P 切换预设  e 开关  enter 保存  b 返回", lipgloss.BrightBlack))
+
+	return finishCustomPageView(&builder, a)
+}
+
+func equalizerRowLabel(row int) string {
+	switch row {
+	case 0:
+		return "前置增益"
+	case 1:
+		return "低架 80Hz"
+	case equalizerRowCount - 1:
+		return "高架 12kHz"
+	}
+	freq := configs.EqualizerBandFrequencies[row-2]
+	if freq >= 1000 {
+		return fmt.Sprintf("%gkHz", freq/1000)
+	}
+	return fmt.Sprintf("%gHz", freq)
+}
+
+// equalizerSlider 以 1dB 为一格绘制 -12dB ~ +12dB 的滑块
+func equalizerSlider(gain float64) string {
+	cells := int(configs.EqualizerMaxGain)*2 + 1
+	center := cells / 2
+	pos := center + int(math.Round(gain))
+	var b strings.Builder
+	for i := range cells {
+		switch {
+		case i == pos:
+			b.WriteString("●")
+		case i == center:
+			b.WriteString("┼")
+		default:
+			b.WriteString("─")
+		}
+	}
+	return b.String()
+}
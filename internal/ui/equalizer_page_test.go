@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+)
+
+func TestWriteEqualizerConfigCreatesMissingTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("[player]\nengine = \"beep\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeEqualizerConfig(path, true, "Rock"); err != nil {
+		t.Fatalf("writeEqualizerConfig() error = %v", err)
+	}
+
+	config, err := configs.NewConfigFromTomlFile(path)
+	if err != nil {
+		t.Fatalf("NewConfigFromTomlFile() error = %v", err)
+	}
+	if got := config.Player.Beep.Equalizer; !got.Enable || got.Preset != "Rock" {
+		t.Fatalf("equalizer config = %+v", got)
+	}
+}
+
+func TestEqualizerPageAdjustsSelectedBand(t *testing.T) {
+	app, netease := newFormPageTestApp(t)
+	configs.LoadEqualizerPresets(t.TempDir())
+	page := NewEqualizerPage(netease, netease.MustMain())
+
+	press := func(key string) {
+		var msg tea.KeyPressMsg
+		switch key {
+		case "up":
+			msg = tea.KeyPressMsg{Code: tea.KeyUp}
+		case "right":
+			msg = tea.KeyPressMsg{Code: tea.KeyRight}
+		default:
+			msg = tea.KeyPressMsg{Code: rune(key[0]), Text: key}
+		}
+		_, _ = page.Update(msg, app)
+	}
+
+	press("right")
+	press("right")
+	press("up")
+	press("up")
+	if page.preset.Bands[0] != 2 || !page.modified {
+		t.Fatalf("31Hz band = %v, modified = %t", page.preset.Bands[0], page.modified)
+	}
+	press("0")
+	if page.preset.Bands[0] != 0 {
+		t.Fatalf("band not reset: %v", page.preset.Bands[0])
+	}
+
+	press("p")
+	if page.preset.Name != "Bass Boost" || page.modified {
+		t.Fatalf("preset after switch = %q, modified = %t", page.preset.Name, page.modified)
+	}
+	press("e")
+	if !page.enabled {
+		t.Fatal("equalizer not enabled")
+	}
+}
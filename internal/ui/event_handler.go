@@ -69,6 +69,22 @@ func (h *EventHandler) handle(op keybindings.OperateType) (bool, model.Page, tea
 			main.EnterMenu(NewCurPlaylist(newBaseMenu(h.netease), player.Playlist()), &model.MenuItem{Title: model.T(MsgMenuCurrentPlaylist), Subtitle: subTitle})
 			player.LocatePlayingSong()
 		}
+	case keybindings.OpUpNext:
+		if _, ok := menu.(*UpNextMenu); !ok {
+			main.EnterMenu(NewUpNextMenu(newBaseMenu(h.netease)), &model.MenuItem{Title: model.T(MsgMenuUpNext)})
+		}
+	case keybindings.OpMoveUpInUpNext:
+		if _, ok := menu.(*LocalPlaylistDetailMenu); ok {
+			moveSongInLocalPlaylist(h.netease, -1)
+		} else {
+			moveSongInUpNext(h.netease, -1)
+		}
+	case keybindings.OpMoveDownInUpNext:
+		if _, ok := menu.(*LocalPlaylistDetailMenu); ok {
+			moveSongInLocalPlaylist(h.netease, 1)
+		} else {
+			moveSongInUpNext(h.netease, 1)
+		}
 	case keybindings.OpPlayOrToggle:
 		h.playOrToggleHandle()
 	case keybindings.OpToggle:
@@ -225,15 +241,21 @@ func (h *EventHandler) handle(op keybindings.OperateType) (bool, model.Page, tea
 		newPage := subscribeArtist(h.netease, false, true)
 		return true, newPage, app.Tick(time.Nanosecond)
 	case keybindings.OpDeleteSongFromPlaylist:
-		// 从播放列表删除歌曲,仅在当前播放列表界面有效
+		// 从播放列表、待播队列或本地歌单删除歌曲,仅在对应界面有效
+		switch menu.(type) {
+		case *UpNextMenu:
+			return true, delSongFromUpNext(h.netease), app.Tick(time.Nanosecond)
+		case *LocalPlaylistDetailMenu:
+			return true, delSongFromLocalPlaylist(h.netease), app.Tick(time.Nanosecond)
+		}
 		newPage := delSongFromPlaylist(h.netease)
 		return true, newPage, app.Tick(time.Nanosecond)
 	case keybindings.OpAppendSongsToNext:
-		// 追加到下一曲播放
-		appendSongsToCurPlaylist(h.netease, true)
+		// 添加到待播队列开头，作为下一曲播放
+		addSongsToUpNext(h.netease, true)
 	case keybindings.OpAppendSongsToEnd:
-		// 追加到播放列表末尾
-		appendSongsToCurPlaylist(h.netease, false)
+		// 添加到待播队列末尾
+		addSongsToUpNext(h.netease, false)
 	case keybindings.OpClearSongCache:
 		// 清除歌曲缓存
 		clearSongCache(h.netease)
@@ -293,6 +315,15 @@ func (h *EventHandler) handle(op keybindings.OperateType) (bool, model.Page, tea
 			h.netease.notifyThemeSwitch(app, "切换主题", themeName)
 			return true, main, app.RerenderCmd(true)
 		}
+	case keybindings.OpToggleOffline:
+		toggleOffline(h.netease)
+		return true, main, app.RerenderCmd(true)
+	case keybindings.OpEqualizer:
+		return true, NewEqualizerPage(h.netease, main), app.RerenderCmd(true)
+	case keybindings.OpSleepTimer:
+		if _, ok := menu.(*SleepTimerMenu); !ok {
+			main.EnterMenu(NewSleepTimerMenu(newBaseMenu(h.netease)), &model.MenuItem{Title: "睡眠定时"})
+		}
 	default:
 		return false, nil, nil
 	}
@@ -372,7 +403,7 @@ func playOrToggle(netease *Netease, selectedIndex int) {
 
 	newPlaylist := make([]structs.Song, len(songs))
 	copy(newPlaylist, songs)
-	_ = player.playlistManager.Initialize(selectedIndex, newPlaylist)
+	player.InitSongManager(selectedIndex, newPlaylist)
 
 	player.playingMenuKey = menu.GetMenuKey()
 	if me, ok := menu.(Menu); ok {
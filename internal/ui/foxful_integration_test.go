@@ -129,6 +129,25 @@ func TestMainMenuCheckUpdateActionReturnsNotificationCommand(t *testing.T) {
 	}
 }
 
+func TestMainMenuKeepsExistingEntryPositions(t *testing.T) {
+	_, netease := newFormPageTestApp(t)
+	menu := NewMainMenu(netease)
+	// 新增菜单项只能加在帮助之前，原有菜单项的序号不能变
+	existing := []string{"每日推荐歌曲", "每日推荐歌单", "我的歌单", "我的收藏", "私人FM", "专辑列表", "搜索", "排行榜", "精选歌单", "热门歌手", "最近播放歌曲", "云盘", "主播电台", "LastFM"}
+	for i, title := range existing {
+		if menu.menus[i].Title != title {
+			t.Fatalf("menus[%d] = %q, want %q", i, menu.menus[i].Title, title)
+		}
+	}
+	n := len(menu.menus)
+	if menu.menus[n-2].Title != "帮助" || menu.menus[n-1].Title != "检查更新" {
+		t.Fatalf("last entries = %q, %q, want help and check update", menu.menus[n-2].Title, menu.menus[n-1].Title)
+	}
+	if len(menu.entries) != n {
+		t.Fatalf("%d entries for %d menu items", len(menu.entries), n)
+	}
+}
+
 func TestCheckUpdateNotificationMsgCoversAllResults(t *testing.T) {
 	tests := []struct {
 		name          string
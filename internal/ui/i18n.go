@@ -73,6 +73,7 @@ const (
 	MsgOperationCacheClearFailed model.MessageID = "operation.cache.clear_failed"
 
 	MsgMenuCurrentPlaylist    model.MessageID = "menu.current_playlist"
+	MsgMenuUpNext             model.MessageID = "menu.up_next"
 	MsgMenuSimilarSongs       model.MessageID = "menu.similar_songs"
 	MsgMenuMyPlaylists        model.MessageID = "menu.my_playlists"
 	MsgMenuSearchResult       model.MessageID = "menu.search_result"
@@ -172,6 +173,7 @@ var zhMessages = map[model.MessageID]string{
 	MsgOperationCacheClearFailed: "清除缓存失败",
 
 	MsgMenuCurrentPlaylist:    "当前播放列表",
+	MsgMenuUpNext:             "待播队列",
 	MsgMenuSimilarSongs:       "相似歌曲",
 	MsgMenuMyPlaylists:        "我的歌单",
 	MsgMenuSearchResult:       "搜索结果",
@@ -12,6 +12,8 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -223,8 +225,12 @@ func (c *ImageCache) touch(key string) {
 	}
 }
 
-// fetchImage downloads an image from the given URL.
+// fetchImage downloads an image from the given URL. file:// URLs are read from disk.
 func (c *ImageCache) fetchImage(ctx context.Context, url string) (image.Image, error) {
+	if path, ok := strings.CutPrefix(url, "file://"); ok {
+		return decodeImageFile(path)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -251,6 +257,20 @@ func (c *ImageCache) fetchImage(ctx context.Context, url string) (image.Image, e
 	return img, nil
 }
 
+func decodeImageFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(io.LimitReader(f, 10*1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
 // Clear removes all entries from the cache.
 func (c *ImageCache) Clear() {
 	c.mu.Lock()
@@ -0,0 +1,295 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/anhoder/foxful-cli/model"
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/playlistfile"
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+	"github.com/go-musicfox/go-musicfox/utils/app"
+	"github.com/go-musicfox/go-musicfox/utils/notify"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// loadLocalPlaylists 读取全部本地歌单
+func loadLocalPlaylists() []storage.LocalPlaylist {
+	playlists, err := storage.LocalPlaylists{}.All()
+	if err != nil {
+		// 尚未创建过本地歌单时桶不存在
+		slog.Debug("读取本地歌单失败", slogx.Error(err))
+	}
+	return playlists
+}
+
+// saveLocalPlaylist 保存歌单修改
+func saveLocalPlaylist(playlist *storage.LocalPlaylist) error {
+	playlist.UpdatedAt = time.Now().Unix()
+	if err := (storage.LocalPlaylists{}).Put(*playlist); err != nil {
+		slog.Error("保存本地歌单失败", "name", playlist.Name, slogx.Error(err))
+		notifyLocalPlaylist("保存本地歌单失败", err.Error(), notify.ToastError)
+		return err
+	}
+	return nil
+}
+
+// createLocalPlaylist 新建歌单，不允许与已有歌单重名
+func createLocalPlaylist(name string, songs []structs.Song) (storage.LocalPlaylist, error) {
+	for _, playlist := range loadLocalPlaylists() {
+		if playlist.Name == name {
+			return storage.LocalPlaylist{}, errors.Errorf("已存在同名歌单「%s」", name)
+		}
+	}
+	now := time.Now().Unix()
+	playlist := storage.LocalPlaylist{Name: name, Songs: songs, CreatedAt: now, UpdatedAt: now}
+	if err := (storage.LocalPlaylists{}).Add(&playlist); err != nil {
+		slog.Error("新建本地歌单失败", "name", name, slogx.Error(err))
+		return playlist, err
+	}
+	return playlist, nil
+}
+
+// uniqueLocalPlaylistName 与已有歌单重名时添加序号
+func uniqueLocalPlaylistName(name string) string {
+	names := make(map[string]struct{})
+	for _, playlist := range loadLocalPlaylists() {
+		names[playlist.Name] = struct{}{}
+	}
+	unique := name
+	for i := 2; ; i++ {
+		if _, ok := names[unique]; !ok {
+			return unique
+		}
+		unique = fmt.Sprintf("%s (%d)", name, i)
+	}
+}
+
+func notifyLocalPlaylist(title, text string, level notify.ToastLevel) {
+	notify.Notify(notify.NotifyContent{
+		Title:   title,
+		Text:    text,
+		GroupId: types.GroupID,
+		Level:   level,
+	})
+}
+
+// refreshLocalPlaylistMenus 修改歌单后刷新当前所在的本地歌单界面
+func refreshLocalPlaylistMenus(n *Netease) {
+	main := n.MustMain()
+	switch menu := main.CurMenu().(type) {
+	case *LocalPlaylistsMenu:
+		menu.refresh()
+	case *LocalPlaylistDetailMenu:
+		if playlist, err := (storage.LocalPlaylists{}).Get(menu.playlist.ID); err == nil {
+			menu.playlist = playlist
+		}
+		menu.refresh()
+	default:
+		return
+	}
+	main.RefreshMenuList()
+	if count := len(main.CurMenu().MenuViews()); main.SelectedIndex() >= count {
+		main.SetSelectedIndex(max(count-1, 0))
+	}
+}
+
+func newCreateLocalPlaylistPage(n *Netease, menu *LocalPlaylistsMenu) model.Page {
+	title := &model.MenuItem{Title: "本地歌单", Subtitle: "新建歌单"}
+	return NewTextInputPage(n, title, "歌单名称", "", func(name string) error {
+		playlist, err := createLocalPlaylist(name, nil)
+		if err != nil {
+			return err
+		}
+		menu.refresh()
+		n.MustMain().RefreshMenuList()
+		notifyLocalPlaylist("已新建本地歌单", playlist.Name, notify.ToastSuccess)
+		return nil
+	})
+}
+
+func newImportLocalPlaylistPage(n *Netease, menu *LocalPlaylistsMenu) model.Page {
+	title := &model.MenuItem{Title: "本地歌单", Subtitle: "导入 M3U8 / XSPF / JSON 歌单文件"}
+	return NewTextInputPage(n, title, "歌单文件路径", "", func(path string) error {
+		imported, err := playlistfile.Import(expandHomeDir(path))
+		if err != nil {
+			return err
+		}
+		if len(imported.Songs) == 0 {
+			return errors.New("文件中没有可识别的歌曲")
+		}
+		playlist, err := createLocalPlaylist(uniqueLocalPlaylistName(imported.Name), imported.Songs)
+		if err != nil {
+			return err
+		}
+		text := fmt.Sprintf("%s（%d 首）", playlist.Name, len(playlist.Songs))
+		if imported.Skipped > 0 {
+			text += fmt.Sprintf("，%d 条无法识别已跳过", imported.Skipped)
+		}
+		notifyLocalPlaylist("已导入本地歌单", text, notify.ToastSuccess)
+		menu.refresh()
+		n.MustMain().RefreshMenuList()
+		return nil
+	})
+}
+
+func newRenameLocalPlaylistPage(n *Netease, playlist storage.LocalPlaylist) model.Page {
+	title := &model.MenuItem{Title: "本地歌单", Subtitle: "重命名「" + playlist.Name + "」"}
+	return NewTextInputPage(n, title, "歌单名称", playlist.Name, func(name string) error {
+		if name == playlist.Name {
+			return nil
+		}
+		for _, other := range loadLocalPlaylists() {
+			if other.ID != playlist.ID && other.Name == name {
+				return errors.Errorf("已存在同名歌单「%s」", name)
+			}
+		}
+		latest, err := storage.LocalPlaylists{}.Get(playlist.ID)
+		if err != nil {
+			return err
+		}
+		latest.Name = name
+		if err = saveLocalPlaylist(&latest); err != nil {
+			return err
+		}
+		refreshLocalPlaylistMenus(n)
+		return nil
+	})
+}
+
+func newExportLocalPlaylistPage(n *Netease, playlist storage.LocalPlaylist) model.Page {
+	title := &model.MenuItem{Title: "本地歌单", Subtitle: "导出「" + playlist.Name + "」，扩展名决定格式"}
+	fileName := strings.NewReplacer("/", "_", "\\", "_").Replace(playlist.Name) + "." + string(playlistfile.FormatM3U8)
+	defaultPath := filepath.Join(app.DownloadDir(), fileName)
+	return NewTextInputPage(n, title, "导出文件路径", defaultPath, func(path string) error {
+		path = expandHomeDir(path)
+		latest, err := storage.LocalPlaylists{}.Get(playlist.ID)
+		if err != nil {
+			return err
+		}
+		if err = playlistfile.Export(path, playlistfile.Playlist{Name: latest.Name, Songs: latest.Songs}); err != nil {
+			return err
+		}
+		notifyLocalPlaylist("已导出本地歌单", fmt.Sprintf("%s（%d 首）→ %s", latest.Name, len(latest.Songs), path), notify.ToastSuccess)
+		return nil
+	})
+}
+
+// confirmDeleteLocalPlaylist 确认后删除歌单，删除当前所在歌单时返回上一级
+func confirmDeleteLocalPlaylist(n *Netease, playlist storage.LocalPlaylist) {
+	content := fmt.Sprintf("确定删除本地歌单「%s」（%d 首）吗？歌曲文件不会被删除。", playlist.Name, len(playlist.Songs))
+	showConfirmPopup(n.App, "删除本地歌单", content, func() {
+		if err := (storage.LocalPlaylists{}).Remove(playlist.ID); err != nil {
+			slog.Error("删除本地歌单失败", "name", playlist.Name, slogx.Error(err))
+			notifyLocalPlaylist("删除本地歌单失败", err.Error(), notify.ToastError)
+			return
+		}
+		main := n.MustMain()
+		if menu, ok := main.CurMenu().(*LocalPlaylistDetailMenu); ok && menu.playlist.ID == playlist.ID {
+			main.BackMenu()
+		}
+		refreshLocalPlaylistMenus(n)
+		notifyLocalPlaylist("已删除本地歌单", playlist.Name, notify.ToastSuccess)
+		n.App.Rerender(false)
+	})
+}
+
+// openAddSongToLocalPlaylistMenu 打开“添加至本地歌单”菜单
+func openAddSongToLocalPlaylistMenu(n *Netease, isSelected bool) model.Page {
+	song, ok := getTargetSong(n, isSelected)
+	if !ok {
+		return nil
+	}
+	main := n.MustMain()
+	if _, ok := main.CurMenu().(*AddToLocalPlaylistMenu); ok {
+		return nil // 避免重复进入
+	}
+	newTitle := &model.MenuItem{Title: "本地歌单", Subtitle: "将「" + song.Name + "」加入本地歌单"}
+	main.EnterMenu(NewAddToLocalPlaylistMenu(newBaseMenu(n), song), newTitle)
+	return nil
+}
+
+// addSongToLocalPlaylist 将歌曲追加到歌单末尾，已在歌单中时不重复添加
+func addSongToLocalPlaylist(playlist storage.LocalPlaylist, song structs.Song) {
+	latest, err := storage.LocalPlaylists{}.Get(playlist.ID)
+	if err != nil {
+		slog.Error("读取本地歌单失败", "id", playlist.ID, slogx.Error(err))
+		notifyLocalPlaylist("加入本地歌单失败", err.Error(), notify.ToastError)
+		return
+	}
+	if slices.ContainsFunc(latest.Songs, func(s structs.Song) bool { return s.Id == song.Id }) {
+		notifyLocalPlaylist("歌曲已在本地歌单「"+latest.Name+"」中", song.Name, notify.ToastWarning)
+		return
+	}
+	latest.Songs = append(latest.Songs, song)
+	if saveLocalPlaylist(&latest) != nil {
+		return
+	}
+	notifyLocalPlaylist("已添加到本地歌单「"+latest.Name+"」", song.Name, notify.ToastSuccess)
+}
+
+// delSongFromLocalPlaylist 从当前本地歌单中移除选中歌曲
+func delSongFromLocalPlaylist(n *Netease) model.Page {
+	main := n.MustMain()
+	menu, ok := main.CurMenu().(*LocalPlaylistDetailMenu)
+	if !ok {
+		return nil
+	}
+	index := menu.RealDataIndex(main.SelectedIndex())
+	if index < 0 || index >= len(menu.playlist.Songs) {
+		return nil
+	}
+	playlist := menu.playlist
+	playlist.Songs = slices.Delete(slices.Clone(playlist.Songs), index, index+1)
+	if saveLocalPlaylist(&playlist) != nil {
+		return nil
+	}
+	refreshLocalPlaylistMenus(n)
+	return nil
+}
+
+// moveSongInLocalPlaylist 在本地歌单中上移（offset 为负）或下移选中歌曲
+func moveSongInLocalPlaylist(n *Netease, offset int) {
+	main := n.MustMain()
+	menu, ok := main.CurMenu().(*LocalPlaylistDetailMenu)
+	if !ok {
+		return
+	}
+	from := menu.RealDataIndex(main.SelectedIndex())
+	to := from + offset
+	songs := menu.playlist.Songs
+	if from < 0 || from >= len(songs) || to < 0 || to >= len(songs) {
+		return
+	}
+	playlist := menu.playlist
+	playlist.Songs = slices.Clone(songs)
+	playlist.Songs[from], playlist.Songs[to] = playlist.Songs[to], playlist.Songs[from]
+	if saveLocalPlaylist(&playlist) != nil {
+		return
+	}
+	refreshLocalPlaylistMenus(n)
+	// 搜索结果中移动后不跟随光标
+	if main.SelectedIndex() == from {
+		main.SetSelectedIndex(to)
+	}
+}
+
+// expandHomeDir 展开路径开头的 ~
+func expandHomeDir(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/anhoder/foxful-cli/model"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/player"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+	mfoxapp "github.com/go-musicfox/go-musicfox/utils/app"
+	"github.com/go-musicfox/go-musicfox/utils/notify"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+	_struct "github.com/go-musicfox/go-musicfox/utils/struct"
+)
+
+// DlnaRenderersMenu 搜索并切换 DLNA 投送设备，仅 dlna 引擎可用
+type DlnaRenderersMenu struct {
+	baseMenu
+	menus     []model.MenuItem
+	renderers []player.DlnaRenderer
+}
+
+func NewDlnaRenderersMenu(base baseMenu) *DlnaRenderersMenu {
+	return &DlnaRenderersMenu{baseMenu: base}
+}
+
+func (m *DlnaRenderersMenu) GetMenuKey() string {
+	return "dlna_renderers"
+}
+
+func (m *DlnaRenderersMenu) rendererPlayer() (player.RendererPlayer, bool) {
+	if m.netease.player == nil {
+		return nil, false
+	}
+	r, ok := m.netease.player.Player.(player.RendererPlayer)
+	return r, ok
+}
+
+func (m *DlnaRenderersMenu) FormatMenuItem(item *model.MenuItem) {
+	r, ok := m.rendererPlayer()
+	if !ok {
+		item.Subtitle = "[仅 dlna 引擎]"
+		return
+	}
+	if cur, connected := r.Renderer(); connected {
+		item.Subtitle = "[" + _struct.ReplaceSpecialStr(cur.Name) + "]"
+	} else {
+		item.Subtitle = "[未连接]"
+	}
+}
+
+func (m *DlnaRenderersMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *DlnaRenderersMenu) BeforeEnterMenuHook() model.Hook {
+	return func(main *model.Main) (bool, model.Page) {
+		r, ok := m.rendererPlayer()
+		if !ok {
+			notify.Notify(notify.NotifyContent{
+				Title:   "投送设备",
+				Text:    "请先将 player.engine 设置为 dlna",
+				GroupId: types.GroupID,
+				Level:   notify.ToastWarning,
+			})
+			return false, nil
+		}
+		renderers, err := r.DiscoverRenderers(context.Background())
+		if err != nil {
+			slog.Warn("搜索 DLNA 设备失败", slogx.Error(err))
+		}
+		m.renderers = renderers
+		m.refresh(r)
+		return true, nil
+	}
+}
+
+func (m *DlnaRenderersMenu) refresh(r player.RendererPlayer) {
+	cur, _ := r.Renderer()
+	m.menus = make([]model.MenuItem, 0, len(m.renderers))
+	for _, renderer := range m.renderers {
+		subtitle := renderer.Host()
+		if renderer.Model != "" {
+			subtitle = renderer.Model + " " + subtitle
+		}
+		if renderer.Location == cur.Location {
+			subtitle = "[当前] " + subtitle
+		}
+		m.menus = append(m.menus, model.MenuItem{
+			Title:    _struct.ReplaceSpecialStr(renderer.Name),
+			Subtitle: _struct.ReplaceSpecialStr(subtitle),
+		})
+	}
+}
+
+func (m *DlnaRenderersMenu) SubMenu(app *model.App, index int) model.Menu {
+	r, ok := m.rendererPlayer()
+	if !ok || index < 0 || index >= len(m.renderers) {
+		return nil
+	}
+	renderer := m.renderers[index]
+	content := notify.NotifyContent{Title: "投送设备", GroupId: types.GroupID}
+	if err := r.SwitchRenderer(renderer.Location); err != nil {
+		content.Text, content.Level = "连接 "+renderer.Name+" 失败: "+err.Error(), notify.ToastError
+		notify.Notify(content)
+		return nil
+	}
+
+	// 记住选择的设备，下次启动优先连接
+	if err := configs.SetTOMLValue(mfoxapp.ConfigFilePath(), []string{"player", "dlna", "deviceUrl"}, renderer.Location); err != nil {
+		slog.Error("Failed to persist DLNA renderer", slogx.Error(err))
+	}
+	if configs.AppConfig != nil {
+		configs.AppConfig.Player.Dlna.DeviceUrl = renderer.Location
+	}
+	content.Text = "已切换到 " + renderer.Name
+	notify.Notify(content)
+
+	m.refresh(r)
+	app.MustMain().RefreshMenuList()
+	return nil
+}
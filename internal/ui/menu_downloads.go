@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anhoder/foxful-cli/model"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	_struct "github.com/go-musicfox/go-musicfox/utils/struct"
+)
+
+// DownloadsMenu 下载管理，列出进行中、已完成和失败的下载任务
+type DownloadsMenu struct {
+	baseMenu
+	jobs []storage.DownloadJob
+}
+
+func NewDownloadsMenu(base baseMenu) *DownloadsMenu {
+	return &DownloadsMenu{baseMenu: base}
+}
+
+func (m *DownloadsMenu) IsSearchable() bool {
+	return true
+}
+
+func (m *DownloadsMenu) GetMenuKey() string {
+	return "downloads"
+}
+
+func (m *DownloadsMenu) FormatMenuItem(item *model.MenuItem) {
+	var running, failed int
+	for _, job := range m.netease.downloadMgr.Jobs() {
+		switch job.State {
+		case storage.DownloadDone:
+		case storage.DownloadFailed:
+			failed++
+		default:
+			running++
+		}
+	}
+	item.Subtitle = fmt.Sprintf("[%d 进行中, %d 失败]", running, failed)
+}
+
+// MenuViews 每次刷新时读取最新的任务状态
+func (m *DownloadsMenu) MenuViews() []model.MenuItem {
+	m.jobs = m.netease.downloadMgr.Jobs()
+	menus := make([]model.MenuItem, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		var artists []string
+		for _, artist := range job.Song.Artists {
+			artists = append(artists, artist.Name)
+		}
+		title := job.Song.Name
+		if len(artists) > 0 {
+			title += " - " + strings.Join(artists, ",")
+		}
+		menus = append(menus, model.MenuItem{
+			Title:    _struct.ReplaceSpecialStr(title),
+			Subtitle: _struct.ReplaceSpecialStr(downloadJobStatus(job)),
+		})
+	}
+	return menus
+}
+
+// Jobs 最近一次刷新时的任务列表，与菜单项一一对应
+func (m *DownloadsMenu) Jobs() []storage.DownloadJob {
+	return m.jobs
+}
+
+// downloadJobStatus 任务状态描述，如 [下载中 45% 3.2/7.1MB]
+func downloadJobStatus(job storage.DownloadJob) string {
+	switch job.State {
+	case storage.DownloadQueued:
+		return "[等待下载]"
+	case storage.DownloadRunning:
+		if job.Total > 0 {
+			return fmt.Sprintf("[下载中 %d%% %s/%s]", job.Downloaded*100/job.Total, formatMB(job.Downloaded), formatMB(job.Total)+"MB")
+		}
+		return fmt.Sprintf("[下载中 %sMB]", formatMB(job.Downloaded))
+	case storage.DownloadRetrying:
+		return fmt.Sprintf("[等待重试 第%d次失败: %s]", job.Attempts, job.Error)
+	case storage.DownloadDone:
+		if job.Existed {
+			return "[已存在]"
+		}
+		return "[已完成]"
+	case storage.DownloadFailed:
+		return fmt.Sprintf("[失败: %s]", job.Error)
+	}
+	return ""
+}
+
+func formatMB(bytes int64) string {
+	return fmt.Sprintf("%.1f", float64(bytes)/(1<<20))
+}
@@ -0,0 +1,224 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhoder/foxful-cli/model"
+
+	"github.com/go-musicfox/go-musicfox/internal/history"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+	_struct "github.com/go-musicfox/go-musicfox/utils/struct"
+)
+
+// listenStatsTopN 排行最多展示的条目数
+const listenStatsTopN = 100
+
+// ListenStatsMenu 听歌统计，数据来自本地听歌记录
+type ListenStatsMenu struct {
+	baseMenu
+	menus []model.MenuItem
+	stats []history.Stats
+}
+
+func NewListenStatsMenu(base baseMenu) *ListenStatsMenu {
+	return &ListenStatsMenu{baseMenu: base}
+}
+
+func (m *ListenStatsMenu) GetMenuKey() string {
+	return "listen_stats"
+}
+
+func (m *ListenStatsMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *ListenStatsMenu) BeforeEnterMenuHook() model.Hook {
+	return func(main *model.Main) (bool, model.Page) {
+		stats, err := history.Load(time.Now())
+		if err != nil {
+			// 还没有任何播放记录时桶不存在
+			slog.Debug("读取听歌记录失败", slogx.Error(err))
+			stats = make([]history.Stats, len(history.Periods))
+		}
+		m.stats = stats
+
+		m.menus = make([]model.MenuItem, 0, len(history.Periods))
+		for i, period := range history.Periods {
+			m.menus = append(m.menus, model.MenuItem{
+				Title:    period.Name(),
+				Subtitle: listenStatsSummary(stats[i]),
+			})
+		}
+		return true, nil
+	}
+}
+
+func (m *ListenStatsMenu) SubMenu(_ *model.App, index int) model.Menu {
+	if index < 0 || index >= len(m.stats) {
+		return nil
+	}
+	return NewListenStatsPeriodMenu(m.baseMenu, history.Periods[index], m.stats[index])
+}
+
+// listenStatsSummary 如 "[12 次播放 · 3小时25分钟]"
+func listenStatsSummary(stats history.Stats) string {
+	return fmt.Sprintf("[%d 次播放 · %s]", stats.Plays, history.FormatDuration(stats.Listened))
+}
+
+// ListenStatsPeriodMenu 一个周期内的排行入口
+type ListenStatsPeriodMenu struct {
+	baseMenu
+	period history.Period
+	stats  history.Stats
+	menus  []model.MenuItem
+}
+
+func NewListenStatsPeriodMenu(base baseMenu, period history.Period, stats history.Stats) *ListenStatsPeriodMenu {
+	skipRate := 0.0
+	if stats.Plays > 0 {
+		skipRate = float64(stats.Skips) / float64(stats.Plays)
+	}
+	return &ListenStatsPeriodMenu{
+		baseMenu: base,
+		period:   period,
+		stats:    stats,
+		menus: []model.MenuItem{
+			{Title: "最常听的歌曲", Subtitle: fmt.Sprintf("[%d 首]", len(stats.Songs))},
+			{Title: "最常听的歌手", Subtitle: fmt.Sprintf("[%d 位]", len(stats.Artists))},
+			{Title: "最常听的专辑", Subtitle: fmt.Sprintf("[%d 张]", len(stats.Albums))},
+			{Title: "跳过率排行", Subtitle: fmt.Sprintf("[跳过 %d 次 · %.0f%%]", stats.Skips, skipRate*100)},
+		},
+	}
+}
+
+func (m *ListenStatsPeriodMenu) GetMenuKey() string {
+	return fmt.Sprintf("listen_stats_%d", m.period)
+}
+
+func (m *ListenStatsPeriodMenu) FormatMenuItem(item *model.MenuItem) {
+	item.Subtitle = listenStatsSummary(m.stats)
+}
+
+func (m *ListenStatsPeriodMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *ListenStatsPeriodMenu) SubMenu(_ *model.App, index int) model.Menu {
+	key := m.GetMenuKey()
+	switch index {
+	case 0:
+		return NewListenStatsSongsMenu(m.baseMenu, key+"_songs", m.stats.Songs, false)
+	case 1:
+		return NewListenStatsRankMenu(m.baseMenu, key+"_artists", m.stats.Artists, true)
+	case 2:
+		return NewListenStatsRankMenu(m.baseMenu, key+"_albums", m.stats.Albums, false)
+	case 3:
+		return NewListenStatsSongsMenu(m.baseMenu, key+"_skips", m.stats.SkipRanked, true)
+	}
+	return nil
+}
+
+// ListenStatsSongsMenu 歌曲排行，可直接播放
+type ListenStatsSongsMenu struct {
+	baseMenu
+	key   string
+	menus []model.MenuItem
+	songs []structs.Song
+}
+
+// NewListenStatsSongsMenu 歌曲排行，skips 为 true 时展示跳过次数
+func NewListenStatsSongsMenu(base baseMenu, key string, counts []history.Count, skips bool) *ListenStatsSongsMenu {
+	counts = counts[:min(len(counts), listenStatsTopN)]
+	m := &ListenStatsSongsMenu{
+		baseMenu: base,
+		key:      key,
+		menus:    make([]model.MenuItem, 0, len(counts)),
+		songs:    make([]structs.Song, 0, len(counts)),
+	}
+	for _, c := range counts {
+		stat := fmt.Sprintf("%d 次", c.Plays)
+		if skips {
+			stat = fmt.Sprintf("跳过 %d/%d · %.0f%%", c.Skips, c.Plays, c.SkipRate()*100)
+		}
+		m.songs = append(m.songs, c.Song)
+		m.menus = append(m.menus, model.MenuItem{
+			Title:    _struct.ReplaceSpecialStr(c.Song.Name),
+			Subtitle: _struct.ReplaceSpecialStr(fmt.Sprintf("%s [%s]", c.Song.ArtistName(), stat)),
+		})
+	}
+	return m
+}
+
+func (m *ListenStatsSongsMenu) IsSearchable() bool {
+	return true
+}
+
+func (m *ListenStatsSongsMenu) IsPlayable() bool {
+	return true
+}
+
+func (m *ListenStatsSongsMenu) GetMenuKey() string {
+	return m.key
+}
+
+func (m *ListenStatsSongsMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *ListenStatsSongsMenu) Songs() []structs.Song {
+	return m.songs
+}
+
+// ListenStatsRankMenu 歌手或专辑排行，进入后打开歌手或专辑详情
+type ListenStatsRankMenu struct {
+	baseMenu
+	key     string
+	artists bool
+	counts  []history.Count
+	menus   []model.MenuItem
+}
+
+func NewListenStatsRankMenu(base baseMenu, key string, counts []history.Count, artists bool) *ListenStatsRankMenu {
+	counts = counts[:min(len(counts), listenStatsTopN)]
+	m := &ListenStatsRankMenu{
+		baseMenu: base,
+		key:      key,
+		artists:  artists,
+		counts:   counts,
+		menus:    make([]model.MenuItem, 0, len(counts)),
+	}
+	for _, c := range counts {
+		m.menus = append(m.menus, model.MenuItem{
+			Title:    _struct.ReplaceSpecialStr(c.Name),
+			Subtitle: fmt.Sprintf("[%d 次 · %s]", c.Plays, history.FormatDuration(c.Played)),
+		})
+	}
+	return m
+}
+
+func (m *ListenStatsRankMenu) IsSearchable() bool {
+	return true
+}
+
+func (m *ListenStatsRankMenu) GetMenuKey() string {
+	return m.key
+}
+
+func (m *ListenStatsRankMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *ListenStatsRankMenu) SubMenu(_ *model.App, index int) model.Menu {
+	// 本地歌曲的歌手、专辑没有 ID
+	if index < 0 || index >= len(m.counts) || m.counts[index].ID <= 0 {
+		return nil
+	}
+	c := m.counts[index]
+	if m.artists {
+		return NewArtistDetailMenu(m.baseMenu, c.ID, c.Name)
+	}
+	return NewAlbumDetailMenu(m.baseMenu, c.ID)
+}
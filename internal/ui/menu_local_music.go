@@ -0,0 +1,216 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/anhoder/foxful-cli/model"
+
+	"github.com/go-musicfox/go-musicfox/internal/library"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+	"github.com/go-musicfox/go-musicfox/utils/menux"
+	"github.com/go-musicfox/go-musicfox/utils/notify"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+type localGroupKind string
+
+const (
+	localGroupArtist localGroupKind = "artist"
+	localGroupAlbum  localGroupKind = "album"
+	localGroupFolder localGroupKind = "folder"
+)
+
+// LocalMusicMenu 本地音乐
+type LocalMusicMenu struct {
+	baseMenu
+}
+
+func NewLocalMusicMenu(base baseMenu) *LocalMusicMenu {
+	return &LocalMusicMenu{baseMenu: base}
+}
+
+func (m *LocalMusicMenu) GetMenuKey() string {
+	return "local_music"
+}
+
+func (m *LocalMusicMenu) MenuViews() []model.MenuItem {
+	return []model.MenuItem{
+		{Title: "全部歌曲"},
+		{Title: "按歌手"},
+		{Title: "按专辑"},
+		{Title: "按文件夹"},
+		{Title: "重新扫描"},
+	}
+}
+
+func (m *LocalMusicMenu) FormatMenuItem(item *model.MenuItem) {
+	lib := m.netease.localLibrary
+	if len(lib.Dirs()) == 0 {
+		item.Subtitle = "[未配置目录]"
+		return
+	}
+	item.Subtitle = fmt.Sprintf("[共 %d 首]", len(lib.Songs()))
+}
+
+func (m *LocalMusicMenu) BeforeEnterMenuHook() model.Hook {
+	return func(main *model.Main) (bool, model.Page) {
+		lib := m.netease.localLibrary
+		if len(lib.Dirs()) == 0 {
+			notify.Notify(notify.NotifyContent{
+				Title:   "未配置本地音乐目录",
+				Text:    "请在配置文件 [storage.local] 中设置 dirs",
+				GroupId: types.GroupID,
+				Level:   notify.ToastWarning,
+			})
+			return false, nil
+		}
+
+		lib.Load()
+		if lib.IsEmpty() {
+			if _, err := lib.Scan(context.Background()); err != nil {
+				slog.Error("扫描本地音乐失败", slogx.Error(err))
+			}
+		}
+		return true, nil
+	}
+}
+
+func (m *LocalMusicMenu) SubMenu(_ *model.App, index int) model.Menu {
+	switch index {
+	case 0:
+		return NewLocalSongsMenu(m.baseMenu, "local_music_all", m.netease.localLibrary.Songs())
+	case 1:
+		return NewLocalGroupsMenu(m.baseMenu, localGroupArtist)
+	case 2:
+		return NewLocalGroupsMenu(m.baseMenu, localGroupAlbum)
+	case 3:
+		return NewLocalGroupsMenu(m.baseMenu, localGroupFolder)
+	case 4:
+		m.rescan()
+	}
+	return nil
+}
+
+func (m *LocalMusicMenu) rescan() {
+	lib := m.netease.localLibrary
+	loading := model.NewLoading(m.netease.MustMain())
+	loading.Start()
+	defer loading.Complete()
+
+	result, err := lib.Scan(context.Background())
+	if err != nil {
+		slog.Error("扫描本地音乐失败", slogx.Error(err))
+		notify.Notify(notify.NotifyContent{
+			Title:   "扫描本地音乐失败",
+			Text:    err.Error(),
+			GroupId: types.GroupID,
+			Level:   notify.ToastError,
+		})
+		return
+	}
+	notify.Notify(notify.NotifyContent{
+		Title:   "本地音乐扫描完成",
+		Text:    fmt.Sprintf("共 %d 首，新增 %d，更新 %d，移除 %d", result.Total, result.Added, result.Updated, result.Removed),
+		GroupId: types.GroupID,
+		Level:   notify.ToastSuccess,
+	})
+	m.netease.MustMain().RefreshMenuTitle()
+}
+
+// LocalGroupsMenu 按歌手、专辑或文件夹聚合的本地音乐
+type LocalGroupsMenu struct {
+	baseMenu
+	kind   localGroupKind
+	menus  []model.MenuItem
+	groups []library.Group
+}
+
+func NewLocalGroupsMenu(base baseMenu, kind localGroupKind) *LocalGroupsMenu {
+	return &LocalGroupsMenu{
+		baseMenu: base,
+		kind:     kind,
+	}
+}
+
+func (m *LocalGroupsMenu) IsSearchable() bool {
+	return true
+}
+
+func (m *LocalGroupsMenu) GetMenuKey() string {
+	return "local_music_" + string(m.kind)
+}
+
+func (m *LocalGroupsMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *LocalGroupsMenu) BeforeEnterMenuHook() model.Hook {
+	return func(main *model.Main) (bool, model.Page) {
+		lib := m.netease.localLibrary
+		switch m.kind {
+		case localGroupArtist:
+			m.groups = lib.Artists()
+		case localGroupAlbum:
+			m.groups = lib.Albums()
+		default:
+			m.groups = lib.Folders()
+		}
+
+		m.menus = make([]model.MenuItem, 0, len(m.groups))
+		for _, group := range m.groups {
+			m.menus = append(m.menus, model.MenuItem{
+				Title:    group.Name,
+				Subtitle: fmt.Sprintf("[%d 首]", len(group.Songs)),
+			})
+		}
+		return true, nil
+	}
+}
+
+func (m *LocalGroupsMenu) SubMenu(_ *model.App, index int) model.Menu {
+	if index < 0 || index >= len(m.groups) {
+		return nil
+	}
+	key := fmt.Sprintf("local_music_%s_%d", m.kind, index)
+	return NewLocalSongsMenu(m.baseMenu, key, m.groups[index].Songs)
+}
+
+// LocalSongsMenu 本地音乐歌曲列表
+type LocalSongsMenu struct {
+	baseMenu
+	key   string
+	menus []model.MenuItem
+	songs []structs.Song
+}
+
+func NewLocalSongsMenu(base baseMenu, key string, songs []structs.Song) *LocalSongsMenu {
+	return &LocalSongsMenu{
+		baseMenu: base,
+		key:      key,
+		menus:    menux.GetViewFromSongs(songs),
+		songs:    songs,
+	}
+}
+
+func (m *LocalSongsMenu) IsSearchable() bool {
+	return true
+}
+
+func (m *LocalSongsMenu) IsPlayable() bool {
+	return true
+}
+
+func (m *LocalSongsMenu) GetMenuKey() string {
+	return m.key
+}
+
+func (m *LocalSongsMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *LocalSongsMenu) Songs() []structs.Song {
+	return m.songs
+}
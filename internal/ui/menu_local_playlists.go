@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/anhoder/foxful-cli/model"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/utils/menux"
+	"github.com/go-musicfox/go-musicfox/utils/notify"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+	_struct "github.com/go-musicfox/go-musicfox/utils/struct"
+)
+
+const localPlaylistsMenuKey = "local_playlists"
+
+// LocalPlaylistsMenu 本地歌单，列表末尾为新建、导入入口
+type LocalPlaylistsMenu struct {
+	baseMenu
+	menus     []model.MenuItem
+	playlists []storage.LocalPlaylist
+}
+
+func NewLocalPlaylistsMenu(base baseMenu) *LocalPlaylistsMenu {
+	return &LocalPlaylistsMenu{baseMenu: base}
+}
+
+func (m *LocalPlaylistsMenu) IsSearchable() bool {
+	return true
+}
+
+func (m *LocalPlaylistsMenu) GetMenuKey() string {
+	return localPlaylistsMenuKey
+}
+
+func (m *LocalPlaylistsMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *LocalPlaylistsMenu) BeforeEnterMenuHook() model.Hook {
+	return func(main *model.Main) (bool, model.Page) {
+		m.refresh()
+		return true, nil
+	}
+}
+
+func (m *LocalPlaylistsMenu) refresh() {
+	m.playlists = loadLocalPlaylists()
+	m.menus = make([]model.MenuItem, 0, len(m.playlists)+2)
+	for _, playlist := range m.playlists {
+		m.menus = append(m.menus, model.MenuItem{
+			Title:    _struct.ReplaceSpecialStr(playlist.Name),
+			Subtitle: fmt.Sprintf("[%d 首]", len(playlist.Songs)),
+		})
+	}
+	m.menus = append(m.menus,
+		model.MenuItem{Title: "新建歌单"},
+		model.MenuItem{Title: "导入歌单文件", Subtitle: "[M3U8 / XSPF / JSON]"},
+	)
+}
+
+func (m *LocalPlaylistsMenu) SubMenu(_ *model.App, index int) model.Menu {
+	switch {
+	case index >= 0 && index < len(m.playlists):
+		return NewLocalPlaylistDetailMenu(m.baseMenu, m.playlists[index])
+	case index == len(m.playlists):
+		return NewMenuToPage(m.baseMenu, newCreateLocalPlaylistPage(m.netease, m))
+	case index == len(m.playlists)+1:
+		return NewMenuToPage(m.baseMenu, newImportLocalPlaylistPage(m.netease, m))
+	}
+	return nil
+}
+
+// Playlist 返回选中的歌单，新建、导入入口返回 false
+func (m *LocalPlaylistsMenu) Playlist(index int) (storage.LocalPlaylist, bool) {
+	if index < 0 || index >= len(m.playlists) {
+		return storage.LocalPlaylist{}, false
+	}
+	return m.playlists[index], true
+}
+
+// LocalPlaylistDetailMenu 本地歌单中的歌曲
+type LocalPlaylistDetailMenu struct {
+	baseMenu
+	playlist storage.LocalPlaylist
+	menus    []model.MenuItem
+}
+
+func NewLocalPlaylistDetailMenu(base baseMenu, playlist storage.LocalPlaylist) *LocalPlaylistDetailMenu {
+	return &LocalPlaylistDetailMenu{baseMenu: base, playlist: playlist}
+}
+
+func (m *LocalPlaylistDetailMenu) IsSearchable() bool {
+	return true
+}
+
+func (m *LocalPlaylistDetailMenu) IsPlayable() bool {
+	return true
+}
+
+func (m *LocalPlaylistDetailMenu) GetMenuKey() string {
+	return fmt.Sprintf("local_playlist_%d", m.playlist.ID)
+}
+
+func (m *LocalPlaylistDetailMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *LocalPlaylistDetailMenu) BeforeEnterMenuHook() model.Hook {
+	return func(main *model.Main) (bool, model.Page) {
+		// 歌单可能在别处被修改，进入时重新读取
+		if playlist, err := (storage.LocalPlaylists{}).Get(m.playlist.ID); err == nil {
+			m.playlist = playlist
+		} else {
+			slog.Warn("读取本地歌单失败", "id", m.playlist.ID, slogx.Error(err))
+		}
+		m.refresh()
+		return true, nil
+	}
+}
+
+func (m *LocalPlaylistDetailMenu) refresh() {
+	if m.netease.IsOffline() {
+		m.menus = offlineSongViews(m.netease.trackManager, m.playlist.Songs)
+		return
+	}
+	m.menus = menux.GetViewFromSongs(m.playlist.Songs)
+}
+
+func (m *LocalPlaylistDetailMenu) Songs() []structs.Song {
+	return m.playlist.Songs
+}
+
+// Playlist 当前歌单
+func (m *LocalPlaylistDetailMenu) Playlist() storage.LocalPlaylist {
+	return m.playlist
+}
+
+// AddToLocalPlaylistMenu 选择要加入歌曲的本地歌单
+type AddToLocalPlaylistMenu struct {
+	baseMenu
+	song      structs.Song
+	menus     []model.MenuItem
+	playlists []storage.LocalPlaylist
+}
+
+func NewAddToLocalPlaylistMenu(base baseMenu, song structs.Song) *AddToLocalPlaylistMenu {
+	return &AddToLocalPlaylistMenu{baseMenu: base, song: song}
+}
+
+func (m *AddToLocalPlaylistMenu) IsSearchable() bool {
+	return true
+}
+
+func (m *AddToLocalPlaylistMenu) GetMenuKey() string {
+	return "add_to_local_playlist"
+}
+
+func (m *AddToLocalPlaylistMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *AddToLocalPlaylistMenu) BeforeEnterMenuHook() model.Hook {
+	return func(main *model.Main) (bool, model.Page) {
+		m.playlists = loadLocalPlaylists()
+		if len(m.playlists) == 0 {
+			notifyLocalPlaylist("还没有本地歌单", "请先在「本地歌单」中新建歌单", notify.ToastWarning)
+			return false, nil
+		}
+		m.menus = make([]model.MenuItem, 0, len(m.playlists))
+		for _, playlist := range m.playlists {
+			m.menus = append(m.menus, model.MenuItem{
+				Title:    _struct.ReplaceSpecialStr(playlist.Name),
+				Subtitle: fmt.Sprintf("[%d 首]", len(playlist.Songs)),
+			})
+		}
+		return true, nil
+	}
+}
+
+// Action 将歌曲加入选中的歌单后返回上一级
+func (m *AddToLocalPlaylistMenu) Action(a *model.App, index int) (model.Page, tea.Cmd) {
+	if index < 0 || index >= len(m.playlists) {
+		return nil, nil
+	}
+	addSongToLocalPlaylist(m.playlists[index], m.song)
+	main := a.MustMain()
+	main.BackMenu()
+	return main, a.RerenderCmd(true)
+}
@@ -3,59 +3,72 @@ package ui
 import (
 	tea "charm.land/bubbletea/v2"
 	"github.com/anhoder/foxful-cli/model"
+
+	"github.com/go-musicfox/go-musicfox/internal/types"
+	"github.com/go-musicfox/go-musicfox/utils/notify"
 )
 
+const offlineMenuUnavailableTag = "[离线不可用]"
+
+// mainMenuAction 没有子菜单、由 Action 直接处理的主菜单项
+type mainMenuAction int
+
 const (
-	mainMenuHelpIndex        = 14
-	mainMenuCheckUpdateIndex = 15
+	mainMenuNoAction mainMenuAction = iota
+	mainMenuHelp
+	mainMenuCheckUpdate
 )
 
+// mainMenuEntry 主菜单项，序号由所在位置决定
+type mainMenuEntry struct {
+	title   string
+	menu    Menu
+	action  mainMenuAction
+	offline bool // 离线模式下仍可进入
+}
+
 type MainMenu struct {
 	baseMenu
-	menus    []model.MenuItem
-	menuList []Menu
+	menus   []model.MenuItem
+	entries []mainMenuEntry
 }
 
 func NewMainMenu(netease *Netease) *MainMenu {
 	base := newBaseMenu(netease)
+	// 新增的菜单项加在帮助之前，不改变已有菜单项的位置
+	entries := []mainMenuEntry{
+		{title: "每日推荐歌曲", menu: NewDailyRecommendSongsMenu(base)},
+		{title: "每日推荐歌单", menu: NewDailyRecommendPlaylistMenu(base)},
+		{title: "我的歌单", menu: NewUserPlaylistMenu(base, CurUser)},
+		{title: "我的收藏", menu: NewUserCollectionMenu(base)},
+		{title: "私人FM", menu: NewPersonalFmMenu(base)},
+		{title: "专辑列表", menu: NewAlbumListMenu(base)},
+		{title: "搜索", menu: NewSearchTypeMenu(base)},
+		{title: "排行榜", menu: NewRanksMenu(base)},
+		{title: "精选歌单", menu: NewHighQualityPlaylistsMenu(base)},
+		{title: "热门歌手", menu: NewHotArtistsMenu(base)},
+		{title: "最近播放歌曲", menu: NewRecentSongsMenu(base)},
+		{title: "云盘", menu: NewCloudMenu(base)},
+		{title: "主播电台", menu: NewRadioDjTypeMenu(base)},
+		{title: "LastFM", menu: NewLastfm(base)},
+		{title: "本地音乐", menu: NewLocalMusicMenu(base), offline: true},
+		{title: "离线歌单", menu: NewOfflinePinsMenu(base), offline: true},
+		{title: "下载管理", menu: NewDownloadsMenu(base), offline: true},
+		{title: "投送设备", menu: NewDlnaRenderersMenu(base), offline: true},
+		{title: "听歌统计", menu: NewListenStatsMenu(base), offline: true},
+		{title: "智能歌单", menu: NewSmartPlaylistsMenu(base), offline: true},
+		{title: "本地歌单", menu: NewLocalPlaylistsMenu(base), offline: true},
+		{title: "导入外部歌单", menu: NewImportPlaylistMenu(base)},
+		{title: "帮助", action: mainMenuHelp, offline: true}, // 直接打开 Markdown 弹窗，不再进入子菜单。
+		{title: "检查更新", action: mainMenuCheckUpdate},       // 异步执行，并直接显示 TUI 通知。
+	}
 	mainMenu := &MainMenu{
 		baseMenu: base,
-		menus: []model.MenuItem{
-			{Title: "每日推荐歌曲"},
-			{Title: "每日推荐歌单"},
-			{Title: "我的歌单"},
-			{Title: "我的收藏"},
-			{Title: "私人FM"},
-			{Title: "专辑列表"},
-			{Title: "搜索"},
-			{Title: "排行榜"},
-			{Title: "精选歌单"},
-			{Title: "热门歌手"},
-			{Title: "最近播放歌曲"},
-			{Title: "云盘"},
-			{Title: "主播电台"},
-			{Title: "LastFM"},
-			{Title: "帮助"},
-			{Title: "检查更新"},
-		},
-		menuList: []Menu{
-			NewDailyRecommendSongsMenu(base),
-			NewDailyRecommendPlaylistMenu(base),
-			NewUserPlaylistMenu(base, CurUser),
-			NewUserCollectionMenu(base),
-			NewPersonalFmMenu(base),
-			NewAlbumListMenu(base),
-			NewSearchTypeMenu(base),
-			NewRanksMenu(base),
-			NewHighQualityPlaylistsMenu(base),
-			NewHotArtistsMenu(base),
-			NewRecentSongsMenu(base),
-			NewCloudMenu(base),
-			NewRadioDjTypeMenu(base),
-			NewLastfm(base),
-			nil, // 帮助由 Action 直接打开 Markdown 弹窗，不再进入子菜单。
-			nil, // 检查更新由 Action 异步执行，并直接显示 TUI 通知。
-		},
+		menus:    make([]model.MenuItem, len(entries)),
+		entries:  entries,
+	}
+	for i, entry := range entries {
+		mainMenu.menus[i].Title = entry.title
 	}
 	return mainMenu
 }
@@ -73,29 +86,60 @@ func (m *MainMenu) GetMenuKey() string {
 }
 
 func (m *MainMenu) MenuViews() []model.MenuItem {
-	for i, menu := range m.menuList {
-		if menu != nil {
-			menu.FormatMenuItem(&m.menus[i])
+	offline := m.netease.IsOffline()
+	for i, entry := range m.entries {
+		if entry.menu != nil {
+			entry.menu.FormatMenuItem(&m.menus[i])
+		} else {
+			m.menus[i].Subtitle = ""
+		}
+		if offline && !entry.offline {
+			m.menus[i].Subtitle = offlineMenuUnavailableTag
 		}
 	}
 	return m.menus
 }
 
 func (m *MainMenu) SubMenu(_ *model.App, index int) model.Menu {
-	if index < 0 || index >= len(m.menuList) {
+	if index < 0 || index >= len(m.entries) {
+		return nil
+	}
+	entry := m.entries[index]
+	if entry.menu == nil {
+		return nil
+	}
+	if m.netease.IsOffline() && !entry.offline {
+		notifyOfflineUnavailable(entry.title)
 		return nil
 	}
-	return m.menuList[index]
+	return entry.menu
 }
 
 func (m *MainMenu) Action(app *model.App, index int) (model.Page, tea.Cmd) {
-	switch index {
-	case mainMenuHelpIndex:
+	if index < 0 || index >= len(m.entries) || m.entries[index].action == mainMenuNoAction {
+		return m.baseMenu.Action(app, index)
+	}
+	entry := m.entries[index]
+	if m.netease.IsOffline() && !entry.offline {
+		notifyOfflineUnavailable(entry.title)
+		return app.MustMain(), nil
+	}
+	switch entry.action {
+	case mainMenuHelp:
 		showHelpPopup(app)
 		return app.MustMain(), nil
-	case mainMenuCheckUpdateIndex:
+	case mainMenuCheckUpdate:
 		return app.MustMain(), checkUpdateCmd()
 	default:
 		return m.baseMenu.Action(app, index)
 	}
 }
+
+func notifyOfflineUnavailable(title string) {
+	notify.Notify(notify.NotifyContent{
+		Title:   "离线模式下不可用",
+		Text:    title + "需要联网，请先关闭离线模式",
+		GroupId: types.GroupID,
+		Level:   notify.ToastWarning,
+	})
+}
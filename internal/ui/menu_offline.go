@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/anhoder/foxful-cli/model"
+
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+	_struct "github.com/go-musicfox/go-musicfox/utils/struct"
+)
+
+// OfflinePinsMenu 已离线保存的歌单和专辑，仅使用本地数据
+type OfflinePinsMenu struct {
+	baseMenu
+	menus []model.MenuItem
+	pins  []storage.OfflinePin
+}
+
+func NewOfflinePinsMenu(base baseMenu) *OfflinePinsMenu {
+	return &OfflinePinsMenu{baseMenu: base}
+}
+
+func (m *OfflinePinsMenu) IsSearchable() bool {
+	return true
+}
+
+func (m *OfflinePinsMenu) GetMenuKey() string {
+	return "offline_pins"
+}
+
+func (m *OfflinePinsMenu) FormatMenuItem(item *model.MenuItem) {
+	if m.netease.IsOffline() {
+		item.Subtitle = "[离线模式]"
+	} else {
+		item.Subtitle = ""
+	}
+}
+
+func (m *OfflinePinsMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *OfflinePinsMenu) BeforeEnterMenuHook() model.Hook {
+	return func(main *model.Main) (bool, model.Page) {
+		m.refresh()
+		return true, nil
+	}
+}
+
+func (m *OfflinePinsMenu) refresh() {
+	pins, err := storage.OfflinePins{}.All()
+	if err != nil {
+		// 尚未固定过任何集合时桶不存在
+		slog.Debug("读取离线记录失败", slogx.Error(err))
+	}
+	m.pins = pins
+
+	m.menus = make([]model.MenuItem, 0, len(pins))
+	for _, pin := range pins {
+		var available int
+		for _, song := range pin.Songs {
+			if m.netease.trackManager.IsAvailableOffline(song) {
+				available++
+			}
+		}
+		kind := "歌单"
+		if pin.Kind == storage.OfflinePinAlbum {
+			kind = "专辑"
+		}
+		m.menus = append(m.menus, model.MenuItem{
+			Title:    _struct.ReplaceSpecialStr(pin.Name),
+			Subtitle: fmt.Sprintf("[%s %d/%d 首可用]", kind, available, len(pin.Songs)),
+		})
+	}
+}
+
+func (m *OfflinePinsMenu) SubMenu(_ *model.App, index int) model.Menu {
+	if index < 0 || index >= len(m.pins) {
+		return nil
+	}
+	return NewOfflineSongsMenu(m.baseMenu, m.pins[index])
+}
+
+// Pins 已固定的集合
+func (m *OfflinePinsMenu) Pins() []storage.OfflinePin {
+	return m.pins
+}
+
+// OfflineSongsMenu 已离线保存集合中的歌曲，无法离线播放的歌曲会被标注
+type OfflineSongsMenu struct {
+	baseMenu
+	pin   storage.OfflinePin
+	menus []model.MenuItem
+}
+
+func NewOfflineSongsMenu(base baseMenu, pin storage.OfflinePin) *OfflineSongsMenu {
+	return &OfflineSongsMenu{baseMenu: base, pin: pin}
+}
+
+func (m *OfflineSongsMenu) IsSearchable() bool {
+	return true
+}
+
+func (m *OfflineSongsMenu) IsPlayable() bool {
+	return true
+}
+
+func (m *OfflineSongsMenu) GetMenuKey() string {
+	return "offline_" + m.pin.Key()
+}
+
+func (m *OfflineSongsMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *OfflineSongsMenu) BeforeEnterMenuHook() model.Hook {
+	return func(main *model.Main) (bool, model.Page) {
+		m.menus = offlineSongViews(m.netease.trackManager, m.pin.Songs)
+		return true, nil
+	}
+}
+
+func (m *OfflineSongsMenu) Songs() []structs.Song {
+	return m.pin.Songs
+}
+
+// Pin 当前集合
+func (m *OfflineSongsMenu) Pin() storage.OfflinePin {
+	return m.pin
+}
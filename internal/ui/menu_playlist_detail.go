@@ -35,7 +35,7 @@ func (m *PlaylistDetailMenu) IsPlayable() bool {
 }
 
 func (m *PlaylistDetailMenu) GetMenuKey() string {
-	return fmt.Sprintf("playlist_detail_%d", m.playlistId)
+	return fmt.Sprintf("%s%d", playlistMenuKeyPrefix, m.playlistId)
 }
 
 func (m *PlaylistDetailMenu) MenuViews() []model.MenuItem {
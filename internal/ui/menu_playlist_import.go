@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/anhoder/foxful-cli/model"
+
+	"github.com/go-musicfox/go-musicfox/internal/playlistimport"
+	_struct "github.com/go-musicfox/go-musicfox/utils/struct"
+)
+
+// ImportPlaylistMenu 导入外部歌单：选择文件、查看匹配结果并修正，最后创建网易云歌单
+// 匹配完成后第一项为创建歌单，其后按文件顺序列出每一行，最后一项为放弃导入
+type ImportPlaylistMenu struct {
+	baseMenu
+	mu      sync.Mutex
+	session *importSession
+	results []playlistimport.Result // 最近一次刷新时的结果，与菜单项对应
+}
+
+func NewImportPlaylistMenu(base baseMenu) *ImportPlaylistMenu {
+	return &ImportPlaylistMenu{baseMenu: base}
+}
+
+func (m *ImportPlaylistMenu) IsSearchable() bool {
+	return true
+}
+
+func (m *ImportPlaylistMenu) GetMenuKey() string {
+	return "playlist_import"
+}
+
+func (m *ImportPlaylistMenu) getSession() *importSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.session
+}
+
+// setSession 替换当前导入，匹配中的旧导入会停止
+func (m *ImportPlaylistMenu) setSession(session *importSession) {
+	m.mu.Lock()
+	old := m.session
+	m.session = session
+	m.mu.Unlock()
+	if old != nil && old != session {
+		old.mu.Lock()
+		old.canceled = true
+		old.mu.Unlock()
+	}
+}
+
+func (m *ImportPlaylistMenu) FormatMenuItem(item *model.MenuItem) {
+	session := m.getSession()
+	if session == nil {
+		item.Subtitle = "[CSV / M3U / 文本]"
+		return
+	}
+	results, total, running, creating := session.snapshot()
+	switch {
+	case creating:
+		item.Subtitle = "[创建歌单中]"
+	case running:
+		item.Subtitle = fmt.Sprintf("[匹配中 %d/%d]", len(results), total)
+	default:
+		item.Subtitle = fmt.Sprintf("[待创建 %d 首]", len(playlistimport.Songs(results)))
+	}
+}
+
+// MenuViews 每次刷新时读取最新的匹配进度
+func (m *ImportPlaylistMenu) MenuViews() []model.MenuItem {
+	session := m.getSession()
+	if session == nil {
+		m.results = nil
+		return []model.MenuItem{{Title: "选择歌单文件", Subtitle: "[CSV / M3U / 每行「歌手 - 歌名」的文本]"}}
+	}
+
+	results, total, running, creating := session.snapshot()
+	m.results = results
+	menus := make([]model.MenuItem, 0, len(results)+2)
+	switch {
+	case creating:
+		menus = append(menus, model.MenuItem{Title: "正在创建歌单「" + _struct.ReplaceSpecialStr(session.name) + "」"})
+	case running:
+		menus = append(menus, model.MenuItem{Title: "匹配中", Subtitle: fmt.Sprintf("[%d/%d]", len(results), total)})
+	default:
+		summary := playlistimport.Summarize(results)
+		menus = append(menus, model.MenuItem{
+			Title:    "创建歌单「" + _struct.ReplaceSpecialStr(session.name) + "」",
+			Subtitle: fmt.Sprintf("[%d 首，待确认 %d 行，未匹配 %d 行]", summary.Imported(), summary.Pending, summary.Unmatched),
+		})
+	}
+	for _, r := range results {
+		menus = append(menus, model.MenuItem{
+			Title:    _struct.ReplaceSpecialStr(r.Entry.String()),
+			Subtitle: _struct.ReplaceSpecialStr(importResultStatus(r)),
+		})
+	}
+	return append(menus, model.MenuItem{Title: "放弃本次导入"})
+}
+
+// importResultStatus 行的匹配状态，如 [已匹配] 周杰伦 - 晴天
+func importResultStatus(r playlistimport.Result) string {
+	if song, ok := r.Song(); ok {
+		status := "[已匹配]"
+		if r.Status != playlistimport.StatusMatched {
+			status = "[已确认]"
+		}
+		return status + " " + song.ArtistName() + " - " + song.Name
+	}
+	switch {
+	case r.Err != nil:
+		return "[搜索失败]"
+	case r.Status == playlistimport.StatusReview:
+		return fmt.Sprintf("[待确认 %d 个候选]", len(r.Candidates))
+	default:
+		return "[未匹配]"
+	}
+}
+
+func (m *ImportPlaylistMenu) SubMenu(_ *model.App, index int) model.Menu {
+	session := m.getSession()
+	if session == nil {
+		if index == 0 {
+			return NewMenuToPage(m.baseMenu, newImportPlaylistFilePage(m.netease, m))
+		}
+		return nil
+	}
+
+	switch {
+	case index == 0:
+		if _, _, running, creating := session.snapshot(); running || creating {
+			return nil
+		}
+		return NewMenuToPage(m.baseMenu, newCreateImportedPlaylistPage(m.netease, m, session))
+	case index >= 1 && index <= len(m.results):
+		if r := m.results[index-1]; len(r.Candidates) > 0 {
+			return NewImportCandidatesMenu(m.baseMenu, session, index-1, r)
+		}
+	}
+	return nil
+}
+
+func (m *ImportPlaylistMenu) Action(a *model.App, index int) (model.Page, tea.Cmd) {
+	if m.getSession() == nil || index != len(m.results)+1 {
+		return nil, nil
+	}
+	m.setSession(nil)
+	main := a.MustMain()
+	main.RefreshMenuList()
+	main.SetSelectedIndex(0)
+	return main, a.RerenderCmd(true)
+}
+
+// ImportCandidatesMenu 选择一行采用的候选歌曲，最后一项为不导入此行
+type ImportCandidatesMenu struct {
+	baseMenu
+	session *importSession
+	index   int
+	result  playlistimport.Result
+	menus   []model.MenuItem
+}
+
+func NewImportCandidatesMenu(base baseMenu, session *importSession, index int, result playlistimport.Result) *ImportCandidatesMenu {
+	menu := &ImportCandidatesMenu{baseMenu: base, session: session, index: index, result: result}
+	for i, c := range result.Candidates {
+		duration := int(c.Song.Duration.Seconds())
+		subtitle := fmt.Sprintf("[%s %d:%02d 相似度 %.2f]", c.Song.Album.Name, duration/60, duration%60, c.Score)
+		if i == result.Chosen {
+			subtitle = "[当前] " + subtitle
+		}
+		menu.menus = append(menu.menus, model.MenuItem{
+			Title:    _struct.ReplaceSpecialStr(c.Song.Name + " - " + c.Song.ArtistName()),
+			Subtitle: _struct.ReplaceSpecialStr(subtitle),
+		})
+	}
+	menu.menus = append(menu.menus, model.MenuItem{Title: "不导入此行"})
+	return menu
+}
+
+func (m *ImportCandidatesMenu) GetMenuKey() string {
+	return fmt.Sprintf("playlist_import_candidates_%d", m.index)
+}
+
+func (m *ImportCandidatesMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+// Action 采用选中的候选后返回上一级
+func (m *ImportCandidatesMenu) Action(a *model.App, index int) (model.Page, tea.Cmd) {
+	if index < 0 || index > len(m.result.Candidates) {
+		return nil, nil
+	}
+	if index == len(m.result.Candidates) {
+		index = -1
+	}
+	m.session.choose(m.index, index)
+	main := a.MustMain()
+	main.BackMenu()
+	main.RefreshMenuList()
+	return main, a.RerenderCmd(true)
+}
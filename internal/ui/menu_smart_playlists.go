@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhoder/foxful-cli/model"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/smartlist"
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+	"github.com/go-musicfox/go-musicfox/utils/likelist"
+	"github.com/go-musicfox/go-musicfox/utils/menux"
+	"github.com/go-musicfox/go-musicfox/utils/notify"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+	_struct "github.com/go-musicfox/go-musicfox/utils/struct"
+)
+
+// SmartPlaylistsMenu 智能歌单，来自配置文件中的 [[smartPlaylists]]
+type SmartPlaylistsMenu struct {
+	baseMenu
+	menus     []model.MenuItem
+	playlists []*smartlist.Playlist // 配置有误时为 nil
+	errs      []error
+}
+
+func NewSmartPlaylistsMenu(base baseMenu) *SmartPlaylistsMenu {
+	m := &SmartPlaylistsMenu{baseMenu: base}
+	for i, cfg := range configs.AppConfig.SmartPlaylists {
+		playlist, err := smartlist.New(cfg)
+		title := cfg.Name
+		if title == "" {
+			title = fmt.Sprintf("智能歌单 %d", i+1)
+		}
+		item := model.MenuItem{Title: _struct.ReplaceSpecialStr(title)}
+		if err != nil {
+			slog.Warn("invalid smart playlist", "name", cfg.Name, slogx.Error(err))
+			item.Subtitle = "[配置有误]"
+		}
+		m.menus = append(m.menus, item)
+		m.playlists = append(m.playlists, playlist)
+		m.errs = append(m.errs, err)
+	}
+	return m
+}
+
+func (m *SmartPlaylistsMenu) GetMenuKey() string {
+	return "smart_playlists"
+}
+
+func (m *SmartPlaylistsMenu) FormatMenuItem(item *model.MenuItem) {
+	if len(m.playlists) == 0 {
+		item.Subtitle = "[未配置]"
+		return
+	}
+	item.Subtitle = fmt.Sprintf("[%d 个]", len(m.playlists))
+}
+
+func (m *SmartPlaylistsMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *SmartPlaylistsMenu) BeforeEnterMenuHook() model.Hook {
+	return func(main *model.Main) (bool, model.Page) {
+		if len(m.playlists) == 0 {
+			notify.Notify(notify.NotifyContent{
+				Title:   "未配置智能歌单",
+				Text:    "请在配置文件中添加 [[smartPlaylists]]",
+				GroupId: types.GroupID,
+				Level:   notify.ToastWarning,
+			})
+			return false, nil
+		}
+		return true, nil
+	}
+}
+
+func (m *SmartPlaylistsMenu) SubMenu(_ *model.App, index int) model.Menu {
+	if index < 0 || index >= len(m.playlists) {
+		return nil
+	}
+	if err := m.errs[index]; err != nil {
+		notify.Notify(notify.NotifyContent{
+			Title:   "智能歌单配置有误",
+			Text:    err.Error(),
+			GroupId: types.GroupID,
+			Level:   notify.ToastError,
+		})
+		return nil
+	}
+	return NewSmartPlaylistSongsMenu(m.baseMenu, index, m.playlists[index])
+}
+
+// SmartPlaylistSongsMenu 智能歌单中的歌曲，每次进入时重新计算
+type SmartPlaylistSongsMenu struct {
+	baseMenu
+	index    int
+	playlist *smartlist.Playlist
+	menus    []model.MenuItem
+	songs    []structs.Song
+}
+
+func NewSmartPlaylistSongsMenu(base baseMenu, index int, playlist *smartlist.Playlist) *SmartPlaylistSongsMenu {
+	return &SmartPlaylistSongsMenu{
+		baseMenu: base,
+		index:    index,
+		playlist: playlist,
+	}
+}
+
+func (m *SmartPlaylistSongsMenu) IsSearchable() bool {
+	return true
+}
+
+func (m *SmartPlaylistSongsMenu) IsPlayable() bool {
+	return true
+}
+
+func (m *SmartPlaylistSongsMenu) GetMenuKey() string {
+	return fmt.Sprintf("smart_playlist_%d", m.index)
+}
+
+func (m *SmartPlaylistSongsMenu) MenuViews() []model.MenuItem {
+	return m.menus
+}
+
+func (m *SmartPlaylistSongsMenu) Songs() []structs.Song {
+	return m.songs
+}
+
+func (m *SmartPlaylistSongsMenu) BeforeEnterMenuHook() model.Hook {
+	return func(main *model.Main) (bool, model.Page) {
+		if m.playlist.NeedsLogin() {
+			if m.netease.IsOffline() {
+				notifyOfflineUnavailable(m.playlist.Name)
+				return false, nil
+			}
+			if _struct.CheckUserInfo(m.netease.user) == _struct.NeedLogin {
+				page, _ := m.netease.ToLoginPage(EnterMenuCallback(main))
+				return false, page
+			}
+		}
+
+		songs, err := m.playlist.Songs(m.env())
+		if err != nil {
+			slog.Error("计算智能歌单失败", "name", m.playlist.Name, slogx.Error(err))
+			notify.Notify(notify.NotifyContent{
+				Title:   "获取智能歌单失败",
+				Text:    err.Error(),
+				GroupId: types.GroupID,
+				Level:   notify.ToastError,
+			})
+			return false, nil
+		}
+		m.songs = songs
+		m.menus = menux.GetViewFromSongs(songs)
+		return true, nil
+	}
+}
+
+func (m *SmartPlaylistSongsMenu) env() smartlist.Env {
+	lib := m.netease.localLibrary
+	env := smartlist.Env{
+		Liked: likelist.IsLikeSong,
+		Local: func() []structs.Song {
+			lib.Load()
+			return lib.Songs()
+		},
+		Now: time.Now(),
+	}
+	if m.netease.user != nil {
+		env.UserId = m.netease.user.UserId
+	}
+	records, err := storage.PlayHistory{}.Records(time.Time{})
+	if err != nil {
+		// 还没有任何播放记录时桶不存在
+		slog.Debug("读取听歌记录失败", slogx.Error(err))
+	}
+	env.Records = records
+	return env
+}
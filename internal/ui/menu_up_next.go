@@ -0,0 +1,52 @@
+package ui
+
+import (
+	tea "charm.land/bubbletea/v2"
+	"github.com/anhoder/foxful-cli/model"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/utils/menux"
+)
+
+const UpNextMenuKey = "up_next"
+
+// UpNextMenu 待播队列，直接展示播放器中的队列，队列变化后即时更新
+type UpNextMenu struct {
+	baseMenu
+}
+
+func NewUpNextMenu(base baseMenu) *UpNextMenu {
+	return &UpNextMenu{baseMenu: base}
+}
+
+func (m *UpNextMenu) IsSearchable() bool {
+	return true
+}
+
+func (m *UpNextMenu) IsLocatable() bool {
+	return false
+}
+
+func (m *UpNextMenu) GetMenuKey() string {
+	return UpNextMenuKey
+}
+
+func (m *UpNextMenu) MenuViews() []model.MenuItem {
+	return menux.GetViewFromSongs(m.Songs())
+}
+
+func (m *UpNextMenu) Songs() []structs.Song {
+	return m.netease.player.upNext.Songs()
+}
+
+// Action 立即播放选中的队列歌曲，之后继续播放队列中的其余歌曲
+func (m *UpNextMenu) Action(a *model.App, index int) (model.Page, tea.Cmd) {
+	selectedIndex := a.MustMain().CurMenu().RealDataIndex(index)
+	song, err := m.netease.player.upNext.Take(selectedIndex)
+	if err != nil {
+		return nil, nil
+	}
+	m.netease.player.refreshUpNextMenu()
+	m.netease.player.playSong(song, DurationNext)
+	return nil, a.RerenderCmd(true)
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -25,8 +26,14 @@ import (
 	"github.com/go-musicfox/go-musicfox/internal/composer"
 	"github.com/go-musicfox/go-musicfox/internal/configs"
 	"github.com/go-musicfox/go-musicfox/internal/desktop_lyrics"
+	"github.com/go-musicfox/go-musicfox/internal/download"
+	"github.com/go-musicfox/go-musicfox/internal/httpapi"
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
 	"github.com/go-musicfox/go-musicfox/internal/lastfm"
+	"github.com/go-musicfox/go-musicfox/internal/library"
 	"github.com/go-musicfox/go-musicfox/internal/lyric"
+	"github.com/go-musicfox/go-musicfox/internal/mediarenderer"
+	"github.com/go-musicfox/go-musicfox/internal/mpdserver"
 	"github.com/go-musicfox/go-musicfox/internal/storage"
 	"github.com/go-musicfox/go-musicfox/internal/structs"
 	"github.com/go-musicfox/go-musicfox/internal/track"
@@ -63,6 +70,17 @@ type Netease struct {
 	player       *Player
 	shareSvc     *composer.ShareService
 	trackManager *track.Manager
+	localLibrary *library.Library
+	downloadMgr  *download.Manager
+	sleepTimer   *automator.SleepTimer
+	alarmClock   *automator.AlarmClock
+	scheduleStop chan struct{}
+	ctlHandler   *ControlHandler
+	ctlServer    *ipc.Server
+	remoteServer *httpapi.Server
+	// mediaRenderer 投屏接收（DLNA 渲染器）
+	mediaRenderer *mediarenderer.Server
+	mpdServer     *mpdserver.Server
 
 	playbarHoveredElement PlaybarElement
 
@@ -73,6 +91,7 @@ type Netease struct {
 	desktopLyrics desktop_lyrics.Controller
 }
 
+// NewNetease 创建网易云音乐应用，app 为 nil 时以无界面（daemon）模式运行
 func NewNetease(app *model.App) *Netease {
 	n := new(Netease)
 	n.lastfm = lastfm.NewClient()
@@ -87,6 +106,9 @@ func NewNetease(app *model.App) *Netease {
 		track.WithCacher(track.NewCacher(maxSizeMB)),
 		track.WithSongQuality(quality))
 
+	n.localLibrary = library.New(configs.AppConfig.Storage.Local.Dirs)
+	n.downloadMgr = newDownloadManager(n)
+
 	showTranslation := configs.AppConfig.Main.Lyric.ShowTranslation
 	offset := time.Duration(configs.AppConfig.Main.Lyric.Offset) * time.Millisecond
 	showLyric := configs.AppConfig.Main.Lyric.Show
@@ -96,9 +118,15 @@ func NewNetease(app *model.App) *Netease {
 	n.lyricService.EnableYRC(true) // Enable word-by-word lyrics
 
 	// Initialize desktop lyrics
-	n.desktopLyrics = desktop_lyrics.NewController(configs.AppConfig.Main.Lyric.DesktopLyrics)
+	if app != nil {
+		n.desktopLyrics = desktop_lyrics.NewController(configs.AppConfig.Main.Lyric.DesktopLyrics)
+	}
 
 	n.player = NewPlayer(n, n.lyricService)
+	n.sleepTimer = newSleepTimer(n)
+	n.alarmClock = newAlarmClock(n)
+	n.scheduleStop = make(chan struct{})
+	n.ctlHandler = NewControlHandler(n.player)
 
 	n.lyricRenderer = NewLyricRenderer(n, n.lyricService, showLyric)
 	n.songInfoRenderer = NewSongInfoRenderer(n, n.player)
@@ -173,7 +201,24 @@ func (n *Netease) InitHook(_ *model.App) {
 	dataDir := app.DataDir()
 
 	// 注册 TUI 内 toast 回调（此时 App.Run 已启动，program 就绪）
-	n.registerToastHook()
+	if !n.Headless() {
+		n.registerToastHook()
+	}
+
+	// HTTP 远程控制
+	if config.Remote.Enable {
+		n.serveRemote(config.Remote)
+	}
+
+	// 投屏接收
+	if config.MediaRenderer.Enable {
+		n.serveMediaRenderer(config.MediaRenderer)
+	}
+
+	// MPD 协议服务
+	if config.MpdServer.Enable {
+		n.serveMpd(config.MpdServer)
+	}
 
 	// 全局文件Jar
 	cookiePath := filepath.Join(dataDir, "cookie")
@@ -289,7 +334,9 @@ func (n *Netease) InitHook(_ *model.App) {
 		n.trackManager.SetCloudUserID(cloudUserID)
 
 		// 刷新界面用户名
-		n.MustMain().RefreshMenuTitle()
+		if !n.Headless() {
+			n.MustMain().RefreshMenuTitle()
+		}
 
 		// 获取播放模式
 		if jsonStr, err := table.GetByKVModel(storage.PlayMode{}); err == nil && len(jsonStr) > 0 {
@@ -310,12 +357,24 @@ func (n *Netease) InitHook(_ *model.App) {
 			}
 		}
 
+		// 恢复离线模式
+		n.loadOfflineMode()
+
 		// 加载播放列表状态
 		if err := n.player.playlistManager.LoadState(); err != nil {
 			// 如果加载失败，记录错误但不影响启动
 			slog.Warn("Failed to load playlist state", slogx.Error(err))
 		}
-		n.Rerender(false)
+		if err := n.player.upNext.LoadState(); err != nil {
+			slog.Warn("Failed to load up next queue", slogx.Error(err))
+		}
+		n.rerender()
+
+		// 恢复未完成的下载任务
+		n.downloadMgr.Start()
+
+		// 恢复睡眠定时，开始检查闹钟
+		n.runSchedules()
 
 		// 获取扩展信息
 		{
@@ -344,7 +403,7 @@ func (n *Netease) InitHook(_ *model.App) {
 		// 刷新like list
 		if n.user != nil {
 			likelist.RefreshLikeList(n.user.UserId)
-			n.Rerender(false)
+			n.rerender()
 		}
 
 		// 签到
@@ -397,6 +456,13 @@ func (n *Netease) InitHook(_ *model.App) {
 			}
 		}
 
+		// 本地音乐库增量扫描
+		if config.Storage.Local.ScanOnStartup && len(n.localLibrary.Dirs()) > 0 {
+			if _, err := n.localLibrary.Scan(context.Background()); err != nil {
+				slog.Error("扫描本地音乐失败", slogx.Error(err))
+			}
+		}
+
 		// 检查更新
 		if config.Startup.CheckUpdate {
 			if ok, newVersion := version.CheckUpdate(); ok {
@@ -419,7 +485,7 @@ func (n *Netease) InitHook(_ *model.App) {
 
 		// changelog: 首次启动新版本或 debug 模式 → 弹更新日志
 		// 使用 AfterFunc 延迟弹窗，确保 startup 页完成、主页面已进入
-		{
+		if !n.Headless() {
 			slog.Debug("changelog: entering check",
 				"debug", configs.AppConfig.Main.Debug,
 				"appVersion", types.AppVersion,
@@ -456,6 +522,20 @@ func (n *Netease) InitHook(_ *model.App) {
 }
 
 func (n *Netease) CloseHook(_ *model.App) {
+	if n.ctlServer != nil {
+		_ = n.ctlServer.Close()
+	}
+	if n.remoteServer != nil {
+		_ = n.remoteServer.Close()
+	}
+	if n.mediaRenderer != nil {
+		_ = n.mediaRenderer.Close()
+	}
+	if n.mpdServer != nil {
+		_ = n.mpdServer.Close()
+	}
+	n.downloadMgr.Close()
+	close(n.scheduleStop)
 	_ = n.player.Close()
 	n.lastfm.Close()
 
@@ -470,6 +550,66 @@ func (n *Netease) CloseHook(_ *model.App) {
 	CloseGohookLogger()
 }
 
+// ListenControl 在 path 上启动本地控制套接字，由 CloseHook 负责关闭
+func (n *Netease) ListenControl(path string) error {
+	server, err := ipc.Listen(path, n.ctlHandler)
+	if err != nil {
+		return err
+	}
+	n.ctlServer = server
+	errorx.Go(func() {
+		if err := server.Serve(); err != nil {
+			slog.Error("Control socket stopped", slogx.Error(err))
+		}
+	}, true)
+	return nil
+}
+
+// serveRemote 启动 HTTP 远程控制服务，由 CloseHook 负责关闭
+func (n *Netease) serveRemote(cfg configs.RemoteConfig) {
+	server, err := httpapi.Listen(cfg.Bind, cfg.Token, n.ctlHandler)
+	if err != nil {
+		slog.Error("HTTP 远程控制启动失败", slogx.Error(err))
+		return
+	}
+	n.remoteServer = server
+	slog.Info("HTTP 远程控制已启动", "addr", server.Addr().String())
+	errorx.Go(func() {
+		if err := server.Serve(); err != nil {
+			slog.Error("HTTP remote control stopped", slogx.Error(err))
+		}
+	}, true)
+}
+
+// serveMpd 启动 MPD 协议服务，由 CloseHook 负责关闭
+func (n *Netease) serveMpd(cfg configs.MpdServerConfig) {
+	server, err := mpdserver.Listen(cfg.Bind, cfg.Password, n.ctlHandler)
+	if err != nil {
+		slog.Error("MPD 协议服务启动失败", slogx.Error(err))
+		return
+	}
+	n.mpdServer = server
+	slog.Info("MPD 协议服务已启动", "addr", server.Addr().String())
+	errorx.Go(func() {
+		if err := server.Serve(); err != nil {
+			slog.Error("MPD server stopped", slogx.Error(err))
+		}
+	}, true)
+}
+
+// Headless 是否以无界面（daemon）模式运行
+func (n *Netease) Headless() bool {
+	return n.App == nil
+}
+
+// rerender 重新渲染界面，无界面模式下忽略
+func (n *Netease) rerender() {
+	if n.Headless() {
+		return
+	}
+	n.Rerender(false)
+}
+
 func (n *Netease) Player() *Player {
 	return n.player
 }
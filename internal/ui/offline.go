@@ -0,0 +1,219 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/anhoder/foxful-cli/model"
+	"github.com/go-musicfox/netease-music/service"
+
+	"github.com/go-musicfox/go-musicfox/internal/netease"
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/track"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+	"github.com/go-musicfox/go-musicfox/utils/errorx"
+	"github.com/go-musicfox/go-musicfox/utils/menux"
+	"github.com/go-musicfox/go-musicfox/utils/notify"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+	_struct "github.com/go-musicfox/go-musicfox/utils/struct"
+)
+
+const offlineUnavailableTag = "[不可用]"
+
+// IsOffline 是否处于离线模式
+func (n *Netease) IsOffline() bool {
+	return n.trackManager != nil && n.trackManager.OfflineMode()
+}
+
+// setOfflineMode 切换离线模式并持久化
+func (n *Netease) setOfflineMode(offline bool) {
+	n.trackManager.SetOfflineMode(offline)
+	if err := storage.NewTable().SetByKVModel(storage.OfflineMode{}, offline); err != nil {
+		slog.Warn("保存离线模式失败", slogx.Error(err))
+	}
+
+	title, text := "已关闭离线模式", "恢复使用网络音源"
+	if offline {
+		title, text = "已开启离线模式", "仅播放已离线保存、已下载或已缓存的歌曲"
+	}
+	notify.Notify(notify.NotifyContent{
+		Title:   title,
+		Text:    text,
+		GroupId: types.GroupID,
+	})
+}
+
+// toggleOffline 开启/关闭离线模式
+func toggleOffline(n *Netease) {
+	n.setOfflineMode(!n.IsOffline())
+	if !n.Headless() {
+		n.MustMain().RefreshMenuList()
+	}
+}
+
+// offlineSongViews 生成歌曲列表视图，无法离线播放的歌曲在副标题中标注
+func offlineSongViews(tm *track.Manager, songs []structs.Song) []model.MenuItem {
+	menus := menux.GetViewFromSongs(songs)
+	for i, song := range songs {
+		if !tm.IsAvailableOffline(song) {
+			menus[i].Subtitle = offlineUnavailableTag + " " + menus[i].Subtitle
+		}
+	}
+	return menus
+}
+
+// pinSelectedPlaylist 离线保存选中的歌单
+func pinSelectedPlaylist(n *Netease) model.Page {
+	return NewOperation(n, func(n *Netease) model.Page {
+		playlist, ok := getSelectedPlaylist(n)
+		if !ok {
+			return nil
+		}
+		codeType, songs := netease.FetchSongsOfPlaylist(playlist.Id, true)
+		if codeType != _struct.Success {
+			notifyPinFailed(playlist.Name, "获取歌单歌曲失败")
+			return nil
+		}
+		pinCollection(n, storage.OfflinePin{Kind: storage.OfflinePinPlaylist, ID: playlist.Id, Name: playlist.Name, Songs: songs})
+		return nil
+	}).ShowLoading().Execute()
+}
+
+// pinSelectedAlbum 离线保存选中的专辑
+func pinSelectedAlbum(n *Netease) model.Page {
+	return NewOperation(n, func(n *Netease) model.Page {
+		main := n.MustMain()
+		menu, ok := main.CurMenu().(AlbumsMenu)
+		if !ok {
+			return nil
+		}
+		index := menu.RealDataIndex(main.SelectedIndex())
+		if index < 0 || index >= len(menu.Albums()) {
+			return nil
+		}
+		album := menu.Albums()[index]
+		songs, ok := fetchSongsOfAlbum(album.Id)
+		if !ok {
+			notifyPinFailed(album.Name, "获取专辑歌曲失败")
+			return nil
+		}
+		pinCollection(n, storage.OfflinePin{Kind: storage.OfflinePinAlbum, ID: album.Id, Name: album.Name, Songs: songs})
+		return nil
+	}).ShowLoading().Execute()
+}
+
+// pinCurrentList 离线保存当前打开的歌单或专辑
+func pinCurrentList(n *Netease) {
+	switch menu := n.MustMain().CurMenu().(type) {
+	case *PlaylistDetailMenu:
+		title := n.MustMain().MenuTitle().Title
+		pinCollection(n, storage.OfflinePin{Kind: storage.OfflinePinPlaylist, ID: menu.PlaylistId(), Name: title, Songs: menu.Songs()})
+	case *AlbumDetailMenu:
+		title := n.MustMain().MenuTitle().Title
+		pinCollection(n, storage.OfflinePin{Kind: storage.OfflinePinAlbum, ID: menu.AlbumId(), Name: title, Songs: menu.Songs()})
+	}
+}
+
+func fetchSongsOfAlbum(albumID int64) ([]structs.Song, bool) {
+	albumService := service.AlbumService{ID: strconv.FormatInt(albumID, 10)}
+	code, response := albumService.Album()
+	if _struct.CheckCode(code) != _struct.Success {
+		return nil, false
+	}
+	return _struct.GetSongsOfAlbum(response), true
+}
+
+// pinCollection 记录固定的集合，并在后台逐首保存到离线存储
+func pinCollection(n *Netease, pin storage.OfflinePin) {
+	if n.IsOffline() {
+		notifyPinFailed(pin.Name, "离线模式下无法下载")
+		return
+	}
+	pin.PinnedAt = time.Now().Unix()
+	if err := (storage.OfflinePins{}).Put(pin); err != nil {
+		slog.Error("保存离线记录失败", slogx.Error(err))
+		notifyPinFailed(pin.Name, err.Error())
+		return
+	}
+
+	notify.Notify(notify.NotifyContent{
+		Title:   "开始离线保存",
+		Text:    fmt.Sprintf("%s（%d 首）", pin.Name, len(pin.Songs)),
+		GroupId: types.GroupID,
+	})
+	errorx.Go(func() {
+		var failed int
+		for _, song := range pin.Songs {
+			if err := n.trackManager.PinSong(context.Background(), song); err != nil {
+				failed++
+				slog.Warn("离线保存歌曲失败", "song", song.Name, "id", song.Id, slogx.Error(err))
+			}
+		}
+		slog.Info("离线保存完成", "name", pin.Name, "total", len(pin.Songs), "failed", failed)
+
+		content := notify.NotifyContent{
+			Title:   "离线保存完成",
+			Text:    fmt.Sprintf("%s：%d 首已保存", pin.Name, len(pin.Songs)-failed),
+			GroupId: types.GroupID,
+			Level:   notify.ToastSuccess,
+		}
+		if failed > 0 {
+			content.Text += fmt.Sprintf("，%d 首失败", failed)
+			content.Level = notify.ToastWarning
+		}
+		notify.Notify(content)
+	}, true)
+}
+
+// unpinCollection 取消固定，仅删除不再被其他集合引用的歌曲文件
+func unpinCollection(n *Netease, pin storage.OfflinePin) {
+	pins := storage.OfflinePins{}
+	if err := pins.Remove(pin); err != nil {
+		slog.Error("删除离线记录失败", slogx.Error(err))
+		return
+	}
+
+	others, _ := pins.All()
+	referenced := make(map[int64]struct{})
+	for _, other := range others {
+		for _, song := range other.Songs {
+			referenced[song.Id] = struct{}{}
+		}
+	}
+	for _, song := range pin.Songs {
+		if _, ok := referenced[song.Id]; ok {
+			continue
+		}
+		if err := n.trackManager.UnpinSong(song.Id); err != nil {
+			slog.Warn("删除离线文件失败", "id", song.Id, slogx.Error(err))
+		}
+	}
+
+	notify.Notify(notify.NotifyContent{
+		Title:   "已取消离线保存",
+		Text:    pin.Name,
+		GroupId: types.GroupID,
+	})
+}
+
+func notifyPinFailed(name, reason string) {
+	notify.Notify(notify.NotifyContent{
+		Title:   "离线保存失败",
+		Text:    name + "：" + reason,
+		GroupId: types.GroupID,
+		Level:   notify.ToastError,
+	})
+}
+
+// loadOfflineMode 恢复上次退出时的离线模式
+func (n *Netease) loadOfflineMode() {
+	jsonStr, err := storage.NewTable().GetByKVModel(storage.OfflineMode{})
+	if err != nil || len(jsonStr) == 0 {
+		return
+	}
+	n.trackManager.SetOfflineMode(string(jsonStr) == "true")
+}
@@ -8,13 +8,11 @@ import (
 	"net/http/cookiejar"
 	"os"
 	"path/filepath"
-	"slices"
 	"strconv"
 
 	"github.com/anhoder/foxful-cli/model"
 	"github.com/buger/jsonparser"
 	"github.com/go-musicfox/go-musicfox/internal/composer"
-	"github.com/go-musicfox/go-musicfox/internal/configs"
 	"github.com/go-musicfox/go-musicfox/internal/storage"
 	"github.com/go-musicfox/go-musicfox/internal/structs"
 	"github.com/go-musicfox/go-musicfox/internal/types"
@@ -264,51 +262,14 @@ func downloadSong(n *Netease, isSelected bool) {
 	op.ShowLoading().Execute()
 }
 
-// handleSongDownload 歌曲下载处理器
+// handleSongDownload 歌曲下载处理器，加入下载队列后由下载管理器通知结果
 func handleSongDownload(n *Netease, song structs.Song) {
 	if song.Id == 0 {
 		slog.Error("指定音乐不存在，跳过下载")
 		return
 	}
 	slog.Info("开始下载歌曲", "song", song.Name, "id", song.Id)
-	notify.Notify(notify.NotifyContent{
-		Title:   model.T(MsgOperationDownloading),
-		Text:    song.Name,
-		GroupId: types.GroupID,
-	})
-	path, err := n.trackManager.DownloadSong(context.Background(), song)
-	switch {
-	case err == nil:
-		slog.Info("歌曲下载成功", "song", song.Name, "id", song.Id, "path", path)
-		notify.Notify(notify.NotifyContent{
-			Title:   model.T(MsgOperationDownloadSuccess),
-			Text:    filepath.Base(path),
-			GroupId: types.GroupID,
-			Level:   notify.ToastSuccess,
-		})
-	case errors.Is(err, os.ErrExist):
-		slog.Info("歌曲文件已存在，跳过下载", "song", song.Name, "id", song.Id, "path", path)
-		notify.Notify(notify.NotifyContent{
-			Title:   model.T(MsgOperationDownloadExists),
-			Text:    filepath.Base(path),
-			GroupId: types.GroupID,
-			Level:   notify.ToastWarning,
-		})
-	default:
-		slog.Error("歌曲下载失败", "song", song.Name, "id", song.Id, "error", err)
-		notify.Notify(notify.NotifyContent{
-			Title:   model.T(MsgOperationDownloadFailed),
-			Text:    err.Error(),
-			GroupId: types.GroupID,
-			Level:   notify.ToastError,
-		})
-	}
-
-	withLyric := configs.AppConfig.Storage.DownloadSongWithLyric
-	if withLyric && (err == nil || errors.Is(err, os.ErrExist)) {
-		slog.Info("歌曲已下载或已存在，开始下载歌词", "song", song.Name, "id", song.Id)
-		errorx.Go(func() { handleLyricDownload(n, song) }, true)
-	}
+	enqueueDownloads(n, []structs.Song{song}, "")
 }
 
 // downloadSongLrc 下载歌词
@@ -635,11 +596,14 @@ func subscribeArtist(n *Netease, isSub bool, isSelected bool) model.Page {
 	return NewOperation(n, coreLogic).ShowLoading().NeedsAuth().Execute()
 }
 
-// appendSongsToCurPlaylist 添加歌曲到播放列表
-func appendSongsToCurPlaylist(n *Netease, addToNext bool) {
+// addSongsToUpNext 添加选中的歌曲（或选中项中的全部歌曲）到待播队列，next 为 true 时作为下一曲播放
+func addSongsToUpNext(n *Netease, next bool) {
 	op := NewOperation(n, func(n *Netease) model.Page {
 		main := n.MustMain()
 		menu := main.CurMenu()
+		if menu.GetMenuKey() == UpNextMenuKey {
+			return nil
+		}
 		selectedIndex := menu.RealDataIndex(main.SelectedIndex())
 		subMenu := menu.SubMenu(n.App, selectedIndex)
 		var appendSongs []structs.Song
@@ -668,35 +632,10 @@ func appendSongsToCurPlaylist(n *Netease, addToNext bool) {
 			return nil
 		}
 
-		var notifyTitle string
-		if addToNext && len(n.player.Playlist()) > 0 {
-			// 添加为下一曲
-			targetIndex := n.player.CurSongIndex() + 1
-			_ = n.player.playlistManager.Initialize(
-				n.player.CurSongIndex(),
-				slices.Concat(
-					n.player.Playlist()[:targetIndex],
-					appendSongs,
-					n.player.Playlist()[targetIndex:],
-				),
-			)
-			notifyTitle = "已添加到下一曲"
-		} else {
-			// 添加到播放列表末尾
-			_ = n.player.playlistManager.Initialize(
-				n.player.CurSongIndex(),
-				append(n.player.Playlist(), appendSongs...),
-			)
-			notifyTitle = "已添加到播放列表末尾"
-		}
-
-		// 替换播放中数据，避免数据错乱
-		n.player.playingMenu = nil
-		n.player.playingMenuKey += "modified"
-		if curPlaylist, ok := menu.(*CurPlaylist); ok {
-			curPlaylist.songs = n.player.Playlist()
-			curPlaylist.menus = menux.GetViewFromSongs(n.player.Playlist())
-			main.RefreshMenuList()
+		n.player.enqueue(appendSongs, next)
+		notifyTitle := "已添加到待播队列"
+		if next {
+			notifyTitle = "已添加为下一曲播放"
 		}
 		notify.Notify(notify.NotifyContent{
 			Title:   notifyTitle,
@@ -709,6 +648,38 @@ func appendSongsToCurPlaylist(n *Netease, addToNext bool) {
 	op.ShowLoading().Execute()
 }
 
+// delSongFromUpNext 从待播队列移除选中歌曲，仅在待播队列界面有效
+func delSongFromUpNext(n *Netease) model.Page {
+	main := n.MustMain()
+	menu, ok := main.CurMenu().(*UpNextMenu)
+	if !ok {
+		return nil
+	}
+	if _, err := n.player.upNext.Remove(menu.RealDataIndex(main.SelectedIndex())); err != nil {
+		return nil
+	}
+	n.player.upNextChanged()
+	return nil
+}
+
+// moveSongInUpNext 在待播队列中上移（offset 为负）或下移选中歌曲，仅在待播队列界面有效
+func moveSongInUpNext(n *Netease, offset int) {
+	main := n.MustMain()
+	menu, ok := main.CurMenu().(*UpNextMenu)
+	if !ok {
+		return
+	}
+	from := menu.RealDataIndex(main.SelectedIndex())
+	if err := n.player.upNext.Move(from, from+offset); err != nil {
+		return
+	}
+	n.player.upNextChanged()
+	// 搜索结果中移动后不跟随光标
+	if main.SelectedIndex() == from {
+		main.SetSelectedIndex(from + offset)
+	}
+}
+
 // openAddSongToUserPlaylistMenu 打开“添加歌曲到歌单”菜单
 func openAddSongToUserPlaylistMenu(n *Netease, isSelected, isAdd bool) model.Page {
 	coreLogic := func(n *Netease) model.Page {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -89,6 +90,13 @@ type Player struct {
 	gaplessLoading  bool
 	gaplessTriedFor int64
 
+	// castSong 其他设备投送的内容，为 nil 时播放的是播放列表中的歌曲
+	castMu   sync.RWMutex
+	castSong *structs.Song
+
+	// upNext 待播队列，优先于播放列表中的下一首播放
+	upNext playlist.Queue
+
 	renderTicker *tickerByPlayer // renderTicker 用于渲染
 
 	// mprisPosThrottle 限制 MPRIS Position 属性更新频率：每个时间 tick 都
@@ -114,8 +122,11 @@ func NewPlayer(n *Netease, lyricService *lyric.Service) *Player {
 		lyricService:    lyricService,
 		playlistManager: playlist.NewPlaylistManager(),
 		ctrl:            make(chan CtrlSignal, 10),
-		reporter:        reporter.NewService(reporterOptions...),
 	}
+	if configs.AppConfig.Reporter.History.Enable {
+		reporterOptions = append(reporterOptions, reporter.WithHistory(p.playSource, configs.AppConfig.Player.Engine))
+	}
+	p.reporter = reporter.NewService(reporterOptions...)
 	var ctx context.Context
 	ctx, p.cancel = context.WithCancel(context.Background())
 
@@ -150,10 +161,10 @@ func NewPlayer(n *Netease, lyricService *lyric.Service) *Player {
 				p.stateHandler.SetPlayingInfo(p.PlayingInfo())
 				p.updateDesktopLyrics()
 				if s != types.Stopped {
-					p.netease.Rerender(false)
+					p.netease.rerender()
 					break
 				}
-				p.NextSong(false)
+				p.autoNext()
 			}
 		}
 	})
@@ -171,7 +182,7 @@ func NewPlayer(n *Netease, lyricService *lyric.Service) *Player {
 					p.stateHandler.SetPosition(pos)
 				}
 				if duration.Seconds()-p.CurMusic().Duration.Seconds() > 10 {
-					p.NextSong(false)
+					p.autoNext()
 				}
 				p.maybePreloadGapless(duration)
 
@@ -193,8 +204,19 @@ func NewPlayer(n *Netease, lyricService *lyric.Service) *Player {
 	return p
 }
 
+// outputFormat 播放引擎输出到音频设备的格式，引擎不支持时返回 false
+func (p *Player) outputFormat() (player.OutputFormat, bool) {
+	if f, ok := p.Player.(player.OutputFormatPlayer); ok {
+		return f.OutputFormat()
+	}
+	return player.OutputFormat{}, false
+}
+
 // InPlayingMenu 是否处于正在播放的菜单中
 func (p *Player) InPlayingMenu() bool {
+	if p.netease.Headless() {
+		return false
+	}
 	key := p.netease.MustMain().CurMenu().GetMenuKey()
 	return key == p.playingMenuKey || key == CurPlaylistKey
 }
@@ -217,6 +239,9 @@ func (p *Player) CompareWithCurPlaylist(playlist []structs.Song) bool {
 
 // LocatePlayingSong 定位到正在播放的音乐
 func (p *Player) LocatePlayingSong() {
+	if p.netease.Headless() {
+		return
+	}
 	var (
 		main        = p.netease.MustMain()
 		curMenu, ok = main.CurMenu().(Menu)
@@ -250,14 +275,23 @@ func (p *Player) LocatePlayingSong() {
 	main.SetSelectedIndex(p.CurSongIndex())
 }
 
-// PlaySong 播放歌曲
+// PlaySong 播放歌曲（播放列表中的歌曲）
 func (p *Player) PlaySong(song structs.Song, direction PlayDirection) {
+	p.upNext.ClearCurrent()
+	p.playSong(song, direction)
+}
+
+// playSong 播放歌曲，不改变待播队列的状态
+func (p *Player) playSong(song structs.Song, direction PlayDirection) {
 	p.cancelGaplessPreload()
+	p.stopCasting()
 	p.reporter.ReportEnd(p.PlayedTime())
 
-	loading := model.NewLoading(p.netease.MustMain())
-	loading.Start()
-	defer loading.Complete()
+	if !p.netease.Headless() {
+		loading := model.NewLoading(p.netease.MustMain())
+		loading.Start()
+		defer loading.Complete()
+	}
 
 	table := storage.NewTable()
 	_ = table.SetByKVModel(storage.PlayerSnapshot{}, storage.PlayerSnapshot{
@@ -321,6 +355,11 @@ func (p *Player) PlaySong(song structs.Song, direction PlayDirection) {
 }
 
 func (p *Player) StartPlay() {
+	// 退出时正在播放待播队列中的歌曲
+	if song, ok := p.upNext.Current(); ok {
+		p.playSong(song, DurationNext)
+		return
+	}
 	if len(p.Playlist()) <= p.CurSongIndex() {
 		return
 	}
@@ -337,6 +376,7 @@ func (p *Player) Playlist() []structs.Song {
 
 func (p *Player) InitSongManager(index int, playlist []structs.Song) {
 	p.cancelGaplessPreload()
+	p.upNext.ClearCurrent()
 	_ = p.playlistManager.Initialize(index, playlist)
 }
 
@@ -345,6 +385,12 @@ func (p *Player) CurSongIndex() int {
 }
 
 func (p *Player) CurSong() structs.Song {
+	if song, ok := p.castingSong(); ok {
+		return song
+	}
+	if song, ok := p.upNext.Current(); ok {
+		return song
+	}
 	index := p.CurSongIndex()
 	if index < 0 || len(p.Playlist()) <= index {
 		return structs.Song{}
@@ -358,7 +404,7 @@ func (p *Player) NextSong(manual bool) {
 	playlistLen := len(p.Playlist())
 
 	// 到达底部，则触发翻页或加载更多
-	if playlistLen == 0 || index >= playlistLen-1 {
+	if p.upNext.Len() == 0 && !p.netease.Headless() && (playlistLen == 0 || index >= playlistLen-1) {
 		main := p.netease.MustMain()
 		if p.InPlayingMenu() {
 			if main.IsDualColumn() && index%2 == 0 {
@@ -374,20 +420,61 @@ func (p *Player) NextSong(manual bool) {
 	}
 
 	// 尝试获取下一首歌曲
-	song, err := p.playlistManager.NextSong(manual)
+	song, err := p.nextSong(manual)
 	if err != nil {
 		slog.Error("Get next song error", slog.Any("err", err), slog.String("play_mode", p.playlistManager.GetPlayModeName()))
 		return
 	}
 
-	p.PlaySong(song, DurationNext)
+	p.playSong(song, DurationNext)
+}
+
+// playSource 正在播放的歌曲所属的菜单，用于本地听歌记录
+func (p *Player) playSource() string {
+	if _, ok := p.upNext.Current(); ok {
+		return UpNextMenuKey
+	}
+	// 播放列表修改后 key 会追加 "modified"
+	return strings.ReplaceAll(p.playingMenuKey, "modified", "")
+}
+
+// nextSong 待播队列中有歌曲时优先取出，否则按播放模式取播放列表中的下一首
+func (p *Player) nextSong(manual bool) (structs.Song, error) {
+	if song, ok := p.upNext.Advance(); ok {
+		p.refreshUpNextMenu()
+		return song, nil
+	}
+	return p.playlistManager.NextSong(manual)
+}
+
+// autoNext 当前歌曲播放结束后自动切换到下一首，睡眠定时到期或播放投送内容时不再继续
+func (p *Player) autoNext() {
+	if p.Casting() {
+		return
+	}
+	if p.netease.sleepTimer != nil && p.netease.sleepTimer.SongEnded() {
+		return
+	}
+	p.NextSong(false)
 }
 
 // PreviousSong 上一曲
 func (p *Player) PreviousSong(manual bool) {
+	// 正在播放队列歌曲时回到播放列表中的当前歌曲，队列歌曲放回队列开头
+	if queued, ok := p.upNext.Current(); ok {
+		if song, err := p.playlistManager.GetCurrentSong(); err == nil {
+			p.upNext.Requeue()
+			p.refreshUpNextMenu()
+			p.playSong(song, DurationNext)
+		} else {
+			p.playSong(queued, DurationNext)
+		}
+		return
+	}
+
 	index := p.CurSongIndex()
 	playlistLen := len(p.Playlist())
-	if playlistLen == 0 || index >= playlistLen-1 {
+	if !p.netease.Headless() && (playlistLen == 0 || index >= playlistLen-1) {
 		main := p.netease.MustMain()
 		if p.InPlayingMenu() {
 			if main.IsDualColumn() && index%2 == 0 {
@@ -403,7 +490,7 @@ func (p *Player) PreviousSong(manual bool) {
 	}
 
 	if song, err := p.playlistManager.PreviousSong(manual); err == nil {
-		p.PlaySong(song, DurationNext)
+		p.playSong(song, DurationNext)
 	}
 }
 
@@ -651,7 +738,7 @@ func (p *Player) handleControlSignal(signal CtrlSignal) {
 	case CtrlSeek:
 		p.Seek(signal.Duration)
 	case CtrlRerender:
-		p.netease.Rerender(false)
+		p.netease.rerender()
 	case CtrlShuffle:
 		if signal.ShuffleType != 0 {
 			p.setShuffle(signal.ShuffleType)
@@ -669,6 +756,7 @@ func (p *Player) handleControlSignal(signal CtrlSignal) {
 
 func (p *Player) PlayingInfo() control.PlayingInfo {
 	song := p.CurSong()
+	_, queued := p.upNext.Current()
 	loopStatus, shuffle := modeToLoopStatusAndShuffle(p.Mode())
 	return control.PlayingInfo{
 		TotalDuration:  song.Duration,
@@ -684,6 +772,8 @@ func (p *Player) PlayingInfo() control.PlayingInfo {
 		LRCText:        p.lyricService.State().FormatAsLRC(),
 		LoopStatus:     loopStatus,
 		Shuffle:        shuffle,
+		Queued:         queued,
+		UpNext:         p.upNext.Len(),
 	}
 }
 
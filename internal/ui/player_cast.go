@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/mediarenderer"
+	"github.com/go-musicfox/go-musicfox/internal/player"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+	"github.com/go-musicfox/go-musicfox/utils/errorx"
+	"github.com/go-musicfox/go-musicfox/utils/notify"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// PlayExternal 播放其他设备投送的地址，播放列表保持不变，播放结束后不会自动切换到下一首
+func (p *Player) PlayExternal(url string, song structs.Song, musicType string) {
+	p.cancelGaplessPreload()
+	p.reporter.ReportEnd(p.PlayedTime())
+
+	p.castMu.Lock()
+	p.castSong = &song
+	p.castMu.Unlock()
+
+	errorx.Go(func() {
+		p.lyricService.SetSong(context.Background(), song)
+	}, true)
+
+	p.Play(player.URLMusic{
+		URL:  url,
+		Song: song,
+		Type: player.SongTypeMapping[musicType],
+	})
+	slog.Info("Start play cast", slog.String("url", url), slog.String("type", musicType), slog.Any("song", song))
+
+	go notify.Notify(notify.NotifyContent{
+		Title:   "正在播放投送: " + song.Name,
+		Text:    song.ArtistName(),
+		GroupId: types.GroupID,
+	})
+}
+
+// Casting 是否正在播放投送的内容
+func (p *Player) Casting() bool {
+	p.castMu.RLock()
+	defer p.castMu.RUnlock()
+	return p.castSong != nil
+}
+
+func (p *Player) castingSong() (structs.Song, bool) {
+	p.castMu.RLock()
+	defer p.castMu.RUnlock()
+	if p.castSong == nil {
+		return structs.Song{}, false
+	}
+	return *p.castSong, true
+}
+
+// stopCasting 回到本机播放列表
+func (p *Player) stopCasting() {
+	p.castMu.Lock()
+	p.castSong = nil
+	p.castMu.Unlock()
+}
+
+var _ mediarenderer.Backend = (*castReceiver)(nil)
+
+// castReceiver 把 DLNA 控制点的操作转交给播放器，与其他远程控制来源串行执行
+type castReceiver struct {
+	handler *ControlHandler
+}
+
+func (c *castReceiver) PlayURI(uri string, meta mediarenderer.Metadata) error {
+	c.handler.mu.Lock()
+	defer c.handler.mu.Unlock()
+
+	song := structs.Song{
+		Name:     meta.Title,
+		Duration: meta.Duration,
+		Album:    structs.Album{Name: meta.Album, PicUrl: meta.AlbumArtURI},
+	}
+	if meta.Artist != "" {
+		song.Artists = []structs.Artist{{Name: meta.Artist}}
+	}
+	format := meta.Format
+	if format == "" {
+		format = "mp3"
+	}
+	c.handler.player.PlayExternal(uri, song, format)
+	return nil
+}
+
+func (c *castReceiver) Pause() {
+	c.handler.mu.Lock()
+	defer c.handler.mu.Unlock()
+	c.handler.player.Pause()
+}
+
+func (c *castReceiver) Resume() {
+	c.handler.mu.Lock()
+	defer c.handler.mu.Unlock()
+	c.handler.player.Resume()
+}
+
+func (c *castReceiver) Stop() {
+	c.handler.mu.Lock()
+	defer c.handler.mu.Unlock()
+	c.handler.player.Stop()
+}
+
+func (c *castReceiver) Seek(position time.Duration) {
+	c.handler.mu.Lock()
+	defer c.handler.mu.Unlock()
+	c.handler.player.Seek(position)
+}
+
+func (c *castReceiver) SetVolume(volume int) {
+	c.handler.mu.Lock()
+	defer c.handler.mu.Unlock()
+	c.handler.player.SetVolume(volume)
+}
+
+func (c *castReceiver) Status() mediarenderer.Status {
+	p := c.handler.player
+	return mediarenderer.Status{
+		State:    p.State(),
+		Position: p.PassedTime(),
+		Duration: p.CurMusic().Duration,
+		Volume:   p.Volume(),
+		Casting:  p.Casting(),
+	}
+}
+
+// serveMediaRenderer 在局域网中公布为 DLNA 渲染器，由 CloseHook 负责关闭
+func (n *Netease) serveMediaRenderer(cfg configs.MediaRendererConfig) {
+	server, err := mediarenderer.Listen(cfg.Bind, cfg.Name, &castReceiver{handler: n.ctlHandler})
+	if err != nil {
+		slog.Error("投屏接收启动失败", slogx.Error(err))
+		return
+	}
+	n.mediaRenderer = server
+	slog.Info("投屏接收已启动", "location", server.Location())
+	errorx.Go(func() {
+		if err := server.Serve(); err != nil {
+			slog.Error("DLNA renderer stopped", slogx.Error(err))
+		}
+	}, true)
+}
@@ -16,16 +16,47 @@ import (
 	"github.com/go-musicfox/go-musicfox/utils/notify"
 )
 
-func (p *Player) maybePreloadGapless(position time.Duration) {
-	if !configs.AppConfig.Player.Beep.Gapless {
-		return
+// dlnaGaplessPreload DLNA 设备需要提前拿到下一首的地址以便缓冲
+const dlnaGaplessPreload = 30 * time.Second
+
+// daemonGaplessPreload mpv、mpd 自行缓冲下一首，提前排入播放列表即可
+const daemonGaplessPreload = 15 * time.Second
+
+// gaplessPreloadWindow 距离歌曲结束多久时预加载下一首，返回 false 表示当前引擎未开启无缝播放
+func (p *Player) gaplessPreloadWindow() (time.Duration, bool) {
+	if _, ok := p.Player.(player.RendererPlayer); ok {
+		return dlnaGaplessPreload, configs.AppConfig.Player.Dlna.Gapless
 	}
-	gapless, ok := p.Player.(player.GaplessPlayer)
-	preloadSeconds := configs.AppConfig.Player.Beep.GaplessPreloadSeconds
+	switch configs.AppConfig.Player.Engine {
+	case types.MpvPlayer:
+		return daemonGaplessPreload, configs.AppConfig.Player.Mpv.Gapless
+	case types.MpdPlayer:
+		return daemonGaplessPreload, configs.AppConfig.Player.Mpd.Gapless
+	}
+	beepCfg := configs.AppConfig.Player.Beep
+	if !beepCfg.Gapless && beepCfg.CrossfadeSeconds <= 0 {
+		return 0, false
+	}
+	preloadSeconds := beepCfg.GaplessPreloadSeconds
 	if preloadSeconds <= 0 {
 		preloadSeconds = 15
 	}
-	if !ok || p.CurMusic().Duration-position > time.Duration(preloadSeconds)*time.Second {
+	// 淡入淡出开始前下一首需要已经解码就绪
+	preloadSeconds = max(preloadSeconds, beepCfg.CrossfadeSeconds+10)
+	return time.Duration(preloadSeconds) * time.Second, true
+}
+
+func (p *Player) maybePreloadGapless(position time.Duration) {
+	window, enabled := p.gaplessPreloadWindow()
+	if !enabled || p.Casting() {
+		return
+	}
+	// 睡眠定时将在本曲结束时暂停，不需要下一首
+	if p.netease.sleepTimer != nil && p.netease.sleepTimer.StopsAtSongEnd() {
+		return
+	}
+	gapless, ok := p.Player.(player.GaplessPlayer)
+	if !ok || p.CurMusic().Duration-position > window {
 		return
 	}
 	next, ok := p.peekGaplessSong()
@@ -56,6 +87,9 @@ func (p *Player) maybePreloadGapless(position time.Duration) {
 }
 
 func (p *Player) peekGaplessSong() (structs.Song, bool) {
+	if song, ok := p.upNext.Peek(); ok {
+		return song, true
+	}
 	songs, index := p.Playlist(), p.CurSongIndex()
 	if len(songs) == 0 || index < 0 || index >= len(songs) {
 		return structs.Song{}, false
@@ -96,18 +130,22 @@ func (p *Player) commitGaplessTransition(transition player.GaplessTransition) {
 	p.gaplessTriedFor = 0
 	p.gaplessMu.Unlock()
 
-	song, err := p.playlistManager.NextSong(false)
+	song, err := p.nextSong(false)
 	if err != nil || song.Id != transition.Music.Id {
 		slog.Warn("gapless playlist transition mismatch", "error", err, "song", transition.Music.Id)
 		return
 	}
 	p.reporter.ReportEnd(transition.PlayedTime)
 	p.reporter.ReportStart(song)
+	if p.netease.sleepTimer != nil {
+		// 无缝切换不经过停止状态，在此计入已播放完的歌曲
+		p.netease.sleepTimer.SongEnded()
+	}
 	errorx.Go(func() { p.lyricService.SetSong(context.Background(), song) }, true)
 	p.LocatePlayingSong()
 	p.stateHandler.SetPlayingInfo(p.PlayingInfo())
 	p.updateDesktopLyrics()
-	p.netease.Rerender(false)
+	p.netease.rerender()
 	go notify.Notify(notify.NotifyContent{
 		Title:   "正在播放: " + song.Name,
 		Text:    fmt.Sprintf("%s - %s", song.ArtistName(), song.Album.Name),
@@ -0,0 +1,243 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+)
+
+// upNextKind 播放顺序中的歌曲来源
+type upNextKind uint8
+
+const (
+	fromPlaylist upNextKind = iota // 播放列表
+	fromPlaying                    // 正在播放的队列歌曲
+	fromQueue                      // 待播队列中等待播放的歌曲
+)
+
+// upNextView 合并后的播放顺序：播放列表中当前歌曲及之前的部分、待播队列（含正在播放的队列歌曲）、播放列表的剩余部分
+//
+// MPRIS TrackList、控制套接字与 MPD 协议都按这个顺序展示和定位歌曲。
+type upNextView struct {
+	playlist []structs.Song
+	index    int            // 播放列表中的当前位置
+	playing  *structs.Song  // 正在播放的队列歌曲
+	queue    []structs.Song // 等待播放的队列歌曲
+}
+
+func (p *Player) upNextView() upNextView {
+	v := upNextView{
+		playlist: p.Playlist(),
+		index:    p.CurSongIndex(),
+		queue:    p.upNext.Songs(),
+	}
+	if song, ok := p.upNext.Current(); ok {
+		v.playing = &song
+	}
+	return v
+}
+
+// queueStart 待播队列在播放顺序中的起始位置
+func (v upNextView) queueStart() int {
+	return min(max(v.index, -1), len(v.playlist)-1) + 1
+}
+
+// queueLen 待播队列（含正在播放的队列歌曲）的长度
+func (v upNextView) queueLen() int {
+	if v.playing != nil {
+		return len(v.queue) + 1
+	}
+	return len(v.queue)
+}
+
+func (v upNextView) songs() []structs.Song {
+	start := v.queueStart()
+	songs := make([]structs.Song, 0, len(v.playlist)+v.queueLen())
+	songs = append(songs, v.playlist[:start]...)
+	if v.playing != nil {
+		songs = append(songs, *v.playing)
+	}
+	songs = append(songs, v.queue...)
+	return append(songs, v.playlist[start:]...)
+}
+
+// current 正在播放的歌曲在播放顺序中的位置
+func (v upNextView) current() int {
+	if v.playing != nil {
+		return v.queueStart()
+	}
+	return v.index
+}
+
+// locate 把播放顺序中的位置换算为来源及在来源中的位置，超出范围时返回 false
+func (v upNextView) locate(pos int) (upNextKind, int, bool) {
+	start, playing := v.queueStart(), v.queueLen()-len(v.queue)
+	switch {
+	case pos < 0 || pos >= len(v.playlist)+v.queueLen():
+		return fromPlaylist, 0, false
+	case pos < start:
+		return fromPlaylist, pos, true
+	case pos < start+playing:
+		return fromPlaying, 0, true
+	case pos < start+v.queueLen():
+		return fromQueue, pos - start - playing, true
+	default:
+		return fromPlaylist, pos - v.queueLen(), true
+	}
+}
+
+// upNextChanged 待播队列变化后重新预加载下一首，并同步到远程控制与界面
+func (p *Player) upNextChanged() {
+	p.cancelGaplessPreload()
+	if p.stateHandler != nil {
+		p.stateHandler.SetPlayingInfo(p.PlayingInfo())
+	}
+	p.refreshUpNextMenu()
+}
+
+// refreshUpNextMenu 位于待播队列界面时刷新列表
+func (p *Player) refreshUpNextMenu() {
+	if p.netease == nil || p.netease.Headless() {
+		return
+	}
+	main := p.netease.MustMain()
+	if _, ok := main.CurMenu().(*UpNextMenu); !ok {
+		return
+	}
+	main.RefreshMenuList()
+	if n := p.upNext.Len(); main.SelectedIndex() >= n {
+		main.SetSelectedIndex(max(n-1, 0))
+	}
+}
+
+// enqueue 添加到待播队列，next 为 true 时添加到队列开头作为下一曲；没有正在播放的歌曲时立即开始播放
+func (p *Player) enqueue(songs []structs.Song, next bool) {
+	if len(songs) == 0 {
+		return
+	}
+	if next {
+		p.upNext.PushFront(songs...)
+	} else {
+		p.upNext.PushBack(songs...)
+	}
+	p.upNextChanged()
+	if p.State() == types.Stopped && p.CurSong().Id == 0 {
+		p.NextSong(true)
+	}
+}
+
+// playAt 播放播放顺序中 pos 位置的歌曲
+func (p *Player) playAt(pos int) error {
+	v := p.upNextView()
+	kind, i, ok := v.locate(pos)
+	if !ok {
+		return errors.Errorf("index out of range: %d", pos)
+	}
+	switch kind {
+	case fromPlaying:
+		p.playSong(*v.playing, DurationNext)
+	case fromQueue:
+		song, err := p.upNext.Take(i)
+		if err != nil {
+			return err
+		}
+		p.refreshUpNextMenu()
+		p.playSong(song, DurationNext)
+	default:
+		p.InitSongManager(i, v.playlist)
+		p.StartPlay()
+	}
+	return nil
+}
+
+// insertAt 把歌曲插入到播放顺序中 pos 位置，落在当前歌曲之后、播放列表剩余部分之前时插入待播队列
+func (p *Player) insertAt(pos int, songs []structs.Song, setAsCurrent bool) error {
+	v := p.upNextView()
+	start := v.queueStart()
+	if pos < 0 || pos > len(v.playlist)+v.queueLen() {
+		return errors.Errorf("index out of range: %d", pos)
+	}
+
+	if pos >= start && pos <= start+v.queueLen() && len(v.playlist) > 0 {
+		index := max(pos-start-(v.queueLen()-len(v.queue)), 0)
+		if err := p.upNext.Insert(index, songs...); err != nil {
+			return err
+		}
+		p.upNextChanged()
+		if setAsCurrent {
+			song, err := p.upNext.Take(index)
+			if err != nil {
+				return err
+			}
+			p.playSong(song, DurationNext)
+		}
+		return nil
+	}
+
+	index := pos
+	if pos > start {
+		index = pos - v.queueLen()
+	}
+	playlist := append(v.playlist[:index:index], append(songs, v.playlist[index:]...)...)
+	current := max(v.index, 0)
+	switch {
+	case setAsCurrent:
+		current = index
+	case index <= current && len(playlist) > len(songs):
+		current += len(songs)
+	}
+	if setAsCurrent {
+		p.replacePlaylist(current, playlist, p.playingMenuKey+"modified")
+		p.StartPlay()
+		return nil
+	}
+	// 只调整播放列表，不影响正在播放的队列歌曲
+	p.cancelGaplessPreload()
+	_ = p.playlistManager.Initialize(current, playlist)
+	p.playingMenu = nil
+	p.playingMenuKey += "modified"
+	p.playlistUpdateAt = time.Now()
+	return nil
+}
+
+// removeAt 移除播放顺序中 pos 位置的歌曲，移除的是正在播放的歌曲时播放下一首
+func (p *Player) removeAt(pos int) error {
+	v := p.upNextView()
+	kind, i, ok := v.locate(pos)
+	if !ok {
+		return errors.Errorf("index out of range: %d", pos)
+	}
+	switch kind {
+	case fromPlaying:
+		p.NextSong(true)
+		return nil
+	case fromQueue:
+		if _, err := p.upNext.Remove(i); err != nil {
+			return err
+		}
+		p.upNextChanged()
+		return nil
+	}
+
+	next, err := p.playlistManager.RemoveSong(i)
+	p.playingMenu = nil
+	p.playingMenuKey += "modified"
+	p.playlistUpdateAt = time.Now()
+	if len(p.Playlist()) == 0 {
+		if v.playing == nil {
+			p.Stop()
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// 移除的是正在播放的歌曲时播放下一首
+	if i == v.index && v.playing == nil {
+		p.PlaySong(next, DurationNext)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/go-musicfox/go-musicfox/internal/ipc"
+	"github.com/go-musicfox/go-musicfox/internal/playlist"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+)
+
+func songIds(songs []structs.Song) []int64 {
+	ids := make([]int64, len(songs))
+	for i, song := range songs {
+		ids[i] = song.Id
+	}
+	return ids
+}
+
+func TestUpNextView(t *testing.T) {
+	playing := structs.Song{Id: 10}
+	v := upNextView{
+		playlist: []structs.Song{{Id: 1}, {Id: 2}, {Id: 3}},
+		index:    1,
+		playing:  &playing,
+		queue:    []structs.Song{{Id: 11}, {Id: 12}},
+	}
+
+	want := []int64{1, 2, 10, 11, 12, 3}
+	got := songIds(v.songs())
+	if len(got) != len(want) {
+		t.Fatalf("songs = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("songs = %v, want %v", got, want)
+		}
+	}
+	if v.current() != 2 {
+		t.Errorf("current = %d, want 2", v.current())
+	}
+
+	tests := []struct {
+		pos   int
+		kind  upNextKind
+		index int
+	}{
+		{0, fromPlaylist, 0},
+		{1, fromPlaylist, 1},
+		{2, fromPlaying, 0},
+		{4, fromQueue, 1},
+		{5, fromPlaylist, 2},
+	}
+	for _, tt := range tests {
+		kind, index, ok := v.locate(tt.pos)
+		if !ok || kind != tt.kind || index != tt.index {
+			t.Errorf("locate(%d) = %d, %d, %v, want %d, %d", tt.pos, kind, index, ok, tt.kind, tt.index)
+		}
+	}
+	if _, _, ok := v.locate(6); ok {
+		t.Error("locate(6) should be out of range")
+	}
+
+	// 未播放队列歌曲时队列紧跟在当前歌曲之后
+	v.playing = nil
+	if got := songIds(v.songs()); got[2] != 11 || v.current() != 1 {
+		t.Errorf("songs = %v, current = %d", got, v.current())
+	}
+	if kind, index, _ := v.locate(2); kind != fromQueue || index != 0 {
+		t.Errorf("locate(2) = %d, %d, want queue 0", kind, index)
+	}
+}
+
+func TestControlHandlerQueueUpNext(t *testing.T) {
+	p := &Player{playlistManager: playlist.NewPlaylistManager()}
+	if err := p.playlistManager.Initialize(0, []structs.Song{{Id: 1}, {Id: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	p.upNext.PushBack(structs.Song{Id: 11}, structs.Song{Id: 12})
+
+	h := NewControlHandler(p)
+	info := h.queueInfo()
+	if got := songIds(p.upNextView().songs()); len(info.Songs) != 4 || got[1] != 11 || got[3] != 2 {
+		t.Fatalf("queue songs = %v", got)
+	}
+	if info.Index != 0 || info.UpNext != 2 || info.Songs[1].ID != 11 {
+		t.Fatalf("queue info = %+v", info)
+	}
+
+	data, err := h.Handle(ipc.Request{Cmd: ipc.CmdQueue, Action: ipc.QueueActionClear})
+	if err != nil {
+		t.Fatalf("queue clear: %v", err)
+	}
+	if info := data.(ipc.QueueInfo); info.UpNext != 0 || len(info.Songs) != 2 {
+		t.Fatalf("queue info after clear = %+v", info)
+	}
+	if _, err = h.Handle(ipc.Request{Cmd: ipc.CmdQueue, Action: ipc.QueueActionEnqueue}); err == nil {
+		t.Error("enqueue without songIds should fail")
+	}
+}
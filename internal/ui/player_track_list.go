@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/netease"
+	control "github.com/go-musicfox/go-musicfox/internal/remote_control"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	_struct "github.com/go-musicfox/go-musicfox/utils/struct"
+)
+
+var (
+	_ control.TrackListController = (*Player)(nil)
+	_ control.PlaylistsController = (*Player)(nil)
+)
+
+// playlistMenuKeyPrefix 与 PlaylistDetailMenu.GetMenuKey 一致，用于判断播放队列是否来自歌单
+const playlistMenuKeyPrefix = "playlist_detail_"
+
+// withControl 与控制套接字、HTTP 远程控制等来源的命令串行执行
+func (p *Player) withControl(fn func() error) error {
+	if p.netease != nil && p.netease.ctlHandler != nil {
+		p.netease.ctlHandler.mu.Lock()
+		defer p.netease.ctlHandler.mu.Unlock()
+	}
+	return fn()
+}
+
+func trackOf(song structs.Song) control.Track {
+	return control.Track{
+		ID:          song.Id,
+		Name:        song.Name,
+		Artist:      song.ArtistName(),
+		Album:       song.Album.Name,
+		AlbumArtist: song.Album.ArtistName(),
+		PicUrl:      song.PicUrl,
+		Duration:    song.Duration,
+	}
+}
+
+// Deprecated: Only remote_control.Handler can call this method, others please use Player instead.
+func (p *Player) CtrlTrackList() ([]control.Track, int) {
+	view := p.upNextView()
+	songs := view.songs()
+	tracks := make([]control.Track, len(songs))
+	for i, song := range songs {
+		tracks[i] = trackOf(song)
+	}
+	return tracks, view.current()
+}
+
+// Deprecated: Only remote_control.Handler can call this method, others please use Player instead.
+func (p *Player) CtrlGoTo(index int) error {
+	return p.withControl(func() error {
+		return p.playAt(index)
+	})
+}
+
+// Deprecated: Only remote_control.Handler can call this method, others please use Player instead.
+func (p *Player) CtrlAddTrack(songId int64, index int, setAsCurrent bool) error {
+	songs, err := netease.FetchSongsByIds([]int64{songId})
+	if err != nil {
+		return errors.Wrap(err, "fetch songs")
+	}
+	if len(songs) == 0 {
+		return errors.New("no song found")
+	}
+
+	return p.withControl(func() error {
+		return p.insertAt(index, songs, setAsCurrent)
+	})
+}
+
+// Deprecated: Only remote_control.Handler can call this method, others please use Player instead.
+func (p *Player) CtrlRemoveTrack(index int) error {
+	return p.withControl(func() error {
+		return p.removeAt(index)
+	})
+}
+
+// Deprecated: Only remote_control.Handler can call this method, others please use Player instead.
+func (p *Player) CtrlPlaylists() ([]control.Playlist, error) {
+	user := p.netease.user
+	if _struct.CheckUserInfo(user) == _struct.NeedLogin {
+		return nil, errors.New("not logged in")
+	}
+
+	const limit = 100
+	var playlists []control.Playlist
+	for offset := 0; ; offset += limit {
+		code, page, hasMore := netease.FetchUserPlaylists(user.UserId, limit, offset)
+		if code != _struct.Success {
+			return nil, errors.Errorf("fetch playlists failed: %d", code)
+		}
+		for _, playlist := range page {
+			playlists = append(playlists, control.Playlist{ID: playlist.Id, Name: playlist.Name})
+		}
+		if !hasMore || len(page) == 0 {
+			return playlists, nil
+		}
+	}
+}
+
+// Deprecated: Only remote_control.Handler can call this method, others please use Player instead.
+func (p *Player) CtrlActivatePlaylist(id int64) error {
+	code, songs := netease.FetchSongsOfPlaylist(id, configs.AppConfig.Player.ShowAllSongsOfPlaylist)
+	if code != _struct.Success {
+		return errors.Errorf("fetch songs of playlist failed: %d", code)
+	}
+	if len(songs) == 0 {
+		return errors.New("playlist is empty")
+	}
+	return p.withControl(func() error {
+		p.replacePlaylist(0, songs, fmt.Sprintf("%s%d", playlistMenuKeyPrefix, id))
+		p.StartPlay()
+		return nil
+	})
+}
+
+// Deprecated: Only remote_control.Handler can call this method, others please use Player instead.
+func (p *Player) CtrlActivePlaylist() int64 {
+	rest, ok := strings.CutPrefix(p.playingMenuKey, playlistMenuKeyPrefix)
+	if !ok {
+		return 0
+	}
+	id, _ := strconv.ParseInt(rest, 10, 64)
+	return id
+}
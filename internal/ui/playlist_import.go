@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/anhoder/foxful-cli/model"
+	"github.com/pkg/errors"
+
+	"github.com/go-musicfox/go-musicfox/internal/netease"
+	"github.com/go-musicfox/go-musicfox/internal/playlistimport"
+	"github.com/go-musicfox/go-musicfox/internal/structs"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+	"github.com/go-musicfox/go-musicfox/utils/app"
+	"github.com/go-musicfox/go-musicfox/utils/errorx"
+	neteaseurl "github.com/go-musicfox/go-musicfox/utils/netease"
+	"github.com/go-musicfox/go-musicfox/utils/notify"
+	"github.com/go-musicfox/go-musicfox/utils/slogx"
+)
+
+// importSearchLimit 每行搜索结果数
+const importSearchLimit = 10
+
+// importSession 一次外部歌单导入：后台逐行匹配，完成后在菜单中确认并创建歌单
+type importSession struct {
+	mu       sync.Mutex
+	name     string
+	results  []playlistimport.Result
+	total    int
+	running  bool // 匹配中
+	creating bool // 创建歌单中
+	canceled bool
+}
+
+// snapshot 复制当前结果供界面读取，避免与后台匹配并发访问
+func (s *importSession) snapshot() (results []playlistimport.Result, total int, running, creating bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]playlistimport.Result(nil), s.results...), s.total, s.running, s.creating
+}
+
+// choose 采用第 index 行的第 candidate 个候选，candidate 为负数时不导入该行
+func (s *importSession) choose(index, candidate int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index >= 0 && index < len(s.results) {
+		s.results[index].Choose(candidate)
+	}
+}
+
+// startPlaylistImport 读取文件后在后台逐行搜索匹配
+func startPlaylistImport(n *Netease, menu *ImportPlaylistMenu, path string) error {
+	entries, err := playlistimport.ParseFile(path)
+	if err != nil {
+		return err
+	}
+	session := &importSession{
+		name:    strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		total:   len(entries),
+		running: true,
+	}
+	menu.setSession(session)
+
+	matcher := playlistimport.NewMatcher(func(keyword string) ([]structs.Song, error) {
+		return netease.SearchSongs(keyword, importSearchLimit)
+	}, playlistimport.Options{})
+	errorx.Go(func() {
+		for _, entry := range entries {
+			result := matcher.Match(entry)
+			session.mu.Lock()
+			if session.canceled {
+				session.mu.Unlock()
+				return
+			}
+			session.results = append(session.results, result)
+			session.mu.Unlock()
+			n.onImportUpdate()
+		}
+		session.mu.Lock()
+		if session.canceled {
+			session.mu.Unlock()
+			return
+		}
+		session.running = false
+		summary := playlistimport.Summarize(session.results)
+		session.mu.Unlock()
+		n.onImportUpdate()
+
+		notify.Notify(notify.NotifyContent{
+			Title:   "外部歌单匹配完成",
+			Text:    fmt.Sprintf("%s：已匹配 %d 首，待确认 %d 行，未匹配 %d 行", session.name, summary.Matched, summary.Pending, summary.Unmatched),
+			GroupId: types.GroupID,
+			Level:   notify.ToastSuccess,
+		})
+	})
+	return nil
+}
+
+// onImportUpdate 导入页面可见时刷新进度
+func (n *Netease) onImportUpdate() {
+	if n.Headless() {
+		return
+	}
+	main := n.MustMain()
+	switch main.CurMenu().(type) {
+	case *ImportPlaylistMenu, *MainMenu:
+	default:
+		return
+	}
+	main.RefreshMenuList()
+	n.rerender()
+}
+
+// newImportPlaylistFilePage 输入要导入的文件路径
+func newImportPlaylistFilePage(n *Netease, menu *ImportPlaylistMenu) model.Page {
+	title := &model.MenuItem{Title: "导入外部歌单", Subtitle: "CSV / M3U / 每行「歌手 - 歌名」的文本"}
+	return NewTextInputPage(n, title, "歌单文件路径", "", func(path string) error {
+		if err := startPlaylistImport(n, menu, expandHomeDir(path)); err != nil {
+			return err
+		}
+		n.MustMain().RefreshMenuList()
+		return nil
+	})
+}
+
+// newCreateImportedPlaylistPage 确认歌单名称后在网易云创建歌单
+func newCreateImportedPlaylistPage(n *Netease, menu *ImportPlaylistMenu, session *importSession) model.Page {
+	title := &model.MenuItem{Title: "导入外部歌单", Subtitle: "创建网易云歌单"}
+	return NewTextInputPage(n, title, "歌单名称", session.name, func(name string) error {
+		if n.user == nil {
+			return errors.New("请先登录")
+		}
+		results, _, running, creating := session.snapshot()
+		switch {
+		case running:
+			return errors.New("仍在匹配中，请稍候")
+		case creating:
+			return errors.New("正在创建歌单，请稍候")
+		case len(playlistimport.Songs(results)) == 0:
+			return errors.New("没有可导入的歌曲")
+		}
+		session.mu.Lock()
+		session.name, session.creating = name, true
+		session.mu.Unlock()
+		errorx.Go(func() { createImportedPlaylist(n, menu, session, name, results) })
+		return nil
+	})
+}
+
+// createImportedPlaylist 创建歌单并写出导入报告，成功后结束本次导入
+func createImportedPlaylist(n *Netease, menu *ImportPlaylistMenu, session *importSession, name string, results []playlistimport.Result) {
+	fail := func(err error) {
+		slog.Error("导入外部歌单失败", "name", name, slogx.Error(err))
+		notify.Notify(notify.NotifyContent{
+			Title:   "导入外部歌单失败",
+			Text:    err.Error(),
+			GroupId: types.GroupID,
+			Level:   notify.ToastError,
+		})
+		session.mu.Lock()
+		session.creating = false
+		session.mu.Unlock()
+	}
+
+	songs := playlistimport.Songs(results)
+	ids := make([]int64, len(songs))
+	for i, song := range songs {
+		ids[i] = song.Id
+	}
+	playlistId, err := netease.CreatePlaylist(name, false)
+	if err != nil {
+		fail(err)
+		return
+	}
+	if err = netease.AddSongsToPlaylist(playlistId, ids); err != nil {
+		fail(err)
+		return
+	}
+
+	text := fmt.Sprintf("%s（%d 首）", name, len(songs))
+	if summary := playlistimport.Summarize(results); summary.Imported() < summary.Total {
+		text += fmt.Sprintf("，%d 行未导入", summary.Total-summary.Imported())
+		if reportPath, err := writeImportReport(name, results); err != nil {
+			slog.Warn("写入导入报告失败", slogx.Error(err))
+		} else {
+			text += "，报告：" + reportPath
+		}
+	}
+	menu.setSession(nil)
+	n.onImportUpdate()
+	notify.Notify(notify.NotifyContent{
+		Title:   "已创建歌单",
+		Text:    text,
+		Url:     neteaseurl.WebUrlOfPlaylist(playlistId),
+		GroupId: types.GroupID,
+		Level:   notify.ToastSuccess,
+	})
+}
+
+// writeImportReport 将报告写入下载目录
+func writeImportReport(name string, results []playlistimport.Result) (string, error) {
+	fileName := strings.NewReplacer("/", "_", "\\", "_").Replace(name) + "-import-report.txt"
+	path := filepath.Join(app.DownloadDir(), fileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return path, playlistimport.WriteReport(f, results)
+}
@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/anhoder/foxful-cli/model"
+
+	"github.com/go-musicfox/go-musicfox/internal/automator"
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/storage"
+	"github.com/go-musicfox/go-musicfox/internal/types"
+	"github.com/go-musicfox/go-musicfox/utils/errorx"
+	"github.com/go-musicfox/go-musicfox/utils/notify"
+)
+
+func newSleepTimer(n *Netease) *automator.SleepTimer {
+	fade := time.Duration(configs.AppConfig.SleepTimer.FadeOutSeconds) * time.Second
+	return automator.NewSleepTimer(n.player,
+		automator.WithSleepTimerFade(fade),
+		automator.WithSleepTimerStopped(func(storage.SleepTimer) {
+			notify.Notify(notify.NotifyContent{
+				Title:   "睡眠定时",
+				Text:    "定时已到，播放已暂停",
+				GroupId: types.GroupID,
+			})
+			n.rerender()
+		}))
+}
+
+func newAlarmClock(n *Netease) *automator.AlarmClock {
+	return automator.NewAlarmClock(configs.AppConfig.SleepTimer.Alarms, func(alarm configs.AlarmConfig) error {
+		if alarm.Volume > 0 {
+			n.player.SetVolume(alarm.Volume)
+		}
+		err := automator.NewAutoPlayer(n.user, n.player, alarm.Autoplay()).Start()
+		content := notify.NotifyContent{Title: "闹钟 " + alarm.Time, Text: "开始播放", GroupId: types.GroupID}
+		if err != nil {
+			content.Text, content.Level = "播放失败: "+err.Error(), notify.ToastError
+		}
+		notify.Notify(content)
+		return err
+	})
+}
+
+// runSchedules 恢复睡眠定时并每秒检查定时与闹钟，直到应用退出
+func (n *Netease) runSchedules() {
+	n.sleepTimer.Restore()
+	errorx.Go(func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-n.scheduleStop:
+				return
+			case <-ticker.C:
+				n.sleepTimer.Tick()
+				n.alarmClock.Check()
+				if n.sleepTimer.Active() {
+					// 状态栏倒计时
+					n.rerender()
+				}
+			}
+		}
+	}, true)
+}
+
+// sleepTimerStatus 状态栏显示的定时信息
+func sleepTimerStatus(n *Netease) string {
+	if n == nil || n.sleepTimer == nil {
+		return ""
+	}
+	return n.sleepTimer.Status()
+}
+
+type sleepTimerOption struct {
+	title  string
+	action func(t *automator.SleepTimer)
+}
+
+// SleepTimerMenu 设置睡眠定时
+type SleepTimerMenu struct {
+	baseMenu
+	options []sleepTimerOption
+}
+
+func NewSleepTimerMenu(base baseMenu) *SleepTimerMenu {
+	return &SleepTimerMenu{baseMenu: base}
+}
+
+func (m *SleepTimerMenu) GetMenuKey() string {
+	return "sleep_timer"
+}
+
+func (m *SleepTimerMenu) MenuViews() []model.MenuItem {
+	items := make([]model.MenuItem, 0, len(m.options))
+	for _, option := range m.options {
+		items = append(items, model.MenuItem{Title: option.title})
+	}
+	return items
+}
+
+func (m *SleepTimerMenu) FormatMenuItem(item *model.MenuItem) {
+	status := m.netease.sleepTimer.Status()
+	if status == "" {
+		status = "未设置"
+	}
+	if next, ok := m.netease.alarmClock.Next(); ok {
+		status += " · 下次闹钟 " + next.Format("01-02 15:04")
+	}
+	item.Subtitle = "[" + status + "]"
+}
+
+func (m *SleepTimerMenu) BeforeEnterMenuHook() model.Hook {
+	return func(main *model.Main) (bool, model.Page) {
+		m.options = m.options[:0]
+		for _, minutes := range []int{15, 30, 45, 60, 90, 120} {
+			m.options = append(m.options, sleepTimerOption{
+				title:  fmt.Sprintf("%d 分钟后暂停", minutes),
+				action: func(t *automator.SleepTimer) { t.After(time.Duration(minutes) * time.Minute) },
+			})
+		}
+		m.options = append(m.options, sleepTimerOption{
+			title:  "播放完当前歌曲后暂停",
+			action: func(t *automator.SleepTimer) { t.AfterSongs(1) },
+		})
+		for _, songs := range []int{2, 3, 5} {
+			m.options = append(m.options, sleepTimerOption{
+				title:  fmt.Sprintf("播放完 %d 首歌后暂停", songs),
+				action: func(t *automator.SleepTimer) { t.AfterSongs(songs) },
+			})
+		}
+		if m.netease.sleepTimer.Active() {
+			m.options = append(m.options, sleepTimerOption{
+				title:  "取消定时",
+				action: func(t *automator.SleepTimer) { t.Cancel() },
+			})
+		}
+		return true, nil
+	}
+}
+
+func (m *SleepTimerMenu) SubMenu(app *model.App, index int) model.Menu {
+	if index < 0 || index >= len(m.options) {
+		return nil
+	}
+	app.MustMain().BackMenu()
+	m.options[index].action(m.netease.sleepTimer)
+	if m.netease.sleepTimer.StopsAtSongEnd() {
+		m.netease.player.cancelGaplessPreload()
+	}
+	text := "已取消睡眠定时"
+	if status := m.netease.sleepTimer.Status(); status != "" {
+		text = status
+	}
+	notify.Notify(notify.NotifyContent{Title: "睡眠定时", Text: text, GroupId: types.GroupID})
+	return nil
+}
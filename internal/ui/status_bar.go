@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"log/slog"
+	"strconv"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/anhoder/foxful-cli/model"
@@ -12,6 +13,7 @@ import (
 	"github.com/skratchdot/open-golang/open"
 
 	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/player"
 	"github.com/go-musicfox/go-musicfox/internal/types"
 )
 
@@ -41,32 +43,56 @@ func qualityDisplayName(level service.SongQualityLevel) string {
 	}
 }
 
+// formatSampleRate 以 kHz 显示采样率，如 44.1kHz、96kHz
+func formatSampleRate(rate int) string {
+	return strconv.FormatFloat(float64(rate)/1000, 'f', -1, 64) + "kHz"
+}
+
+// formatOutputFormat 显示输出设备的格式，歌曲被重采样时同时显示原始采样率，如 96kHz→48kHz/32bit
+func formatOutputFormat(format player.OutputFormat) string {
+	text := formatSampleRate(format.SampleRate)
+	if format.SourceRate != 0 && format.SourceRate != format.SampleRate {
+		text = formatSampleRate(format.SourceRate) + "→" + text
+	}
+	return fmt.Sprintf("%s/%dbit", text, format.BitDepth)
+}
+
 // formatQueueAndQuality 格式化状态栏中间文本。
-// 格式：musicfox · [当前索引/总数] · 音质
-// 若无播放歌曲或播放列表为空，返回空字符串。
+// 格式：musicfox · [当前索引/总数 +待播数] · 音质 · 输出格式（引擎支持时）
+// 若无播放歌曲或播放队列为空，返回空字符串。
 func formatQueueAndQuality(player *Player) string {
 	song := player.CurSong()
 	if song.Id == 0 {
 		return ""
 	}
 
-	playlist := player.Playlist()
-	if len(playlist) == 0 {
+	view := player.upNextView()
+	total := len(view.playlist) + view.queueLen()
+	if total == 0 {
 		return ""
 	}
 
-	// 队列位置（1-indexed 显示）
-	curIndex := player.CurSongIndex() + 1
-	total := len(playlist)
-	position := fmt.Sprintf("[%d/%d]", curIndex, total)
+	// 队列位置（1-indexed 显示），索引与总数包含待播队列
+	position := fmt.Sprintf("[%d/%d]", view.current()+1, total)
+	if n := len(view.queue); n > 0 {
+		position = fmt.Sprintf("[%d/%d +%d]", view.current()+1, total, n)
+	}
 
 	// 音质
 	quality := configs.AppConfig.Player.SongLevel
 	qualityName := qualityDisplayName(quality)
 
+	text := fmt.Sprintf(" · %s · %s", position, qualityName)
+	if format, ok := player.outputFormat(); ok {
+		text += " · " + formatOutputFormat(format)
+	}
+	if sleep := sleepTimerStatus(player.netease); sleep != "" {
+		text += " · " + sleep
+	}
+
 	statusTextStyle := style.CurrentStyleSet().StatusBarText
 	return statusTextStyle.Foreground(util.GetPrimaryColor()).Render(musicfoxStatusBarLabel) +
-		statusTextStyle.Render(fmt.Sprintf(" · %s · %s", position, qualityName))
+		statusTextStyle.Render(text)
 }
 
 type queueQualityStatusBarComponent struct {
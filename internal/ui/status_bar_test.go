@@ -11,6 +11,7 @@ import (
 	"github.com/go-musicfox/netease-music/service"
 
 	"github.com/go-musicfox/go-musicfox/internal/configs"
+	"github.com/go-musicfox/go-musicfox/internal/player"
 	"github.com/go-musicfox/go-musicfox/internal/playlist"
 	"github.com/go-musicfox/go-musicfox/internal/structs"
 	"github.com/go-musicfox/go-musicfox/internal/types"
@@ -63,3 +64,19 @@ func TestQueueQualityStatusBarMusicfoxComponentOpensRepository(t *testing.T) {
 		t.Fatalf("adjacent text click handled=%v cmd=%v, want no action", handled, cmd)
 	}
 }
+
+func TestFormatOutputFormat(t *testing.T) {
+	tests := []struct {
+		format player.OutputFormat
+		want   string
+	}{
+		{player.OutputFormat{SourceRate: 96000, SampleRate: 96000, BitDepth: 32}, "96kHz/32bit"},
+		{player.OutputFormat{SourceRate: 44100, SampleRate: 48000, BitDepth: 32}, "44.1kHz→48kHz/32bit"},
+		{player.OutputFormat{SampleRate: 192000, BitDepth: 16}, "192kHz/16bit"},
+	}
+	for _, tt := range tests {
+		if got := formatOutputFormat(tt.format); got != tt.want {
+			t.Errorf("formatOutputFormat(%+v) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/anhoder/foxful-cli/model"
+	"github.com/anhoder/foxful-cli/style"
+	"github.com/anhoder/foxful-cli/util"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/go-musicfox/go-musicfox/internal/configs"
+)
+
+const PageTypeTextInput model.PageType = "text_input"
+
+type tickTextInputMsg struct{}
+
+func tickTextInput(duration time.Duration) tea.Cmd {
+	return tea.Tick(duration, func(t time.Time) tea.Msg {
+		return tickTextInputMsg{}
+	})
+}
+
+// TextInputPage 单行输入页，用于新建、重命名歌单及输入文件路径等
+// onSubmit 返回错误时留在本页并显示错误，成功后回到主界面
+type TextInputPage struct {
+	netease   *Netease
+	menuTitle *model.MenuItem
+	onSubmit  func(value string) error
+
+	backBtnHovered bool
+	backBtnRowY    int
+	backBtnStartX  int
+
+	index        int // 0 输入框，1 提交按钮
+	input        textinput.Model
+	submitButton string
+	tips         string
+
+	inputRowY     int
+	inputStartX   int
+	inputEndX     int
+	submitRowY    int
+	submitStartX  int
+	submitEndX    int
+	hoveredInput  bool
+	hoveredSubmit bool
+	mousePointer  string
+}
+
+func NewTextInputPage(netease *Netease, title *model.MenuItem, placeholder, value string, onSubmit func(value string) error) *TextInputPage {
+	page := &TextInputPage{
+		netease:      netease,
+		menuTitle:    title,
+		onSubmit:     onSubmit,
+		input:        textinput.New(),
+		submitButton: pageSubmitButton(false),
+		mousePointer: "default",
+	}
+	page.input.Placeholder = placeholder
+	page.input.CharLimit = 512
+	page.input.SetValue(value)
+	page.input.CursorEnd()
+	focusPageInput(&page.input)
+	return page
+}
+
+func (p *TextInputPage) IgnoreQuitKeyMsg(_ tea.KeyMsg) bool {
+	return true
+}
+
+func (p *TextInputPage) Type() model.PageType {
+	return PageTypeTextInput
+}
+
+func (p *TextInputPage) Update(msg tea.Msg, a *model.App) (model.Page, tea.Cmd) {
+	if _, ok := msg.(tickTextInputMsg); ok {
+		return p, nil
+	}
+
+	if mouseMsg, ok := msg.(tea.MouseMotionMsg); ok {
+		mouse := mouseMsg.Mouse()
+		oldBackHovered, oldInputHovered, oldSubmitHovered, oldPointer := p.backBtnHovered, p.hoveredInput, p.hoveredSubmit, p.mousePointer
+
+		bcChanged, bcOver := pageBreadcrumbMotion(a, p.netease.MustMain(), mouse.X, mouse.Y)
+		p.backBtnHovered = mouse.Y == p.backBtnRowY && mouse.X >= p.backBtnStartX && mouse.X < p.backBtnStartX+pageBackButtonWidth
+		p.hoveredInput = mouse.Y == p.inputRowY && mouse.X >= p.inputStartX && mouse.X <= p.inputEndX
+		p.hoveredSubmit = mouse.Y == p.submitRowY && mouse.X >= p.submitStartX && mouse.X <= p.submitEndX
+		p.mousePointer = "default"
+		if p.hoveredInput {
+			p.mousePointer = "text"
+		} else if p.backBtnHovered || p.hoveredSubmit || bcOver {
+			p.mousePointer = "pointer"
+		}
+
+		if p.backBtnHovered != oldBackHovered || p.hoveredInput != oldInputHovered || p.hoveredSubmit != oldSubmitHovered || p.mousePointer != oldPointer || bcChanged {
+			return p, tea.Sequence(tickTextInput(time.Nanosecond), a.SetMousePointer(p.mousePointer))
+		}
+		return p.updateInput(msg)
+	}
+
+	if clickMsg, ok := msg.(tea.MouseClickMsg); ok {
+		mouse := clickMsg.Mouse()
+		if mouse.Button != tea.MouseLeft {
+			return p.updateInput(msg)
+		}
+		if newPage := pageBreadcrumbClick(a, p.netease.MustMain(), mouse.X, mouse.Y); newPage != nil {
+			return newPage, p.netease.RerenderCmd(true)
+		}
+		if mouse.Y == p.backBtnRowY && mouse.X >= p.backBtnStartX && mouse.X < p.backBtnStartX+pageBackButtonWidth {
+			return p.netease.MustMain(), p.netease.RerenderCmd(true)
+		}
+		if mouse.Y == p.inputRowY && mouse.X >= p.inputStartX && mouse.X <= p.inputEndX {
+			p.setIndex(0)
+			setPageInputCursor(&p.input, mouse.X, p.inputStartX)
+			return p, tickTextInput(time.Nanosecond)
+		}
+		if mouse.Y == p.submitRowY && mouse.X >= p.submitStartX && mouse.X <= p.submitEndX {
+			return p.enterHandler()
+		}
+		return p.updateInput(msg)
+	}
+
+	key, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return p.updateInput(msg)
+	}
+
+	switch key.String() {
+	case "esc":
+		return p.netease.MustMain(), p.netease.RerenderCmd(true)
+	case "enter":
+		// 单个输入框，回车直接提交
+		return p.enterHandler()
+	case "tab", "shift+tab", "up", "down":
+		p.setIndex(1 - p.index)
+		return p, nil
+	}
+	return p.updateInput(msg)
+}
+
+func (p *TextInputPage) setIndex(index int) {
+	p.index = index
+	if index == 0 {
+		focusPageInput(&p.input)
+	} else {
+		blurPageInput(&p.input)
+	}
+	p.submitButton = pageSubmitButton(index == 1)
+}
+
+func (p *TextInputPage) enterHandler() (model.Page, tea.Cmd) {
+	value := strings.TrimSpace(p.input.Value())
+	if value == "" {
+		p.tips = util.SetFgStyle("请输入"+p.input.Placeholder, lipgloss.BrightRed)
+		return p, tickTextInput(time.Nanosecond)
+	}
+
+	loading := model.NewLoading(p.netease.MustMain(), p.menuTitle)
+	loading.DisplayNotOnlyOnMain()
+	loading.Start()
+	err := p.onSubmit(value)
+	loading.Complete()
+	if err != nil {
+		p.tips = util.SetFgStyle(err.Error(), lipgloss.BrightRed)
+		return p, tickTextInput(time.Nanosecond)
+	}
+	return p.netease.MustMain(), p.netease.RerenderCmd(true)
+}
+
+func (p *TextInputPage) View(a *model.App) string {
+	var (
+		builder strings.Builder
+		top     int
+		main    = p.netease.MustMain()
+	)
+	lineCount := 0
+	write := func(text string) {
+		builder.WriteString(text)
+		lineCount += strings.Count(text, "\n")
+	}
+	padding := func() {
+		if main.MenuStartColumn() > 0 {
+			write(style.CurrentStyleSet().AppBackground.Render(strings.Repeat(" ", main.MenuStartColumn())))
+		}
+	}
+
+	if configs.AppConfig.Theme.ShowTitle {
+		write(pageTitleView(a, main, &top))
+	} else {
+		write("\n")
+		top++
+	}
+
+	topBefore := top
+	write(pageMenuTitleViewWithBack(a, main, &top, p.menuTitle, p.backBtnHovered))
+	p.backBtnRowY = pageMenuTitleRow(a, main, topBefore)
+	p.backBtnStartX = max(0, main.MenuStartColumn()-pageBackButtonWidth)
+	write("\n\n")
+
+	padding()
+	p.inputRowY = lineCount
+	p.inputStartX = max(0, main.MenuStartColumn())
+	p.inputEndX = max(p.inputStartX, a.WindowWidth()-1)
+	p.input.SetWidth(max(1, a.WindowWidth()-p.inputStartX-runewidth.StringWidth(p.input.Prompt)))
+	write(pageInputView(p.input, p.hoveredInput))
+
+	write("\n\n")
+	padding()
+	write(p.tips)
+	write("\n\n")
+	padding()
+	p.submitRowY = lineCount
+	p.submitStartX = max(0, main.MenuStartColumn())
+	submitButtonView := p.submitButton
+	if p.hoveredSubmit {
+		submitButtonView = pageButtonHoverView(pageSubmitText())
+	}
+	p.submitEndX = p.submitStartX + lipgloss.Width(submitButtonView) - 1
+	write(submitButtonView)
+	if spaceLen := a.WindowWidth() - main.MenuStartColumn() - lipgloss.Width(submitButtonView); spaceLen > 0 {
+		write(style.CurrentStyleSet().AppBackground.Render(strings.Repeat(" ", spaceLen)))
+	}
+	write("\n")
+
+	return finishCustomPageView(&builder, a)
+}
+
+func (p *TextInputPage) Msg() tea.Msg {
+	return &tickTextInputMsg{}
+}
+
+func (p *TextInputPage) updateInput(msg tea.Msg) (model.Page, tea.Cmd) {
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return p, cmd
+}
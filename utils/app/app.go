@@ -217,6 +217,11 @@ func DownloadDir() string {
 	return paths.downloadDir
 }
 
+// OfflineDir 离线歌曲目录，不受缓存大小限制
+func OfflineDir() string {
+	return filepath.Join(DataDir(), "offline")
+}
+
 // DownloadLyricDir 歌词下载目录，同 DownloadDir
 func DownloadLyricDir() string {
 	customDir := configs.AppConfig.Storage.LyricDir
@@ -1,7 +1,9 @@
 package netease
 
 import (
+	"net/url"
 	"strconv"
+	"strings"
 )
 
 func WebUrlOfPlaylist(playlistId int64) string {
@@ -19,3 +21,31 @@ func WebUrlOfArtist(artistId int64) string {
 func WebUrlOfAlbum(artistId int64) string {
 	return "https://music.163.com/#/album?id=" + strconv.FormatInt(artistId, 10)
 }
+
+// ParseSongId 从歌曲 id、netease:<id> 或网易云音乐的歌曲链接中解析歌曲 id
+func ParseSongId(uri string) (int64, bool) {
+	if rest, ok := strings.CutPrefix(uri, "netease:"); ok {
+		uri = strings.TrimPrefix(rest, "//")
+	}
+	if id, err := strconv.ParseInt(uri, 10, 64); err == nil {
+		return id, id > 0
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return 0, false
+	}
+	// 只接受 music.163.com 及其子域名，evilmusic.163.com 之类的域名不算
+	if host := u.Hostname(); host != "music.163.com" && !strings.HasSuffix(host, ".music.163.com") {
+		return 0, false
+	}
+	query := u.Query()
+	// https://music.163.com/#/song?id=N 的参数在 fragment 中
+	if _, fragment, ok := strings.Cut(u.Fragment, "?"); ok {
+		if q, err := url.ParseQuery(fragment); err == nil {
+			query = q
+		}
+	}
+	id, err := strconv.ParseInt(query.Get("id"), 10, 64)
+	return id, err == nil && id > 0
+}
@@ -0,0 +1,22 @@
+package netease
+
+import "testing"
+
+func TestParseSongId(t *testing.T) {
+	tests := map[string]int64{
+		"123":                                    123,
+		"netease:456":                            456,
+		"https://music.163.com/#/song?id=789":    789,
+		"https://music.163.com/song?id=10&uct=x": 10,
+		"https://y.music.163.com/m/song?id=11":   11,
+		"https://example.com/song?id=1":          0,
+		"https://evilmusic.163.com/song?id=12":   0,
+		"https://music.163.com.evil.com/?id=13":  0,
+		"file:///tmp/a.mp3":                      0,
+	}
+	for uri, want := range tests {
+		if got, _ := ParseSongId(uri); got != want {
+			t.Errorf("ParseSongId(%q) = %d, want %d", uri, got, want)
+		}
+	}
+}
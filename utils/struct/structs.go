@@ -116,6 +116,17 @@ func GetSongsOfPlaylist(data []byte) (list []structs.Song) {
 	return
 }
 
+// GetSongsOfSongDetail 获取歌曲详情接口返回的歌曲
+func GetSongsOfSongDetail(data []byte) (list []structs.Song) {
+	_, _ = jsonparser.ArrayEach(data, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		if song, err := structs.NewSongFromShortNameSongsJson(value); err == nil {
+			list = append(list, song)
+		}
+	}, "songs")
+
+	return
+}
+
 // GetSongsOfAlbum 获取专辑的歌曲
 func GetSongsOfAlbum(data []byte) (list []structs.Song) {
 	var album structs.Album